@@ -0,0 +1,207 @@
+// Package client is an HTTP client for the Spectra API with the method set of sdk.SpectraFS, for
+// programs that talk to a Spectra server instead of embedding the SDK. Requests are built from the
+// API's own models (internal/api/models) and failures unwrap to the SDK's error sentinels, so
+// errors.Is(err, sdk.ErrPathExists) works the same against either.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/api/handlers"
+	apimiddleware "github.com/Project-Sylos/Spectra/internal/api/middleware"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// DefaultTimeout bounds each attempt of a request when Config.Timeout is unset
+const DefaultTimeout = 30 * time.Second
+
+// DefaultRetryPolicy is used when Config.Retry is left zero
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, Backoff: 100 * time.Millisecond, MaxBackoff: 2 * time.Second}
+
+// RetryPolicy controls how a request is retried after a connection error or a 5xx response
+// Only requests that are safe to repeat are retried: reads, deletes, resets and overwriting uploads
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts per request, the first included (1 = never retry)
+	Backoff     time.Duration // Wait before the first retry, doubled for each one after
+	MaxBackoff  time.Duration // Cap on the wait between retries (0 = no cap)
+}
+
+// Config configures a Client
+type Config struct {
+	BaseURL    string        // Server URL, e.g. http://localhost:8086
+	Instance   string        // Named instance to address instead of the main filesystem (optional)
+	Token      string        // API token sent as a bearer token, when the server has api.auth enabled
+	Timeout    time.Duration // Bound on each attempt of a request (0 = DefaultTimeout)
+	Retry      RetryPolicy   // Zero value = DefaultRetryPolicy
+	HTTPClient *http.Client  // Used as is when set; Timeout is then ignored
+}
+
+// Client calls the Spectra API of one server (and instance); it is safe for concurrent use
+type Client struct {
+	base  string // URL of /api/v1, or of the instance's routes under it
+	token string
+	retry RetryPolicy
+	http  *http.Client
+}
+
+// Error is a failed API call: the status, code and message of the server's error response
+// It unwraps to the SDK sentinel the code stands for (e.g. sdk.ErrNodeNotFound for node_not_found)
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+// Error returns the server's message
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the SDK sentinel of the error code, or nil if it has none
+func (e *Error) Unwrap() error {
+	return handlers.ErrorForCode(e.Code)
+}
+
+// New creates a client for the server at cfg.BaseURL
+func New(cfg Config) (*Client, error) {
+	base, err := url.Parse(cfg.BaseURL)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: want scheme and host, e.g. http://localhost:8086", cfg.BaseURL)
+	}
+
+	c := &Client{
+		base:  strings.TrimSuffix(cfg.BaseURL, "/") + "/api/v1",
+		token: cfg.Token,
+		retry: cfg.Retry,
+		http:  cfg.HTTPClient,
+	}
+	if cfg.Instance != "" {
+		c.base += "/instances/" + url.PathEscape(cfg.Instance)
+	}
+	if c.retry == (RetryPolicy{}) {
+		c.retry = DefaultRetryPolicy
+	}
+	if c.retry.MaxAttempts < 1 {
+		c.retry.MaxAttempts = 1
+	}
+	if c.http == nil {
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		c.http = &http.Client{Timeout: timeout}
+	}
+	return c, nil
+}
+
+// request describes one API call
+type request struct {
+	method     string
+	route      string // Path under /api/v1, e.g. "/items/list"
+	query      url.Values
+	body       any  // Sent as JSON when set
+	idempotent bool // Safe to retry after a connection error or 5xx
+}
+
+// do performs r, retrying under the policy, and returns the response if its status is 2xx or an
+// *Error otherwise. The caller closes the body.
+func (c *Client) do(ctx context.Context, r request) (*http.Response, error) {
+	var payload []byte
+	if r.body != nil {
+		data, err := json.Marshal(r.body)
+		if err != nil {
+			return nil, err
+		}
+		payload = data
+	}
+	target := c.base + r.route
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+
+	attempts := 1
+	if r.idempotent {
+		attempts = c.retry.MaxAttempts
+	}
+	backoff := c.retry.Backoff
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, r.method, target, payload)
+		retryable := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		if !retryable || attempt >= attempts || ctx.Err() != nil {
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode >= 300 {
+				return nil, readError(resp)
+			}
+			return resp, nil
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if c.retry.MaxBackoff > 0 && backoff > c.retry.MaxBackoff {
+			backoff = c.retry.MaxBackoff
+		}
+	}
+}
+
+// send makes one attempt at a request
+func (c *Client) send(ctx context.Context, method, target string, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	// The models are snake_case, whatever api.response_case the server defaults to
+	req.Header.Set(apimiddleware.CaseHeader, apimiddleware.CaseSnake)
+	return c.http.Do(req)
+}
+
+// readError turns an error response into an *Error and closes its body
+func readError(resp *http.Response) error {
+	defer resp.Body.Close()
+	var failure types.APIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&failure); err != nil || failure.Message == "" {
+		failure.Message = fmt.Sprintf("%s %s: %s", resp.Request.Method, resp.Request.URL.Path, resp.Status)
+	}
+	return &Error{StatusCode: resp.StatusCode, Code: failure.Code, Message: failure.Message}
+}
+
+// call performs r and decodes the data of the APIResponse envelope into out (skipped if nil)
+func (c *Client) call(ctx context.Context, r request, out any) error {
+	resp, err := c.do(ctx, r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Data holds out, so the decoder fills it in place
+	if err := json.NewDecoder(resp.Body).Decode(&types.APIResponse{Data: out}); err != nil {
+		return fmt.Errorf("invalid response from %s %s: %w", r.method, r.route, err)
+	}
+	return nil
+}
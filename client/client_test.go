@@ -0,0 +1,227 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/api"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// newServer serves a small in-memory instance through the real router, both closed when the test ends
+func newServer(t *testing.T, configure func(*sdk.Config)) (*httptest.Server, *sdk.SpectraFS) {
+	t.Helper()
+	cfg := sdk.DefaultConfig()
+	cfg.Seed.DBPath = sdk.MemoryDBPath
+	cfg.Seed.MaxDepth = 2
+	configure(&cfg)
+	fs, err := sdk.NewFromConfig(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(api.NewRouter(fs).SetupRoutes())
+	t.Cleanup(func() {
+		server.Close()
+		fs.Close()
+	})
+	return server, fs
+}
+
+// newClient returns a client of server that retries quickly
+func newClient(t *testing.T, server *httptest.Server, token string) *Client {
+	t.Helper()
+	c, err := New(Config{BaseURL: server.URL, Token: token, Retry: RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestClientMatchesSDK(t *testing.T) {
+	server, fs := newServer(t, func(*sdk.Config) {})
+	c := newClient(t, server, "")
+
+	got, err := c.ListChildren(&sdk.ListChildrenRequest{ParentID: "root", TableName: "primary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := fs.ListChildren(&sdk.ListChildrenRequest{ParentID: "root", TableName: "primary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Folders) != len(want.Folders) || len(got.Files) != len(want.Files) || got.Folders[0].ID != want.Folders[0].ID {
+		t.Fatalf("client listed %d folders and %d files, SDK %d and %d", len(got.Folders), len(got.Files), len(want.Folders), len(want.Files))
+	}
+
+	file := got.Files[0]
+	byID, err := c.GetNode(&sdk.GetNodeRequest{ID: file.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPath, err := c.GetNode(&sdk.GetNodeRequest{Path: file.Path, TableName: "primary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byID.ID != file.ID || byPath.ID != file.ID {
+		t.Errorf("GetNode by ID %s and by path %s, want %s", byID.ID, byPath.ID, file.ID)
+	}
+
+	data, checksum, err := c.GetFileData(file.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantData, wantChecksum, err := fs.GetFileData(file.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, wantData) || checksum != wantChecksum {
+		t.Errorf("GetFileData returned %d bytes with checksum %q, SDK %d with %q", len(data), checksum, len(wantData), wantChecksum)
+	}
+
+	tables, err := c.GetTableInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTables, err := fs.GetTableInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != len(wantTables) {
+		t.Errorf("GetTableInfo returned %d tables, SDK %d", len(tables), len(wantTables))
+	}
+}
+
+func TestClientWrites(t *testing.T) {
+	server, fs := newServer(t, func(*sdk.Config) {})
+	c := newClient(t, server, "")
+
+	folder, err := c.CreateFolder(&sdk.CreateFolderRequest{ParentID: "root", Name: "made"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateFolder(&sdk.CreateFolderRequest{ParentID: "root", Name: "made"}); !errors.Is(err, sdk.ErrPathExists) {
+		t.Errorf("duplicate CreateFolder = %v, want ErrPathExists", err)
+	}
+	if _, err := c.CreateFolder(&sdk.CreateFolderRequest{ParentID: "missing", Name: "x"}); !errors.Is(err, sdk.ErrParentNotFound) {
+		t.Errorf("CreateFolder under a missing parent = %v, want ErrParentNotFound", err)
+	}
+
+	file, err := c.UploadFile(&sdk.UploadFileRequest{ParentID: folder.ID, Name: "a.txt", Data: []byte("hello")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.GetNode(&sdk.GetNodeRequest{ID: file.ID}); err != nil {
+		t.Errorf("uploaded file is not in the instance: %v", err)
+	}
+	if _, err := c.UploadFile(&sdk.UploadFileRequest{ParentID: folder.ID, Name: "a.txt", Data: []byte("again"), Overwrite: true}); err != nil {
+		t.Errorf("overwriting upload: %v", err)
+	}
+
+	if err := c.DeleteNode(&sdk.DeleteNodeRequest{ID: folder.ID}); !errors.Is(err, sdk.ErrFolderNotEmpty) {
+		t.Errorf("DeleteNode on a non-empty folder = %v, want ErrFolderNotEmpty", err)
+	}
+	if err := c.DeleteNode(&sdk.DeleteNodeRequest{Path: "/made", TableName: "primary", Recursive: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetNode(&sdk.GetNodeRequest{ID: folder.ID}); !errors.Is(err, sdk.ErrNodeNotFound) {
+		t.Errorf("GetNode after delete = %v, want ErrNodeNotFound", err)
+	}
+
+	if err := c.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := fs.GetNodeCount("primary"); err != nil || count > 1 {
+		t.Errorf("%d primary nodes after Reset (%v), want only the root", count, err)
+	}
+}
+
+func TestClientJobs(t *testing.T) {
+	server, fs := newServer(t, func(*sdk.Config) {})
+	c := newClient(t, server, "")
+	ctx := context.Background()
+
+	job, err := c.StartGenerateContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done, err := c.WaitForJob(ctx, job.ID, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done.Status != sdk.JobDone {
+		t.Errorf("generate job ended %s", done.Status)
+	}
+	stats, err := fs.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.MaxDepth != 2 {
+		t.Errorf("generated tree is %d deep, want 2", stats.MaxDepth)
+	}
+	if _, err := c.GetJob("missing"); !errors.Is(err, sdk.ErrJobNotFound) {
+		t.Errorf("GetJob of an unknown ID = %v, want ErrJobNotFound", err)
+	}
+}
+
+func TestClientToken(t *testing.T) {
+	server, _ := newServer(t, func(cfg *sdk.Config) {
+		cfg.API.Auth.Tokens = []sdk.AuthToken{{Token: "r", Role: sdk.RoleRead}}
+	})
+
+	var failure *Error
+	if _, err := newClient(t, server, "").GetTableInfo(); !errors.As(err, &failure) || failure.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GetTableInfo without a token = %v, want 401", err)
+	}
+	if _, err := newClient(t, server, "r").GetTableInfo(); err != nil {
+		t.Errorf("GetTableInfo with a read token: %v", err)
+	}
+	if err := newClient(t, server, "r").Reset(); !errors.As(err, &failure) || failure.StatusCode != http.StatusForbidden {
+		t.Errorf("Reset with a read token = %v, want 403", err)
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success": true, "data": []}`))
+	}))
+	defer server.Close()
+	c := newClient(t, server, "")
+
+	if _, err := c.GetTableInfo(); err != nil {
+		t.Fatalf("read after two 503s: %v", err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("read took %d attempts, want 3", n)
+	}
+
+	// Creating a folder is not safe to repeat, so a 5xx is returned at once
+	calls.Store(0)
+	if _, err := c.CreateFolder(&sdk.CreateFolderRequest{ParentID: "root", Name: "x"}); err == nil {
+		t.Error("CreateFolder succeeded after a 503")
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("CreateFolder took %d attempts, want 1", n)
+	}
+
+	// The policy caps the attempts
+	calls.Store(-10)
+	var failure *Error
+	if _, err := c.GetTableInfo(); !errors.As(err, &failure) || failure.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("read after exhausting retries = %v, want the 503", err)
+	}
+	if n := calls.Load(); n != -7 {
+		t.Errorf("read made %d attempts, want 3", n+10)
+	}
+}
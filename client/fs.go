@@ -0,0 +1,239 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+
+	"github.com/Project-Sylos/Spectra/internal/api/handlers"
+	apimodels "github.com/Project-Sylos/Spectra/internal/api/models"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// lookupPageSize is the listing page size used to resolve a path
+const lookupPageSize = 1000
+
+// The methods below mirror sdk.SpectraFS: each Context method calls the matching /api/v1 route,
+// and each plain one calls it with context.Background()
+
+// ListChildrenContext returns the children of a given parent node
+// Set Limit and StartingAfter/EndingBefore on the request to page through wide folders
+func (c *Client) ListChildrenContext(ctx context.Context, req *sdk.ListChildrenRequest) (*sdk.ListResult, error) {
+	resp, err := c.do(ctx, request{
+		method: http.MethodPost,
+		route:  "/items/list",
+		body: apimodels.ListChildrenRequest{
+			ParentID:      req.ParentID,
+			ParentPath:    req.ParentPath,
+			TableName:     req.TableName,
+			Limit:         req.Limit,
+			StartingAfter: req.StartingAfter,
+			EndingBefore:  req.EndingBefore,
+			Cursor:        req.Cursor,
+		},
+		idempotent: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// The list route answers with the bare result rather than the APIResponse envelope
+	var result sdk.ListResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid response from POST /items/list: %w", err)
+	}
+	return &result, nil
+}
+
+// ListChildren calls ListChildrenContext with context.Background()
+func (c *Client) ListChildren(req *sdk.ListChildrenRequest) (*sdk.ListResult, error) {
+	return c.ListChildrenContext(context.Background(), req)
+}
+
+// GetNodeContext retrieves a node using either ID or Path+TableName
+// The API looks nodes up by ID, so a path is resolved by listing its parent folder
+func (c *Client) GetNodeContext(ctx context.Context, req *sdk.GetNodeRequest) (*sdk.Node, error) {
+	id := req.ID
+	if id == "" {
+		if req.Path == "" || req.TableName == "" {
+			return nil, &Error{StatusCode: http.StatusBadRequest, Code: handlers.CodeInvalidInput, Message: "either id or (path + table_name) are required"}
+		}
+		return c.lookup(ctx, req.TableName, req.Path)
+	}
+
+	var node sdk.Node
+	err := c.call(ctx, request{method: http.MethodGet, route: "/node/" + url.PathEscape(id), idempotent: true}, &node)
+	if err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// GetNode calls GetNodeContext with context.Background()
+func (c *Client) GetNode(req *sdk.GetNodeRequest) (*sdk.Node, error) {
+	return c.GetNodeContext(context.Background(), req)
+}
+
+// GetFileDataContext returns a file's content and checksum
+func (c *Client) GetFileDataContext(ctx context.Context, id string) ([]byte, string, error) {
+	resp, err := c.do(ctx, request{method: http.MethodGet, route: "/items/" + url.PathEscape(id) + "/data", idempotent: true})
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, resp.Header.Get("X-Checksum"), nil
+}
+
+// GetFileData calls GetFileDataContext with context.Background()
+func (c *Client) GetFileData(id string) ([]byte, string, error) {
+	return c.GetFileDataContext(context.Background(), id)
+}
+
+// CreateFolderContext creates a new folder node
+// Returns ErrPathExists if a sibling already has the name. Never retried, since a first attempt
+// that landed would make the retry fail with ErrPathExists
+func (c *Client) CreateFolderContext(ctx context.Context, req *sdk.CreateFolderRequest) (*sdk.Node, error) {
+	var node sdk.Node
+	err := c.call(ctx, request{
+		method: http.MethodPost,
+		route:  "/items/folder",
+		body: apimodels.CreateFolderRequest{
+			ParentID:   req.ParentID,
+			ParentPath: req.ParentPath,
+			TableName:  req.TableName,
+			Name:       req.Name,
+		},
+	}, &node)
+	if err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// CreateFolder calls CreateFolderContext with context.Background()
+func (c *Client) CreateFolder(req *sdk.CreateFolderRequest) (*sdk.Node, error) {
+	return c.CreateFolderContext(context.Background(), req)
+}
+
+// UploadFileContext uploads a file, sending its content base64 encoded in the JSON body
+// Returns ErrPathExists if a sibling already has the name, unless req.Overwrite replaces an existing
+// file in place. Only overwriting uploads are retried
+func (c *Client) UploadFileContext(ctx context.Context, req *sdk.UploadFileRequest) (*sdk.Node, error) {
+	var node sdk.Node
+	err := c.call(ctx, request{
+		method: http.MethodPost,
+		route:  "/items/file",
+		body: apimodels.UploadFileRequest{
+			ParentID:   req.ParentID,
+			ParentPath: req.ParentPath,
+			TableName:  req.TableName,
+			Name:       req.Name,
+			Data:       req.Data,
+			Overwrite:  req.Overwrite,
+		},
+		idempotent: req.Overwrite,
+	}, &node)
+	if err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// UploadFile calls UploadFileContext with context.Background()
+func (c *Client) UploadFile(req *sdk.UploadFileRequest) (*sdk.Node, error) {
+	return c.UploadFileContext(context.Background(), req)
+}
+
+// DeleteNodeContext deletes a node using either ID or Path+TableName
+// Non-empty folders return ErrFolderNotEmpty unless req.Recursive is set
+func (c *Client) DeleteNodeContext(ctx context.Context, req *sdk.DeleteNodeRequest) error {
+	id := req.ID
+	if id == "" {
+		node, err := c.GetNodeContext(ctx, &sdk.GetNodeRequest{Path: req.Path, TableName: req.TableName})
+		if err != nil {
+			return err
+		}
+		id = node.ID
+	}
+
+	query := url.Values{}
+	if req.Recursive {
+		query.Set("recursive", strconv.FormatBool(true))
+	}
+	return c.call(ctx, request{method: http.MethodDelete, route: "/node/" + url.PathEscape(id), query: query, idempotent: true}, nil)
+}
+
+// DeleteNode calls DeleteNodeContext with context.Background()
+func (c *Client) DeleteNode(req *sdk.DeleteNodeRequest) error {
+	return c.DeleteNodeContext(context.Background(), req)
+}
+
+// ResetContext clears all nodes and recreates the root; the token needs the admin role
+func (c *Client) ResetContext(ctx context.Context) error {
+	return c.call(ctx, request{method: http.MethodPost, route: "/reset", idempotent: true}, nil)
+}
+
+// Reset calls ResetContext with context.Background()
+func (c *Client) Reset() error {
+	return c.ResetContext(context.Background())
+}
+
+// GetTableInfoContext returns information about all tables
+func (c *Client) GetTableInfoContext(ctx context.Context) ([]sdk.TableInfo, error) {
+	var tables []sdk.TableInfo
+	if err := c.call(ctx, request{method: http.MethodGet, route: "/tables", idempotent: true}, &tables); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// GetTableInfo calls GetTableInfoContext with context.Background()
+func (c *Client) GetTableInfo() ([]sdk.TableInfo, error) {
+	return c.GetTableInfoContext(context.Background())
+}
+
+// lookup returns the node at p in world, paging through its parent folder's listing
+func (c *Client) lookup(ctx context.Context, world, p string) (*sdk.Node, error) {
+	p = path.Clean("/" + p)
+	if p == "/" {
+		return c.GetNodeContext(ctx, &sdk.GetNodeRequest{ID: "root"})
+	}
+
+	req := &sdk.ListChildrenRequest{ParentPath: path.Dir(p), TableName: world, Limit: lookupPageSize}
+	for {
+		page, err := c.ListChildrenContext(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		for i := range page.Folders {
+			if page.Folders[i].Name == path.Base(p) {
+				return &page.Folders[i].Node, nil
+			}
+		}
+		for i := range page.Files {
+			if page.Files[i].Name == path.Base(p) {
+				return &page.Files[i].Node, nil
+			}
+		}
+		for i := range page.Symlinks {
+			if page.Symlinks[i].Name == path.Base(p) {
+				return &page.Symlinks[i].Node, nil
+			}
+		}
+		if page.NextCursor == "" {
+			return nil, &Error{StatusCode: http.StatusNotFound, Code: "node_not_found", Message: fmt.Sprintf("node not found with path %s in %s", p, world)}
+		}
+		req.Cursor = page.NextCursor
+	}
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// DefaultJobPollInterval is how often WaitForJob polls when given no interval
+const DefaultJobPollInterval = 500 * time.Millisecond
+
+// StartGenerateContext starts GenerateAll on the server as a job and returns it (see WaitForJob);
+// sdk.ErrGenerationRunning if a run is already in progress
+func (c *Client) StartGenerateContext(ctx context.Context) (*sdk.Job, error) {
+	var job sdk.Job
+	if err := c.call(ctx, request{method: http.MethodPost, route: "/generate"}, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// StartResetContext starts a reset on the server as a job, followed by GenerateAll if regenerate
+// is set, and returns it (see WaitForJob); the token needs the admin role
+func (c *Client) StartResetContext(ctx context.Context, regenerate bool) (*sdk.Job, error) {
+	query := url.Values{"async": {"true"}, "regenerate": {strconv.FormatBool(regenerate)}}
+	var job sdk.Job
+	if err := c.call(ctx, request{method: http.MethodPost, route: "/reset", query: query}, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetJobContext returns the current state of a job (sdk.ErrJobNotFound if the server has no record of it)
+func (c *Client) GetJobContext(ctx context.Context, id string) (*sdk.Job, error) {
+	var job sdk.Job
+	route := "/jobs/" + url.PathEscape(id)
+	if err := c.call(ctx, request{method: http.MethodGet, route: route, idempotent: true}, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetJob calls GetJobContext with context.Background()
+func (c *Client) GetJob(id string) (*sdk.Job, error) {
+	return c.GetJobContext(context.Background(), id)
+}
+
+// CancelJobContext cancels a queued or running job; the job reports cancelled once its operation
+// has stopped. A job that has already finished is an *Error with status 409
+func (c *Client) CancelJobContext(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, route: "/jobs/" + url.PathEscape(id)}, nil)
+}
+
+// WaitForJob polls a job every interval (DefaultJobPollInterval if 0) until it finishes and returns
+// its final state. A failed job also returns an *Error holding the job's error code and message
+// (StatusCode 0), so errors.Is matches the SDK sentinel of the failure; a cancelled job returns no
+// error. Cancelling ctx stops the wait, not the job
+func (c *Client) WaitForJob(ctx context.Context, id string, interval time.Duration) (*sdk.Job, error) {
+	if interval <= 0 {
+		interval = DefaultJobPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job, err := c.GetJobContext(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		switch job.Status {
+		case sdk.JobFailed:
+			return job, &Error{Code: job.ErrorCode, Message: job.Error}
+		case sdk.JobDone, sdk.JobCancelled:
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
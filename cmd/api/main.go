@@ -2,89 +2,90 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
-	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/Project-Sylos/Spectra/internal/api"
+	"github.com/Project-Sylos/Spectra/internal/config"
 	"github.com/Project-Sylos/Spectra/sdk"
 )
 
 func main() {
-	fmt.Println("Spectra API Server")
-	fmt.Println("==================")
-
-	// Load configuration
+	// Initialize SpectraFS, with the named instances the config lists
 	configPath := getConfigPath()
-	fmt.Printf("Loading configuration from: %s\n", configPath)
-
-	// Initialize SpectraFS
-	fmt.Println("Initializing SpectraFS...")
-	fs, err := sdk.New(configPath)
+	instances, err := sdk.NewMultiInstance(configPath)
 	if err != nil {
-		log.Fatalf("Failed to initialize SpectraFS: %v", err)
+		log.Fatalf("Failed to initialize SpectraFS from %s: %v", configPath, err)
 	}
-	fmt.Println("SpectraFS initialized successfully")
-
-	// Get configuration
+	fs := instances.Main()
 	cfg := fs.GetConfig()
-	fmt.Printf("API config: Host=%s, Port=%d\n", cfg.API.Host, cfg.API.Port)
 
-	// Create API server
-	fmt.Println("Creating API server...")
-	server := api.NewServer(fs, &cfg.API)
-	fmt.Println("API server created successfully")
+	// Create the API server; its logger (api.log_level, api.log_format) is used from here on
+	server := api.NewMultiInstanceServer(instances, &cfg.API)
+	logger := server.Logger()
+	fs.SetLogger(logger)
+	logger.Info("SpectraFS initialized", "config", configPath, "host", cfg.API.Host, "port", cfg.API.Port)
+	for _, name := range instances.Names() {
+		if instance, err := instances.Instance(name); err == nil {
+			instance.SetLogger(logger.With("instance", name))
+		}
+		logger.Info("Instance available", "instance", name, "path", "/api/v1/instances/"+name+"/")
+	}
 
-	// Create HTTP server with timeout
-	addr := fmt.Sprintf("%s:%d", cfg.API.Host, cfg.API.Port)
-	httpServer := &http.Server{
-		Addr:         addr,
-		Handler:      server.GetRouter(),
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	// Report what the recovery pass found if the previous run did not shut down cleanly
+	if report := fs.RecoveryOnOpen(); report != nil {
+		logger.Warn("Previous shutdown was unclean", "issues", len(report.Findings), "repaired", report.Repaired)
+		for _, finding := range report.Findings {
+			logger.Warn("Recovery finding", "severity", finding.Severity, "message", finding.Message)
+		}
 	}
 
-	// Handle graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// Warn when db.accept_config_change let a database generated with other settings open
+	if changes := fs.ConfigChangesOnOpen(); len(changes) > 0 {
+		logger.Warn("The config differs from the database's generation settings, which now record the config's", "changes", changes)
+	}
 
-	go func() {
-		<-sigChan
-		fmt.Println("\nShutting down server...")
+	for _, warning := range config.Warnings(cfg) {
+		logger.Warn(warning)
+	}
 
-		// Create shutdown context with timeout
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+	// Run scheduled maintenance in the background; Close stops it on shutdown
+	if err := fs.StartMaintenance(); err != nil {
+		logger.Error("Failed to start maintenance scheduler", "error", err)
+		os.Exit(1)
+	}
 
-		// Shutdown HTTP server
-		if err := httpServer.Shutdown(ctx); err != nil {
-			log.Printf("Error shutting down HTTP server: %v", err)
+	// Change the tree in the background if the config asks for it; Close stops the engine too
+	if cfg.Mutations.Enabled {
+		if err := fs.StartMutations(); err != nil {
+			logger.Error("Failed to start mutation engine", "error", err)
+			os.Exit(1)
 		}
+	}
 
-		// Close filesystem
-		if err := fs.Close(); err != nil {
-			log.Printf("Error closing filesystem: %v", err)
-		}
+	// Stop serving on SIGINT/SIGTERM; Start drains in-flight requests before returning
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-		fmt.Println("Server shutdown complete")
-		os.Exit(0)
+	go func() {
+		<-ctx.Done()
+		logger.Info("Shutting down server")
 	}()
 
-	// Start server
-	fmt.Printf("Starting HTTP server on %s\n", addr)
-	fmt.Printf("API endpoints available at http://%s/api/v1/\n", addr)
-	fmt.Printf("Health check available at http://%s/health\n", addr)
-	fmt.Println("Press Ctrl+C to stop the server")
-
 	// I am here to serve.
-	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("Failed to start server: %v", err)
+	serveErr := server.Start(ctx)
+
+	// Close filesystem
+	if err := server.Stop(); err != nil {
+		logger.Error("Failed to close filesystem", "error", err)
+	}
+	if serveErr != nil {
+		logger.Error("Server failed", "error", serveErr)
+		os.Exit(1)
 	}
+	logger.Info("Server shutdown complete")
 }
 
 // getConfigPath returns the configuration file path
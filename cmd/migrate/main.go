@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+func main() {
+	configPath := flag.String("config", "internal/config/default.json", "configuration file path")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: migrate [flags] node-encoding")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	fs, err := sdk.New(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to initialize SpectraFS: %v", err)
+	}
+	defer fs.Close()
+
+	// An interrupted migration keeps the batches already committed; running it again carries on
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch flag.Arg(0) {
+	case "node-encoding":
+		result, err := fs.MigrateNodeEncoding(ctx)
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		fmt.Printf("Rewrote %d of %d nodes in the binary encoding in %dms\n", result.Rewritten, result.Scanned, result.DurationMillis)
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"reflect"
+
+	"github.com/Project-Sylos/Spectra/internal/config"
+	"github.com/Project-Sylos/Spectra/internal/spectrafs"
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+func main() {
+	configPath := flag.String("config", "internal/config/default.json", "configuration file path")
+	format := flag.String("format", sdk.SnapshotFormatJSONL, "export format: jsonl or json")
+	out := flag.String("out", "", "export destination (default stdout)")
+	in := flag.String("in", "", "import source (default stdin)")
+	merge := flag.Bool("merge", false, "import into a non-empty database, skipping nodes that already exist")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: snapshot [flags] export|import|verify")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	fs, err := sdk.New(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to initialize SpectraFS: %v", err)
+	}
+	defer fs.Close()
+
+	ctx := context.Background()
+	switch flag.Arg(0) {
+	case "export":
+		w := io.Writer(os.Stdout)
+		if *out != "" {
+			f, err := os.Create(*out)
+			if err != nil {
+				log.Fatalf("Failed to create %s: %v", *out, err)
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := fs.Export(ctx, w, *format); err != nil {
+			log.Fatalf("Export failed: %v", err)
+		}
+
+	case "import":
+		r := io.Reader(os.Stdin)
+		if *in != "" {
+			f, err := os.Open(*in)
+			if err != nil {
+				log.Fatalf("Failed to open %s: %v", *in, err)
+			}
+			defer f.Close()
+			r = f
+		}
+		result, err := fs.Import(ctx, r, *merge)
+		if err != nil {
+			log.Fatalf("Import failed: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "Imported %d nodes (%d skipped)\n", result.Imported, result.Skipped)
+
+	case "verify":
+		if err := verify(ctx, fs, *configPath, *format); err != nil {
+			log.Fatalf("Round trip failed: %v", err)
+		}
+		fmt.Println("Round trip OK")
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// verify exports fs, imports the snapshot into a fresh database built from the same config,
+// exports that again and checks the two snapshots node for node, existence maps included
+func verify(ctx context.Context, fs *sdk.SpectraFS, configPath, format string) error {
+	var original bytes.Buffer
+	if err := fs.Export(ctx, &original, format); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadFromFile(configPath)
+	if err != nil {
+		return err
+	}
+	cfg.Seed.DBPath = sdk.MemoryDBPath
+	target, err := spectrafs.NewSpectraFSFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	result, err := target.Import(ctx, bytes.NewReader(original.Bytes()), false)
+	if err != nil {
+		return err
+	}
+	var reloaded bytes.Buffer
+	if err := target.Export(ctx, &reloaded, format); err != nil {
+		return err
+	}
+
+	want, err := decodeSnapshot(original.Bytes(), format)
+	if err != nil {
+		return err
+	}
+	got, err := decodeSnapshot(reloaded.Bytes(), format)
+	if err != nil {
+		return err
+	}
+	if len(got) != len(want) || result.Imported != len(want) {
+		return fmt.Errorf("exported %d nodes, imported %d, re-exported %d", len(want), result.Imported, len(got))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(want[i], got[i]) {
+			return fmt.Errorf("node %d (%s) differs after the round trip", i, want[i].Path)
+		}
+	}
+	fmt.Printf("%d nodes round-tripped\n", len(want))
+	return nil
+}
+
+// decodeSnapshot parses an exported snapshot back into nodes
+func decodeSnapshot(data []byte, format string) ([]*types.Node, error) {
+	var nodes []*types.Node
+	if format == sdk.SnapshotFormatJSON {
+		err := json.Unmarshal(data, &nodes)
+		return nodes, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		var node types.Node
+		if err := json.Unmarshal(scanner.Bytes(), &node); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, &node)
+	}
+	return nodes, scanner.Err()
+}
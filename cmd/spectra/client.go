@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	apimiddleware "github.com/Project-Sylos/Spectra/internal/api/middleware"
+	apimodels "github.com/Project-Sylos/Spectra/internal/api/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// listPageSize is the page size used when ls and stat page through a folder
+const listPageSize = 1000
+
+// client talks to the HTTP API of a running Spectra server
+type client struct {
+	server   string // Base URL, e.g. http://localhost:8086
+	token    string // Sent as a bearer token when set
+	instance string // Named instance to address instead of the main filesystem
+	json     bool   // Print JSON instead of tables
+	http     *http.Client
+}
+
+// endpoint returns the URL of an /api/v1 route, under the instance's prefix when one is set
+func (c *client) endpoint(route string, query url.Values) string {
+	base := strings.TrimSuffix(c.server, "/") + "/api/v1"
+	if c.instance != "" {
+		base += "/instances/" + url.PathEscape(c.instance)
+	}
+	if len(query) > 0 {
+		return base + route + "?" + query.Encode()
+	}
+	return base + route
+}
+
+// send performs a request, returning the response if its status is 2xx and the server's error
+// message otherwise. The caller closes the body.
+func (c *client) send(ctx context.Context, method, route string, query url.Values, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint(route, query), body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	// The models below are snake_case, whatever api.response_case the server defaults to
+	req.Header.Set(apimiddleware.CaseHeader, apimiddleware.CaseSnake)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var failure types.APIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&failure); err != nil || failure.Message == "" {
+		return nil, fmt.Errorf("%s %s: %s", method, route, resp.Status)
+	}
+	if failure.Code != "" {
+		return nil, fmt.Errorf("%s (%s)", failure.Message, failure.Code)
+	}
+	return nil, errors.New(failure.Message)
+}
+
+// sendJSON sends in as a JSON body, returning the response as send does
+func (c *client) sendJSON(ctx context.Context, method, route string, query url.Values, in any) (*http.Response, error) {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+	return c.send(ctx, method, route, query, "application/json", bytes.NewReader(data))
+}
+
+// call sends in as a JSON body (none if nil) and decodes the response's data into out (skipped if nil),
+// returning the response message
+func (c *client) call(ctx context.Context, method, route string, query url.Values, in, out any) (string, error) {
+	var resp *http.Response
+	var err error
+	if in != nil {
+		resp, err = c.sendJSON(ctx, method, route, query, in)
+	} else {
+		resp, err = c.send(ctx, method, route, query, "", nil)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// Data holds out, so the decoder fills it in place
+	response := types.APIResponse{Data: out}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("invalid response from %s %s: %w", method, route, err)
+	}
+	return response.Message, nil
+}
+
+// list returns every child of the folder at folderPath in world, following next_cursor across pages
+func (c *client) list(ctx context.Context, world, folderPath string) ([]*types.Node, error) {
+	var nodes []*types.Node
+	request := apimodels.ListChildrenRequest{ParentPath: folderPath, TableName: world, Limit: listPageSize}
+	for {
+		page, err := c.listPage(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		for i := range page.Folders {
+			nodes = append(nodes, &page.Folders[i].Node)
+		}
+		for i := range page.Files {
+			nodes = append(nodes, &page.Files[i].Node)
+		}
+		for i := range page.Symlinks {
+			nodes = append(nodes, &page.Symlinks[i].Node)
+		}
+		if page.NextCursor == "" {
+			return nodes, nil
+		}
+		request.Cursor = page.NextCursor
+	}
+}
+
+// listPage fetches one page of a listing, which the API returns as a bare ListResult
+func (c *client) listPage(ctx context.Context, request apimodels.ListChildrenRequest) (*types.ListResult, error) {
+	resp, err := c.sendJSON(ctx, http.MethodPost, "/items/list", nil, request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var page types.ListResult
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("invalid list response: %w", err)
+	}
+	return &page, nil
+}
+
+// resolve returns the node at p in world by listing its parent folder
+func (c *client) resolve(ctx context.Context, world, p string) (*types.Node, error) {
+	p = cleanPath(p)
+	if p == "/" {
+		var root types.Node
+		if _, err := c.call(ctx, http.MethodGet, "/node/root", nil, nil, &root); err != nil {
+			return nil, err
+		}
+		return &root, nil
+	}
+
+	siblings, err := c.list(ctx, world, path.Dir(p))
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range siblings {
+		if node.Name == path.Base(p) {
+			return node, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: no such file or folder in %s", p, world)
+}
+
+// cleanPath returns p as an absolute, cleaned path
+func cleanPath(p string) string {
+	return path.Clean("/" + p)
+}
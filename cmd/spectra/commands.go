@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	apimodels "github.com/Project-Sylos/Spectra/internal/api/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// runLs lists a folder, one row per child
+func runLs(ctx context.Context, c *client, flags *flag.FlagSet, args []string) error {
+	world := flags.String("world", "primary", "world to list")
+	args = parseArgs(flags, args, 1, 1)
+
+	nodes, err := c.list(ctx, *world, cleanPath(args[0]))
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return printJSON(nodes)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tSIZE\tMODIFIED\tNAME")
+	for _, node := range nodes {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", node.Type, node.Size, node.LastUpdated.Format(time.DateTime), displayName(node))
+	}
+	return w.Flush()
+}
+
+// runTree prints a folder's subtree depth-first, indented by depth, as the walk streams in
+func runTree(ctx context.Context, c *client, flags *flag.FlagSet, args []string) error {
+	world := flags.String("world", "primary", "world to walk")
+	depth := flags.Int("depth", 0, "levels to print below the folder (0 = all)")
+	args = parseArgs(flags, args, 0, 1)
+	start := "/"
+	if len(args) == 1 {
+		start = cleanPath(args[0])
+	}
+
+	request := apimodels.WalkRequest{ParentPath: start, TableName: *world, MaxDepth: *depth}
+	resp, err := c.sendJSON(ctx, http.MethodPost, "/items/walk", nil, request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if !c.json {
+		fmt.Println(start)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		// Each line is a WalkEntry, except the closing {"done": ...} line or an {"error": ...} line
+		var line struct {
+			types.WalkEntry
+			Done      bool   `json:"done"`
+			Count     int    `json:"count"`
+			Truncated bool   `json:"truncated"`
+			Error     string `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return fmt.Errorf("invalid walk response: %w", err)
+		}
+		switch {
+		case line.Error != "":
+			return fmt.Errorf("walk failed: %s", line.Error)
+		case line.Done:
+			if line.Truncated {
+				fmt.Fprintf(os.Stderr, "Stopped after %d nodes (the server's walk cap)\n", line.Count)
+			}
+			return nil
+		case c.json:
+			if err := printJSONLine(line.WalkEntry); err != nil {
+				return err
+			}
+		default:
+			fmt.Printf("%s%s\n", strings.Repeat("  ", line.Depth), displayName(line.Node))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("walk response ended early")
+}
+
+// runStat prints a node's metadata
+func runStat(ctx context.Context, c *client, flags *flag.FlagSet, args []string) error {
+	world := flags.String("world", "primary", "world to look in")
+	args = parseArgs(flags, args, 1, 1)
+
+	node, err := c.resolve(ctx, *world, args[0])
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return printJSON(node)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%s\n", node.ID)
+	fmt.Fprintf(w, "Path:\t%s\n", node.Path)
+	fmt.Fprintf(w, "Type:\t%s\n", node.Type)
+	fmt.Fprintf(w, "Size:\t%d\n", node.Size)
+	fmt.Fprintf(w, "Modified:\t%s\n", node.LastUpdated.Format(time.RFC3339))
+	if node.Checksum != nil {
+		fmt.Fprintf(w, "Checksum:\t%s\n", *node.Checksum)
+	}
+	if node.Target != "" {
+		fmt.Fprintf(w, "Target:\t%s\n", node.Target)
+	}
+	var worlds []string
+	for _, name := range slices.Sorted(maps.Keys(node.ExistenceMap)) {
+		if node.ExistenceMap[name] {
+			worlds = append(worlds, name)
+		}
+	}
+	if len(worlds) > 0 {
+		fmt.Fprintf(w, "Worlds:\t%s\n", strings.Join(worlds, ", "))
+	}
+	for _, key := range slices.Sorted(maps.Keys(node.Metadata)) {
+		fmt.Fprintf(w, "Metadata %s:\t%s\n", key, node.Metadata[key])
+	}
+	return w.Flush()
+}
+
+// runCat writes a file's content to stdout
+func runCat(ctx context.Context, c *client, flags *flag.FlagSet, args []string) error {
+	world := flags.String("world", "primary", "world to look in")
+	args = parseArgs(flags, args, 1, 1)
+
+	node, err := c.resolve(ctx, *world, args[0])
+	if err != nil {
+		return err
+	}
+	if node.Type != types.NodeTypeFile {
+		return fmt.Errorf("%s is a %s, not a file", node.Path, node.Type)
+	}
+
+	resp, err := c.send(ctx, http.MethodGet, "/items/"+url.PathEscape(node.ID)+"/raw", nil, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(os.Stdout, resp.Body)
+	return err
+}
+
+// runMkdir creates a folder in primary; its parent must exist
+func runMkdir(ctx context.Context, c *client, flags *flag.FlagSet, args []string) error {
+	args = parseArgs(flags, args, 1, 1)
+	target := cleanPath(args[0])
+
+	var folder types.Node
+	request := apimodels.CreateFolderRequest{ParentPath: path.Dir(target), TableName: "primary", Name: path.Base(target)}
+	message, err := c.call(ctx, http.MethodPost, "/items/folder", nil, request, &folder)
+	if err != nil {
+		return err
+	}
+	return c.report(message, &folder)
+}
+
+// runUpload uploads a local file to primary; a remote path ending in / is a folder to upload into
+func runUpload(ctx context.Context, c *client, flags *flag.FlagSet, args []string) error {
+	overwrite := flags.Bool("overwrite", false, "replace the content of a file already at the remote path")
+	args = parseArgs(flags, args, 2, 2)
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	remote := args[1]
+	if strings.HasSuffix(remote, "/") {
+		remote += path.Base(args[0])
+	}
+	remote = cleanPath(remote)
+
+	var file types.Node
+	request := apimodels.UploadFileRequest{
+		ParentPath: path.Dir(remote),
+		TableName:  "primary",
+		Name:       path.Base(remote),
+		Data:       data,
+		Overwrite:  *overwrite,
+	}
+	message, err := c.call(ctx, http.MethodPost, "/items/file", nil, request, &file)
+	if err != nil {
+		return err
+	}
+	return c.report(message, &file)
+}
+
+// runRm deletes a node from primary
+func runRm(ctx context.Context, c *client, flags *flag.FlagSet, args []string) error {
+	recursive := flags.Bool("recursive", false, "delete a non-empty folder and everything below it")
+	args = parseArgs(flags, args, 1, 1)
+
+	node, err := c.resolve(ctx, "primary", args[0])
+	if err != nil {
+		return err
+	}
+	query := url.Values{}
+	if *recursive {
+		query.Set("recursive", "true")
+	}
+	message, err := c.call(ctx, http.MethodDelete, "/node/"+url.PathEscape(node.ID), query, nil, nil)
+	if err != nil {
+		return err
+	}
+	return c.report(message, nil)
+}
+
+// runReset deletes every node but the root
+func runReset(ctx context.Context, c *client, flags *flag.FlagSet, args []string) error {
+	parseArgs(flags, args, 0, 0)
+
+	message, err := c.call(ctx, http.MethodPost, "/reset", nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	return c.report(message, nil)
+}
+
+// runExport streams a snapshot to stdout or a file
+func runExport(ctx context.Context, c *client, flags *flag.FlagSet, args []string) error {
+	format := flags.String("format", "jsonl", "snapshot format: jsonl or json")
+	out := flags.String("out", "", "destination file (default stdout)")
+	parseArgs(flags, args, 0, 0)
+
+	resp, err := c.send(ctx, http.MethodGet, "/export", url.Values{"format": {*format}}, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// runImport streams a snapshot from stdin or a file to the server
+func runImport(ctx context.Context, c *client, flags *flag.FlagSet, args []string) error {
+	merge := flags.Bool("merge", false, "import into a non-empty database, skipping nodes that already exist")
+	in := flags.String("in", "", "snapshot file (default stdin)")
+	parseArgs(flags, args, 0, 0)
+
+	r := io.Reader(os.Stdin)
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	resp, err := c.send(ctx, http.MethodPost, "/import", url.Values{"merge": {strconv.FormatBool(*merge)}}, "application/x-ndjson", r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result types.ImportResult
+	if err := json.NewDecoder(resp.Body).Decode(&types.APIResponse{Data: &result}); err != nil {
+		return fmt.Errorf("invalid import response: %w", err)
+	}
+	if c.json {
+		return printJSON(&result)
+	}
+	fmt.Printf("Imported %d nodes (%d skipped)\n", result.Imported, result.Skipped)
+	return nil
+}
+
+// report prints the result of a write: the node (if any) as JSON, or the server's message
+func (c *client) report(message string, node *types.Node) error {
+	if c.json {
+		if node == nil {
+			return printJSON(map[string]string{"message": message})
+		}
+		return printJSON(node)
+	}
+	if node != nil {
+		fmt.Printf("%s: %s\n", message, node.Path)
+		return nil
+	}
+	fmt.Println(message)
+	return nil
+}
+
+// displayName is a node's name as ls and tree show it: folders end in / and symlinks show their target
+func displayName(node *types.Node) string {
+	switch node.Type {
+	case types.NodeTypeFolder:
+		return node.Name + "/"
+	case types.NodeTypeSymlink:
+		return node.Name + " -> " + node.Target
+	}
+	return node.Name
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// printJSONLine writes v to stdout as one line of JSON
+func printJSONLine(v any) error {
+	return json.NewEncoder(os.Stdout).Encode(v)
+}
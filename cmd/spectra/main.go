@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+)
+
+// command is one spectra subcommand
+type command struct {
+	name    string
+	usage   string // Arguments shown after the name
+	summary string
+	run     func(ctx context.Context, c *client, flags *flag.FlagSet, args []string) error
+}
+
+var commands = []command{
+	{"ls", "[-world name] <path>", "list a folder", runLs},
+	{"tree", "[-world name] [-depth n] [path]", "print a folder's subtree", runTree},
+	{"stat", "[-world name] <path>", "show a node's metadata", runStat},
+	{"cat", "[-world name] <path>", "write a file's content to stdout", runCat},
+	{"mkdir", "<path>", "create a folder in primary", runMkdir},
+	{"upload", "[-overwrite] <local> <remote>", "upload a local file to primary", runUpload},
+	{"rm", "[-recursive] <path>", "delete a node from primary", runRm},
+	{"reset", "", "delete everything but the root", runReset},
+	{"export", "[-format jsonl|json] [-out file]", "download a snapshot", runExport},
+	{"import", "[-merge] [-in file]", "load a snapshot", runImport},
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("spectra: ")
+
+	c := &client{http: &http.Client{}}
+	flag.StringVar(&c.server, "server", envOr("SPECTRA_SERVER", "http://localhost:8086"), "server URL (env SPECTRA_SERVER)")
+	flag.StringVar(&c.token, "token", os.Getenv("SPECTRA_TOKEN"), "API token, when the server requires auth (env SPECTRA_TOKEN)")
+	flag.StringVar(&c.instance, "instance", "", "named instance to address instead of the main filesystem")
+	flag.BoolVar(&c.json, "json", false, "print JSON instead of tables")
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintln(out, "Usage: spectra [flags] <command> [args]")
+		fmt.Fprintln(out, "\nCommands:")
+		for _, cmd := range commands {
+			fmt.Fprintf(out, "  %-7s %-36s %s\n", cmd.name, cmd.usage, cmd.summary)
+		}
+		fmt.Fprintln(out, "\nFlags:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	for _, cmd := range commands {
+		if cmd.name == flag.Arg(0) {
+			if err := cmd.run(ctx, c, c.newFlags(cmd), flag.Args()[1:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+	flag.Usage()
+	os.Exit(2)
+}
+
+// newFlags returns the flag set of a subcommand, which also accepts -json
+func (c *client) newFlags(cmd command) *flag.FlagSet {
+	flags := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+	flags.BoolVar(&c.json, "json", c.json, "print JSON instead of tables")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: spectra %s %s\n", cmd.name, cmd.usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseArgs parses args with flags, accepting flags after the positional arguments as well as before
+// (spectra ls / -world s1), and checks that between min and max positional arguments are left
+func parseArgs(flags *flag.FlagSet, args []string, min, max int) []string {
+	var positional []string
+	for {
+		flags.Parse(args)
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if len(positional) < min || len(positional) > max {
+		flags.Usage()
+		os.Exit(2)
+	}
+	return positional
+}
+
+// envOr returns the environment variable key, or fallback when it is unset
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
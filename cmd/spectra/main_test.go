@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/api"
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// newClient starts an API server over an in-memory filesystem and returns a client for it
+func newClient(t *testing.T) (*client, *sdk.SpectraFS) {
+	t.Helper()
+	cfg := sdk.DefaultConfig()
+	cfg.Seed.DBPath = sdk.MemoryDBPath
+	cfg.Seed.MaxDepth = 2
+	fs, err := sdk.NewFromConfig(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(api.NewRouter(fs).SetupRoutes())
+	t.Cleanup(func() {
+		server.Close()
+		fs.Close()
+	})
+	return &client{server: server.URL, http: server.Client()}, fs
+}
+
+// run runs the named command with args and returns what it printed to stdout
+func run(t *testing.T, c *client, name string, args ...string) (string, error) {
+	t.Helper()
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		out, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer out.Close()
+		stdout := os.Stdout
+		os.Stdout = out
+		err = cmd.run(context.Background(), c, c.newFlags(cmd), args)
+		os.Stdout = stdout
+		c.json = false
+
+		printed, readErr := os.ReadFile(out.Name())
+		if readErr != nil {
+			t.Fatal(readErr)
+		}
+		return string(printed), err
+	}
+	t.Fatalf("no command %s", name)
+	return "", nil
+}
+
+func TestCommandsAgainstServer(t *testing.T) {
+	c, _ := newClient(t)
+
+	if _, err := run(t, c, "mkdir", "/t"); err != nil {
+		t.Fatal(err)
+	}
+	local := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(local, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := run(t, c, "upload", local, "/t/"); err != nil || !strings.Contains(out, "/t/a.txt") {
+		t.Fatalf("upload = %q, %v", out, err)
+	}
+
+	out, err := run(t, c, "ls", "/t")
+	if err != nil || !strings.Contains(out, "NAME") || !strings.Contains(out, "a.txt") {
+		t.Fatalf("ls /t = %q, %v", out, err)
+	}
+	out, err = run(t, c, "stat", "/t/a.txt", "-json")
+	var node types.Node
+	if err != nil || json.Unmarshal([]byte(out), &node) != nil || node.Path != "/t/a.txt" || node.Type != types.NodeTypeFile {
+		t.Fatalf("stat -json = %q, %v", out, err)
+	}
+	if out, err := run(t, c, "cat", "/t/a.txt"); err != nil || int64(len(out)) != node.Size {
+		t.Errorf("cat printed %d bytes, %v, want the file's %d", len(out), err, node.Size)
+	}
+	if out, err := run(t, c, "tree", "/t"); err != nil || !strings.Contains(out, "  a.txt") {
+		t.Errorf("tree /t = %q, %v", out, err)
+	}
+
+	// Failures carry the server's message
+	if _, err := run(t, c, "rm", "/t"); err == nil || !strings.Contains(err.Error(), "(") {
+		t.Errorf("rm of a non-empty folder = %v, want the server's error and code", err)
+	}
+	if _, err := run(t, c, "rm", "-recursive", "/t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run(t, c, "stat", "/t"); err == nil {
+		t.Error("stat of a removed folder succeeded")
+	}
+	if _, err := run(t, c, "mkdir", "/missing/x"); err == nil {
+		t.Error("mkdir under a missing folder succeeded")
+	}
+}
+
+func TestLsPagesThroughLargeFolders(t *testing.T) {
+	c, fs := newClient(t)
+	folder, err := fs.CreateFolderContext(context.Background(), &sdk.CreateFolderRequest{ParentPath: "/", TableName: "primary", Name: "big"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := make([]sdk.BatchOp, listPageSize+5)
+	for i := range ops {
+		ops[i] = sdk.BatchOp{Op: "file", ParentID: folder.ID, Name: fmt.Sprintf("f%04d", i), Data: []byte("x")}
+	}
+	for _, batch := range [][]sdk.BatchOp{ops[:listPageSize], ops[listPageSize:]} {
+		if _, err := fs.BatchCreate(context.Background(), batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := run(t, c, "ls", "-json", "/big")
+	var nodes []types.Node
+	if err != nil || json.Unmarshal([]byte(out), &nodes) != nil {
+		t.Fatalf("ls -json /big: %v", err)
+	}
+	if len(nodes) != len(ops) || nodes[len(nodes)-1].Name != fmt.Sprintf("f%04d", len(ops)-1) {
+		t.Errorf("ls listed %d children, want all %d across pages", len(nodes), len(ops))
+	}
+}
+
+func TestAuthTokenSent(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = req.Header.Get("Authorization")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(types.APIResponse{Message: "token rejected", Code: "unauthorized"})
+	}))
+	defer server.Close()
+
+	c := &client{server: server.URL, token: "secret", http: server.Client()}
+	_, err := run(t, c, "reset")
+	if got != "Bearer secret" {
+		t.Errorf("Authorization = %q, want the bearer token", got)
+	}
+	if err == nil || err.Error() != "token rejected (unauthorized)" {
+		t.Errorf("reset against a rejecting server = %v", err)
+	}
+}
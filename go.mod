@@ -6,6 +6,5 @@ require (
 	github.com/go-chi/chi/v5 v5.2.3
 	github.com/google/uuid v1.6.0
 	go.etcd.io/bbolt v1.3.10
+	golang.org/x/sys v0.4.0
 )
-
-require golang.org/x/sys v0.4.0 // indirect
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// send makes a request with the given headers and returns its status and, for a JSON response,
+// the envelope's error code
+func send(t *testing.T, server *httptest.Server, method, path string, header map[string]string, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var envelope types.APIResponse
+	json.NewDecoder(resp.Body).Decode(&envelope)
+	return resp.StatusCode, envelope.Code
+}
+
+// bearer returns the header sending token as a bearer token
+func bearer(token string) map[string]string {
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+func TestAuthRoleBoundaries(t *testing.T) {
+	server, _ := newServer(t, func(cfg *sdk.Config) {
+		cfg.API.Auth.Tokens = []sdk.AuthToken{
+			{Token: "r", Role: sdk.RoleRead},
+			{Token: "w", Role: sdk.RoleWrite},
+			{Token: "a", Role: sdk.RoleAdmin},
+		}
+	})
+
+	list := func(int) (string, string, string) {
+		return http.MethodPost, "/api/v1/items/list", `{"parent_id": "root"}`
+	}
+	folder := func(i int) (string, string, string) {
+		return http.MethodPost, "/api/v1/items/folder", fmt.Sprintf(`{"parent_id": "root", "name": "f%d"}`, i)
+	}
+	reset := func(int) (string, string, string) {
+		return http.MethodPost, "/api/v1/reset", `{}`
+	}
+
+	for i, tc := range []struct {
+		name    string
+		request func(int) (string, string, string)
+		header  map[string]string
+		allowed bool
+		code    string // Expected error code when refused
+	}{
+		{"no token", list, nil, false, "unauthorized"},
+		{"unknown token", list, bearer("nope"), false, "unauthorized"},
+		{"malformed header", list, map[string]string{"Authorization": "Token r"}, false, "unauthorized"},
+		{"read lists", list, bearer("r"), true, ""},
+		{"read key lists", list, map[string]string{"X-API-Key": "r"}, true, ""},
+		{"read creates", folder, bearer("r"), false, "forbidden"},
+		{"write creates", folder, bearer("w"), true, ""},
+		{"write lists", list, bearer("w"), true, ""},
+		{"write resets", reset, bearer("w"), false, "forbidden"},
+		{"admin resets", reset, bearer("a"), true, ""},
+		{"admin creates", folder, bearer("a"), true, ""},
+	} {
+		method, path, body := tc.request(i)
+		status, code := send(t, server, method, path, tc.header, body)
+		if tc.allowed {
+			if status >= 300 {
+				t.Errorf("%s: %s %s = %d %q, want success", tc.name, method, path, status, code)
+			}
+			continue
+		}
+		want := http.StatusForbidden
+		if tc.code == "unauthorized" {
+			want = http.StatusUnauthorized
+		}
+		if status != want || code != tc.code {
+			t.Errorf("%s: %s %s = %d %q, want %d %q", tc.name, method, path, status, code, want, tc.code)
+		}
+	}
+
+	if status, _ := send(t, server, http.MethodGet, "/health/live", nil, ""); status != http.StatusOK {
+		t.Errorf("health check without a token = %d, want 200", status)
+	}
+}
+
+func TestAuthOpenWithoutTokens(t *testing.T) {
+	server, _ := newServer(t, func(*sdk.Config) {})
+	if status, code := send(t, server, http.MethodPost, "/api/v1/reset", nil, `{}`); status >= 300 {
+		t.Errorf("reset on an open API = %d %q, want success", status, code)
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+func TestChangesEndpoint(t *testing.T) {
+	server, _ := newServer(t, func(*sdk.Config) {})
+	for _, name := range []string{"a", "b", "c"} {
+		if status, resp := post(t, server, "/api/v1/items/folder", `{"parent_path": "/", "table_name": "primary", "name": "`+name+`"}`, nil); status != http.StatusCreated {
+			t.Fatalf("create %s = %d %+v", name, status, resp)
+		}
+	}
+
+	changes := func(query string) types.ChangeFeed {
+		t.Helper()
+		resp, err := http.Get(server.URL + "/api/v1/changes" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var envelope struct {
+			Data types.ChangeFeed `json:"data"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET /api/v1/changes%s = %d, %v", query, resp.StatusCode, err)
+		}
+		return envelope.Data
+	}
+
+	first := changes("?limit=2")
+	if len(first.Events) != 2 || !first.HasMore || first.NextCursor != 2 || first.Events[0].Path != "/a" {
+		t.Fatalf("first page = %+v", first)
+	}
+	rest := changes("?since=2")
+	if len(rest.Events) != 1 || rest.HasMore || rest.Events[0].Path != "/c" || rest.Events[0].Op != types.ChangeCreate {
+		t.Fatalf("page after 2 = %+v", rest)
+	}
+	if empty := changes("?since=3"); len(empty.Events) != 0 || empty.NextCursor != 3 {
+		t.Errorf("page after the last event = %+v", empty)
+	}
+
+	for _, query := range []string{"?since=-1", "?since=x", "?limit=x", "?limit=100000"} {
+		if status, code := send(t, server, http.MethodGet, "/api/v1/changes"+query, nil, ""); status != http.StatusBadRequest {
+			t.Errorf("GET /api/v1/changes%s = %d %s, want 400", query, status, code)
+		}
+	}
+}
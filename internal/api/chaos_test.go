@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/api/middleware"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+func TestChaosEndpointAndBypassHeader(t *testing.T) {
+	server, _ := newServer(t, func(*sdk.Config) {})
+	list := func(header map[string]string) (int, string) {
+		return send(t, server, http.MethodPost, "/api/v1/items/list", header, `{"parent_id": "root"}`)
+	}
+
+	status, _ := post(t, server, "/api/v1/chaos", `{"seed": 1, "operations": {"list": {"error_rate": 1, "error_code": 502}}}`, nil)
+	if status != http.StatusOK {
+		t.Fatalf("POST /chaos = %d, want 200", status)
+	}
+	if status, code := list(nil); status != http.StatusBadGateway || code != "chaos_injected" {
+		t.Errorf("list with chaos = %d %s, want 502 chaos_injected", status, code)
+	}
+	if status, _ := list(map[string]string{middleware.NoChaosHeader: "1"}); status != http.StatusOK {
+		t.Errorf("list with %s = %d, want 200", middleware.NoChaosHeader, status)
+	}
+
+	if status, _ := post(t, server, "/api/v1/chaos", `{"operations": {"list": {"error_rate": 2}}}`, nil); status != http.StatusBadRequest {
+		t.Errorf("POST /chaos with error_rate 2 = %d, want 400", status)
+	}
+	if status, _ := post(t, server, "/api/v1/chaos", `{}`, nil); status != http.StatusOK {
+		t.Fatalf("POST /chaos to turn it off = %d, want 200", status)
+	}
+	if status, _ := list(nil); status != http.StatusOK {
+		t.Errorf("list after chaos was turned off = %d, want 200", status)
+	}
+}
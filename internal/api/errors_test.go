@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+func TestErrorStatusAndCode(t *testing.T) {
+	server, _ := newServer(t, func(*sdk.Config) {})
+
+	for _, tc := range []struct {
+		name, method, path, body string
+		status                   int
+		code                     string
+	}{
+		{"missing parent", http.MethodPost, "/api/v1/items/folder", `{"parent_id": "p-missing", "name": "x"}`, http.StatusNotFound, "parent_not_found"},
+		{"missing node", http.MethodGet, "/api/v1/node/p-missing", "", http.StatusNotFound, "node_not_found"},
+		{"unknown world", http.MethodPost, "/api/v1/items/list", `{"parent_id": "root", "table_name": "nope"}`, http.StatusBadRequest, "unknown_world"},
+		{"malformed body", http.MethodPost, "/api/v1/items/folder", `{`, http.StatusBadRequest, "invalid_input"},
+		{"created", http.MethodPost, "/api/v1/items/folder", `{"parent_id": "root", "name": "made"}`, http.StatusCreated, ""},
+		{"duplicate", http.MethodPost, "/api/v1/items/folder", `{"parent_id": "root", "name": "made"}`, http.StatusConflict, "path_exists"},
+		{"delete root", http.MethodDelete, "/api/v1/node/root", "", http.StatusForbidden, "root_protected"},
+	} {
+		status, code := send(t, server, tc.method, tc.path, nil, tc.body)
+		if status != tc.status || code != tc.code {
+			t.Errorf("%s: %s %s = %d %q, want %d %q", tc.name, tc.method, tc.path, status, code, tc.status, tc.code)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// fsRequest sends a request to a /fs path and returns the status and body
+func fsRequest(t *testing.T, server *httptest.Server, method, path, body string) (int, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, data
+}
+
+func TestFSRoutesByPath(t *testing.T) {
+	server, fs := newServer(t, func(*sdk.Config) {})
+	folders := rootFolders(t, fs)
+
+	status, body := fsRequest(t, server, http.MethodGet, "/fs/primary/", "")
+	var entries []sdk.Node
+	if err := json.Unmarshal(body, &entries); status != http.StatusOK || err != nil {
+		t.Fatalf("GET /fs/primary/ = %d %v", status, err)
+	}
+	if len(entries) == 0 || entries[0].Name != folders[0] {
+		t.Errorf("root listing starts with %v, want folder %s", entries, folders[0])
+	}
+
+	// A trailing slash names a folder, its absence a file
+	if status, _ := fsRequest(t, server, http.MethodGet, "/fs/primary/"+folders[0], ""); status != http.StatusNotFound {
+		t.Errorf("GET of a folder without a trailing slash = %d, want 404", status)
+	}
+
+	if status, _ := fsRequest(t, server, http.MethodPut, "/fs/primary/new/", ""); status != http.StatusCreated {
+		t.Fatalf("PUT folder = %d, want 201", status)
+	}
+	status, body = fsRequest(t, server, http.MethodPut, "/fs/primary/new/a.txt", "hello")
+	var written struct {
+		Data sdk.Node `json:"data"`
+	}
+	if err := json.Unmarshal(body, &written); status != http.StatusCreated || err != nil {
+		t.Fatalf("PUT file = %d %v, want 201", status, err)
+	}
+	// The stored file serves its deterministic content
+	if status, body := fsRequest(t, server, http.MethodGet, "/fs/primary/new/a.txt", ""); status != http.StatusOK || int64(len(body)) != written.Data.Size {
+		t.Errorf("GET uploaded file = %d with %d bytes, want 200 with %d", status, len(body), written.Data.Size)
+	}
+	if status, _ := fsRequest(t, server, http.MethodGet, "/fs/primary/new/a.txt/", ""); status != http.StatusNotFound {
+		t.Errorf("GET of a file with a trailing slash = %d, want 404", status)
+	}
+
+	if status, _ := fsRequest(t, server, http.MethodPut, "/fs/s1/b.txt", "x"); status != http.StatusMethodNotAllowed {
+		t.Errorf("PUT to s1 = %d, want 405", status)
+	}
+	if status, _ := fsRequest(t, server, http.MethodGet, "/fs/nope/", ""); status != http.StatusNotFound {
+		t.Errorf("GET in an unknown world = %d, want 404", status)
+	}
+
+	if status, _ := fsRequest(t, server, http.MethodDelete, "/fs/primary/new/", ""); status == http.StatusOK {
+		t.Error("DELETE of a non-empty folder without recursive succeeded")
+	}
+	if status, _ := fsRequest(t, server, http.MethodDelete, "/fs/primary/new/?recursive=true", ""); status != http.StatusOK {
+		t.Errorf("recursive DELETE = %d, want 200", status)
+	}
+	if status, _ := fsRequest(t, server, http.MethodGet, "/fs/primary/new/a.txt", ""); status != http.StatusNotFound {
+		t.Errorf("GET after delete = %d, want 404", status)
+	}
+}
@@ -1,30 +1,191 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/Project-Sylos/Spectra/internal/api/middleware"
 	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
 )
 
+// Error codes sent in APIResponse.Code for each failure category
+const (
+	CodeInvalidInput  = "invalid_input"
+	CodeNotFound      = "not_found"
+	CodeConflict      = "conflict"
+	CodeRootProtected = "root_protected"
+	CodeReadOnly      = "read_only"
+	CodeInternal      = "internal_error"
+	CodeTooLarge      = "upload_too_large"
+	CodeNotReady      = "not_ready"
+)
+
+// errorCategories maps the sentinel categories to a status and fallback code; anything else is a 500
+var errorCategories = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{sdk.ErrRootProtected, http.StatusForbidden, CodeRootProtected},
+	{sdk.ErrReadOnly, http.StatusForbidden, CodeReadOnly},
+	{sdk.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{sdk.ErrInvalidInput, http.StatusBadRequest, CodeInvalidInput},
+	{sdk.ErrConflict, http.StatusConflict, CodeConflict},
+}
+
+// errorCodes gives the sentinels clients most often act on a code of their own; they are checked in
+// order, so wrappers (ErrParentNotFound wraps ErrNodeNotFound) come before what they wrap
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{sdk.ErrParentNotFound, "parent_not_found"},
+	{sdk.ErrNodeNotFound, "node_not_found"},
+	{sdk.ErrUnknownWorld, "unknown_world"},
+	{sdk.ErrUnknownBucket, "unknown_bucket"},
+	{sdk.ErrPathExists, "path_exists"},
+	{sdk.ErrFolderNotEmpty, "folder_not_empty"},
+	{sdk.ErrWorldExists, "world_exists"},
+	{sdk.ErrDatabaseNotEmpty, "database_not_empty"},
+	{sdk.ErrConfigMismatch, "config_mismatch"},
+	{sdk.ErrInvalidConfig, "invalid_config"},
+	{sdk.ErrGenerationRunning, "generation_running"},
+	{sdk.ErrMutationsRunning, "mutations_running"},
+	{sdk.ErrNoMutationCandidates, "no_mutation_candidates"},
+	{sdk.ErrInvalidCursor, "invalid_cursor"},
+	{sdk.ErrCursorExpired, "cursor_expired"},
+	{sdk.ErrInvalidName, "invalid_name"},
+	{sdk.ErrInvalidWorld, "invalid_world"},
+	{sdk.ErrAncestorMissing, "ancestor_missing"},
+	{sdk.ErrUnknownInstance, "unknown_instance"},
+	{sdk.ErrInstanceExists, "instance_exists"},
+	{sdk.ErrInvalidInstance, "invalid_instance"},
+	{sdk.ErrNotAFile, "not_a_file"},
+	{sdk.ErrInvalidRange, "invalid_range"},
+	{sdk.ErrMoveTargetNotDir, "not_a_folder"},
+	{sdk.ErrWalkTargetNotDir, "not_a_folder"},
+	{sdk.ErrMoveIntoDescendant, "move_into_descendant"},
+	{sdk.ErrMoveWorldMismatch, "move_world_mismatch"},
+	{sdk.ErrInvalidSnapshot, "invalid_snapshot"},
+	{sdk.ErrUnknownFormat, "unknown_format"},
+	{sdk.ErrSnapshotNotFound, "snapshot_not_found"},
+	{sdk.ErrSnapshotExists, "snapshot_exists"},
+	{sdk.ErrInvalidSnapshotName, "invalid_snapshot_name"},
+	{sdk.ErrInvalidChecksum, "invalid_checksum"},
+	{sdk.ErrCompactUnsupported, "compact_unsupported"},
+	{sdk.ErrJobNotFound, "job_not_found"},
+}
+
+// classifyError returns the status and code for err: 403/404/400/409 by category, 413 for an
+// upload over the limit, 500 otherwise
+func classifyError(err error) (int, string) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge, CodeTooLarge
+	}
+	status, code := http.StatusInternalServerError, CodeInternal
+	for _, category := range errorCategories {
+		if errors.Is(err, category.err) {
+			status, code = category.status, category.code
+			break
+		}
+	}
+	if status == http.StatusInternalServerError {
+		return status, code
+	}
+	for _, specific := range errorCodes {
+		if errors.Is(err, specific.err) {
+			return status, specific.code
+		}
+	}
+	return status, code
+}
+
+// ErrorForCode returns the sentinel an error code stands for, so HTTP clients can match failures with
+// errors.Is as SDK callers do; nil for codes without one (internal_error, upload_too_large, ...)
+func ErrorForCode(code string) error {
+	for _, specific := range errorCodes {
+		if specific.code == code {
+			return specific.err
+		}
+	}
+	for _, category := range errorCategories {
+		if category.code == code {
+			return category.err
+		}
+	}
+	return nil
+}
+
+// codeForStatus is the code of an error response the handler raised itself (e.g. a malformed body)
+func codeForStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return CodeInvalidInput
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusRequestEntityTooLarge:
+		return CodeTooLarge
+	case http.StatusServiceUnavailable:
+		return "unavailable"
+	}
+	return CodeInternal
+}
+
+// queryBool parses the boolean query parameter name, false when it is absent
+func queryBool(req *http.Request, name string) (bool, error) {
+	raw := req.URL.Query().Get(name)
+	if raw == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean", name)
+	}
+	return value, nil
+}
+
 // BaseHandler provides common functionality for all API handlers
 type BaseHandler struct{}
 
 // sendJSON sends a JSON response with the given status code and data
 func (h *BaseHandler) sendJSON(w http.ResponseWriter, statusCode int, data any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(data)
+	middleware.WriteJSON(w, statusCode, data)
+}
+
+// decodeJSON decodes the JSON request body into target, in the casing the client selected
+// An empty body returns io.EOF, which handlers with optional bodies ignore
+func decodeJSON(req *http.Request, target any) error {
+	return middleware.DecodeJSON(req, target)
 }
 
 // sendError sends an error response with the given status code and message
 func (h *BaseHandler) sendError(w http.ResponseWriter, statusCode int, message string) {
 	h.sendJSON(w, statusCode, types.APIResponse{
 		Success: false,
+		Code:    codeForStatus(statusCode),
 		Message: message,
 	})
 }
 
+// sendFailure sends err from the SDK as an error response classified by classifyError, with the
+// message prefixed by what failed (e.g. "Failed to create folder")
+func (h *BaseHandler) sendFailure(w http.ResponseWriter, action string, err error) {
+	statusCode, code := classifyError(err)
+	h.sendJSON(w, statusCode, types.APIResponse{
+		Success: false,
+		Code:    code,
+		Message: fmt.Sprintf("%s: %v", action, err),
+	})
+}
+
 // sendSuccess sends a success response with the given data
 func (h *BaseHandler) sendSuccess(w http.ResponseWriter, message string, data any) {
 	h.sendJSON(w, http.StatusOK, types.APIResponse{
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	apimodels "github.com/Project-Sylos/Spectra/internal/api/models"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// ChaosHandler reads and replaces the chaos rules without a restart
+// Its own routes are never subject to chaos
+type ChaosHandler struct {
+	BaseHandler
+	fs *sdk.SpectraFS
+}
+
+// NewChaosHandler creates a new chaos handler
+func NewChaosHandler(fs *sdk.SpectraFS) *ChaosHandler {
+	return &ChaosHandler{
+		fs: fs,
+	}
+}
+
+// GetChaos returns the chaos rules currently in force
+func (h *ChaosHandler) GetChaos(w http.ResponseWriter, req *http.Request) {
+	h.sendSuccess(w, "Chaos settings retrieved successfully", h.fs.ChaosSettings())
+}
+
+// SetChaos replaces the chaos rules; a body without operations turns chaos off
+func (h *ChaosHandler) SetChaos(w http.ResponseWriter, req *http.Request) {
+	var apiRequest apimodels.ChaosRequest
+	if err := decodeJSON(req, &apiRequest); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rules := sdk.ChaosConfig{
+		Seed:       apiRequest.Seed,
+		Operations: make(map[string]sdk.ChaosRule, len(apiRequest.Operations)),
+	}
+	for op, rule := range apiRequest.Operations {
+		rules.Operations[op] = sdk.ChaosRule{
+			LatencyMS: rule.LatencyMS,
+			ErrorRate: rule.ErrorRate,
+			ErrorCode: rule.ErrorCode,
+		}
+	}
+	if err := h.fs.SetChaos(rules); err != nil {
+		h.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	message := "Chaos disabled"
+	if len(rules.Operations) > 0 {
+		message = fmt.Sprintf("Chaos enabled for %d operation rule(s)", len(rules.Operations))
+	}
+	h.sendSuccess(w, message, h.fs.ChaosSettings())
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+	"github.com/go-chi/chi/v5"
+)
+
+// DebugHandler serves raw database contents for diagnosing index problems
+// Routes are only mounted when debug.expose_buckets is set
+type DebugHandler struct {
+	BaseHandler
+	fs *sdk.SpectraFS
+}
+
+// NewDebugHandler creates a new debug handler
+func NewDebugHandler(fs *sdk.SpectraFS) *DebugHandler {
+	return &DebugHandler{
+		fs: fs,
+	}
+}
+
+// ListBuckets lists every bucket with its key count
+func (h *DebugHandler) ListBuckets(w http.ResponseWriter, req *http.Request) {
+	buckets, err := h.fs.DebugBuckets()
+	if err != nil {
+		h.sendDebugError(w, req, err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("%d bucket(s)", len(buckets)), buckets)
+}
+
+// ScanBucket returns raw key/value pairs from one bucket
+// Query parameters: prefix (key prefix), after (next_after from the previous page), limit
+func (h *DebugHandler) ScanBucket(w http.ResponseWriter, req *http.Request) {
+	name := chi.URLParam(req, "name")
+	query := req.URL.Query()
+
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			h.sendError(w, http.StatusBadRequest, "limit must be a non-negative integer")
+			return
+		}
+		limit = parsed
+	}
+
+	page, err := h.fs.DebugScanBucket(name, query.Get("prefix"), query.Get("after"), limit)
+	if err != nil {
+		h.sendDebugError(w, req, err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("%d entr(ies) from bucket %s", len(page.Entries), name), page)
+}
+
+// sendDebugError maps debug errors to responses; a disabled endpoint is reported as 404
+func (h *DebugHandler) sendDebugError(w http.ResponseWriter, req *http.Request, err error) {
+	if errors.Is(err, sdk.ErrDebugDisabled) {
+		http.NotFound(w, req)
+		return
+	}
+	h.sendFailure(w, "Failed to read bucket", err)
+}
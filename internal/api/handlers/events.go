@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// EventsPath is the Server-Sent Events stream; the router exempts it from the request timeout
+const EventsPath = "/api/v1/events"
+
+// eventsKeepAlive is the interval between comment lines that keep idle event streams open through proxies
+const eventsKeepAlive = 15 * time.Second
+
+// EventsHandler streams live filesystem events
+type EventsHandler struct {
+	BaseHandler
+	fs   *sdk.SpectraFS
+	done <-chan struct{} // Closed when the server shuts down
+}
+
+// NewEventsHandler creates a new events handler whose streams end when done is closed
+func NewEventsHandler(fs *sdk.SpectraFS, done <-chan struct{}) *EventsHandler {
+	return &EventsHandler{
+		fs:   fs,
+		done: done,
+	}
+}
+
+// Stream serves live events as Server-Sent Events until the client disconnects
+// Each event is sent as "event: <op>" with its JSON as data, and journaled changes carry their
+// sequence number as the SSE id. A client that falls too far behind is sent a final "dropped"
+// event and disconnected; it should catch up from /api/v1/changes?since=<last id> and reconnect
+func (h *EventsHandler) Stream(w http.ResponseWriter, req *http.Request) {
+	events, err := h.fs.Subscribe(req.Context())
+	if err != nil {
+		h.sendError(w, http.StatusServiceUnavailable, fmt.Sprintf("Failed to subscribe: %v", err))
+		return
+	}
+
+	// Streams outlive the server's write timeout
+	controller := http.NewResponseController(w)
+	controller.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	controller.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				if req.Context().Err() == nil {
+					fmt.Fprint(w, "event: dropped\ndata: {}\n\n")
+					controller.Flush()
+				}
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if event.Seq > 0 {
+				fmt.Fprintf(w, "id: %d\n", event.Seq)
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Op, data); err != nil {
+				return
+			}
+			controller.Flush()
+
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			controller.Flush()
+
+		case <-req.Context().Done():
+			return
+
+		case <-h.done:
+			return
+		}
+	}
+}
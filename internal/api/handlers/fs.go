@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	spectrafsmodels "github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+	"github.com/go-chi/chi/v5"
+)
+
+// FSHandler serves worlds by path, like an object store: /fs/{world}/some/folder/ lists a folder
+// and /fs/{world}/some/file.txt is a file. A trailing slash names a folder, its absence a file,
+// and a node of the other type is reported missing. Writes are only accepted for primary.
+type FSHandler struct {
+	BaseHandler
+	fs *sdk.SpectraFS
+}
+
+// NewFSHandler creates a new path-based handler
+func NewFSHandler(fs *sdk.SpectraFS) *FSHandler {
+	return &FSHandler{
+		fs: fs,
+	}
+}
+
+// fsTarget is the node named by a /fs request URL
+type fsTarget struct {
+	world  string
+	name   string // fs.FS name ("." for the root)
+	folder bool   // The URL ended in a slash (or named the root)
+}
+
+// parseTarget reads the world and path of a /fs request, writing an error response if they are invalid
+func (h *FSHandler) parseTarget(w http.ResponseWriter, req *http.Request) (*fsTarget, bool) {
+	world := chi.URLParam(req, "world")
+	if world != "primary" && !slices.Contains(h.fs.GetSecondaryTables(), world) {
+		h.sendError(w, http.StatusNotFound, fmt.Sprintf("%v: %s", sdk.ErrUnknownWorld, world))
+		return nil, false
+	}
+
+	raw, err := url.PathUnescape(chi.URLParam(req, "*"))
+	if err != nil {
+		h.sendError(w, http.StatusBadRequest, fmt.Sprintf("Invalid path: %v", err))
+		return nil, false
+	}
+	target := &fsTarget{
+		world:  world,
+		name:   strings.TrimSuffix(raw, "/"),
+		folder: raw == "" || strings.HasSuffix(raw, "/"),
+	}
+	if target.name == "" {
+		target.name = "."
+	}
+	if !fs.ValidPath(target.name) {
+		h.sendError(w, http.StatusBadRequest, fmt.Sprintf("Invalid path: %q", raw))
+		return nil, false
+	}
+	return target, true
+}
+
+// lookup resolves a path in a world through the fs.FS wrapper, so folders on the way are generated
+// Returns nil if nothing is there or the node's type does not match folder
+func (h *FSHandler) lookup(world, name string, folder bool) *types.Node {
+	info, err := fs.Stat(h.fs.AsFS(world), name)
+	if err != nil || info.IsDir() != folder {
+		return nil
+	}
+	node, _ := info.Sys().(*types.Node)
+	return node
+}
+
+// requirePrimary rejects writes to secondary worlds with 405
+func (h *FSHandler) requirePrimary(w http.ResponseWriter, target *fsTarget) bool {
+	if target.world == "primary" {
+		return true
+	}
+	w.Header().Set("Allow", http.MethodGet)
+	h.sendError(w, http.StatusMethodNotAllowed, fmt.Sprintf("world %s is read-only; writes go to primary", target.world))
+	return false
+}
+
+// FSChaosOp names the chaos operation of a /fs request: listing or reading for GET,
+// creating or uploading for PUT, deleting for DELETE
+func FSChaosOp(req *http.Request) string {
+	folder := strings.HasSuffix(req.URL.Path, "/")
+	switch req.Method {
+	case http.MethodGet:
+		if folder {
+			return sdk.ChaosOpList
+		}
+		return sdk.ChaosOpRead
+	case http.MethodPut:
+		if folder {
+			return sdk.ChaosOpCreate
+		}
+		return sdk.ChaosOpUpload
+	case http.MethodDelete:
+		return sdk.ChaosOpDelete
+	}
+	return ""
+}
+
+// Get lists a folder as a JSON array of nodes or streams a file's raw content
+func (h *FSHandler) Get(w http.ResponseWriter, req *http.Request) {
+	target, ok := h.parseTarget(w, req)
+	if !ok {
+		return
+	}
+
+	node := h.lookup(target.world, target.name, target.folder)
+	if node == nil {
+		h.sendError(w, http.StatusNotFound, fmt.Sprintf("Not found: /%s", chi.URLParam(req, "*")))
+		return
+	}
+
+	if !target.folder {
+		reader, node, err := h.fs.OpenFileDataContext(req.Context(), node.ID)
+		if err != nil {
+			h.sendFailure(w, "Failed to get file data", err)
+			return
+		}
+		serveFileContent(w, req, node, reader)
+		return
+	}
+
+	result, err := h.fs.ListChildrenContext(req.Context(), &spectrafsmodels.ListChildrenRequest{
+		ParentID:  node.ID,
+		TableName: target.world,
+	})
+	if err != nil {
+		h.sendFailure(w, "Failed to list children", err)
+		return
+	}
+
+	entries := make([]*types.Node, 0, len(result.Folders)+len(result.Files)+len(result.Symlinks))
+	for i := range result.Folders {
+		entries = append(entries, &result.Folders[i].Node)
+	}
+	for i := range result.Files {
+		entries = append(entries, &result.Files[i].Node)
+	}
+	for i := range result.Symlinks {
+		entries = append(entries, &result.Symlinks[i].Node)
+	}
+	h.sendJSON(w, http.StatusOK, entries)
+}
+
+// Put creates a folder (trailing slash) or uploads a file with the request body as its content
+// An existing file is overwritten in place; any other node at the path is a 409. The body is
+// streamed, and one over api.max_upload_bytes is a 413
+func (h *FSHandler) Put(w http.ResponseWriter, req *http.Request) {
+	target, ok := h.parseTarget(w, req)
+	if !ok || !h.requirePrimary(w, target) {
+		return
+	}
+	if target.name == "." {
+		h.sendError(w, http.StatusConflict, "the root folder already exists")
+		return
+	}
+
+	parent := h.lookup(target.world, path.Dir(target.name), true)
+	if parent == nil {
+		h.sendError(w, http.StatusNotFound, fmt.Sprintf("Parent folder not found: /%s/", path.Dir(target.name)))
+		return
+	}
+
+	var node *types.Node
+	var err error
+	if target.folder {
+		node, err = h.fs.CreateFolderContext(req.Context(), &spectrafsmodels.CreateFolderRequest{
+			ParentID: parent.ID,
+			Name:     path.Base(target.name),
+		})
+	} else {
+		limitUpload(w, req, h.fs)
+		digest := newUploadDigest(req.Body)
+		node, err = h.fs.UploadFileFrom(req.Context(), &spectrafsmodels.UploadFileRequest{
+			ParentID:  parent.ID,
+			Name:      path.Base(target.name),
+			Overwrite: true,
+		}, digest)
+		if err == nil {
+			digest.setHeaders(w)
+		}
+	}
+	if err != nil {
+		h.sendFailure(w, "Failed to write node", err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusCreated, types.APIResponse{
+		Success: true,
+		Message: "Node written successfully",
+		Data:    node,
+	})
+}
+
+// Delete removes the folder or file at the path; non-empty folders need ?recursive=true
+func (h *FSHandler) Delete(w http.ResponseWriter, req *http.Request) {
+	target, ok := h.parseTarget(w, req)
+	if !ok || !h.requirePrimary(w, target) {
+		return
+	}
+
+	node := h.lookup(target.world, target.name, target.folder)
+	if node == nil {
+		h.sendError(w, http.StatusNotFound, fmt.Sprintf("Not found: /%s", chi.URLParam(req, "*")))
+		return
+	}
+
+	err := h.fs.DeleteNodeContext(req.Context(), &spectrafsmodels.DeleteNodeRequest{
+		ID:        node.ID,
+		Recursive: req.URL.Query().Get("recursive") == "true",
+	})
+	if err != nil {
+		h.sendFailure(w, "Failed to delete node", err)
+		return
+	}
+
+	h.sendSuccess(w, "Node deleted successfully", nil)
+}
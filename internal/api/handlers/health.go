@@ -2,19 +2,58 @@ package handlers
 
 import (
 	"net/http"
+	"runtime/debug"
+	"sync"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
 )
 
 // HealthHandler handles health check endpoints
 type HealthHandler struct {
 	BaseHandler
+	fs *sdk.SpectraFS
 }
 
 // NewHealthHandler creates a new health handler
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+func NewHealthHandler(fs *sdk.SpectraFS) *HealthHandler {
+	return &HealthHandler{fs: fs}
+}
+
+// Live handles the liveness endpoint: the process is up and serving, whatever the database's state
+func (h *HealthHandler) Live(w http.ResponseWriter, req *http.Request) {
+	h.sendSuccess(w, "Spectra API is alive", nil)
 }
 
-// HealthCheck handles the health check endpoint
-func (h *HealthHandler) HealthCheck(w http.ResponseWriter, req *http.Request) {
-	h.sendSuccess(w, "Spectra API is healthy", nil)
+// Ready handles the readiness endpoint (and /health): 200 once a read-only transaction finds the
+// root node, 503 with code not_ready while that fails or a reset or import replaces the tree.
+// Both carry the HealthReport as data
+func (h *HealthHandler) Ready(w http.ResponseWriter, req *http.Request) {
+	report := h.fs.Health(req.Context())
+	report.Version = buildVersion()
+	if !report.Ready {
+		h.sendJSON(w, http.StatusServiceUnavailable, types.APIResponse{
+			Success: false,
+			Code:    CodeNotReady,
+			Message: "Spectra API is not ready: " + report.Reason,
+			Data:    report,
+		})
+		return
+	}
+	h.sendSuccess(w, "Spectra API is ready", report)
 }
+
+// buildVersion is the module version and VCS revision the binary was built from, read once
+var buildVersion = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	version := info.Main.Version
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			version += " (" + setting.Value + ")"
+		}
+	}
+	return version
+})
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	apimodels "github.com/Project-Sylos/Spectra/internal/api/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+	"github.com/go-chi/chi/v5"
+)
+
+// InstanceHandler handles the endpoints that list, create and delete named filesystem instances
+type InstanceHandler struct {
+	BaseHandler
+	instances *sdk.MultiInstance
+}
+
+// NewInstanceHandler creates a new instance handler
+func NewInstanceHandler(instances *sdk.MultiInstance) *InstanceHandler {
+	return &InstanceHandler{
+		instances: instances,
+	}
+}
+
+// ListInstances handles the list instances endpoint
+func (h *InstanceHandler) ListInstances(w http.ResponseWriter, req *http.Request) {
+	names := h.instances.Names()
+	list := make([]sdk.InstanceConfig, 0, len(names))
+	for _, name := range names {
+		instance, err := h.instances.Instance(name)
+		if err != nil {
+			continue // Deleted since Names
+		}
+		list = append(list, sdk.InstanceConfig{Name: name, Seed: instance.GetConfig().Seed})
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("Found %d instances", len(list)), list)
+}
+
+// CreateInstance handles the create instance endpoint
+// The body's seed section is laid over the main config's, so it only lists the settings it changes
+func (h *InstanceHandler) CreateInstance(w http.ResponseWriter, req *http.Request) {
+	var apiRequest apimodels.CreateInstanceRequest
+	if err := decodeJSON(req, &apiRequest); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	seed, err := h.instances.InstanceSeed(apiRequest.Seed)
+	if err != nil {
+		h.sendFailure(w, "Failed to create instance", err)
+		return
+	}
+
+	created, err := h.instances.CreateInstance(sdk.InstanceConfig{Name: apiRequest.Name, Seed: seed})
+	if err != nil {
+		h.sendFailure(w, "Failed to create instance", err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusCreated, types.APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Instance %s created", apiRequest.Name),
+		Data:    sdk.InstanceConfig{Name: apiRequest.Name, Seed: created.GetConfig().Seed},
+	})
+}
+
+// CloneInstance handles the clone instance endpoint: the instance in the path is copied, without
+// pausing it, into a new instance named by the body, and the copy's identity is returned
+func (h *InstanceHandler) CloneInstance(w http.ResponseWriter, req *http.Request) {
+	source := chi.URLParam(req, "instance")
+
+	var apiRequest apimodels.CloneInstanceRequest
+	if err := decodeJSON(req, &apiRequest); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	created, err := h.instances.CloneInstance(source, apiRequest.Name, apiRequest.DBPath)
+	if err != nil {
+		h.sendFailure(w, "Failed to clone instance", err)
+		return
+	}
+	identity, err := created.Identity()
+	if err != nil {
+		h.sendFailure(w, "Failed to read clone identity", err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusCreated, types.APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Instance %s cloned to %s", source, apiRequest.Name),
+		Data:    identity,
+	})
+}
+
+// DeleteInstance handles the delete instance endpoint; the instance's database file is removed
+func (h *InstanceHandler) DeleteInstance(w http.ResponseWriter, req *http.Request) {
+	name := chi.URLParam(req, "instance")
+
+	if err := h.instances.DeleteInstance(name); err != nil {
+		h.sendFailure(w, "Failed to delete instance", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("Instance %s deleted", name), nil)
+}
+
+// Forward returns a handler for /api/v1/instances/{instance}/* that serves each request with the
+// instance's API, as returned by routes, or fails with unknown_instance
+func (h *InstanceHandler) Forward(routes func(name string, fs *sdk.SpectraFS) http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		name := chi.URLParam(req, "instance")
+		instance, err := h.instances.Instance(name)
+		if err != nil {
+			h.sendFailure(w, "Failed to reach instance", err)
+			return
+		}
+
+		// Route the rest of the path as if the instance's API were mounted at the root
+		chi.RouteContext(req.Context()).RoutePath = "/" + chi.URLParam(req, "*")
+		routes(name, instance).ServeHTTP(w, req)
+	}
+}
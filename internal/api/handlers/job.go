@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/logging"
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// DefaultMaxJobs is how many jobs run at once when api.max_jobs is unset
+const DefaultMaxJobs = 2
+
+// JobManager runs long operations in the background for the endpoints that would otherwise outlast
+// the server's write timeout. At most api.max_jobs jobs run at once and later ones queue; each is
+// recorded in the database (sdk.SaveJob) as it is queued, starts and finishes, so its outcome can be
+// read after a restart. Jobs outlive the request that started them and end when cancelled or when
+// the instance closes
+type JobManager struct {
+	fs    *sdk.SpectraFS
+	slots chan struct{} // Holds a token per running job
+
+	mu   sync.Mutex
+	jobs map[string]*job // Jobs started by this process that are unfinished or could not be recorded
+}
+
+// job is one job started by this process
+type job struct {
+	record   types.Job
+	cancel   context.CancelFunc
+	progress func() any // Reports the job's progress while it runs (nil if it has none)
+}
+
+// jobFunc is the work of a job; the result is reported once it is done
+type jobFunc func(ctx context.Context) (any, error)
+
+// NewJobManager creates the job manager of fs, running at most api.max_jobs jobs at once
+func NewJobManager(fs *sdk.SpectraFS) *JobManager {
+	maxJobs := fs.GetConfig().API.MaxJobs
+	if maxJobs <= 0 {
+		maxJobs = DefaultMaxJobs
+	}
+	return &JobManager{
+		fs:    fs,
+		slots: make(chan struct{}, maxJobs),
+		jobs:  make(map[string]*job),
+	}
+}
+
+// Submit queues a job of kind running run and returns its record. ctx only supplies the job's logger:
+// the job runs until run returns, Cancel is called or the instance closes. progress, if set, is
+// reported while the job runs
+func (m *JobManager) Submit(ctx context.Context, kind string, run jobFunc, progress func() any) types.Job {
+	logger := logging.FromContext(ctx, logging.Discard)
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	j := &job{
+		record: types.Job{
+			ID:        uuid.New().String(),
+			Kind:      kind,
+			Status:    types.JobQueued,
+			CreatedAt: time.Now().UTC(),
+		},
+		cancel:   cancel,
+		progress: progress,
+	}
+
+	m.mu.Lock()
+	m.jobs[j.record.ID] = j
+	record := j.record
+	m.mu.Unlock()
+	m.save(logger, record)
+
+	go func() {
+		defer cancel()
+		select {
+		case m.slots <- struct{}{}:
+		case <-ctx.Done():
+			m.finish(logger, j, nil, ctx.Err())
+			return
+		}
+		defer func() { <-m.slots }()
+
+		m.mu.Lock()
+		started := time.Now().UTC()
+		j.record.Status = types.JobRunning
+		j.record.StartedAt = &started
+		record := j.record
+		m.mu.Unlock()
+		m.save(logger, record)
+		logger.Info("job started", "job", record.ID, "kind", kind)
+
+		result, err := run(ctx)
+		m.finish(logger, j, result, err)
+	}()
+	return record
+}
+
+// finish records how a job ended: cancelled if its context was, failed with the error's API code
+// otherwise, or done with result
+func (m *JobManager) finish(logger *slog.Logger, j *job, result any, err error) {
+	m.mu.Lock()
+	started := j.record.StartedAt != nil
+	m.mu.Unlock()
+	var final json.RawMessage
+	if started {
+		final = m.progressOf(j) // A job cancelled while queued has none of its own
+	}
+
+	m.mu.Lock()
+	finished := time.Now().UTC()
+	j.record.FinishedAt = &finished
+	j.record.Progress = final
+	switch {
+	case errors.Is(err, context.Canceled):
+		j.record.Status = types.JobCancelled
+	case err != nil:
+		j.record.Status = types.JobFailed
+		j.record.Error = err.Error()
+		_, j.record.ErrorCode = classifyError(err)
+	default:
+		j.record.Status = types.JobDone
+		if result != nil {
+			data, marshalErr := json.Marshal(result)
+			if marshalErr != nil {
+				j.record.Status = types.JobFailed
+				j.record.Error = fmt.Sprintf("failed to encode result: %v", marshalErr)
+				j.record.ErrorCode = CodeInternal
+			}
+			j.record.Result = data
+		}
+	}
+	record := j.record
+	m.mu.Unlock()
+
+	logger.Info("job finished", "job", record.ID, "kind", record.Kind, "status", record.Status, "error", record.Error)
+	if m.save(logger, record) {
+		m.mu.Lock()
+		delete(m.jobs, record.ID)
+		m.mu.Unlock()
+	}
+}
+
+// save records a job's state, reporting whether it was stored; a read-only instance keeps its jobs
+// in memory only
+func (m *JobManager) save(logger *slog.Logger, record types.Job) bool {
+	err := m.fs.SaveJob(&record)
+	if err != nil && !errors.Is(err, sdk.ErrReadOnly) {
+		logger.Warn("failed to record job", "job", record.ID, "status", record.Status, "error", err)
+	}
+	return err == nil
+}
+
+// progressOf encodes a job's current progress, or returns nil if it reports none
+func (m *JobManager) progressOf(j *job) json.RawMessage {
+	if j.progress == nil {
+		return nil
+	}
+	data, err := json.Marshal(j.progress())
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+	return data
+}
+
+// Get returns the state of a job: live for one this process is running, recorded otherwise
+func (m *JobManager) Get(id string) (*types.Job, error) {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	m.mu.Unlock()
+	if ok {
+		return m.snapshot(j), nil
+	}
+	return m.fs.GetJob(id)
+}
+
+// List returns every known job, newest first
+func (m *JobManager) List() ([]*types.Job, error) {
+	recorded, err := m.fs.ListJobs()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	live := make([]*job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		live = append(live, j)
+	}
+	m.mu.Unlock()
+
+	jobs := make([]*types.Job, 0, len(recorded)+len(live))
+	seen := make(map[string]bool, len(live))
+	for _, j := range live {
+		snapshot := m.snapshot(j)
+		seen[snapshot.ID] = true
+		jobs = append(jobs, snapshot)
+	}
+	for _, record := range recorded {
+		if !seen[record.ID] {
+			jobs = append(jobs, record)
+		}
+	}
+	sort.SliceStable(jobs, func(i, k int) bool { return jobs[i].CreatedAt.After(jobs[k].CreatedAt) })
+	return jobs, nil
+}
+
+// Cancel cancels a queued or running job and reports whether it was unfinished; the job ends as
+// cancelled once its operation has stopped
+func (m *JobManager) Cancel(id string) (*types.Job, bool, error) {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		record, err := m.fs.GetJob(id)
+		return record, false, err
+	}
+
+	snapshot := m.snapshot(j)
+	if types.IsFinishedJobStatus(snapshot.Status) {
+		return snapshot, false, nil
+	}
+	j.cancel()
+	return snapshot, true, nil
+}
+
+// snapshot copies a job's record, with its current progress while it runs
+func (m *JobManager) snapshot(j *job) *types.Job {
+	m.mu.Lock()
+	record := j.record
+	m.mu.Unlock()
+	if record.Status == types.JobRunning {
+		record.Progress = m.progressOf(j)
+	}
+	return &record
+}
+
+// JobHandler handles the job endpoints
+type JobHandler struct {
+	BaseHandler
+	jobs *JobManager
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(jobs *JobManager) *JobHandler {
+	return &JobHandler{
+		jobs: jobs,
+	}
+}
+
+// ListJobs handles the list jobs endpoint
+func (h *JobHandler) ListJobs(w http.ResponseWriter, req *http.Request) {
+	jobs, err := h.jobs.List()
+	if err != nil {
+		h.sendFailure(w, "Failed to list jobs", err)
+		return
+	}
+
+	h.sendSuccess(w, "Jobs retrieved successfully", jobs)
+}
+
+// GetJob handles the get job endpoint
+func (h *JobHandler) GetJob(w http.ResponseWriter, req *http.Request) {
+	job, err := h.jobs.Get(chi.URLParam(req, "id"))
+	if err != nil {
+		h.sendFailure(w, "Failed to get job", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("Job is %s", job.Status), job)
+}
+
+// CancelJob handles the cancel job endpoint; 409 if the job has already finished
+func (h *JobHandler) CancelJob(w http.ResponseWriter, req *http.Request) {
+	job, cancelled, err := h.jobs.Cancel(chi.URLParam(req, "id"))
+	if err != nil {
+		h.sendFailure(w, "Failed to cancel job", err)
+		return
+	}
+	if !cancelled {
+		h.sendError(w, http.StatusConflict, fmt.Sprintf("job has already finished (%s)", job.Status))
+		return
+	}
+
+	h.sendSuccess(w, "Job cancelled", job)
+}
+
+// sendJob responds 202 with the record of a job just submitted
+func (h *BaseHandler) sendJob(w http.ResponseWriter, message string, job types.Job) {
+	h.sendJSON(w, http.StatusAccepted, types.APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("%s; poll /api/v1/jobs/%s for its status", message, job.ID),
+		Data:    job,
+	})
+}
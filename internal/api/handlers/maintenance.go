@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	apimodels "github.com/Project-Sylos/Spectra/internal/api/models"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// Bounds for the determinism check endpoint
+const (
+	defaultDeterminismIterations = 3
+	maxDeterminismIterations     = 10
+)
+
+// MaintenanceHandler handles maintenance and self-check endpoints
+type MaintenanceHandler struct {
+	BaseHandler
+	fs   *sdk.SpectraFS
+	jobs *JobManager
+}
+
+// NewMaintenanceHandler creates a new maintenance handler running its background checks through jobs
+func NewMaintenanceHandler(fs *sdk.SpectraFS, jobs *JobManager) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		fs:   fs,
+		jobs: jobs,
+	}
+}
+
+// DeterminismCheck handles the determinism self-check endpoint
+// The request body is optional; iterations defaults to 3
+func (h *MaintenanceHandler) DeterminismCheck(w http.ResponseWriter, req *http.Request) {
+	var apiRequest apimodels.DeterminismCheckRequest
+	if err := decodeJSON(req, &apiRequest); err != nil && !errors.Is(err, io.EOF) {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	iterations := apiRequest.Iterations
+	if iterations == 0 {
+		iterations = defaultDeterminismIterations
+	}
+	if iterations < 2 || iterations > maxDeterminismIterations {
+		h.sendError(w, http.StatusBadRequest, fmt.Sprintf("iterations must be between 2 and %d", maxDeterminismIterations))
+		return
+	}
+
+	report, err := h.fs.DeterminismCheck(iterations)
+	if err != nil {
+		h.sendFailure(w, "Failed to run determinism check", err)
+		return
+	}
+
+	message := "Generation is deterministic"
+	if !report.Deterministic {
+		message = fmt.Sprintf("Generation diverged at %s (%s)", report.Divergence.Path, report.Divergence.Field)
+	}
+
+	h.sendSuccess(w, message, report)
+}
+
+// VerifyChecksum handles the checksum verification endpoint for a single file
+// A mismatch still responds 200 with match false
+func (h *MaintenanceHandler) VerifyChecksum(w http.ResponseWriter, req *http.Request) {
+	var apiRequest apimodels.VerifyChecksumRequest
+	if err := decodeJSON(req, &apiRequest); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if apiRequest.ID == "" {
+		h.sendError(w, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	result, err := h.fs.VerifyChecksum(req.Context(), apiRequest.ID, apiRequest.Checksum)
+	if err != nil {
+		h.sendFailure(w, "Failed to verify checksum", err)
+		return
+	}
+
+	message := "Checksum matches the file content"
+	if !result.Match {
+		message = "Checksum does not match the file content"
+	}
+	h.sendSuccess(w, message, result)
+}
+
+// VerifyTree handles the bulk checksum verification endpoint, recomputing the checksums of the
+// stored files below a node. The request body is optional; mismatches still respond 200 with ok false.
+// Query parameter async=true runs the check as a job and responds 202 with it
+func (h *MaintenanceHandler) VerifyTree(w http.ResponseWriter, req *http.Request) {
+	async, err := queryBool(req, "async")
+	if err != nil {
+		h.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var apiRequest apimodels.VerifyTreeRequest
+	if err := decodeJSON(req, &apiRequest); err != nil && !errors.Is(err, io.EOF) {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if async {
+		job := h.jobs.Submit(req.Context(), sdk.JobVerifyTree, func(ctx context.Context) (any, error) {
+			return h.fs.VerifyTree(ctx, apiRequest.ID, apiRequest.TableName)
+		}, nil)
+		h.sendJob(w, "Verification started", job)
+		return
+	}
+
+	report, err := h.fs.VerifyTree(req.Context(), apiRequest.ID, apiRequest.TableName)
+	if err != nil {
+		h.sendFailure(w, "Failed to verify tree", err)
+		return
+	}
+
+	message := fmt.Sprintf("Verified %d file(s); all checksums match", report.Files)
+	if !report.OK {
+		message = fmt.Sprintf("Verified %d file(s); %d checksum(s) do not match", report.Files, report.Mismatched)
+	}
+	h.sendSuccess(w, message, report)
+}
+
+// LastRecovery returns the most recent crash-recovery report
+// Responds 404 if the database has never been opened after an unclean shutdown
+func (h *MaintenanceHandler) LastRecovery(w http.ResponseWriter, req *http.Request) {
+	report, err := h.fs.LastRecovery()
+	if err != nil {
+		h.sendFailure(w, "Failed to read recovery report", err)
+		return
+	}
+	if report == nil {
+		h.sendError(w, http.StatusNotFound, "No recovery report recorded")
+		return
+	}
+
+	message := fmt.Sprintf("Recovery pass found %d issue(s)", len(report.Findings))
+	if report.Repaired {
+		message += "; indexes and stats were rebuilt"
+	}
+
+	h.sendSuccess(w, message, report)
+}
+
+// FailGeneration arms (or, with after_nodes 0, disarms) the generation failure hook
+// This is a testing hook: the next generation that crosses the count fails part-way
+func (h *MaintenanceHandler) FailGeneration(w http.ResponseWriter, req *http.Request) {
+	var apiRequest apimodels.FailGenerationRequest
+	if err := decodeJSON(req, &apiRequest); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if apiRequest.AfterNodes < 0 {
+		h.sendError(w, http.StatusBadRequest, "after_nodes must be non-negative")
+		return
+	}
+
+	h.fs.ArmGenerationFailure(apiRequest.AfterNodes)
+	armed, remaining := h.fs.GenerationFailureArmed()
+
+	message := "Generation failure hook disarmed"
+	if armed {
+		message = fmt.Sprintf("Generation will fail after %d more node(s)", remaining)
+	}
+
+	h.sendSuccess(w, message, map[string]any{
+		"armed":           armed,
+		"remaining_nodes": remaining,
+	})
+}
+
+// Schedule reports the configured maintenance tasks and their last-run status
+func (h *MaintenanceHandler) Schedule(w http.ResponseWriter, req *http.Request) {
+	schedule, err := h.fs.MaintenanceSchedule()
+	if err != nil {
+		h.sendFailure(w, "Failed to read maintenance schedule", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("%d scheduled maintenance task(s)", len(schedule.Tasks)), schedule)
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	apimodels "github.com/Project-Sylos/Spectra/internal/api/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// defaultMutationLogLimit is the page size of the mutation log endpoint when limit is omitted
+const defaultMutationLogLimit = 100
+
+// MutationHandler controls the mutation engine and serves its log
+type MutationHandler struct {
+	BaseHandler
+	fs *sdk.SpectraFS
+}
+
+// NewMutationHandler creates a new mutation handler
+func NewMutationHandler(fs *sdk.SpectraFS) *MutationHandler {
+	return &MutationHandler{
+		fs: fs,
+	}
+}
+
+// Status reports the mutation engine's settings, state and progress
+func (h *MutationHandler) Status(w http.ResponseWriter, req *http.Request) {
+	h.sendStatus(w, "Mutation status retrieved successfully")
+}
+
+// Start starts the mutation engine; it responds 409 if the engine is already running
+func (h *MutationHandler) Start(w http.ResponseWriter, req *http.Request) {
+	if err := h.fs.StartMutations(); err != nil {
+		h.sendFailure(w, "Failed to start mutation engine", err)
+		return
+	}
+	h.sendStatus(w, "Mutation engine started")
+}
+
+// Stop stops the mutation engine, waiting for a round in progress; stopping a stopped engine succeeds
+func (h *MutationHandler) Stop(w http.ResponseWriter, req *http.Request) {
+	h.fs.StopMutations()
+	h.sendStatus(w, "Mutation engine stopped")
+}
+
+// Run applies mutations immediately; the request body is optional and count defaults to 1
+// A scope with nothing left to mutate responds 409 with the mutations applied before it ran out
+func (h *MutationHandler) Run(w http.ResponseWriter, req *http.Request) {
+	var apiRequest apimodels.RunMutationsRequest
+	if err := decodeJSON(req, &apiRequest); err != nil && !errors.Is(err, io.EOF) {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	count := apiRequest.Count
+	if count == 0 {
+		count = 1
+	}
+	if count < 1 || count > sdk.MaxMutationsPerRun {
+		h.sendError(w, http.StatusBadRequest, fmt.Sprintf("count must be between 1 and %d", sdk.MaxMutationsPerRun))
+		return
+	}
+
+	mutations, err := h.fs.RunMutations(req.Context(), count)
+	if errors.Is(err, sdk.ErrNoMutationCandidates) {
+		status, code := classifyError(err)
+		h.sendJSON(w, status, types.APIResponse{
+			Success: false,
+			Code:    code,
+			Message: err.Error(),
+			Data:    mutations,
+		})
+		return
+	}
+	if err != nil {
+		h.sendFailure(w, "Failed to apply mutations", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("Applied %d mutation(s)", len(mutations)), mutations)
+}
+
+// Log returns mutation log entries after the after_seq query parameter (default 0), oldest first,
+// up to limit (default 100)
+func (h *MutationHandler) Log(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	var afterSeq int64
+	if raw := query.Get("after_seq"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed < 0 {
+			h.sendError(w, http.StatusBadRequest, "after_seq must be a non-negative integer")
+			return
+		}
+		afterSeq = parsed
+	}
+
+	limit := defaultMutationLogLimit
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > sdk.MaxMutationsPerRun {
+			h.sendError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", sdk.MaxMutationsPerRun))
+			return
+		}
+		limit = parsed
+	}
+
+	mutations, err := h.fs.ListMutations(afterSeq, limit)
+	if err != nil {
+		h.sendFailure(w, "Failed to read mutation log", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("%d mutation(s)", len(mutations)), mutations)
+}
+
+// sendStatus responds with the current mutation status
+func (h *MutationHandler) sendStatus(w http.ResponseWriter, message string) {
+	status, err := h.fs.MutationStatus()
+	if err != nil {
+		h.sendFailure(w, "Failed to read mutation status", err)
+		return
+	}
+	h.sendSuccess(w, message, status)
+}
@@ -3,8 +3,11 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 
+	apimodels "github.com/Project-Sylos/Spectra/internal/api/models"
 	spectrafsmodels "github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
 	"github.com/Project-Sylos/Spectra/sdk"
 	"github.com/go-chi/chi/v5"
 )
@@ -35,9 +38,9 @@ func (h *NodeHandler) GetNode(w http.ResponseWriter, req *http.Request) {
 		ID: id,
 	}
 
-	node, err := h.fs.GetNode(request)
+	node, err := h.fs.GetNodeContext(req.Context(), request)
 	if err != nil {
-		h.sendError(w, http.StatusNotFound, fmt.Sprintf("Node not found: %v", err))
+		h.sendFailure(w, "Failed to get node", err)
 		return
 	}
 
@@ -52,21 +55,293 @@ func (h *NodeHandler) DeleteNode(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// Prevent deletion of root node (check will also happen in SDK, but this is a fast path)
-	if id == "root" || (len(id) > 5 && id[len(id)-5:] == "-root") {
-		h.sendError(w, http.StatusBadRequest, "Cannot delete root node")
+	// Create request struct from URL parameters
+	request := &spectrafsmodels.DeleteNodeRequest{
+		ID:        id,
+		Recursive: req.URL.Query().Get("recursive") == "true",
+	}
+
+	if err := h.fs.DeleteNodeContext(req.Context(), request); err != nil {
+		h.sendFailure(w, "Failed to delete node", err)
 		return
 	}
 
-	// Create request struct from URL parameter
-	request := &spectrafsmodels.DeleteNodeRequest{
-		ID: id,
+	h.sendSuccess(w, "Node deleted successfully", nil)
+}
+
+// MoveNode handles the move node endpoint
+func (h *NodeHandler) MoveNode(w http.ResponseWriter, req *http.Request) {
+	var apiRequest apimodels.MoveNodeRequest
+	if err := decodeJSON(req, &apiRequest); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
 	}
 
-	if err := h.fs.DeleteNode(request); err != nil {
-		h.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to delete node: %v", err))
+	node, err := h.fs.MoveNodeContext(req.Context(), &spectrafsmodels.MoveNodeRequest{
+		ID:            apiRequest.ID,
+		Path:          apiRequest.Path,
+		TableName:     apiRequest.TableName,
+		NewParentID:   apiRequest.NewParentID,
+		NewParentPath: apiRequest.NewParentPath,
+	})
+	if err != nil {
+		h.sendFailure(w, "Failed to move node", err)
 		return
 	}
 
-	h.sendSuccess(w, "Node deleted successfully", nil)
+	h.sendSuccess(w, "Node moved successfully", node)
+}
+
+// UpdateCopyStatus handles the update copy status endpoint
+func (h *NodeHandler) UpdateCopyStatus(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		h.sendError(w, http.StatusBadRequest, "node id is required")
+		return
+	}
+
+	var apiRequest apimodels.UpdateCopyStatusRequest
+	if err := decodeJSON(req, &apiRequest); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	statusRequest := &spectrafsmodels.UpdateCopyStatusRequest{
+		ID:     id,
+		Status: apiRequest.Status,
+	}
+
+	var data any
+	var err error
+	if apiRequest.Recursive {
+		var updated int
+		updated, err = h.fs.UpdateSubtreeCopyStatus(statusRequest)
+		data = map[string]any{"updated": updated}
+	} else {
+		data, err = h.fs.UpdateCopyStatus(statusRequest)
+	}
+	if err != nil {
+		h.sendFailure(w, "Failed to update copy status", err)
+		return
+	}
+
+	h.sendSuccess(w, "Copy status updated successfully", data)
+}
+
+// ListNodes handles the node query endpoint (currently filtered by copy_status)
+func (h *NodeHandler) ListNodes(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	status := query.Get("copy_status")
+	if status == "" {
+		h.sendError(w, http.StatusBadRequest, "copy_status is required")
+		return
+	}
+
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			h.sendError(w, http.StatusBadRequest, "limit must be a non-negative integer")
+			return
+		}
+		limit = parsed
+	}
+
+	page, err := h.fs.ListNodesByCopyStatus(query.Get("world"), status, limit, query.Get("cursor"))
+	if err != nil {
+		h.sendFailure(w, "Failed to list nodes", err)
+		return
+	}
+
+	h.sendSuccess(w, "Nodes retrieved successfully", page)
+}
+
+// Changes handles the change feed endpoint: journal events after the since query parameter
+// (default 0), up to limit (default 100), with the cursor to pass as since next time
+func (h *NodeHandler) Changes(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	var since int64
+	if raw := query.Get("since"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			h.sendError(w, http.StatusBadRequest, "since must be a non-negative integer")
+			return
+		}
+		since = parsed
+	}
+
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			h.sendError(w, http.StatusBadRequest, "limit must be a non-negative integer")
+			return
+		}
+		limit = parsed
+	}
+
+	feed, err := h.fs.GetChanges(since, limit)
+	if err != nil {
+		h.sendFailure(w, "Failed to read changes", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("%d change(s)", len(feed.Events)), feed)
+}
+
+// Search handles the search endpoint
+// Only nodes already stored are searched; the response message says so
+func (h *NodeHandler) Search(w http.ResponseWriter, req *http.Request) {
+	var apiRequest apimodels.SearchRequest
+	if err := decodeJSON(req, &apiRequest); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.fs.Search(req.Context(), &spectrafsmodels.SearchRequest{
+		World:      apiRequest.World,
+		PathPrefix: apiRequest.PathPrefix,
+		NameGlob:   apiRequest.NameGlob,
+		Type:       apiRequest.Type,
+		MinSize:    apiRequest.MinSize,
+		MaxSize:    apiRequest.MaxSize,
+		Limit:      apiRequest.Limit,
+		Metadata:   apiRequest.Metadata,
+	})
+	if err != nil {
+		h.sendFailure(w, "Failed to search nodes", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("Found %d materialized nodes (unexpanded folders are not searched)", len(result.Nodes)), result)
+}
+
+// Duplicates handles the duplicates endpoint: groups of stored files in the world query parameter
+// (default primary) that share their content
+func (h *NodeHandler) Duplicates(w http.ResponseWriter, req *http.Request) {
+	report, err := h.fs.Duplicates(req.Context(), req.URL.Query().Get("world"))
+	if err != nil {
+		h.sendFailure(w, "Failed to find duplicates", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("Found %d group(s) of duplicate files among %d materialized file(s)", len(report.Groups), report.Files), report)
+}
+
+// RenameNode handles the rename node endpoint
+func (h *NodeHandler) RenameNode(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		h.sendError(w, http.StatusBadRequest, "node id is required")
+		return
+	}
+
+	var apiRequest apimodels.RenameNodeRequest
+	if err := decodeJSON(req, &apiRequest); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	node, err := h.fs.RenameNodeContext(req.Context(), &spectrafsmodels.RenameNodeRequest{
+		ID:      id,
+		NewName: apiRequest.Name,
+	})
+	if err != nil {
+		h.sendFailure(w, "Failed to rename node", err)
+		return
+	}
+
+	h.sendSuccess(w, "Node renamed successfully", node)
+}
+
+// SetNodeMetadata handles the node metadata update endpoint
+func (h *NodeHandler) SetNodeMetadata(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		h.sendError(w, http.StatusBadRequest, "node id is required")
+		return
+	}
+
+	var apiRequest apimodels.SetNodeMetadataRequest
+	if err := decodeJSON(req, &apiRequest); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	node, err := h.fs.SetNodeMetadata(req.Context(), &spectrafsmodels.SetNodeMetadataRequest{
+		ID:       id,
+		Metadata: apiRequest.Metadata,
+		Replace:  apiRequest.Replace,
+	})
+	if err != nil {
+		h.sendFailure(w, "Failed to update node metadata", err)
+		return
+	}
+
+	h.sendSuccess(w, "Node metadata updated successfully", node)
+}
+
+// UpdateTraversalStatus handles the update traversal status endpoint
+func (h *NodeHandler) UpdateTraversalStatus(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		h.sendError(w, http.StatusBadRequest, "node id is required")
+		return
+	}
+
+	var apiRequest apimodels.UpdateTraversalStatusRequest
+	if err := decodeJSON(req, &apiRequest); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	node, err := h.fs.UpdateTraversalStatus(&spectrafsmodels.UpdateTraversalStatusRequest{
+		ID:     id,
+		Status: apiRequest.Status,
+	})
+	if err != nil {
+		h.sendFailure(w, "Failed to update traversal status", err)
+		return
+	}
+
+	h.sendSuccess(w, "Traversal status updated successfully", node)
+}
+
+// BatchDelete handles the batch delete endpoint
+func (h *NodeHandler) BatchDelete(w http.ResponseWriter, req *http.Request) {
+	var apiRequest apimodels.BatchDeleteRequest
+	if err := decodeJSON(req, &apiRequest); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if len(apiRequest.IDs) == 0 {
+		h.sendError(w, http.StatusBadRequest, "ids is required")
+		return
+	}
+
+	if len(apiRequest.IDs) > sdk.MaxBatchDeleteSize {
+		h.sendError(w, http.StatusBadRequest, fmt.Sprintf("ids exceeds maximum batch size of %d", sdk.MaxBatchDeleteSize))
+		return
+	}
+
+	result, err := h.fs.DeleteNodesContext(req.Context(), apiRequest.IDs, apiRequest.Recursive)
+	if err != nil && result != nil {
+		// The deletes are stored; only recording them failed, so the outcomes are still reported
+		statusCode, code := classifyError(err)
+		h.sendJSON(w, statusCode, types.APIResponse{
+			Success: false,
+			Code:    code,
+			Message: fmt.Sprintf("Failed to journal deleted nodes: %v", err),
+			Data:    result,
+		})
+		return
+	}
+	if err != nil {
+		h.sendFailure(w, "Failed to delete nodes", err)
+		return
+	}
+
+	h.sendSuccess(w, "Batch delete completed", result)
 }
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	apimodels "github.com/Project-Sylos/Spectra/internal/api/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+	"github.com/go-chi/chi/v5"
+)
+
+// SnapshotHandler handles the named snapshot endpoints
+type SnapshotHandler struct {
+	BaseHandler
+	fs *sdk.SpectraFS
+}
+
+// NewSnapshotHandler creates a new snapshot handler
+func NewSnapshotHandler(fs *sdk.SpectraFS) *SnapshotHandler {
+	return &SnapshotHandler{
+		fs: fs,
+	}
+}
+
+// ListSnapshots handles the list snapshots endpoint
+func (h *SnapshotHandler) ListSnapshots(w http.ResponseWriter, req *http.Request) {
+	snapshots, err := h.fs.ListSnapshots()
+	if err != nil {
+		h.sendFailure(w, "Failed to list snapshots", err)
+		return
+	}
+
+	h.sendSuccess(w, "Snapshots retrieved successfully", snapshots)
+}
+
+// SaveSnapshot handles the save snapshot endpoint
+func (h *SnapshotHandler) SaveSnapshot(w http.ResponseWriter, req *http.Request) {
+	var apiRequest apimodels.SaveSnapshotRequest
+	if err := decodeJSON(req, &apiRequest); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	info, err := h.fs.SaveSnapshot(req.Context(), apiRequest.Name)
+	if err != nil {
+		h.sendFailure(w, "Failed to save snapshot", err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusCreated, types.APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Snapshot %s saved", info.Name),
+		Data:    info,
+	})
+}
+
+// RestoreSnapshot handles the restore snapshot endpoint
+func (h *SnapshotHandler) RestoreSnapshot(w http.ResponseWriter, req *http.Request) {
+	name := chi.URLParam(req, "name")
+
+	info, err := h.fs.RestoreSnapshot(req.Context(), name)
+	if err != nil {
+		h.sendFailure(w, "Failed to restore snapshot", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("Snapshot %s restored", name), info)
+}
+
+// DeleteSnapshot handles the delete snapshot endpoint
+func (h *SnapshotHandler) DeleteSnapshot(w http.ResponseWriter, req *http.Request) {
+	name := chi.URLParam(req, "name")
+
+	if err := h.fs.DeleteSnapshot(name); err != nil {
+		h.sendFailure(w, "Failed to delete snapshot", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("Snapshot %s deleted", name), nil)
+}
@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strconv"
+	"sync/atomic"
 
 	"github.com/Project-Sylos/Spectra/sdk"
 	"github.com/go-chi/chi/v5"
@@ -11,31 +14,198 @@ import (
 // SystemHandler handles system-related endpoints
 type SystemHandler struct {
 	BaseHandler
-	fs *sdk.SpectraFS
+	fs   *sdk.SpectraFS
+	jobs *JobManager
 }
 
-// NewSystemHandler creates a new system handler
-func NewSystemHandler(fs *sdk.SpectraFS) *SystemHandler {
+// NewSystemHandler creates a new system handler running its background operations through jobs
+func NewSystemHandler(fs *sdk.SpectraFS, jobs *JobManager) *SystemHandler {
 	return &SystemHandler{
-		fs: fs,
+		fs:   fs,
+		jobs: jobs,
 	}
 }
 
 // Reset handles the reset endpoint
+// Query parameter regenerate=true follows the reset with a full generation; it and async=true run
+// the reset as a job and respond 202 with it
 func (h *SystemHandler) Reset(w http.ResponseWriter, req *http.Request) {
-	if err := h.fs.Reset(); err != nil {
-		h.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to reset filesystem: %v", err))
+	async, err := queryBool(req, "async")
+	if err != nil {
+		h.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	regenerate, err := queryBool(req, "regenerate")
+	if err != nil {
+		h.sendError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if !async && !regenerate {
+		if err := h.fs.ResetContext(req.Context()); err != nil {
+			h.sendFailure(w, "Failed to reset filesystem", err)
+			return
+		}
 
-	h.sendSuccess(w, "Filesystem reset successfully", nil)
+		h.sendSuccess(w, "Filesystem reset successfully", nil)
+		return
+	}
+	if h.fs.ReadOnly() {
+		h.sendFailure(w, "Failed to start reset", sdk.ErrReadOnly)
+		return
+	}
+
+	var generating atomic.Bool
+	job := h.jobs.Submit(req.Context(), sdk.JobReset, func(ctx context.Context) (any, error) {
+		if err := h.fs.ResetContext(ctx); err != nil {
+			return nil, err
+		}
+		if !regenerate {
+			return nil, nil
+		}
+		generating.Store(true)
+		return h.fs.GenerateAll(ctx)
+	}, func() any {
+		if !generating.Load() {
+			return sdk.ResetProgress{Stage: "reset"}
+		}
+		return sdk.ResetProgress{Stage: "generate", Generation: h.fs.GenerationProgress()}
+	})
+	h.sendJob(w, "Reset started", job)
+}
+
+// Integrity handles the integrity endpoint, checking the indexes against the stored nodes
+// Query parameter quick=true only compares entry counts. A failed check still responds 200 with ok false
+func (h *SystemHandler) Integrity(w http.ResponseWriter, req *http.Request) {
+	quick := false
+	if raw := req.URL.Query().Get("quick"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			h.sendError(w, http.StatusBadRequest, "quick must be a boolean")
+			return
+		}
+		quick = parsed
+	}
+
+	report, err := h.fs.CheckIntegrity(req.Context(), quick)
+	if err != nil {
+		h.sendFailure(w, "Failed to check integrity", err)
+		return
+	}
+
+	message := "Indexes match the stored nodes"
+	if !report.OK {
+		message = fmt.Sprintf("Found %d missing, %d dangling and %d stale index entries and %d orphaned world existences",
+			report.Missing, report.Dangling, report.Stale, report.Orphaned)
+		if report.Quick {
+			message = "Index entry counts differ from the node count"
+		}
+	}
+	h.sendSuccess(w, message, report)
+}
+
+// Repair handles the repair endpoint, rebuilding every index and the stats from the stored nodes
+func (h *SystemHandler) Repair(w http.ResponseWriter, req *http.Request) {
+	report, err := h.fs.Repair(req.Context())
+	if err != nil {
+		h.sendFailure(w, "Failed to repair database", err)
+		return
+	}
+
+	h.sendSuccess(w, "Indexes and stats rebuilt", report)
+}
+
+// Compact handles the compact endpoint, rewriting the database file with only its live pages
+func (h *SystemHandler) Compact(w http.ResponseWriter, req *http.Request) {
+	result, err := h.fs.Compact()
+	if err != nil {
+		h.sendFailure(w, "Failed to compact database", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("Database compacted from %d to %d bytes", result.SizeBefore, result.SizeAfter), result)
+}
+
+// Export handles the export endpoint, streaming a snapshot of every stored node
+// Query parameter format: jsonl (default, application/x-ndjson) or json (application/json)
+func (h *SystemHandler) Export(w http.ResponseWriter, req *http.Request) {
+	format := req.URL.Query().Get("format")
+	if format == "" {
+		format = sdk.SnapshotFormatJSONL
+	}
+
+	switch format {
+	case sdk.SnapshotFormatJSONL:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	case sdk.SnapshotFormatJSON:
+		w.Header().Set("Content-Type", "application/json")
+	default:
+		h.sendError(w, http.StatusBadRequest, fmt.Sprintf("%v: %s", sdk.ErrUnknownFormat, format))
+		return
+	}
+
+	// Headers are already sent once streaming starts, so a later failure can only cut the body short
+	h.fs.Export(req.Context(), w, format)
+}
+
+// Import handles the import endpoint, loading a snapshot streamed in the request body
+// Query parameter merge=true keeps existing nodes; otherwise the database must hold only the root (409)
+func (h *SystemHandler) Import(w http.ResponseWriter, req *http.Request) {
+	merge := false
+	if raw := req.URL.Query().Get("merge"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			h.sendError(w, http.StatusBadRequest, "merge must be a boolean")
+			return
+		}
+		merge = parsed
+	}
+
+	result, err := h.fs.Import(req.Context(), req.Body, merge)
+	if err != nil {
+		h.sendFailure(w, "Failed to import snapshot", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("Imported %d nodes", result.Imported), result)
+}
+
+// Generate handles the generate endpoint, starting GenerateAll as a job
+// Progress is reported by the job and under "generation" in GET /api/v1/stats; 409 if a run is
+// already in progress. The run stops on DELETE /api/v1/generate, on cancelling the job or when the
+// instance closes, and its log lines carry the ID of the request that started it
+func (h *SystemHandler) Generate(w http.ResponseWriter, req *http.Request) {
+	if h.fs.ReadOnly() {
+		h.sendFailure(w, "Failed to start generation", sdk.ErrReadOnly)
+		return
+	}
+	if progress := h.fs.GenerationProgress(); progress != nil && progress.Running {
+		h.sendFailure(w, "Failed to start generation", sdk.ErrGenerationRunning)
+		return
+	}
+
+	job := h.jobs.Submit(req.Context(), sdk.JobGenerate, func(ctx context.Context) (any, error) {
+		return h.fs.GenerateAll(ctx)
+	}, func() any {
+		return h.fs.GenerationProgress()
+	})
+	h.sendJob(w, "Generation started", job)
+}
+
+// CancelGenerate handles the cancel generation endpoint
+func (h *SystemHandler) CancelGenerate(w http.ResponseWriter, req *http.Request) {
+	if !h.fs.CancelGeneration() {
+		h.sendError(w, http.StatusConflict, "generation is not running")
+		return
+	}
+
+	h.sendSuccess(w, "Generation cancelled", nil)
 }
 
 // GetTables handles the get tables endpoint
 func (h *SystemHandler) GetTables(w http.ResponseWriter, req *http.Request) {
-	tables, err := h.fs.GetTableInfo()
+	tables, err := h.fs.GetTableInfoContext(req.Context())
 	if err != nil {
-		h.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get table info: %v", err))
+		h.sendFailure(w, "Failed to get table info", err)
 		return
 	}
 
@@ -50,9 +220,9 @@ func (h *SystemHandler) GetTableCount(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	count, err := h.fs.GetNodeCount(tableName)
+	count, err := h.fs.GetNodeCountContext(req.Context(), tableName)
 	if err != nil {
-		h.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get table count: %v", err))
+		h.sendFailure(w, "Failed to get table count", err)
 		return
 	}
 
@@ -66,17 +236,69 @@ func (h *SystemHandler) GetTableCount(w http.ResponseWriter, req *http.Request)
 
 // GetConfig handles the get config endpoint
 func (h *SystemHandler) GetConfig(w http.ResponseWriter, req *http.Request) {
-	config := h.fs.GetConfig()
+	config := *h.fs.GetConfig()
+	config.API.Auth.Tokens = redactTokens(config.API.Auth.Tokens)
 	h.sendSuccess(w, "Config retrieved successfully", config)
 }
 
+// GetPersistedConfig handles the persisted config endpoint: the generation settings stored in the database
+func (h *SystemHandler) GetPersistedConfig(w http.ResponseWriter, req *http.Request) {
+	fingerprint, err := h.fs.PersistedConfig()
+	if err != nil {
+		h.sendFailure(w, "Failed to get persisted config", err)
+		return
+	}
+	if fingerprint == nil {
+		h.sendError(w, http.StatusNotFound, "No generation settings are stored in the database")
+		return
+	}
+
+	h.sendSuccess(w, "Persisted config retrieved successfully", fingerprint)
+}
+
+// redactTokens copies tokens with their secrets blanked, so the config endpoint never reveals them
+func redactTokens(tokens []sdk.AuthToken) []sdk.AuthToken {
+	if tokens == nil {
+		return nil
+	}
+	redacted := make([]sdk.AuthToken, len(tokens))
+	for i, token := range tokens {
+		redacted[i] = token
+		redacted[i].Token = "REDACTED"
+	}
+	return redacted
+}
+
 // GetStats handles the get stats endpoint
 func (h *SystemHandler) GetStats(w http.ResponseWriter, req *http.Request) {
 	stats, err := h.fs.GetStats()
 	if err != nil {
-		h.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get stats: %v", err))
+		h.sendFailure(w, "Failed to get stats", err)
 		return
 	}
 
 	h.sendSuccess(w, "Stats retrieved successfully", stats)
 }
+
+// GetCoverage handles the coverage endpoint
+func (h *SystemHandler) GetCoverage(w http.ResponseWriter, req *http.Request) {
+	coverage, err := h.fs.GetCoverage()
+	if err != nil {
+		h.sendFailure(w, "Failed to get coverage", err)
+		return
+	}
+
+	h.sendSuccess(w, "Coverage retrieved successfully", coverage)
+}
+
+// Analyze handles the analyze endpoint, reporting the shape of the stored subtree below ?root= in ?world=
+func (h *SystemHandler) Analyze(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	report, err := h.fs.Analyze(req.Context(), query.Get("root"), query.Get("world"))
+	if err != nil {
+		h.sendFailure(w, "Failed to analyze tree", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("Analyzed %d node(s) below %s", report.Nodes, report.RootPath), report)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// DefaultMaxUploadBytes is the upload body limit used when api.max_upload_bytes is unset
+const DefaultMaxUploadBytes int64 = 32 << 20
+
+// Headers reporting what an upload received; the stored node's size and checksum describe its
+// generated content instead, since uploaded bytes are not persisted
+const (
+	UploadSizeHeader     = "X-Upload-Size"
+	UploadChecksumHeader = "X-Upload-Checksum"
+)
+
+// maxFormFieldBytes caps each non-file field of a multipart upload
+const maxFormFieldBytes = 4 << 10
+
+// maxUploadBytes returns the configured upload body limit
+func maxUploadBytes(fs *sdk.SpectraFS) int64 {
+	if limit := fs.GetConfig().API.MaxUploadBytes; limit > 0 {
+		return limit
+	}
+	return DefaultMaxUploadBytes
+}
+
+// limitUpload caps the request body at the upload limit; reading past it fails with *http.MaxBytesError
+func limitUpload(w http.ResponseWriter, req *http.Request, fs *sdk.SpectraFS) {
+	req.Body = http.MaxBytesReader(w, req.Body, maxUploadBytes(fs))
+}
+
+// uploadDigest counts and hashes upload content as it is read
+type uploadDigest struct {
+	r    io.Reader
+	hash hash.Hash
+	size int64
+}
+
+// newUploadDigest wraps r so the content read through it is counted and hashed (SHA-256, like node checksums)
+func newUploadDigest(r io.Reader) *uploadDigest {
+	return &uploadDigest{r: r, hash: sha256.New()}
+}
+
+// Read reads from the wrapped reader, adding what it returns to the size and hash
+func (d *uploadDigest) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	d.size += int64(n)
+	d.hash.Write(p[:n])
+	return n, err
+}
+
+// setHeaders reports the received size and checksum on the response
+func (d *uploadDigest) setHeaders(w http.ResponseWriter) {
+	w.Header().Set(UploadSizeHeader, fmt.Sprint(d.size))
+	w.Header().Set(UploadChecksumHeader, fmt.Sprintf("%x", d.hash.Sum(nil)))
+}
+
+// isUploadTooLarge reports whether err came from reading an upload past the limit
+func isUploadTooLarge(err error) bool {
+	var tooLarge *http.MaxBytesError
+	return errors.As(err, &tooLarge)
+}
+
+// sendReadError answers a failure to read an upload body: 413 when it is over the limit, otherwise a
+// 400 with message
+func (h *BaseHandler) sendReadError(w http.ResponseWriter, err error, message string) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		h.sendError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Upload exceeds the maximum size of %d bytes", tooLarge.Limit))
+		return
+	}
+	h.sendError(w, http.StatusBadRequest, message)
+}
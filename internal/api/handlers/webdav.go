@@ -0,0 +1,463 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	spectrafsmodels "github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+	"github.com/go-chi/chi/v5"
+)
+
+// WebDAV methods beyond the standard HTTP set; chi rejects methods it does not know about
+const (
+	MethodPropfind = "PROPFIND"
+	MethodMkcol    = "MKCOL"
+)
+
+const (
+	davReadMethods  = "OPTIONS, GET, HEAD, PROPFIND"
+	davWriteMethods = "PUT, MKCOL, DELETE"
+)
+
+// DAVHandler serves worlds over WebDAV (class 1, no locking) so they can be mounted as a drive
+// PROPFIND and GET go through the fs.FS wrapper, so listings generate children like ListChildren;
+// PUT, MKCOL and DELETE map onto UploadFile, CreateFolder and DeleteNode and only apply to primary
+type DAVHandler struct {
+	fs *sdk.SpectraFS
+}
+
+// NewDAVHandler creates a new WebDAV handler
+func NewDAVHandler(fs *sdk.SpectraFS) *DAVHandler {
+	return &DAVHandler{
+		fs: fs,
+	}
+}
+
+// davTarget is the node named by a /dav request URL
+type davTarget struct {
+	world string
+	name  string // fs.FS name ("." for the root)
+}
+
+// ServeHTTP dispatches a WebDAV request on its method
+func (h *DAVHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	target, ok := h.parseTarget(w, req)
+	if !ok {
+		return
+	}
+
+	switch req.Method {
+	case http.MethodOptions:
+		h.options(w, target)
+	case MethodPropfind:
+		h.propfind(w, req, target)
+	case http.MethodGet, http.MethodHead:
+		h.get(w, req, target)
+	case http.MethodPut:
+		h.put(w, req, target)
+	case MethodMkcol:
+		h.mkcol(w, req, target)
+	case http.MethodDelete:
+		h.delete(w, req, target)
+	default:
+		w.Header().Set("Allow", h.allow(target))
+		http.Error(w, fmt.Sprintf("method %s is not supported", req.Method), http.StatusMethodNotAllowed)
+	}
+}
+
+// DAVChaosOp names the chaos operation of a WebDAV request; OPTIONS and unsupported methods have none
+func DAVChaosOp(req *http.Request) string {
+	switch req.Method {
+	case MethodPropfind:
+		return sdk.ChaosOpList
+	case http.MethodGet, http.MethodHead:
+		return sdk.ChaosOpRead
+	case http.MethodPut:
+		return sdk.ChaosOpUpload
+	case MethodMkcol:
+		return sdk.ChaosOpCreate
+	case http.MethodDelete:
+		return sdk.ChaosOpDelete
+	}
+	return ""
+}
+
+// parseTarget reads the world and path of a /dav request, writing an error response if they are invalid
+func (h *DAVHandler) parseTarget(w http.ResponseWriter, req *http.Request) (*davTarget, bool) {
+	world := chi.URLParam(req, "world")
+	if world != "primary" && !slices.Contains(h.fs.GetSecondaryTables(), world) {
+		http.Error(w, fmt.Sprintf("%v: %s", sdk.ErrUnknownWorld, world), http.StatusNotFound)
+		return nil, false
+	}
+
+	raw, err := url.PathUnescape(chi.URLParam(req, "*"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid path: %v", err), http.StatusBadRequest)
+		return nil, false
+	}
+	name := strings.Trim(raw, "/")
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		http.Error(w, fmt.Sprintf("Invalid path: %q", raw), http.StatusBadRequest)
+		return nil, false
+	}
+	return &davTarget{world: world, name: name}, true
+}
+
+// lookup resolves a path in a world through the fs.FS wrapper, so folders on the way are generated
+// Returns nil if nothing is there
+func (h *DAVHandler) lookup(world, name string) *types.Node {
+	info, err := fs.Stat(h.fs.AsFS(world), name)
+	if err != nil {
+		return nil
+	}
+	node, _ := info.Sys().(*types.Node)
+	return node
+}
+
+// allow lists the methods accepted for a world
+func (h *DAVHandler) allow(target *davTarget) string {
+	if target.world == "primary" && !h.fs.ReadOnly() {
+		return davReadMethods + ", " + davWriteMethods
+	}
+	return davReadMethods
+}
+
+// requirePrimary rejects writes to a read-only instance with 403 and to secondary worlds with 405
+func (h *DAVHandler) requirePrimary(w http.ResponseWriter, target *davTarget) bool {
+	if h.fs.ReadOnly() {
+		http.Error(w, sdk.ErrReadOnly.Error(), http.StatusForbidden)
+		return false
+	}
+	if target.world == "primary" {
+		return true
+	}
+	w.Header().Set("Allow", davReadMethods)
+	http.Error(w, fmt.Sprintf("world %s is read-only; writes go to primary", target.world), http.StatusMethodNotAllowed)
+	return false
+}
+
+// options advertises WebDAV class 1 and the methods accepted for the world
+func (h *DAVHandler) options(w http.ResponseWriter, target *davTarget) {
+	w.Header().Set("DAV", "1")
+	w.Header().Set("MS-Author-Via", "DAV")
+	w.Header().Set("Allow", h.allow(target))
+	w.WriteHeader(http.StatusOK)
+}
+
+// davPropfind is a PROPFIND request body; an empty body means allprop
+type davPropfind struct {
+	XMLName  xml.Name  `xml:"DAV: propfind"`
+	AllProp  *struct{} `xml:"DAV: allprop"`
+	PropName *struct{} `xml:"DAV: propname"`
+	Prop     *struct {
+		Names []struct {
+			XMLName xml.Name
+		} `xml:",any"`
+	} `xml:"DAV: prop"`
+}
+
+// davProps are the live properties served for every node, in response order
+var davProps = []string{"displayname", "resourcetype", "getcontentlength", "getlastmodified", "getetag", "getcontenttype"}
+
+// propfind describes the node and, with Depth: 1, its children as a 207 multistatus
+// Depth: infinity (also the default when the header is missing) is refused, as RFC 4918 allows
+func (h *DAVHandler) propfind(w http.ResponseWriter, req *http.Request, target *davTarget) {
+	depth := req.Header.Get("Depth")
+	if depth != "0" && depth != "1" {
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, xml.Header+`<D:error xmlns:D="DAV:"><D:propfind-finite-depth/></D:error>`)
+		return
+	}
+
+	var body davPropfind
+	if err := xml.NewDecoder(req.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, fmt.Sprintf("Invalid PROPFIND body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	node := h.lookup(target.world, target.name)
+	if node == nil {
+		http.Error(w, fmt.Sprintf("Not found: /%s", target.name), http.StatusNotFound)
+		return
+	}
+
+	nodes := []*types.Node{node}
+	if depth == "1" && node.Type == types.NodeTypeFolder {
+		// ReadDir lists through the wrapper, generating the folder's children on first access
+		entries, err := fs.ReadDir(h.fs.AsFS(target.world), target.name)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to list children: %v", err), http.StatusInternalServerError)
+			return
+		}
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			if child, ok := info.Sys().(*types.Node); ok {
+				nodes = append(nodes, child)
+			}
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString(xml.Header)
+	out.WriteString(`<D:multistatus xmlns:D="DAV:">`)
+	for _, n := range nodes {
+		h.writeResponse(&out, target.world, n, &body)
+	}
+	out.WriteString(`</D:multistatus>`)
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	io.WriteString(w, out.String())
+}
+
+// writeResponse appends one node's <D:response> to a multistatus body
+// Requested properties the node does not have (or that are not DAV: live properties) come back as 404
+func (h *DAVHandler) writeResponse(out *strings.Builder, world string, node *types.Node, body *davPropfind) {
+	values := davValues(node)
+
+	out.WriteString(`<D:response><D:href>`)
+	xml.EscapeText(out, []byte(davHref(world, node)))
+	out.WriteString(`</D:href>`)
+
+	if body.PropName != nil {
+		out.WriteString(`<D:propstat><D:prop>`)
+		for _, prop := range davProps {
+			if _, ok := values[prop]; ok {
+				fmt.Fprintf(out, `<D:%s/>`, prop)
+			}
+		}
+		out.WriteString(`</D:prop><D:status>HTTP/1.1 200 OK</D:status></D:propstat></D:response>`)
+		return
+	}
+
+	var found []string
+	var missing []xml.Name
+	if body.Prop == nil {
+		for _, prop := range davProps {
+			if _, ok := values[prop]; ok {
+				found = append(found, prop)
+			}
+		}
+	} else {
+		for _, n := range body.Prop.Names {
+			if _, ok := values[n.XMLName.Local]; ok && n.XMLName.Space == "DAV:" {
+				found = append(found, n.XMLName.Local)
+			} else {
+				missing = append(missing, n.XMLName)
+			}
+		}
+	}
+
+	if len(found) > 0 {
+		out.WriteString(`<D:propstat><D:prop>`)
+		for _, prop := range found {
+			fmt.Fprintf(out, `<D:%s>%s</D:%s>`, prop, values[prop], prop)
+		}
+		out.WriteString(`</D:prop><D:status>HTTP/1.1 200 OK</D:status></D:propstat>`)
+	}
+	if len(missing) > 0 {
+		out.WriteString(`<D:propstat><D:prop>`)
+		for _, name := range missing {
+			out.WriteString(`<R:`)
+			xml.EscapeText(out, []byte(name.Local))
+			out.WriteString(` xmlns:R="`)
+			xml.EscapeText(out, []byte(name.Space))
+			out.WriteString(`"/>`)
+		}
+		out.WriteString(`</D:prop><D:status>HTTP/1.1 404 Not Found</D:status></D:propstat>`)
+	}
+	out.WriteString(`</D:response>`)
+}
+
+// davValues renders a node's live properties as escaped XML content, keyed by property name
+func davValues(node *types.Node) map[string]string {
+	escape := func(s string) string {
+		var b strings.Builder
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+
+	values := map[string]string{
+		"displayname":     escape(node.Name),
+		"resourcetype":    "",
+		"getlastmodified": node.LastUpdated.UTC().Format(http.TimeFormat),
+	}
+	if node.Type == types.NodeTypeFolder {
+		values["resourcetype"] = `<D:collection/>`
+		return values
+	}
+	values["getcontentlength"] = strconv.FormatInt(node.Size, 10)
+	values["getcontenttype"] = "application/octet-stream"
+	if node.Checksum != nil {
+		values["getetag"] = escape(strconv.Quote(*node.Checksum))
+	}
+	return values
+}
+
+// davHref is the URL path of a node under /dav/{world}; folders end in a slash
+func davHref(world string, node *types.Node) string {
+	href := "/dav/" + url.PathEscape(world)
+	for _, segment := range strings.Split(strings.Trim(node.Path, "/"), "/") {
+		if segment != "" {
+			href += "/" + url.PathEscape(segment)
+		}
+	}
+	if node.Type == types.NodeTypeFolder {
+		href += "/"
+	}
+	return href
+}
+
+// get streams a file's content like /api/v1/items/{id}/raw; collections have no content
+func (h *DAVHandler) get(w http.ResponseWriter, req *http.Request, target *davTarget) {
+	node := h.lookup(target.world, target.name)
+	if node == nil {
+		http.Error(w, fmt.Sprintf("Not found: /%s", target.name), http.StatusNotFound)
+		return
+	}
+	if node.Type == types.NodeTypeFolder {
+		w.Header().Set("Allow", "OPTIONS, PROPFIND")
+		http.Error(w, "collections have no content; use PROPFIND to list them", http.StatusMethodNotAllowed)
+		return
+	}
+
+	reader, node, err := h.fs.OpenFileDataContext(req.Context(), node.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get file data: %v", err), http.StatusInternalServerError)
+		return
+	}
+	serveFileContent(w, req, node, reader)
+}
+
+// put uploads the request body as a file, overwriting an existing file in place
+// 201 for a new file, 204 for an overwrite, 409 if the parent collection is missing
+func (h *DAVHandler) put(w http.ResponseWriter, req *http.Request, target *davTarget) {
+	if !h.requirePrimary(w, target) {
+		return
+	}
+
+	existing := h.lookup(target.world, target.name)
+	if existing != nil && existing.Type == types.NodeTypeFolder {
+		w.Header().Set("Allow", "OPTIONS, PROPFIND, MKCOL, DELETE")
+		http.Error(w, "cannot PUT to a collection", http.StatusMethodNotAllowed)
+		return
+	}
+	parent := h.lookup(target.world, path.Dir(target.name))
+	if parent == nil || parent.Type != types.NodeTypeFolder {
+		http.Error(w, fmt.Sprintf("Parent collection not found: /%s/", path.Dir(target.name)), http.StatusConflict)
+		return
+	}
+
+	limitUpload(w, req, h.fs)
+	node, err := h.fs.UploadFileFrom(req.Context(), &spectrafsmodels.UploadFileRequest{
+		ParentID:  parent.ID,
+		Name:      path.Base(target.name),
+		Overwrite: true,
+	}, req.Body)
+	if err != nil {
+		switch {
+		case errors.Is(err, sdk.ErrPathExists):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, sdk.ErrInvalidInput):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case isUploadTooLarge(err):
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		default:
+			http.Error(w, fmt.Sprintf("Failed to write file: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if node.Checksum != nil {
+		w.Header().Set("ETag", strconv.Quote(*node.Checksum))
+	}
+	if existing != nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// mkcol creates a collection; 405 if something is already there, 409 if the parent is missing
+func (h *DAVHandler) mkcol(w http.ResponseWriter, req *http.Request, target *davTarget) {
+	if !h.requirePrimary(w, target) {
+		return
+	}
+	if req.ContentLength > 0 {
+		http.Error(w, "MKCOL request bodies are not supported", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if target.name == "." || h.lookup(target.world, target.name) != nil {
+		w.Header().Set("Allow", h.allow(target))
+		http.Error(w, fmt.Sprintf("Already exists: /%s", target.name), http.StatusMethodNotAllowed)
+		return
+	}
+	parent := h.lookup(target.world, path.Dir(target.name))
+	if parent == nil || parent.Type != types.NodeTypeFolder {
+		http.Error(w, fmt.Sprintf("Parent collection not found: /%s/", path.Dir(target.name)), http.StatusConflict)
+		return
+	}
+
+	_, err := h.fs.CreateFolderContext(req.Context(), &spectrafsmodels.CreateFolderRequest{
+		ParentID: parent.ID,
+		Name:     path.Base(target.name),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sdk.ErrPathExists):
+			w.Header().Set("Allow", h.allow(target))
+			http.Error(w, err.Error(), http.StatusMethodNotAllowed)
+		default:
+			http.Error(w, fmt.Sprintf("Failed to create collection: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// delete removes a file or a collection with everything under it, as WebDAV DELETE requires
+func (h *DAVHandler) delete(w http.ResponseWriter, req *http.Request, target *davTarget) {
+	if !h.requirePrimary(w, target) {
+		return
+	}
+
+	node := h.lookup(target.world, target.name)
+	if node == nil {
+		http.Error(w, fmt.Sprintf("Not found: /%s", target.name), http.StatusNotFound)
+		return
+	}
+
+	err := h.fs.DeleteNodeContext(req.Context(), &spectrafsmodels.DeleteNodeRequest{
+		ID:        node.ID,
+		Recursive: true,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sdk.ErrRootProtected):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, fmt.Sprintf("Failed to delete node: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
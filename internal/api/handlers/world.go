@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	apimodels "github.com/Project-Sylos/Spectra/internal/api/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+	"github.com/go-chi/chi/v5"
+)
+
+// WorldHandler handles per-world endpoints
+type WorldHandler struct {
+	BaseHandler
+	fs *sdk.SpectraFS
+}
+
+// NewWorldHandler creates a new world handler
+func NewWorldHandler(fs *sdk.SpectraFS) *WorldHandler {
+	return &WorldHandler{
+		fs: fs,
+	}
+}
+
+// ListWorlds handles the list worlds endpoint: the valid world names with their probabilities
+func (h *WorldHandler) ListWorlds(w http.ResponseWriter, req *http.Request) {
+	worlds := h.fs.Worlds()
+	h.sendSuccess(w, fmt.Sprintf("%d world(s) configured", len(worlds)), worlds)
+}
+
+// AddWorld handles the add world endpoint
+func (h *WorldHandler) AddWorld(w http.ResponseWriter, req *http.Request) {
+	world := chi.URLParam(req, "world")
+
+	var apiRequest apimodels.AddWorldRequest
+	if err := decodeJSON(req, &apiRequest); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	info, err := h.fs.AddWorld(world, apiRequest.Probability)
+	if err != nil {
+		h.sendFailure(w, "Failed to add world", err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusCreated, types.APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("World %s added", world),
+		Data:    info,
+	})
+}
+
+// RemoveWorld handles the remove world endpoint
+func (h *WorldHandler) RemoveWorld(w http.ResponseWriter, req *http.Request) {
+	world := chi.URLParam(req, "world")
+
+	if err := h.fs.RemoveWorld(world); err != nil {
+		h.sendFailure(w, "Failed to remove world", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("World %s removed", world), nil)
+}
+
+// ApplyRetention handles the apply retention endpoint
+func (h *WorldHandler) ApplyRetention(w http.ResponseWriter, req *http.Request) {
+	world := chi.URLParam(req, "world")
+	if world == "" {
+		h.sendError(w, http.StatusBadRequest, "world is required")
+		return
+	}
+
+	result, err := h.fs.ApplyRetention(world)
+	if err != nil {
+		h.sendFailure(w, "Failed to apply retention", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("Retention applied to world %s", world), result)
+}
+
+// SetExistence handles the set world existence endpoint
+func (h *WorldHandler) SetExistence(w http.ResponseWriter, req *http.Request) {
+	world := chi.URLParam(req, "world")
+
+	var apiRequest apimodels.SetWorldExistenceRequest
+	if err := decodeJSON(req, &apiRequest); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if apiRequest.ID == "" {
+		h.sendError(w, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	result, err := h.fs.SetWorldExistence(apiRequest.ID, world, apiRequest.Exists, apiRequest.Recursive)
+	if err != nil {
+		h.sendFailure(w, "Failed to set world existence", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("Existence set for %d node(s) in world %s", result.Updated, world), result)
+}
+
+// Diff handles the world diff endpoint
+// Query parameters: a (default primary), b (required), root (subtree path), limit (0 = all) and cursor
+func (h *WorldHandler) Diff(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	worldA := query.Get("a")
+	if worldA == "" {
+		worldA = "primary"
+	}
+	worldB := query.Get("b")
+	if worldB == "" {
+		h.sendError(w, http.StatusBadRequest, "b is required")
+		return
+	}
+
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			h.sendError(w, http.StatusBadRequest, "limit must be a non-negative integer")
+			return
+		}
+		limit = parsed
+	}
+
+	diff, err := h.fs.DiffWorlds(req.Context(), worldA, worldB, sdk.DiffOptions{
+		Root:   query.Get("root"),
+		Limit:  limit,
+		Cursor: query.Get("cursor"),
+	})
+	if err != nil {
+		h.sendFailure(w, "Failed to diff worlds", err)
+		return
+	}
+
+	h.sendSuccess(w, fmt.Sprintf("Diffed worlds %s and %s", worldA, worldB), diff)
+}
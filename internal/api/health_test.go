@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/api/handlers"
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	server, fs := newServer(t, func(*sdk.Config) {})
+
+	ready := func(path string) (int, string, types.HealthReport) {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var envelope struct {
+			Code string             `json:"code"`
+			Data types.HealthReport `json:"data"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		return resp.StatusCode, envelope.Code, envelope.Data
+	}
+
+	for _, path := range []string{"/health/ready", "/health"} {
+		if status, _, report := ready(path); status != http.StatusOK || !report.Ready || report.DBPath != sdk.MemoryDBPath {
+			t.Errorf("GET %s = %d %+v", path, status, report)
+		}
+	}
+
+	// Once the database is closed, readiness fails while liveness still answers
+	fs.Close()
+	for _, path := range []string{"/health/ready", "/health"} {
+		status, code, report := ready(path)
+		if status != http.StatusServiceUnavailable || code != handlers.CodeNotReady || report.Ready || report.LastError == "" {
+			t.Errorf("GET %s with the database closed = %d %s %+v", path, status, code, report)
+		}
+	}
+	if status, _ := send(t, server, http.MethodGet, "/health/live", nil, ""); status != http.StatusOK {
+		t.Errorf("GET /health/live with the database closed = %d, want 200", status)
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+func TestInstanceRoutes(t *testing.T) {
+	server, m := newMultiInstanceServer(t)
+
+	instanceNames := func() []string {
+		t.Helper()
+		resp, err := http.Get(server.URL + "/api/v1/instances")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var envelope struct {
+			Data []sdk.InstanceConfig `json:"data"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET /api/v1/instances = %d, %v", resp.StatusCode, err)
+		}
+		var names []string
+		for _, instance := range envelope.Data {
+			names = append(names, instance.Name)
+		}
+		return names
+	}
+	if names := instanceNames(); !slices.Equal(names, []string{"a"}) {
+		t.Fatalf("instances = %v, want [a]", names)
+	}
+
+	// Each instance serves the full API below its name, against its own filesystem
+	if status, resp := post(t, server, "/api/v1/instances/a/items/folder", `{"parent_path": "/", "table_name": "primary", "name": "only-a"}`, nil); status != http.StatusCreated {
+		t.Fatalf("create folder in instance a = %d %+v", status, resp)
+	}
+	a, _ := m.Instance("a")
+	if !slices.Contains(rootFolders(t, a), "only-a") || slices.Contains(rootFolders(t, m.Main()), "only-a") {
+		t.Error("the folder created under /instances/a did not land in instance a alone")
+	}
+	if status, _ := send(t, server, http.MethodGet, "/api/v1/instances/missing/worlds", nil, ""); status != http.StatusNotFound {
+		t.Errorf("request to an unknown instance = %d, want 404", status)
+	}
+
+	if status, resp := post(t, server, "/api/v1/instances", `{"name": "b", "seed": {"max_depth": 3}}`, nil); status != http.StatusCreated {
+		t.Fatalf("create instance b = %d %+v", status, resp)
+	}
+	b, err := m.Instance("b")
+	if err != nil || b.GetConfig().Seed.MaxDepth != 3 || b.GetConfig().Seed.Seed != 7 {
+		t.Fatalf("instance b = %v, %v, want max_depth 3 over the main seed", b, err)
+	}
+	if status, _ := post(t, server, "/api/v1/instances", `{"name": "b"}`, nil); status != http.StatusConflict {
+		t.Errorf("creating b twice = %d, want 409", status)
+	}
+	if status, _ := post(t, server, "/api/v1/instances", `{"name": "b/c"}`, nil); status != http.StatusBadRequest {
+		t.Errorf("creating b/c = %d, want 400", status)
+	}
+
+	if status, code := send(t, server, http.MethodDelete, "/api/v1/instances/b", nil, ""); status != http.StatusOK {
+		t.Fatalf("DELETE instance b = %d %s", status, code)
+	}
+	if names := instanceNames(); !slices.Equal(names, []string{"a"}) {
+		t.Errorf("instances after deleting b = %v, want [a]", names)
+	}
+	if status, _ := send(t, server, http.MethodDelete, "/api/v1/instances/b", nil, ""); status != http.StatusNotFound {
+		t.Errorf("deleting b twice = %d, want 404", status)
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/api/handlers"
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// waitForJob polls jobs until the job with id reaches status, failing the test after 5s
+func waitForJob(t *testing.T, jobs *handlers.JobManager, id, status string) *types.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := jobs.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %s after 5s, want %s", id, job.Status, status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestJobManagerCapsAndCancels(t *testing.T) {
+	_, fs := newServer(t, func(cfg *sdk.Config) { cfg.API.MaxJobs = 1 })
+	jobs := handlers.NewJobManager(fs)
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	first := jobs.Submit(ctx, types.JobGenerate, func(ctx context.Context) (any, error) {
+		<-release
+		return map[string]int{"answer": 42}, nil
+	}, nil)
+	waitForJob(t, jobs, first.ID, types.JobRunning)
+
+	// The only slot is taken, so the next job queues until it is cancelled
+	second := jobs.Submit(ctx, types.JobGenerate, func(ctx context.Context) (any, error) {
+		t.Error("a job ran past the max_jobs cap")
+		return nil, nil
+	}, nil)
+	time.Sleep(20 * time.Millisecond)
+	if job, _ := jobs.Get(second.ID); job.Status != types.JobQueued {
+		t.Fatalf("second job is %s with the slot taken, want queued", job.Status)
+	}
+	if _, cancelled, err := jobs.Cancel(second.ID); !cancelled || err != nil {
+		t.Fatalf("Cancel(queued) = %t, %v", cancelled, err)
+	}
+	waitForJob(t, jobs, second.ID, types.JobCancelled)
+
+	close(release)
+	done := waitForJob(t, jobs, first.ID, types.JobDone)
+	if string(done.Result) != `{"answer":42}` || done.StartedAt == nil || done.FinishedAt == nil {
+		t.Errorf("finished job = %+v", done)
+	}
+	if _, cancelled, _ := jobs.Cancel(first.ID); cancelled {
+		t.Error("cancelling a finished job reported it cancelled")
+	}
+
+	// Finished jobs are read back from their records
+	if recorded, err := fs.GetJob(first.ID); err != nil || recorded.Status != types.JobDone {
+		t.Errorf("recorded job = %+v, %v", recorded, err)
+	}
+}
+
+func TestGenerateRunsAsJob(t *testing.T) {
+	server, fs := newServer(t, func(cfg *sdk.Config) { cfg.Seed.MaxDepth = 2 })
+
+	var job types.Job
+	status, resp := post(t, server, "/api/v1/generate", "", &job)
+	if status != http.StatusAccepted || job.ID == "" || job.Kind != types.JobGenerate {
+		t.Fatalf("POST /api/v1/generate = %d %+v, job %+v", status, resp, job)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !types.IsFinishedJobStatus(job.Status) {
+		if time.Now().After(deadline) {
+			t.Fatalf("generate job is %s after 5s", job.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+		httpResp, err := http.Get(server.URL + "/api/v1/jobs/" + job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var envelope struct {
+			Data types.Job `json:"data"`
+		}
+		err = json.NewDecoder(httpResp.Body).Decode(&envelope)
+		httpResp.Body.Close()
+		if err != nil || httpResp.StatusCode != http.StatusOK {
+			t.Fatalf("GET job = %d, %v", httpResp.StatusCode, err)
+		}
+		job = envelope.Data
+	}
+	if job.Status != types.JobDone || len(job.Progress) == 0 {
+		t.Errorf("generate job ended %+v", job)
+	}
+	if stats, err := fs.GetStats(); err != nil || stats.MaxDepth != 2 {
+		t.Errorf("stats after the job = %+v, %v", stats, err)
+	}
+
+	if status, _ := send(t, server, http.MethodDelete, "/api/v1/jobs/"+job.ID, nil, ""); status != http.StatusConflict {
+		t.Errorf("DELETE of a finished job = %d, want 409", status)
+	}
+	if status, _ := send(t, server, http.MethodGet, "/api/v1/jobs/missing", nil, ""); status != http.StatusNotFound {
+		t.Errorf("GET of an unknown job = %d, want 404", status)
+	}
+}
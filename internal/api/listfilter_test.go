@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+func TestListFilterPassthrough(t *testing.T) {
+	server, _ := newServer(t, func(*sdk.Config) {})
+
+	var all, files types.ListResult
+	if status, resp := post(t, server, "/api/v1/items/list", `{"parent_id": "root", "table_name": "primary"}`, &all); status != http.StatusOK {
+		t.Fatalf("list = %d %+v", status, resp)
+	}
+	status, resp := post(t, server, "/api/v1/items/list", `{"parent_id": "root", "table_name": "primary", "type_filter": "file", "sort_by": "size", "sort_order": "desc"}`, &files)
+	if status != http.StatusOK {
+		t.Fatalf("filtered list = %d %+v", status, resp)
+	}
+	if len(files.Folders) != 0 || len(files.Files) != len(all.Files) || files.Filtered != len(all.Folders) {
+		t.Errorf("type_filter file listed %d folders and %d files with %d filtered out, want 0, %d and %d",
+			len(files.Folders), len(files.Files), files.Filtered, len(all.Files), len(all.Folders))
+	}
+
+	for _, body := range []string{
+		`{"parent_id": "root", "table_name": "primary", "sort_by": "owner"}`,
+		`{"parent_id": "root", "table_name": "primary", "type_filter": "device"}`,
+		`{"parent_id": "root", "table_name": "primary", "min_size": 10, "max_size": 5}`,
+	} {
+		if status, code := send(t, server, http.MethodPost, "/api/v1/items/list", nil, body); status != http.StatusBadRequest || code != "invalid_input" {
+			t.Errorf("list %s = %d %q, want 400 invalid_input", body, status, code)
+		}
+	}
+}
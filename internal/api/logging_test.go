@@ -0,0 +1,56 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/logging"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+func TestRequestLogCarriesRequestID(t *testing.T) {
+	cfg := sdk.DefaultConfig()
+	cfg.Seed.DBPath = sdk.MemoryDBPath
+	fs, err := sdk.NewFromConfig(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.LevelDebug, logging.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := NewRouter(fs)
+	router.SetLogger(logger)
+	server := httptest.NewServer(router.SetupRoutes())
+	t.Cleanup(func() {
+		server.Close()
+		fs.Close()
+	})
+
+	status, _ := send(t, server, http.MethodPost, "/api/v1/items/list", map[string]string{"X-Request-Id": "req-42"}, `{"parent_path": "/", "table_name": "primary"}`)
+	if status != http.StatusOK {
+		t.Fatalf("list = %d", status)
+	}
+
+	var request map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		if record["msg"] == "request" {
+			request = record
+		}
+	}
+	if request == nil {
+		t.Fatalf("no request record in %q", buf.String())
+	}
+	if request["request_id"] != "req-42" || request["method"] != http.MethodPost || request["path"] != "/api/v1/items/list" || request["status"] != float64(http.StatusOK) {
+		t.Errorf("request record = %v", request)
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+func TestNodeMetadataEndpoint(t *testing.T) {
+	server, fs := newServer(t, func(*sdk.Config) {})
+	file, _ := rootFile(t, fs)
+	path := "/api/v1/node/" + file.ID + "/metadata"
+
+	if status, code := send(t, server, http.MethodPatch, path, nil, `{"metadata": {"owner": "alice", "content-type": "text/plain"}}`); status != http.StatusOK {
+		t.Fatalf("PATCH metadata = %d %s", status, code)
+	}
+	if status, code := send(t, server, http.MethodPatch, path, nil, `{"metadata": {"content-type": ""}}`); status != http.StatusOK {
+		t.Fatalf("PATCH removing a key = %d %s", status, code)
+	}
+
+	resp, err := http.Get(server.URL + "/api/v1/node/" + file.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var envelope struct {
+		Data sdk.Node `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET node = %d, %v", resp.StatusCode, err)
+	}
+	if want := map[string]string{"owner": "alice"}; !maps.Equal(envelope.Data.Metadata, want) {
+		t.Errorf("node metadata = %v, want %v", envelope.Data.Metadata, want)
+	}
+
+	if status, _ := send(t, server, http.MethodPatch, path, nil, `{"metadata": {}}`); status != http.StatusBadRequest {
+		t.Errorf("PATCH without entries = %d, want 400", status)
+	}
+	if status, _ := send(t, server, http.MethodPatch, "/api/v1/node/missing/metadata", nil, `{"metadata": {"a": "b"}}`); status != http.StatusNotFound {
+		t.Errorf("PATCH of an unknown node = %d, want 404", status)
+	}
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"context"
+
+	"github.com/Project-Sylos/Spectra/internal/metrics"
+)
+
+// newMetrics creates the registry behind /metrics and has the main filesystem record into it
+// Named instances are covered by the request metrics only
+func (r *Router) newMetrics() *metrics.Registry {
+	registry := metrics.NewRegistry()
+	r.fs.SetMetrics(registry)
+
+	// Node counts are read from the stats bucket on each scrape
+	registry.GaugeFunc(metrics.Nodes, func() []metrics.Sample {
+		tables, err := r.fs.GetTableInfoContext(context.Background())
+		if err != nil {
+			return nil
+		}
+		samples := make([]metrics.Sample, 0, len(tables))
+		for _, table := range tables {
+			samples = append(samples, metrics.Sample{
+				Labels: []metrics.Label{{Name: "world", Value: table.Name}},
+				Value:  float64(table.RowCount),
+			})
+		}
+		return samples
+	})
+	return registry
+}
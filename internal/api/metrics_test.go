@@ -0,0 +1,111 @@
+package api
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/metrics"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// scrape reads /metrics and returns each series (name and labels) with its value
+func scrape(t *testing.T, server *httptest.Server) map[string]float64 {
+	t.Helper()
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /metrics = %d", resp.StatusCode)
+	}
+
+	values := make(map[string]float64)
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		value, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("bad sample %q: %v", line, err)
+		}
+		values[line[:i]] = value
+	}
+	return values
+}
+
+func TestMetricsRecordListing(t *testing.T) {
+	server, _ := newServer(t, func(cfg *sdk.Config) { cfg.API.MetricsEnabled = true })
+	const listed = `spectra_http_requests_total{method="POST",route="/api/v1/items/list",status="200"}`
+
+	before := scrape(t, server)
+	if status, _ := post(t, server, "/api/v1/items/list", `{"parent_id": "root"}`, nil); status != http.StatusOK {
+		t.Fatalf("list = %d", status)
+	}
+	after := scrape(t, server)
+
+	for _, series := range []string{
+		listed,
+		`spectra_http_request_duration_seconds_count{method="POST",route="/api/v1/items/list"}`,
+		metrics.NodesGenerated,
+		metrics.ChildrenGenerationDuration + "_count",
+		metrics.BoltTxDuration + `_count{op="update"}`,
+	} {
+		if after[series] <= before[series] {
+			t.Errorf("%s went from %v to %v over a first listing", series, before[series], after[series])
+		}
+	}
+	if after[metrics.Nodes+`{world="primary"}`] < after[metrics.NodesGenerated] {
+		t.Errorf("%s{world=\"primary\"} = %v, below the %v nodes generated", metrics.Nodes, after[metrics.Nodes+`{world="primary"}`], after[metrics.NodesGenerated])
+	}
+
+	// Listing again generates nothing
+	post(t, server, "/api/v1/items/list", `{"parent_id": "root"}`, nil)
+	again := scrape(t, server)
+	if again[metrics.NodesGenerated] != after[metrics.NodesGenerated] || again[listed] != after[listed]+1 {
+		t.Errorf("second listing: %v nodes generated (was %v), %v requests (was %v)",
+			again[metrics.NodesGenerated], after[metrics.NodesGenerated], again[listed], after[listed])
+	}
+}
+
+func TestMetricsDescribed(t *testing.T) {
+	server, _ := newServer(t, func(cfg *sdk.Config) { cfg.API.MetricsEnabled = true })
+	post(t, server, "/api/v1/items/list", `{"parent_id": "root"}`, nil)
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	types := make(map[string]string)
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) == 4 && fields[1] == "TYPE" {
+			types[fields[2]] = fields[3]
+		}
+	}
+	for _, desc := range metrics.Descs {
+		if types[desc.Name] != desc.Type {
+			t.Errorf("%s has TYPE %q, want %q", desc.Name, types[desc.Name], desc.Type)
+		}
+	}
+}
+
+func TestMetricsDisabled(t *testing.T) {
+	server, _ := newServer(t, func(*sdk.Config) {})
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /metrics without api.metrics_enabled = %d, want 404", resp.StatusCode)
+	}
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// APIKeyHeader carries an API token for clients that cannot set an Authorization header
+const APIKeyHeader = "X-API-Key"
+
+// roleKey is the context key under which Authenticate stores the request's role
+type roleKey struct{}
+
+// Authenticate returns middleware that resolves the request's token to its role, answering 401 when
+// the token is missing or unknown. With no tokens configured every request is treated as admin, so
+// the API stays open
+func Authenticate(auth types.AuthConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if len(auth.Tokens) == 0 {
+				next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), roleKey{}, types.RoleAdmin)))
+				return
+			}
+
+			token, ok := requestToken(req)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="spectra"`)
+				writeAuthError(w, http.StatusUnauthorized, "unauthorized", "Missing API token")
+				return
+			}
+			role, ok := tokenRole(auth.Tokens, token)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="spectra", error="invalid_token"`)
+				writeAuthError(w, http.StatusUnauthorized, "unauthorized", "Invalid API token")
+				return
+			}
+			next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), roleKey{}, role)))
+		})
+	}
+}
+
+// RequireRole returns middleware that answers 403 unless Authenticate granted at least role
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return RequireRoleFunc(func(*http.Request) string { return role })
+}
+
+// RequireRoleFunc is RequireRole for routes that serve several operations: roleFor names the role
+// each request needs ("" needs none beyond a valid token)
+func RequireRoleFunc(roleFor func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			granted, ok := req.Context().Value(roleKey{}).(string)
+			if !ok {
+				writeAuthError(w, http.StatusUnauthorized, "unauthorized", "Missing API token")
+				return
+			}
+			required := roleFor(req)
+			if roleRank(granted) < roleRank(required) {
+				writeAuthError(w, http.StatusForbidden, "forbidden", fmt.Sprintf("This operation requires the %s role; the token has %s", required, granted))
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// MethodRole is the role needed by a path-based request: read for GET, HEAD, OPTIONS and PROPFIND, write otherwise
+func MethodRole(req *http.Request) string {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, "PROPFIND":
+		return types.RoleRead
+	}
+	return types.RoleWrite
+}
+
+// requestToken returns the bearer token, Basic auth password or API key sent with req
+func requestToken(req *http.Request) (string, bool) {
+	if header := req.Header.Get("Authorization"); header != "" {
+		// WebDAV clients mostly speak Basic auth; the password is taken as the token
+		if _, password, ok := req.BasicAuth(); ok {
+			return password, password != ""
+		}
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			return "", false
+		}
+		token = strings.TrimSpace(token)
+		return token, token != ""
+	}
+	token := req.Header.Get(APIKeyHeader)
+	return token, token != ""
+}
+
+// tokenRole returns the role of the configured token matching token, comparing in constant time
+func tokenRole(tokens []types.AuthToken, token string) (string, bool) {
+	role, found := "", false
+	for _, candidate := range tokens {
+		if subtle.ConstantTimeCompare([]byte(candidate.Token), []byte(token)) == 1 && !found {
+			role, found = candidate.Role, true
+		}
+	}
+	if found && role == "" {
+		role = types.RoleAdmin
+	}
+	return role, found
+}
+
+// roleRank orders roles by privilege; unknown roles rank below read
+func roleRank(role string) int {
+	return slices.Index(types.AuthRoles, role)
+}
+
+// writeAuthError sends an APIResponse error with code for a rejected request
+func writeAuthError(w http.ResponseWriter, status int, code, message string) {
+	WriteJSON(w, status, types.APIResponse{
+		Success: false,
+		Code:    code,
+		Message: message,
+	})
+}
@@ -0,0 +1,377 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// CaseHeader lets a client pick the JSON field casing per request ("snake" or "camel")
+const CaseHeader = "X-Spectra-Case"
+
+// Field casing modes
+const (
+	CaseSnake = "snake"
+	CaseCamel = "camel"
+)
+
+// RawShaper is implemented by payloads whose json.RawMessage fields hold documents of a known Go
+// type. In camel mode the fields inside such a document are renamed by the type RawShape returns
+// for its JSON name; a raw field without a shape (e.g. debug bucket values) passes through untouched.
+type RawShaper interface {
+	RawShape(field string) any
+}
+
+// caseKey is the request context key the selected casing is stored under
+type caseKey struct{}
+
+// FieldCase returns middleware that selects the JSON field casing for legacy camelCase clients
+// The mode comes from the X-Spectra-Case header, falling back to defaultCase. It is applied where
+// bodies are encoded and decoded (WriteJSON and DecodeJSON), which rename struct fields by their Go
+// type: keys of maps holding user data (world names, metadata keys, bucket names) are never touched.
+func FieldCase(defaultCase string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			mode := strings.ToLower(req.Header.Get(CaseHeader))
+			if mode == "" {
+				mode = defaultCase
+			}
+			if mode != CaseCamel {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			req = req.WithContext(context.WithValue(req.Context(), caseKey{}, mode))
+			next.ServeHTTP(&caseWriter{ResponseWriter: w, mode: mode}, req)
+		})
+	}
+}
+
+// caseWriter carries the selected casing to WriteJSON, which only sees the response writer
+type caseWriter struct {
+	http.ResponseWriter
+	mode string
+}
+
+// Unwrap exposes the underlying writer so http.ResponseController can flush streamed responses
+func (w *caseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// responseCase returns the casing FieldCase selected for the response written through w
+func responseCase(w http.ResponseWriter) string {
+	for w != nil {
+		if cw, ok := w.(*caseWriter); ok {
+			return cw.mode
+		}
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = unwrapper.Unwrap()
+	}
+	return CaseSnake
+}
+
+// RequestCase returns the casing FieldCase selected for req (CaseSnake outside the middleware)
+func RequestCase(req *http.Request) string {
+	if mode, ok := req.Context().Value(caseKey{}).(string); ok {
+		return mode
+	}
+	return CaseSnake
+}
+
+// WriteJSON sends data as a JSON response with the given status code, in the casing FieldCase
+// selected for the request
+func WriteJSON(w http.ResponseWriter, statusCode int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if responseCase(w) == CaseCamel {
+		if converted, err := convertJSON(data, reflect.ValueOf(data), snakeToCamel); err == nil {
+			data = converted
+		}
+	}
+	json.NewEncoder(w).Encode(data)
+}
+
+// DecodeJSON decodes the JSON request body into target, a pointer. In camel mode fields are
+// accepted in either casing. An empty body returns io.EOF, as json.Decoder does.
+func DecodeJSON(req *http.Request, target any) error {
+	if RequestCase(req) != CaseCamel {
+		return json.NewDecoder(req.Body).Decode(target)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return err
+	}
+
+	converted, err := json.Marshal(renameFields(document, reflect.TypeOf(target), reflect.Value{}, camelToSnake))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(converted, target)
+}
+
+// convertJSON encodes data and renames the fields of the resulting document by value's Go type
+// Numbers are preserved exactly (no float64 round-trip) so no value is altered
+func convertJSON(data any, value reflect.Value, rename func(string) string) (any, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	if !value.IsValid() {
+		return document, nil
+	}
+	return renameFields(document, value.Type(), value, rename), nil
+}
+
+var (
+	marshalerType  = reflect.TypeFor[json.Marshaler]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// renameFields walks a decoded JSON document alongside the Go type it was encoded from (or is to
+// be decoded into) and renames the fields of structs, and the keys of map[string]any documents
+// built by handlers. Maps with any other element type are keyed by user data, so their keys are
+// kept and only their values are walked. value is the Go value when known (responses), used to
+// resolve interface fields; types with their own JSON encoding are left as they are.
+func renameFields(document any, t reflect.Type, value reflect.Value, rename func(string) string) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		if value.IsValid() {
+			value = indirect(value)
+		}
+	}
+	if t.Kind() == reflect.Interface {
+		if !value.IsValid() || value.IsNil() {
+			return document
+		}
+		value = value.Elem()
+		return renameFields(document, value.Type(), value, rename)
+	}
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		return document
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := document.(map[string]any)
+		if !ok {
+			return document
+		}
+		return renameStruct(object, t, value, rename)
+	case reflect.Map:
+		object, ok := document.(map[string]any)
+		if !ok {
+			return document
+		}
+		documentKeys := t.Elem().Kind() == reflect.Interface
+		renamed := make(map[string]any, len(object))
+		for key, child := range object {
+			var childValue reflect.Value
+			if value.IsValid() && t.Key().Kind() == reflect.String {
+				childValue = value.MapIndex(reflect.ValueOf(key).Convert(t.Key()))
+			}
+			if documentKeys {
+				key = rename(key)
+			}
+			renamed[key] = renameFields(child, t.Elem(), childValue, rename)
+		}
+		return renamed
+	case reflect.Slice, reflect.Array:
+		array, ok := document.([]any)
+		if !ok {
+			return document
+		}
+		for i, child := range array {
+			var childValue reflect.Value
+			if value.IsValid() && i < value.Len() {
+				childValue = value.Index(i)
+			}
+			array[i] = renameFields(child, t.Elem(), childValue, rename)
+		}
+		return array
+	default:
+		return document
+	}
+}
+
+// renameStruct renames the fields of a JSON object encoded from (or decoded into) the struct type t
+func renameStruct(object map[string]any, t reflect.Type, value reflect.Value, rename func(string) string) any {
+	fields := structFields(t)
+	var shaper RawShaper
+	if value.IsValid() && value.CanInterface() {
+		shaper, _ = value.Interface().(RawShaper)
+	} else {
+		shaper, _ = reflect.Zero(t).Interface().(RawShaper)
+	}
+
+	renamed := make(map[string]any, len(object))
+	for key, child := range object {
+		field, ok := fields.byName[key]
+		if !ok {
+			field, ok = fields.byCamel[key]
+		}
+		if !ok {
+			renamed[key] = child
+			continue
+		}
+
+		var fieldValue reflect.Value
+		if value.IsValid() {
+			fieldValue, _ = value.FieldByIndexErr(field.index)
+		}
+		fieldType := field.typ
+		if fieldType == rawMessageType {
+			var shape any
+			if shaper != nil {
+				shape = shaper.RawShape(field.name)
+			}
+			if shape == nil {
+				renamed[rename(field.name)] = child
+				continue
+			}
+			fieldType, fieldValue = reflect.TypeOf(shape), reflect.ValueOf(shape)
+		}
+		renamed[rename(field.name)] = renameFields(child, fieldType, fieldValue, rename)
+	}
+	return renamed
+}
+
+// indirect dereferences a pointer value, returning the zero Value for a nil pointer
+func indirect(value reflect.Value) reflect.Value {
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return reflect.Value{}
+	}
+	return value.Elem()
+}
+
+// jsonField is a struct field as encoding/json sees it
+type jsonField struct {
+	name  string // JSON (snake_case) name
+	index []int
+	typ   reflect.Type
+}
+
+// jsonFields indexes a struct type's JSON fields by name and by camelCase name
+type jsonFields struct {
+	byName  map[string]jsonField
+	byCamel map[string]jsonField
+}
+
+// fieldCache holds the jsonFields of each struct type seen
+var fieldCache sync.Map // reflect.Type -> *jsonFields
+
+// structFields returns the JSON fields of struct type t, including those promoted from embedded structs
+func structFields(t reflect.Type) *jsonFields {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.(*jsonFields)
+	}
+
+	fields := &jsonFields{byName: map[string]jsonField{}, byCamel: map[string]jsonField{}}
+	collectFields(t, nil, fields)
+	for _, field := range fields.byName {
+		fields.byCamel[snakeToCamel(field.name)] = field
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// collectFields adds the JSON fields of struct type t, reached through index, to fields
+// Fields of the outer struct win over promoted ones, as in encoding/json
+func collectFields(t reflect.Type, index []int, fields *jsonFields) {
+	var embedded []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				field.Index = fieldIndex
+				field.Type = fieldType
+				embedded = append(embedded, field)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields.byName[name] = jsonField{name: name, index: fieldIndex, typ: field.Type}
+	}
+
+	for _, field := range embedded {
+		promoted := &jsonFields{byName: map[string]jsonField{}}
+		collectFields(field.Type, field.Index, promoted)
+		for name, promotedField := range promoted.byName {
+			if _, exists := fields.byName[name]; !exists {
+				fields.byName[name] = promotedField
+			}
+		}
+	}
+}
+
+// snakeToCamel converts "depth_level" to "depthLevel"
+func snakeToCamel(key string) string {
+	if !strings.Contains(key, "_") {
+		return key
+	}
+	parts := strings.Split(key, "_")
+	var b strings.Builder
+	b.WriteString(parts[0])
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
+
+// camelToSnake converts "depthLevel" to "depth_level"; snake_case input is returned unchanged
+func camelToSnake(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
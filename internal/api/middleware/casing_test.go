@@ -0,0 +1,230 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/api/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// respond runs WriteJSON(data) behind FieldCase in the given mode and decodes the response body
+func respond(t *testing.T, mode string, data any) map[string]any {
+	t.Helper()
+	handler := FieldCase(CaseSnake)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		WriteJSON(w, http.StatusOK, data)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(CaseHeader, mode)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	var body map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response %q: %v", recorder.Body.String(), err)
+	}
+	return body
+}
+
+// decode runs DecodeJSON(body) into target behind FieldCase in the given mode
+func decode(t *testing.T, mode, body string, target any) error {
+	t.Helper()
+	var err error
+	handler := FieldCase(CaseSnake)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		err = DecodeJSON(req, target)
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(CaseHeader, mode)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	return err
+}
+
+// object returns body[key] as a JSON object, failing the test if it is not one
+func object(t *testing.T, body map[string]any, key string) map[string]any {
+	t.Helper()
+	value, ok := body[key].(map[string]any)
+	if !ok {
+		t.Fatalf("%q is %T (%v), want an object; body %v", key, body[key], body[key], body)
+	}
+	return value
+}
+
+func TestWriteJSONCamelRenamesFields(t *testing.T) {
+	node := types.Node{
+		ID:           "n1",
+		ParentID:     "p-root",
+		DepthLevel:   2,
+		ExistenceMap: map[string]bool{"primary": true, "S1": true, "myWorld": false},
+		Metadata:     map[string]string{"content_type": "text/plain", "ownerName": "ann"},
+	}
+	body := respond(t, CaseCamel, types.APIResponse{Success: true, Data: node})
+
+	data := object(t, body, "data")
+	for _, key := range []string{"id", "parentId", "depthLevel", "existenceMap", "metadata"} {
+		if _, ok := data[key]; !ok {
+			t.Errorf("camel node lacks %q: %v", key, data)
+		}
+	}
+	if _, ok := data["parent_id"]; ok {
+		t.Errorf("camel node kept parent_id: %v", data)
+	}
+
+	existence := object(t, data, "existenceMap")
+	if !reflect.DeepEqual(existence, map[string]any{"primary": true, "S1": true, "myWorld": false}) {
+		t.Errorf("existence map keys rewritten: %v", existence)
+	}
+	metadata := object(t, data, "metadata")
+	if !reflect.DeepEqual(metadata, map[string]any{"content_type": "text/plain", "ownerName": "ann"}) {
+		t.Errorf("metadata keys rewritten: %v", metadata)
+	}
+}
+
+func TestWriteJSONCamelKeepsUserKeyedMaps(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  any
+		field string // camelCase field holding the map
+		keys  []string
+	}{
+		{
+			name:  "index entries",
+			data:  types.IntegrityReport{IndexEntries: map[string]int64{"index_parent_id": 4, "index_path": 4}},
+			field: "indexEntries",
+			keys:  []string{"index_parent_id", "index_path"},
+		},
+		{
+			name:  "world coverage",
+			data:  types.AnalysisReport{WorldCoverage: map[string]float64{"s1": 70, "archive_2": 10}},
+			field: "worldCoverage",
+			keys:  []string{"s1", "archive_2"},
+		},
+		{
+			name:  "depth folders",
+			data:  types.CoverageCounters{Folders: map[string][]int64{"primary": {1}, "cold_store": {1}}},
+			field: "folders",
+			keys:  []string{"primary", "cold_store"},
+		},
+		{
+			name:  "world generation",
+			data:  types.Config{WorldGeneration: map[string]types.WorldGeneration{"edge_cache": {}}},
+			field: "worldGeneration",
+			keys:  []string{"edge_cache"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := respond(t, CaseCamel, types.APIResponse{Data: tt.data})
+			value := object(t, object(t, body, "data"), tt.field)
+			for _, key := range tt.keys {
+				if _, ok := value[key]; !ok {
+					t.Errorf("%s lacks key %q: %v", tt.field, key, value)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteJSONCamelHandlerDocuments(t *testing.T) {
+	body := respond(t, CaseCamel, types.APIResponse{Data: map[string]any{
+		"table_name":      "primary",
+		"remaining_nodes": 3,
+	}})
+
+	data := object(t, body, "data")
+	if !reflect.DeepEqual(data, map[string]any{"tableName": "primary", "remainingNodes": float64(3)}) {
+		t.Errorf("handler-built document = %v", data)
+	}
+}
+
+func TestWriteJSONCamelRawMessages(t *testing.T) {
+	entry := types.BucketEntry{Key: "k", Value: json.RawMessage(`{"parent_id":"p-root","existence_map":{"S1":true}}`)}
+	body := respond(t, CaseCamel, types.APIResponse{Data: []types.BucketEntry{entry}})
+
+	entries, ok := body["data"].([]any)
+	if !ok || len(entries) != 1 {
+		t.Fatalf("data = %v", body["data"])
+	}
+	value := object(t, entries[0].(map[string]any), "value")
+	if _, ok := value["parent_id"]; !ok {
+		t.Errorf("debug value was rewritten: %v", value)
+	}
+
+	progress, err := json.Marshal(types.GenerationProgress{NodesCreated: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := types.Job{ID: "j1", Kind: types.JobGenerate, Progress: progress}
+	body = respond(t, CaseCamel, types.APIResponse{Data: job})
+	jobProgress := object(t, object(t, body, "data"), "progress")
+	if _, ok := jobProgress["nodesCreated"]; !ok {
+		t.Errorf("generate progress not renamed: %v", jobProgress)
+	}
+}
+
+func TestWriteJSONSnakeUnchanged(t *testing.T) {
+	node := types.Node{ID: "n1", ParentID: "p-root", ExistenceMap: map[string]bool{"S1": true}}
+	body := respond(t, CaseSnake, types.APIResponse{Data: node})
+
+	data := object(t, body, "data")
+	if data["parent_id"] != "p-root" {
+		t.Errorf("snake node = %v", data)
+	}
+	if _, ok := object(t, data, "existence_map")["S1"]; !ok {
+		t.Errorf("snake existence map = %v", data)
+	}
+}
+
+func TestDecodeJSONCamel(t *testing.T) {
+	var request models.CopySubtreeRequest
+	err := decode(t, CaseCamel, `{"sourceId":"a","destination_parent_id":"b","worldOverrides":{"S1":true,"myWorld":false}}`, &request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := models.CopySubtreeRequest{
+		SourceID:            "a",
+		DestinationParentID: "b",
+		WorldOverrides:      map[string]bool{"S1": true, "myWorld": false},
+	}
+	if !reflect.DeepEqual(request, want) {
+		t.Errorf("decoded %+v, want %+v", request, want)
+	}
+}
+
+func TestDecodeJSONCamelSeedOverrides(t *testing.T) {
+	var request models.CreateInstanceRequest
+	err := decode(t, CaseCamel, `{"name":"b","seed":{"maxDepth":2,"extensionWeights":{".TXT":1},"metadataPool":{"ownerName":["x"]}}}`, &request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var seed types.SeedConfig
+	if err := json.Unmarshal(request.Seed, &seed); err != nil {
+		t.Fatal(err)
+	}
+	if seed.MaxDepth != 2 {
+		t.Errorf("max depth = %d, want 2 (seed %s)", seed.MaxDepth, request.Seed)
+	}
+	if _, ok := seed.ExtensionWeights[".TXT"]; !ok {
+		t.Errorf("extension weights = %v", seed.ExtensionWeights)
+	}
+	if _, ok := seed.MetadataPool["ownerName"]; !ok {
+		t.Errorf("metadata pool = %v", seed.MetadataPool)
+	}
+}
+
+func TestDecodeJSONEmptyBody(t *testing.T) {
+	for _, mode := range []string{CaseSnake, CaseCamel} {
+		var request models.CopySubtreeRequest
+		if err := decode(t, mode, "", &request); !errors.Is(err, io.EOF) {
+			t.Errorf("%s: empty body error = %v, want io.EOF", mode, err)
+		}
+	}
+}
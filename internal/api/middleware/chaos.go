@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// NoChaosHeader exempts a request from chaos injection, for control and verification traffic
+const NoChaosHeader = "X-Spectra-No-Chaos"
+
+// ChaosInjector applies the chaos rule for an operation (see sdk.SpectraFS.InjectChaos)
+type ChaosInjector interface {
+	InjectChaos(ctx context.Context, op string) error
+}
+
+// Chaos returns middleware that delays and fails requests according to the chaos rule for op
+// Requests carrying the X-Spectra-No-Chaos header pass straight through. An injected failure
+// is answered with the rule's status code and marked with an X-Spectra-Chaos header
+func Chaos(injector ChaosInjector, op string) func(http.Handler) http.Handler {
+	return ChaosFunc(injector, func(*http.Request) string { return op })
+}
+
+// ChaosFunc is Chaos for routes that serve several operations: opFor names the operation
+// of each request, and requests it maps to "" are never subject to chaos
+func ChaosFunc(injector ChaosInjector, opFor func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			op := opFor(req)
+			if op == "" || req.Header.Get(NoChaosHeader) != "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			err := injector.InjectChaos(req.Context(), op)
+			if err == nil {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			status := http.StatusServiceUnavailable
+			var chaosErr *sdk.ChaosError
+			if errors.As(err, &chaosErr) {
+				status = chaosErr.Code
+				w.Header().Set("X-Spectra-Chaos", "injected")
+			}
+			WriteJSON(w, status, types.APIResponse{
+				Success: false,
+				Code:    "chaos_injected",
+				Message: err.Error(),
+			})
+		})
+	}
+}
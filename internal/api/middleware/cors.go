@@ -7,9 +7,11 @@ func CORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Spectra-Case, X-Spectra-No-Chaos, X-API-Key, Range, If-None-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "Content-Length, Content-Range, Accept-Ranges, ETag, X-Checksum, X-Spectra-Chaos")
 
-		if req.Method == "OPTIONS" {
+		// Answer preflights here; other OPTIONS requests (WebDAV discovery) reach the router
+		if req.Method == "OPTIONS" && req.Header.Get("Access-Control-Request-Method") != "" {
 			return
 		}
 
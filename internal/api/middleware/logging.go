@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/logging"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// RequestLogger returns middleware that gives each request a logger tagged with its request ID (set
+// by chi's RequestID, which must run first) and logs the request once it is served: at info level,
+// or error level for a 5xx. Handlers and spectrafs log through the request's logger (see LoggerFrom)
+func RequestLogger(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			requestLogger := logger.With("request_id", middleware.GetReqID(req.Context()))
+			wrapped := middleware.NewWrapResponseWriter(w, req.ProtoMajor)
+			next.ServeHTTP(wrapped, req.WithContext(logging.WithLogger(req.Context(), requestLogger)))
+
+			status := wrapped.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			requestLogger.Log(req.Context(), level, "request",
+				"method", req.Method,
+				"path", req.URL.Path,
+				"status", status,
+				"bytes", wrapped.BytesWritten(),
+				"duration", time.Since(start),
+				"remote", req.RemoteAddr,
+			)
+		})
+	}
+}
+
+// LoggerFrom returns the logger RequestLogger gave req, or a discarding one outside of it
+func LoggerFrom(req *http.Request) *slog.Logger {
+	return logging.FromContext(req.Context(), logging.Discard)
+}
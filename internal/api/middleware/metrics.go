@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/metrics"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Metrics returns middleware that counts requests and records their latency by route pattern
+// (e.g. /api/v1/node/{id}), so IDs and paths do not each become a series; requests no route
+// matched are recorded as "unmatched"
+func Metrics(recorder metrics.Recorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			wrapped := middleware.NewWrapResponseWriter(w, req.ProtoMajor)
+			next.ServeHTTP(wrapped, req)
+
+			// The pattern is only complete once routing has run
+			route := "unmatched"
+			if rctx := chi.RouteContext(req.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			status := wrapped.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			method := metrics.Label{Name: "method", Value: req.Method}
+			pattern := metrics.Label{Name: "route", Value: route}
+			recorder.Add(metrics.HTTPRequests, 1, method, pattern, metrics.Label{Name: "status", Value: strconv.Itoa(status)})
+			recorder.Observe(metrics.HTTPRequestDuration, time.Since(start).Seconds(), method, pattern)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// TimeoutExcept is chi's Timeout middleware for every request except those to the given paths,
+// which are long-lived streams that end when the client disconnects
+func TimeoutExcept(timeout time.Duration, paths ...string) func(http.Handler) http.Handler {
+	exempt := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		exempt[path] = true
+	}
+	return TimeoutExceptFunc(timeout, func(path string) bool { return exempt[path] })
+}
+
+// TimeoutExceptFunc is TimeoutExcept for the paths exempt reports, for streams whose paths carry parameters
+func TimeoutExceptFunc(timeout time.Duration, exempt func(path string) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		timed := middleware.Timeout(timeout)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if exempt(req.URL.Path) {
+				next.ServeHTTP(w, req)
+				return
+			}
+			timed.ServeHTTP(w, req)
+		})
+	}
+}
@@ -1,11 +1,72 @@
 package models
 
+import (
+	"encoding/json"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
 // ListChildrenRequest represents the request to list children of a parent node
-// Supports both ID-based and Path+TableName-based lookups
+// Supports both ID-based and Path+TableName-based lookups, with optional keyset pagination
 type ListChildrenRequest struct {
-	ParentID   string `json:"parent_id,omitempty"`   // Parent node ID
-	ParentPath string `json:"parent_path,omitempty"` // Parent node path
-	TableName  string `json:"table_name,omitempty"`  // Required when using ParentPath
+	ParentID      string `json:"parent_id,omitempty"`      // Parent node ID
+	ParentPath    string `json:"parent_path,omitempty"`    // Parent node path
+	TableName     string `json:"table_name,omitempty"`     // Required when using ParentPath
+	Limit         int    `json:"limit,omitempty"`          // Page size (0 returns all children)
+	StartingAfter string `json:"starting_after,omitempty"` // next_cursor from a previous page
+	EndingBefore  string `json:"ending_before,omitempty"`  // prev_cursor from a previous page
+	Cursor        string `json:"cursor,omitempty"`         // Alias for starting_after
+	TypeFilter    string `json:"type_filter,omitempty"`    // Keep only "folder", "file" or "symlink" children
+	NameContains  string `json:"name_contains,omitempty"`  // Keep names containing this substring
+	NameGlob      string `json:"name_glob,omitempty"`      // Keep names matching this glob
+	MinSize       int64  `json:"min_size,omitempty"`       // Minimum size in bytes
+	MaxSize       int64  `json:"max_size,omitempty"`       // Maximum size in bytes (0 = no maximum)
+	SortBy        string `json:"sort_by,omitempty"`        // "name" (default), "size" or "mtime"
+	SortOrder     string `json:"sort_order,omitempty"`     // "asc" (default) or "desc"
+	Materialize   bool   `json:"materialize,omitempty"`    // Generate unlisted folders on the way to parent_path
+}
+
+// WalkRequest represents the request to walk a folder's whole subtree
+// Supports both ID-based and Path+TableName-based lookups of the starting folder
+type WalkRequest struct {
+	ParentID   string `json:"parent_id,omitempty"`   // Starting folder ID
+	ParentPath string `json:"parent_path,omitempty"` // Starting folder path
+	TableName  string `json:"table_name,omitempty"`  // World to walk; required when using ParentPath
+	MaxDepth   int    `json:"max_depth,omitempty"`   // Levels below the starting folder (0 = down to seed.max_depth)
+	MaxNodes   int    `json:"max_nodes,omitempty"`   // Node cap (0 = default cap)
+}
+
+// SearchRequest represents the request to find stored nodes by path prefix, name and size
+type SearchRequest struct {
+	World      string `json:"world,omitempty"`       // World to search (default "primary")
+	PathPrefix string `json:"path_prefix,omitempty"` // Subtree to search (default "/")
+	NameGlob   string `json:"name_glob,omitempty"`   // Name pattern, e.g. "*.txt"
+	Type       string `json:"type,omitempty"`        // "file", "folder" or "symlink"
+	MinSize    int64  `json:"min_size,omitempty"`    // Minimum size in bytes
+	MaxSize    int64  `json:"max_size,omitempty"`    // Maximum size in bytes (0 = no maximum)
+	Limit      int    `json:"limit,omitempty"`       // Result cap (0 = default cap)
+
+	Metadata map[string]string `json:"metadata,omitempty"` // Metadata key -> value pattern, e.g. {"content-type": "image/*"}
+}
+
+// CreateInstanceRequest represents the request to host a new named filesystem
+type CreateInstanceRequest struct {
+	Name string          `json:"name"`
+	Seed json.RawMessage `json:"seed,omitempty"` // Seed settings laid over the main config's seed section
+}
+
+// RawShape gives the seed overrides the shape of the seed section, so their fields are cased like it
+func (CreateInstanceRequest) RawShape(field string) any {
+	if field == "seed" {
+		return types.SeedConfig{}
+	}
+	return nil
+}
+
+// CloneInstanceRequest represents the request to copy a named instance into a new one
+type CloneInstanceRequest struct {
+	Name   string `json:"name"`
+	DBPath string `json:"db_path,omitempty"` // Database file of the copy; defaults to the main path with the name before the extension
 }
 
 // CreateFolderRequest represents the request to create a new folder
@@ -17,6 +78,16 @@ type CreateFolderRequest struct {
 	Name       string `json:"name"`                  // Name of the folder to create
 }
 
+// CreateSymlinkRequest represents the request to create a symlink
+// Supports both ID-based and Path+TableName-based lookups
+type CreateSymlinkRequest struct {
+	ParentID   string `json:"parent_id,omitempty"`   // Parent node ID
+	ParentPath string `json:"parent_path,omitempty"` // Parent node path
+	TableName  string `json:"table_name,omitempty"`  // Required when using ParentPath
+	Name       string `json:"name"`                  // Name of the symlink to create
+	Target     string `json:"target"`                // Absolute path, or one relative to the parent; need not exist
+}
+
 // UploadFileRequest represents the request to upload a file
 // Supports both ID-based and Path+TableName-based lookups
 type UploadFileRequest struct {
@@ -25,4 +96,126 @@ type UploadFileRequest struct {
 	TableName  string `json:"table_name,omitempty"`  // Required when using ParentPath
 	Name       string `json:"name"`                  // Name of the file to upload
 	Data       []byte `json:"data"`                  // File content (base64 encoded in JSON)
+	Overwrite  bool   `json:"overwrite,omitempty"`   // Replace the content of a file already at the path
+}
+
+// BatchCreateRequest represents the request to create several folders and files at once
+type BatchCreateRequest struct {
+	Ops []BatchOp `json:"ops"` // Executed in order
+}
+
+// BatchOp is one create of a BatchCreateRequest
+// The parent is named by parent_id, parent_path + table_name, or parent_key (the key of a folder
+// created earlier in the same batch)
+type BatchOp struct {
+	Op         string `json:"op"`                    // "folder" or "file"
+	Key        string `json:"key,omitempty"`         // Client-assigned key later ops can use as parent_key
+	ParentID   string `json:"parent_id,omitempty"`   // Parent node ID
+	ParentPath string `json:"parent_path,omitempty"` // Parent node path
+	TableName  string `json:"table_name,omitempty"`  // Required when using ParentPath
+	ParentKey  string `json:"parent_key,omitempty"`  // Key of a folder created earlier in the batch
+	Name       string `json:"name"`                  // Name of the new node
+	Data       []byte `json:"data,omitempty"`        // File content (base64 encoded in JSON), files only
+}
+
+// CopySubtreeRequest represents the request to copy a subtree under another parent
+type CopySubtreeRequest struct {
+	SourceID            string          `json:"source_id"`                 // Node to copy (with its descendants)
+	DestinationParentID string          `json:"destination_parent_id"`     // Folder to copy into
+	OnlyWorld           string          `json:"only_world,omitempty"`      // Copy only nodes existing in this world
+	WorldOverrides      map[string]bool `json:"world_overrides,omitempty"` // Existence forced on every copy per secondary world
+}
+
+// UpdateTraversalStatusRequest represents the request to set a node's traversal status
+type UpdateTraversalStatusRequest struct {
+	Status string `json:"status"` // "pending", "successful" or "failed"
+}
+
+// UpdateCopyStatusRequest represents the request to set a node's copy status
+type UpdateCopyStatusRequest struct {
+	Status    string `json:"status"`              // "pending", "in_progress" or "completed"
+	Recursive bool   `json:"recursive,omitempty"` // Also update every descendant
+}
+
+// RenameNodeRequest represents the request to rename a node in place
+type RenameNodeRequest struct {
+	Name string `json:"name"` // New name (no "/")
+}
+
+// SetNodeMetadataRequest represents the request to update a node's custom metadata
+type SetNodeMetadataRequest struct {
+	Metadata map[string]string `json:"metadata"`          // Entries to merge; an empty value removes the key
+	Replace  bool              `json:"replace,omitempty"` // Replace the node's metadata with Metadata instead of merging
+}
+
+// MoveNodeRequest represents the request to move a node and its subtree to a new parent
+type MoveNodeRequest struct {
+	ID            string `json:"id,omitempty"`              // Node ID
+	Path          string `json:"path,omitempty"`            // Node path
+	TableName     string `json:"table_name,omitempty"`      // Required when using Path
+	NewParentID   string `json:"new_parent_id,omitempty"`   // Destination folder ID
+	NewParentPath string `json:"new_parent_path,omitempty"` // Destination folder path
+}
+
+// BatchDeleteRequest represents the request to delete several nodes at once
+type BatchDeleteRequest struct {
+	IDs       []string `json:"ids"`                 // Node IDs to delete
+	Recursive bool     `json:"recursive,omitempty"` // Delete non-empty folders with their descendants
+}
+
+// AddWorldRequest represents the request to add a secondary world at runtime
+type AddWorldRequest struct {
+	Probability float64 `json:"probability"` // Chance (0.0-1.0) that a primary node exists in the world
+}
+
+// SetWorldExistenceRequest represents the request to add a subtree to a world or remove it
+type SetWorldExistenceRequest struct {
+	ID        string `json:"id"`
+	Exists    bool   `json:"exists"`
+	Recursive bool   `json:"recursive,omitempty"` // Also add the descendants; removal always cascades
+}
+
+// SaveSnapshotRequest represents the request to save a named snapshot of the tree
+type SaveSnapshotRequest struct {
+	Name string `json:"name"`
+}
+
+// DeterminismCheckRequest represents the request to run a determinism self-check
+type DeterminismCheckRequest struct {
+	Iterations int `json:"iterations,omitempty"` // Number of throwaway instances to compare (default 3)
+}
+
+// VerifyChecksumRequest represents the request to check a claimed checksum against a file's content
+type VerifyChecksumRequest struct {
+	ID       string `json:"id"`
+	Checksum string `json:"checksum"` // Hex SHA256 checksum, e.g. computed over a copy of the file
+}
+
+// VerifyTreeRequest represents the request to recompute the checksums of a subtree's stored files
+type VerifyTreeRequest struct {
+	ID        string `json:"id,omitempty"`         // Start node (default root)
+	TableName string `json:"table_name,omitempty"` // World to walk (default the ID's world prefix, or primary)
+}
+
+// FailGenerationRequest represents the request to arm the generation failure hook
+type FailGenerationRequest struct {
+	AfterNodes int64 `json:"after_nodes"` // Fail once this many more nodes are inserted (0 disarms)
+}
+
+// ChaosRequest represents the request to replace the chaos rules at runtime
+type ChaosRequest struct {
+	Seed       int64                `json:"seed,omitempty"`       // Reseeds the chaos RNG (0 = seeded from the clock)
+	Operations map[string]ChaosRule `json:"operations,omitempty"` // Operation name or "*" -> rule; empty turns chaos off
+}
+
+// ChaosRule represents the latency and failure rate applied to one operation
+type ChaosRule struct {
+	LatencyMS []int   `json:"latency_ms,omitempty"` // [min, max] delay in milliseconds
+	ErrorRate float64 `json:"error_rate,omitempty"` // Probability (0.0-1.0) that a request fails
+	ErrorCode int     `json:"error_code,omitempty"` // HTTP status of an injected failure (default 503)
+}
+
+// RunMutationsRequest represents the request to apply mutations immediately
+type RunMutationsRequest struct {
+	Count int `json:"count,omitempty"` // Number of mutations to apply (default 1)
 }
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// fileDataRange is the data of a JSON file data response for a byte range
+type fileDataRange struct {
+	Data   []byte `json:"data"`
+	Offset int64  `json:"offset"`
+	Length int    `json:"length"`
+	EOF    bool   `json:"eof"`
+}
+
+// rootFile returns the first file of the root and its full content
+func rootFile(t *testing.T, fs *sdk.SpectraFS) (sdk.Node, []byte) {
+	t.Helper()
+	result, err := fs.ListChildrenContext(t.Context(), &sdk.ListChildrenRequest{ParentID: "root", TableName: "primary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) == 0 {
+		t.Fatal("root has no files")
+	}
+	file := result.Files[0].Node
+	full, _, err := fs.GetFileData(file.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file, full
+}
+
+func TestFileDataRangeQuery(t *testing.T) {
+	server, fs := newServer(t, func(*sdk.Config) {})
+	file, full := rootFile(t, fs)
+
+	get := func(query string) (int, fileDataRange) {
+		t.Helper()
+		resp, err := http.Get(server.URL + "/api/v1/items/" + file.ID + "/data?format=json&" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var envelope struct {
+			Data fileDataRange `json:"data"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		return resp.StatusCode, envelope.Data
+	}
+
+	split := file.Size / 3
+	status, head := get(fmt.Sprintf("length=%d", split))
+	if status != http.StatusOK || head.Offset != 0 || head.Length != int(split) || head.EOF {
+		t.Errorf("length=%d = %d %+v", split, status, head)
+	}
+	status, tail := get(fmt.Sprintf("offset=%d", split))
+	if status != http.StatusOK || tail.Offset != split || !tail.EOF {
+		t.Errorf("offset=%d = %d, offset %d, eof %v", split, status, tail.Offset, tail.EOF)
+	}
+	if !bytes.Equal(append(head.Data, tail.Data...), full) {
+		t.Error("adjacent ranges differ from the full content")
+	}
+
+	for _, query := range []string{fmt.Sprintf("offset=%d", file.Size+1), "offset=-1", "length=x"} {
+		if status, _ := get(query); status != http.StatusBadRequest {
+			t.Errorf("%s = %d, want 400", query, status)
+		}
+	}
+}
+
+func TestFileRawServesBytesWithETagAndRanges(t *testing.T) {
+	server, fs := newServer(t, func(*sdk.Config) {})
+	file, full := rootFile(t, fs)
+	etag := strconv.Quote(*file.Checksum)
+
+	get := func(header, value string) (*http.Response, []byte) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/items/"+file.ID+"/raw", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, body
+	}
+
+	resp, body := get("", "")
+	if resp.StatusCode != http.StatusOK || !bytes.Equal(body, full) {
+		t.Fatalf("raw = %d with %d bytes, want 200 with the %d bytes of the file", resp.StatusCode, len(body), len(full))
+	}
+	if resp.Header.Get("Content-Type") != "application/octet-stream" || resp.Header.Get("ETag") != etag || resp.ContentLength != file.Size {
+		t.Errorf("raw headers = %v, want octet-stream, ETag %s, length %d", resp.Header, etag, file.Size)
+	}
+
+	resp, body = get("Range", "bytes=10-19")
+	if resp.StatusCode != http.StatusPartialContent || !bytes.Equal(body, full[10:20]) {
+		t.Errorf("bytes=10-19 = %d with %q, want 206 with %q", resp.StatusCode, body, full[10:20])
+	}
+
+	// Both the quoted ETag and the bare checksum match
+	for _, value := range []string{etag, *file.Checksum} {
+		if resp, _ := get("If-None-Match", value); resp.StatusCode != http.StatusNotModified {
+			t.Errorf("If-None-Match %s = %d, want 304", value, resp.StatusCode)
+		}
+	}
+}
@@ -1,70 +1,316 @@
 package api
 
 import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/Project-Sylos/Spectra/internal/api/handlers"
 	apimiddleware "github.com/Project-Sylos/Spectra/internal/api/middleware"
+	"github.com/Project-Sylos/Spectra/internal/logging"
+	"github.com/Project-Sylos/Spectra/internal/metrics"
 	"github.com/Project-Sylos/Spectra/sdk"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
+// instancesPath is where named instances are listed, created and deleted; each instance's own
+// API is mounted below it at /api/v1/instances/{instance}/...
+const instancesPath = "/api/v1/instances"
+
 // Router represents the HTTP API router
 type Router struct {
-	fs *sdk.SpectraFS
+	fs        *sdk.SpectraFS
+	instances *sdk.MultiInstance // nil for a single filesystem
+
+	mu             sync.Mutex // Protects instanceRoutes
+	instanceRoutes map[string]instanceRoutes
+
+	streamsDone  chan struct{} // Closed by CloseStreams to end long-lived event streams
+	closeStreams sync.Once
+
+	logger *slog.Logger // Request log (see SetLogger)
+}
+
+// instanceRoutes is the API of one named instance, built on its first request
+type instanceRoutes struct {
+	fs     *sdk.SpectraFS // The instance the routes were built for; a recreated instance gets new ones
+	router *chi.Mux
 }
 
 // NewRouter creates a new API router
 func NewRouter(fs *sdk.SpectraFS) *Router {
-	return &Router{fs: fs}
+	return &Router{fs: fs, streamsDone: make(chan struct{}), logger: logging.Discard}
+}
+
+// NewMultiInstanceRouter creates an API router serving the main filesystem at /api/v1 and each named
+// instance at /api/v1/instances/{instance}
+func NewMultiInstanceRouter(instances *sdk.MultiInstance) *Router {
+	return &Router{
+		fs:             instances.Main(),
+		instances:      instances,
+		instanceRoutes: make(map[string]instanceRoutes),
+		streamsDone:    make(chan struct{}),
+		logger:         logging.Discard,
+	}
+}
+
+// SetLogger sets the logger of served requests (nil stops logging); call it before SetupRoutes
+func (r *Router) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = logging.Discard
+	}
+	r.logger = logger
+}
+
+// CloseStreams ends every open event stream, so a graceful shutdown does not wait on them
+func (r *Router) CloseStreams() {
+	r.closeStreams.Do(func() { close(r.streamsDone) })
 }
 
 // SetupRoutes configures all API routes using modular handlers
 func (r *Router) SetupRoutes() *chi.Mux {
 	router := chi.NewRouter()
 
-	// Standard middleware
-	router.Use(middleware.Logger)
-	router.Use(middleware.Recoverer)
+	// Standard middleware; requests are logged with their ID, and after Recoverer has turned a panic into a 500
 	router.Use(middleware.RequestID)
 	router.Use(middleware.RealIP)
-	router.Use(middleware.Timeout(60))
+	router.Use(apimiddleware.RequestLogger(r.logger))
+	router.Use(middleware.Recoverer)
+	router.Use(apimiddleware.TimeoutExceptFunc(60*time.Second, isEventsPath))
+
+	// Request metrics, served at /metrics; off (and /metrics a plain 404) unless api.metrics_enabled is set
+	var registry *metrics.Registry
+	if r.fs.GetConfig().API.MetricsEnabled {
+		registry = r.newMetrics()
+		router.Use(apimiddleware.Metrics(registry))
+	}
 
 	// Custom middleware
 	router.Use(apimiddleware.CORS)
+	router.Use(apimiddleware.FieldCase(r.fs.GetConfig().API.ResponseCase))
 
 	// Initialize handlers
-	healthHandler := handlers.NewHealthHandler()
-	itemHandler := handlers.NewItemHandler(r.fs)
-	nodeHandler := handlers.NewNodeHandler(r.fs)
-	systemHandler := handlers.NewSystemHandler(r.fs)
+	healthHandler := handlers.NewHealthHandler(r.fs)
+	fsHandler := handlers.NewFSHandler(r.fs)
+	davHandler := handlers.NewDAVHandler(r.fs)
 
-	// Health check
-	router.Get("/health", healthHandler.HealthCheck)
+	// Token auth (open unless api.auth lists tokens); each route states the role it needs
+	authenticate := apimiddleware.Authenticate(r.fs.GetConfig().API.Auth)
+	read := apimiddleware.RequireRole(sdk.RoleRead)
+	admin := apimiddleware.RequireRole(sdk.RoleAdmin)
 
-	// API routes
-	router.Route("/api/v1", func(api chi.Router) {
-		// Item operations (files and folders)
-		api.Route("/items", func(items chi.Router) {
-			items.Post("/list", itemHandler.ListItems)
-			items.Post("/folder", itemHandler.CreateFolder)
-			items.Post("/file", itemHandler.UploadFile)
-			items.Get("/{id}", nodeHandler.GetNode) // Reuse node handler for getting item info
-			items.Get("/{id}/data", itemHandler.GetFileData)
-		})
+	// Health checks: liveness, and readiness of the main filesystem's database (/health is its alias)
+	router.Get("/health", healthHandler.Ready)
+	router.Get("/health/live", healthHandler.Live)
+	router.Get("/health/ready", healthHandler.Ready)
+
+	// Prometheus scrape endpoint
+	if registry != nil {
+		router.With(authenticate, read).Get("/metrics", registry.ServeHTTP)
+	}
 
-		// Node operations
-		api.Route("/node", func(node chi.Router) {
-			node.Get("/{id}", nodeHandler.GetNode)
-			node.Delete("/{id}", nodeHandler.DeleteNode)
+	// Path-based access, object store style: a trailing slash names a folder
+	router.Route("/fs/{world}", func(paths chi.Router) {
+		paths.Use(authenticate, apimiddleware.RequireRoleFunc(apimiddleware.MethodRole))
+		paths.Use(apimiddleware.ChaosFunc(r.fs, handlers.FSChaosOp))
+		paths.Get("/*", fsHandler.Get)
+		paths.Put("/*", fsHandler.Put)
+		paths.Delete("/*", fsHandler.Delete)
+	})
+
+	// WebDAV view of the worlds; left unmounted (plain 404) unless api.webdav_enabled is set
+	if r.fs.GetConfig().API.WebDAVEnabled {
+		chi.RegisterMethod(handlers.MethodPropfind)
+		chi.RegisterMethod(handlers.MethodMkcol)
+		router.Route("/dav/{world}", func(dav chi.Router) {
+			dav.Use(authenticate, apimiddleware.RequireRoleFunc(apimiddleware.MethodRole))
+			dav.Use(apimiddleware.ChaosFunc(r.fs, handlers.DAVChaosOp))
+			dav.Handle("/", davHandler)
+			dav.Handle("/*", davHandler)
 		})
+	}
+
+	// API routes
+	router.Route("/api/v1", func(api chi.Router) {
+		api.Use(authenticate)
+		r.mountAPI(api, r.fs)
 
-		// System operations
-		api.Post("/reset", systemHandler.Reset)
-		api.Get("/config", systemHandler.GetConfig)
-		api.Get("/stats", systemHandler.GetStats)
-		api.Get("/tables", systemHandler.GetTables)
-		api.Get("/tables/{tableName}/count", systemHandler.GetTableCount)
+		// Named instances, each with the full API below its name
+		if r.instances != nil {
+			instanceHandler := handlers.NewInstanceHandler(r.instances)
+			api.Route("/instances", func(instances chi.Router) {
+				instances.With(read).Get("/", instanceHandler.ListInstances)
+				instances.With(admin).Post("/", instanceHandler.CreateInstance)
+				instances.With(admin).Delete("/{instance}", instanceHandler.DeleteInstance)
+				instances.With(admin).Post("/{instance}/clone", instanceHandler.CloneInstance)
+				instances.Handle("/{instance}/*", instanceHandler.Forward(r.instanceAPI))
+			})
+		}
 	})
 
 	return router
 }
+
+// mountAPI adds the API of fs to api, which is /api/v1 for the main filesystem and
+// /api/v1/instances/{instance} for a named instance
+func (r *Router) mountAPI(api chi.Router, fs *sdk.SpectraFS) {
+	itemHandler := handlers.NewItemHandler(fs)
+	nodeHandler := handlers.NewNodeHandler(fs)
+	jobs := handlers.NewJobManager(fs)
+	jobHandler := handlers.NewJobHandler(jobs)
+	systemHandler := handlers.NewSystemHandler(fs, jobs)
+	maintenanceHandler := handlers.NewMaintenanceHandler(fs, jobs)
+	worldHandler := handlers.NewWorldHandler(fs)
+	snapshotHandler := handlers.NewSnapshotHandler(fs)
+	debugHandler := handlers.NewDebugHandler(fs)
+	chaosHandler := handlers.NewChaosHandler(fs)
+	mutationHandler := handlers.NewMutationHandler(fs)
+	eventsHandler := handlers.NewEventsHandler(fs, r.streamsDone)
+
+	// Chaos injection for filesystem operations (no-op unless chaos rules are set)
+	chaos := func(op string) func(http.Handler) http.Handler {
+		return apimiddleware.Chaos(fs, op)
+	}
+
+	read := apimiddleware.RequireRole(sdk.RoleRead)
+	write := apimiddleware.RequireRole(sdk.RoleWrite)
+	admin := apimiddleware.RequireRole(sdk.RoleAdmin)
+
+	// Item operations (files and folders)
+	api.Route("/items", func(items chi.Router) {
+		items.With(read, chaos(sdk.ChaosOpList)).Post("/list", itemHandler.ListItems)
+		items.With(write, chaos(sdk.ChaosOpCreate)).Post("/folder", itemHandler.CreateFolder)
+		items.With(write, chaos(sdk.ChaosOpCreate)).Post("/symlink", itemHandler.CreateSymlink)
+		items.With(write, chaos(sdk.ChaosOpUpload)).Post("/file", itemHandler.UploadFile)
+		items.With(write, chaos(sdk.ChaosOpBatch)).Post("/batch", itemHandler.BatchCreate)
+		items.With(write, chaos(sdk.ChaosOpCopy)).Post("/copy", itemHandler.CopySubtree)
+		items.With(read, chaos(sdk.ChaosOpWalk)).Post("/walk", itemHandler.Walk)
+		items.With(read, chaos(sdk.ChaosOpGet)).Get("/{id}", nodeHandler.GetNode) // Reuse node handler for getting item info
+		items.With(read, chaos(sdk.ChaosOpRead)).Get("/{id}/data", itemHandler.GetFileData)
+		items.With(read, chaos(sdk.ChaosOpRead)).Get("/{id}/raw", itemHandler.GetFileRaw)
+	})
+
+	// Node queries
+	api.With(read, chaos(sdk.ChaosOpList)).Get("/nodes", nodeHandler.ListNodes)
+	api.With(read, chaos(sdk.ChaosOpSearch)).Post("/search", nodeHandler.Search)
+	api.With(read).Get("/duplicates", nodeHandler.Duplicates)
+	api.With(read).Get("/changes", nodeHandler.Changes)
+	api.With(read).Get("/events", eventsHandler.Stream)
+
+	// Node operations
+	api.Route("/node", func(node chi.Router) {
+		node.With(write, chaos(sdk.ChaosOpDelete)).Post("/batch-delete", nodeHandler.BatchDelete)
+		node.With(write, chaos(sdk.ChaosOpMove)).Post("/move", nodeHandler.MoveNode)
+		node.With(read, chaos(sdk.ChaosOpGet)).Get("/{id}", nodeHandler.GetNode)
+		node.With(write, chaos(sdk.ChaosOpDelete)).Delete("/{id}", nodeHandler.DeleteNode)
+		node.With(write, chaos(sdk.ChaosOpStatus)).Put("/{id}/status", nodeHandler.UpdateTraversalStatus)
+		node.With(write, chaos(sdk.ChaosOpRename)).Patch("/{id}/rename", nodeHandler.RenameNode)
+		node.With(write, chaos(sdk.ChaosOpStatus)).Patch("/{id}/copy-status", nodeHandler.UpdateCopyStatus)
+		node.With(write, chaos(sdk.ChaosOpMetadata)).Patch("/{id}/metadata", nodeHandler.SetNodeMetadata)
+	})
+
+	// System operations
+	api.With(admin).Post("/reset", systemHandler.Reset)
+	api.With(write).Post("/generate", systemHandler.Generate)
+	api.With(write).Delete("/generate", systemHandler.CancelGenerate)
+	api.With(read).Get("/export", systemHandler.Export)
+	api.With(admin).Post("/import", systemHandler.Import)
+	api.With(read).Get("/integrity", systemHandler.Integrity)
+	api.With(read).Post("/verify", maintenanceHandler.VerifyChecksum)
+	api.With(read).Post("/verify/tree", maintenanceHandler.VerifyTree)
+	api.With(admin).Post("/repair", systemHandler.Repair)
+	api.With(admin).Post("/compact", systemHandler.Compact)
+	api.With(read).Get("/config", systemHandler.GetConfig)
+	api.With(read).Get("/config/persisted", systemHandler.GetPersistedConfig)
+	api.With(read).Get("/stats", systemHandler.GetStats)
+	api.With(read).Get("/coverage", systemHandler.GetCoverage)
+	api.With(read).Get("/analyze", systemHandler.Analyze)
+	api.With(read).Get("/tables", systemHandler.GetTables)
+	api.With(read).Get("/tables/{tableName}/count", systemHandler.GetTableCount)
+
+	// Background jobs started by the endpoints above
+	api.Route("/jobs", func(jobs chi.Router) {
+		jobs.With(read).Get("/", jobHandler.ListJobs)
+		jobs.With(read).Get("/{id}", jobHandler.GetJob)
+		jobs.With(write).Delete("/{id}", jobHandler.CancelJob)
+	})
+
+	// Named snapshots
+	api.Route("/snapshots", func(snapshots chi.Router) {
+		snapshots.With(read).Get("/", snapshotHandler.ListSnapshots)
+		snapshots.With(write).Post("/", snapshotHandler.SaveSnapshot)
+		snapshots.With(admin).Post("/{name}/restore", snapshotHandler.RestoreSnapshot)
+		snapshots.With(write).Delete("/{name}", snapshotHandler.DeleteSnapshot)
+	})
+
+	// World operations
+	api.Route("/worlds", func(worlds chi.Router) {
+		worlds.With(read).Get("/", worldHandler.ListWorlds)
+		worlds.With(read).Get("/diff", worldHandler.Diff)
+		worlds.With(write).Post("/{world}", worldHandler.AddWorld)
+		worlds.With(write).Delete("/{world}", worldHandler.RemoveWorld)
+		worlds.With(write).Post("/{world}/apply-retention", worldHandler.ApplyRetention)
+		worlds.With(write).Post("/{world}/existence", worldHandler.SetExistence)
+	})
+
+	// Chaos settings (never subject to chaos themselves)
+	api.With(read).Get("/chaos", chaosHandler.GetChaos)
+	api.With(admin).Post("/chaos", chaosHandler.SetChaos)
+
+	// Mutation engine
+	api.Route("/mutations", func(mutations chi.Router) {
+		mutations.With(read).Get("/", mutationHandler.Status)
+		mutations.With(write).Post("/start", mutationHandler.Start)
+		mutations.With(write).Post("/stop", mutationHandler.Stop)
+		mutations.With(write).Post("/run", mutationHandler.Run)
+		mutations.With(read).Get("/log", mutationHandler.Log)
+	})
+
+	// Maintenance operations
+	api.Route("/maintenance", func(maintenance chi.Router) {
+		maintenance.With(read).Post("/determinism-check", maintenanceHandler.DeterminismCheck)
+		maintenance.With(read).Get("/last-recovery", maintenanceHandler.LastRecovery)
+		maintenance.With(admin).Post("/fail-generation", maintenanceHandler.FailGeneration)
+		maintenance.With(read).Get("/schedule", maintenanceHandler.Schedule)
+	})
+
+	// Raw bucket inspection; left unmounted (plain 404) unless debug.expose_buckets is set
+	if fs.GetConfig().Debug.ExposeBuckets {
+		api.Route("/debug", func(debug chi.Router) {
+			debug.Use(admin)
+			debug.Get("/buckets", debugHandler.ListBuckets)
+			debug.Get("/buckets/{name}", debugHandler.ScanBucket)
+		})
+	}
+}
+
+// instanceAPI returns the API of the named instance, building it on first use and again when the
+// instance has been deleted and recreated since
+func (r *Router) instanceAPI(name string, fs *sdk.SpectraFS) http.Handler {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	routes, ok := r.instanceRoutes[name]
+	if !ok || routes.fs != fs {
+		routes = instanceRoutes{fs: fs, router: chi.NewRouter()}
+		r.mountAPI(routes.router, fs)
+		r.instanceRoutes[name] = routes
+	}
+	return routes.router
+}
+
+// isEventsPath reports whether path is an event stream, which is exempt from the request timeout
+func isEventsPath(path string) bool {
+	if path == handlers.EventsPath {
+		return true
+	}
+	rest, ok := strings.CutPrefix(path, instancesPath+"/")
+	if !ok {
+		return false
+	}
+	name, tail, _ := strings.Cut(rest, "/")
+	return name != "" && "/"+tail == strings.TrimPrefix(handlers.EventsPath, "/api/v1")
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// newMultiInstanceServer serves a main filesystem and an instance named "a", with their databases in
+// a directory removed when the test ends
+func newMultiInstanceServer(t *testing.T) (*httptest.Server, *sdk.MultiInstance) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := `{
+		"seed": {"max_depth": 2, "min_folders": 2, "max_folders": 2, "min_files": 1, "max_files": 1, "seed": 7,
+			"db_path": ` + quote(filepath.Join(dir, "spectra.db")) + `},
+		"api": {"host": "127.0.0.1", "port": 8086},
+		"instances": [{"name": "a"}]
+	}`
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(cfg), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	instances, err := sdk.NewMultiInstance(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(NewMultiInstanceRouter(instances).SetupRoutes())
+	t.Cleanup(func() {
+		server.Close()
+		instances.Close()
+	})
+	return server, instances
+}
+
+// quote returns s as a JSON string
+func quote(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// post sends body to path and decodes the response envelope, whose data lands in data if non-nil
+func post(t *testing.T, server *httptest.Server, path, body string, data any) (int, types.APIResponse) {
+	t.Helper()
+	resp, err := http.Post(server.URL+path, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		types.APIResponse
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		t.Fatalf("POST %s: decode response: %v", path, err)
+	}
+	if data != nil && envelope.Data != nil {
+		if err := json.Unmarshal(envelope.Data, data); err != nil {
+			t.Fatalf("POST %s: decode data: %v", path, err)
+		}
+	}
+	return resp.StatusCode, envelope.APIResponse
+}
+
+// rootFolders lists the names of the folders under an instance's root
+func rootFolders(t *testing.T, s *sdk.SpectraFS) []string {
+	t.Helper()
+	result, err := s.ListChildrenContext(t.Context(), &sdk.ListChildrenRequest{ParentID: "root", TableName: "primary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, folder := range result.Folders {
+		names = append(names, folder.Name)
+	}
+	return names
+}
+
+func TestCloneInstanceRoute(t *testing.T) {
+	server, instances := newMultiInstanceServer(t)
+	source, err := instances.Instance("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := rootFolders(t, source)
+
+	var identity types.InstanceIdentity
+	status, resp := post(t, server, "/api/v1/instances/a/clone", `{"name": "b"}`, &identity)
+	if status != http.StatusCreated {
+		t.Fatalf("clone: %d %+v", status, resp)
+	}
+	sourceIdentity, err := source.Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.ClonedFrom != sourceIdentity.InstanceID || identity.InstanceID == sourceIdentity.InstanceID {
+		t.Errorf("clone identity %+v, source %+v", identity, sourceIdentity)
+	}
+
+	clone, err := instances.Instance("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rootFolders(t, clone); !slices.Equal(got, before) {
+		t.Fatalf("clone lists %v, source listed %v", got, before)
+	}
+
+	// The clone is served at its own path and diverges from the source
+	status, resp = post(t, server, "/api/v1/instances/b/items/folder", `{"parent_id": "root", "name": "only-b"}`, nil)
+	if status != http.StatusCreated && status != http.StatusOK {
+		t.Fatalf("create folder in b: %d %+v", status, resp)
+	}
+	if !slices.Contains(rootFolders(t, clone), "only-b") || slices.Contains(rootFolders(t, source), "only-b") {
+		t.Error("folder created in the clone did not stay in the clone")
+	}
+
+	for _, tc := range []struct {
+		path, body string
+		status     int
+		code       string
+	}{
+		{"/api/v1/instances/a/clone", `{"name": "b"}`, http.StatusConflict, "instance_exists"},
+		{"/api/v1/instances/missing/clone", `{"name": "c"}`, http.StatusNotFound, "unknown_instance"},
+		{"/api/v1/instances/a/clone", `{"name": "bad/name"}`, http.StatusBadRequest, "invalid_instance"},
+	} {
+		status, resp := post(t, server, tc.path, tc.body, nil)
+		if status != tc.status || resp.Code != tc.code {
+			t.Errorf("POST %s %s = %d %q, want %d %q", tc.path, tc.body, status, resp.Code, tc.status, tc.code)
+		}
+	}
+}
+
+// newServer serves a single in-memory filesystem built from the defaults as adjusted by configure
+func newServer(t *testing.T, configure func(*sdk.Config)) (*httptest.Server, *sdk.SpectraFS) {
+	t.Helper()
+	cfg := sdk.DefaultConfig()
+	cfg.Seed.DBPath = sdk.MemoryDBPath
+	configure(&cfg)
+	fs, err := sdk.NewFromConfig(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(NewRouter(fs).SetupRoutes())
+	t.Cleanup(func() {
+		server.Close()
+		fs.Close()
+	})
+	return server, fs
+}
+
+func TestDebugBucketsGated(t *testing.T) {
+	for _, exposed := range []bool{false, true} {
+		server, _ := newServer(t, func(cfg *sdk.Config) {
+			cfg.Debug.ExposeBuckets = exposed
+		})
+
+		want := http.StatusNotFound
+		if exposed {
+			want = http.StatusOK
+		}
+		for _, path := range []string{"/api/v1/debug/buckets", "/api/v1/debug/buckets/meta?prefix=identity&limit=1"} {
+			resp, err := http.Get(server.URL + path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != want {
+				t.Errorf("expose_buckets %v: GET %s = %d, want %d", exposed, path, resp.StatusCode, want)
+			}
+		}
+	}
+}
+
+func TestPersistedConfigEndpoint(t *testing.T) {
+	server, fs := newServer(t, func(cfg *sdk.Config) { cfg.SecondaryTables = map[string]float64{"s1": 0.5} })
+
+	resp, err := http.Get(server.URL + "/api/v1/config/persisted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var envelope struct {
+		Data types.GenerationFingerprint `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /api/v1/config/persisted = %d, %v", resp.StatusCode, err)
+	}
+	stored := envelope.Data
+	if stored.Seed != fs.GetConfig().Seed.Seed || stored.Worlds["s1"] != 0.5 || stored.Hash == "" {
+		t.Errorf("persisted config = %+v", stored)
+	}
+}
@@ -1,41 +1,213 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"sync"
+	"time"
 
+	"github.com/Project-Sylos/Spectra/internal/logging"
 	"github.com/Project-Sylos/Spectra/internal/types"
 	"github.com/Project-Sylos/Spectra/sdk"
 	"github.com/go-chi/chi/v5"
 )
 
+// Defaults for the api section's server timeouts
+const (
+	DefaultReadTimeout     = 15 * time.Second
+	DefaultWriteTimeout    = 15 * time.Second
+	DefaultIdleTimeout     = 60 * time.Second
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+// ErrServerStarted is returned by Start when the server has already been started
+var ErrServerStarted = errors.New("server already started")
+
 // Server represents the HTTP API server
 type Server struct {
-	router *chi.Mux
-	fs     *sdk.SpectraFS
-	config *types.APIConfig
+	router    *chi.Mux
+	routes    *Router
+	fs        *sdk.SpectraFS
+	instances *sdk.MultiInstance // nil for a single filesystem
+	config    *types.APIConfig
+	logger    *slog.Logger
+
+	mu       sync.Mutex // Protects started and addr
+	started  bool
+	addr     string
+	ready    chan struct{} // Closed once Start has bound its listener or failed to
+	markDone sync.Once
 }
 
 // NewServer creates a new API server
+// Requests are logged to stderr at api.log_level in api.log_format
 func NewServer(fs *sdk.SpectraFS, config *types.APIConfig) *Server {
+	logger := newLogger(config)
 	router := NewRouter(fs)
+	router.SetLogger(logger)
 
 	return &Server{
 		router: router.SetupRoutes(),
+		routes: router,
 		fs:     fs,
 		config: config,
+		logger: logger,
+		ready:  make(chan struct{}),
 	}
 }
 
-// Start starts the HTTP server
-func (s *Server) Start() error {
-	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
+// NewMultiInstanceServer creates a new API server for the main filesystem and the named instances
+// of instances (see NewMultiInstanceRouter)
+func NewMultiInstanceServer(instances *sdk.MultiInstance, config *types.APIConfig) *Server {
+	logger := newLogger(config)
+	router := NewMultiInstanceRouter(instances)
+	router.SetLogger(logger)
 
-	fmt.Printf("Starting Spectra API server on %s\n", addr)
-	fmt.Printf("API endpoints available at http://%s/api/v1/\n", addr)
-	fmt.Printf("Health check available at http://%s/health\n", addr)
+	return &Server{
+		router:    router.SetupRoutes(),
+		routes:    router,
+		fs:        instances.Main(),
+		instances: instances,
+		config:    config,
+		logger:    logger,
+		ready:     make(chan struct{}),
+	}
+}
 
-	return http.ListenAndServe(addr, s.router)
+// newLogger creates the server's stderr logger from the api section (config.Validate has checked
+// its level and format; anything it would reject falls back to info-level text)
+func newLogger(config *types.APIConfig) *slog.Logger {
+	logger, err := logging.New(os.Stderr, config.LogLevel, config.LogFormat)
+	if err != nil {
+		logger, _ = logging.New(os.Stderr, logging.LevelInfo, logging.FormatText)
+	}
+	return logger
+}
+
+// Logger returns the server's logger, for the embedding program to log (and SetLogger) through
+func (s *Server) Logger() *slog.Logger {
+	return s.logger
+}
+
+// Start listens on the configured host and port and serves until ctx is cancelled, then shuts down
+// gracefully: it stops accepting connections, ends event streams and returns once in-flight requests
+// have drained or the shutdown timeout has passed. Port 0 binds any free port; see Addr
+func (s *Server) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.started {
+		s.mu.Unlock()
+		return ErrServerStarted
+	}
+	s.started = true
+	s.mu.Unlock()
+	defer s.markReady()
+
+	timeouts, err := s.timeouts()
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(s.config.Host, fmt.Sprint(s.config.Port)))
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	httpServer := &http.Server{
+		Handler:      s.router,
+		ReadTimeout:  timeouts.read,
+		WriteTimeout: timeouts.write,
+		IdleTimeout:  timeouts.idle,
+	}
+	// Event streams never finish on their own; end them when shutdown begins
+	httpServer.RegisterOnShutdown(s.routes.CloseStreams)
+
+	addr := listener.Addr().String()
+	s.mu.Lock()
+	s.addr = addr
+	s.mu.Unlock()
+	s.markReady()
+
+	s.logger.Info("Spectra API server listening", "addr", addr,
+		"api", fmt.Sprintf("http://%s/api/v1/", addr),
+		"health", fmt.Sprintf("http://%s/health", addr))
+
+	served := make(chan error, 1)
+	go func() {
+		served <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-served:
+		return fmt.Errorf("server stopped: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeouts.shutdown)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		httpServer.Close()
+		<-served
+		return fmt.Errorf("failed to drain in-flight requests: %w", err)
+	}
+	<-served
+	return nil
+}
+
+// Addr returns the address Start is listening on, or "" before it has bound (wait on Ready)
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addr
+}
+
+// Ready returns a channel closed once Start has bound its listener, or has failed to
+func (s *Server) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// markReady closes the ready channel (no-op if already closed)
+func (s *Server) markReady() {
+	s.markDone.Do(func() { close(s.ready) })
+}
+
+// serverTimeouts are the api section's timeouts with defaults applied
+type serverTimeouts struct {
+	read, write, idle, shutdown time.Duration
+}
+
+// timeouts parses the configured server timeouts
+func (s *Server) timeouts() (serverTimeouts, error) {
+	timeouts := serverTimeouts{
+		read:     DefaultReadTimeout,
+		write:    DefaultWriteTimeout,
+		idle:     DefaultIdleTimeout,
+		shutdown: DefaultShutdownTimeout,
+	}
+	for _, timeout := range []struct {
+		name  string
+		value string
+		into  *time.Duration
+	}{
+		{"read_timeout", s.config.ReadTimeout, &timeouts.read},
+		{"write_timeout", s.config.WriteTimeout, &timeouts.write},
+		{"idle_timeout", s.config.IdleTimeout, &timeouts.idle},
+		{"shutdown_timeout", s.config.ShutdownTimeout, &timeouts.shutdown},
+	} {
+		if timeout.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(timeout.value)
+		if err != nil || d <= 0 {
+			return timeouts, fmt.Errorf("invalid API %s %q", timeout.name, timeout.value)
+		}
+		*timeout.into = d
+	}
+	return timeouts, nil
 }
 
 // GetRouter returns the configured router
@@ -43,8 +215,16 @@ func (s *Server) GetRouter() *chi.Mux {
 	return s.router
 }
 
-// Stop gracefully stops the server (placeholder for future implementation)
+// CloseStreams ends open event streams; Start does this itself on shutdown, so it is only
+// needed when serving GetRouter from your own http.Server (register it with RegisterOnShutdown)
+func (s *Server) CloseStreams() {
+	s.routes.CloseStreams()
+}
+
+// Stop closes the filesystem, and every instance for a multi-instance server; call it after Start has returned
 func (s *Server) Stop() error {
-	// Close the filesystem connection
+	if s.instances != nil {
+		return s.instances.Close()
+	}
 	return s.fs.Close()
 }
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+func TestSnapshotEndpoints(t *testing.T) {
+	server, fs := newServer(t, func(*sdk.Config) {})
+	before := rootFolders(t, fs)
+
+	for _, tc := range []struct {
+		method, path, body string
+		status             int
+		code               string
+	}{
+		{http.MethodPost, "/api/v1/snapshots", `{"name": "start"}`, http.StatusCreated, ""},
+		{http.MethodPost, "/api/v1/snapshots", `{"name": "start"}`, http.StatusConflict, "snapshot_exists"},
+		{http.MethodPost, "/api/v1/items/folder", `{"parent_id": "root", "name": "added"}`, http.StatusCreated, ""},
+		{http.MethodGet, "/api/v1/snapshots", "", http.StatusOK, ""},
+		{http.MethodPost, "/api/v1/snapshots/start/restore", `{}`, http.StatusOK, ""},
+		{http.MethodPost, "/api/v1/snapshots/missing/restore", `{}`, http.StatusNotFound, "snapshot_not_found"},
+		{http.MethodDelete, "/api/v1/snapshots/start", "", http.StatusOK, ""},
+		{http.MethodDelete, "/api/v1/snapshots/start", "", http.StatusNotFound, "snapshot_not_found"},
+	} {
+		status, code := send(t, server, tc.method, tc.path, nil, tc.body)
+		if status != tc.status || code != tc.code {
+			t.Errorf("%s %s = %d %q, want %d %q", tc.method, tc.path, status, code, tc.status, tc.code)
+		}
+	}
+
+	if after := rootFolders(t, fs); !slices.Equal(after, before) {
+		t.Errorf("root holds %v after the restore, want %v", after, before)
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/api/handlers"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// multipartBody builds a multipart/form-data body from fields, in order, followed by a file part
+// when filename is set, and returns it with its content type
+func multipartBody(t *testing.T, fields [][2]string, filename, content string) (string, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	for _, field := range fields {
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if filename != "" {
+		part, err := form.CreateFormFile("file", filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(content))
+	}
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String(), form.FormDataContentType()
+}
+
+func TestUploadMultipart(t *testing.T) {
+	server, fs := newServer(t, func(*sdk.Config) {})
+	folder := rootFolders(t, fs)[0]
+
+	content := "multipart content"
+	body, contentType := multipartBody(t, [][2]string{{"parent_path", "/" + folder}, {"table_name", "primary"}}, "up.txt", content)
+	resp, err := http.Post(server.URL+"/api/v1/items/file", contentType, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var envelope struct {
+		Data sdk.Node `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("multipart upload = %d, %v", resp.StatusCode, err)
+	}
+	if node := envelope.Data; node.Name != "up.txt" || node.Path != "/"+folder+"/up.txt" || node.Type != sdk.NodeTypeFile {
+		t.Errorf("uploaded node = %+v", node)
+	}
+	if size := resp.Header.Get(handlers.UploadSizeHeader); size != fmt.Sprint(len(content)) {
+		t.Errorf("%s = %s, want %d", handlers.UploadSizeHeader, size, len(content))
+	}
+	if checksum := resp.Header.Get(handlers.UploadChecksumHeader); checksum != fmt.Sprintf("%x", sha256.Sum256([]byte(content))) {
+		t.Errorf("%s = %s, want the content's SHA-256", handlers.UploadChecksumHeader, checksum)
+	}
+
+	// The parent must be named before the file part
+	body, contentType = multipartBody(t, nil, "orphan.txt", content)
+	if status, code := send(t, server, http.MethodPost, "/api/v1/items/file", map[string]string{"Content-Type": contentType}, body); status != http.StatusBadRequest {
+		t.Errorf("multipart upload without a parent = %d %s, want 400", status, code)
+	}
+	body, contentType = multipartBody(t, [][2]string{{"parent_path", "/"}, {"table_name", "primary"}}, "", "")
+	if status, code := send(t, server, http.MethodPost, "/api/v1/items/file", map[string]string{"Content-Type": contentType}, body); status != http.StatusBadRequest {
+		t.Errorf("multipart upload without a file part = %d %s, want 400", status, code)
+	}
+}
+
+func TestUploadTooLarge(t *testing.T) {
+	server, _ := newServer(t, func(cfg *sdk.Config) { cfg.API.MaxUploadBytes = 64 })
+	large := strings.Repeat("x", 65)
+
+	body, contentType := multipartBody(t, [][2]string{{"parent_path", "/"}, {"table_name", "primary"}}, "big.txt", large)
+	if status, code := send(t, server, http.MethodPost, "/api/v1/items/file", map[string]string{"Content-Type": contentType}, body); status != http.StatusRequestEntityTooLarge || code != handlers.CodeTooLarge {
+		t.Errorf("oversize multipart upload = %d %s, want 413 %s", status, code, handlers.CodeTooLarge)
+	}
+	if status, code := send(t, server, http.MethodPut, "/fs/primary/big.txt", nil, large); status != http.StatusRequestEntityTooLarge || code != handlers.CodeTooLarge {
+		t.Errorf("oversize raw PUT = %d %s, want 413 %s", status, code, handlers.CodeTooLarge)
+	}
+
+	// A body within the limit is accepted
+	if status, code := send(t, server, http.MethodPut, "/fs/primary/small.txt", nil, large[:64]); status != http.StatusCreated {
+		t.Errorf("raw PUT at the limit = %d %s, want 201", status, code)
+	}
+}
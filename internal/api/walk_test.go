@@ -0,0 +1,59 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+func TestWalkStreamsNDJSON(t *testing.T) {
+	server, _ := newServer(t, func(cfg *sdk.Config) {
+		cfg.Seed.MaxDepth = 2
+	})
+
+	resp, err := http.Post(server.URL+"/api/v1/items/walk", "application/json", strings.NewReader(`{"parent_id": "root", "max_nodes": 5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("walk = %d %s, want 200 application/x-ndjson", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	// One line per node, then a summary line
+	var entries []sdk.WalkEntry
+	var summary struct {
+		Done      bool `json:"done"`
+		Count     int  `json:"count"`
+		Truncated bool `json:"truncated"`
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var entry sdk.WalkEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		if entry.Node == nil {
+			if err := json.Unmarshal(scanner.Bytes(), &summary); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 5 || entries[0].Depth != 1 {
+		t.Errorf("walk streamed %d entries starting at depth %d, want 5 starting at depth 1", len(entries), entries[0].Depth)
+	}
+	if !summary.Done || summary.Count != 5 || !summary.Truncated {
+		t.Errorf("walk summary = %+v, want done, count 5, truncated", summary)
+	}
+
+	status, _ := post(t, server, "/api/v1/items/walk", `{"parent_id": "root", "max_depth": -1}`, nil)
+	if status != http.StatusBadRequest {
+		t.Errorf("walk with a negative max_depth = %d, want 400", status)
+	}
+}
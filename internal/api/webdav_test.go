@@ -0,0 +1,210 @@
+package api
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// davMultistatus is the part of a PROPFIND response the tests read
+type davMultistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Prop struct {
+				ContentLength string `xml:"getcontentlength"`
+				LastModified  string `xml:"getlastmodified"`
+				ResourceType  struct {
+					Collection *struct{} `xml:"collection"`
+				} `xml:"resourcetype"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+// davEntry is one resource of a PROPFIND listing
+type davEntry struct {
+	href         string
+	collection   bool
+	length       string
+	lastModified string
+}
+
+// newDAVServer serves a small in-memory tree with WebDAV enabled
+func newDAVServer(t *testing.T) (*httptest.Server, *sdk.SpectraFS) {
+	t.Helper()
+	return newServer(t, func(cfg *sdk.Config) {
+		cfg.API.WebDAVEnabled = true
+		cfg.Seed.MaxDepth = 2
+		cfg.Seed.MinFolders, cfg.Seed.MaxFolders = 2, 2
+		cfg.Seed.MinFiles, cfg.Seed.MaxFiles = 2, 2
+	})
+}
+
+// davRequest sends a WebDAV request and returns the response, whose body the caller closes
+func davRequest(t *testing.T, server *httptest.Server, method, href string, header map[string]string, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+href, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// propfind lists a collection with Depth: 1, returning the collection itself first
+func propfind(t *testing.T, server *httptest.Server, href string) []davEntry {
+	t.Helper()
+	resp := davRequest(t, server, "PROPFIND", href, map[string]string{"Depth": "1"}, "")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		t.Fatalf("PROPFIND %s = %d", href, resp.StatusCode)
+	}
+
+	var ms davMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		t.Fatalf("PROPFIND %s: %v", href, err)
+	}
+	entries := make([]davEntry, 0, len(ms.Responses))
+	for _, r := range ms.Responses {
+		entry := davEntry{href: r.Href}
+		for _, ps := range r.Propstat {
+			entry.collection = entry.collection || ps.Prop.ResourceType.Collection != nil
+			entry.length += ps.Prop.ContentLength
+			entry.lastModified += ps.Prop.LastModified
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// davPath is the node path a /dav/primary href names
+func davPath(t *testing.T, href string) string {
+	t.Helper()
+	p, err := url.PathUnescape(strings.TrimSuffix(strings.TrimPrefix(href, "/dav/primary"), "/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p == "" {
+		return "/"
+	}
+	return p
+}
+
+func TestWebDAVWalkMatchesNodes(t *testing.T) {
+	server, fs := newDAVServer(t)
+
+	files := make(map[string]davEntry)
+	folders := 0
+	queue := []string{"/dav/primary/"}
+	for len(queue) > 0 {
+		href := queue[0]
+		queue = queue[1:]
+		entries := propfind(t, server, href)
+		if len(entries) == 0 || entries[0].href != href {
+			t.Fatalf("PROPFIND %s did not describe the collection first: %+v", href, entries)
+		}
+		for _, entry := range entries[1:] {
+			if entry.collection {
+				folders++
+				queue = append(queue, entry.href)
+			} else {
+				files[entry.href] = entry
+			}
+		}
+	}
+
+	// Depth: 1 listings generated the tree as they went, down to max_depth
+	var nodes int
+	err := fs.WalkFuncContext(t.Context(), &sdk.WalkRequest{ParentID: "root", TableName: "primary"}, func(*sdk.Node) error {
+		nodes++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if folders == 0 || len(files) == 0 || folders+len(files) != nodes {
+		t.Fatalf("WebDAV walk found %d folders and %d files, the tree has %d nodes", folders, len(files), nodes)
+	}
+
+	for href, entry := range files {
+		node, err := fs.GetNodeContext(t.Context(), &sdk.GetNodeRequest{Path: davPath(t, href), TableName: "primary"})
+		if err != nil {
+			t.Fatalf("%s: %v", href, err)
+		}
+		if entry.length != strconv.FormatInt(node.Size, 10) {
+			t.Errorf("%s: getcontentlength %s, node size %d", href, entry.length, node.Size)
+		}
+		if entry.lastModified != node.LastUpdated.UTC().Format(http.TimeFormat) {
+			t.Errorf("%s: getlastmodified %s, node updated %v", href, entry.lastModified, node.LastUpdated)
+		}
+
+		resp := davRequest(t, server, http.MethodGet, href, nil, "")
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || int64(len(data)) != node.Size {
+			t.Errorf("GET %s = %d with %d bytes, want 200 with %d", href, resp.StatusCode, len(data), node.Size)
+		}
+	}
+}
+
+func TestWebDAVWrites(t *testing.T) {
+	server, fs := newDAVServer(t)
+
+	for _, tc := range []struct {
+		method, href string
+		status       int
+	}{
+		{"MKCOL", "/dav/primary/made", http.StatusCreated},
+		{"MKCOL", "/dav/primary/made", http.StatusMethodNotAllowed},
+		{http.MethodPut, "/dav/primary/made/a.txt", http.StatusCreated},
+		{http.MethodPut, "/dav/primary/made/a.txt", http.StatusNoContent},
+		{http.MethodPut, "/dav/primary/missing/a.txt", http.StatusConflict},
+		{"MKCOL", "/dav/s1/made-in-s1", http.StatusMethodNotAllowed},
+		{http.MethodPut, "/dav/s1/a.txt", http.StatusMethodNotAllowed},
+		{http.MethodDelete, "/dav/primary/made", http.StatusNoContent},
+	} {
+		body := ""
+		if tc.method == http.MethodPut {
+			body = "data"
+		}
+		resp := davRequest(t, server, tc.method, tc.href, nil, body)
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s %s = %d, want %d", tc.method, tc.href, resp.StatusCode, tc.status)
+		}
+		if tc.method == "MKCOL" && tc.status == http.StatusCreated {
+			if _, err := fs.GetNodeContext(t.Context(), &sdk.GetNodeRequest{Path: "/made", TableName: "primary"}); err != nil {
+				t.Errorf("MKCOL did not create the folder: %v", err)
+			}
+		}
+	}
+
+	resp := davRequest(t, server, "PROPFIND", "/dav/primary/", map[string]string{"Depth": "infinity"}, "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("PROPFIND Depth: infinity = %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestWebDAVDisabled(t *testing.T) {
+	server, _ := newServer(t, func(*sdk.Config) {})
+	resp := davRequest(t, server, "PROPFIND", "/dav/primary/", map[string]string{"Depth": "1"}, "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("PROPFIND without api.webdav_enabled = %d, want 404", resp.StatusCode)
+	}
+}
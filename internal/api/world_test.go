@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+func TestWorldsEndpoint(t *testing.T) {
+	server, _ := newServer(t, func(cfg *sdk.Config) { cfg.SecondaryTables = map[string]float64{"s1": 0.7, "s2": 0.2} })
+
+	resp, err := http.Get(server.URL + "/api/v1/worlds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var envelope struct {
+		Data []types.WorldInfo `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /api/v1/worlds = %d, %v", resp.StatusCode, err)
+	}
+	worlds := envelope.Data
+	want := []types.WorldInfo{
+		{Name: "primary", Type: "primary", Probability: 1},
+		{Name: "s1", Type: "secondary", Probability: 0.7},
+		{Name: "s2", Type: "secondary", Probability: 0.2},
+	}
+	if len(worlds) != len(want) {
+		t.Fatalf("worlds = %+v, want %+v", worlds, want)
+	}
+	for i := range want {
+		if worlds[i] != want[i] {
+			t.Errorf("world %d = %+v, want %+v", i, worlds[i], want[i])
+		}
+	}
+}
+
+func TestUnknownWorldIsBadRequest(t *testing.T) {
+	server, _ := newServer(t, func(*sdk.Config) {})
+
+	for _, tc := range []struct{ method, path, body string }{
+		{http.MethodPost, "/api/v1/items/list", `{"parent_path": "/", "table_name": "s9"}`},
+		{http.MethodPost, "/api/v1/items/folder", `{"parent_path": "/", "table_name": "s9", "name": "x"}`},
+		{http.MethodGet, "/api/v1/duplicates?world=s9", ""},
+	} {
+		status, code := send(t, server, tc.method, tc.path, nil, tc.body)
+		if status != http.StatusBadRequest || code != "unknown_world" {
+			t.Errorf("%s %s = %d %q, want 400 \"unknown_world\"", tc.method, tc.path, status, code)
+		}
+	}
+}
+
+func TestWorldExistenceEndpoint(t *testing.T) {
+	server, fs := newServer(t, func(cfg *sdk.Config) { cfg.SecondaryTables = map[string]float64{"s1": 1} })
+	folder, err := fs.CreateFolderContext(t.Context(), &sdk.CreateFolderRequest{ParentID: "root", Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := fs.CreateFolderContext(t.Context(), &sdk.CreateFolderRequest{ParentID: folder.ID, Name: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		body   string
+		status int
+		code   string
+	}{
+		{`{"id": "` + folder.ID + `", "exists": false}`, http.StatusOK, ""},
+		{`{"id": "` + sub.ID + `", "exists": true}`, http.StatusConflict, "ancestor_missing"},
+		{`{"id": "` + folder.ID + `", "exists": true, "recursive": true}`, http.StatusOK, ""},
+		{`{"exists": true}`, http.StatusBadRequest, "invalid_input"},
+	} {
+		status, code := send(t, server, http.MethodPost, "/api/v1/worlds/s1/existence", nil, tc.body)
+		if status != tc.status || code != tc.code {
+			t.Errorf("POST %s = %d %q, want %d %q", tc.body, status, code, tc.status, tc.code)
+		}
+	}
+	if status, code := send(t, server, http.MethodPost, "/api/v1/worlds/s9/existence", nil, `{"id": "`+folder.ID+`", "exists": false}`); status != http.StatusBadRequest || code != "unknown_world" {
+		t.Errorf("setting existence in s9 = %d %q, want 400 \"unknown_world\"", status, code)
+	}
+
+	node, err := fs.GetNodeContext(t.Context(), &sdk.GetNodeRequest{ID: sub.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !node.ExistenceMap["s1"] {
+		t.Errorf("%s is not back in s1 after the recursive add", node.Path)
+	}
+}
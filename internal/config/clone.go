@@ -0,0 +1,87 @@
+package config
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// Clone returns a deep copy of cfg that shares no maps, slices or pointers with it
+func Clone(cfg *types.Config) *types.Config {
+	if cfg == nil {
+		return nil
+	}
+	clone := *cfg
+	clone.Seed = cloneSeed(cfg.Seed)
+	clone.API.Auth.Tokens = slices.Clone(cfg.API.Auth.Tokens)
+	clone.SecondaryTables = maps.Clone(cfg.SecondaryTables)
+	clone.MaintenanceSchedule = maps.Clone(cfg.MaintenanceSchedule)
+
+	if cfg.Retention != nil {
+		clone.Retention = make(map[string][]types.RetentionRule, len(cfg.Retention))
+		for world, rules := range cfg.Retention {
+			clone.Retention[world] = slices.Clone(rules)
+		}
+	}
+	if cfg.WorldGeneration != nil {
+		clone.WorldGeneration = make(map[string]types.WorldGeneration, len(cfg.WorldGeneration))
+		for world, generation := range cfg.WorldGeneration {
+			generation.MinFolders = cloneInt(generation.MinFolders)
+			generation.MaxFolders = cloneInt(generation.MaxFolders)
+			generation.MinFiles = cloneInt(generation.MinFiles)
+			generation.MaxFiles = cloneInt(generation.MaxFiles)
+			clone.WorldGeneration[world] = generation
+		}
+	}
+	if cfg.Chaos.Operations != nil {
+		clone.Chaos.Operations = make(map[string]types.ChaosRule, len(cfg.Chaos.Operations))
+		for op, rule := range cfg.Chaos.Operations {
+			rule.LatencyMS = slices.Clone(rule.LatencyMS)
+			clone.Chaos.Operations[op] = rule
+		}
+	}
+	if cfg.Instances != nil {
+		clone.Instances = make([]types.InstanceConfig, len(cfg.Instances))
+		for i, instance := range cfg.Instances {
+			clone.Instances[i] = types.InstanceConfig{Name: instance.Name, Seed: cloneSeed(instance.Seed)}
+		}
+	}
+	return &clone
+}
+
+// cloneSeed returns a deep copy of a seed section
+func cloneSeed(seed types.SeedConfig) types.SeedConfig {
+	clone := seed
+	clone.ExtensionWeights = maps.Clone(seed.ExtensionWeights)
+	clone.FolderNamePatterns = slices.Clone(seed.FolderNamePatterns)
+	clone.FileNamePatterns = slices.Clone(seed.FileNamePatterns)
+	if seed.MetadataPool != nil {
+		clone.MetadataPool = make(map[string][]string, len(seed.MetadataPool))
+		for key, patterns := range seed.MetadataPool {
+			clone.MetadataPool[key] = slices.Clone(patterns)
+		}
+	}
+	if seed.Profile != nil {
+		profile := *seed.Profile
+		profile.Depths = slices.Clone(seed.Profile.Depths)
+		for i := range profile.Depths {
+			depth := &profile.Depths[i]
+			depth.MinFolders = cloneInt(depth.MinFolders)
+			depth.MaxFolders = cloneInt(depth.MaxFolders)
+			depth.MinFiles = cloneInt(depth.MinFiles)
+			depth.MaxFiles = cloneInt(depth.MaxFiles)
+		}
+		clone.Profile = &profile
+	}
+	return clone
+}
+
+// cloneInt returns a pointer to a copy of *p, or nil
+func cloneInt(p *int) *int {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
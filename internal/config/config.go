@@ -3,9 +3,17 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"net"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/generator"
+	"github.com/Project-Sylos/Spectra/internal/logging"
 	"github.com/Project-Sylos/Spectra/internal/types"
 )
 
@@ -20,12 +28,15 @@ func DefaultConfig() types.Config {
 			MaxFiles:       5,
 			Seed:           42,
 			DBPath:         "./spectra.db",
-			FileBinarySeed: 0,
+			FileBinarySeed: generator.DeriveFileBinarySeed(42),
 		},
 		API: types.APIConfig{
 			Host: "localhost",
 			Port: 8086,
 		},
+		DB: types.DBConfig{
+			Preload: types.PreloadNone,
+		},
 		SecondaryTables: map[string]float64{
 			"s1": 0.7,
 		},
@@ -50,22 +61,41 @@ func LoadFromFile(configPath string) (*types.Config, error) {
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config JSON: %w", err)
 	}
+	if err := loadInstanceSeeds(&cfg, data); err != nil {
+		return nil, fmt.Errorf("failed to parse config JSON: %w", err)
+	}
 
 	// Validate configuration
 	if err := Validate(&cfg); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
 	}
 
+	// Fill in what the file leaves out
+	if err := ApplyDefaults(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// ApplyDefaults fills in the settings cfg leaves unset: the derived file_binary_seed, the DB path
+// (made absolute), the API host, port and response case, and the preload mode
+func ApplyDefaults(cfg *types.Config) error {
+	// Derive the file content seed from the main seed if not specified
+	if cfg.Seed.FileBinarySeed == 0 {
+		cfg.Seed.FileBinarySeed = generator.DeriveFileBinarySeed(cfg.Seed.Seed)
+	}
+
 	// Set default DB path if not specified
 	if cfg.Seed.DBPath == "" {
-		cfg.Seed.DBPath = "./spectra.db"
+		cfg.Seed.DBPath = defaultDBPath
 	}
 
-	// Ensure DB path is absolute
-	if !filepath.IsAbs(cfg.Seed.DBPath) {
+	// Ensure DB path is absolute (":memory:" names no file)
+	if !filepath.IsAbs(cfg.Seed.DBPath) && !db.IsMemoryPath(cfg.Seed.DBPath) {
 		absPath, err := filepath.Abs(cfg.Seed.DBPath)
 		if err != nil {
-			return nil, fmt.Errorf("failed to resolve DB path: %w", err)
+			return fmt.Errorf("failed to resolve DB path: %w", err)
 		}
 		cfg.Seed.DBPath = absPath
 	}
@@ -77,8 +107,16 @@ func LoadFromFile(configPath string) (*types.Config, error) {
 	if cfg.API.Port == 0 {
 		cfg.API.Port = 8086
 	}
+	if cfg.API.ResponseCase == "" {
+		cfg.API.ResponseCase = "snake"
+	}
 
-	return &cfg, nil
+	// Set default DB config if not specified
+	if cfg.DB.Preload == "" {
+		cfg.DB.Preload = types.PreloadNone
+	}
+
+	return nil
 }
 
 // Validate checks that the configuration parameters are valid
@@ -108,10 +146,164 @@ func Validate(cfg *types.Config) error {
 		return fmt.Errorf("max_files (%d) must be >= min_files (%d)", cfg.Seed.MaxFiles, cfg.Seed.MinFiles)
 	}
 
+	if cfg.Seed.FileBinarySeed < 0 {
+		return fmt.Errorf("file_binary_seed must be non-negative (0 derives it from seed), got %d", cfg.Seed.FileBinarySeed)
+	}
+
+	if cfg.Seed.TimestampStepMillis < 0 {
+		return fmt.Errorf("timestamp_step_ms must be non-negative, got %d", cfg.Seed.TimestampStepMillis)
+	}
+	if cfg.Seed.BaseTimestamp != "" {
+		if _, err := time.Parse(time.RFC3339, cfg.Seed.BaseTimestamp); err != nil {
+			return fmt.Errorf("base_timestamp must be an RFC 3339 time, got %q: %w", cfg.Seed.BaseTimestamp, err)
+		}
+	}
+	if cfg.Seed.TimestampJitter != "" {
+		jitter, err := time.ParseDuration(cfg.Seed.TimestampJitter)
+		if err != nil {
+			return fmt.Errorf("timestamp_jitter must be a duration, got %q: %w", cfg.Seed.TimestampJitter, err)
+		}
+		if jitter < 0 {
+			return fmt.Errorf("timestamp_jitter must be non-negative, got %s", jitter)
+		}
+	}
+
+	if cfg.Seed.MinFileSize < 0 {
+		return fmt.Errorf("min_file_size must be non-negative, got %d", cfg.Seed.MinFileSize)
+	}
+
+	if cfg.Seed.FileSizeCap < 0 {
+		return fmt.Errorf("file_size_cap must be non-negative, got %d", cfg.Seed.FileSizeCap)
+	}
+
+	minSize, maxSize := generator.FileSizeRange(cfg)
+	if maxSize < minSize {
+		return fmt.Errorf("max_file_size (%d) must be >= min_file_size (%d)", maxSize, minSize)
+	}
+
+	if sizeCap := generator.FileSizeCap(cfg); maxSize > sizeCap {
+		return fmt.Errorf("max_file_size (%d) exceeds file_size_cap (%d)", maxSize, sizeCap)
+	}
+	if cfg.Seed.PerFileBandwidth < 0 {
+		return fmt.Errorf("per_file_bandwidth must be non-negative, got %d", cfg.Seed.PerFileBandwidth)
+	}
+	if cfg.Seed.SymlinkProbability < 0.0 || cfg.Seed.SymlinkProbability > 1.0 {
+		return fmt.Errorf("symlink_probability must be between 0.0 and 1.0, got %f", cfg.Seed.SymlinkProbability)
+	}
+	if cfg.Seed.DuplicateProbability < 0.0 || cfg.Seed.DuplicateProbability > 1.0 {
+		return fmt.Errorf("duplicate_probability must be between 0.0 and 1.0, got %f", cfg.Seed.DuplicateProbability)
+	}
+	if cfg.Seed.MetadataProbability < 0.0 || cfg.Seed.MetadataProbability > 1.0 {
+		return fmt.Errorf("metadata_probability must be between 0.0 and 1.0, got %f", cfg.Seed.MetadataProbability)
+	}
+	for key, patterns := range cfg.Seed.MetadataPool {
+		if key == "" {
+			return fmt.Errorf("metadata_pool keys must not be empty")
+		}
+		if len(patterns) == 0 {
+			return fmt.Errorf("metadata_pool key %q needs at least one value pattern", key)
+		}
+	}
+	if err := generator.ValidateNaming(cfg); err != nil {
+		return err
+	}
+
 	// Validate API config
 	if cfg.API.Port < 1 || cfg.API.Port > 65535 {
 		return fmt.Errorf("API port must be between 1 and 65535, got %d", cfg.API.Port)
 	}
+	if cfg.API.ResponseCase != "" && cfg.API.ResponseCase != "snake" && cfg.API.ResponseCase != "camel" {
+		return fmt.Errorf("API response_case must be \"snake\" or \"camel\", got %q", cfg.API.ResponseCase)
+	}
+	if _, err := logging.New(io.Discard, cfg.API.LogLevel, cfg.API.LogFormat); err != nil {
+		return fmt.Errorf("API %w", err)
+	}
+	if cfg.API.MaxReadBandwidth < 0 {
+		return fmt.Errorf("API max_read_bandwidth must be non-negative, got %d", cfg.API.MaxReadBandwidth)
+	}
+	if cfg.API.MaxUploadBytes < 0 {
+		return fmt.Errorf("API max_upload_bytes must be non-negative, got %d", cfg.API.MaxUploadBytes)
+	}
+	if cfg.API.MaxJobs < 0 {
+		return fmt.Errorf("API max_jobs must be non-negative, got %d", cfg.API.MaxJobs)
+	}
+	for _, timeout := range []struct{ name, value string }{
+		{"read_timeout", cfg.API.ReadTimeout},
+		{"write_timeout", cfg.API.WriteTimeout},
+		{"idle_timeout", cfg.API.IdleTimeout},
+		{"shutdown_timeout", cfg.API.ShutdownTimeout},
+	} {
+		if timeout.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(timeout.value)
+		if err != nil {
+			return fmt.Errorf("API %s: invalid duration %q: %w", timeout.name, timeout.value, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("API %s must be positive, got %s", timeout.name, d)
+		}
+	}
+	seenTokens := make(map[string]bool, len(cfg.API.Auth.Tokens))
+	for i, token := range cfg.API.Auth.Tokens {
+		if token.Token == "" {
+			return fmt.Errorf("API auth: token %d is empty", i)
+		}
+		if seenTokens[token.Token] {
+			return fmt.Errorf("API auth: token %d is listed more than once", i)
+		}
+		seenTokens[token.Token] = true
+		if token.Role != "" && !slices.Contains(types.AuthRoles, token.Role) {
+			return fmt.Errorf("API auth: token %d: role must be one of %s, got %q", i, strings.Join(types.AuthRoles, ", "), token.Role)
+		}
+	}
+
+	// Validate DB config
+	switch cfg.DB.Preload {
+	case "", types.PreloadNone, types.PreloadIndex, types.PreloadFull:
+	default:
+		return fmt.Errorf("db preload must be \"none\", \"index\" or \"full\", got %q", cfg.DB.Preload)
+	}
+	if cfg.DB.PreloadMaxBytes < 0 {
+		return fmt.Errorf("db preload_max_bytes must be non-negative, got %d", cfg.DB.PreloadMaxBytes)
+	}
+	if cfg.DB.JournalMaxEntries < 0 {
+		return fmt.Errorf("db journal_max_entries must be non-negative, got %d", cfg.DB.JournalMaxEntries)
+	}
+	if cfg.DB.SnapshotKeep < 0 {
+		return fmt.Errorf("db snapshot_keep must be non-negative, got %d", cfg.DB.SnapshotKeep)
+	}
+	switch cfg.DB.FreelistType {
+	case "", types.FreelistArray, types.FreelistMap:
+	default:
+		return fmt.Errorf("db freelist_type must be \"array\" or \"map\", got %q", cfg.DB.FreelistType)
+	}
+	if cfg.DB.InitialMmapSize < 0 {
+		return fmt.Errorf("db initial_mmap_size must be non-negative, got %d", cfg.DB.InitialMmapSize)
+	}
+	if cfg.DB.NodeCacheSize < 0 {
+		return fmt.Errorf("db node_cache_size must be non-negative, got %d", cfg.DB.NodeCacheSize)
+	}
+	if cfg.DB.BulkBatchSize < 0 {
+		return fmt.Errorf("db bulk_batch_size must be non-negative, got %d", cfg.DB.BulkBatchSize)
+	}
+	if cfg.DB.CompactOnCloseRatio < 0 || cfg.DB.CompactOnCloseRatio >= 1 {
+		return fmt.Errorf("db compact_on_close_ratio must be in [0, 1), got %g", cfg.DB.CompactOnCloseRatio)
+	}
+	if cfg.DB.ReadOnly {
+		switch {
+		case cfg.DB.AutoRepair:
+			return fmt.Errorf("db read_only cannot be combined with auto_repair")
+		case cfg.DB.AcceptConfigChange:
+			return fmt.Errorf("db read_only cannot be combined with accept_config_change")
+		case cfg.DB.CompactOnCloseRatio > 0:
+			return fmt.Errorf("db read_only cannot be combined with compact_on_close_ratio")
+		case cfg.Mutations.Enabled:
+			return fmt.Errorf("db read_only cannot be combined with mutations.enabled")
+		case len(cfg.MaintenanceSchedule) > 0:
+			return fmt.Errorf("db read_only cannot be combined with a maintenance_schedule")
+		}
+	}
 
 	// Validate secondary tables
 	for tableName, probability := range cfg.SecondaryTables {
@@ -120,9 +312,192 @@ func Validate(cfg *types.Config) error {
 		}
 	}
 
+	if cfg.Seed.MaxTotalNodes < 0 {
+		return fmt.Errorf("max_total_nodes must be non-negative, got %d", cfg.Seed.MaxTotalNodes)
+	}
+	if cfg.Seed.MaxTotalBytes < 0 {
+		return fmt.Errorf("max_total_bytes must be non-negative, got %d", cfg.Seed.MaxTotalBytes)
+	}
+
+	// Validate the generation profile
+	if profile := cfg.Seed.Profile; profile != nil {
+		for i, level := range profile.Depths {
+			if level.MinDepth < 0 || level.MaxDepth < level.MinDepth {
+				return fmt.Errorf("profile depth entry %d: invalid depth range: min_depth=%d, max_depth=%d", i, level.MinDepth, level.MaxDepth)
+			}
+			minFolders, maxFolders, minFiles, maxFiles := generator.LevelCountRanges(cfg, level)
+			if minFolders < 0 || maxFolders < minFolders {
+				return fmt.Errorf("profile depth entry %d: invalid folder count range: min=%d, max=%d", i, minFolders, maxFolders)
+			}
+			if minFiles < 0 || maxFiles < minFiles {
+				return fmt.Errorf("profile depth entry %d: invalid file count range: min=%d, max=%d", i, minFiles, maxFiles)
+			}
+		}
+		if profile.LongTailFraction < 0.0 || profile.LongTailFraction > 1.0 {
+			return fmt.Errorf("profile long_tail_fraction must be between 0.0 and 1.0, got %f", profile.LongTailFraction)
+		}
+		if profile.LongTailMultiplier < 0 {
+			return fmt.Errorf("profile long_tail_multiplier must be non-negative, got %d", profile.LongTailMultiplier)
+		}
+	}
+
+	// Validate per-world extra-node settings
+	for world, override := range cfg.WorldGeneration {
+		if _, ok := cfg.SecondaryTables[world]; !ok {
+			return fmt.Errorf("world_generation references undeclared secondary world %s", world)
+		}
+		if override.ExtraNodesProbability < 0.0 || override.ExtraNodesProbability > 1.0 {
+			return fmt.Errorf("world_generation %s: extra_nodes_probability must be between 0.0 and 1.0, got %f", world, override.ExtraNodesProbability)
+		}
+		minFolders, maxFolders, minFiles, maxFiles := generator.WorldCountRanges(cfg, world)
+		if minFolders < 0 || maxFolders < minFolders {
+			return fmt.Errorf("world_generation %s: invalid folder count range: min=%d, max=%d", world, minFolders, maxFolders)
+		}
+		if minFiles < 0 || maxFiles < minFiles {
+			return fmt.Errorf("world_generation %s: invalid file count range: min=%d, max=%d", world, minFiles, maxFiles)
+		}
+	}
+
+	// Validate retention rules
+	for world, rules := range cfg.Retention {
+		if _, ok := cfg.SecondaryTables[world]; !ok && world != "primary" {
+			return fmt.Errorf("retention rules reference unknown world %s", world)
+		}
+		for i, rule := range rules {
+			if !strings.HasPrefix(rule.PathPrefix, "/") {
+				return fmt.Errorf("retention rule %d for world %s: path_prefix must start with \"/\", got %q", i, world, rule.PathPrefix)
+			}
+			if rule.TTLSeconds <= 0 {
+				return fmt.Errorf("retention rule %d for world %s: ttl_seconds must be positive, got %d", i, world, rule.TTLSeconds)
+			}
+		}
+	}
+
+	// Validate root display name (cosmetic, but it must still be a valid single path element)
+	if strings.Contains(cfg.RootDisplayName, "/") {
+		return fmt.Errorf("root_display_name must not contain \"/\", got %q", cfg.RootDisplayName)
+	}
+
+	// Validate maintenance schedule
+	for task, interval := range cfg.MaintenanceSchedule {
+		if !slices.Contains(types.MaintenanceTasks, task) {
+			return fmt.Errorf("maintenance_schedule: unknown task %s (supported: %s)", task, strings.Join(types.MaintenanceTasks, ", "))
+		}
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			return fmt.Errorf("maintenance_schedule: task %s: invalid interval %q: %w", task, interval, err)
+		}
+		if d < time.Second {
+			return fmt.Errorf("maintenance_schedule: task %s: interval must be at least 1s, got %s", task, d)
+		}
+	}
+
+	// Validate the mutation engine
+	if err := validateMutations(cfg); err != nil {
+		return err
+	}
+
+	// Validate debug hooks
+	if cfg.Debug.FailGenerationAfterNNodes < 0 {
+		return fmt.Errorf("debug fail_generation_after_n_nodes must be non-negative, got %d", cfg.Debug.FailGenerationAfterNNodes)
+	}
+
+	if err := ValidateChaos(&cfg.Chaos); err != nil {
+		return err
+	}
+
+	// Validate the hosted instances
+	return validateInstances(cfg)
+}
+
+// ValidateChaos checks chaos rules; it also guards settings changed at runtime
+func ValidateChaos(chaos *types.ChaosConfig) error {
+	for op, rule := range chaos.Operations {
+		if op != types.ChaosOpAny && !slices.Contains(types.ChaosOperations, op) {
+			return fmt.Errorf("chaos: unknown operation %s (supported: %s, or %q for any)", op, strings.Join(types.ChaosOperations, ", "), types.ChaosOpAny)
+		}
+		if len(rule.LatencyMS) != 0 {
+			if len(rule.LatencyMS) != 2 {
+				return fmt.Errorf("chaos: operation %s: latency_ms must be [min, max], got %v", op, rule.LatencyMS)
+			}
+			if rule.LatencyMS[0] < 0 || rule.LatencyMS[1] < rule.LatencyMS[0] {
+				return fmt.Errorf("chaos: operation %s: latency_ms needs 0 <= min <= max, got %v", op, rule.LatencyMS)
+			}
+		}
+		if rule.ErrorRate < 0.0 || rule.ErrorRate > 1.0 {
+			return fmt.Errorf("chaos: operation %s: error_rate must be between 0.0 and 1.0, got %f", op, rule.ErrorRate)
+		}
+		if rule.ErrorCode != 0 && (rule.ErrorCode < 400 || rule.ErrorCode > 599) {
+			return fmt.Errorf("chaos: operation %s: error_code must be between 400 and 599, got %d", op, rule.ErrorCode)
+		}
+	}
+	return nil
+}
+
+// validateMutations checks the mutations section
+func validateMutations(cfg *types.Config) error {
+	mutations := cfg.Mutations
+	if mutations.Interval != "" {
+		d, err := time.ParseDuration(mutations.Interval)
+		if err != nil {
+			return fmt.Errorf("mutations: invalid interval %q: %w", mutations.Interval, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("mutations: interval must be positive, got %s", d)
+		}
+	}
+	if mutations.Rate < 0 {
+		return fmt.Errorf("mutations: rate must be non-negative, got %d", mutations.Rate)
+	}
+	weights := mutations.Weights
+	if weights.Create < 0 || weights.Modify < 0 || weights.Delete < 0 {
+		return fmt.Errorf("mutations: weights must be non-negative, got create=%g modify=%g delete=%g", weights.Create, weights.Modify, weights.Delete)
+	}
+	if mutations.Scope != "" && !strings.HasPrefix(mutations.Scope, "/") {
+		return fmt.Errorf("mutations: scope must start with \"/\", got %q", mutations.Scope)
+	}
+	if _, ok := cfg.SecondaryTables[mutations.World]; !ok && mutations.World != "" && mutations.World != "primary" {
+		return fmt.Errorf("mutations: unknown world %s", mutations.World)
+	}
 	return nil
 }
 
+// Warnings returns non-fatal problems with a valid configuration, such as testing hooks left enabled
+func Warnings(cfg *types.Config) []string {
+	var warnings []string
+	if cfg.Debug.FailGenerationAfterNNodes > 0 {
+		warnings = append(warnings, fmt.Sprintf(
+			"debug.fail_generation_after_n_nodes is set (%d): generation will fail on purpose; this is a testing hook and must not be used in production",
+			cfg.Debug.FailGenerationAfterNNodes))
+	}
+	if cfg.Debug.ExposeBuckets {
+		warnings = append(warnings, "debug.expose_buckets is set: raw database contents are served under /api/v1/debug/buckets")
+	}
+	if len(cfg.Chaos.Operations) > 0 {
+		warnings = append(warnings, "chaos rules are set: API requests and sdk.ChaosFS calls will be delayed and fail on purpose; this is a testing hook")
+	}
+	if cfg.DB.AcceptConfigChange {
+		warnings = append(warnings, "db.accept_config_change is set: a database generated with other seed, branching or world settings is opened and continued with the config's")
+	}
+	if cfg.Mutations.Enabled {
+		warnings = append(warnings, "mutations.enabled is set: the server will change, add and delete nodes in the background")
+	}
+	if len(cfg.API.Auth.Tokens) == 0 && !isLoopbackHost(cfg.API.Host) {
+		warnings = append(warnings, fmt.Sprintf(
+			"api.host is %s and api.auth lists no tokens: every endpoint, including /api/v1/reset, is open to the network", cfg.API.Host))
+	}
+	return warnings
+}
+
+// isLoopbackHost reports whether host only accepts local connections
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // SaveToFile saves configuration to a JSON file
 func SaveToFile(cfg *types.Config, configPath string) error {
 	data, err := json.MarshalIndent(cfg, "", "  ")
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/generator"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// writeConfig writes a config file with the given seed section and returns its path
+func writeConfig(t *testing.T, seed string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := fmt.Sprintf(`{"api": {"port": 8086}, "seed": {"max_depth": 2, "min_folders": 1, "max_folders": 2, "min_files": 1, "max_files": 2, "db_path": ":memory:", %s}}`, seed)
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// fileContent returns the generated content of a 1KiB file with node ID id under cfg
+func fileContent(t *testing.T, cfg *types.Config, id string) []byte {
+	t.Helper()
+	data, _, err := generator.GenerateDeterministicFileData(generator.ContentSeed(cfg, id), 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestFileBinarySeedDerivedFromSeed(t *testing.T) {
+	one, err := LoadFromFile(writeConfig(t, `"seed": 1`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	two, err := LoadFromFile(writeConfig(t, `"seed": 2`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if one.Seed.FileBinarySeed != generator.DeriveFileBinarySeed(1) || two.Seed.FileBinarySeed != generator.DeriveFileBinarySeed(2) {
+		t.Errorf("file_binary_seed %d and %d, want the ones derived from seeds 1 and 2", one.Seed.FileBinarySeed, two.Seed.FileBinarySeed)
+	}
+	if bytes.Equal(fileContent(t, one, "node"), fileContent(t, two, "node")) {
+		t.Error("configs with different seeds generated the same file content")
+	}
+
+	// An explicit file_binary_seed wins, so both seeds share content
+	three, err := LoadFromFile(writeConfig(t, `"seed": 3, "file_binary_seed": 7`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	four, err := LoadFromFile(writeConfig(t, `"seed": 4, "file_binary_seed": 7`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if three.Seed.FileBinarySeed != 7 || !bytes.Equal(fileContent(t, three, "node"), fileContent(t, four, "node")) {
+		t.Error("an explicit file_binary_seed did not fix the file content")
+	}
+}
+
+func TestFileBinarySeedRoundTrip(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.Seed.FileBinarySeed != generator.DeriveFileBinarySeed(cfg.Seed.Seed) {
+		t.Errorf("DefaultConfig file_binary_seed %d, want the one derived from seed %d", cfg.Seed.FileBinarySeed, cfg.Seed.Seed)
+	}
+
+	cfg.Seed.FileBinarySeed = 12345
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := SaveToFile(&cfg, path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Seed.FileBinarySeed != 12345 {
+		t.Errorf("file_binary_seed after SaveToFile and LoadFromFile = %d, want 12345", loaded.Seed.FileBinarySeed)
+	}
+}
+
+func TestFileBinarySeedRejectsNegative(t *testing.T) {
+	_, err := LoadFromFile(writeConfig(t, `"seed": 1, "file_binary_seed": -1`))
+	if err == nil || !strings.Contains(err.Error(), "file_binary_seed") {
+		t.Errorf("a negative file_binary_seed: %v, want a file_binary_seed error", err)
+	}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/generator"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// defaultDBPath is the database path used when seed.db_path is unset
+const defaultDBPath = "./spectra.db"
+
+// instanceNamePattern matches instance names, which appear in URLs and database file names
+var instanceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// ValidateInstanceName checks that name can name an instance
+func ValidateInstanceName(name string) error {
+	if !instanceNamePattern.MatchString(name) {
+		return fmt.Errorf("instance name %q must be 1-64 letters, digits, '-' or '_', starting with a letter or digit", name)
+	}
+	return nil
+}
+
+// InstanceSeed lays an instance's seed section (raw JSON; empty keeps every setting) over base's seed
+// The returned db_path is empty unless the section sets one, so ForInstance derives it
+func InstanceSeed(base *types.Config, raw json.RawMessage) (types.SeedConfig, error) {
+	seed := base.Seed
+	seed.DBPath = ""
+	if len(raw) == 0 {
+		return seed, nil
+	}
+	if err := json.Unmarshal(raw, &seed); err != nil {
+		return seed, fmt.Errorf("invalid instance seed: %w", err)
+	}
+	return seed, nil
+}
+
+// InstanceDBPath returns the database path of an instance without a db_path of its own: basePath with
+// the name inserted before the extension (spectra.db becomes spectra.<name>.db). ":memory:" stays in memory
+func InstanceDBPath(basePath, name string) string {
+	if db.IsMemoryPath(basePath) {
+		return basePath
+	}
+	if basePath == "" {
+		basePath = defaultDBPath
+	}
+	ext := filepath.Ext(basePath)
+	return strings.TrimSuffix(basePath, ext) + "." + name + ext
+}
+
+// ForInstance returns the full config of a named instance: base with the instance's seed section and
+// no instances of its own. An unset db_path, or one equal to base's, is derived with InstanceDBPath, and
+// an unset file_binary_seed, or one base derived from a seed the instance changed, from the instance's seed
+func ForInstance(base *types.Config, instance types.InstanceConfig) (*types.Config, error) {
+	if err := ValidateInstanceName(instance.Name); err != nil {
+		return nil, err
+	}
+
+	cfg := *base
+	cfg.Seed = instance.Seed
+	cfg.Instances = nil
+	inherited := cfg.Seed.Seed != base.Seed.Seed && cfg.Seed.FileBinarySeed == generator.DeriveFileBinarySeed(base.Seed.Seed)
+	if cfg.Seed.FileBinarySeed == 0 || inherited {
+		cfg.Seed.FileBinarySeed = generator.DeriveFileBinarySeed(cfg.Seed.Seed)
+	}
+	if cfg.Seed.DBPath == "" || (cfg.Seed.DBPath == base.Seed.DBPath && !db.IsMemoryPath(base.Seed.DBPath)) {
+		cfg.Seed.DBPath = InstanceDBPath(base.Seed.DBPath, instance.Name)
+	}
+	if !filepath.IsAbs(cfg.Seed.DBPath) && !db.IsMemoryPath(cfg.Seed.DBPath) {
+		absPath, err := filepath.Abs(cfg.Seed.DBPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve DB path of instance %s: %w", instance.Name, err)
+		}
+		cfg.Seed.DBPath = absPath
+	}
+	return &cfg, nil
+}
+
+// loadInstanceSeeds re-reads the instances' seed sections from the config file, laying each over the
+// main seed section so an instance only lists the settings it changes
+func loadInstanceSeeds(cfg *types.Config, data []byte) error {
+	var raw struct {
+		Instances []struct {
+			Seed json.RawMessage `json:"seed"`
+		} `json:"instances"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for i, instance := range raw.Instances {
+		seed, err := InstanceSeed(cfg, instance.Seed)
+		if err != nil {
+			return fmt.Errorf("instance %q: %w", cfg.Instances[i].Name, err)
+		}
+		cfg.Instances[i].Seed = seed
+	}
+	return nil
+}
+
+// validateInstances checks that instance names are valid and unique, that every instance's config is
+// valid, and that no two filesystems share a database file
+func validateInstances(cfg *types.Config) error {
+	basePath := cfg.Seed.DBPath
+	if basePath == "" {
+		basePath = defaultDBPath
+	}
+	paths := map[string]string{}
+	if abs, err := filepath.Abs(basePath); err == nil && !db.IsMemoryPath(basePath) {
+		paths[abs] = "the main filesystem"
+	}
+
+	names := make(map[string]bool, len(cfg.Instances))
+	for _, instance := range cfg.Instances {
+		if names[instance.Name] {
+			return fmt.Errorf("instance name %q is used more than once", instance.Name)
+		}
+		names[instance.Name] = true
+
+		instanceCfg, err := ForInstance(cfg, instance)
+		if err != nil {
+			return err
+		}
+		if err := Validate(instanceCfg); err != nil {
+			return fmt.Errorf("instance %q: %w", instance.Name, err)
+		}
+		if path := instanceCfg.Seed.DBPath; !db.IsMemoryPath(path) {
+			if other, taken := paths[path]; taken {
+				return fmt.Errorf("instance %q: db_path %s is already used by %s", instance.Name, path, other)
+			}
+			paths[path] = fmt.Sprintf("instance %q", instance.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// metaChildrenGeneratedPrefix prefixes the meta keys marking folders whose children were generated
+// (keyed by folder ID), whether any came out or not. Without the marker a folder that came out
+// empty, or whose children were all deleted since, looks never expanded, and the next listing would
+// run the generator for it again (bringing deleted children back under their derived IDs)
+const metaChildrenGeneratedPrefix = "children_generated/"
+
+// markChildrenGeneratedTx records that parentID's children were generated
+func (db *DB) markChildrenGeneratedTx(tx *bbolt.Tx, parentID string) error {
+	return db.meta.Put(tx, metaChildrenGeneratedPrefix+parentID, []byte{1})
+}
+
+// childrenGeneratedTx reports whether parentID carries the children-generated marker
+func (db *DB) childrenGeneratedTx(tx *bbolt.Tx, parentID string) (bool, error) {
+	value, err := db.meta.Get(tx, metaChildrenGeneratedPrefix+parentID)
+	return value != nil, err
+}
+
+// ChildrenGenerated reports whether parentID's children were generated (see InsertChildren and
+// MarkChildrenGenerated), so it must not be generated again
+func (db *DB) ChildrenGenerated(parentID string) (bool, error) {
+	var generated bool
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		generated, err = db.childrenGeneratedTx(tx, parentID)
+		return err
+	})
+	return generated, err
+}
+
+// MarkChildrenGenerated records that the children of each of parentIDs were generated, for
+// generation that stores them with BulkInsertNodes rather than InsertChildren
+func (db *DB) MarkChildrenGenerated(parentIDs []string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.withTx(func(tx *bbolt.Tx) error {
+		for _, id := range parentIDs {
+			if err := db.markChildrenGeneratedTx(tx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// unmarkChildrenGeneratedTx drops the markers of the deleted folders among nodes
+func (db *DB) unmarkChildrenGeneratedTx(tx *bbolt.Tx, nodes []*types.Node) error {
+	for _, node := range nodes {
+		if node.Type != types.NodeTypeFolder {
+			continue
+		}
+		if err := db.meta.Delete(tx, metaChildrenGeneratedPrefix+node.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearChildrenGeneratedTx drops every children-generated marker (for Reset and Import)
+func (db *DB) clearChildrenGeneratedTx(tx *bbolt.Tx) error {
+	keys, err := db.meta.Keys(tx, metaChildrenGeneratedPrefix)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := db.meta.Delete(tx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
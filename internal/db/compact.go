@@ -0,0 +1,144 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// compactTxMaxSize is how many bytes Compact copies per transaction into the new file
+const compactTxMaxSize = 64 << 20
+
+// CompactSuffix is appended to the database path to name Compact's default target
+const CompactSuffix = ".compact"
+
+// ErrCompactUnsupported is returned by Compact for an in-memory database, which has no file to shrink
+var ErrCompactUnsupported = NewError(ErrInvalidInput, "[SpectraFS] an in-memory database cannot be compacted")
+
+// Compact rewrites the database into destPath with only its live pages and swaps it in for the
+// database file, which bbolt otherwise never shrinks however much is deleted. destPath must not
+// exist and should be on the same filesystem as the database, since it is renamed over it; empty
+// means the database path plus CompactSuffix, replacing any file an interrupted compaction left there.
+// Writers wait while the copy runs; readers keep reading the old file until the swap and then
+// move to the new one
+func (db *DB) Compact(destPath string) (*types.CompactResult, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.compactLocked(destPath)
+}
+
+// compactLocked implements Compact
+// NOTE: This function assumes the caller already holds db.mu lock
+func (db *DB) compactLocked(destPath string) (*types.CompactResult, error) {
+	if db.readOnly {
+		return nil, ErrReadOnly
+	}
+	if db.cleanup != nil {
+		return nil, ErrCompactUnsupported
+	}
+	if destPath == "" {
+		// The database file is locked by this connection, so no other compaction can be using it
+		destPath = db.bolt().Path() + CompactSuffix
+		if err := os.Remove(destPath); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("[SpectraFS] failed to remove stale compaction target: %w", err)
+		}
+	}
+	if _, err := os.Stat(destPath); err == nil {
+		return nil, fmt.Errorf("[SpectraFS] compaction target %s already exists", destPath)
+	}
+
+	started := time.Now()
+	source := db.bolt()
+	path := source.Path()
+	sizeBefore, freeBefore, err := db.fileUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy the live pages into a fresh file
+	target, err := bbolt.Open(destPath, 0600, &bbolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("[SpectraFS] failed to create compaction target: %w", err)
+	}
+	if err := bbolt.Compact(target, source, compactTxMaxSize); err != nil {
+		target.Close()
+		os.Remove(destPath)
+		return nil, fmt.Errorf("[SpectraFS] failed to compact database: %w", err)
+	}
+	if err := target.Close(); err != nil {
+		os.Remove(destPath)
+		return nil, fmt.Errorf("[SpectraFS] failed to close compaction target: %w", err)
+	}
+
+	// Swap it in: the old connection keeps its (now unlinked) file until its readers finish
+	if err := os.Rename(destPath, path); err != nil {
+		os.Remove(destPath)
+		return nil, fmt.Errorf("[SpectraFS] failed to replace database file: %w", err)
+	}
+	compacted, err := bbolt.Open(path, 0600, db.options.boltOptions())
+	if err != nil {
+		return nil, fmt.Errorf("[SpectraFS] failed to reopen compacted database: %w", err)
+	}
+	db.db.Store(compacted)
+	if err := source.Close(); err != nil {
+		db.logger.Warn("failed to close database file replaced by compaction", "error", err)
+	}
+	if db.nodeCache != nil {
+		db.nodeCache.reset() // The new file numbers its transactions afresh
+	}
+
+	sizeAfter, _, err := db.fileUsage()
+	if err != nil {
+		return nil, err
+	}
+	return &types.CompactResult{
+		CompactedAt:     time.Now().UTC(),
+		DurationMillis:  time.Since(started).Milliseconds(),
+		SizeBefore:      sizeBefore,
+		SizeAfter:       sizeAfter,
+		FreeBytesBefore: freeBefore,
+	}, nil
+}
+
+// fileUsage returns the database file's size and the bytes held by its free pages
+func (db *DB) fileUsage() (int64, int64, error) {
+	bolt := db.bolt()
+	info, err := os.Stat(bolt.Path())
+	if err != nil {
+		return 0, 0, fmt.Errorf("[SpectraFS] failed to stat database file: %w", err)
+	}
+	return info.Size(), int64(bolt.Stats().FreeAlloc), nil
+}
+
+// CompactOnClose makes Close compact the database first when free pages make up at least ratio
+// of the file (0 never does)
+func (db *DB) CompactOnClose(ratio float64) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.compactRatio = ratio
+}
+
+// compactOnClose runs the compaction CompactOnClose asked for, if it is due; a failure is logged
+// and leaves the database as it was
+// NOTE: This function assumes the caller already holds db.mu lock
+func (db *DB) compactOnClose() {
+	if db.compactRatio <= 0 || db.cleanup != nil {
+		return
+	}
+	size, free, err := db.fileUsage()
+	if err != nil || size == 0 || float64(free)/float64(size) < db.compactRatio {
+		return
+	}
+
+	result, err := db.compactLocked("")
+	if err != nil {
+		db.logger.Warn("failed to compact database on close", "error", err)
+		return
+	}
+	db.logger.Info("compacted database on close", "size_before", result.SizeBefore, "size_after", result.SizeAfter)
+}
@@ -0,0 +1,174 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// GetSubtree returns a node and all of its descendants in every world, breadth-first (parents before children)
+func (db *DB) GetSubtree(id string) ([]*types.Node, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var subtree []*types.Node
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		subtree, err = db.subtreeTx(tx, id)
+		return err
+	})
+	return subtree, err
+}
+
+// PathExists reports whether any node, in any world, is stored at path
+func (db *DB) PathExists(path string) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var id string
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		id, err = db.index.LookupPath(tx, path)
+		return err
+	})
+	return id != "", err
+}
+
+// UpdateCopyStatus sets a node's copy status
+// status must be one of the CopyStatus constants; unknown IDs return ErrNodeNotFound
+func (db *DB) UpdateCopyStatus(id, status string) error {
+	if !types.IsValidCopyStatus(status) {
+		return fmt.Errorf("[SpectraFS] invalid copy status %q", status)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var updated []*types.Node
+	var sizes []int64
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		node, err := db.nodes.Get(tx, id)
+		if err != nil {
+			return err
+		}
+		if node == nil {
+			return fmt.Errorf("%w: %s", ErrNodeNotFound, id)
+		}
+		updated, sizes, err = db.putCopyStatusTx(tx, []*types.Node{node}, status)
+		return err
+	})
+
+	db.cacheCopyStatus(err, updated, sizes)
+	return err
+}
+
+// UpdateSubtreeCopyStatus sets the copy status of a node and all of its descendants in one transaction
+// Returns the number of nodes updated
+func (db *DB) UpdateSubtreeCopyStatus(id, status string) (int, error) {
+	if !types.IsValidCopyStatus(status) {
+		return 0, fmt.Errorf("[SpectraFS] invalid copy status %q", status)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var updated []*types.Node
+	var sizes []int64
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		subtree, err := db.subtreeTx(tx, id)
+		if err != nil {
+			return err
+		}
+		updated, sizes, err = db.putCopyStatusTx(tx, subtree, status)
+		return err
+	})
+
+	db.cacheCopyStatus(err, updated, sizes)
+	return len(updated), err
+}
+
+// SetCopyStatus sets the copy status of every listed node in one transaction
+// IDs that no longer exist are skipped
+func (db *DB) SetCopyStatus(ids []string, status string) error {
+	if !types.IsValidCopyStatus(status) {
+		return fmt.Errorf("[SpectraFS] invalid copy status %q", status)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var updated []*types.Node
+	var sizes []int64
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		nodes := make([]*types.Node, 0, len(ids))
+		for _, id := range ids {
+			node, err := db.nodes.Get(tx, id)
+			if err != nil {
+				return err
+			}
+			if node != nil {
+				nodes = append(nodes, node)
+			}
+		}
+
+		var err error
+		updated, sizes, err = db.putCopyStatusTx(tx, nodes, status)
+		return err
+	})
+
+	db.cacheCopyStatus(err, updated, sizes)
+	return err
+}
+
+// ListNodesByCopyStatus returns up to limit nodes in world with the given copy status, in ID order,
+// starting strictly after afterID ("" = from the start); more reports whether further matches exist
+// Nodes stored before copy status existed have an empty status and never match
+func (db *DB) ListNodesByCopyStatus(world, status, afterID string, limit int) ([]*types.Node, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	nodes := make([]*types.Node, 0)
+	more := false
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		return db.nodes.Scan(tx, afterID, func(node *types.Node) (bool, error) {
+			if node.CopyStatus != status || !node.ExistenceMap[world] {
+				return true, nil
+			}
+			if limit > 0 && len(nodes) == limit {
+				more = true
+				return false, nil
+			}
+			nodes = append(nodes, node)
+			return true, nil
+		})
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("[SpectraFS] failed to list nodes by copy status: %w", err)
+	}
+
+	return nodes, more, nil
+}
+
+// putCopyStatusTx stores nodes with their copy status set, returning them with their encoded sizes
+func (db *DB) putCopyStatusTx(tx *bbolt.Tx, nodes []*types.Node, status string) ([]*types.Node, []int64, error) {
+	sizes := make([]int64, len(nodes))
+	for i, node := range nodes {
+		node.CopyStatus = status
+		var err error
+		if sizes[i], err = db.nodes.Put(tx, node); err != nil {
+			return nil, nil, fmt.Errorf("[SpectraFS] failed to update copy status for %s: %w", node.ID, err)
+		}
+	}
+	return nodes, sizes, nil
+}
+
+// cacheCopyStatus mirrors committed copy status updates into the preload cache
+func (db *DB) cacheCopyStatus(err error, nodes []*types.Node, sizes []int64) {
+	if err != nil || db.cache == nil {
+		return
+	}
+	for i, node := range nodes {
+		db.cache.update(node, sizes[i])
+	}
+}
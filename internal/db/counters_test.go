@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"go.etcd.io/bbolt"
+)
+
+// worldCounts returns GetNodeCount for primary and s1, checking GetTableInfo agrees
+func worldCounts(tb testing.TB, database *DB) (primary, s1 int) {
+	tb.Helper()
+	counts := make(map[string]int)
+	for _, world := range []string{"primary", "s1"} {
+		count, err := database.GetNodeCount(context.Background(), world)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		counts[world] = count
+	}
+
+	tables, err := database.GetTableInfo(context.Background())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for _, table := range tables {
+		if table.RowCount != counts[table.Name] {
+			tb.Errorf("GetTableInfo %s = %d rows, GetNodeCount = %d", table.Name, table.RowCount, counts[table.Name])
+		}
+	}
+	return counts["primary"], counts["s1"]
+}
+
+func TestCountersFollowExistenceFlips(t *testing.T) {
+	database := newTestDB(t, Options{})
+	nodes := seedTree(t, database, 2, 2)
+	want := len(nodes) + 1 // The root too
+	if primary, s1 := worldCounts(t, database); primary != want || s1 != want {
+		t.Fatalf("counts after insert = %d, %d; want %d each", primary, s1, want)
+	}
+
+	file := nodes[1]
+	if err := database.UpdateExistenceMap(file.ID, map[string]bool{"primary": true, "s1": false}); err != nil {
+		t.Fatal(err)
+	}
+	if primary, s1 := worldCounts(t, database); primary != want || s1 != want-1 {
+		t.Errorf("counts after leaving s1 = %d, %d; want %d, %d", primary, s1, want, want-1)
+	}
+
+	if err := database.UpdateExistenceMap(file.ID, map[string]bool{"primary": true, "s1": true}); err != nil {
+		t.Fatal(err)
+	}
+	if primary, s1 := worldCounts(t, database); primary != want || s1 != want {
+		t.Errorf("counts after rejoining s1 = %d, %d; want %d each", primary, s1, want)
+	}
+
+	if err := database.DeleteNode(file.ID); err != nil {
+		t.Fatal(err)
+	}
+	if primary, s1 := worldCounts(t, database); primary != want-1 || s1 != want-1 {
+		t.Errorf("counts after delete = %d, %d; want %d each", primary, s1, want-1)
+	}
+}
+
+func TestRebuildCountersRepairsDrift(t *testing.T) {
+	database := newTestDB(t, Options{})
+	nodes := seedTree(t, database, 2, 2)
+	want := len(nodes) + 1
+
+	// Drop two nodes from the counters without touching the nodes
+	update(t, database, func(tx *bbolt.Tx) error { return database.stats.Apply(tx, nodes[:2], false) })
+	if primary, _ := worldCounts(t, database); primary == want {
+		t.Fatal("counters did not drift")
+	}
+
+	if err := database.RebuildCounters(); err != nil {
+		t.Fatal(err)
+	}
+	if primary, s1 := worldCounts(t, database); primary != want || s1 != want {
+		t.Errorf("counts after rebuild = %d, %d; want %d each", primary, s1, want)
+	}
+	if stats := storedStats(t, database); stats.SecondaryNodes["s1"] != int64(len(nodes)) {
+		t.Errorf("s1 counter %d, want %d", stats.SecondaryNodes["s1"], len(nodes))
+	}
+}
@@ -0,0 +1,178 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// newCoverageCounters returns empty coverage counters
+func newCoverageCounters() *types.CoverageCounters {
+	return &types.CoverageCounters{
+		Folders:  make(map[string][]int64),
+		Expanded: make(map[string][]int64),
+	}
+}
+
+// GetCoverageCounters returns the stored per-world, per-depth folder coverage counters
+func (db *DB) GetCoverageCounters() (*types.CoverageCounters, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var counters *types.CoverageCounters
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		counters, err = db.stats.GetCoverage(tx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[SpectraFS] failed to read coverage: %w", err)
+	}
+
+	if counters == nil {
+		counters = newCoverageCounters()
+	}
+	return counters, nil
+}
+
+// coverageTx runs mutate and keeps the coverage counters in step with it
+// The contribution of every affected folder (by ID) is removed before mutate and re-added from
+// the post-mutation state, so inserts, deletes and existence changes all reduce to the same bookkeeping.
+// Affected IDs must cover every folder whose existence, depth, or child set mutate can change.
+func (db *DB) coverageTx(tx *bbolt.Tx, affected []string, mutate func() error) error {
+	counters, err := db.stats.GetCoverage(tx)
+	if err != nil {
+		return err
+	}
+	if counters == nil {
+		// No counters yet (new database, or one created before coverage tracking): count everything once
+		if err := mutate(); err != nil {
+			return err
+		}
+		return db.rebuildCoverageTx(tx)
+	}
+
+	affected = uniqueIDs(affected)
+	if err := db.applyCoverageTx(tx, counters, affected, -1); err != nil {
+		return err
+	}
+	if err := mutate(); err != nil {
+		return err
+	}
+	if err := db.applyCoverageTx(tx, counters, affected, 1); err != nil {
+		return err
+	}
+	return db.stats.PutCoverage(tx, counters)
+}
+
+// applyCoverageTx adds (delta 1) or removes (delta -1) the current contribution of each folder in ids
+// IDs that are missing or are files contribute nothing
+func (db *DB) applyCoverageTx(tx *bbolt.Tx, counters *types.CoverageCounters, ids []string, delta int64) error {
+	for _, id := range ids {
+		node, err := db.nodes.Get(tx, id)
+		if err != nil {
+			return err
+		}
+		if node == nil || node.Type != types.NodeTypeFolder {
+			continue
+		}
+
+		expanded, err := db.index.HasChildren(tx, id)
+		if err != nil {
+			return err
+		}
+		addCoverage(counters, node, delta, expanded)
+	}
+	return nil
+}
+
+// rebuildCoverageTx recomputes the coverage counters from the nodes bucket
+func (db *DB) rebuildCoverageTx(tx *bbolt.Tx) error {
+	counters := newCoverageCounters()
+	err := db.nodes.ForEach(tx, func(node *types.Node, _ int64) error {
+		if node.Type != types.NodeTypeFolder {
+			return nil
+		}
+		expanded, err := db.index.HasChildren(tx, node.ID)
+		if err != nil {
+			return err
+		}
+		addCoverage(counters, node, 1, expanded)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return db.stats.PutCoverage(tx, counters)
+}
+
+// addCoverage applies delta to a folder's counters in every world it exists in
+func addCoverage(counters *types.CoverageCounters, folder *types.Node, delta int64, expanded bool) {
+	depth := folder.DepthLevel
+	if depth < 0 {
+		return
+	}
+
+	for world, exists := range folder.ExistenceMap {
+		if !exists {
+			continue
+		}
+		addAtDepth(counters.Folders, world, depth, delta)
+		if expanded {
+			addAtDepth(counters.Expanded, world, depth, delta)
+		}
+	}
+}
+
+// addAtDepth adds delta to counts[world][depth], growing the slice as needed and never going below zero
+// Trailing empty depths are dropped, and a world left with none is removed, so the counters match
+// a rebuild from the nodes bucket
+func addAtDepth(counts map[string][]int64, world string, depth int, delta int64) {
+	worldCounts := counts[world]
+	for len(worldCounts) <= depth {
+		worldCounts = append(worldCounts, 0)
+	}
+	worldCounts[depth] += delta
+	if worldCounts[depth] < 0 {
+		worldCounts[depth] = 0
+	}
+
+	for len(worldCounts) > 0 && worldCounts[len(worldCounts)-1] == 0 {
+		worldCounts = worldCounts[:len(worldCounts)-1]
+	}
+	if len(worldCounts) == 0 {
+		delete(counts, world)
+		return
+	}
+	counts[world] = worldCounts
+}
+
+// coverageAffected returns the IDs whose coverage a write to nodes can change: their parents and the folders themselves
+func coverageAffected(nodes []*types.Node) []string {
+	ids := make([]string, 0, len(nodes)+1)
+	for _, node := range nodes {
+		ids = append(ids, node.ParentID)
+		if node.Type == types.NodeTypeFolder {
+			ids = append(ids, node.ID)
+		}
+	}
+	return ids
+}
+
+// uniqueIDs drops duplicate and empty IDs, keeping the first occurrence
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]struct{}, len(ids))
+	unique := ids[:0:0]
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	return unique
+}
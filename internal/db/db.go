@@ -1,40 +1,163 @@
 package db
 
 import (
-	"encoding/json"
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/Project-Sylos/Spectra/internal/logging"
+	"github.com/Project-Sylos/Spectra/internal/metrics"
 	"github.com/Project-Sylos/Spectra/internal/types"
 	"github.com/Project-Sylos/Spectra/internal/utils"
 	"github.com/google/uuid"
 	"go.etcd.io/bbolt"
 )
 
-// DB wraps BoltDB connection and provides key-value CRUD operations
+// ErrNodeNotFound is returned when a node lookup by ID does not match any stored node
+var ErrNodeNotFound = NewError(ErrNotFound, "[SpectraFS] node not found")
+
+// ctxCheckInterval is the number of nodes a scan or bulk insert handles between cancellation checks
+const ctxCheckInterval = 1024
+
+// checkCtx returns ctx.Err() on every ctxCheckInterval-th iteration i, so long loops stay cancellable cheaply
+func checkCtx(ctx context.Context, i int) error {
+	if i%ctxCheckInterval != 0 {
+		return nil
+	}
+	return ctx.Err()
+}
+
+// DB is a thin facade over BoltDB that composes the bucket repositories
+// (nodes, indexes, stats, meta) behind the method set used by spectrafs.
+// Every writing method takes db.mu once and runs a single transaction via withTx/withViewTx;
+// the hot read-only lookups skip db.mu and rely on bbolt's MVCC, which gives each View its own
+// consistent snapshot alongside the single writer. The repositories only operate on the
+// transaction they are handed and never lock.
 type DB struct {
-	db              *bbolt.DB
-	secondaryTables []string   // List of secondary world names (e.g., ["s1", "s2"])
-	mu              sync.Mutex // Protects all database operations from concurrent access
+	db              atomic.Pointer[bbolt.DB] // Replaced only by Compact, which swaps in the rewritten file (see bolt)
+	options         Options                  // How the file was opened, reused when Compact reopens it
+	cleanup         func()                   // Removes an in-memory database's backing file after close (nil otherwise)
+	secondaryTables []string                 // List of secondary world names (e.g., ["s1", "s2"]); replaced, never mutated
+	worldsMu        sync.RWMutex             // Guards secondaryTables for lock-free readers (see AddWorld)
+	mu              sync.Mutex               // Serializes writers and the cache/pending/failpoint state they update
+	cache           *preloadCache            // Warm-start cache (nil when preload is off)
+	nodeCache       *nodeCache               // Hot node cache behind nodes (nil when Options.NodeCacheSize is 0)
+	readOnly        bool                     // Opened with NewReadOnly: every write returns ErrReadOnly
+	snapshot        bool                     // Read-only from an in-memory copy, since a writer held the file
+	unclean         bool                     // Opened without a clean-shutdown marker; cleared once Recover runs
+	checkOnClose    bool                     // Close records a clean shutdown only if a quick integrity check passes
+	compactRatio    float64                  // Close compacts first when free pages make up at least this share of the file (0 = never)
+	failpoint       insertFailpoint          // Generation failure hook (testing only)
+	pending         map[string]struct{}      // Parents with parked children from an injected failure
+	metrics         metrics.Recorder         // Receives transaction durations (nil = not recorded)
+	logger          *slog.Logger             // Logs transaction durations at debug level (see SetLogger)
+
+	nodes NodeRepo
+	index IndexRepo
+	stats StatsRepo
+	meta  MetaRepo
+}
+
+// Options tunes how the database file is opened and written (the db section of the config)
+type Options struct {
+	NoSync          bool   // Skip fsync on every commit; Close syncs once before closing
+	InitialMmapSize int    // Bytes to mmap on open (0 = bbolt's default)
+	FreelistType    string // types.FreelistArray ("" too) or types.FreelistMap
+	BulkBatchSize   int    // Nodes per transaction in BulkInsertNodes (0 = all in one)
+	NodeCacheSize   int    // Decoded nodes kept in the hot node cache (0 = no cache)
+}
+
+// boltOptions returns the bbolt options of a file-backed database opened with o
+func (o Options) boltOptions() *bbolt.Options {
+	freelist := bbolt.FreelistArrayType
+	if o.FreelistType == types.FreelistMap {
+		freelist = bbolt.FreelistMapType
+	}
+	return &bbolt.Options{
+		Timeout:         1 * time.Second,
+		NoSync:          o.NoSync,
+		InitialMmapSize: o.InitialMmapSize,
+		FreelistType:    freelist,
+	}
 }
 
 // New creates a new database connection and initializes the schema
-func New(dbPath string, secondaryTables map[string]float64) (*DB, error) {
-	// Check if database file exists
+func New(dbPath string, secondaryTables map[string]float64, opts Options) (*DB, error) {
+	// Check if database file exists (an in-memory database never does)
 	dbFileExists := false
-	if _, err := os.Stat(dbPath); err == nil {
+	if _, err := os.Stat(dbPath); err == nil && !IsMemoryPath(dbPath) {
 		dbFileExists = true
 	}
 
 	// Open BoltDB connection
-	boltDB, err := bbolt.Open(dbPath, 0600, &bbolt.Options{Timeout: 1 * time.Second})
+	options := opts.boltOptions()
+	var cleanup func()
+	if IsMemoryPath(dbPath) {
+		options, cleanup = memoryOptions(options)
+	}
+	boltDB, err := bbolt.Open(dbPath, 0600, options)
 	if err != nil {
+		if cleanup != nil {
+			cleanup()
+		}
 		return nil, fmt.Errorf("failed to open BoltDB connection: %w", err)
 	}
+	db := newDB(boltDB, cleanup, secondaryTables, opts)
+
+	// Verify and initialize database structure
+	if err := db.VerifyAndInitialize(dbFileExists, secondaryTables); err != nil {
+		db.closeBolt()
+		return nil, fmt.Errorf("failed to verify and initialize database: %w", err)
+	}
+
+	// Settle an AddWorld or RemoveWorld that a crash interrupted
+	if err := db.settleWorldOp(); err != nil {
+		db.closeBolt()
+		return nil, fmt.Errorf("failed to settle interrupted world operation: %w", err)
+	}
+
+	return db, nil
+}
+
+// NewReadOnly opens an existing database file without ever writing to it. bbolt takes a shared lock,
+// so any number of processes may open the file read-only at once; while a writer has it open, the
+// database is read from an in-memory copy taken on open instead, which does not see later commits
+// (see Snapshot). Every write returns ErrReadOnly, Close records no clean shutdown, and a file that
+// opening it writable would change first (an older schema, stats to backfill, a world operation to
+// settle) is refused, since it cannot be read as it is
+func NewReadOnly(dbPath string, secondaryTables map[string]float64, opts Options) (*DB, error) {
+	if IsMemoryPath(dbPath) {
+		return nil, fmt.Errorf("[SpectraFS] an in-memory database cannot be opened read-only")
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("[SpectraFS] a read-only database must exist: %w", err)
+	}
 
+	boltDB, cleanup, snapshot, err := openReadOnly(dbPath, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open BoltDB connection: %w", err)
+	}
+	db := newDB(boltDB, cleanup, secondaryTables, opts)
+	db.readOnly = true
+	db.snapshot = snapshot
+
+	if err := db.verifyReadOnly(); err != nil {
+		db.closeBolt()
+		return nil, fmt.Errorf("failed to verify database: %w", err)
+	}
+	return db, nil
+}
+
+// newDB wraps an open BoltDB connection
+func newDB(boltDB *bbolt.DB, cleanup func(), secondaryTables map[string]float64, opts Options) *DB {
 	// Create secondary tables list
 	secondaryList := make([]string, 0, len(secondaryTables))
 	for tableName := range secondaryTables {
@@ -42,24 +165,114 @@ func New(dbPath string, secondaryTables map[string]float64) (*DB, error) {
 	}
 
 	db := &DB{
-		db:              boltDB,
+		options:         opts,
+		cleanup:         cleanup,
 		secondaryTables: secondaryList,
+		logger:          logging.Discard,
+		nodes:           boltNodeRepo{},
+		index:           boltIndexRepo{},
+		stats:           boltStatsRepo{secondaryTables: secondaryList},
+		meta:            boltMetaRepo{},
+	}
+	if opts.NodeCacheSize > 0 {
+		db.nodeCache = newNodeCache(opts.NodeCacheSize)
+		db.nodes = cachedNodeRepo{NodeRepo: db.nodes, cache: db.nodeCache}
 	}
+	db.db.Store(boltDB)
+	return db
+}
 
-	// Verify and initialize database structure
-	if err := db.VerifyAndInitialize(dbFileExists, secondaryTables); err != nil {
-		boltDB.Close()
-		return nil, fmt.Errorf("failed to verify and initialize database: %w", err)
+// ReadOnly reports whether the database was opened with NewReadOnly
+func (db *DB) ReadOnly() bool {
+	return db.readOnly
+}
+
+// Snapshot reports whether the read-only database is a copy taken on open because a writer held the
+// file; it shows the file as it was then, not the writer's later commits
+func (db *DB) Snapshot() bool {
+	return db.snapshot
+}
+
+// withTx runs fn in one read-write transaction shared by every repository it touches
+// NOTE: This function assumes the caller already holds db.mu lock
+func (db *DB) withTx(fn func(tx *bbolt.Tx) error) error {
+	if db.readOnly {
+		return ErrReadOnly
+	}
+	if !db.observingTx() {
+		return db.bolt().Update(fn)
 	}
+	defer db.observeTx("update", time.Now())
+	return db.bolt().Update(fn)
+}
 
-	return db, nil
+// withViewTx runs fn in one read-only transaction
+// Lock-free readers may call it without db.mu; bbolt allows concurrent read transactions
+func (db *DB) withViewTx(fn func(tx *bbolt.Tx) error) error {
+	if !db.observingTx() {
+		return db.view(fn)
+	}
+	defer db.observeTx("view", time.Now())
+	return db.view(fn)
+}
+
+// bolt returns the open BoltDB connection
+func (db *DB) bolt() *bbolt.DB {
+	return db.db.Load()
+}
+
+// view runs fn in a read-only transaction on the current connection. A lock-free reader that
+// loaded the connection just before Compact swapped it finds it closed before fn runs, and retries
+// on the one that replaced it
+func (db *DB) view(fn func(tx *bbolt.Tx) error) error {
+	bolt := db.bolt()
+	err := bolt.View(fn)
+	if errors.Is(err, bbolt.ErrDatabaseNotOpen) && db.bolt() != bolt {
+		return db.bolt().View(fn)
+	}
+	return err
+}
+
+// observingTx reports whether transaction durations are recorded or logged, so they are only timed then
+func (db *DB) observingTx() bool {
+	return db.metrics != nil || db.logger.Enabled(context.Background(), slog.LevelDebug)
+}
+
+// observeTx records and logs the duration of a transaction of kind op that started at start
+func (db *DB) observeTx(op string, start time.Time) {
+	elapsed := time.Since(start)
+	if db.metrics != nil {
+		db.metrics.Observe(metrics.BoltTxDuration, elapsed.Seconds(), metrics.Label{Name: "op", Value: op})
+	}
+	db.logger.Debug("bolt transaction", "op", op, "duration", elapsed)
+}
+
+// SetMetrics sets the recorder of transaction durations (nil stops recording)
+// Call it before the database is shared; the recorder is read without locking
+func (db *DB) SetMetrics(recorder metrics.Recorder) {
+	db.metrics = recorder
+}
+
+// SetLogger sets the logger of transaction durations, logged at debug level (nil stops logging)
+// Call it before the database is shared; the logger is read without locking
+func (db *DB) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = logging.Discard
+	}
+	db.logger = logger
+	if db.cache != nil {
+		db.cache.setLogger(logger)
+	}
 }
 
 // VerifyAndInitialize performs comprehensive database verification and initialization
 // It checks each stage and creates what's missing:
 // A) Database file exists (checked before connection)
-// B) Buckets exist
+// B) Buckets exist (buckets added in later versions are created on open)
 // C) Root node exists
+// D) Stats exist
+// E) The previous run shut down cleanly (the marker is consumed; see Recover)
+// F) Parked children from an injected generation failure are tracked (see CompletePendingChildren)
 func (db *DB) VerifyAndInitialize(dbFileExists bool, secondaryTables map[string]float64) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
@@ -67,222 +280,311 @@ func (db *DB) VerifyAndInitialize(dbFileExists bool, secondaryTables map[string]
 	// B) Initialize or verify buckets exist
 	if !dbFileExists {
 		// New database - create all buckets
-		if err := InitializeBuckets(db.db); err != nil {
+		if err := InitializeBuckets(db.bolt()); err != nil {
 			return fmt.Errorf("failed to initialize buckets: %w", err)
 		}
 	} else {
 		// Existing database - verify buckets exist
-		if err := VerifyBucketsExist(db.db); err != nil {
+		if err := VerifyBucketsExist(db.bolt()); err != nil {
 			return fmt.Errorf("failed to verify buckets: %w", err)
 		}
+		if err := MigrateBuckets(db.bolt()); err != nil {
+			return fmt.Errorf("failed to migrate buckets: %w", err)
+		}
 	}
 
-	// C) Check if root node exists
-	rootExists, err := db.rootNodeExists()
-	if err != nil {
-		return fmt.Errorf("failed to check if root node exists: %w", err)
-	}
-
-	if !rootExists {
-		// Create root node
-		if err := db.createRootNodeInternal(); err != nil {
+	return db.withTx(func(tx *bbolt.Tx) error {
+		// C) Create the root node if it is missing
+		err := db.coverageTx(tx, []string{"root"}, func() error {
+			_, _, err := db.createRootTx(tx)
+			return err
+		})
+		if err != nil {
 			return fmt.Errorf("failed to create root node: %w", err)
 		}
-	}
 
-	// D) Initialize stats if needed
-	if err := db.initializeStats(); err != nil {
-		return fmt.Errorf("failed to initialize stats: %w", err)
-	}
-
-	return nil
-}
+		// D) Initialize stats if needed, backfilling counters added after the stats were first written
+		if err := db.stats.Init(tx); err != nil {
+			return fmt.Errorf("failed to initialize stats: %w", err)
+		}
+		if err := db.backfillStatsTx(tx); err != nil {
+			return fmt.Errorf("failed to backfill stats: %w", err)
+		}
 
-// rootNodeExists checks if the root node exists
-func (db *DB) rootNodeExists() (bool, error) {
-	var exists bool
-	err := db.db.View(func(tx *bbolt.Tx) error {
-		nodesBucket := tx.Bucket([]byte(bucketNodes))
-		if nodesBucket == nil {
-			return fmt.Errorf("nodes bucket does not exist")
+		// E) An existing file without the marker was not closed cleanly
+		clean, err := db.consumeCleanShutdownTx(tx)
+		if err != nil {
+			return fmt.Errorf("failed to read clean-shutdown marker: %w", err)
 		}
+		db.unclean = dbFileExists && !clean
 
-		rootData := nodesBucket.Get([]byte("root"))
-		exists = rootData != nil
-		return nil
+		// F) Remember parents left partially generated by an injected failure
+		return db.loadPendingTx(tx)
 	})
+}
 
-	if err != nil {
-		return false, err
+// verifyReadOnly is VerifyAndInitialize for NewReadOnly: it checks what VerifyAndInitialize and
+// settleWorldOp would otherwise create, migrate or settle, and loads the parked children, but
+// leaves the clean-shutdown marker in place and skips recovery (see Recover)
+func (db *DB) verifyReadOnly() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := VerifyBucketsExist(db.bolt()); err != nil {
+		return fmt.Errorf("failed to verify buckets: %w", err)
 	}
-	return exists, nil
+	return db.withViewTx(func(tx *bbolt.Tx) error {
+		if err := checkMigratedTx(tx); err != nil {
+			return err
+		}
+		root, err := db.nodes.Exists(tx, "root")
+		if err != nil {
+			return err
+		}
+		stats, err := db.stats.Get(tx)
+		if err != nil {
+			return err
+		}
+		pendingWorld, err := db.meta.Get(tx, metaPendingWorld)
+		if err != nil {
+			return err
+		}
+		switch {
+		case !root:
+			return fmt.Errorf("[SpectraFS] the root node is missing; open the database writable once")
+		case stats.TotalNodes > 0 && (len(stats.NodesPerDepth) == 0 || stats.PrimaryNodes == 0):
+			return fmt.Errorf("[SpectraFS] the stats predate per-depth counters; open the database writable once")
+		case pendingWorld != nil:
+			return fmt.Errorf("[SpectraFS] world operation on %q was interrupted; open the database writable once", pendingWorld)
+		}
+		return db.loadPendingTx(tx)
+	})
 }
 
-// createRootNodeInternal creates the root node (internal, assumes lock is held)
-func (db *DB) createRootNodeInternal() error {
+// createRootTx creates the root node with existence in all worlds if it does not exist yet
+// Returns the new root and its encoded size, or nil if the root already existed
+// The root is not counted in stats
+func (db *DB) createRootTx(tx *bbolt.Tx) (*types.Node, int64, error) {
+	exists, err := db.nodes.Exists(tx, "root")
+	if err != nil || exists {
+		return nil, 0, err
+	}
+
 	// Create existence map with all worlds
 	existenceMap := make(map[string]bool)
 	existenceMap["primary"] = true
-	for _, worldName := range db.secondaryTables {
+	for _, worldName := range db.worlds() {
 		existenceMap[worldName] = true
 	}
 
-	// Create root node
 	rootNode := &types.Node{
-		ID:           "root",
-		ParentID:     "",
-		Name:         "root",
-		Path:         "/",
-		ParentPath:   "",
-		Type:         types.NodeTypeFolder,
-		DepthLevel:   0,
-		Size:         0,
-		LastUpdated:  time.Now(),
-		Checksum:     nil,
-		ExistenceMap: existenceMap,
-	}
-
-	// Use InsertNode logic but within the existing transaction context
-	// Since lock is already held, we can't call InsertNode directly
-	return db.db.Update(func(tx *bbolt.Tx) error {
-		// Serialize node to JSON
-		nodeJSON, err := json.Marshal(rootNode)
-		if err != nil {
-			return fmt.Errorf("failed to marshal root node: %w", err)
-		}
+		ID:              "root",
+		ParentID:        "",
+		Name:            "root",
+		Path:            "/",
+		ParentPath:      "",
+		Type:            types.NodeTypeFolder,
+		DepthLevel:      0,
+		Size:            0,
+		LastUpdated:     time.Now(),
+		Checksum:        nil,
+		ExistenceMap:    existenceMap,
+		TraversalStatus: types.StatusPending,
+		CopyStatus:      types.CopyStatusPending,
+	}
 
-		// Store node in nodes bucket
-		nodesBucket := tx.Bucket([]byte(bucketNodes))
-		if nodesBucket == nil {
-			return fmt.Errorf("nodes bucket does not exist")
-		}
+	size, err := db.nodes.Put(tx, rootNode)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := db.index.Add(tx, rootNode); err != nil {
+		return nil, 0, err
+	}
 
-		if err := nodesBucket.Put([]byte("root"), nodeJSON); err != nil {
-			return fmt.Errorf("failed to create root node: %w", err)
-		}
+	return rootNode, size, nil
+}
 
-		// Update index_parent_id: key format "{parentID}|{nodeID}"
-		indexParentID := tx.Bucket([]byte(bucketIndexParentID))
-		if indexParentID != nil {
-			parentIDKey := fmt.Sprintf("%s|%s", rootNode.ParentID, rootNode.ID)
-			if err := indexParentID.Put([]byte(parentIDKey), []byte{}); err != nil {
-				return fmt.Errorf("failed to update parent_id index: %w", err)
-			}
-		}
+// Close records the clean-shutdown marker (unless the database is read-only) and closes the database connection
+// BoltDB is ACID compliant and automatically persists all changes; with Options.NoSync the file is synced once here
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
 
-		// Update index_path: key format "{path}" -> value "{nodeID}"
-		indexPath := tx.Bucket([]byte(bucketIndexPath))
-		if indexPath != nil {
-			if err := indexPath.Put([]byte(rootNode.Path), []byte(rootNode.ID)); err != nil {
-				return fmt.Errorf("failed to update path index: %w", err)
-			}
-		}
+	if !db.readOnly {
+		db.compactOnClose()
+	}
+	var markErr, syncErr error
+	if !db.readOnly && db.closeCheckPasses() {
+		markErr = db.markCleanShutdown()
+	}
+	if db.options.NoSync && !db.readOnly {
+		syncErr = db.bolt().Sync() // Commits skipped fsync, so flush them once before closing
+	}
+	if err := db.closeBolt(); err != nil {
+		return err
+	}
+	if syncErr != nil {
+		return fmt.Errorf("[SpectraFS] failed to sync database: %w", syncErr)
+	}
+	if markErr != nil {
+		return fmt.Errorf("[SpectraFS] failed to record clean shutdown: %w", markErr)
+	}
+	return nil
+}
 
-		// Update index_parent_path: key format "{parentPath}|{nodeID}"
-		indexParentPath := tx.Bucket([]byte(bucketIndexParentPath))
-		if indexParentPath != nil {
-			parentPathKey := fmt.Sprintf("%s|%s", rootNode.ParentPath, rootNode.ID)
-			if err := indexParentPath.Put([]byte(parentPathKey), []byte{}); err != nil {
-				return fmt.Errorf("failed to update parent_path index: %w", err)
+// closeBolt closes BoltDB and releases an in-memory database's backing file
+func (db *DB) closeBolt() error {
+	err := db.bolt().Close()
+	if db.cleanup != nil {
+		db.cleanup()
+	}
+	return err
+}
+
+// InsertNode inserts a new node into the nodes bucket, updates all indexes, and updates stats
+// Fails with ErrPathExists if a node is already stored at node.Path; the check runs in the insert's transaction
+func (db *DB) InsertNode(node *types.Node) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var nodeSize int64
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		return db.coverageTx(tx, coverageAffected([]*types.Node{node}), func() error {
+			if err := db.checkPathFreeTx(tx, node.Path); err != nil {
+				return err
 			}
-		}
 
-		return nil
+			var err error
+			if nodeSize, err = db.nodes.Put(tx, node); err != nil {
+				return err
+			}
+			if err := db.index.Add(tx, node); err != nil {
+				return err
+			}
+			return db.stats.Apply(tx, []*types.Node{node}, true)
+		})
 	})
-}
 
-// Close closes the database connection
-// BoltDB is ACID compliant and automatically persists all changes
-func (db *DB) Close() error {
-	return db.db.Close()
+	if err == nil && db.cache != nil {
+		db.cache.add(node, nodeSize)
+	}
+
+	return err
 }
 
-// InsertNode inserts a new node into the nodes bucket and updates all indexes
-func (db *DB) InsertNode(node *types.Node) error {
+// ReplaceFileNode stores a new file node, or replaces the content of the file already stored at its path
+// The stored file keeps its ID, existence and place in the tree; it takes node's content (ContentID,
+// defaulting to node.ID), size, checksum and LastUpdated, and its traversal and copy status are reset.
+// A folder at the path fails with ErrPathExists. The path check and the write share one transaction.
+// Returns the stored node and whether an existing file was replaced
+func (db *DB) ReplaceFileNode(node *types.Node) (*types.Node, bool, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	err := db.db.Update(func(tx *bbolt.Tx) error {
-		// Serialize node to JSON
-		nodeJSON, err := json.Marshal(node)
+	stored := node
+	replaced := false
+	var nodeSize int64
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		existingID, err := db.index.LookupPath(tx, node.Path)
 		if err != nil {
-			return fmt.Errorf("[SpectraFS] failed to marshal node %s: %w", node.ID, err)
-		}
-
-		// Store node in nodes bucket
-		nodesBucket := tx.Bucket([]byte(bucketNodes))
-		if nodesBucket == nil {
-			return fmt.Errorf("[SpectraFS] nodes bucket does not exist")
+			return err
 		}
-
-		if err := nodesBucket.Put([]byte(node.ID), nodeJSON); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to insert node %s: %w", node.ID, err)
+		if existingID == "" {
+			return db.coverageTx(tx, coverageAffected([]*types.Node{node}), func() error {
+				var err error
+				if nodeSize, err = db.nodes.Put(tx, node); err != nil {
+					return err
+				}
+				if err := db.index.Add(tx, node); err != nil {
+					return err
+				}
+				return db.stats.Apply(tx, []*types.Node{node}, true)
+			})
 		}
 
-		// Update index_parent_id: key format "{parentID}|{nodeID}"
-		indexParentID := tx.Bucket([]byte(bucketIndexParentID))
-		if indexParentID == nil {
-			return fmt.Errorf("[SpectraFS] index_parent_id bucket does not exist")
+		existing, err := db.nodes.Get(tx, existingID)
+		if err != nil {
+			return err
 		}
-		parentIDKey := fmt.Sprintf("%s|%s", node.ParentID, node.ID)
-		if err := indexParentID.Put([]byte(parentIDKey), []byte{}); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to update parent_id index for node %s: %w", node.ID, err)
+		if existing == nil || existing.Type != types.NodeTypeFile {
+			return fmt.Errorf("%w: %s", ErrPathExists, node.Path)
 		}
 
-		// Update index_path: key format "{path}" -> value "{nodeID}"
-		indexPath := tx.Bucket([]byte(bucketIndexPath))
-		if indexPath == nil {
-			return fmt.Errorf("[SpectraFS] index_path bucket does not exist")
-		}
-		if err := indexPath.Put([]byte(node.Path), []byte(node.ID)); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to update path index for node %s: %w", node.ID, err)
+		// Swap the old size for the new one in the stats
+		if err := db.stats.Apply(tx, []*types.Node{existing}, false); err != nil {
+			return err
 		}
-
-		// Update index_parent_path: key format "{parentPath}|{nodeID}"
-		indexParentPath := tx.Bucket([]byte(bucketIndexParentPath))
-		if indexParentPath == nil {
-			return fmt.Errorf("[SpectraFS] index_parent_path bucket does not exist")
+		existing.ContentID = node.ContentID
+		if existing.ContentID == "" {
+			existing.ContentID = node.ID
 		}
-		parentPathKey := fmt.Sprintf("%s|%s", node.ParentPath, node.ID)
-		if err := indexParentPath.Put([]byte(parentPathKey), []byte{}); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to update parent_path index for node %s: %w", node.ID, err)
+		existing.Size = node.Size
+		existing.Checksum = node.Checksum
+		existing.LastUpdated = node.LastUpdated
+		existing.TraversalStatus = types.StatusPending
+		existing.CopyStatus = types.CopyStatusPending
+		if err := db.stats.Apply(tx, []*types.Node{existing}, true); err != nil {
+			return err
 		}
 
+		if nodeSize, err = db.nodes.Put(tx, existing); err != nil {
+			return fmt.Errorf("[SpectraFS] failed to replace file %s: %w", existing.ID, err)
+		}
+		stored, replaced = existing, true
 		return nil
 	})
+	if err != nil {
+		return nil, false, err
+	}
 
-	// Update stats after successful insertion
-	if err == nil {
-		if err := db.updateStatsForNode(node, true); err != nil {
-			// Log error but don't fail the insertion
-			// Stats update failure shouldn't prevent node insertion
-			_ = err
+	if db.cache != nil {
+		if replaced {
+			db.cache.update(stored, nodeSize)
+		} else {
+			db.cache.add(stored, nodeSize)
 		}
 	}
+	return stored, replaced, nil
+}
 
-	return err
+// checkPathFreeTx fails with ErrPathExists if any node, in any world, is stored at path
+func (db *DB) checkPathFreeTx(tx *bbolt.Tx, path string) error {
+	existing, err := db.index.LookupPath(tx, path)
+	if err != nil {
+		return err
+	}
+	if existing != "" {
+		return fmt.Errorf("%w: %s", ErrPathExists, path)
+	}
+	return nil
+}
+
+// Ping checks in one read-only transaction that the database is open and holds the nodes bucket and
+// the root node; it is cheap enough for a readiness probe
+func (db *DB) Ping() error {
+	return db.withViewTx(func(tx *bbolt.Tx) error {
+		exists, err := db.nodes.Exists(tx, "root")
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: root", ErrNodeNotFound)
+		}
+		return nil
+	})
 }
 
 // GetNodeByID retrieves a node by its ID from the nodes bucket
 func (db *DB) GetNodeByID(id string) (*types.Node, error) {
-	db.mu.Lock()
-	defer db.mu.Unlock()
-
 	var node *types.Node
-	err := db.db.View(func(tx *bbolt.Tx) error {
-		nodesBucket := tx.Bucket([]byte(bucketNodes))
-		if nodesBucket == nil {
-			return fmt.Errorf("[SpectraFS] nodes bucket does not exist")
-		}
-
-		nodeData := nodesBucket.Get([]byte(id))
-		if nodeData == nil {
-			return fmt.Errorf("[SpectraFS] node not found: %s", id)
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		node, err = db.loadNodeTx(tx, id)
+		if err != nil {
+			return err
 		}
-
-		node = &types.Node{}
-		if err := json.Unmarshal(nodeData, node); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to unmarshal node %s: %w", id, err)
+		if node == nil {
+			return fmt.Errorf("%w: %s", ErrNodeNotFound, id)
 		}
 
 		return nil
@@ -295,191 +597,319 @@ func (db *DB) GetNodeByID(id string) (*types.Node, error) {
 	return node, nil
 }
 
-// GetChildrenByParentID retrieves all children of a parent node filtered by world
-func (db *DB) GetChildrenByParentID(parentID, world string) ([]*types.Node, error) {
-	db.mu.Lock()
-	defer db.mu.Unlock()
+// childrenInWorldTx loads every child of parentID that exists in world
+// Safe without db.mu: the cache takes its own lock
+func (db *DB) childrenInWorldTx(tx *bbolt.Tx, parentID, world string) ([]*types.Node, error) {
+	return db.childrenTx(tx, parentID, func(node *types.Node) bool { return node.ExistenceMap[world] })
+}
+
+// childrenTx loads every child of parentID that keep accepts
+func (db *DB) childrenTx(tx *bbolt.Tx, parentID string, keep func(*types.Node) bool) ([]*types.Node, error) {
+	// Use index_parent_id (or its warm cache) to find all children
+	childIDs, err := db.childIDsTx(tx, parentID)
+	if err != nil {
+		return nil, err
+	}
 
 	var children []*types.Node
-	err := db.db.View(func(tx *bbolt.Tx) error {
-		// Use index_parent_id bucket to find all children
-		indexParentID := tx.Bucket([]byte(bucketIndexParentID))
-		if indexParentID == nil {
-			return fmt.Errorf("[SpectraFS] index_parent_id bucket does not exist")
+	for _, nodeID := range childIDs {
+		node, err := db.loadNodeTx(tx, nodeID)
+		if err != nil {
+			return nil, err
+		}
+		if node == nil {
+			continue // Skip if node not found
 		}
 
-		nodesBucket := tx.Bucket([]byte(bucketNodes))
-		if nodesBucket == nil {
-			return fmt.Errorf("[SpectraFS] nodes bucket does not exist")
+		if keep(node) {
+			children = append(children, node)
 		}
+	}
+
+	return children, nil
+}
 
-		// Prefix to search for: "{parentID}|"
-		prefix := []byte(parentID + "|")
-		cursor := indexParentID.Cursor()
+// GetChildrenByParentID retrieves all children of a parent node filtered by world
+func (db *DB) GetChildrenByParentID(parentID, world string) ([]*types.Node, error) {
+	var children []*types.Node
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		children, err = db.childrenInWorldTx(tx, parentID, world)
+		return err
+	})
 
-		// Iterate over all keys with the parentID prefix
-		for key, _ := cursor.Seek(prefix); key != nil && len(key) > len(prefix) && string(key[:len(prefix)]) == string(prefix); key, _ = cursor.Next() {
-			// Extract nodeID from key: "{parentID}|{nodeID}"
-			nodeID := string(key[len(prefix):])
+	if err != nil {
+		return nil, fmt.Errorf("[SpectraFS] failed to query children of %s in world %s: %w", parentID, world, err)
+	}
 
-			// Get node from nodes bucket
-			nodeData := nodesBucket.Get([]byte(nodeID))
-			if nodeData == nil {
-				continue // Skip if node not found
-			}
+	// Sort by type, then name, then ID
+	SortNodes(children)
 
-			var node types.Node
-			if err := json.Unmarshal(nodeData, &node); err != nil {
-				return fmt.Errorf("[SpectraFS] failed to unmarshal node %s: %w", nodeID, err)
-			}
+	return children, nil
+}
 
-			// Filter by world - check existence map
-			if node.ExistenceMap[world] {
-				children = append(children, &node)
+// ScanNodes visits every node with an ID strictly after afterID in ID order until fn returns false
+// It runs on one read-only snapshot without db.mu and stops with ctx.Err() if ctx is cancelled
+func (db *DB) ScanNodes(ctx context.Context, afterID string, fn func(node *types.Node) (bool, error)) error {
+	scanned := 0
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		return db.nodes.Scan(tx, afterID, func(node *types.Node) (bool, error) {
+			if err := checkCtx(ctx, scanned); err != nil {
+				return false, err
 			}
+			scanned++
+			return fn(node)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("[SpectraFS] failed to scan nodes: %w", err)
+	}
+	return nil
+}
+
+// nodeScanBatchSize is the number of nodes ForEachNode reads per transaction unless told otherwise
+const nodeScanBatchSize = 1000
+
+// ForEachNode calls fn for every stored node that passes opts, stopping at the first error fn returns
+// Without a path prefix the nodes bucket is scanned in ID order; with one, index_path is seeked to the
+// prefix and nodes come in path order. Nodes are read opts.BatchSize at a time, each batch on its own
+// read-only snapshot released before fn sees it, so a slow fn never holds a transaction open; nodes
+// written between batches may or may not be visited. opts.Snapshot reads everything on one snapshot
+// instead, calling fn inside the transaction, so fn must be quick and must not write. Runs without
+// db.mu and stops with ctx.Err() if ctx is cancelled
+func (db *DB) ForEachNode(ctx context.Context, opts types.NodeScanOptions, fn func(node *types.Node) error) error {
+	keep := func(node *types.Node) bool {
+		return opts.World == "" || node.ExistenceMap[opts.World]
+	}
+
+	if opts.Snapshot {
+		var fnErr error
+		scanned := 0
+		err := db.withViewTx(func(tx *bbolt.Tx) error {
+			return db.scanNodesTx(tx, opts.PathPrefix, "", func(_ string, node *types.Node) (bool, error) {
+				if err := checkCtx(ctx, scanned); err != nil {
+					return false, err
+				}
+				scanned++
+				if keep(node) {
+					fnErr = fn(node)
+				}
+				return fnErr == nil, nil
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("[SpectraFS] failed to scan nodes: %w", err)
 		}
+		return fnErr
+	}
 
-		return nil
-	})
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = nodeScanBatchSize
+	}
+	after := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		batch := make([]*types.Node, 0, batchSize)
+		done := true
+		scanned := 0
+		err := db.withViewTx(func(tx *bbolt.Tx) error {
+			return db.scanNodesTx(tx, opts.PathPrefix, after, func(key string, node *types.Node) (bool, error) {
+				if err := checkCtx(ctx, scanned); err != nil {
+					return false, err
+				}
+				scanned++
+				after = key
+				if keep(node) {
+					batch = append(batch, node)
+				}
+				if len(batch) == batchSize {
+					done = false
+					return false, nil
+				}
+				return true, nil
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("[SpectraFS] failed to scan nodes: %w", err)
+		}
 
-	if err != nil {
-		return nil, fmt.Errorf("[SpectraFS] failed to query children of %s in world %s: %w", parentID, world, err)
+		for _, node := range batch {
+			if err := fn(node); err != nil {
+				return err
+			}
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// scanNodesTx visits the nodes after the key after ("" = from the start) until fn returns false, passing
+// each with its resume key: in ID order through the nodes bucket, or with a prefix in path order
+// through index_path (dangling index entries are skipped)
+func (db *DB) scanNodesTx(tx *bbolt.Tx, prefix, after string, fn func(key string, node *types.Node) (bool, error)) error {
+	if prefix == "" {
+		return db.nodes.Scan(tx, after, func(node *types.Node) (bool, error) {
+			return fn(node.ID, node)
+		})
 	}
 
-	// Sort by type, then name (folders first, then files)
-	sort.Slice(children, func(i, j int) bool {
-		if children[i].Type != children[j].Type {
-			return children[i].Type < children[j].Type // "folder" < "file"
+	return db.index.ScanPathPrefix(tx, prefix, after, func(path, id string) (bool, error) {
+		node, err := db.loadNodeTx(tx, id)
+		if err != nil {
+			return false, err
+		}
+		if node == nil {
+			return true, nil
 		}
-		return children[i].Name < children[j].Name
+		return fn(path, node)
+	})
+}
+
+// GetAllChildren retrieves every child of a parent node regardless of world, in listing order
+func (db *DB) GetAllChildren(parentID string) ([]*types.Node, error) {
+	var children []*types.Node
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		children, err = db.childrenTx(tx, parentID, func(*types.Node) bool { return true })
+		return err
 	})
 
+	if err != nil {
+		return nil, fmt.Errorf("[SpectraFS] failed to query children of %s: %w", parentID, err)
+	}
+
+	SortNodes(children)
 	return children, nil
 }
 
-// GetParentAndChildren retrieves parent and all its children in ONE optimized query
-// This is the key performance optimization for ListChildren operations
-func (db *DB) GetParentAndChildren(parentID, world string) ([]*types.Node, error) {
-	db.mu.Lock()
-	defer db.mu.Unlock()
+// Listing is a folder and its children in one world, read by GetListing in a single transaction
+type Listing struct {
+	Parent      *types.Node
+	Children    []*types.Node // Children in the world, sorted by SortNodes; nil unless Parent exists in the world
+	HasChildren bool          // Parent has children in some world, possibly none of them in this one
+	// ChildrenGenerated is set for a parent without children whose children were generated, so it
+	// must not be generated again: none came out, or all were deleted since
+	ChildrenGenerated bool
+	Filtered          int // Children in the world that the listing's keep function rejected
+}
 
-	var nodes []*types.Node
-	err := db.db.View(func(tx *bbolt.Tx) error {
-		nodesBucket := tx.Bucket([]byte(bucketNodes))
-		if nodesBucket == nil {
-			return fmt.Errorf("[SpectraFS] nodes bucket does not exist")
-		}
-
-		// Get parent node
-		parentData := nodesBucket.Get([]byte(parentID))
-		if parentData != nil {
-			var parent types.Node
-			if err := json.Unmarshal(parentData, &parent); err != nil {
-				return fmt.Errorf("[SpectraFS] failed to unmarshal parent node %s: %w", parentID, err)
+// GetListing reads a parent, identified by ID or else by path, and its children in world in one
+// read-only transaction. A parent looked up by path must exist in world; either lookup fails with
+// ErrNodeNotFound when nothing matches. Children are only read when the parent exists in world, and
+// a non-nil keep drops the children it rejects as they are loaded, counting them in Filtered
+func (db *DB) GetListing(id, path, world string, keep func(*types.Node) bool) (*Listing, error) {
+	listing := &Listing{}
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		byPath := id == ""
+		if byPath {
+			var err error
+			if id, err = db.index.LookupPath(tx, path); err != nil {
+				return err
 			}
-			// Filter by world
-			if parent.ExistenceMap[world] {
-				nodes = append(nodes, &parent)
+			if id == "" {
+				return fmt.Errorf("%w with path %s", ErrNodeNotFound, path)
 			}
 		}
 
-		// Get children using index_parent_id
-		indexParentID := tx.Bucket([]byte(bucketIndexParentID))
-		if indexParentID == nil {
-			return fmt.Errorf("[SpectraFS] index_parent_id bucket does not exist")
+		parent, err := db.loadNodeTx(tx, id)
+		if err != nil {
+			return err
+		}
+		switch {
+		case parent == nil && byPath:
+			return fmt.Errorf("%w with path %s", ErrNodeNotFound, path)
+		case parent == nil:
+			return fmt.Errorf("%w: %s", ErrNodeNotFound, id)
+		case !parent.ExistenceMap[world] && byPath:
+			return fmt.Errorf("%w with path %s in world %s", ErrNodeNotFound, path, world)
+		}
+		listing.Parent = parent
+		if !parent.ExistenceMap[world] {
+			return nil
 		}
 
-		// Prefix to search for: "{parentID}|"
-		prefix := []byte(parentID + "|")
-		cursor := indexParentID.Cursor()
-
-		// Iterate over all keys with the parentID prefix
-		for key, _ := cursor.Seek(prefix); key != nil && len(key) > len(prefix) && string(key[:len(prefix)]) == string(prefix); key, _ = cursor.Next() {
-			// Extract nodeID from key: "{parentID}|{nodeID}"
-			nodeID := string(key[len(prefix):])
-
-			// Get node from nodes bucket
-			nodeData := nodesBucket.Get([]byte(nodeID))
-			if nodeData == nil {
-				continue // Skip if node not found
+		listing.Filtered = 0
+		listing.Children, err = db.childrenTx(tx, id, func(node *types.Node) bool {
+			if !node.ExistenceMap[world] {
+				return false
 			}
-
-			var node types.Node
-			if err := json.Unmarshal(nodeData, &node); err != nil {
-				return fmt.Errorf("[SpectraFS] failed to unmarshal node %s: %w", nodeID, err)
+			if keep != nil && !keep(node) {
+				listing.Filtered++
+				return false
 			}
-
-			// Filter by world - check existence map
-			if node.ExistenceMap[world] {
-				nodes = append(nodes, &node)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		listing.HasChildren = len(listing.Children) > 0 || listing.Filtered > 0
+		if !listing.HasChildren {
+			if listing.HasChildren, err = db.index.HasChildren(tx, id); err != nil {
+				return err
 			}
 		}
+		if !listing.HasChildren {
+			listing.ChildrenGenerated, err = db.childrenGeneratedTx(tx, id)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		return nil
+	SortNodes(listing.Children)
+	return listing, nil
+}
+
+// GetParentAndChildren retrieves parent and all its children in ONE optimized query
+// This is the key performance optimization for ListChildren operations
+func (db *DB) GetParentAndChildren(parentID, world string) ([]*types.Node, error) {
+	var parent *types.Node
+	var children []*types.Node
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		if parent, err = db.loadNodeTx(tx, parentID); err != nil {
+			return err
+		}
+		children, err = db.childrenInWorldTx(tx, parentID, world)
+		return err
 	})
 
 	if err != nil {
 		return nil, fmt.Errorf("[SpectraFS] failed to query parent and children: %w", err)
 	}
 
-	// Sort: parent first (if exists), then children by type, name
-	sort.Slice(nodes, func(i, j int) bool {
-		// Parent should be first (id == parentID)
-		if nodes[i].ID == parentID && nodes[j].ID != parentID {
-			return true
-		}
-		if nodes[i].ID != parentID && nodes[j].ID == parentID {
-			return false
-		}
-		// Both are children or both are parent - sort by type, then name
-		if nodes[i].Type != nodes[j].Type {
-			return nodes[i].Type < nodes[j].Type // "folder" < "file"
-		}
-		return nodes[i].Name < nodes[j].Name
-	})
+	// Parent first (if it exists in the world), then children by type, name, ID
+	SortNodes(children)
+	nodes := make([]*types.Node, 0, len(children)+1)
+	if parent != nil && parent.ExistenceMap[world] {
+		nodes = append(nodes, parent)
+	}
 
-	return nodes, nil
+	return append(nodes, children...), nil
 }
 
 // CheckChildrenExist checks if a parent has any children in a specific world
 func (db *DB) CheckChildrenExist(parentID, world string) (bool, error) {
-	db.mu.Lock()
-	defer db.mu.Unlock()
-
 	var hasChildren bool
-	err := db.db.View(func(tx *bbolt.Tx) error {
-		// Use index_parent_id bucket to find children
-		indexParentID := tx.Bucket([]byte(bucketIndexParentID))
-		if indexParentID == nil {
-			return fmt.Errorf("[SpectraFS] index_parent_id bucket does not exist")
-		}
-
-		nodesBucket := tx.Bucket([]byte(bucketNodes))
-		if nodesBucket == nil {
-			return fmt.Errorf("[SpectraFS] nodes bucket does not exist")
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		// Use index_parent_id (or its warm cache) to find children
+		childIDs, err := db.childIDsTx(tx, parentID)
+		if err != nil {
+			return err
 		}
 
-		// Prefix to search for: "{parentID}|"
-		prefix := []byte(parentID + "|")
-		cursor := indexParentID.Cursor()
-
 		// Check if any child exists in the specified world
-		for key, _ := cursor.Seek(prefix); key != nil && len(key) > len(prefix) && string(key[:len(prefix)]) == string(prefix); key, _ = cursor.Next() {
-			// Extract nodeID from key: "{parentID}|{nodeID}"
-			nodeID := string(key[len(prefix):])
-
-			// Get node from nodes bucket
-			nodeData := nodesBucket.Get([]byte(nodeID))
-			if nodeData == nil {
-				continue // Skip if node not found
+		for _, nodeID := range childIDs {
+			node, err := db.loadNodeTx(tx, nodeID)
+			if err != nil || node == nil {
+				continue // Skip if node not found or unreadable
 			}
-
-			var node types.Node
-			if err := json.Unmarshal(nodeData, &node); err != nil {
-				continue // Skip on error
-			}
-
-			// Check if node exists in the specified world
 			if node.ExistenceMap[world] {
 				hasChildren = true
 				return nil // Found one, we can return early
@@ -501,158 +931,398 @@ func (db *DB) UpdateExistenceMap(id string, existenceMap map[string]bool) error
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	return db.db.Update(func(tx *bbolt.Tx) error {
-		nodesBucket := tx.Bucket([]byte(bucketNodes))
-		if nodesBucket == nil {
-			return fmt.Errorf("[SpectraFS] nodes bucket does not exist")
-		}
+	var node *types.Node
+	var nodeSize int64
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		return db.coverageTx(tx, []string{id}, func() error {
+			var err error
+			if node, err = db.nodes.Get(tx, id); err != nil {
+				return err
+			}
+			if node == nil {
+				return fmt.Errorf("%w: %s", ErrNodeNotFound, id)
+			}
 
-		// Get existing node
-		nodeData := nodesBucket.Get([]byte(id))
-		if nodeData == nil {
-			return fmt.Errorf("[SpectraFS] node %s not found", id)
-		}
+			// Move the node's per-world stats from its old existence map to the new one
+			if err := db.stats.Apply(tx, []*types.Node{node}, false); err != nil {
+				return err
+			}
+			node.ExistenceMap = existenceMap
+			if err := db.stats.Apply(tx, []*types.Node{node}, true); err != nil {
+				return err
+			}
+
+			if nodeSize, err = db.nodes.Put(tx, node); err != nil {
+				return fmt.Errorf("[SpectraFS] failed to update existence map for %s: %w", id, err)
+			}
+
+			return nil
+		})
+	})
 
-		var node types.Node
-		if err := json.Unmarshal(nodeData, &node); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to unmarshal node %s: %w", id, err)
+	if err == nil && db.cache != nil {
+		db.cache.update(node, nodeSize)
+	}
+
+	return err
+}
+
+// existenceBatchSize bounds how many nodes UpdateExistenceMaps rewrites per transaction
+const existenceBatchSize = 1000
+
+// ExistenceUpdate is one node's new existence map for UpdateExistenceMaps
+type ExistenceUpdate struct {
+	ID           string
+	ExistenceMap map[string]bool
+}
+
+// UpdateExistenceMaps is the bulk UpdateExistenceMap: updates are applied in order, in
+// transactions of at most existenceBatchSize nodes, moving each node's per-world stats and coverage
+// with it. Callers order the updates so that an interrupted run never leaves a node present under
+// an absent parent (children first when removing, parents first when adding). Returns the updated
+// nodes; on error, those of the batches already committed
+func (db *DB) UpdateExistenceMaps(updates []ExistenceUpdate) ([]*types.Node, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	updated := make([]*types.Node, 0, len(updates))
+	for start := 0; start < len(updates); start += existenceBatchSize {
+		batch := updates[start:min(start+existenceBatchSize, len(updates))]
+
+		ids := make([]string, len(batch))
+		for i, update := range batch {
+			ids[i] = update.ID
 		}
 
-		// Update existence map
-		node.ExistenceMap = existenceMap
+		nodes := make([]*types.Node, 0, len(batch))
+		sizes := make([]int64, 0, len(batch))
+		err := db.withTx(func(tx *bbolt.Tx) error {
+			return db.coverageTx(tx, ids, func() error {
+				for _, update := range batch {
+					node, err := db.nodes.Get(tx, update.ID)
+					if err != nil {
+						return err
+					}
+					if node == nil {
+						return fmt.Errorf("%w: %s", ErrNodeNotFound, update.ID)
+					}
+
+					if err := db.stats.Apply(tx, []*types.Node{node}, false); err != nil {
+						return err
+					}
+					node.ExistenceMap = update.ExistenceMap
+					if err := db.stats.Apply(tx, []*types.Node{node}, true); err != nil {
+						return err
+					}
 
-		// Serialize updated node
-		updatedNodeData, err := json.Marshal(node)
+					size, err := db.nodes.Put(tx, node)
+					if err != nil {
+						return fmt.Errorf("[SpectraFS] failed to update existence map for %s: %w", node.ID, err)
+					}
+					nodes = append(nodes, node)
+					sizes = append(sizes, size)
+				}
+				return nil
+			})
+		})
 		if err != nil {
-			return fmt.Errorf("[SpectraFS] failed to marshal node %s: %w", id, err)
+			return updated, err
 		}
 
-		// Store updated node
-		if err := nodesBucket.Put([]byte(id), updatedNodeData); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to update existence map for %s: %w", id, err)
+		db.cacheUpdates(nodes, sizes)
+		updated = append(updated, nodes...)
+	}
+
+	return updated, nil
+}
+
+// UpdateTraversalStatus sets a node's traversal status
+// status must be StatusPending, StatusSuccessful or StatusFailed; unknown IDs return ErrNodeNotFound
+func (db *DB) UpdateTraversalStatus(id, status string) error {
+	if !types.IsValidTraversalStatus(status) {
+		return fmt.Errorf("[SpectraFS] invalid traversal status %q", status)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var node *types.Node
+	var nodeSize int64
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		var err error
+		if node, err = db.nodes.Get(tx, id); err != nil {
+			return err
+		}
+		if node == nil {
+			return fmt.Errorf("%w: %s", ErrNodeNotFound, id)
 		}
 
+		node.TraversalStatus = status
+		if nodeSize, err = db.nodes.Put(tx, node); err != nil {
+			return fmt.Errorf("[SpectraFS] failed to update traversal status for %s: %w", id, err)
+		}
 		return nil
 	})
+
+	if err == nil && db.cache != nil {
+		db.cache.update(node, nodeSize)
+	}
+
+	return err
 }
 
-// DeleteAllNodes removes all nodes from the nodes bucket and all indexes (for Reset)
-func (db *DB) DeleteAllNodes() error {
+// TouchNode sets a node's LastUpdated and returns the updated node
+func (db *DB) TouchNode(id string, lastUpdated time.Time) (*types.Node, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	err := db.db.Update(func(tx *bbolt.Tx) error {
-		// Delete all nodes from nodes bucket
-		nodesBucket := tx.Bucket([]byte(bucketNodes))
-		if nodesBucket != nil {
-			cursor := nodesBucket.Cursor()
-			for key, _ := cursor.First(); key != nil; key, _ = cursor.Next() {
-				if err := nodesBucket.Delete(key); err != nil {
-					return fmt.Errorf("[SpectraFS] failed to delete node: %w", err)
-				}
-			}
+	var node *types.Node
+	var nodeSize int64
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		var err error
+		if node, err = db.nodes.Get(tx, id); err != nil {
+			return err
+		}
+		if node == nil {
+			return fmt.Errorf("%w: %s", ErrNodeNotFound, id)
 		}
 
-		// Delete all entries from index buckets
-		indexBuckets := []string{bucketIndexParentID, bucketIndexPath, bucketIndexParentPath}
-		for _, bucketName := range indexBuckets {
-			bucket := tx.Bucket([]byte(bucketName))
-			if bucket != nil {
-				cursor := bucket.Cursor()
-				for key, _ := cursor.First(); key != nil; key, _ = cursor.Next() {
-					if err := bucket.Delete(key); err != nil {
-						return fmt.Errorf("[SpectraFS] failed to delete from index %s: %w", bucketName, err)
-					}
-				}
+		node.LastUpdated = lastUpdated
+		if nodeSize, err = db.nodes.Put(tx, node); err != nil {
+			return fmt.Errorf("[SpectraFS] failed to update last_updated for %s: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if db.cache != nil {
+		db.cache.update(node, nodeSize)
+	}
+	return node, nil
+}
+
+// UpdateMetadata merges metadata into a node's Metadata and returns the updated node
+// Keys with an empty value are removed; with replace the node's metadata becomes exactly metadata
+func (db *DB) UpdateMetadata(id string, metadata map[string]string, replace bool) (*types.Node, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var node *types.Node
+	var nodeSize int64
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		var err error
+		if node, err = db.nodes.Get(tx, id); err != nil {
+			return err
+		}
+		if node == nil {
+			return fmt.Errorf("%w: %s", ErrNodeNotFound, id)
+		}
+
+		if replace || node.Metadata == nil {
+			node.Metadata = make(map[string]string, len(metadata))
+		}
+		for key, value := range metadata {
+			if value == "" {
+				delete(node.Metadata, key)
+			} else {
+				node.Metadata[key] = value
 			}
 		}
+		if len(node.Metadata) == 0 {
+			node.Metadata = nil
+		}
 
+		if nodeSize, err = db.nodes.Put(tx, node); err != nil {
+			return fmt.Errorf("[SpectraFS] failed to update metadata for %s: %w", id, err)
+		}
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	// Reset stats after successful deletion
-	if err == nil {
-		if err := db.resetStats(); err != nil {
-			// Log error but don't fail the reset
-			// Stats update failure shouldn't prevent reset
-			_ = err
-		}
+	if db.cache != nil {
+		db.cache.update(node, nodeSize)
 	}
+	return node, nil
+}
 
-	return err
+// ResetNodes removes all nodes and indexes, resets stats and recreates the root stamped with
+// rootTimestamp, all in one transaction, so no reader ever sees a database without a root (for Reset)
+// Parked children and the mutation log describe the removed nodes, so they are emptied too
+// The node, index and mutation buckets are dropped and recreated. Cancelling ctx between steps
+// rolls the whole transaction back
+func (db *DB) ResetNodes(ctx context.Context, rootTimestamp time.Time) error {
+	return db.resetNodes(ctx, rootTimestamp, func(tx *bbolt.Tx) error {
+		if err := db.nodes.Clear(tx); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := db.index.Clear(tx); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return db.clearMutationsTx(tx)
+	})
+}
+
+// DeleteAllNodesSlow does what ResetNodes does, but empties the node, index and mutation buckets
+// key by key instead of dropping them, for backends without a bucket drop. The transaction grows
+// with the tree; kept as the baseline BenchmarkResetNodes is measured against
+func (db *DB) DeleteAllNodesSlow(ctx context.Context, rootTimestamp time.Time) error {
+	return db.resetNodes(ctx, rootTimestamp, func(tx *bbolt.Tx) error {
+		if db.nodeCache != nil {
+			db.nodeCache.purge(tx.ID())
+		}
+		for _, name := range append([]string{bucketNodes, bucketMutations}, indexBuckets...) {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := emptyBucket(tx, name); err != nil {
+				return fmt.Errorf("[SpectraFS] failed to empty %s: %w", name, err)
+			}
+		}
+		return nil
+	})
 }
 
-// GetNodeCount returns the total number of nodes in a specific world
-func (db *DB) GetNodeCount(world string) (int, error) {
+// resetNodes runs a reset in one transaction, with clear emptying the node, index and mutation
+// buckets before the rest of the state is cleared and the root recreated
+func (db *DB) resetNodes(ctx context.Context, rootTimestamp time.Time, clear func(tx *bbolt.Tx) error) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	var count int
-	err := db.db.View(func(tx *bbolt.Tx) error {
-		nodesBucket := tx.Bucket([]byte(bucketNodes))
-		if nodesBucket == nil {
-			return fmt.Errorf("[SpectraFS] nodes bucket does not exist")
+	var rootNode *types.Node
+	var nodeSize int64
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := clear(tx); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := db.clearPendingTx(tx); err != nil {
+			return err
 		}
+		if err := db.clearChildrenGeneratedTx(tx); err != nil {
+			return err
+		}
+		if err := db.stats.Reset(tx); err != nil {
+			return err
+		}
+
+		return db.coverageTx(tx, []string{"root"}, func() error {
+			var err error
+			if rootNode, _, err = db.createRootTx(tx); err != nil {
+				return err
+			}
+			rootNode.LastUpdated = rootTimestamp
+			nodeSize, err = db.nodes.Put(tx, rootNode)
+			return err
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	db.pending = make(map[string]struct{})
+	if db.cache != nil {
+		db.cache.clear()
+		db.cache.add(rootNode, nodeSize)
+	}
+	return nil
+}
+
+// GetNodeCount returns the total number of nodes in a specific world, root included
+// It reads the per-world counters kept in the stats bucket instead of scanning the nodes
+func (db *DB) GetNodeCount(ctx context.Context, world string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	var counts map[string]int64
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		counts, err = db.worldCountsTx(tx)
+		return err
+	})
+
+	if err != nil {
+		return 0, fmt.Errorf("[SpectraFS] failed to get node count for world %s: %w", world, err)
+	}
+
+	return int(counts[world]), nil
+}
 
-		cursor := nodesBucket.Cursor()
-		for key, value := cursor.First(); key != nil; key, value = cursor.Next() {
-			var node types.Node
-			if err := json.Unmarshal(value, &node); err != nil {
-				continue // Skip on error
+// worldCountsTx returns the node count of primary and every secondary world, root included
+// The stats counters exclude the root, so it is added for each world its existence map names
+func (db *DB) worldCountsTx(tx *bbolt.Tx) (map[string]int64, error) {
+	stats, err := db.stats.Get(tx)
+	if err != nil {
+		return nil, err
+	}
+	root, err := db.nodes.Get(tx, "root")
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(stats.SecondaryNodes)+1)
+	counts["primary"] = stats.PrimaryNodes
+	for worldName, count := range stats.SecondaryNodes {
+		counts[worldName] = count
+	}
+	if root != nil {
+		for worldName := range counts {
+			if root.ExistenceMap[worldName] {
+				counts[worldName]++
 			}
+		}
+	}
+	return counts, nil
+}
 
-			// Count if node exists in the specified world
+// GetNodesInWorld returns every stored node that exists in a specific world, read on one
+// snapshot without db.mu
+func (db *DB) GetNodesInWorld(world string) ([]*types.Node, error) {
+	var nodes []*types.Node
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		return db.nodes.ForEach(tx, func(node *types.Node, _ int64) error {
 			if node.ExistenceMap[world] {
-				count++
+				nodes = append(nodes, node)
 			}
-		}
-
-		return nil
+			return nil
+		})
 	})
 
 	if err != nil {
-		return 0, fmt.Errorf("[SpectraFS] failed to get node count for world %s: %w", world, err)
+		return nil, fmt.Errorf("[SpectraFS] failed to list nodes in world %s: %w", world, err)
 	}
 
-	return count, nil
+	return nodes, nil
 }
 
 // GetTableInfo returns information about all worlds
-func (db *DB) GetTableInfo() ([]types.TableInfo, error) {
-	db.mu.Lock()
-	defer db.mu.Unlock()
-
-	var tables []types.TableInfo
-
-	// Get counts for all worlds in a single pass
-	worldCounts := make(map[string]int)
-	worldCounts["primary"] = 0
-	for _, worldName := range db.secondaryTables {
-		worldCounts[worldName] = 0
+// Row counts come from the per-world counters kept in the stats bucket instead of a scan
+func (db *DB) GetTableInfo(ctx context.Context) ([]types.TableInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	err := db.db.View(func(tx *bbolt.Tx) error {
-		nodesBucket := tx.Bucket([]byte(bucketNodes))
-		if nodesBucket == nil {
-			return fmt.Errorf("[SpectraFS] nodes bucket does not exist")
-		}
-
-		cursor := nodesBucket.Cursor()
-		for key, value := cursor.First(); key != nil; key, value = cursor.Next() {
-			var node types.Node
-			if err := json.Unmarshal(value, &node); err != nil {
-				continue // Skip on error
-			}
-
-			// Count node in each world it exists in
-			for world := range worldCounts {
-				if node.ExistenceMap[world] {
-					worldCounts[world]++
-				}
-			}
-		}
-
-		return nil
+	secondaryTables := db.worlds()
+	var counts map[string]int64
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		counts, err = db.worldCountsTx(tx)
+		return err
 	})
 
 	if err != nil {
@@ -660,17 +1330,17 @@ func (db *DB) GetTableInfo() ([]types.TableInfo, error) {
 	}
 
 	// Add primary world
-	tables = append(tables, types.TableInfo{
+	tables := []types.TableInfo{{
 		Name:      "primary",
-		RowCount:  worldCounts["primary"],
+		RowCount:  int(counts["primary"]),
 		TableType: "primary",
-	})
+	}}
 
 	// Add secondary worlds
-	for _, worldName := range db.secondaryTables {
+	for _, worldName := range secondaryTables {
 		tables = append(tables, types.TableInfo{
 			Name:      worldName,
-			RowCount:  worldCounts[worldName],
+			RowCount:  int(counts[worldName]),
 			TableType: "secondary",
 		})
 	}
@@ -679,32 +1349,18 @@ func (db *DB) GetTableInfo() ([]types.TableInfo, error) {
 }
 
 // CreateFolder creates a new folder node
+// It only reads the parent, so it runs without db.mu; the caller stores the node
 func (db *DB) CreateFolder(parentID, name string, depth int) (*types.Node, error) {
-	db.mu.Lock()
-	defer db.mu.Unlock()
-
-	// Generate UUID for the new folder
-	nodeID := uuid.New().String()
-
 	// Get parent node to determine path
-	var parentPath string
-	err := db.db.View(func(tx *bbolt.Tx) error {
-		nodesBucket := tx.Bucket([]byte(bucketNodes))
-		if nodesBucket == nil {
-			return fmt.Errorf("[SpectraFS] nodes bucket does not exist")
+	var parent *types.Node
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		if parent, err = db.nodes.Get(tx, parentID); err != nil {
+			return err
 		}
-
-		parentData := nodesBucket.Get([]byte(parentID))
-		if parentData == nil {
-			return fmt.Errorf("[SpectraFS] parent node not found: %s", parentID)
-		}
-
-		var parent types.Node
-		if err := json.Unmarshal(parentData, &parent); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to unmarshal parent node: %w", err)
+		if parent == nil {
+			return fmt.Errorf("%w: parent %s", ErrNodeNotFound, parentID)
 		}
-
-		parentPath = parent.Path
 		return nil
 	})
 
@@ -712,531 +1368,372 @@ func (db *DB) CreateFolder(parentID, name string, depth int) (*types.Node, error
 		return nil, fmt.Errorf("[SpectraFS] failed to get parent node: %w", err)
 	}
 
-	path := utils.JoinPath(parentPath, name)
-
 	folderNode := &types.Node{
-		ID:           nodeID,
-		ParentID:     parentID,
-		Name:         name,
-		Path:         path,
-		Type:         types.NodeTypeFolder,
-		DepthLevel:   depth,
-		Size:         0, // Folders have size 0
-		LastUpdated:  time.Now(),
-		Checksum:     nil, // Folders don't have checksums
-		ExistenceMap: make(map[string]bool),
+		ID:              uuid.New().String(),
+		ParentID:        parentID,
+		Name:            name,
+		Path:            utils.JoinPath(parent.Path, name),
+		Type:            types.NodeTypeFolder,
+		DepthLevel:      depth,
+		Size:            0, // Folders have size 0
+		LastUpdated:     time.Now(),
+		Checksum:        nil, // Folders don't have checksums
+		ExistenceMap:    make(map[string]bool),
+		TraversalStatus: types.StatusPending,
+		CopyStatus:      types.CopyStatusPending,
 	}
 
 	return folderNode, nil
 }
 
-// CreateRootNode creates a single root node with existence in all worlds
-// This function is idempotent - it will skip creating the node if it already exists
-func (db *DB) CreateRootNode() error {
-	db.mu.Lock()
-	defer db.mu.Unlock()
-
-	return db.db.Update(func(tx *bbolt.Tx) error {
-		nodesBucket := tx.Bucket([]byte(bucketNodes))
-		if nodesBucket == nil {
-			return fmt.Errorf("[SpectraFS] nodes bucket does not exist")
-		}
-
-		// Check if root already exists
-		rootData := nodesBucket.Get([]byte("root"))
-		if rootData != nil {
-			// Root already exists
-			return nil
-		}
-
-		// Create existence map with all worlds
-		existenceMap := make(map[string]bool)
-		existenceMap["primary"] = true
-		for _, worldName := range db.secondaryTables {
-			existenceMap[worldName] = true
-		}
-
-		// Create root node
-		rootNode := &types.Node{
-			ID:           "root",
-			ParentID:     "",
-			Name:         "root",
-			Path:         "/",
-			ParentPath:   "",
-			Type:         types.NodeTypeFolder,
-			DepthLevel:   0,
-			Size:         0,
-			LastUpdated:  time.Now(),
-			Checksum:     nil,
-			ExistenceMap: existenceMap,
-		}
-
-		// Serialize node to JSON
-		nodeJSON, err := json.Marshal(rootNode)
-		if err != nil {
-			return fmt.Errorf("[SpectraFS] failed to marshal root node: %w", err)
-		}
-
-		// Store node in nodes bucket
-		if err := nodesBucket.Put([]byte("root"), nodeJSON); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to create root node: %w", err)
-		}
-
-		// Update index_parent_id: key format "{parentID}|{nodeID}"
-		indexParentID := tx.Bucket([]byte(bucketIndexParentID))
-		if indexParentID != nil {
-			parentIDKey := fmt.Sprintf("%s|%s", rootNode.ParentID, rootNode.ID)
-			if err := indexParentID.Put([]byte(parentIDKey), []byte{}); err != nil {
-				return fmt.Errorf("[SpectraFS] failed to update parent_id index: %w", err)
-			}
-		}
-
-		// Update index_path: key format "{path}" -> value "{nodeID}"
-		indexPath := tx.Bucket([]byte(bucketIndexPath))
-		if indexPath != nil {
-			if err := indexPath.Put([]byte(rootNode.Path), []byte(rootNode.ID)); err != nil {
-				return fmt.Errorf("[SpectraFS] failed to update path index: %w", err)
-			}
-		}
-
-		// Update index_parent_path: key format "{parentPath}|{nodeID}"
-		indexParentPath := tx.Bucket([]byte(bucketIndexParentPath))
-		if indexParentPath != nil {
-			parentPathKey := fmt.Sprintf("%s|%s", rootNode.ParentPath, rootNode.ID)
-			if err := indexParentPath.Put([]byte(parentPathKey), []byte{}); err != nil {
-				return fmt.Errorf("[SpectraFS] failed to update parent_path index: %w", err)
-			}
-		}
-
-		return nil
-	})
-}
-
 // DeleteNode deletes a node from the nodes bucket and all indexes
 func (db *DB) DeleteNode(id string) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	var node types.Node
-	err := db.db.Update(func(tx *bbolt.Tx) error {
-		// First, get the node to retrieve its path and parent info for index cleanup
-		nodesBucket := tx.Bucket([]byte(bucketNodes))
-		if nodesBucket == nil {
-			return fmt.Errorf("[SpectraFS] nodes bucket does not exist")
-		}
-
-		nodeData := nodesBucket.Get([]byte(id))
-		if nodeData == nil {
-			return fmt.Errorf("[SpectraFS] node not found: %s", id)
-		}
-
-		if err := json.Unmarshal(nodeData, &node); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to unmarshal node %s: %w", id, err)
+	var node *types.Node
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		var err error
+		if node, err = db.nodes.Get(tx, id); err != nil {
+			return err
 		}
-
-		// Delete from nodes bucket
-		if err := nodesBucket.Delete([]byte(id)); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to delete node %s: %w", id, err)
+		if node == nil {
+			return fmt.Errorf("%w: %s", ErrNodeNotFound, id)
 		}
+		return db.deleteNodesTx(tx, []*types.Node{node})
+	})
 
-		// Delete from index_parent_id
-		indexParentID := tx.Bucket([]byte(bucketIndexParentID))
-		if indexParentID != nil {
-			parentIDKey := fmt.Sprintf("%s|%s", node.ParentID, node.ID)
-			if err := indexParentID.Delete([]byte(parentIDKey)); err != nil {
-				return fmt.Errorf("[SpectraFS] failed to delete from parent_id index: %w", err)
-			}
-		}
+	if err == nil && db.cache != nil {
+		db.cache.remove(node)
+	}
 
-		// Delete from index_path
-		indexPath := tx.Bucket([]byte(bucketIndexPath))
-		if indexPath != nil {
-			if err := indexPath.Delete([]byte(node.Path)); err != nil {
-				return fmt.Errorf("[SpectraFS] failed to delete from path index: %w", err)
-			}
-		}
+	return err
+}
 
-		// Delete from index_parent_path
-		indexParentPath := tx.Bucket([]byte(bucketIndexParentPath))
-		if indexParentPath != nil {
-			parentPathKey := fmt.Sprintf("%s|%s", node.ParentPath, node.ID)
-			if err := indexParentPath.Delete([]byte(parentPathKey)); err != nil {
-				return fmt.Errorf("[SpectraFS] failed to delete from parent_path index: %w", err)
-			}
-		}
+// deleteSubtreeBatchSize bounds how many nodes DeleteSubtree removes per transaction
+const deleteSubtreeBatchSize = 1000
 
-		return nil
+// HasChildren reports whether a node has any children, regardless of world
+func (db *DB) HasChildren(parentID string) (bool, error) {
+	var hasChildren bool
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		hasChildren, err = db.index.HasChildren(tx, parentID)
+		return err
 	})
 
-	// Update stats after successful deletion
-	if err == nil {
-		if err := db.updateStatsForNode(&node, false); err != nil {
-			// Log error but don't fail the deletion
-			// Stats update failure shouldn't prevent node deletion
-			_ = err
-		}
+	if err != nil {
+		return false, fmt.Errorf("[SpectraFS] failed to check children of %s: %w", parentID, err)
 	}
 
-	return err
-}
-
-// GetSecondaryTables returns the list of secondary world names
-func (db *DB) GetSecondaryTables() []string {
-	return db.secondaryTables
+	return hasChildren, nil
 }
 
-// initializeStats initializes the stats bucket with zero values
-func (db *DB) initializeStats() error {
+// DeleteSubtree deletes a node and every descendant from the nodes bucket and all indexes
+// Descendants are collected breadth-first via index_parent_id and removed deepest-first in
+// transactions of at most deleteSubtreeBatchSize nodes, so an interrupted delete never leaves orphans
+// Returns the deleted nodes
+func (db *DB) DeleteSubtree(id string) ([]*types.Node, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	return db.db.Update(func(tx *bbolt.Tx) error {
-		statsBucket := tx.Bucket([]byte(bucketStats))
-		if statsBucket == nil {
-			return fmt.Errorf("[SpectraFS] stats bucket does not exist")
-		}
-
-		// Check if stats already exist
-		statsData := statsBucket.Get([]byte("global"))
-		if statsData != nil {
-			// Stats already initialized
-			return nil
-		}
-
-		// Initialize with zero values
-		stats := &types.Stats{
-			FileCount:      0,
-			FolderCount:    0,
-			TotalFileSize:  0,
-			SecondaryNodes: make(map[string]int64),
-		}
+	// Collect the subtree in BFS order (parents before children)
+	var subtree []*types.Node
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		subtree, err = db.subtreeTx(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		// Initialize secondary nodes map for each secondary world
-		for _, worldName := range db.secondaryTables {
-			stats.SecondaryNodes[worldName] = 0
+	// Delete deepest-first in bounded batches
+	deleted := make([]*types.Node, 0, len(subtree))
+	for end := len(subtree); end > 0; end -= deleteSubtreeBatchSize {
+		start := end - deleteSubtreeBatchSize
+		if start < 0 {
+			start = 0
 		}
+		batch := subtree[start:end]
 
-		statsJSON, err := json.Marshal(stats)
+		err := db.withTx(func(tx *bbolt.Tx) error {
+			return db.deleteNodesTx(tx, batch)
+		})
 		if err != nil {
-			return fmt.Errorf("[SpectraFS] failed to marshal stats: %w", err)
+			return deleted, fmt.Errorf("[SpectraFS] failed to delete subtree of %s: %w", id, err)
 		}
-
-		if err := statsBucket.Put([]byte("global"), statsJSON); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to initialize stats: %w", err)
-		}
-
-		return nil
-	})
-}
-
-// updateStatsForNode increments stats for a newly inserted node
-// NOTE: This function assumes the caller already holds db.mu lock
-func (db *DB) updateStatsForNode(node *types.Node, increment bool) error {
-	return db.db.Update(func(tx *bbolt.Tx) error {
-		statsBucket := tx.Bucket([]byte(bucketStats))
-		if statsBucket == nil {
-			return fmt.Errorf("[SpectraFS] stats bucket does not exist")
-		}
-
-		// Get current stats
-		statsData := statsBucket.Get([]byte("global"))
-		if statsData == nil {
-			// Stats not initialized, initialize them
-			stats := &types.Stats{
-				FileCount:      0,
-				FolderCount:    0,
-				TotalFileSize:  0,
-				SecondaryNodes: make(map[string]int64),
+		if db.cache != nil {
+			for _, node := range batch {
+				db.cache.remove(node)
 			}
-			for _, worldName := range db.secondaryTables {
-				stats.SecondaryNodes[worldName] = 0
-			}
-			statsData, _ = json.Marshal(stats)
 		}
+		deleted = append(deleted, batch...)
+	}
 
-		var stats types.Stats
-		if err := json.Unmarshal(statsData, &stats); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to unmarshal stats: %w", err)
-		}
+	return deleted, nil
+}
 
-		// Ensure SecondaryNodes map is initialized
-		if stats.SecondaryNodes == nil {
-			stats.SecondaryNodes = make(map[string]int64)
-		}
+// subtreeTx loads a node and all of its descendants breadth-first via index_parent_id
+// (parents before children); dangling index entries are skipped
+func (db *DB) subtreeTx(tx *bbolt.Tx, id string) ([]*types.Node, error) {
+	root, err := db.nodes.Get(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, id)
+	}
 
-		// Update stats based on node type
-		delta := int64(1)
-		if !increment {
-			delta = -1
+	subtree := []*types.Node{root}
+	for i := 0; i < len(subtree); i++ {
+		childIDs, err := db.index.ChildIDs(tx, subtree[i].ID)
+		if err != nil {
+			return nil, err
 		}
-
-		switch node.Type {
-		case types.NodeTypeFile:
-			stats.FileCount += delta
-			if increment {
-				stats.TotalFileSize += node.Size
-			} else {
-				stats.TotalFileSize -= node.Size
-				if stats.TotalFileSize < 0 {
-					stats.TotalFileSize = 0
-				}
+		for _, childID := range childIDs {
+			child, err := db.nodes.Get(tx, childID)
+			if err != nil {
+				return nil, err
 			}
-		case types.NodeTypeFolder:
-			stats.FolderCount += delta
-		}
-
-		// Update secondary node counts for each world
-		for worldName := range stats.SecondaryNodes {
-			if node.ExistenceMap[worldName] {
-				stats.SecondaryNodes[worldName] += delta
-				if stats.SecondaryNodes[worldName] < 0 {
-					stats.SecondaryNodes[worldName] = 0
-				}
+			if child == nil {
+				continue // Skip dangling index entries
 			}
+			subtree = append(subtree, child)
 		}
+	}
+	return subtree, nil
+}
 
-		// Ensure all secondary worlds are in the map
-		for _, worldName := range db.secondaryTables {
-			if _, exists := stats.SecondaryNodes[worldName]; !exists {
-				stats.SecondaryNodes[worldName] = 0
+// deleteNodesTx removes nodes (last to first) with their index entries, stats and coverage inside a write transaction
+func (db *DB) deleteNodesTx(tx *bbolt.Tx, nodes []*types.Node) error {
+	return db.coverageTx(tx, coverageAffected(nodes), func() error {
+		for i := len(nodes) - 1; i >= 0; i-- {
+			if err := db.nodes.Delete(tx, nodes[i].ID); err != nil {
+				return err
+			}
+			if err := db.index.Remove(tx, nodes[i]); err != nil {
+				return err
 			}
 		}
-
-		// Save updated stats
-		updatedStatsJSON, err := json.Marshal(stats)
-		if err != nil {
-			return fmt.Errorf("[SpectraFS] failed to marshal updated stats: %w", err)
-		}
-
-		if err := statsBucket.Put([]byte("global"), updatedStatsJSON); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to update stats: %w", err)
+		if err := db.unmarkChildrenGeneratedTx(tx, nodes); err != nil {
+			return err
 		}
-
-		return nil
+		return db.stats.Apply(tx, nodes, false)
 	})
 }
 
+// GetSecondaryTables returns the list of secondary world names
+func (db *DB) GetSecondaryTables() []string {
+	return db.worlds()
+}
+
 // GetStats retrieves the current filesystem statistics
+// Safe without db.mu: the stats are read in a view and the caches take their own locks
 func (db *DB) GetStats() (*types.Stats, error) {
-	db.mu.Lock()
-	defer db.mu.Unlock()
-
 	var stats *types.Stats
-	err := db.db.View(func(tx *bbolt.Tx) error {
-		statsBucket := tx.Bucket([]byte(bucketStats))
-		if statsBucket == nil {
-			return fmt.Errorf("[SpectraFS] stats bucket does not exist")
-		}
-
-		statsData := statsBucket.Get([]byte("global"))
-		if statsData == nil {
-			// Stats not initialized, return zero stats
-			stats = &types.Stats{
-				FileCount:      0,
-				FolderCount:    0,
-				TotalFileSize:  0,
-				SecondaryNodes: make(map[string]int64),
-			}
-			// Initialize secondary nodes map
-			for _, worldName := range db.secondaryTables {
-				stats.SecondaryNodes[worldName] = 0
-			}
-			return nil
-		}
-
-		stats = &types.Stats{}
-		if err := json.Unmarshal(statsData, stats); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to unmarshal stats: %w", err)
-		}
-
-		// Ensure SecondaryNodes map is initialized
-		if stats.SecondaryNodes == nil {
-			stats.SecondaryNodes = make(map[string]int64)
-		}
-
-		// Ensure all secondary worlds are in the map
-		for _, worldName := range db.secondaryTables {
-			if _, exists := stats.SecondaryNodes[worldName]; !exists {
-				stats.SecondaryNodes[worldName] = 0
-			}
-		}
-
-		return nil
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		stats, err = db.stats.Get(tx)
+		return err
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
+	if db.cache != nil {
+		stats.Preload = db.cache.stats()
+	}
+	if db.nodeCache != nil {
+		stats.NodeCache = db.nodeCache.stats()
+	}
+
 	return stats, nil
 }
 
-// resetStats resets all stats to zero
-// NOTE: This function assumes the caller already holds db.mu lock
-func (db *DB) resetStats() error {
-	return db.db.Update(func(tx *bbolt.Tx) error {
-		statsBucket := tx.Bucket([]byte(bucketStats))
-		if statsBucket == nil {
-			return fmt.Errorf("[SpectraFS] stats bucket does not exist")
-		}
-
-		// Reset to zero values
-		stats := &types.Stats{
-			FileCount:      0,
-			FolderCount:    0,
-			TotalFileSize:  0,
-			SecondaryNodes: make(map[string]int64),
-		}
-
-		// Initialize secondary nodes map for each secondary world
-		for _, worldName := range db.secondaryTables {
-			stats.SecondaryNodes[worldName] = 0
-		}
-
-		statsJSON, err := json.Marshal(stats)
-		if err != nil {
-			return fmt.Errorf("[SpectraFS] failed to marshal stats: %w", err)
-		}
-
-		if err := statsBucket.Put([]byte("global"), statsJSON); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to reset stats: %w", err)
-		}
-
-		return nil
-	})
+// ErrChildrenExist is returned by InsertChildren when the parent already has children, or had
+// them generated before (even if none came out or all were deleted since)
+var ErrChildrenExist = NewError(ErrConflict, "[SpectraFS] parent already has children")
+
+// BulkInsertNodes inserts multiple nodes in a single BoltDB transaction, or with Options.BulkBatchSize
+// set in one transaction per that many nodes, in order
+// Nodes whose ID is already stored are skipped (INSERT OR IGNORE behavior)
+// If the generation failure hook is armed (see ArmInsertFailure) and fires part-way, the nodes
+// inserted so far are committed, the rest are parked for CompletePendingChildren, and
+// ErrInjectedFailure is returned. Cancelling ctx mid-insert rolls the current transaction back
+// (the whole slice unless it is split) and returns ctx.Err()
+func (db *DB) BulkInsertNodes(ctx context.Context, nodes []*types.Node) error {
+	return db.bulkInsert(ctx, "", nodes)
 }
 
-// Note: ParentInfo and GetParentInfo removed - replaced by GetParentAndChildren for better performance
+// InsertChildren inserts a folder's freshly generated children like BulkInsertNodes, but checks in
+// the same transaction that parentID has no children yet and was never generated, returning
+// ErrChildrenExist (storing nothing) otherwise, so a folder can never be given two generated child sets.
+// The parent is marked as generated in the same transaction (see Listing.ChildrenGenerated), even
+// when nodes is empty, so deleting all of its children later does not make it generate again.
+// A parent removed since the caller read it (by a delete or a reset) returns ErrNodeNotFound, so no
+// children are orphaned
+func (db *DB) InsertChildren(ctx context.Context, parentID string, nodes []*types.Node) error {
+	return db.bulkInsert(ctx, parentID, nodes)
+}
 
-// BulkInsertNodes inserts multiple nodes in a single BoltDB transaction
-func (db *DB) BulkInsertNodes(nodes []*types.Node) error {
+// bulkInsert implements BulkInsertNodes and, with a non-empty generatedParent, InsertChildren
+// InsertChildren's check covers the whole slice, so only BulkInsertNodes is split into batches
+func (db *DB) bulkInsert(ctx context.Context, generatedParent string, nodes []*types.Node) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	if len(nodes) == 0 {
-		return nil
+	if generatedParent != "" && len(nodes) == 0 {
+		_, err := db.insertBatch(ctx, generatedParent, nodes, 0, 0) // Records the empty generation
+		return err
 	}
 
-	// Track which nodes were actually inserted (not skipped)
-	insertedNodes := make([]*types.Node, 0, len(nodes))
-
-	err := db.db.Update(func(tx *bbolt.Tx) error {
-		nodesBucket := tx.Bucket([]byte(bucketNodes))
-		if nodesBucket == nil {
-			return fmt.Errorf("[SpectraFS] nodes bucket does not exist")
+	batch := len(nodes)
+	if generatedParent == "" && db.options.BulkBatchSize > 0 {
+		batch = db.options.BulkBatchSize
+	}
+	for start := 0; start < len(nodes); start += batch {
+		parked, err := db.insertBatch(ctx, generatedParent, nodes, start, min(start+batch, len(nodes)))
+		if err != nil {
+			return err
 		}
-
-		indexParentID := tx.Bucket([]byte(bucketIndexParentID))
-		if indexParentID == nil {
-			return fmt.Errorf("[SpectraFS] index_parent_id bucket does not exist")
+		if parked != nil {
+			return db.fireInsertFailure(parked)
 		}
+	}
+	return nil
+}
 
-		indexPath := tx.Bucket([]byte(bucketIndexPath))
-		if indexPath == nil {
-			return fmt.Errorf("[SpectraFS] index_path bucket does not exist")
-		}
+// insertBatch inserts nodes[start:end] in one transaction; if the failure hook fires, it parks
+// every node from there to the end of nodes (later batches included) and returns them
+// NOTE: This function assumes the caller already holds db.mu lock
+func (db *DB) insertBatch(ctx context.Context, generatedParent string, nodes []*types.Node, start, end int) ([]*types.Node, error) {
+	batch := nodes[start:end]
 
-		indexParentPath := tx.Bucket([]byte(bucketIndexParentPath))
-		if indexParentPath == nil {
-			return fmt.Errorf("[SpectraFS] index_parent_path bucket does not exist")
-		}
+	// Track which nodes were actually inserted (not skipped) and their encoded sizes
+	insertedNodes := make([]*types.Node, 0, len(batch))
+	insertedSizes := make([]int64, 0, len(batch))
+	var parked []*types.Node
 
-		// Insert all nodes
-		for _, node := range nodes {
-			// Check if node already exists (INSERT OR IGNORE behavior)
-			existingData := nodesBucket.Get([]byte(node.ID))
-			if existingData != nil {
-				continue // Skip if node already exists
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		if generatedParent != "" {
+			parentExists, err := db.nodes.Exists(tx, generatedParent)
+			if err != nil {
+				return err
 			}
-
-			// Serialize node to JSON
-			nodeJSON, err := json.Marshal(node)
+			if !parentExists {
+				return fmt.Errorf("%w: parent %s", ErrNodeNotFound, generatedParent)
+			}
+			hasChildren, err := db.index.HasChildren(tx, generatedParent)
 			if err != nil {
-				return fmt.Errorf("[SpectraFS] failed to marshal node %s: %w", node.ID, err)
+				return err
 			}
-
-			// Store node in nodes bucket
-			if err := nodesBucket.Put([]byte(node.ID), nodeJSON); err != nil {
-				return fmt.Errorf("[SpectraFS] failed to insert node %s: %w", node.ID, err)
+			if !hasChildren {
+				hasChildren, err = db.childrenGeneratedTx(tx, generatedParent)
+				if err != nil {
+					return err
+				}
 			}
-
-			// Update index_parent_id: key format "{parentID}|{nodeID}"
-			parentIDKey := fmt.Sprintf("%s|%s", node.ParentID, node.ID)
-			if err := indexParentID.Put([]byte(parentIDKey), []byte{}); err != nil {
-				return fmt.Errorf("[SpectraFS] failed to update parent_id index for node %s: %w", node.ID, err)
+			if hasChildren {
+				return fmt.Errorf("%w: %s", ErrChildrenExist, generatedParent)
 			}
-
-			// Update index_path: key format "{path}" -> value "{nodeID}"
-			if err := indexPath.Put([]byte(node.Path), []byte(node.ID)); err != nil {
-				return fmt.Errorf("[SpectraFS] failed to update path index for node %s: %w", node.ID, err)
+			if err := db.markChildrenGeneratedTx(tx, generatedParent); err != nil {
+				return err
 			}
+		}
 
-			// Update index_parent_path: key format "{parentPath}|{nodeID}"
-			parentPathKey := fmt.Sprintf("%s|%s", node.ParentPath, node.ID)
-			if err := indexParentPath.Put([]byte(parentPathKey), []byte{}); err != nil {
-				return fmt.Errorf("[SpectraFS] failed to update parent_path index for node %s: %w", node.ID, err)
-			}
+		return db.coverageTx(tx, coverageAffected(batch), func() error {
+			for i := start; i < end; i++ {
+				if err := checkCtx(ctx, i); err != nil {
+					return err
+				}
+				if db.insertFailureDue() {
+					parked = nodes[i:]
+					break
+				}
 
-			// Track this node as inserted
-			insertedNodes = append(insertedNodes, node)
-		}
+				inserted, size, err := db.insertNewNodeTx(tx, nodes[i])
+				if err != nil {
+					return err
+				}
+				if !inserted {
+					continue // Skip if node already exists
+				}
 
-		return nil
-	})
+				insertedNodes = append(insertedNodes, nodes[i])
+				insertedSizes = append(insertedSizes, size)
+				db.countInsertForFailure()
+			}
 
-	// Update stats after successful bulk insertion
-	if err == nil {
-		for _, node := range insertedNodes {
-			if err := db.updateStatsForNode(node, true); err != nil {
-				// Log error but don't fail the bulk insertion
-				// Stats update failure shouldn't prevent node insertion
-				_ = err
+			if err := db.stats.Apply(tx, insertedNodes, true); err != nil {
+				return err
 			}
+			if len(insertedNodes) > 0 {
+				if err := db.stats.MarkGenerated(tx, time.Now()); err != nil {
+					return err
+				}
+			}
+			return db.parkPendingTx(tx, parked)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if db.cache != nil {
+		for i, node := range insertedNodes {
+			db.cache.add(node, insertedSizes[i])
 		}
 	}
+	return parked, nil
+}
 
-	return err
+// insertNewNodeTx stores a node and its index entries unless a node with the same ID exists
+// A different node already stored at the path fails with ErrPathExists
+// Returns whether the node was inserted and its encoded size; stats are left to the caller
+func (db *DB) insertNewNodeTx(tx *bbolt.Tx, node *types.Node) (bool, int64, error) {
+	exists, err := db.nodes.Exists(tx, node.ID)
+	if err != nil || exists {
+		return false, 0, err
+	}
+	if err := db.checkPathFreeTx(tx, node.Path); err != nil {
+		return false, 0, err
+	}
+
+	size, err := db.nodes.Put(tx, node)
+	if err != nil {
+		return false, 0, err
+	}
+	if err := db.index.Add(tx, node); err != nil {
+		return false, 0, err
+	}
+	return true, size, nil
 }
 
 // GetNodeByPath retrieves a node by its path, optionally filtering by world
 func (db *DB) GetNodeByPath(path, world string) (*types.Node, error) {
-	db.mu.Lock()
-	defer db.mu.Unlock()
-
 	var node *types.Node
-	err := db.db.View(func(tx *bbolt.Tx) error {
-		// Use index_path bucket to get nodeID from path
-		indexPath := tx.Bucket([]byte(bucketIndexPath))
-		if indexPath == nil {
-			return fmt.Errorf("[SpectraFS] index_path bucket does not exist")
-		}
-
-		nodeIDBytes := indexPath.Get([]byte(path))
-		if nodeIDBytes == nil {
-			return fmt.Errorf("[SpectraFS] node not found with path %s", path)
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		// Use index_path to get the node ID from the path
+		nodeID, err := db.index.LookupPath(tx, path)
+		if err != nil {
+			return err
 		}
-
-		nodeID := string(nodeIDBytes)
-
-		// Get node from nodes bucket
-		nodesBucket := tx.Bucket([]byte(bucketNodes))
-		if nodesBucket == nil {
-			return fmt.Errorf("[SpectraFS] nodes bucket does not exist")
+		if nodeID == "" {
+			return fmt.Errorf("%w with path %s", ErrNodeNotFound, path)
 		}
 
-		nodeData := nodesBucket.Get([]byte(nodeID))
-		if nodeData == nil {
-			return fmt.Errorf("[SpectraFS] node not found with path %s", path)
+		if node, err = db.loadNodeTx(tx, nodeID); err != nil {
+			return err
 		}
-
-		node = &types.Node{}
-		if err := json.Unmarshal(nodeData, node); err != nil {
-			return fmt.Errorf("[SpectraFS] failed to unmarshal node %s: %w", nodeID, err)
+		if node == nil {
+			return fmt.Errorf("%w with path %s", ErrNodeNotFound, path)
 		}
 
 		// Filter by world if specified
 		if world != "" && !node.ExistenceMap[world] {
-			return fmt.Errorf("[SpectraFS] node not found with path %s in world %s", path, world)
+			return fmt.Errorf("%w with path %s in world %s", ErrNodeNotFound, path, world)
 		}
 
 		return nil
@@ -1248,3 +1745,87 @@ func (db *DB) GetNodeByPath(path, world string) (*types.Node, error) {
 
 	return node, nil
 }
+
+// GetMeta returns the value stored under key in the meta bucket, or nil if there is none
+// Runs without db.mu, like the other read-only lookups
+func (db *DB) GetMeta(key string) ([]byte, error) {
+	var value []byte
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		value, err = db.meta.Get(tx, key)
+		return err
+	})
+
+	return value, err
+}
+
+// PutMeta stores value under key in the meta bucket
+func (db *DB) PutMeta(key string, value []byte) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.withTx(func(tx *bbolt.Tx) error {
+		return db.meta.Put(tx, key, value)
+	})
+}
+
+// DeleteMeta removes key from the meta bucket
+func (db *DB) DeleteMeta(key string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.withTx(func(tx *bbolt.Tx) error {
+		return db.meta.Delete(tx, key)
+	})
+}
+
+// nodeTypeRank gives the position of each node type in child listings: folders, then files, then
+// symlinks. Unknown types sort after all of them
+var nodeTypeRank = map[string]int{
+	types.NodeTypeFolder:  0,
+	types.NodeTypeFile:    1,
+	types.NodeTypeSymlink: 2,
+}
+
+// CompareNodeTypes orders node types folders first, then files, then symlinks, then any other type
+// by its string. The types are ranked explicitly rather than compared as strings, which would put
+// "file" before "folder"
+func CompareNodeTypes(a, b string) int {
+	rankA, knownA := nodeTypeRank[a]
+	rankB, knownB := nodeTypeRank[b]
+	switch {
+	case knownA && knownB:
+		return cmp.Compare(rankA, rankB)
+	case knownA:
+		return -1
+	case knownB:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// CompareNodeKeys orders children by type (see CompareNodeTypes), then by name as a byte-wise
+// string comparison, then by ID, the tiebreak for siblings that share a name. This is the single
+// ordering used for child listings and keyset pagination cursors; the fs.FS wrapper re-sorts its
+// ReadDir results lexically by name, as fs.ReadDirFS requires
+func CompareNodeKeys(typeA, nameA, idA, typeB, nameB, idB string) int {
+	if c := CompareNodeTypes(typeA, typeB); c != 0 {
+		return c
+	}
+	if c := strings.Compare(nameA, nameB); c != 0 {
+		return c
+	}
+	return strings.Compare(idA, idB)
+}
+
+// CompareNodes compares two nodes using CompareNodeKeys
+func CompareNodes(a, b *types.Node) int {
+	return CompareNodeKeys(a.Type, a.Name, a.ID, b.Type, b.Name, b.ID)
+}
+
+// SortNodes sorts nodes in place using CompareNodes
+func SortNodes(nodes []*types.Node) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return CompareNodes(nodes[i], nodes[j]) < 0
+	})
+}
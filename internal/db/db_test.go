@@ -0,0 +1,53 @@
+package db
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestCompareNodeTypes(t *testing.T) {
+	// As strings "file" < "folder"; the ranking must put folders first anyway
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{types.NodeTypeFolder, types.NodeTypeFile, -1},
+		{types.NodeTypeFile, types.NodeTypeFolder, 1},
+		{types.NodeTypeFile, types.NodeTypeSymlink, -1},
+		{types.NodeTypeFolder, types.NodeTypeSymlink, -1},
+		{types.NodeTypeSymlink, "device", -1},
+		{"device", "pipe", -1},
+		{types.NodeTypeFile, types.NodeTypeFile, 0},
+	} {
+		if got := CompareNodeTypes(tc.a, tc.b); got != tc.want {
+			t.Errorf("CompareNodeTypes(%s, %s) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestSortNodes(t *testing.T) {
+	node := func(nodeType, name, id string) *types.Node {
+		return &types.Node{ID: id, Name: name, Type: nodeType}
+	}
+	nodes := []*types.Node{
+		node(types.NodeTypeSymlink, "a-link", "1"),
+		node(types.NodeTypeFile, "a.txt", "2"),
+		node(types.NodeTypeFolder, "z", "3"),
+		node(types.NodeTypeFile, "B.txt", "4"),
+		node(types.NodeTypeFolder, "dup", "6"),
+		node(types.NodeTypeFolder, "dup", "5"),
+		node(types.NodeTypeFolder, "a", "7"),
+	}
+	SortNodes(nodes)
+
+	var got []string
+	for _, n := range nodes {
+		got = append(got, n.ID)
+	}
+	// Folders by name with duplicate names by ID, then files byte-wise (upper case first), then symlinks
+	if want := []string{"7", "5", "6", "3", "4", "2", "1"}; !slices.Equal(got, want) {
+		t.Errorf("sorted IDs %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// ErrUnknownBucket is returned when a debug scan names a bucket that does not exist
+var ErrUnknownBucket = NewError(ErrNotFound, "[SpectraFS] unknown bucket")
+
+// Limits for raw bucket scans
+const (
+	DefaultBucketScanLimit = 100
+	MaxBucketScanLimit     = 1000
+	MaxDebugValueBytes     = 4096 // Larger values are returned hex-encoded and cut to this size
+)
+
+// ListBuckets returns every top-level bucket with its key count, sorted by name
+func (db *DB) ListBuckets() ([]types.BucketInfo, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	buckets := make([]types.BucketInfo, 0)
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		return tx.ForEach(func(name []byte, bucket *bbolt.Bucket) error {
+			buckets = append(buckets, types.BucketInfo{
+				Name:     string(name),
+				KeyCount: int64(bucket.Stats().KeyN),
+			})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Name < buckets[j].Name })
+	return buckets, nil
+}
+
+// ScanBucket returns up to limit raw entries from the named bucket whose keys start with prefix,
+// beginning after the key after (empty starts at the first match)
+// limit <= 0 uses DefaultBucketScanLimit and is capped at MaxBucketScanLimit
+func (db *DB) ScanBucket(name, prefix, after string, limit int) (*types.BucketPage, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if limit <= 0 {
+		limit = DefaultBucketScanLimit
+	}
+	if limit > MaxBucketScanLimit {
+		limit = MaxBucketScanLimit
+	}
+
+	page := &types.BucketPage{
+		Bucket:  name,
+		Prefix:  prefix,
+		Entries: make([]types.BucketEntry, 0),
+	}
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(name))
+		if bucket == nil {
+			return fmt.Errorf("%w: %s", ErrUnknownBucket, name)
+		}
+
+		cursor := bucket.Cursor()
+		key, value := cursor.Seek([]byte(prefix))
+		if after != "" && after >= prefix {
+			key, value = cursor.Seek([]byte(after))
+			if key != nil && string(key) == after {
+				key, value = cursor.Next()
+			}
+		}
+
+		for ; key != nil && bytes.HasPrefix(key, []byte(prefix)); key, value = cursor.Next() {
+			if len(page.Entries) == limit {
+				page.NextAfter = page.Entries[limit-1].Key
+				break
+			}
+			page.Entries = append(page.Entries, bucketEntry(name, key, value))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// bucketEntry decodes a raw key/value pair, keeping JSON values readable; binary node records are
+// shown as the JSON of the node they hold
+func bucketEntry(bucket string, key, value []byte) types.BucketEntry {
+	entry := types.BucketEntry{
+		Key:  string(key),
+		Size: len(value),
+	}
+
+	switch {
+	case value == nil:
+		// Nested bucket; there is no value to show
+	case bucket == bucketNodes && len(value) > 0 && value[0] == nodeEncodingBinary:
+		if node, err := decodeNode(value); err == nil {
+			if data, err := json.Marshal(node); err == nil {
+				entry.Value = data
+				break
+			}
+		}
+		entry.ValueHex = hex.EncodeToString(value[:min(len(value), MaxDebugValueBytes)])
+		entry.Truncated = len(value) > MaxDebugValueBytes
+	case len(value) > MaxDebugValueBytes:
+		entry.ValueHex = hex.EncodeToString(value[:MaxDebugValueBytes])
+		entry.Truncated = true
+	case json.Valid(value):
+		entry.Value = append(json.RawMessage(nil), value...)
+	case utf8.Valid(value):
+		entry.ValueText = string(value)
+	default:
+		entry.ValueHex = hex.EncodeToString(value)
+	}
+	return entry
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.etcd.io/bbolt"
+)
+
+// putMeta stores raw meta records for the bucket scan tests
+func putMeta(tb testing.TB, database *DB, records map[string][]byte) {
+	tb.Helper()
+	update(tb, database, func(tx *bbolt.Tx) error {
+		for key, value := range records {
+			if err := database.meta.Put(tx, key, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func TestScanBucketPrefixAndPaging(t *testing.T) {
+	database := newTestDB(t, Options{})
+	records := map[string][]byte{"debug/x": []byte("outside")}
+	for i := range 5 {
+		records[fmt.Sprintf("debug-test/%d", i)] = []byte(fmt.Sprintf(`{"n": %d}`, i))
+	}
+	putMeta(t, database, records)
+
+	var keys []string
+	after := ""
+	for pages := 0; ; pages++ {
+		page, err := database.ScanBucket(bucketMeta, "debug-test/", after, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Entries) > 2 || pages > 3 {
+			t.Fatalf("page %d holds %d entries", pages, len(page.Entries))
+		}
+		for _, entry := range page.Entries {
+			keys = append(keys, entry.Key)
+			if string(entry.Value) != string(records[entry.Key]) {
+				t.Errorf("%s: value %s, want the stored JSON", entry.Key, entry.Value)
+			}
+		}
+		if page.NextAfter == "" {
+			break
+		}
+		after = page.NextAfter
+	}
+	if want := "debug-test/0 debug-test/1 debug-test/2 debug-test/3 debug-test/4"; strings.Join(keys, " ") != want {
+		t.Errorf("scanned %v, want %s", keys, want)
+	}
+}
+
+func TestScanBucketValueCap(t *testing.T) {
+	database := newTestDB(t, Options{})
+	large := []byte(strings.Repeat("x", MaxDebugValueBytes+1))
+	putMeta(t, database, map[string][]byte{
+		"debug-test/large": large,
+		"debug-test/text":  []byte("plain text"),
+		"debug-test/bin":   {0xff, 0x00},
+	})
+
+	page, err := database.ScanBucket(bucketMeta, "debug-test/", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := make(map[string]int)
+	for i, entry := range page.Entries {
+		entries[entry.Key] = i
+	}
+
+	big := page.Entries[entries["debug-test/large"]]
+	if !big.Truncated || big.Size != len(large) || len(big.ValueHex) != 2*MaxDebugValueBytes || big.Value != nil {
+		t.Errorf("large value: truncated %v, size %d, %d hex digits", big.Truncated, big.Size, len(big.ValueHex))
+	}
+	if text := page.Entries[entries["debug-test/text"]]; text.ValueText != "plain text" || text.Truncated {
+		t.Errorf("text value = %+v", text)
+	}
+	if bin := page.Entries[entries["debug-test/bin"]]; bin.ValueHex != "ff00" {
+		t.Errorf("binary value = %+v", bin)
+	}
+}
+
+func TestScanBucketDecodesNodesAndRejectsUnknownBuckets(t *testing.T) {
+	database := newTestDB(t, Options{})
+	page, err := database.ScanBucket(bucketNodes, "root", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Entries) != 1 || !strings.Contains(string(page.Entries[0].Value), `"path":"/"`) {
+		t.Errorf("root record = %+v, want its JSON", page.Entries)
+	}
+
+	if _, err := database.ScanBucket("nope", "", "", 0); !errors.Is(err, ErrUnknownBucket) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown bucket error = %v", err)
+	}
+
+	buckets, err := database.ListBuckets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bucket := range buckets {
+		if bucket.Name == bucketNodes && bucket.KeyCount != 1 {
+			t.Errorf("nodes bucket lists %d keys, want the root", bucket.KeyCount)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package db
+
+import "errors"
+
+// Error categories: every sentinel of the db and spectrafs layers that callers can act on matches
+// one of these with errors.Is, so the API can map failures to status codes without listing them all
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrConflict     = errors.New("conflict")
+)
+
+// ErrReadOnly is returned by every write to a database opened with NewReadOnly; like the categories
+// it is matched with errors.Is, and it stands on its own rather than belonging to one
+var ErrReadOnly = errors.New("[SpectraFS] database is read-only")
+
+// classifiedError is a sentinel that errors.Is also matches against its category
+type classifiedError struct {
+	message  string
+	category error
+}
+
+// Error returns the sentinel's message
+func (e *classifiedError) Error() string {
+	return e.message
+}
+
+// Is reports whether target is the sentinel's category
+func (e *classifiedError) Is(target error) bool {
+	return target == e.category
+}
+
+// NewError returns a sentinel error with message that also matches category
+// (ErrNotFound, ErrInvalidInput or ErrConflict) under errors.Is
+func NewError(category error, message string) error {
+	return &classifiedError{message: message, category: category}
+}
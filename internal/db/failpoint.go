@@ -0,0 +1,198 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// ErrInjectedFailure is returned by BulkInsertNodes when the generation failure hook fires
+var ErrInjectedFailure = errors.New("[SpectraFS] injected generation failure")
+
+// metaPendingPrefix prefixes the meta keys holding children parked by an injected failure
+// (one JSON []*types.Node per parent, keyed by parent ID)
+const metaPendingPrefix = "generation_pending/"
+
+// insertFailpoint is the generation failure hook: once armed, BulkInsertNodes fails after
+// exactly remaining more nodes have been inserted, then disarms itself
+type insertFailpoint struct {
+	armed     bool
+	remaining int64
+	after     int64 // Budget the hook was armed with, reported in the error
+}
+
+// ArmInsertFailure makes generation fail after exactly afterNodes more inserted nodes
+// The hook fires once and then disarms; afterNodes <= 0 disarms it immediately
+func (db *DB) ArmInsertFailure(afterNodes int64) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if afterNodes <= 0 {
+		db.failpoint = insertFailpoint{}
+		return
+	}
+	db.failpoint = insertFailpoint{armed: true, remaining: afterNodes, after: afterNodes}
+}
+
+// InsertFailureArmed reports whether the hook is armed and how many inserts remain before it fires
+func (db *DB) InsertFailureArmed() (bool, int64) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.failpoint.armed, db.failpoint.remaining
+}
+
+// insertFailureDue reports whether the hook should fire before the next insert
+// NOTE: This function assumes the caller already holds db.mu lock
+func (db *DB) insertFailureDue() bool {
+	return db.failpoint.armed && db.failpoint.remaining <= 0
+}
+
+// countInsertForFailure charges one inserted node against the armed budget
+// NOTE: This function assumes the caller already holds db.mu lock
+func (db *DB) countInsertForFailure() {
+	if db.failpoint.armed {
+		db.failpoint.remaining--
+	}
+}
+
+// fireInsertFailure disarms the hook, marks the parked parents pending and builds the error
+// NOTE: This function assumes the caller already holds db.mu lock
+func (db *DB) fireInsertFailure(parked []*types.Node) error {
+	after := db.failpoint.after
+	db.failpoint = insertFailpoint{}
+
+	for _, node := range parked {
+		db.pending[node.ParentID] = struct{}{}
+	}
+	return fmt.Errorf("%w after %d nodes (%d parked)", ErrInjectedFailure, after, len(parked))
+}
+
+// parkPendingTx stores the nodes an injected failure left uninserted, grouped by parent
+// Existing records for a parent are appended to, so repeated failures never lose children
+func (db *DB) parkPendingTx(tx *bbolt.Tx, nodes []*types.Node) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	byParent := make(map[string][]*types.Node)
+	var order []string
+	for _, node := range nodes {
+		if _, ok := byParent[node.ParentID]; !ok {
+			order = append(order, node.ParentID)
+		}
+		byParent[node.ParentID] = append(byParent[node.ParentID], node)
+	}
+
+	for _, parentID := range order {
+		parked, err := db.pendingTx(tx, parentID)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(append(parked, byParent[parentID]...))
+		if err != nil {
+			return fmt.Errorf("[SpectraFS] failed to marshal pending children of %s: %w", parentID, err)
+		}
+		if err := db.meta.Put(tx, metaPendingPrefix+parentID, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pendingTx returns the children parked for parentID, or nil if there are none
+func (db *DB) pendingTx(tx *bbolt.Tx, parentID string) ([]*types.Node, error) {
+	data, err := db.meta.Get(tx, metaPendingPrefix+parentID)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var nodes []*types.Node
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		return nil, fmt.Errorf("[SpectraFS] failed to unmarshal pending children of %s: %w", parentID, err)
+	}
+	return nodes, nil
+}
+
+// loadPendingTx rebuilds the in-memory set of parents with parked children
+func (db *DB) loadPendingTx(tx *bbolt.Tx) error {
+	keys, err := db.meta.Keys(tx, metaPendingPrefix)
+	if err != nil {
+		return err
+	}
+
+	db.pending = make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		db.pending[key[len(metaPendingPrefix):]] = struct{}{}
+	}
+	return nil
+}
+
+// clearPendingTx drops every parked record (for Reset); the caller resets db.pending after commit
+func (db *DB) clearPendingTx(tx *bbolt.Tx) error {
+	keys, err := db.meta.Keys(tx, metaPendingPrefix)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := db.meta.Delete(tx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CompletePendingChildren inserts the children an injected failure parked for parentID
+// Children that are already stored are skipped, so completion never duplicates a node.
+// Returns the number of nodes inserted (0 when nothing was pending, or the database is read-only
+// and the children stay parked)
+func (db *DB) CompletePendingChildren(parentID string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.pending[parentID]; !ok || db.readOnly {
+		return 0, nil
+	}
+
+	insertedNodes := make([]*types.Node, 0)
+	insertedSizes := make([]int64, 0)
+
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		parked, err := db.pendingTx(tx, parentID)
+		if err != nil {
+			return err
+		}
+
+		return db.coverageTx(tx, coverageAffected(parked), func() error {
+			for _, node := range parked {
+				inserted, size, err := db.insertNewNodeTx(tx, node)
+				if err != nil {
+					return err
+				}
+				if inserted {
+					insertedNodes = append(insertedNodes, node)
+					insertedSizes = append(insertedSizes, size)
+				}
+			}
+
+			if err := db.stats.Apply(tx, insertedNodes, true); err != nil {
+				return err
+			}
+			return db.meta.Delete(tx, metaPendingPrefix+parentID)
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	delete(db.pending, parentID)
+	if db.cache != nil {
+		for i, node := range insertedNodes {
+			db.cache.add(node, insertedSizes[i])
+		}
+	}
+	return len(insertedNodes), nil
+}
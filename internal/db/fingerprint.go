@@ -0,0 +1,47 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// metaFingerprint is the meta bucket key holding the JSON types.GenerationFingerprint
+const metaFingerprint = "generation_fingerprint"
+
+// Fingerprint returns the stored generation settings, or nil if none have been recorded
+func (db *DB) Fingerprint() (*types.GenerationFingerprint, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var fingerprint *types.GenerationFingerprint
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		data, err := db.meta.Get(tx, metaFingerprint)
+		if err != nil || data == nil {
+			return err
+		}
+		fingerprint = &types.GenerationFingerprint{}
+		if err := json.Unmarshal(data, fingerprint); err != nil {
+			return fmt.Errorf("[SpectraFS] failed to unmarshal generation fingerprint: %w", err)
+		}
+		return nil
+	})
+	return fingerprint, err
+}
+
+// SetFingerprint stores the generation settings, replacing any recorded before
+func (db *DB) SetFingerprint(fingerprint *types.GenerationFingerprint) error {
+	data, err := json.Marshal(fingerprint)
+	if err != nil {
+		return fmt.Errorf("[SpectraFS] failed to marshal generation fingerprint: %w", err)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.withTx(func(tx *bbolt.Tx) error {
+		return db.meta.Put(tx, metaFingerprint, data)
+	})
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/internal/utils"
+	"github.com/google/uuid"
+	"go.etcd.io/bbolt"
+)
+
+// testWorlds are the secondary worlds test databases are opened with
+var testWorlds = map[string]float64{"s1": 0.5}
+
+// testBackendEnv switches the test databases to a file in t.TempDir() when set to "file"
+// (SPECTRA_TEST_BACKEND=file go test ./...)
+const testBackendEnv = "SPECTRA_TEST_BACKEND"
+
+// newTestDB opens an in-memory database, or a temporary file under SPECTRA_TEST_BACKEND=file,
+// closed when the test ends
+func newTestDB(tb testing.TB, opts Options) *DB {
+	tb.Helper()
+	if os.Getenv(testBackendEnv) == "file" {
+		return openTestDB(tb, tempDBPath(tb), opts)
+	}
+	return openTestDB(tb, MemoryPath, opts)
+}
+
+// openTestDB opens (creating if needed) the database at path, closed when the test ends
+func openTestDB(tb testing.TB, path string, opts Options) *DB {
+	tb.Helper()
+	database, err := New(path, testWorlds, opts)
+	if err != nil {
+		tb.Fatalf("open %s: %v", path, err)
+	}
+	tb.Cleanup(func() { database.Close() })
+	return database
+}
+
+// tempDBPath returns a database file path in a directory removed when the test ends
+func tempDBPath(tb testing.TB) string {
+	tb.Helper()
+	return filepath.Join(tb.TempDir(), "spectra.db")
+}
+
+// newNode builds a node of nodeType named name under parent, existing in primary and s1
+func newNode(parent *types.Node, name, nodeType string) *types.Node {
+	node := &types.Node{
+		ID:              uuid.New().String(),
+		ParentID:        parent.ID,
+		Name:            name,
+		Path:            utils.JoinPath(parent.Path, name),
+		ParentPath:      parent.Path,
+		Type:            nodeType,
+		DepthLevel:      parent.DepthLevel + 1,
+		LastUpdated:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		ExistenceMap:    map[string]bool{"primary": true, "s1": true},
+		TraversalStatus: types.StatusPending,
+		CopyStatus:      types.CopyStatusPending,
+	}
+	if nodeType == types.NodeTypeFile {
+		checksum := fmt.Sprintf("%064x", len(name))
+		node.Size = int64(len(name))
+		node.Checksum = &checksum
+	}
+	return node
+}
+
+// rootNode returns the database's root
+func rootNode(tb testing.TB, database *DB) *types.Node {
+	tb.Helper()
+	root, err := database.GetNodeByID("root")
+	if err != nil {
+		tb.Fatalf("get root: %v", err)
+	}
+	return root
+}
+
+// seedTree inserts folders folders under the root with files files in each, and returns the
+// inserted nodes, folders before their files
+func seedTree(tb testing.TB, database *DB, folders, files int) []*types.Node {
+	tb.Helper()
+	root := rootNode(tb, database)
+	var nodes []*types.Node
+	for i := range folders {
+		folder := newNode(root, fmt.Sprintf("folder-%04d", i), types.NodeTypeFolder)
+		nodes = append(nodes, folder)
+		for j := range files {
+			nodes = append(nodes, newNode(folder, fmt.Sprintf("file-%04d.txt", j), types.NodeTypeFile))
+		}
+	}
+	if err := database.BulkInsertNodes(context.Background(), nodes); err != nil {
+		tb.Fatalf("insert tree: %v", err)
+	}
+	return nodes
+}
+
+// update runs fn in a write transaction, failing the test on error
+func update(tb testing.TB, database *DB, fn func(tx *bbolt.Tx) error) {
+	tb.Helper()
+	if err := database.withTx(fn); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// view runs fn in a read transaction, failing the test on error
+func view(tb testing.TB, database *DB, fn func(tx *bbolt.Tx) error) {
+	tb.Helper()
+	if err := database.withViewTx(fn); err != nil {
+		tb.Fatal(err)
+	}
+}
@@ -0,0 +1,198 @@
+package db
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/google/uuid"
+	"go.etcd.io/bbolt"
+)
+
+// metaIdentity is the meta bucket key holding the JSON types.InstanceIdentity
+const metaIdentity = "identity"
+
+// metaCursorSecret is the meta bucket key holding the random key pagination cursors are signed with
+const metaCursorSecret = "cursor_secret"
+
+// cursorSecretSize is the length in bytes of a generated cursor secret
+const cursorSecretSize = 32
+
+// EnsureIdentity returns the instance identity, creating one for seed if the database has none yet
+// An existing identity is never rewritten, so the stored seed is the one the data was generated with
+// A read-only database without one returns nil
+func (db *DB) EnsureIdentity(seed int64) (*types.InstanceIdentity, error) {
+	if db.readOnly {
+		return db.Identity()
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var identity *types.InstanceIdentity
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		var err error
+		if identity, err = db.identityTx(tx); err != nil || identity != nil {
+			return err
+		}
+
+		identity = &types.InstanceIdentity{
+			InstanceID: uuid.New().String(),
+			Seed:       seed,
+			CreatedAt:  time.Now().UTC(),
+		}
+		return putIdentityTx(tx, db.meta, identity)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[SpectraFS] failed to load instance identity: %w", err)
+	}
+	return identity, nil
+}
+
+// CursorSecret returns the instance's random pagination cursor key, creating it on first use
+// Unlike the seed, which the config endpoint returns, the key never leaves the database, so cursors
+// cannot be forged. A read-only database without one gets a fresh key that lasts for this open only
+func (db *DB) CursorSecret() ([]byte, error) {
+	var secret []byte
+	if db.readOnly {
+		err := db.withViewTx(func(tx *bbolt.Tx) error {
+			var err error
+			secret, err = db.meta.Get(tx, metaCursorSecret)
+			return err
+		})
+		if err != nil || secret != nil {
+			return secret, err
+		}
+		return newCursorSecret(), nil
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		var err error
+		if secret, err = db.meta.Get(tx, metaCursorSecret); err != nil || secret != nil {
+			return err
+		}
+		secret = newCursorSecret()
+		return db.meta.Put(tx, metaCursorSecret, secret)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[SpectraFS] failed to load cursor secret: %w", err)
+	}
+	return secret, nil
+}
+
+// newCursorSecret draws a random cursor secret
+func newCursorSecret() []byte {
+	secret := make([]byte, cursorSecretSize)
+	rand.Read(secret) // Never fails (see crypto/rand.Read)
+	return secret
+}
+
+// Identity returns the instance identity, or nil if none has been recorded
+func (db *DB) Identity() (*types.InstanceIdentity, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var identity *types.InstanceIdentity
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		identity, err = db.identityTx(tx)
+		return err
+	})
+	return identity, err
+}
+
+// CloneTo writes a consistent snapshot of the database to targetPath and gives the copy its own identity
+// The copy runs in a read transaction without holding db.mu, so reads and writes continue meanwhile
+// The clone gets a new instance ID, records this instance as its source, keeps the seed, and is
+// marked as cleanly shut down so opening it does not trigger a recovery pass
+func (db *DB) CloneTo(targetPath string) (*types.InstanceIdentity, error) {
+	if _, err := os.Stat(targetPath); err == nil {
+		return nil, fmt.Errorf("[SpectraFS] clone target %s already exists", targetPath)
+	}
+
+	source, err := db.Identity()
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, fmt.Errorf("[SpectraFS] source database has no instance identity")
+	}
+
+	if err := db.withViewTx(func(tx *bbolt.Tx) error {
+		return tx.CopyFile(targetPath, 0600)
+	}); err != nil {
+		os.Remove(targetPath)
+		return nil, fmt.Errorf("[SpectraFS] failed to copy database to %s: %w", targetPath, err)
+	}
+
+	clonedAt := time.Now().UTC()
+	identity := &types.InstanceIdentity{
+		InstanceID: uuid.New().String(),
+		Seed:       source.Seed,
+		CreatedAt:  clonedAt,
+		ClonedFrom: source.InstanceID,
+		ClonedAt:   &clonedAt,
+	}
+	if err := rewriteCloneMeta(targetPath, identity); err != nil {
+		os.Remove(targetPath)
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+// rewriteCloneMeta stamps a freshly copied database file with its own identity and a clean-shutdown marker
+// The source's recovery history stays with the source
+func rewriteCloneMeta(path string, identity *types.InstanceIdentity) error {
+	clone, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return fmt.Errorf("[SpectraFS] failed to open clone %s: %w", path, err)
+	}
+	defer clone.Close()
+
+	meta := boltMetaRepo{}
+	err = clone.Update(func(tx *bbolt.Tx) error {
+		if err := putIdentityTx(tx, meta, identity); err != nil {
+			return err
+		}
+		if err := meta.Delete(tx, metaLastRecovery); err != nil {
+			return err
+		}
+		if err := meta.Delete(tx, metaCursorSecret); err != nil { // The clone draws its own on open
+			return err
+		}
+		return meta.Put(tx, metaCleanShutdown, []byte(identity.CreatedAt.Format(time.RFC3339Nano)))
+	})
+	if err != nil {
+		return fmt.Errorf("[SpectraFS] failed to rewrite clone identity: %w", err)
+	}
+	return nil
+}
+
+// identityTx reads the stored identity, or nil if there is none
+func (db *DB) identityTx(tx *bbolt.Tx) (*types.InstanceIdentity, error) {
+	data, err := db.meta.Get(tx, metaIdentity)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	identity := &types.InstanceIdentity{}
+	if err := json.Unmarshal(data, identity); err != nil {
+		return nil, fmt.Errorf("[SpectraFS] failed to unmarshal instance identity: %w", err)
+	}
+	return identity, nil
+}
+
+// putIdentityTx stores the identity through meta
+func putIdentityTx(tx *bbolt.Tx, meta MetaRepo, identity *types.InstanceIdentity) error {
+	data, err := json.Marshal(identity)
+	if err != nil {
+		return fmt.Errorf("[SpectraFS] failed to marshal instance identity: %w", err)
+	}
+	return meta.Put(tx, metaIdentity, data)
+}
@@ -0,0 +1,284 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// IndexRepo owns the secondary index buckets:
+//   - index_parent_id:   a nested bucket per parent, "{parentID}" -> {"{nodeID}" -> empty}
+//   - index_path:        "{path}" -> "{nodeID}"
+//   - index_parent_path: "{parentPath}|{nodeID}" -> empty
+//
+// Methods run inside a caller-supplied transaction and never lock
+type IndexRepo interface {
+	// Add records a node in every index
+	Add(tx *bbolt.Tx, node *types.Node) error
+	// Remove drops a node from every index
+	Remove(tx *bbolt.Tx, node *types.Node) error
+	// ChildIDs returns the IDs of every child of a parent, across all worlds
+	ChildIDs(tx *bbolt.Tx, parentID string) ([]string, error)
+	// HasChildren reports whether a parent has any child, across all worlds
+	HasChildren(tx *bbolt.Tx, parentID string) (bool, error)
+	// LookupPath returns the ID of the node at a path, or "" if none
+	LookupPath(tx *bbolt.Tx, path string) (string, error)
+	// ScanPathPrefix visits index_path entries at prefix or below it and after the path after ("" = from
+	// the start), in path order, until fn returns false
+	ScanPathPrefix(tx *bbolt.Tx, prefix, after string, fn func(path, id string) (bool, error)) error
+	// ForEachChildLink visits every parent/child pair in index_parent_id
+	ForEachChildLink(tx *bbolt.Tx, fn func(parentID, childID string) error) error
+	// Counts returns the number of entries in each index bucket, keyed by bucket name
+	Counts(tx *bbolt.Tx) (map[string]int64, error)
+	// Clear removes every entry from every index
+	Clear(tx *bbolt.Tx) error
+}
+
+// indexBuckets lists every bucket owned by IndexRepo
+var indexBuckets = []string{bucketIndexParentID, bucketIndexPath, bucketIndexParentPath}
+
+// rootParentKey names the index_parent_id bucket holding the root, whose parent ID is empty
+// (bbolt bucket names cannot be); no node ID starts with a NUL byte
+const rootParentKey = "\x00"
+
+// parentKey returns the name of a parent's nested bucket in index_parent_id
+func parentKey(parentID string) []byte {
+	if parentID == "" {
+		return []byte(rootParentKey)
+	}
+	return []byte(parentID)
+}
+
+// parentIDFromKey reverses parentKey
+func parentIDFromKey(key []byte) string {
+	if string(key) == rootParentKey {
+		return ""
+	}
+	return string(key)
+}
+
+// hasChildLink reports whether index_parent_id links childID under parentID
+func hasChildLink(indexParentID *bbolt.Bucket, parentID, childID string) bool {
+	children := indexParentID.Bucket(parentKey(parentID))
+	return children != nil && children.Get([]byte(childID)) != nil
+}
+
+// linkChild records childID in parentID's nested bucket, creating the bucket on its first child
+func linkChild(indexParentID *bbolt.Bucket, parentID, childID string) error {
+	children, err := indexParentID.CreateBucketIfNotExists(parentKey(parentID))
+	if err != nil {
+		return err
+	}
+	return children.Put([]byte(childID), []byte{})
+}
+
+// boltIndexRepo is the BoltDB implementation of IndexRepo
+type boltIndexRepo struct{}
+
+// bucket returns a named index bucket
+func (r boltIndexRepo) bucket(tx *bbolt.Tx, name string) (*bbolt.Bucket, error) {
+	bucket := tx.Bucket([]byte(name))
+	if bucket == nil {
+		return nil, fmt.Errorf("[SpectraFS] %s bucket does not exist", name)
+	}
+	return bucket, nil
+}
+
+// Add records a node in every index
+func (r boltIndexRepo) Add(tx *bbolt.Tx, node *types.Node) error {
+	indexParentID, err := r.bucket(tx, bucketIndexParentID)
+	if err != nil {
+		return err
+	}
+	if err := linkChild(indexParentID, node.ParentID, node.ID); err != nil {
+		return fmt.Errorf("[SpectraFS] failed to update parent_id index for node %s: %w", node.ID, err)
+	}
+
+	indexPath, err := r.bucket(tx, bucketIndexPath)
+	if err != nil {
+		return err
+	}
+	if err := indexPath.Put([]byte(node.Path), []byte(node.ID)); err != nil {
+		return fmt.Errorf("[SpectraFS] failed to update path index for node %s: %w", node.ID, err)
+	}
+
+	indexParentPath, err := r.bucket(tx, bucketIndexParentPath)
+	if err != nil {
+		return err
+	}
+	if err := indexParentPath.Put([]byte(node.ParentPath+"|"+node.ID), []byte{}); err != nil {
+		return fmt.Errorf("[SpectraFS] failed to update parent_path index for node %s: %w", node.ID, err)
+	}
+
+	return nil
+}
+
+// Remove drops a node from every index
+func (r boltIndexRepo) Remove(tx *bbolt.Tx, node *types.Node) error {
+	indexParentID, err := r.bucket(tx, bucketIndexParentID)
+	if err != nil {
+		return err
+	}
+	if children := indexParentID.Bucket(parentKey(node.ParentID)); children != nil {
+		if err := children.Delete([]byte(node.ID)); err != nil {
+			return fmt.Errorf("[SpectraFS] failed to delete from parent_id index: %w", err)
+		}
+		// Drop the parent's bucket with its last child, so a bucket always means children
+		if key, _ := children.Cursor().First(); key == nil {
+			if err := indexParentID.DeleteBucket(parentKey(node.ParentID)); err != nil {
+				return fmt.Errorf("[SpectraFS] failed to delete from parent_id index: %w", err)
+			}
+		}
+	}
+
+	indexPath, err := r.bucket(tx, bucketIndexPath)
+	if err != nil {
+		return err
+	}
+	if err := indexPath.Delete([]byte(node.Path)); err != nil {
+		return fmt.Errorf("[SpectraFS] failed to delete from path index: %w", err)
+	}
+
+	indexParentPath, err := r.bucket(tx, bucketIndexParentPath)
+	if err != nil {
+		return err
+	}
+	if err := indexParentPath.Delete([]byte(node.ParentPath + "|" + node.ID)); err != nil {
+		return fmt.Errorf("[SpectraFS] failed to delete from parent_path index: %w", err)
+	}
+
+	return nil
+}
+
+// ChildIDs returns the IDs of every child of a parent, across all worlds
+func (r boltIndexRepo) ChildIDs(tx *bbolt.Tx, parentID string) ([]string, error) {
+	indexParentID, err := r.bucket(tx, bucketIndexParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	children := indexParentID.Bucket(parentKey(parentID))
+	if children == nil {
+		return nil, nil
+	}
+
+	var ids []string
+	cursor := children.Cursor()
+	for key, _ := cursor.First(); key != nil; key, _ = cursor.Next() {
+		ids = append(ids, string(key))
+	}
+	return ids, nil
+}
+
+// HasChildren reports whether a parent has any child, across all worlds
+func (r boltIndexRepo) HasChildren(tx *bbolt.Tx, parentID string) (bool, error) {
+	indexParentID, err := r.bucket(tx, bucketIndexParentID)
+	if err != nil {
+		return false, err
+	}
+
+	children := indexParentID.Bucket(parentKey(parentID))
+	if children == nil {
+		return false, nil
+	}
+	key, _ := children.Cursor().First()
+	return key != nil, nil
+}
+
+// LookupPath returns the ID of the node at a path, or "" if none
+func (r boltIndexRepo) LookupPath(tx *bbolt.Tx, path string) (string, error) {
+	indexPath, err := r.bucket(tx, bucketIndexPath)
+	if err != nil {
+		return "", err
+	}
+	return string(indexPath.Get([]byte(path))), nil
+}
+
+// ScanPathPrefix visits index_path entries at prefix or below it, in path order, until fn returns false
+// Only whole path components match: "/a" visits "/a" and "/a/x" but not "/ab". prefix "/" visits every path.
+// A non-empty after resumes the scan past that path, as returned by an earlier visit
+func (r boltIndexRepo) ScanPathPrefix(tx *bbolt.Tx, prefix, after string, fn func(path, id string) (bool, error)) error {
+	indexPath, err := r.bucket(tx, bucketIndexPath)
+	if err != nil {
+		return err
+	}
+
+	below := []byte(prefix)
+	if prefix != "/" {
+		// The prefix itself sorts before its descendants but may be followed by siblings such as
+		// "/a b" or "/a-b" ahead of "/a/", so visit it on its own and then seek to "/a/"
+		if id := indexPath.Get(below); id != nil && after == "" {
+			more, err := fn(prefix, string(id))
+			if err != nil || !more {
+				return err
+			}
+		}
+		below = append(below, '/')
+	}
+
+	c := indexPath.Cursor()
+	key, id := c.Seek(below)
+	if after != "" && after >= string(below) {
+		if key, id = c.Seek([]byte(after)); key != nil && string(key) == after {
+			key, id = c.Next()
+		}
+	}
+	for ; key != nil && bytes.HasPrefix(key, below); key, id = c.Next() {
+		more, err := fn(string(key), string(id))
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForEachChildLink visits every parent/child pair in index_parent_id
+func (r boltIndexRepo) ForEachChildLink(tx *bbolt.Tx, fn func(parentID, childID string) error) error {
+	indexParentID, err := r.bucket(tx, bucketIndexParentID)
+	if err != nil {
+		return err
+	}
+
+	return indexParentID.ForEachBucket(func(key []byte) error {
+		parentID := parentIDFromKey(key)
+		return indexParentID.Bucket(key).ForEach(func(childID, _ []byte) error {
+			return fn(parentID, string(childID))
+		})
+	})
+}
+
+// Counts returns the number of entries in each index bucket, keyed by bucket name
+func (r boltIndexRepo) Counts(tx *bbolt.Tx) (map[string]int64, error) {
+	counts := make(map[string]int64, len(indexBuckets))
+	for _, name := range indexBuckets {
+		bucket, err := r.bucket(tx, name)
+		if err != nil {
+			return nil, err
+		}
+		if name != bucketIndexParentID {
+			counts[name] = int64(bucket.Stats().KeyN)
+			continue
+		}
+		// Stats on the outer bucket would count the nested buckets' names too, so sum the children
+		err = bucket.ForEachBucket(func(key []byte) error {
+			counts[name] += int64(bucket.Bucket(key).Stats().KeyN)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return counts, nil
+}
+
+// Clear removes every entry from every index
+func (r boltIndexRepo) Clear(tx *bbolt.Tx) error {
+	for _, name := range indexBuckets {
+		if err := clearBucket(tx, name); err != nil {
+			return fmt.Errorf("[SpectraFS] failed to delete from index %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// addIndexed records nodes in the indexes only, bypassing the nodes bucket
+func addIndexed(tb testing.TB, database *DB, nodes ...*types.Node) {
+	tb.Helper()
+	update(tb, database, func(tx *bbolt.Tx) error {
+		for _, node := range nodes {
+			if err := database.index.Add(tx, node); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func TestIndexRepoChildren(t *testing.T) {
+	database := newTestDB(t, Options{})
+	repo := database.index
+	root := rootNode(t, database)
+	folder := newNode(root, "a", types.NodeTypeFolder)
+	file := newNode(folder, "x.txt", types.NodeTypeFile)
+	addIndexed(t, database, folder, file)
+
+	view(t, database, func(tx *bbolt.Tx) error {
+		ids, err := repo.ChildIDs(tx, folder.ID)
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(ids, []string{file.ID}) {
+			t.Errorf("ChildIDs = %v, want [%s]", ids, file.ID)
+		}
+		if has, err := repo.HasChildren(tx, file.ID); err != nil || has {
+			t.Errorf("HasChildren(file) = %v, %v", has, err)
+		}
+		if id, err := repo.LookupPath(tx, "/a/x.txt"); err != nil || id != file.ID {
+			t.Errorf("LookupPath = %q, %v; want %s", id, err, file.ID)
+		}
+
+		links := map[string]string{}
+		err = repo.ForEachChildLink(tx, func(parentID, childID string) error {
+			links[childID] = parentID
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if links[folder.ID] != "root" || links[file.ID] != folder.ID {
+			t.Errorf("child links = %v", links)
+		}
+		return nil
+	})
+
+	update(t, database, func(tx *bbolt.Tx) error { return repo.Remove(tx, file) })
+	view(t, database, func(tx *bbolt.Tx) error {
+		if has, err := repo.HasChildren(tx, folder.ID); err != nil || has {
+			t.Errorf("HasChildren after removing the only child = %v, %v", has, err)
+		}
+		if bucket := tx.Bucket([]byte(bucketIndexParentID)).Bucket(parentKey(folder.ID)); bucket != nil {
+			t.Error("empty parent bucket left behind")
+		}
+		if id, err := repo.LookupPath(tx, "/a/x.txt"); err != nil || id != "" {
+			t.Errorf("LookupPath after Remove = %q, %v", id, err)
+		}
+		return nil
+	})
+}
+
+func TestIndexRepoScanPathPrefixBoundary(t *testing.T) {
+	database := newTestDB(t, Options{})
+	root := rootNode(t, database)
+	a := newNode(root, "a", types.NodeTypeFolder)
+	addIndexed(t, database,
+		a,
+		newNode(a, "x", types.NodeTypeFile),
+		newNode(a, "y", types.NodeTypeFile),
+		newNode(root, "ab", types.NodeTypeFolder),
+		newNode(root, "a b", types.NodeTypeFolder),
+		newNode(root, "a-b", types.NodeTypeFolder),
+	)
+
+	scan := func(prefix, after string, limit int) []string {
+		var paths []string
+		view(t, database, func(tx *bbolt.Tx) error {
+			return database.index.ScanPathPrefix(tx, prefix, after, func(path, _ string) (bool, error) {
+				paths = append(paths, path)
+				return len(paths) < limit, nil
+			})
+		})
+		return paths
+	}
+
+	if got, want := scan("/a", "", 100), []string{"/a", "/a/x", "/a/y"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanPathPrefix(/a) = %v, want %v", got, want)
+	}
+	if got, want := scan("/a", "/a/x", 100), []string{"/a/y"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanPathPrefix(/a) after /a/x = %v, want %v", got, want)
+	}
+	if got, want := scan("/a", "", 2), []string{"/a", "/a/x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanPathPrefix(/a) stopped = %v, want %v", got, want)
+	}
+	if got := scan("/", "", 100); len(got) != 7 || !slices.IsSorted(got) {
+		t.Errorf("ScanPathPrefix(/) = %v, want all 7 paths in order", got)
+	}
+}
+
+func TestIndexRepoCountsAndClear(t *testing.T) {
+	database := newTestDB(t, Options{})
+	repo := database.index
+	nodes := seedTree(t, database, 2, 3)
+
+	want := int64(len(nodes) + 1) // The root too
+	view(t, database, func(tx *bbolt.Tx) error {
+		counts, err := repo.Counts(tx)
+		if err != nil {
+			return err
+		}
+		for _, name := range indexBuckets {
+			if counts[name] != want {
+				t.Errorf("%s holds %d entries, want %d", name, counts[name], want)
+			}
+		}
+		return nil
+	})
+
+	update(t, database, func(tx *bbolt.Tx) error { return repo.Clear(tx) })
+	view(t, database, func(tx *bbolt.Tx) error {
+		counts, err := repo.Counts(tx)
+		if err != nil {
+			return err
+		}
+		for name, count := range counts {
+			if count != 0 {
+				t.Errorf("%s holds %d entries after Clear", name, count)
+			}
+		}
+		return nil
+	})
+}
+
+func TestIndexRepoPrefixParentIDs(t *testing.T) {
+	database := newTestDB(t, Options{})
+	root := rootNode(t, database)
+	a := newNode(root, "a", types.NodeTypeFolder)
+	a.ID = "root2"
+	b := newNode(a, "b", types.NodeTypeFolder)
+	b.ID = "root2|x"
+	c := newNode(b, "c.txt", types.NodeTypeFile)
+	addIndexed(t, database, a, b, c)
+
+	view(t, database, func(tx *bbolt.Tx) error {
+		for parentID, want := range map[string][]string{"root": {"root2"}, "root2": {"root2|x"}, "root2|x": {c.ID}} {
+			ids, err := database.index.ChildIDs(tx, parentID)
+			if err != nil {
+				return err
+			}
+			if !slices.Equal(ids, want) {
+				t.Errorf("ChildIDs(%s) = %v, want %v", parentID, ids, want)
+			}
+		}
+		return nil
+	})
+}
+
+// flattenParentIndex rewrites index_parent_id in the layout used before it held a bucket per parent
+func flattenParentIndex(tb testing.TB, database *DB) {
+	tb.Helper()
+	update(tb, database, func(tx *bbolt.Tx) error {
+		var links [][2]string
+		err := database.index.ForEachChildLink(tx, func(parentID, childID string) error {
+			links = append(links, [2]string{parentID, childID})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := clearBucket(tx, bucketIndexParentID); err != nil {
+			return err
+		}
+		for _, link := range links {
+			if err := tx.Bucket([]byte(bucketIndexParentID)).Put([]byte(link[0]+"|"+link[1]), []byte{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func TestMigrateFlatParentIndex(t *testing.T) {
+	path := tempDBPath(t)
+	database := openTestDB(t, path, Options{})
+	nodes := seedTree(t, database, 3, 4)
+	flattenParentIndex(t, database)
+	if err := database.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	database = openTestDB(t, path, Options{})
+	view(t, database, func(tx *bbolt.Tx) error {
+		if bucket := tx.Bucket([]byte(bucketIndexParentID)).Bucket(parentKey("root")); bucket == nil {
+			t.Error("the root's children were not moved into a nested bucket")
+		}
+		ids, err := database.index.ChildIDs(tx, nodes[0].ID)
+		if err != nil {
+			return err
+		}
+		if len(ids) != 4 {
+			t.Errorf("first folder has %d children after migration, want 4", len(ids))
+		}
+		return nil
+	})
+	report, err := database.CheckIntegrity(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK {
+		t.Errorf("integrity after migration: %+v", report)
+	}
+}
+
+// wideFolder stores a folder with n file children and returns its ID
+func wideFolder(b *testing.B, database *DB, n int) string {
+	b.Helper()
+	folder := newNode(rootNode(b, database), "wide", types.NodeTypeFolder)
+	nodes := []*types.Node{folder}
+	for i := range n {
+		nodes = append(nodes, newNode(folder, fmt.Sprintf("file-%05d.txt", i), types.NodeTypeFile))
+	}
+	if err := database.BulkInsertNodes(context.Background(), nodes); err != nil {
+		b.Fatal(err)
+	}
+	return folder.ID
+}
+
+// BenchmarkChildIDs10k lists a 10k-child folder from its nested parent bucket
+func BenchmarkChildIDs10k(b *testing.B) {
+	database := newTestDB(b, Options{})
+	folderID := wideFolder(b, database, 10000)
+
+	for b.Loop() {
+		view(b, database, func(tx *bbolt.Tx) error {
+			_, err := database.index.ChildIDs(tx, folderID)
+			return err
+		})
+	}
+}
+
+// BenchmarkChildIDs10kFlat lists the same folder with the prefix scan the flat layout needed
+func BenchmarkChildIDs10kFlat(b *testing.B) {
+	database := newTestDB(b, Options{})
+	folderID := wideFolder(b, database, 10000)
+	flattenParentIndex(b, database)
+	prefix := []byte(folderID + "|")
+
+	for b.Loop() {
+		view(b, database, func(tx *bbolt.Tx) error {
+			var ids []string
+			cursor := tx.Bucket([]byte(bucketIndexParentID)).Cursor()
+			for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Next() {
+				ids = append(ids, string(key[len(prefix):]))
+			}
+			return nil
+		})
+	}
+}
+
+// BenchmarkGetChildrenByParentID10k lists a 10k-child folder through the DB, nodes decoded
+func BenchmarkGetChildrenByParentID10k(b *testing.B) {
+	database := newTestDB(b, Options{})
+	folderID := wideFolder(b, database, 10000)
+
+	for b.Loop() {
+		if _, err := database.GetChildrenByParentID(folderID, "primary"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// MaxIntegrityIssues caps the issues listed in an IntegrityReport; the counts cover every issue
+const MaxIntegrityIssues = 1000
+
+// indexedNode is the part of a node the indexes are keyed by, and its existence map
+type indexedNode struct {
+	parentID, path, parentPath string
+	existence                  map[string]bool
+}
+
+// CheckIntegrity verifies the index buckets against the nodes bucket on one read-only snapshot
+// The full check confirms that every node has its entry in index_parent_id, index_path and
+// index_parent_path, that every entry points at a stored node that still matches it, and that no
+// node exists in a world its parent is missing from; the quick check only compares each index's
+// entry count with the node count. Stops with ctx.Err() if ctx is cancelled
+func (db *DB) CheckIntegrity(ctx context.Context, quick bool) (*types.IntegrityReport, error) {
+	started := time.Now()
+	report := &types.IntegrityReport{
+		Quick:  quick,
+		Issues: make([]types.IntegrityIssue, 0),
+	}
+
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		if report.IndexEntries, err = db.index.Counts(tx); err != nil {
+			return err
+		}
+		if quick {
+			report.NodeCount = int64(tx.Bucket([]byte(bucketNodes)).Stats().KeyN)
+			return nil
+		}
+		return db.checkIndexesTx(ctx, tx, report)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if quick {
+		report.OK = true
+		for _, name := range indexBuckets {
+			report.OK = report.OK && report.IndexEntries[name] == report.NodeCount
+		}
+	} else {
+		report.OK = report.Missing == 0 && report.Dangling == 0 && report.Stale == 0 && report.Orphaned == 0
+	}
+	report.CheckedAt = started.UTC()
+	report.DurationMillis = time.Since(started).Milliseconds()
+	return report, nil
+}
+
+// checkIndexesTx runs the full integrity check, counting and listing every issue in report
+func (db *DB) checkIndexesTx(ctx context.Context, tx *bbolt.Tx, report *types.IntegrityReport) error {
+	indexParentID := tx.Bucket([]byte(bucketIndexParentID))
+	indexPath := tx.Bucket([]byte(bucketIndexPath))
+	indexParentPath := tx.Bucket([]byte(bucketIndexParentPath))
+
+	// Every node has its entry in each index
+	nodes := make(map[string]indexedNode)
+	var order []string // Node IDs in key order, so orphaned existence is listed in a stable order
+	err := db.nodes.ForEach(tx, func(node *types.Node, _ int64) error {
+		if err := checkCtx(ctx, len(nodes)); err != nil {
+			return err
+		}
+		nodes[node.ID] = indexedNode{parentID: node.ParentID, path: node.Path, parentPath: node.ParentPath, existence: node.ExistenceMap}
+		order = append(order, node.ID)
+		report.NodeCount++
+
+		if !hasChildLink(indexParentID, node.ParentID, node.ID) {
+			addIssue(report, types.IntegrityMissing, bucketIndexParentID, node.ParentID+"|"+node.ID, node.ID)
+		}
+		if id := indexPath.Get([]byte(node.Path)); id == nil {
+			addIssue(report, types.IntegrityMissing, bucketIndexPath, node.Path, node.ID)
+		} else if string(id) != node.ID {
+			// The path is indexed for another node; that entry is reported as stale below
+			addIssue(report, types.IntegrityMissing, bucketIndexPath, node.Path, node.ID)
+		}
+		if indexParentPath.Get([]byte(node.ParentPath+"|"+node.ID)) == nil {
+			addIssue(report, types.IntegrityMissing, bucketIndexParentPath, node.ParentPath+"|"+node.ID, node.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Every node exists only in worlds its parent exists in
+	for _, id := range order {
+		node := nodes[id]
+		parent, stored := nodes[node.parentID]
+		if !stored {
+			continue // The root, or a node whose parent is gone (reported by the recovery pass)
+		}
+		for _, world := range slices.Sorted(maps.Keys(node.existence)) {
+			if node.existence[world] && !parent.existence[world] {
+				report.Orphaned++
+				listIssue(report, types.IntegrityIssue{Kind: types.IntegrityOrphaned, Bucket: bucketNodes, Key: node.path, NodeID: id, World: world})
+			}
+		}
+	}
+
+	// Every entry points at a stored node that it still matches
+	scanned := 0
+	checkEntry := func(name, key, id string, matches bool) error {
+		scanned++
+		if err := checkCtx(ctx, scanned); err != nil {
+			return err
+		}
+		switch _, stored := nodes[id]; {
+		case !stored:
+			addIssue(report, types.IntegrityDangling, name, key, id)
+		case !matches:
+			addIssue(report, types.IntegrityStale, name, key, id)
+		}
+		return nil
+	}
+
+	// index_parent_id entries are reported as "{parentID}|{nodeID}" like the other link index
+	err = db.index.ForEachChildLink(tx, func(parentID, childID string) error {
+		return checkEntry(bucketIndexParentID, parentID+"|"+childID, childID, nodes[childID].parentID == parentID)
+	})
+	if err != nil {
+		return err
+	}
+	err = indexPath.ForEach(func(key, value []byte) error {
+		id := string(value)
+		return checkEntry(bucketIndexPath, string(key), id, nodes[id].path == string(key))
+	})
+	if err != nil {
+		return err
+	}
+	return indexParentPath.ForEach(func(key, _ []byte) error {
+		separator := bytes.LastIndexByte(key, '|')
+		if separator < 0 {
+			return checkEntry(bucketIndexParentPath, string(key), "", false) // A malformed key names no node
+		}
+		id := string(key[separator+1:])
+		return checkEntry(bucketIndexParentPath, string(key), id, nodes[id].parentPath == string(key[:separator]))
+	})
+}
+
+// addIssue counts an issue and lists it while the report has room
+func addIssue(report *types.IntegrityReport, kind, bucket, key, nodeID string) {
+	switch kind {
+	case types.IntegrityMissing:
+		report.Missing++
+	case types.IntegrityDangling:
+		report.Dangling++
+	case types.IntegrityStale:
+		report.Stale++
+	}
+	listIssue(report, types.IntegrityIssue{Kind: kind, Bucket: bucket, Key: key, NodeID: nodeID})
+}
+
+// listIssue lists an already counted issue while the report has room
+func listIssue(report *types.IntegrityReport, issue types.IntegrityIssue) {
+	if len(report.Issues) >= MaxIntegrityIssues {
+		report.Truncated = true
+		return
+	}
+	report.Issues = append(report.Issues, issue)
+}
+
+// Repair clears orphaned existence (a node marked as existing in a world its parent is missing
+// from, along with its descendants there), then rebuilds every index bucket, the stats and the
+// coverage counters from the nodes bucket in one transaction, and reloads the preload cache from the result
+func (db *DB) Repair(ctx context.Context) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var nodes []*types.Node
+	var sizes []int64
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		if err := db.clearOrphanedExistenceTx(tx); err != nil {
+			return err
+		}
+		if err := db.rebuildTx(tx); err != nil {
+			return err
+		}
+		if db.cache == nil {
+			return nil
+		}
+		return db.nodes.ForEach(tx, func(node *types.Node, size int64) error {
+			nodes = append(nodes, node)
+			sizes = append(sizes, size)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if db.cache != nil {
+		db.cache.reload(nodes, sizes)
+	}
+	return nil
+}
+
+// clearOrphanedExistenceTx marks every node missing from the worlds its parent is missing from,
+// parents first so the fix carries down to descendants. Stats and coverage are left to the caller to rebuild
+func (db *DB) clearOrphanedExistenceTx(tx *bbolt.Tx) error {
+	var nodes []*types.Node
+	byID := make(map[string]*types.Node)
+	err := db.nodes.ForEach(tx, func(node *types.Node, _ int64) error {
+		nodes = append(nodes, node)
+		byID[node.ID] = node
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].DepthLevel < nodes[j].DepthLevel })
+
+	for _, node := range nodes {
+		parent := byID[node.ParentID]
+		if parent == nil {
+			continue
+		}
+		changed := false
+		for world, exists := range node.ExistenceMap {
+			if exists && !parent.ExistenceMap[world] {
+				node.ExistenceMap[world] = false
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		if _, err := db.nodes.Put(tx, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// closeCheckPasses runs the quick check CheckOnClose asks for; it passes when disabled
+// NOTE: This function assumes the caller already holds db.mu lock
+func (db *DB) closeCheckPasses() bool {
+	if !db.checkOnClose {
+		return true
+	}
+	report, err := db.CheckIntegrity(context.Background(), true)
+	return err == nil && report.OK
+}
+
+// CheckOnClose makes Close run a quick integrity check and leave the clean-shutdown marker
+// unwritten if it fails, so the next open runs the recovery pass
+func (db *DB) CheckOnClose(enabled bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.checkOnClose = enabled
+}
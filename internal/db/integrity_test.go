@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestIntegrityOrphanedExistence(t *testing.T) {
+	database := newTestDB(t, Options{})
+	ctx := context.Background()
+
+	// folder is missing from s1, yet its child and grandchild exist there
+	folder := newNode(rootNode(t, database), "folder", types.NodeTypeFolder)
+	folder.ExistenceMap["s1"] = false
+	child := newNode(folder, "child", types.NodeTypeFolder)
+	grandchild := newNode(child, "grandchild.txt", types.NodeTypeFile)
+	if err := database.BulkInsertNodes(ctx, []*types.Node{folder, child, grandchild}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := database.CheckIntegrity(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK || report.Orphaned != 1 || len(report.Issues) != 1 {
+		t.Fatalf("check found %d orphaned (ok %v, issues %+v), want child in s1", report.Orphaned, report.OK, report.Issues)
+	}
+	if issue := report.Issues[0]; issue.Kind != types.IntegrityOrphaned || issue.NodeID != child.ID || issue.World != "s1" {
+		t.Errorf("issue %+v, want child orphaned in s1", issue)
+	}
+
+	if err := database.Repair(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range []*types.Node{child, grandchild} {
+		stored, err := database.GetNodeByID(node.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.ExistenceMap["s1"] || !stored.ExistenceMap["primary"] {
+			t.Errorf("%s after repair exists in %v, want primary only", node.Path, stored.ExistenceMap)
+		}
+	}
+	if count, err := database.GetNodeCount(ctx, "s1"); err != nil || count != 1 {
+		t.Errorf("s1 holds %d nodes after repair (%v), want only the root", count, err)
+	}
+	if report, err := database.CheckIntegrity(ctx, false); err != nil || !report.OK {
+		t.Errorf("check after repair: %+v, %v", report, err)
+	}
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// bucketJobs holds the API's job records by ID as JSON types.Job; the first SaveJob creates it
+const bucketJobs = "jobs"
+
+// MaxJobRecords caps the finished jobs kept; saving a job drops the oldest finished ones beyond it
+const MaxJobRecords = 100
+
+// ErrJobNotFound is returned for a job ID with no record
+var ErrJobNotFound = NewError(ErrNotFound, "[SpectraFS] job not found")
+
+// SaveJob stores job under its ID, replacing an earlier record of it
+// Jobs do not touch the tree, so this does not take db.mu
+func (db *DB) SaveJob(job *types.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("[SpectraFS] failed to marshal job %s: %w", job.ID, err)
+	}
+	return db.withTx(func(tx *bbolt.Tx) error {
+		jobs, err := tx.CreateBucketIfNotExists([]byte(bucketJobs))
+		if err != nil {
+			return fmt.Errorf("[SpectraFS] failed to create %s bucket: %w", bucketJobs, err)
+		}
+		if err := jobs.Put([]byte(job.ID), data); err != nil {
+			return err
+		}
+		return pruneJobsTx(jobs)
+	})
+}
+
+// GetJob returns the record of a job, or ErrJobNotFound
+func (db *DB) GetJob(id string) (*types.Job, error) {
+	var job *types.Job
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		jobs := tx.Bucket([]byte(bucketJobs))
+		if jobs == nil {
+			return fmt.Errorf("%w: %s", ErrJobNotFound, id)
+		}
+		data := jobs.Get([]byte(id))
+		if data == nil {
+			return fmt.Errorf("%w: %s", ErrJobNotFound, id)
+		}
+		job = &types.Job{}
+		return json.Unmarshal(data, job)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// ListJobs returns every job record, newest first
+func (db *DB) ListJobs() ([]*types.Job, error) {
+	jobs := make([]*types.Job, 0)
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		jobs, err = jobsTx(tx.Bucket([]byte(bucketJobs)))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[SpectraFS] failed to list jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// FailInterruptedJobs marks every queued or running job failed, returning how many there were
+// Called on open: no job survives the process that ran it
+func (db *DB) FailInterruptedJobs() (int, error) {
+	failed := 0
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketJobs))
+		jobs, err := jobsTx(bucket)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		for _, job := range jobs {
+			if types.IsFinishedJobStatus(job.Status) {
+				continue
+			}
+			job.Error = "interrupted: the instance was closed while the job was " + job.Status
+			job.Status = types.JobFailed
+			job.FinishedAt = &now
+			data, err := json.Marshal(job)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(job.ID), data); err != nil {
+				return err
+			}
+			failed++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("[SpectraFS] failed to fail interrupted jobs: %w", err)
+	}
+	return failed, nil
+}
+
+// jobsTx decodes every record of the jobs bucket (nil before the first job), newest first
+func jobsTx(bucket *bbolt.Bucket) ([]*types.Job, error) {
+	jobs := make([]*types.Job, 0)
+	if bucket == nil {
+		return jobs, nil
+	}
+	err := bucket.ForEach(func(k, v []byte) error {
+		job := &types.Job{}
+		if err := json.Unmarshal(v, job); err != nil {
+			return fmt.Errorf("corrupt job %s: %w", k, err)
+		}
+		jobs = append(jobs, job)
+		return nil
+	})
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	return jobs, err
+}
+
+// pruneJobsTx deletes the oldest finished jobs beyond MaxJobRecords
+func pruneJobsTx(bucket *bbolt.Bucket) error {
+	jobs, err := jobsTx(bucket)
+	if err != nil {
+		return err
+	}
+	finished := 0
+	for _, job := range jobs {
+		if !types.IsFinishedJobStatus(job.Status) {
+			continue
+		}
+		if finished++; finished > MaxJobRecords {
+			if err := bucket.Delete([]byte(job.ID)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
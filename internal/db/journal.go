@@ -0,0 +1,159 @@
+package db
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// journalBucket returns the change journal bucket
+func journalBucket(tx *bbolt.Tx) (*bbolt.Bucket, error) {
+	bucket := tx.Bucket([]byte(bucketJournal))
+	if bucket == nil {
+		return nil, fmt.Errorf("[SpectraFS] journal bucket does not exist")
+	}
+	return bucket, nil
+}
+
+// AppendChanges stores events in the change journal, giving each the next sequence number, and
+// prunes the oldest events beyond maxEntries. Sequence numbers come from the bucket's own counter,
+// so they keep increasing across pruning and TruncateChanges. Returns the events as stored
+func (db *DB) AppendChanges(events []types.ChangeEvent, maxEntries int64) ([]types.ChangeEvent, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		bucket, err := journalBucket(tx)
+		if err != nil {
+			return err
+		}
+		if err := appendChangesTx(bucket, events); err != nil {
+			return err
+		}
+		return pruneChangesTx(bucket, maxEntries)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// PruneChanges deletes the oldest events of the change journal until at most maxEntries remain
+// AppendChanges already does this on every append; this applies a lowered cap without a new event
+func (db *DB) PruneChanges(maxEntries int64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.withTx(func(tx *bbolt.Tx) error {
+		bucket, err := journalBucket(tx)
+		if err != nil {
+			return err
+		}
+		return pruneChangesTx(bucket, maxEntries)
+	})
+}
+
+// TruncateChanges empties the change journal and stores event as its only entry (for Reset)
+// The sequence counter is kept, so consumers holding an older cursor still see the event
+func (db *DB) TruncateChanges(event types.ChangeEvent) (types.ChangeEvent, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	events := []types.ChangeEvent{event}
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		bucket, err := journalBucket(tx)
+		if err != nil {
+			return err
+		}
+		if err := pruneChangesTx(bucket, 0); err != nil {
+			return err
+		}
+		return appendChangesTx(bucket, events)
+	})
+	if err != nil {
+		return types.ChangeEvent{}, err
+	}
+	return events[0], nil
+}
+
+// ListChanges returns up to limit journal events with a sequence number above sinceSeq, oldest first,
+// the sequence number of the oldest event still stored (0 for an empty journal) and the latest
+// sequence number handed out (0 if none ever was)
+func (db *DB) ListChanges(sinceSeq int64, limit int) ([]types.ChangeEvent, int64, int64, error) {
+	events := make([]types.ChangeEvent, 0)
+	var oldest, latest int64
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		bucket, err := journalBucket(tx)
+		if err != nil {
+			return err
+		}
+		latest = int64(bucket.Sequence())
+
+		c := bucket.Cursor()
+		if key, _ := c.First(); key != nil {
+			oldest = int64(binary.BigEndian.Uint64(key))
+		}
+		for key, value := c.Seek(seqKey(sinceSeq + 1)); key != nil && len(events) < limit; key, value = c.Next() {
+			var event types.ChangeEvent
+			if err := json.Unmarshal(value, &event); err != nil {
+				return fmt.Errorf("[SpectraFS] failed to unmarshal change %d: %w", binary.BigEndian.Uint64(key), err)
+			}
+			events = append(events, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return events, oldest, latest, nil
+}
+
+// appendChangesTx numbers and stores events in order
+func appendChangesTx(bucket *bbolt.Bucket, events []types.ChangeEvent) error {
+	for i := range events {
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return fmt.Errorf("[SpectraFS] failed to number change: %w", err)
+		}
+		events[i].Seq = int64(seq)
+
+		data, err := json.Marshal(&events[i])
+		if err != nil {
+			return fmt.Errorf("[SpectraFS] failed to marshal change %d: %w", seq, err)
+		}
+		if err := bucket.Put(seqKey(int64(seq)), data); err != nil {
+			return fmt.Errorf("[SpectraFS] failed to journal change %d: %w", seq, err)
+		}
+	}
+	return nil
+}
+
+// lastChangeSeq returns the sequence number of the newest stored event, or 0 for an empty journal
+func lastChangeSeq(bucket *bbolt.Bucket) int64 {
+	key, _ := bucket.Cursor().Last()
+	if key == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(key))
+}
+
+// pruneChangesTx deletes the oldest events until at most maxEntries remain
+// Sequence numbers are contiguous between the oldest and newest entry, so the cutoff is arithmetic
+func pruneChangesTx(bucket *bbolt.Bucket, maxEntries int64) error {
+	cutoff := int64(bucket.Sequence()) - maxEntries
+
+	var stale [][]byte
+	c := bucket.Cursor()
+	for key, _ := c.First(); key != nil && int64(binary.BigEndian.Uint64(key)) <= cutoff; key, _ = c.Next() {
+		stale = append(stale, append([]byte(nil), key...))
+	}
+	for _, key := range stale {
+		if err := bucket.Delete(key); err != nil {
+			return fmt.Errorf("[SpectraFS] failed to prune change %d: %w", binary.BigEndian.Uint64(key), err)
+		}
+	}
+	return nil
+}
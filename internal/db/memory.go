@@ -0,0 +1,45 @@
+package db
+
+import (
+	"os"
+
+	"go.etcd.io/bbolt"
+)
+
+// MemoryPath is the db_path that keeps the database in memory instead of a file
+// The same BoltDB engine runs on an anonymous in-memory file, so every method behaves exactly as it
+// does on disk; writes skip fsync, and everything is discarded when the DB is closed
+const MemoryPath = ":memory:"
+
+// IsMemoryPath reports whether dbPath selects the in-memory backend
+func IsMemoryPath(dbPath string) bool {
+	return dbPath == MemoryPath
+}
+
+// memoryOptions returns BoltDB options that open an in-memory file in place of dbPath,
+// and a cleanup to run once the database is closed
+// BoltDB also opens its own file again to copy it, and the copy's target, through this hook (Clone),
+// so later opens of MemoryPath return new handles on the same file and other paths are real files
+func memoryOptions(options *bbolt.Options) (*bbolt.Options, func()) {
+	var first *os.File
+	var cleanup func()
+	memory := *options
+	memory.NoSync = true
+	memory.NoFreelistSync = true
+	memory.OpenFile = func(name string, flag int, mode os.FileMode) (*os.File, error) {
+		if !IsMemoryPath(name) {
+			return os.OpenFile(name, flag, mode)
+		}
+		if first != nil {
+			return reopenMemoryFile(first, flag, mode)
+		}
+		file, remove, err := openMemoryFile()
+		first, cleanup = file, remove
+		return file, err
+	}
+	return &memory, func() {
+		if cleanup != nil {
+			cleanup()
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// openMemoryFile creates an anonymous memory-backed file (memfd), which the kernel frees on close
+func openMemoryFile() (*os.File, func(), error) {
+	fd, err := unix.MemfdCreate("spectra", unix.MFD_CLOEXEC)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[SpectraFS] failed to create in-memory database file: %w", err)
+	}
+	return os.NewFile(uintptr(fd), MemoryPath), nil, nil
+}
+
+// reopenMemoryFile opens a new handle, with its own offset, on the memfd behind file
+func reopenMemoryFile(file *os.File, flag int, mode os.FileMode) (*os.File, error) {
+	return os.OpenFile(fmt.Sprintf("/proc/self/fd/%d", file.Fd()), flag, mode)
+}
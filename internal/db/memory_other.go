@@ -0,0 +1,23 @@
+//go:build !linux
+
+package db
+
+import (
+	"fmt"
+	"os"
+)
+
+// openMemoryFile creates a temporary file standing in for memory where memfd is unavailable
+// Writes still skip fsync; the file is removed once the database is closed
+func openMemoryFile() (*os.File, func(), error) {
+	file, err := os.CreateTemp("", "spectra-memory-*.db")
+	if err != nil {
+		return nil, nil, fmt.Errorf("[SpectraFS] failed to create in-memory database file: %w", err)
+	}
+	return file, func() { os.Remove(file.Name()) }, nil
+}
+
+// reopenMemoryFile opens a new handle on the temporary file behind file
+func reopenMemoryFile(file *os.File, flag int, mode os.FileMode) (*os.File, error) {
+	return os.OpenFile(file.Name(), flag, mode)
+}
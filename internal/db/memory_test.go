@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+)
+
+// childNames returns the names of parentID's children in world, in listing order
+func childNames(tb testing.TB, database *DB, parentID, world string) []string {
+	tb.Helper()
+	children, err := database.GetChildrenByParentID(parentID, world)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	names := make([]string, len(children))
+	for i, child := range children {
+		names[i] = child.Name
+	}
+	return names
+}
+
+func TestMemoryMatchesFile(t *testing.T) {
+	memory := openTestDB(t, MemoryPath, Options{})
+	file := openTestDB(t, tempDBPath(t), Options{})
+
+	// Insert in reverse so listing order comes from the index, not insertion
+	nodes := seedTree(t, memory, 20, 5)
+	reversed := slices.Clone(nodes)
+	slices.Reverse(reversed)
+	for _, node := range reversed {
+		if err := file.InsertNode(node); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := memory.UpdateExistenceMap(nodes[1].ID, map[string]bool{"primary": true, "s1": false}); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.UpdateExistenceMap(nodes[1].ID, map[string]bool{"primary": true, "s1": false}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, parentID := range []string{"root", nodes[0].ID} {
+		for _, world := range []string{"primary", "s1"} {
+			if a, b := childNames(t, memory, parentID, world), childNames(t, file, parentID, world); !slices.Equal(a, b) {
+				t.Errorf("children of %s in %s: memory %v, file %v", parentID, world, a, b)
+			}
+		}
+	}
+	for _, world := range []string{"primary", "s1"} {
+		a, err := memory.GetNodeCount(context.Background(), world)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := file.GetNodeCount(context.Background(), world)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a != b {
+			t.Errorf("%s count: memory %d, file %d", world, a, b)
+		}
+	}
+	a, b := storedStats(t, memory), storedStats(t, file)
+	if a.TotalNodes != b.TotalNodes || a.TotalFileSize != b.TotalFileSize {
+		t.Errorf("stats: memory %+v, file %+v", a, b)
+	}
+}
+
+func TestMemoryDiscardedOnClose(t *testing.T) {
+	t.Chdir(t.TempDir())
+	database, err := New(MemoryPath, testWorlds, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seedTree(t, database, 3, 2)
+	if err := database.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if entries, _ := os.ReadDir("."); len(entries) > 0 {
+		t.Errorf("in-memory database left %s in the working directory", entries[0].Name())
+	}
+	reopened := openTestDB(t, MemoryPath, Options{})
+	if names := childNames(t, reopened, "root", "primary"); len(names) > 0 {
+		t.Errorf("a new in-memory database has %d children under the root", len(names))
+	}
+	if _, err := reopened.GetNodeByID("root"); err != nil {
+		t.Errorf("new in-memory database has no root: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+)
+
+// MetaRepo owns the meta bucket: small instance-level records keyed by name
+// (markers, identity, and other bookkeeping that is not part of the tree)
+// Methods run inside a caller-supplied transaction and never lock
+type MetaRepo interface {
+	// Get returns the value stored under key, or nil if there is none
+	Get(tx *bbolt.Tx, key string) ([]byte, error)
+	// Put stores value under key
+	Put(tx *bbolt.Tx, key string, value []byte) error
+	// Delete removes key
+	Delete(tx *bbolt.Tx, key string) error
+	// Keys returns every key that starts with prefix, in key order
+	Keys(tx *bbolt.Tx, prefix string) ([]string, error)
+}
+
+// boltMetaRepo is the BoltDB implementation of MetaRepo
+type boltMetaRepo struct{}
+
+// bucket returns the meta bucket
+func (r boltMetaRepo) bucket(tx *bbolt.Tx) (*bbolt.Bucket, error) {
+	bucket := tx.Bucket([]byte(bucketMeta))
+	if bucket == nil {
+		return nil, fmt.Errorf("[SpectraFS] meta bucket does not exist")
+	}
+	return bucket, nil
+}
+
+// Get returns the value stored under key, or nil if there is none
+// The returned slice is a copy and remains valid after the transaction ends
+func (r boltMetaRepo) Get(tx *bbolt.Tx, key string) ([]byte, error) {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return nil, err
+	}
+	value := bucket.Get([]byte(key))
+	if value == nil {
+		return nil, nil
+	}
+	return append([]byte(nil), value...), nil
+}
+
+// Put stores value under key
+func (r boltMetaRepo) Put(tx *bbolt.Tx, key string, value []byte) error {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return err
+	}
+	if err := bucket.Put([]byte(key), value); err != nil {
+		return fmt.Errorf("[SpectraFS] failed to store meta %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes key
+func (r boltMetaRepo) Delete(tx *bbolt.Tx, key string) error {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return err
+	}
+	if err := bucket.Delete([]byte(key)); err != nil {
+		return fmt.Errorf("[SpectraFS] failed to delete meta %s: %w", key, err)
+	}
+	return nil
+}
+
+// Keys returns every key that starts with prefix, in key order
+func (r boltMetaRepo) Keys(tx *bbolt.Tx, prefix string) ([]string, error) {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	cursor := bucket.Cursor()
+	for key, _ := cursor.Seek([]byte(prefix)); key != nil && bytes.HasPrefix(key, []byte(prefix)); key, _ = cursor.Next() {
+		keys = append(keys, string(key))
+	}
+	return keys, nil
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"go.etcd.io/bbolt"
+)
+
+func TestMetaRepo(t *testing.T) {
+	database := newTestDB(t, Options{})
+	repo := boltMetaRepo{}
+
+	update(t, database, func(tx *bbolt.Tx) error {
+		for _, key := range []string{"test/b", "test/a", "test", "tests/c"} {
+			if err := repo.Put(tx, key, []byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	var value []byte
+	view(t, database, func(tx *bbolt.Tx) error {
+		var err error
+		value, err = repo.Get(tx, "test/a")
+		if err != nil {
+			return err
+		}
+		keys, err := repo.Keys(tx, "test/")
+		if err != nil {
+			return err
+		}
+		if want := []string{"test/a", "test/b"}; !reflect.DeepEqual(keys, want) {
+			t.Errorf("Keys(test/) = %v, want %v", keys, want)
+		}
+		if missing, err := repo.Get(tx, "nope"); err != nil || missing != nil {
+			t.Errorf("Get(nope) = %q, %v", missing, err)
+		}
+		return nil
+	})
+	// Get copies, so the value outlives the transaction
+	if string(value) != "test/a" {
+		t.Errorf("Get(test/a) = %q after the transaction", value)
+	}
+
+	update(t, database, func(tx *bbolt.Tx) error { return repo.Delete(tx, "test/a") })
+	view(t, database, func(tx *bbolt.Tx) error {
+		keys, err := repo.Keys(tx, "test/")
+		if err != nil {
+			return err
+		}
+		if want := []string{"test/b"}; !reflect.DeepEqual(keys, want) {
+			t.Errorf("Keys after Delete = %v, want %v", keys, want)
+		}
+		return nil
+	})
+}
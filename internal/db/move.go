@@ -0,0 +1,209 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/internal/utils"
+	"go.etcd.io/bbolt"
+)
+
+// Errors returned by MoveSubtree when a move would break the tree's invariants
+var (
+	ErrMoveIntoDescendant = NewError(ErrInvalidInput, "[SpectraFS] cannot move a node into itself or its own descendant")
+	ErrMoveWorldMismatch  = NewError(ErrInvalidInput, "[SpectraFS] node exists in a world its new parent does not")
+	ErrMoveTargetNotDir   = NewError(ErrInvalidInput, "[SpectraFS] move destination is not a folder")
+	ErrPathExists         = NewError(ErrConflict, "[SpectraFS] a node already exists at the destination path")
+)
+
+// MoveSubtree re-parents a node under newParentID and rewrites the Path, ParentPath and DepthLevel
+// of the node and every descendant, keeping indexes, stats and coverage consistent in one transaction
+// Returns the moved nodes as stored after the move (the moved node first, then its descendants breadth-first)
+func (db *DB) MoveSubtree(id, newParentID string) ([]*types.Node, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var before, after []*types.Node
+	var sizes []int64
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		newParent, err := db.nodes.Get(tx, newParentID)
+		if err != nil {
+			return err
+		}
+		if newParent == nil {
+			return fmt.Errorf("%w: %s", ErrNodeNotFound, newParentID)
+		}
+		if newParent.Type != types.NodeTypeFolder {
+			return fmt.Errorf("%w: %s", ErrMoveTargetNotDir, newParent.Path)
+		}
+
+		if before, err = db.subtreeTx(tx, id); err != nil {
+			return err
+		}
+		node := before[0]
+
+		if err := db.checkMoveTx(tx, node, newParent); err != nil {
+			return err
+		}
+
+		after, sizes, err = db.rewriteSubtreeTx(tx, before, newParent, node.Name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	db.cacheRewrite(before, after, sizes)
+	return after, nil
+}
+
+// RenameSubtree renames a node in place and rewrites the Path and ParentPath of every descendant,
+// keeping indexes, stats and coverage consistent in one transaction
+// Returns the renamed nodes as stored after the rename (the renamed node first, then its descendants breadth-first)
+func (db *DB) RenameSubtree(id, newName string) ([]*types.Node, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var before, after []*types.Node
+	var sizes []int64
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		var err error
+		if before, err = db.subtreeTx(tx, id); err != nil {
+			return err
+		}
+		node := before[0]
+
+		parent, err := db.nodes.Get(tx, node.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return fmt.Errorf("%w: parent %s of %s", ErrNodeNotFound, node.ParentID, node.Path)
+		}
+
+		target := utils.JoinPath(parent.Path, newName)
+		if target != node.Path {
+			existing, err := db.index.LookupPath(tx, target)
+			if err != nil {
+				return err
+			}
+			if existing != "" {
+				return fmt.Errorf("%w: %s", ErrPathExists, target)
+			}
+		}
+
+		after, sizes, err = db.rewriteSubtreeTx(tx, before, parent, newName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	db.cacheRewrite(before, after, sizes)
+	return after, nil
+}
+
+// rewriteSubtreeTx places the subtree root (before[0]) under parent as name and re-derives the
+// ParentID, ParentPath, Path and DepthLevel of every node top-down, replacing the stored nodes,
+// their index entries, the stats and the coverage of every affected folder
+func (db *DB) rewriteSubtreeTx(tx *bbolt.Tx, before []*types.Node, parent *types.Node, name string) ([]*types.Node, []int64, error) {
+	// Rewrite the subtree top-down so every child derives its path from its rewritten parent
+	rewritten := make(map[string]*types.Node, len(before))
+	after := make([]*types.Node, len(before))
+	for i, old := range before {
+		next := cloneNode(old)
+		p := parent
+		if i == 0 {
+			next.Name = name
+		} else {
+			p = rewritten[old.ParentID]
+		}
+		next.ParentID = p.ID
+		next.ParentPath = p.Path
+		next.Path = utils.JoinPath(p.Path, next.Name)
+		next.DepthLevel = p.DepthLevel + 1
+		rewritten[next.ID] = next
+		after[i] = next
+	}
+
+	affected := []string{before[0].ParentID, parent.ID}
+	for _, n := range before {
+		affected = append(affected, n.ID)
+	}
+
+	sizes := make([]int64, len(after))
+	err := db.coverageTx(tx, affected, func() error {
+		if err := db.stats.Apply(tx, before, false); err != nil {
+			return err
+		}
+		for _, old := range before {
+			if err := db.index.Remove(tx, old); err != nil {
+				return err
+			}
+		}
+
+		for i, next := range after {
+			var err error
+			if sizes[i], err = db.nodes.Put(tx, next); err != nil {
+				return err
+			}
+			if err := db.index.Add(tx, next); err != nil {
+				return err
+			}
+		}
+		return db.stats.Apply(tx, after, true)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return after, sizes, nil
+}
+
+// cacheRewrite mirrors a committed rewriteSubtreeTx into the preload cache
+func (db *DB) cacheRewrite(before, after []*types.Node, sizes []int64) {
+	if db.cache == nil {
+		return
+	}
+	db.cache.move(before[0], after[0], sizes[0])
+	for i := 1; i < len(after); i++ {
+		db.cache.update(after[i], sizes[i])
+	}
+}
+
+// checkMoveTx rejects moves that would create a cycle, orphan a node from a world,
+// or collide with an existing path
+func (db *DB) checkMoveTx(tx *bbolt.Tx, node, newParent *types.Node) error {
+	// The destination must not be the node or one of its descendants
+	for ancestor := newParent; ancestor != nil; {
+		if ancestor.ID == node.ID {
+			return fmt.Errorf("%w: %s -> %s", ErrMoveIntoDescendant, node.Path, newParent.Path)
+		}
+		if ancestor.ParentID == "" {
+			break
+		}
+		var err error
+		if ancestor, err = db.nodes.Get(tx, ancestor.ParentID); err != nil {
+			return err
+		}
+	}
+
+	// Descendants only exist where the node does, so checking the node covers the subtree
+	for world, exists := range node.ExistenceMap {
+		if exists && !newParent.ExistenceMap[world] {
+			return fmt.Errorf("%w: %s exists in %s but %s does not", ErrMoveWorldMismatch, node.Path, world, newParent.Path)
+		}
+	}
+
+	target := utils.JoinPath(newParent.Path, node.Name)
+	if target == node.Path {
+		return nil // Already there
+	}
+	existing, err := db.index.LookupPath(tx, target)
+	if err != nil {
+		return err
+	}
+	if existing != "" {
+		return fmt.Errorf("%w: %s", ErrPathExists, target)
+	}
+	return nil
+}
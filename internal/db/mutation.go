@@ -0,0 +1,96 @@
+package db
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// mutationsBucket returns the mutation log bucket
+func mutationsBucket(tx *bbolt.Tx) (*bbolt.Bucket, error) {
+	bucket := tx.Bucket([]byte(bucketMutations))
+	if bucket == nil {
+		return nil, fmt.Errorf("[SpectraFS] mutations bucket does not exist")
+	}
+	return bucket, nil
+}
+
+// seqKey encodes a sequence number so keys sort in log order
+func seqKey(seq int64) []byte {
+	var key [8]byte
+	binary.BigEndian.PutUint64(key[:], uint64(seq))
+	return key[:]
+}
+
+// AppendMutation stores a mutation in the log under its sequence number
+func (db *DB) AppendMutation(mutation *types.Mutation) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data, err := json.Marshal(mutation)
+	if err != nil {
+		return fmt.Errorf("[SpectraFS] failed to marshal mutation %d: %w", mutation.Seq, err)
+	}
+	return db.withTx(func(tx *bbolt.Tx) error {
+		bucket, err := mutationsBucket(tx)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(seqKey(mutation.Seq), data); err != nil {
+			return fmt.Errorf("[SpectraFS] failed to log mutation %d: %w", mutation.Seq, err)
+		}
+		return nil
+	})
+}
+
+// LastMutationSeq returns the sequence number of the newest logged mutation (0 for an empty log)
+func (db *DB) LastMutationSeq() (int64, error) {
+	var seq int64
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		bucket, err := mutationsBucket(tx)
+		if err != nil {
+			return err
+		}
+		if key, _ := bucket.Cursor().Last(); key != nil {
+			seq = int64(binary.BigEndian.Uint64(key))
+		}
+		return nil
+	})
+	return seq, err
+}
+
+// ListMutations returns up to limit logged mutations with a sequence number above afterSeq, oldest first
+func (db *DB) ListMutations(afterSeq int64, limit int) ([]types.Mutation, error) {
+	mutations := make([]types.Mutation, 0)
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		bucket, err := mutationsBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		c := bucket.Cursor()
+		for key, value := c.Seek(seqKey(afterSeq + 1)); key != nil && len(mutations) < limit; key, value = c.Next() {
+			var mutation types.Mutation
+			if err := json.Unmarshal(value, &mutation); err != nil {
+				return fmt.Errorf("[SpectraFS] failed to unmarshal mutation %d: %w", binary.BigEndian.Uint64(key), err)
+			}
+			mutations = append(mutations, mutation)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mutations, nil
+}
+
+// clearMutationsTx empties the mutation log (for Reset)
+func (db *DB) clearMutationsTx(tx *bbolt.Tx) error {
+	if err := clearBucket(tx, bucketMutations); err != nil {
+		return fmt.Errorf("[SpectraFS] failed to clear the mutation log: %w", err)
+	}
+	return nil
+}
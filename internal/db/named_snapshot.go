@@ -0,0 +1,278 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// bucketSnapshots holds a nested bucket per named snapshot; the first SaveSnapshot creates it
+const bucketSnapshots = "snapshots"
+
+// snapshotInfoKey holds a snapshot's JSON types.SnapshotInfo, beside its copies of the buckets
+const snapshotInfoKey = "info"
+
+// snapshotBuckets are copied whole into a snapshot: the tree with its indexes and stats, and the
+// mutation log and change journal that record how it got there. Of meta only the tree's own records
+// are copied (see treeMetaPrefixes); identity, fingerprint and the recovery and maintenance records
+// describe the instance rather than the tree
+var snapshotBuckets = []string{
+	bucketNodes,
+	bucketIndexParentID,
+	bucketIndexPath,
+	bucketIndexParentPath,
+	bucketStats,
+	bucketMutations,
+	bucketJournal,
+}
+
+// Errors of the named snapshots
+var (
+	ErrSnapshotNotFound = NewError(ErrNotFound, "[SpectraFS] snapshot not found")
+	ErrSnapshotExists   = NewError(ErrConflict, "[SpectraFS] snapshot already exists")
+)
+
+// SaveSnapshot copies the tree into a snapshot named info.Name in one transaction, filling in the
+// node count and journal sequence of info. Fails with ErrSnapshotExists if the name is taken
+func (db *DB) SaveSnapshot(info *types.SnapshotInfo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.withTx(func(tx *bbolt.Tx) error {
+		snapshots, err := tx.CreateBucketIfNotExists([]byte(bucketSnapshots))
+		if err != nil {
+			return fmt.Errorf("[SpectraFS] failed to create %s bucket: %w", bucketSnapshots, err)
+		}
+		if snapshots.Bucket([]byte(info.Name)) != nil {
+			return fmt.Errorf("%w: %s", ErrSnapshotExists, info.Name)
+		}
+		snapshot, err := snapshots.CreateBucket([]byte(info.Name))
+		if err != nil {
+			return fmt.Errorf("[SpectraFS] failed to create snapshot %s: %w", info.Name, err)
+		}
+
+		for _, name := range snapshotBuckets {
+			copied, err := snapshot.CreateBucket([]byte(name))
+			if err != nil {
+				return err
+			}
+			if err := copyBucket(copied, tx.Bucket([]byte(name))); err != nil {
+				return fmt.Errorf("[SpectraFS] failed to copy %s bucket: %w", name, err)
+			}
+		}
+		meta, err := snapshot.CreateBucket([]byte(bucketMeta))
+		if err != nil {
+			return err
+		}
+		if err := db.copyTreeMetaTx(tx, meta); err != nil {
+			return err
+		}
+
+		info.Nodes = int64(tx.Bucket([]byte(bucketNodes)).Stats().KeyN)
+		info.JournalSeq = int64(tx.Bucket([]byte(bucketJournal)).Sequence())
+		data, err := json.Marshal(info)
+		if err != nil {
+			return fmt.Errorf("[SpectraFS] failed to marshal snapshot %s: %w", info.Name, err)
+		}
+		return snapshot.Put([]byte(snapshotInfoKey), data)
+	})
+}
+
+// RestoreSnapshot replaces the tree with the named snapshot in one transaction, so readers see
+// either the tree before or the snapshot. The journal keeps its sequence counter (numbers never
+// go backwards), and the parked children and preload cache are reloaded from the result.
+// Cancelling ctx before the commit leaves the tree untouched
+func (db *DB) RestoreSnapshot(ctx context.Context, name string) (*types.SnapshotInfo, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var info *types.SnapshotInfo
+	var nodes []*types.Node
+	var sizes []int64
+	pending := make(map[string]struct{})
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		snapshot, err := snapshotBucketTx(tx, name)
+		if err != nil {
+			return err
+		}
+		if info, err = decodeSnapshotInfo(snapshot); err != nil {
+			return err
+		}
+
+		// The buckets are copied directly rather than through the repositories
+		if db.nodeCache != nil {
+			db.nodeCache.purge(tx.ID())
+		}
+		journalSeq := tx.Bucket([]byte(bucketJournal)).Sequence()
+		for _, bucketName := range snapshotBuckets {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			copied := snapshot.Bucket([]byte(bucketName))
+			if copied == nil {
+				return fmt.Errorf("[SpectraFS] snapshot %s lacks the %s bucket", name, bucketName)
+			}
+			if err := clearBucket(tx, bucketName); err != nil {
+				return err
+			}
+			if err := copyBucket(tx.Bucket([]byte(bucketName)), copied); err != nil {
+				return fmt.Errorf("[SpectraFS] failed to restore %s bucket: %w", bucketName, err)
+			}
+		}
+		if journal := tx.Bucket([]byte(bucketJournal)); journal.Sequence() < journalSeq {
+			if err := journal.SetSequence(journalSeq); err != nil {
+				return err
+			}
+		}
+
+		if err := db.clearPendingTx(tx); err != nil {
+			return err
+		}
+		if err := db.clearChildrenGeneratedTx(tx); err != nil {
+			return err
+		}
+		if meta := snapshot.Bucket([]byte(bucketMeta)); meta != nil {
+			err := meta.ForEach(func(key, value []byte) error {
+				if parentID, ok := bytes.CutPrefix(key, []byte(metaPendingPrefix)); ok {
+					pending[string(parentID)] = struct{}{}
+				}
+				return db.meta.Put(tx, string(key), value)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if db.cache == nil {
+			return nil
+		}
+		return db.nodes.ForEach(tx, func(node *types.Node, size int64) error {
+			nodes = append(nodes, node)
+			sizes = append(sizes, size)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	db.pending = pending
+	if db.cache != nil {
+		db.cache.reload(nodes, sizes)
+	}
+	return info, nil
+}
+
+// GetSnapshot returns the description of the named snapshot (ErrSnapshotNotFound if there is none)
+func (db *DB) GetSnapshot(name string) (*types.SnapshotInfo, error) {
+	var info *types.SnapshotInfo
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		snapshot, err := snapshotBucketTx(tx, name)
+		if err != nil {
+			return err
+		}
+		info, err = decodeSnapshotInfo(snapshot)
+		return err
+	})
+	return info, err
+}
+
+// ListSnapshots returns the descriptions of every snapshot, ordered by name
+func (db *DB) ListSnapshots() ([]types.SnapshotInfo, error) {
+	infos := make([]types.SnapshotInfo, 0)
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		snapshots := tx.Bucket([]byte(bucketSnapshots))
+		if snapshots == nil {
+			return nil
+		}
+		return snapshots.ForEachBucket(func(name []byte) error {
+			info, err := decodeSnapshotInfo(snapshots.Bucket(name))
+			if err != nil {
+				return err
+			}
+			infos = append(infos, *info)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// DeleteSnapshot removes the named snapshot (ErrSnapshotNotFound if there is none)
+func (db *DB) DeleteSnapshot(name string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.withTx(func(tx *bbolt.Tx) error {
+		if _, err := snapshotBucketTx(tx, name); err != nil {
+			return err
+		}
+		return tx.Bucket([]byte(bucketSnapshots)).DeleteBucket([]byte(name))
+	})
+}
+
+// snapshotBucketTx returns the bucket of the named snapshot, or ErrSnapshotNotFound
+func snapshotBucketTx(tx *bbolt.Tx, name string) (*bbolt.Bucket, error) {
+	if snapshots := tx.Bucket([]byte(bucketSnapshots)); snapshots != nil {
+		if snapshot := snapshots.Bucket([]byte(name)); snapshot != nil {
+			return snapshot, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrSnapshotNotFound, name)
+}
+
+// decodeSnapshotInfo reads the description stored in a snapshot's bucket
+func decodeSnapshotInfo(snapshot *bbolt.Bucket) (*types.SnapshotInfo, error) {
+	info := &types.SnapshotInfo{}
+	if err := json.Unmarshal(snapshot.Get([]byte(snapshotInfoKey)), info); err != nil {
+		return nil, fmt.Errorf("[SpectraFS] failed to unmarshal snapshot: %w", err)
+	}
+	return info, nil
+}
+
+// treeMetaPrefixes prefix the meta records that belong to the tree and travel with its snapshots:
+// children parked by an injected generation failure and the children-generated folder markers
+var treeMetaPrefixes = []string{metaPendingPrefix, metaChildrenGeneratedPrefix}
+
+// copyTreeMetaTx copies the tree's meta records (see treeMetaPrefixes) into dst
+func (db *DB) copyTreeMetaTx(tx *bbolt.Tx, dst *bbolt.Bucket) error {
+	for _, prefix := range treeMetaPrefixes {
+		keys, err := db.meta.Keys(tx, prefix)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			data, err := db.meta.Get(tx, key)
+			if err != nil {
+				return err
+			}
+			if err := dst.Put([]byte(key), data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyBucket copies every key, nested bucket and sequence counter of src into the empty bucket dst
+func copyBucket(dst, src *bbolt.Bucket) error {
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+	return src.ForEach(func(key, value []byte) error {
+		if value != nil {
+			return dst.Put(key, value)
+		}
+		nested, err := dst.CreateBucket(key)
+		if err != nil {
+			return err
+		}
+		return copyBucket(nested, src.Bucket(key))
+	})
+}
@@ -0,0 +1,166 @@
+package db
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// nodeCache is a least-recently-used cache of decoded nodes by ID, for the handful of folders
+// (root and the first levels) that nearly every request reads. Lock-free readers fill it from their
+// View snapshots while writers drop what they change, so a fill is only accepted from a snapshot
+// no older than the last write transaction that dropped anything (fence): a reader that started
+// before a write can never put back the node the write replaced. Entries are copies, and so is
+// every node handed out, so callers may modify what they get
+type nodeCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element // nodeID -> element of order holding the *types.Node
+	order   *list.List               // Most recently used first
+	fence   int                      // ID of the latest write transaction that dropped entries
+	hits    int64
+	misses  int64
+}
+
+// newNodeCache creates a cache holding at most size nodes
+func newNodeCache(size int) *nodeCache {
+	return &nodeCache{
+		size:    size,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+// get returns a copy of a cached node, counting the hit or miss
+func (c *nodeCache) get(id string) (*types.Node, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[id]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(element)
+	return cloneNode(element.Value.(*types.Node)), true
+}
+
+// fill caches a node read in the snapshot of transaction txID, evicting the least recently used
+// node if the cache is full; a snapshot older than the fence is ignored
+func (c *nodeCache) fill(txID int, node *types.Node) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if txID < c.fence {
+		return
+	}
+	if element, ok := c.entries[node.ID]; ok {
+		element.Value = cloneNode(node)
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[node.ID] = c.order.PushFront(cloneNode(node))
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*types.Node).ID)
+	}
+}
+
+// drop removes a node that write transaction txID is changing
+func (c *nodeCache) drop(txID int, id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.raiseFence(txID)
+	if element, ok := c.entries[id]; ok {
+		c.order.Remove(element)
+		delete(c.entries, id)
+	}
+}
+
+// purge removes every node, for a write transaction txID that replaces the whole nodes bucket
+func (c *nodeCache) purge(txID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.raiseFence(txID)
+	c.entries = make(map[string]*list.Element, c.size)
+	c.order.Init()
+}
+
+// reset empties the cache and lowers the fence, for a database file whose transaction IDs start
+// over (see Compact)
+func (c *nodeCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fence = 0
+	c.entries = make(map[string]*list.Element, c.size)
+	c.order.Init()
+}
+
+// raiseFence moves the fence up to txID
+func (c *nodeCache) raiseFence(txID int) {
+	if txID > c.fence {
+		c.fence = txID
+	}
+}
+
+// stats reports the cache's size and hit rate
+func (c *nodeCache) stats() *types.NodeCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := &types.NodeCacheStats{
+		Size:    c.size,
+		Entries: c.order.Len(),
+		Hits:    c.hits,
+		Misses:  c.misses,
+	}
+	if lookups := c.hits + c.misses; lookups > 0 {
+		stats.HitRate = float64(c.hits) / float64(lookups)
+	}
+	return stats
+}
+
+// cachedNodeRepo is a NodeRepo that serves Get from a nodeCache and drops the nodes that Put,
+// Delete and Clear change. Only read-only transactions fill the cache: a write transaction may
+// still roll back what it read
+type cachedNodeRepo struct {
+	NodeRepo
+	cache *nodeCache
+}
+
+// Get returns the node with the given ID from the cache, or reads and caches it
+func (r cachedNodeRepo) Get(tx *bbolt.Tx, id string) (*types.Node, error) {
+	if node, ok := r.cache.get(id); ok {
+		return node, nil
+	}
+	node, err := r.NodeRepo.Get(tx, id)
+	if err == nil && node != nil && !tx.Writable() {
+		r.cache.fill(tx.ID(), node)
+	}
+	return node, err
+}
+
+// Put stores a node, dropping its cached copy
+func (r cachedNodeRepo) Put(tx *bbolt.Tx, node *types.Node) (int64, error) {
+	r.cache.drop(tx.ID(), node.ID)
+	return r.NodeRepo.Put(tx, node)
+}
+
+// Delete removes a node record and its cached copy
+func (r cachedNodeRepo) Delete(tx *bbolt.Tx, id string) error {
+	r.cache.drop(tx.ID(), id)
+	return r.NodeRepo.Delete(tx, id)
+}
+
+// Clear removes every node record and empties the cache
+func (r cachedNodeRepo) Clear(tx *bbolt.Tx) error {
+	r.cache.purge(tx.ID())
+	return r.NodeRepo.Clear(tx)
+}
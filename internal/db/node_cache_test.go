@@ -0,0 +1,173 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// getNode reads a node, failing the test on error
+func getNode(tb testing.TB, database *DB, id string) *types.Node {
+	tb.Helper()
+	node, err := database.GetNodeByID(id)
+	if err != nil {
+		tb.Fatalf("get %s: %v", id, err)
+	}
+	return node
+}
+
+func TestNodeCacheHandsOutCopies(t *testing.T) {
+	database := newTestDB(t, Options{NodeCacheSize: 8})
+	folder := seedTree(t, database, 1, 0)[0]
+	before := database.nodeCache.stats()
+
+	first := getNode(t, database, folder.ID)
+	first.Name = "changed"
+	first.ExistenceMap["primary"] = false
+	if again := getNode(t, database, folder.ID); again.Name != folder.Name || !again.ExistenceMap["primary"] {
+		t.Errorf("changing a returned node changed the cached one: %+v", again)
+	}
+
+	// The first read missed and filled the cache, the second hit it
+	stats, err := database.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache := stats.NodeCache; cache == nil || cache.Hits != before.Hits+1 || cache.Misses != before.Misses+1 {
+		t.Errorf("cache stats %+v, before the reads %+v", stats.NodeCache, before)
+	}
+}
+
+func TestNodeCacheFollowsWrites(t *testing.T) {
+	database := newTestDB(t, Options{NodeCacheSize: 4})
+	root := rootNode(t, database)
+	folder := newNode(root, "fixed", types.NodeTypeFolder)
+	file := newNode(folder, "fixed.txt", types.NodeTypeFile)
+	if err := database.BulkInsertNodes(context.Background(), []*types.Node{folder, file}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range []struct {
+		name  string
+		write func() error
+	}{
+		{"seed", func() error {
+			seedTree(t, database, 3, 2)
+			return nil
+		}},
+		{"existence", func() error {
+			_, err := database.UpdateExistenceMaps([]ExistenceUpdate{{ID: folder.ID, ExistenceMap: map[string]bool{"primary": true}}})
+			return err
+		}},
+		{"insert", func() error {
+			return database.InsertNode(newNode(folder, "late.txt", types.NodeTypeFile))
+		}},
+		{"delete", func() error {
+			return database.DeleteNode(file.ID)
+		}},
+		{"reset", func() error {
+			return database.ResetNodes(context.Background(), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		}},
+	} {
+		// Read everything first, so the cache holds what the write is about to change
+		before := allNodeIDs(t, database)
+		for _, id := range before {
+			getNode(t, database, id)
+		}
+		if err := step.write(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+
+		// Every cached read matches the stored record, and removed nodes are gone
+		for _, id := range before {
+			var stored *types.Node
+			view(t, database, func(tx *bbolt.Tx) error {
+				var err error
+				stored, err = boltNodeRepo{}.Get(tx, id)
+				return err
+			})
+			got, err := database.GetNodeByID(id)
+			switch {
+			case stored == nil && err == nil:
+				t.Errorf("after %s, removed node %s is still served from the cache", step.name, id)
+			case stored != nil && !reflect.DeepEqual(got, stored):
+				t.Errorf("after %s, read of %s = %+v (%v), stored %+v", step.name, id, got, err, stored)
+			}
+		}
+	}
+}
+
+// allNodeIDs returns the ID of every stored node
+func allNodeIDs(tb testing.TB, database *DB) []string {
+	tb.Helper()
+	var ids []string
+	err := database.ScanNodes(context.Background(), "", func(node *types.Node) (bool, error) {
+		ids = append(ids, node.ID)
+		return true, nil
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return ids
+}
+
+func TestNodeCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newNodeCache(2)
+	for _, id := range []string{"a", "b"} {
+		cache.fill(1, &types.Node{ID: id})
+	}
+	cache.get("a") // b is now the least recently used
+	cache.fill(1, &types.Node{ID: "c"})
+
+	for id, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := cache.get(id); ok != want {
+			t.Errorf("%s cached = %v, want %v", id, ok, want)
+		}
+	}
+
+	// A snapshot older than a write that dropped entries cannot fill
+	cache.drop(5, "a")
+	cache.fill(4, &types.Node{ID: "a"})
+	if _, ok := cache.get("a"); ok {
+		t.Error("a fill from before the drop was cached")
+	}
+}
+
+// benchmarkCache runs read against databases with and without the node cache
+func benchmarkCache(b *testing.B, read func(b *testing.B, database *DB, folderID string)) {
+	for name, size := range map[string]int{"uncached": 0, "cached": 1024} {
+		b.Run(name, func(b *testing.B) {
+			database := newTestDB(b, Options{NodeCacheSize: size})
+			folder := seedTree(b, database, 1, 100)[0]
+			for b.Loop() {
+				read(b, database, folder.ID)
+			}
+		})
+	}
+}
+
+// BenchmarkGetNodeByIDRepeated reads the same folder over and over
+func BenchmarkGetNodeByIDRepeated(b *testing.B) {
+	benchmarkCache(b, func(b *testing.B, database *DB, folderID string) {
+		if _, err := database.GetNodeByID(folderID); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+// BenchmarkGetListingRepeated lists the same 100-file folder over and over, as ListChildren does
+func BenchmarkGetListingRepeated(b *testing.B) {
+	benchmarkCache(b, func(b *testing.B, database *DB, folderID string) {
+		listing, err := database.GetListing(folderID, "", "primary", nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(listing.Children) != 100 {
+			b.Fatalf("listed %d children", len(listing.Children))
+		}
+	})
+}
@@ -0,0 +1,358 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// Leading byte of a stored node record, naming its encoding. Records written before the binary
+// encoding existed are JSON objects and so start with '{'
+const (
+	nodeEncodingJSON   byte = '{'
+	nodeEncodingBinary byte = 0x01
+)
+
+// nodeMigrationBatchSize is how many node records MigrateNodeEncoding reads per transaction
+const nodeMigrationBatchSize = 10000
+
+// errNodeTruncated is returned when a binary node record ends before its last field
+var errNodeTruncated = errors.New("record is truncated")
+
+// encodeNode encodes a node in the binary encoding: the version byte followed by every field in
+// declaration order. Strings are length-prefixed, integers are varints, the timestamp uses
+// time.MarshalBinary, and maps are a count plus one (0 for nil) followed by their entries in key
+// order, so the same node always encodes to the same bytes
+func encodeNode(node *types.Node) ([]byte, error) {
+	lastUpdated, err := node.LastUpdated.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	size := 64 + len(node.ID) + len(node.ParentID) + len(node.Name) + len(node.Path) + len(node.ParentPath) +
+		len(node.Type) + len(node.TraversalStatus) + len(node.CopyStatus) + len(node.ContentID) + len(node.Target) +
+		len(lastUpdated) + 16*(len(node.ExistenceMap)+len(node.Metadata))
+	if node.Checksum != nil {
+		size += len(*node.Checksum)
+	}
+	buf := make([]byte, 0, size)
+
+	buf = append(buf, nodeEncodingBinary)
+	buf = appendString(buf, node.ID)
+	buf = appendString(buf, node.ParentID)
+	buf = appendString(buf, node.Name)
+	buf = appendString(buf, node.Path)
+	buf = appendString(buf, node.ParentPath)
+	buf = appendString(buf, node.Type)
+	buf = binary.AppendVarint(buf, int64(node.DepthLevel))
+	buf = binary.AppendVarint(buf, node.Size)
+	buf = appendString(buf, string(lastUpdated))
+	if node.Checksum == nil {
+		buf = append(buf, 0)
+	} else {
+		buf = append(buf, 1)
+		buf = appendString(buf, *node.Checksum)
+	}
+	buf = binary.AppendUvarint(buf, mapCount(len(node.ExistenceMap), node.ExistenceMap == nil))
+	for _, world := range sortedKeys(node.ExistenceMap) {
+		buf = appendString(buf, world)
+		buf = appendBool(buf, node.ExistenceMap[world])
+	}
+	buf = appendString(buf, node.TraversalStatus)
+	buf = appendString(buf, node.CopyStatus)
+	buf = appendString(buf, node.ContentID)
+	buf = appendString(buf, node.Target)
+	buf = binary.AppendUvarint(buf, mapCount(len(node.Metadata), node.Metadata == nil))
+	for _, key := range sortedKeys(node.Metadata) {
+		buf = appendString(buf, key)
+		buf = appendString(buf, node.Metadata[key])
+	}
+	return buf, nil
+}
+
+// decodeNode decodes a stored node record in either encoding
+func decodeNode(data []byte) (*types.Node, error) {
+	if len(data) == 0 {
+		return nil, errNodeTruncated
+	}
+
+	node := &types.Node{}
+	switch data[0] {
+	case nodeEncodingJSON:
+		if err := json.Unmarshal(data, node); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case nodeEncodingBinary:
+	default:
+		return nil, fmt.Errorf("unknown node encoding %#x", data[0])
+	}
+
+	r := nodeReader{data: data[1:]}
+	node.ID = r.string()
+	node.ParentID = r.string()
+	node.Name = r.string()
+	node.Path = r.string()
+	node.ParentPath = r.string()
+	node.Type = r.string()
+	node.DepthLevel = int(r.varint())
+	node.Size = r.varint()
+	if lastUpdated := r.bytes(); r.err == nil {
+		if err := node.LastUpdated.UnmarshalBinary(lastUpdated); err != nil {
+			return nil, err
+		}
+	}
+	if r.bool() {
+		checksum := r.string()
+		node.Checksum = &checksum
+	}
+	if count, ok := r.mapCount(); ok {
+		node.ExistenceMap = make(map[string]bool, count)
+		for i := 0; i < count && r.err == nil; i++ {
+			world := r.string()
+			node.ExistenceMap[world] = r.bool()
+		}
+	}
+	node.TraversalStatus = r.string()
+	node.CopyStatus = r.string()
+	node.ContentID = r.string()
+	node.Target = r.string()
+	if count, ok := r.mapCount(); ok {
+		node.Metadata = make(map[string]string, count)
+		for i := 0; i < count && r.err == nil; i++ {
+			key := r.string()
+			node.Metadata[key] = r.string()
+		}
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(r.data) > 0 {
+		return nil, fmt.Errorf("%d unexpected trailing bytes", len(r.data))
+	}
+	return node, nil
+}
+
+// appendString appends a length-prefixed string
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// appendBool appends a bool as one byte
+func appendBool(buf []byte, b bool) []byte {
+	if b {
+		return append(buf, 1)
+	}
+	return append(buf, 0)
+}
+
+// mapCount returns the stored count of a map: its length plus one, or 0 if it is nil
+func mapCount(length int, isNil bool) uint64 {
+	if isNil {
+		return 0
+	}
+	return uint64(length) + 1
+}
+
+// sortedKeys returns a map's keys in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// nodeReader reads the fields of a binary node record; the first error sticks and makes every
+// later read return the zero value
+type nodeReader struct {
+	data []byte
+	err  error
+}
+
+// uvarint reads an unsigned varint
+func (r *nodeReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	value, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = errNodeTruncated
+		return 0
+	}
+	r.data = r.data[n:]
+	return value
+}
+
+// varint reads a signed varint
+func (r *nodeReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	value, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.err = errNodeTruncated
+		return 0
+	}
+	r.data = r.data[n:]
+	return value
+}
+
+// bytes reads a length-prefixed byte string, which aliases the record
+func (r *nodeReader) bytes() []byte {
+	length := r.uvarint()
+	if r.err != nil {
+		return nil
+	}
+	if length > uint64(len(r.data)) {
+		r.err = errNodeTruncated
+		return nil
+	}
+	value := r.data[:length]
+	r.data = r.data[length:]
+	return value
+}
+
+// string reads a length-prefixed string
+func (r *nodeReader) string() string {
+	return string(r.bytes())
+}
+
+// bool reads a one-byte bool
+func (r *nodeReader) bool() bool {
+	if r.err != nil {
+		return false
+	}
+	if len(r.data) == 0 {
+		r.err = errNodeTruncated
+		return false
+	}
+	value := r.data[0] != 0
+	r.data = r.data[1:]
+	return value
+}
+
+// mapCount reads a map's stored count, reporting false for a nil map. The count is checked against
+// the bytes left, so a corrupt record cannot make the caller allocate a huge map
+func (r *nodeReader) mapCount() (int, bool) {
+	count := r.uvarint()
+	if r.err != nil || count == 0 {
+		return 0, false
+	}
+	if count-1 > uint64(len(r.data)) {
+		r.err = errNodeTruncated
+		return 0, false
+	}
+	return int(count - 1), true
+}
+
+// MigrateNodeEncoding rewrites every node record still stored as JSON in the binary encoding. Nodes
+// written since the binary encoding existed already use it, and Put rewrites a JSON record whenever
+// its node changes, so this only speeds up reads of nodes that are never updated. It works through
+// the bucket nodeMigrationBatchSize records per transaction, letting other writers in between, and
+// stops with ctx.Err() if ctx is cancelled; the batches already committed stay migrated, and running
+// it again carries on
+func (db *DB) MigrateNodeEncoding(ctx context.Context) (*types.NodeEncodingResult, error) {
+	started := time.Now()
+	result := &types.NodeEncodingResult{}
+
+	var after []byte
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		last, err := db.migrateNodeBatch(after, result)
+		if err != nil {
+			return nil, err
+		}
+		if last == nil {
+			break
+		}
+		after = last
+	}
+
+	result.MigratedAt = time.Now().UTC()
+	result.DurationMillis = time.Since(started).Milliseconds()
+	return result, nil
+}
+
+// migrateNodeBatch rewrites the JSON records among the next nodeMigrationBatchSize records after
+// the key after (nil starts at the first), counting them in result. Returns the last key read, or
+// nil once the bucket is exhausted
+func (db *DB) migrateNodeBatch(after []byte, result *types.NodeEncodingResult) ([]byte, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var last []byte
+	var nodes []*types.Node
+	var sizes []int64
+	scanned, rewritten := int64(0), int64(0)
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketNodes))
+		if bucket == nil {
+			return fmt.Errorf("[SpectraFS] nodes bucket does not exist")
+		}
+
+		// Decode the batch first: bbolt cursors are not valid across a Put
+		var keys [][]byte
+		var decoded []*types.Node
+		cursor := bucket.Cursor()
+		key, value := cursor.First()
+		if after != nil {
+			key, value = cursor.Seek(after)
+			if key != nil && bytes.Equal(key, after) {
+				key, value = cursor.Next()
+			}
+		}
+		for ; key != nil && scanned < nodeMigrationBatchSize; key, value = cursor.Next() {
+			scanned++
+			last = append([]byte(nil), key...)
+			if len(value) == 0 || value[0] != nodeEncodingJSON {
+				continue
+			}
+			node, err := decodeNode(value)
+			if err != nil {
+				continue // Reported when the record is next read
+			}
+			keys = append(keys, last)
+			decoded = append(decoded, node)
+		}
+
+		for i, node := range decoded {
+			data, err := encodeNode(node)
+			if err != nil {
+				return fmt.Errorf("[SpectraFS] failed to encode node %s: %w", keys[i], err)
+			}
+			if err := bucket.Put(keys[i], data); err != nil {
+				return fmt.Errorf("[SpectraFS] failed to store node %s: %w", keys[i], err)
+			}
+			rewritten++
+			if db.cache != nil {
+				nodes = append(nodes, node)
+				sizes = append(sizes, int64(len(data)))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Scanned += scanned
+	result.Rewritten += rewritten
+	for i, node := range nodes {
+		db.cache.update(node, sizes[i])
+	}
+	return last, nil
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// codecNodes are the nodes the codec tests round-trip, by case
+func codecNodes() map[string]*types.Node {
+	root := &types.Node{ID: "root", Path: "/", Type: types.NodeTypeFolder, ExistenceMap: map[string]bool{"primary": true}}
+	full := newNode(root, "a.txt", types.NodeTypeFile)
+	full.Metadata = map[string]string{"owner": "ann", "content-type": "text/plain"}
+	full.ContentID = "other"
+	full.LastUpdated = time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.FixedZone("CET", 3600))
+
+	folder := newNode(root, "folder", types.NodeTypeFolder) // Checksum nil
+
+	emptyMaps := newNode(root, "empty", types.NodeTypeFolder)
+	emptyMaps.ExistenceMap = map[string]bool{}
+	emptyMaps.Metadata = map[string]string{}
+
+	nilMaps := newNode(root, "nil", types.NodeTypeFolder)
+	nilMaps.ExistenceMap = nil
+
+	unicode := newNode(root, "日本語 ñandú 🙂.txt", types.NodeTypeFile)
+	unicode.Metadata = map[string]string{"clé": "välue ✓"}
+
+	symlink := newNode(root, "link", types.NodeTypeSymlink)
+	symlink.Target = "../a.txt"
+	symlink.Size = int64(len(symlink.Target))
+
+	return map[string]*types.Node{
+		"full":         full,
+		"nil checksum": folder,
+		"empty maps":   emptyMaps,
+		"nil maps":     nilMaps,
+		"unicode":      unicode,
+		"symlink":      symlink,
+		"zero":         {},
+	}
+}
+
+// sameNode reports whether got decodes want, comparing timestamps by instant
+func sameNode(got, want *types.Node) bool {
+	if !got.LastUpdated.Equal(want.LastUpdated) {
+		return false
+	}
+	copied := *got
+	copied.LastUpdated = want.LastUpdated
+	return reflect.DeepEqual(&copied, want)
+}
+
+func TestNodeCodecRoundTrip(t *testing.T) {
+	for name, node := range codecNodes() {
+		t.Run(name, func(t *testing.T) {
+			data, err := encodeNode(node)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data[0] != nodeEncodingBinary {
+				t.Errorf("record starts with %#x, want the binary version byte", data[0])
+			}
+			if again, _ := encodeNode(node); !bytes.Equal(again, data) {
+				t.Error("encoding the same node twice gave different bytes")
+			}
+			got, err := decodeNode(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sameNode(got, node) {
+				t.Errorf("binary round trip = %+v, want %+v", got, node)
+			}
+
+			// Records written as JSON before the binary encoding still read
+			legacy, err := json.Marshal(node)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err = decodeNode(legacy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ID != node.ID || got.Name != node.Name || !reflect.DeepEqual(got.Checksum, node.Checksum) || !got.LastUpdated.Equal(node.LastUpdated) {
+				t.Errorf("JSON record read as %+v, want %+v", got, node)
+			}
+
+			// A truncated record is an error, not a partial node
+			if _, err := decodeNode(data[:len(data)-1]); err == nil {
+				t.Error("decoding a truncated record succeeded")
+			}
+		})
+	}
+}
+
+func TestMigrateNodeEncoding(t *testing.T) {
+	database := newTestDB(t, Options{})
+	nodes := seedTree(t, database, 3, 2)
+
+	// Store every record as JSON, the way databases written before the binary encoding hold them
+	update(t, database, func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketNodes))
+		for _, node := range nodes {
+			data, err := json.Marshal(node)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(node.ID), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	// A changed node is rewritten in the binary encoding on its own
+	touched, err := database.TouchNode(nodes[0].ID, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoding := storedEncoding(t, database, touched.ID); encoding != nodeEncodingBinary {
+		t.Errorf("updated record encoded as %q, want binary", encoding)
+	}
+
+	result, err := database.MigrateNodeEncoding(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Rewritten != int64(len(nodes)-1) || result.Scanned != int64(len(nodes)+1) {
+		t.Errorf("migration %+v, want %d of %d records rewritten", result, len(nodes)-1, len(nodes)+1)
+	}
+	for _, node := range nodes[1:] {
+		if encoding := storedEncoding(t, database, node.ID); encoding != nodeEncodingBinary {
+			t.Errorf("%s encoded as %q after migrating", node.Path, encoding)
+		}
+		if got := getNode(t, database, node.ID); !sameNode(got, node) {
+			t.Errorf("%s read as %+v after migrating, want %+v", node.Path, got, node)
+		}
+	}
+
+	if again, err := database.MigrateNodeEncoding(context.Background()); err != nil || again.Rewritten != 0 {
+		t.Errorf("second migration %+v, %v, want nothing rewritten", again, err)
+	}
+}
+
+// storedEncoding returns the version byte of a stored node record
+func storedEncoding(tb testing.TB, database *DB, id string) byte {
+	tb.Helper()
+	var encoding byte
+	view(tb, database, func(tx *bbolt.Tx) error {
+		encoding = tx.Bucket([]byte(bucketNodes)).Get([]byte(id))[0]
+		return nil
+	})
+	return encoding
+}
+
+// BenchmarkNodeCodec encodes and decodes a file node with the binary encoding and with JSON
+func BenchmarkNodeCodec(b *testing.B) {
+	node := codecNodes()["full"]
+	binaryData, err := encodeNode(node)
+	if err != nil {
+		b.Fatal(err)
+	}
+	jsonData, err := json.Marshal(node)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("encode/binary", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := encodeNode(node); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("encode/json", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := json.Marshal(node); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("decode/binary", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := decodeNode(binaryData); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("decode/json", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := decodeNode(jsonData); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
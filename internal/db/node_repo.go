@@ -0,0 +1,180 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// NodeRepo owns the nodes bucket: encoded node records keyed by node ID (see node_codec.go)
+// Methods run inside a caller-supplied transaction and never lock
+type NodeRepo interface {
+	// Get returns the node with the given ID, or nil if it does not exist
+	Get(tx *bbolt.Tx, id string) (*types.Node, error)
+	// Exists reports whether a node with the given ID is stored
+	Exists(tx *bbolt.Tx, id string) (bool, error)
+	// Put stores a node, replacing any existing record, and returns its encoded size
+	Put(tx *bbolt.Tx, node *types.Node) (int64, error)
+	// Delete removes a node record
+	Delete(tx *bbolt.Tx, id string) error
+	// ForEach visits every node with its encoded size; a record that fails to decode is an error
+	ForEach(tx *bbolt.Tx, fn func(node *types.Node, size int64) error) error
+	// Scan visits nodes with IDs strictly after afterID in ID order until fn returns false
+	Scan(tx *bbolt.Tx, afterID string, fn func(node *types.Node) (bool, error)) error
+	// Clear removes every node record
+	Clear(tx *bbolt.Tx) error
+}
+
+// boltNodeRepo is the BoltDB implementation of NodeRepo
+type boltNodeRepo struct{}
+
+// bucket returns the nodes bucket
+func (r boltNodeRepo) bucket(tx *bbolt.Tx) (*bbolt.Bucket, error) {
+	bucket := tx.Bucket([]byte(bucketNodes))
+	if bucket == nil {
+		return nil, fmt.Errorf("[SpectraFS] nodes bucket does not exist")
+	}
+	return bucket, nil
+}
+
+// Get returns the node with the given ID, or nil if it does not exist
+func (r boltNodeRepo) Get(tx *bbolt.Tx, id string) (*types.Node, error) {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	data := bucket.Get([]byte(id))
+	if data == nil {
+		return nil, nil
+	}
+
+	node, err := decodeNode(data)
+	if err != nil {
+		return nil, fmt.Errorf("[SpectraFS] failed to decode node %s: %w", id, err)
+	}
+	return node, nil
+}
+
+// Exists reports whether a node with the given ID is stored
+func (r boltNodeRepo) Exists(tx *bbolt.Tx, id string) (bool, error) {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return false, err
+	}
+	return bucket.Get([]byte(id)) != nil, nil
+}
+
+// Put stores a node in the binary encoding, replacing any existing record (a JSON one included),
+// and returns its encoded size
+func (r boltNodeRepo) Put(tx *bbolt.Tx, node *types.Node) (int64, error) {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := encodeNode(node)
+	if err != nil {
+		return 0, fmt.Errorf("[SpectraFS] failed to encode node %s: %w", node.ID, err)
+	}
+
+	if err := bucket.Put([]byte(node.ID), data); err != nil {
+		return 0, fmt.Errorf("[SpectraFS] failed to store node %s: %w", node.ID, err)
+	}
+	return int64(len(data)), nil
+}
+
+// Delete removes a node record
+func (r boltNodeRepo) Delete(tx *bbolt.Tx, id string) error {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return err
+	}
+	if err := bucket.Delete([]byte(id)); err != nil {
+		return fmt.Errorf("[SpectraFS] failed to delete node %s: %w", id, err)
+	}
+	return nil
+}
+
+// ForEach visits every node with its encoded size
+// A record that fails to decode stops the walk with an error naming it
+func (r boltNodeRepo) ForEach(tx *bbolt.Tx, fn func(node *types.Node, size int64) error) error {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return err
+	}
+
+	return bucket.ForEach(func(key, value []byte) error {
+		node, err := decodeNode(value)
+		if err != nil {
+			return fmt.Errorf("[SpectraFS] failed to decode node %s: %w", key, err)
+		}
+		return fn(node, int64(len(value)))
+	})
+}
+
+// Scan visits nodes with IDs strictly after afterID in ID order until fn returns false
+// A record that fails to decode stops the scan with an error naming it
+func (r boltNodeRepo) Scan(tx *bbolt.Tx, afterID string, fn func(node *types.Node) (bool, error)) error {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return err
+	}
+
+	c := bucket.Cursor()
+	key, value := c.Seek([]byte(afterID))
+	if key != nil && string(key) == afterID {
+		key, value = c.Next()
+	}
+	for ; key != nil; key, value = c.Next() {
+		node, err := decodeNode(value)
+		if err != nil {
+			return fmt.Errorf("[SpectraFS] failed to decode node %s: %w", key, err)
+		}
+		more, err := fn(node)
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clear removes every node record
+func (r boltNodeRepo) Clear(tx *bbolt.Tx) error {
+	if err := clearBucket(tx, bucketNodes); err != nil {
+		return fmt.Errorf("[SpectraFS] failed to delete nodes: %w", err)
+	}
+	return nil
+}
+
+// clearBucket empties a bucket by dropping and recreating it
+func clearBucket(tx *bbolt.Tx, name string) error {
+	if err := tx.DeleteBucket([]byte(name)); err != nil && err != bbolt.ErrBucketNotFound {
+		return err
+	}
+	_, err := tx.CreateBucket([]byte(name))
+	return err
+}
+
+// emptyBucket empties a bucket key by key, deleting nested buckets too, and resets its sequence
+func emptyBucket(tx *bbolt.Tx, name string) error {
+	bucket := tx.Bucket([]byte(name))
+	if bucket == nil {
+		_, err := tx.CreateBucket([]byte(name))
+		return err
+	}
+	cursor := bucket.Cursor()
+	for key, value := cursor.First(); key != nil; key, value = cursor.First() {
+		var err error
+		if value == nil {
+			err = bucket.DeleteBucket(key)
+		} else {
+			err = cursor.Delete()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return bucket.SetSequence(0)
+}
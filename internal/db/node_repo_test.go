@@ -0,0 +1,131 @@
+package db
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+func TestNodeRepoRoundTrip(t *testing.T) {
+	database := newTestDB(t, Options{})
+	repo := boltNodeRepo{}
+	node := newNode(rootNode(t, database), "a.txt", types.NodeTypeFile)
+	node.Metadata = map[string]string{"owner": "ann"}
+
+	var size int64
+	update(t, database, func(tx *bbolt.Tx) error {
+		var err error
+		size, err = repo.Put(tx, node)
+		return err
+	})
+	if size <= 0 {
+		t.Errorf("Put size = %d", size)
+	}
+
+	view(t, database, func(tx *bbolt.Tx) error {
+		got, err := repo.Get(tx, node.ID)
+		if err != nil {
+			return err
+		}
+		if !got.LastUpdated.Equal(node.LastUpdated) {
+			t.Errorf("LastUpdated = %v, want %v", got.LastUpdated, node.LastUpdated)
+		}
+		got.LastUpdated = node.LastUpdated
+		if !reflect.DeepEqual(got, node) {
+			t.Errorf("Get = %+v, want %+v", got, node)
+		}
+		if exists, err := repo.Exists(tx, node.ID); err != nil || !exists {
+			t.Errorf("Exists = %v, %v", exists, err)
+		}
+		missing, err := repo.Get(tx, "missing")
+		if err != nil || missing != nil {
+			t.Errorf("Get(missing) = %v, %v; want nil, nil", missing, err)
+		}
+		return nil
+	})
+
+	update(t, database, func(tx *bbolt.Tx) error { return repo.Delete(tx, node.ID) })
+	view(t, database, func(tx *bbolt.Tx) error {
+		if exists, err := repo.Exists(tx, node.ID); err != nil || exists {
+			t.Errorf("Exists after Delete = %v, %v", exists, err)
+		}
+		return nil
+	})
+}
+
+func TestNodeRepoScanOrder(t *testing.T) {
+	database := newTestDB(t, Options{})
+	repo := boltNodeRepo{}
+	nodes := seedTree(t, database, 3, 2)
+
+	var ids []string
+	view(t, database, func(tx *bbolt.Tx) error {
+		return repo.Scan(tx, "", func(node *types.Node) (bool, error) {
+			ids = append(ids, node.ID)
+			return true, nil
+		})
+	})
+	if len(ids) != len(nodes)+1 { // The root too
+		t.Fatalf("Scan visited %d nodes, want %d", len(ids), len(nodes)+1)
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i-1] >= ids[i] {
+			t.Fatalf("Scan out of ID order: %s then %s", ids[i-1], ids[i])
+		}
+	}
+
+	var after []string
+	view(t, database, func(tx *bbolt.Tx) error {
+		return repo.Scan(tx, ids[2], func(node *types.Node) (bool, error) {
+			after = append(after, node.ID)
+			return len(after) < 2, nil
+		})
+	})
+	if !reflect.DeepEqual(after, ids[3:5]) {
+		t.Errorf("Scan after %s = %v, want %v", ids[2], after, ids[3:5])
+	}
+}
+
+func TestNodeRepoReportsUndecodableRecords(t *testing.T) {
+	database := newTestDB(t, Options{})
+	repo := boltNodeRepo{}
+	seedTree(t, database, 1, 1)
+	update(t, database, func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(bucketNodes)).Put([]byte("corrupt"), []byte{0xff, 0x00, 0x01})
+	})
+
+	for name, walk := range map[string]func(tx *bbolt.Tx) error{
+		"ForEach": func(tx *bbolt.Tx) error {
+			return repo.ForEach(tx, func(*types.Node, int64) error { return nil })
+		},
+		"Scan": func(tx *bbolt.Tx) error {
+			return repo.Scan(tx, "", func(*types.Node) (bool, error) { return true, nil })
+		},
+		"Get": func(tx *bbolt.Tx) error {
+			_, err := repo.Get(tx, "corrupt")
+			return err
+		},
+	} {
+		err := database.withViewTx(walk)
+		if err == nil || !strings.Contains(err.Error(), "corrupt") {
+			t.Errorf("%s error = %v, want a decode error naming the record", name, err)
+		}
+	}
+}
+
+func TestNodeRepoClear(t *testing.T) {
+	database := newTestDB(t, Options{})
+	repo := boltNodeRepo{}
+	seedTree(t, database, 2, 2)
+
+	update(t, database, func(tx *bbolt.Tx) error { return repo.Clear(tx) })
+	view(t, database, func(tx *bbolt.Tx) error {
+		return repo.ForEach(tx, func(node *types.Node, _ int64) error {
+			t.Errorf("node %s left after Clear", node.Path)
+			return nil
+		})
+	})
+}
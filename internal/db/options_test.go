@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestNoSyncSurvivesCloseAndReopen(t *testing.T) {
+	for _, freelist := range []string{types.FreelistArray, types.FreelistMap} {
+		t.Run(freelist, func(t *testing.T) {
+			path := tempDBPath(t)
+			database, err := New(path, testWorlds, Options{
+				NoSync:          true,
+				FreelistType:    freelist,
+				InitialMmapSize: 1 << 20,
+				BulkBatchSize:   7, // Splits the 120 nodes below across transactions
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			seedTree(t, database, 20, 5)
+			want := storedStats(t, database)
+			if err := database.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			reopened := openTestDB(t, path, Options{})
+			if got := storedStats(t, reopened); got.TotalNodes != 120 || got.FileCount != want.FileCount || got.TotalFileSize != want.TotalFileSize {
+				t.Errorf("stats after reopening %+v, before closing %+v", got, want)
+			}
+			folders, err := reopened.GetChildrenByParentID("root", "primary")
+			if err != nil || len(folders) != 20 {
+				t.Fatalf("root lists %d folders after reopening (%v), want 20", len(folders), err)
+			}
+			if files, err := reopened.GetChildrenByParentID(folders[19].ID, "primary"); err != nil || len(files) != 5 {
+				t.Errorf("last folder lists %d files after reopening (%v), want 5", len(files), err)
+			}
+			report, err := reopened.CheckIntegrity(context.Background(), false)
+			if err != nil || !report.OK {
+				t.Errorf("integrity after reopening: %+v, %v", report, err)
+			}
+		})
+	}
+}
+
+// BenchmarkBulkInsert inserts a folder of 1000 files per run into a database file, with and
+// without fsync on commit
+func BenchmarkBulkInsert(b *testing.B) {
+	for name, opts := range map[string]Options{
+		"sync":    {},
+		"no_sync": {NoSync: true},
+	} {
+		b.Run(name, func(b *testing.B) {
+			database := openTestDB(b, tempDBPath(b), opts)
+			root := rootNode(b, database)
+			run := 0
+			for b.Loop() {
+				folder := newNode(root, fmt.Sprintf("run-%06d", run), types.NodeTypeFolder)
+				nodes := []*types.Node{folder}
+				for i := range 1000 {
+					nodes = append(nodes, newNode(folder, fmt.Sprintf("file-%04d.txt", i), types.NodeTypeFile))
+				}
+				if err := database.BulkInsertNodes(context.Background(), nodes); err != nil {
+					b.Fatal(err)
+				}
+				run++
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package db
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// indexEntryOverhead approximates the per-entry cost of Go map storage on top of the key bytes
+const indexEntryOverhead = 48
+
+// preloadCache is the in-memory warm-start copy of the primary index structures
+// "index" mode keeps parentID -> childIDs; "full" mode additionally keeps every decoded node.
+// Every mutation path in DB keeps it coherent through the add/update/move/remove/clear hooks
+// below. The hooks and readers take the cache's own lock, since lock-free DB reads use it
+// concurrently with writers; the lowercase helpers they share assume it is already held.
+type preloadCache struct {
+	mu sync.RWMutex // Guards everything below once the cache is published
+
+	mode       string // Active mode; drops from "full" to "index" if growth hits the cap
+	requested  string // Mode the cache was preloaded with
+	maxBytes   int64
+	startup    time.Duration
+	downgraded time.Time    // When the cap dropped the node cache (zero if it has not)
+	logger     *slog.Logger // Warned when the cap drops the node cache (see DB.SetLogger)
+
+	children  map[string]map[string]struct{} // parentID -> set of child IDs (all worlds)
+	nodes     map[string]*types.Node         // nodeID -> decoded node ("full" mode only)
+	nodeBytes map[string]int64               // nodeID -> encoded size, for memory accounting
+	bytes     int64
+}
+
+// Preload warms the in-memory cache according to mode ("none", "index" or "full")
+// In "full" mode it refuses to finish if the decoded nodes would exceed maxBytes (0 = unlimited)
+// Call it before the DB is shared: lock-free readers load db.cache without db.mu
+func (db *DB) Preload(mode string, maxBytes int64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if mode == "" || mode == types.PreloadNone {
+		db.cache = nil
+		return nil
+	}
+	if mode != types.PreloadIndex && mode != types.PreloadFull {
+		return fmt.Errorf("[SpectraFS] unknown preload mode %q", mode)
+	}
+
+	started := time.Now()
+	cache := &preloadCache{
+		mode:      mode,
+		requested: mode,
+		maxBytes:  maxBytes,
+		logger:    db.logger,
+		children:  make(map[string]map[string]struct{}),
+	}
+	if mode == types.PreloadFull {
+		cache.nodes = make(map[string]*types.Node)
+		cache.nodeBytes = make(map[string]int64)
+	}
+
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		if err := db.index.ForEachChildLink(tx, func(parentID, childID string) error {
+			cache.addChild(parentID, childID)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if mode != types.PreloadFull {
+			return nil
+		}
+
+		return db.nodes.ForEach(tx, func(node *types.Node, size int64) error {
+			cache.putNode(node, size)
+			if cache.overCap() {
+				return fmt.Errorf("[SpectraFS] full preload exceeds preload_max_bytes (%d bytes)", maxBytes)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	cache.startup = time.Since(started)
+	db.cache = cache
+	return nil
+}
+
+// overCap reports whether the cache has grown past its configured memory cap
+func (c *preloadCache) overCap() bool {
+	return c.maxBytes > 0 && c.bytes > c.maxBytes
+}
+
+// addChild records childID under parentID
+func (c *preloadCache) addChild(parentID, childID string) {
+	set, ok := c.children[parentID]
+	if !ok {
+		set = make(map[string]struct{})
+		c.children[parentID] = set
+	}
+	if _, exists := set[childID]; !exists {
+		set[childID] = struct{}{}
+		c.bytes += int64(len(parentID)+len(childID)) + indexEntryOverhead
+	}
+}
+
+// removeChild drops childID from parentID's child set
+func (c *preloadCache) removeChild(parentID, childID string) {
+	set, ok := c.children[parentID]
+	if !ok {
+		return
+	}
+	if _, exists := set[childID]; exists {
+		delete(set, childID)
+		c.bytes -= int64(len(parentID)+len(childID)) + indexEntryOverhead
+	}
+	if len(set) == 0 {
+		delete(c.children, parentID)
+	}
+}
+
+// putNode stores or replaces a decoded node in "full" mode
+func (c *preloadCache) putNode(node *types.Node, size int64) {
+	if c.nodes == nil {
+		return
+	}
+	c.bytes += size - c.nodeBytes[node.ID]
+	c.nodes[node.ID] = cloneNode(node)
+	c.nodeBytes[node.ID] = size
+}
+
+// dropNode removes a decoded node in "full" mode
+func (c *preloadCache) dropNode(id string) {
+	if c.nodes == nil {
+		return
+	}
+	c.bytes -= c.nodeBytes[id]
+	delete(c.nodes, id)
+	delete(c.nodeBytes, id)
+}
+
+// add is the invalidation hook for a newly inserted node
+func (c *preloadCache) add(node *types.Node, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.addChild(node.ParentID, node.ID)
+	c.putNode(node, size)
+	c.enforceCap()
+}
+
+// update is the invalidation hook for a node rewritten in place
+func (c *preloadCache) update(node *types.Node, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.putNode(node, size)
+	c.enforceCap()
+}
+
+// move is the invalidation hook for a node re-parented or renamed by MoveSubtree or RenameSubtree
+func (c *preloadCache) move(old, node *types.Node, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.removeChild(old.ParentID, old.ID)
+	c.addChild(node.ParentID, node.ID)
+	c.putNode(node, size)
+	c.enforceCap()
+}
+
+// remove is the invalidation hook for a deleted node
+func (c *preloadCache) remove(node *types.Node) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.removeChild(node.ParentID, node.ID)
+	c.dropNode(node.ID)
+}
+
+// clear is the invalidation hook for a full reset
+func (c *preloadCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.children = make(map[string]map[string]struct{})
+	c.bytes = 0
+	if c.nodes != nil {
+		c.nodes = make(map[string]*types.Node)
+		c.nodeBytes = make(map[string]int64)
+	}
+}
+
+// reload is the invalidation hook for Repair: it replaces everything with nodes (and their encoded sizes)
+func (c *preloadCache) reload(nodes []*types.Node, sizes []int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.children = make(map[string]map[string]struct{})
+	c.bytes = 0
+	if c.nodes != nil {
+		c.nodes = make(map[string]*types.Node)
+		c.nodeBytes = make(map[string]int64)
+	}
+	for i, node := range nodes {
+		c.addChild(node.ParentID, node.ID)
+		c.putNode(node, sizes[i])
+	}
+	c.enforceCap()
+}
+
+// enforceCap drops the node cache (falling back to "index" mode) if growth after startup
+// pushed it past the memory cap, so the cache stays bounded without refusing writes. The drop is
+// logged as a warning and reported in stats, since reads then go back to BoltDB
+func (c *preloadCache) enforceCap() {
+	if c.nodes == nil || !c.overCap() {
+		return
+	}
+	c.logger.Warn("preload cache exceeded preload_max_bytes, falling back to index mode",
+		"bytes", c.bytes, "max_bytes", c.maxBytes, "cached_nodes", len(c.nodes))
+
+	for _, size := range c.nodeBytes {
+		c.bytes -= size
+	}
+	c.nodes = nil
+	c.nodeBytes = nil
+	c.mode = types.PreloadIndex
+	c.downgraded = time.Now()
+}
+
+// setLogger replaces the logger warned when the cap drops the node cache
+func (c *preloadCache) setLogger(logger *slog.Logger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.logger = logger
+}
+
+// childIDs returns the cached child IDs of a parent (all worlds)
+func (c *preloadCache) childIDs(parentID string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	set := c.children[parentID]
+	ids := make([]string, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// node returns a copy of a cached node; ok is false if the node is not cached
+func (c *preloadCache) node(id string) (*types.Node, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.nodes == nil {
+		return nil, false
+	}
+	node, ok := c.nodes[id]
+	if !ok {
+		return nil, false
+	}
+	return cloneNode(node), true
+}
+
+// stats reports the cache's current footprint
+func (c *preloadCache) stats() *types.PreloadStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stats := &types.PreloadStats{
+		Mode:           c.mode,
+		RequestedMode:  c.requested,
+		StartupMillis:  c.startup.Milliseconds(),
+		Bytes:          c.bytes,
+		IndexedParents: len(c.children),
+		CachedNodes:    len(c.nodes),
+	}
+	if !c.downgraded.IsZero() {
+		downgraded := c.downgraded
+		stats.DowngradedAt = &downgraded
+	}
+	return stats
+}
+
+// cloneNode copies a node so cached values are never shared with callers
+func cloneNode(node *types.Node) *types.Node {
+	clone := *node
+	clone.ExistenceMap = maps.Clone(node.ExistenceMap)
+	clone.Metadata = maps.Clone(node.Metadata)
+	if node.Checksum != nil {
+		checksum := *node.Checksum
+		clone.Checksum = &checksum
+	}
+	return &clone
+}
+
+// loadNodeTx reads a node by ID, preferring the warm cache when one is loaded
+// Returns nil without error if the node does not exist
+// Safe without db.mu: the cache takes its own lock
+func (db *DB) loadNodeTx(tx *bbolt.Tx, id string) (*types.Node, error) {
+	if db.cache != nil {
+		if node, ok := db.cache.node(id); ok {
+			return node, nil
+		}
+	}
+
+	return db.nodes.Get(tx, id)
+}
+
+// childIDsTx returns the IDs of every child of parentID, preferring the warm cache when one is loaded
+// Safe without db.mu: the cache takes its own lock
+func (db *DB) childIDsTx(tx *bbolt.Tx, parentID string) ([]string, error) {
+	if db.cache != nil {
+		return db.cache.childIDs(parentID), nil
+	}
+
+	return db.index.ChildIDs(tx, parentID)
+}
@@ -0,0 +1,161 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+var preloadModes = []string{types.PreloadNone, types.PreloadIndex, types.PreloadFull}
+
+// checkCacheCoherent compares every cached node and child list with what is stored in BoltDB
+func checkCacheCoherent(t *testing.T, database *DB) {
+	t.Helper()
+	err := database.withViewTx(func(tx *bbolt.Tx) error {
+		return database.nodes.ForEach(tx, func(stored *types.Node, size int64) error {
+			cached, err := database.loadNodeTx(tx, stored.ID)
+			if err != nil {
+				return err
+			}
+			if cached == nil || cached.Path != stored.Path || cached.ParentID != stored.ParentID ||
+				cached.ExistenceMap["s1"] != stored.ExistenceMap["s1"] || cached.Metadata["tag"] != stored.Metadata["tag"] {
+				t.Errorf("node %s: cached %+v, stored %+v", stored.ID, cached, stored)
+			}
+
+			cachedChildren, err := database.childIDsTx(tx, stored.ID)
+			if err != nil {
+				return err
+			}
+			storedChildren, err := database.index.ChildIDs(tx, stored.ID)
+			if err != nil {
+				return err
+			}
+			slices.Sort(cachedChildren)
+			slices.Sort(storedChildren)
+			if !slices.Equal(cachedChildren, storedChildren) {
+				t.Errorf("children of %s: cached %v, stored %v", stored.Path, cachedChildren, storedChildren)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPreloadCoherentAfterMutations(t *testing.T) {
+	for _, mode := range preloadModes {
+		t.Run(mode, func(t *testing.T) {
+			database := newTestDB(t, Options{})
+			nodes := seedTree(t, database, 4, 3)
+			if err := database.Preload(mode, 0); err != nil {
+				t.Fatal(err)
+			}
+			checkCacheCoherent(t, database)
+
+			folder, file := nodes[0], nodes[1]
+			if err := database.InsertNode(newNode(folder, "added.txt", types.NodeTypeFile)); err != nil {
+				t.Fatal(err)
+			}
+			if err := database.UpdateExistenceMap(file.ID, map[string]bool{"primary": true, "s1": false}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := database.UpdateMetadata(nodes[2].ID, map[string]string{"tag": "x"}, false); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := database.MoveSubtree(nodes[4].ID, folder.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := database.RenameSubtree(folder.ID, "renamed"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := database.DeleteSubtree(nodes[8].ID); err != nil {
+				t.Fatal(err)
+			}
+			checkCacheCoherent(t, database)
+
+			if err := database.Repair(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			checkCacheCoherent(t, database)
+		})
+	}
+}
+
+func TestPreloadFullRefusesOverCap(t *testing.T) {
+	database := newTestDB(t, Options{})
+	seedTree(t, database, 10, 10)
+
+	if err := database.Preload(types.PreloadFull, 1024); err == nil {
+		t.Fatal("full preload past the cap succeeded")
+	}
+}
+
+func TestPreloadCapDowngradeIsReported(t *testing.T) {
+	database := newTestDB(t, Options{})
+	nodes := seedTree(t, database, 2, 2)
+	var logs bytes.Buffer
+	database.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	if err := database.Preload(types.PreloadFull, 0); err != nil {
+		t.Fatal(err)
+	}
+	startup := database.cache.stats().Bytes
+	database.cache.maxBytes = startup + 2048 // Room for a few more nodes, not dozens
+
+	for i := 0; database.cache.stats().Mode == types.PreloadFull; i++ {
+		if i == 100 {
+			t.Fatal("cache never reached its cap")
+		}
+		if err := database.InsertNode(newNode(nodes[0], fmt.Sprintf("growth-%03d.txt", i), types.NodeTypeFile)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := database.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Preload.Mode != types.PreloadIndex || stats.Preload.RequestedMode != types.PreloadFull {
+		t.Errorf("preload modes = %q (requested %q), want index (requested full)", stats.Preload.Mode, stats.Preload.RequestedMode)
+	}
+	if stats.Preload.DowngradedAt == nil || stats.Preload.CachedNodes != 0 {
+		t.Errorf("downgrade not reported: %+v", stats.Preload)
+	}
+	if !strings.Contains(logs.String(), "level=WARN") || !strings.Contains(logs.String(), "falling back to index mode") {
+		t.Errorf("downgrade not logged: %q", logs.String())
+	}
+	checkCacheCoherent(t, database)
+}
+
+// BenchmarkPreloadReads compares node and listing reads cold (no preload) against the warm modes
+func BenchmarkPreloadReads(b *testing.B) {
+	for _, mode := range preloadModes {
+		b.Run(mode, func(b *testing.B) {
+			database := openTestDB(b, tempDBPath(b), Options{NoSync: true})
+			nodes := seedTree(b, database, 100, 50)
+			if err := database.Preload(mode, 0); err != nil {
+				b.Fatal(err)
+			}
+			random := rand.New(rand.NewPCG(1, 2))
+
+			for b.Loop() {
+				node := nodes[random.IntN(len(nodes))]
+				if _, err := database.GetNodeByID(node.ID); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := database.GetChildrenByParentID(node.ParentID, "primary"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
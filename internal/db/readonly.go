@@ -0,0 +1,90 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// readOnlyLockTimeout bounds the wait for the shared lock before NewReadOnly reads a copy instead;
+// a writer holds its lock for as long as it has the file open, so waiting longer would not help
+const readOnlyLockTimeout = 100 * time.Millisecond
+
+// snapshotAttempts is how many copies NewReadOnly takes of a file a writer holds before giving up
+const snapshotAttempts = 3
+
+// openReadOnly opens dbPath with bbolt's ReadOnly option under a shared lock or, when a writer
+// holds the file, opens a private in-memory copy of it (see openSnapshot). Returns the cleanup to
+// run once the database is closed and whether the copy was opened
+func openReadOnly(dbPath string, opts Options) (*bbolt.DB, func(), bool, error) {
+	options := opts.boltOptions()
+	options.ReadOnly = true
+	options.Timeout = readOnlyLockTimeout
+	boltDB, err := bbolt.Open(dbPath, 0600, options)
+	if !errors.Is(err, bbolt.ErrTimeout) {
+		return boltDB, nil, false, err
+	}
+
+	for range snapshotAttempts {
+		var cleanup func()
+		boltDB, cleanup, err = openSnapshot(dbPath, options)
+		if err == nil {
+			return boltDB, cleanup, true, nil
+		}
+	}
+	return nil, nil, false, fmt.Errorf("[SpectraFS] %s is open for writing and no consistent copy of it could be read: %w", dbPath, err)
+}
+
+// openSnapshot copies dbPath into an in-memory file and opens the copy read-only. The writer may
+// commit while the file is copied, so the copy is only accepted once bbolt's consistency check passes
+func openSnapshot(dbPath string, options *bbolt.Options) (*bbolt.DB, func(), error) {
+	source, err := os.Open(dbPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer source.Close()
+
+	memory, remove, err := openMemoryFile()
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		memory.Close()
+		if remove != nil {
+			remove()
+		}
+	}
+	if _, err := io.Copy(memory, source); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("[SpectraFS] failed to copy %s: %w", dbPath, err)
+	}
+
+	snapshot := *options
+	snapshot.OpenFile = func(string, int, os.FileMode) (*os.File, error) {
+		return reopenMemoryFile(memory, os.O_RDONLY, 0)
+	}
+	boltDB, err := bbolt.Open(dbPath, 0600, &snapshot)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	err = boltDB.View(func(tx *bbolt.Tx) error {
+		var first error
+		for err := range tx.Check() { // Drained, since the check runs until its channel closes
+			if first == nil {
+				first = err
+			}
+		}
+		return first
+	})
+	if err != nil {
+		boltDB.Close()
+		cleanup()
+		return nil, nil, fmt.Errorf("[SpectraFS] copy of %s is inconsistent: %w", dbPath, err)
+	}
+	return boltDB, cleanup, nil
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// readOnlyHelperEnv names the database the helper process opens read-only
+const readOnlyHelperEnv = "SPECTRA_READ_ONLY_HELPER"
+
+// TestReadOnlyHelperProcess is the second process of TestReadOnlyWhileWriterHoldsFile; it does
+// nothing when run as a test
+func TestReadOnlyHelperProcess(t *testing.T) {
+	path := os.Getenv(readOnlyHelperEnv)
+	if path == "" {
+		t.Skip("helper process only")
+	}
+	database, err := NewReadOnly(path, testWorlds, Options{})
+	if err != nil {
+		fmt.Println("open:", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+	children, err := database.GetChildrenByParentID("root", "primary")
+	if err != nil {
+		fmt.Println("list:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("children=%d snapshot=%v\n", len(children), database.Snapshot())
+}
+
+func TestReadOnlyWhileWriterHoldsFile(t *testing.T) {
+	path := tempDBPath(t)
+	writer := openTestDB(t, path, Options{})
+	seedTree(t, writer, 4, 1)
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestReadOnlyHelperProcess$")
+	cmd.Env = append(os.Environ(), readOnlyHelperEnv+"="+path)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("second process: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "children=4 snapshot=true") {
+		t.Errorf("second process read %q, want the writer's 4 folders from a snapshot", out)
+	}
+
+	// The writer keeps writing, and a reader in this process reads a copy too
+	reader, err := NewReadOnly(path, testWorlds, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if err := writer.InsertNode(newNode(rootNode(t, writer), "late", types.NodeTypeFolder)); err != nil {
+		t.Fatal(err)
+	}
+	if children, err := reader.GetChildrenByParentID("root", "primary"); err != nil || len(children) != 4 {
+		t.Errorf("snapshot lists %d children (%v), want the 4 stored when it opened", len(children), err)
+	}
+	if err := reader.InsertNode(newNode(rootNode(t, reader), "x", types.NodeTypeFolder)); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("write to the snapshot = %v, want ErrReadOnly", err)
+	}
+}
+
+func TestReadOnlySharesFileWithoutWriter(t *testing.T) {
+	path := tempDBPath(t)
+	writer, err := New(path, testWorlds, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seedTree(t, writer, 2, 1)
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := NewReadOnly(path, testWorlds, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	second, err := NewReadOnly(path, testWorlds, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+	if first.Snapshot() || second.Snapshot() {
+		t.Error("readers without a writer opened copies instead of sharing the file")
+	}
+	if _, err := New(path, testWorlds, Options{}); err == nil {
+		t.Error("a writer opened the file while readers held it")
+	}
+}
@@ -0,0 +1,328 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// Meta bucket keys used for crash recovery
+const (
+	metaCleanShutdown = "clean_shutdown" // Written by Close, consumed on open
+	metaLastRecovery  = "last_recovery"  // JSON types.RecoveryReport from the most recent unclean open
+)
+
+// Recover runs the consistency pass if the previous shutdown was unclean, stores the report
+// in the meta bucket, and returns it. Returns nil when the last shutdown was clean.
+// With autoRepair, severe findings rebuild every index and the stats from the nodes bucket.
+// Must be called before Preload
+func (db *DB) Recover(autoRepair bool) (*types.RecoveryReport, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if !db.unclean {
+		return nil, nil
+	}
+	if db.cache != nil {
+		return nil, fmt.Errorf("[SpectraFS] recovery must run before preload")
+	}
+
+	started := time.Now()
+	var report *types.RecoveryReport
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		var err error
+		if report, err = db.checkConsistencyTx(tx); err != nil {
+			return err
+		}
+
+		if report.Severe && autoRepair {
+			if err := db.rebuildTx(tx); err != nil {
+				return fmt.Errorf("[SpectraFS] failed to repair database: %w", err)
+			}
+			report.Repaired = true
+		}
+
+		report.DetectedAt = started.UTC()
+		report.DurationMillis = time.Since(started).Milliseconds()
+		data, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("[SpectraFS] failed to marshal recovery report: %w", err)
+		}
+		return db.meta.Put(tx, metaLastRecovery, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	db.unclean = false
+	return report, nil
+}
+
+// LastRecovery returns the most recently stored recovery report, or nil if none was ever recorded
+func (db *DB) LastRecovery() (*types.RecoveryReport, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var data []byte
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		data, err = db.meta.Get(tx, metaLastRecovery)
+		return err
+	})
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	report := &types.RecoveryReport{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("[SpectraFS] failed to unmarshal recovery report: %w", err)
+	}
+	return report, nil
+}
+
+// markCleanShutdown records that Close ran, so the next open skips the recovery pass
+// NOTE: This function assumes the caller already holds db.mu lock
+func (db *DB) markCleanShutdown() error {
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		return db.meta.Put(tx, metaCleanShutdown, []byte(time.Now().UTC().Format(time.RFC3339Nano)))
+	})
+	if errors.Is(err, bbolt.ErrDatabaseNotOpen) {
+		return nil // Already closed
+	}
+	return err
+}
+
+// consumeCleanShutdownTx reads and clears the clean-shutdown marker
+// Returns true if the marker was present
+func (db *DB) consumeCleanShutdownTx(tx *bbolt.Tx) (bool, error) {
+	marker, err := db.meta.Get(tx, metaCleanShutdown)
+	if err != nil {
+		return false, err
+	}
+	if marker == nil {
+		return false, nil
+	}
+	return true, db.meta.Delete(tx, metaCleanShutdown)
+}
+
+// checkConsistencyTx compares stored counters and index cardinalities against the nodes bucket, and
+// the journal's sequence counter against its newest entry
+// Counter mismatches are warnings; index mismatches, dangling child links and a journal counter
+// that would reuse a stored sequence number are severe
+func (db *DB) checkConsistencyTx(tx *bbolt.Tx) (*types.RecoveryReport, error) {
+	report := &types.RecoveryReport{
+		Findings: make([]types.RecoveryFinding, 0),
+	}
+
+	// Derive the expected counters from the nodes themselves (the root is never counted in stats)
+	expected := &types.Stats{SecondaryNodes: make(map[string]int64)}
+	for _, worldName := range db.worlds() {
+		expected.SecondaryNodes[worldName] = 0
+	}
+	err := db.nodes.ForEach(tx, func(node *types.Node, _ int64) error {
+		report.NodeCount++
+		if node.ID == "root" {
+			return nil
+		}
+		switch node.Type {
+		case types.NodeTypeFile:
+			expected.FileCount++
+			expected.TotalFileSize += node.Size
+		case types.NodeTypeFolder:
+			expected.FolderCount++
+		case types.NodeTypeSymlink:
+			expected.SymlinkCount++
+		}
+		if node.ExistenceMap["primary"] {
+			expected.PrimaryNodes++
+		}
+		for worldName := range expected.SecondaryNodes {
+			if node.ExistenceMap[worldName] {
+				expected.SecondaryNodes[worldName]++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := db.stats.Get(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	addFinding(report, "stats.file_count", types.RecoverySeverityWarning, expected.FileCount, stored.FileCount)
+	addFinding(report, "stats.folder_count", types.RecoverySeverityWarning, expected.FolderCount, stored.FolderCount)
+	addFinding(report, "stats.symlink_count", types.RecoverySeverityWarning, expected.SymlinkCount, stored.SymlinkCount)
+	addFinding(report, "stats.total_file_size", types.RecoverySeverityWarning, expected.TotalFileSize, stored.TotalFileSize)
+	addFinding(report, "stats.primary_nodes", types.RecoverySeverityWarning, expected.PrimaryNodes, stored.PrimaryNodes)
+	for _, worldName := range db.worlds() {
+		addFinding(report, "stats.secondary_nodes."+worldName, types.RecoverySeverityWarning,
+			expected.SecondaryNodes[worldName], stored.SecondaryNodes[worldName])
+	}
+
+	// Every node has exactly one entry in each index
+	counts, err := db.index.Counts(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range indexBuckets {
+		addFinding(report, name+".cardinality", types.RecoverySeveritySevere, report.NodeCount, counts[name])
+	}
+
+	// Child links must point at stored nodes
+	var dangling int64
+	err = db.index.ForEachChildLink(tx, func(_, childID string) error {
+		exists, err := db.nodes.Exists(tx, childID)
+		if err == nil && !exists {
+			dangling++
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	addFinding(report, bucketIndexParentID+".dangling", types.RecoverySeveritySevere, 0, dangling)
+
+	// The next sequence handed out must be above the newest journal key, or new events would
+	// overwrite stored ones. The counter may run ahead of the keys after pruning, so only behind is a finding
+	journal, err := journalBucket(tx)
+	if err != nil {
+		return nil, err
+	}
+	if last := lastChangeSeq(journal); int64(journal.Sequence()) < last {
+		addFinding(report, bucketJournal+".sequence", types.RecoverySeveritySevere, last, int64(journal.Sequence()))
+	}
+
+	return report, nil
+}
+
+// RebuildStats recomputes the stats and coverage counters from the nodes bucket
+// Indexes are left alone; use Recover with autoRepair to rebuild those
+func (db *DB) RebuildStats() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.withTx(db.rebuildStatsTx)
+}
+
+// RebuildCounters recomputes the primary and secondary world node counts from the nodes bucket
+// GetNodeCount and GetTableInfo read these counters, so this repairs them on databases whose
+// counters have drifted; RebuildStats recomputes them along with everything else
+func (db *DB) RebuildCounters() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.withTx(func(tx *bbolt.Tx) error {
+		counts := make(map[string]int64)
+		err := db.nodes.ForEach(tx, func(node *types.Node, _ int64) error {
+			if node.ID == "root" {
+				return nil
+			}
+			for worldName, exists := range node.ExistenceMap {
+				if exists {
+					counts[worldName]++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return db.stats.SetWorldCounts(tx, counts)
+	})
+}
+
+// rebuildTx regenerates every index, the stats and the coverage counters from the nodes bucket, and
+// moves a journal sequence counter that fell behind the stored events up to the newest one
+func (db *DB) rebuildTx(tx *bbolt.Tx) error {
+	journal, err := journalBucket(tx)
+	if err != nil {
+		return err
+	}
+	if last := lastChangeSeq(journal); int64(journal.Sequence()) < last {
+		if err := journal.SetSequence(uint64(last)); err != nil {
+			return fmt.Errorf("[SpectraFS] failed to repair journal sequence: %w", err)
+		}
+	}
+
+	if err := db.index.Clear(tx); err != nil {
+		return err
+	}
+
+	err = db.nodes.ForEach(tx, func(node *types.Node, _ int64) error {
+		return db.index.Add(tx, node)
+	})
+	if err != nil {
+		return err
+	}
+
+	return db.rebuildStatsTx(tx)
+}
+
+// rebuildStatsTx recomputes the stats and coverage counters from the nodes bucket
+// The last generation time cannot be derived from the nodes and is carried over
+func (db *DB) rebuildStatsTx(tx *bbolt.Tx) error {
+	previous, err := db.stats.Get(tx)
+	if err != nil {
+		return err
+	}
+
+	var counted []*types.Node
+	err = db.nodes.ForEach(tx, func(node *types.Node, _ int64) error {
+		if node.ID != "root" {
+			counted = append(counted, node)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := db.stats.Reset(tx); err != nil {
+		return err
+	}
+	if err := db.stats.Apply(tx, counted, true); err != nil {
+		return err
+	}
+	if previous.LastGeneratedAt != nil {
+		if err := db.stats.MarkGenerated(tx, *previous.LastGeneratedAt); err != nil {
+			return err
+		}
+	}
+	return db.rebuildCoverageTx(tx)
+}
+
+// backfillStatsTx rebuilds stats written before the per-depth or primary node counters existed
+func (db *DB) backfillStatsTx(tx *bbolt.Tx) error {
+	stats, err := db.stats.Get(tx)
+	if err != nil {
+		return err
+	}
+	if stats.TotalNodes == 0 || (len(stats.NodesPerDepth) > 0 && stats.PrimaryNodes > 0) {
+		return nil
+	}
+	return db.rebuildStatsTx(tx)
+}
+
+// addFinding records a finding when expected and actual differ
+func addFinding(report *types.RecoveryReport, check, severity string, expected, actual int64) {
+	if expected == actual {
+		return
+	}
+	report.Findings = append(report.Findings, types.RecoveryFinding{
+		Check:    check,
+		Severity: severity,
+		Expected: expected,
+		Actual:   actual,
+		Message:  fmt.Sprintf("%s is %d, expected %d", check, actual, expected),
+	})
+	if severity == types.RecoverySeveritySevere {
+		report.Severe = true
+	}
+}
@@ -0,0 +1,158 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// crashAndReopen closes database cleanly, applies damage to the file and drops the clean-shutdown
+// marker as a crash would have, then reopens it
+func crashAndReopen(t *testing.T, database *DB, path string, damage func(tx *bbolt.Tx) error) *DB {
+	t.Helper()
+	if err := database.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := bbolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = raw.Update(func(tx *bbolt.Tx) error {
+		if err := tx.Bucket([]byte(bucketMeta)).Delete([]byte(metaCleanShutdown)); err != nil {
+			return err
+		}
+		return damage(tx)
+	})
+	if closeErr := raw.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return openTestDB(t, path, Options{})
+}
+
+// finding returns the report's finding for check, or nil
+func finding(report *types.RecoveryReport, check string) *types.RecoveryFinding {
+	for i := range report.Findings {
+		if report.Findings[i].Check == check {
+			return &report.Findings[i]
+		}
+	}
+	return nil
+}
+
+func TestRecoverSkippedAfterCleanShutdown(t *testing.T) {
+	path := tempDBPath(t)
+	database := openTestDB(t, path, Options{})
+	seedTree(t, database, 2, 2)
+	if err := database.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := openTestDB(t, path, Options{})
+	report, err := reopened.Recover(true)
+	if err != nil || report != nil {
+		t.Errorf("Recover after a clean shutdown = %+v, %v; want nil, nil", report, err)
+	}
+}
+
+func TestRecoverReportsDriftedStats(t *testing.T) {
+	path := tempDBPath(t)
+	database := openTestDB(t, path, Options{})
+	seedTree(t, database, 2, 2)
+
+	reopened := crashAndReopen(t, database, path, func(tx *bbolt.Tx) error {
+		return boltStatsRepo{}.SetWorldCounts(tx, map[string]int64{"primary": 99})
+	})
+	report, err := reopened.Recover(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report == nil || report.Severe || report.NodeCount != 7 {
+		t.Fatalf("report = %+v, want a non-severe report over 7 nodes", report)
+	}
+	if f := finding(report, "stats.primary_nodes"); f == nil || f.Expected != 6 || f.Actual != 99 {
+		t.Errorf("primary_nodes finding = %+v", f)
+	}
+
+	stored, err := reopened.LastRecovery()
+	if err != nil || stored == nil || len(stored.Findings) != len(report.Findings) {
+		t.Errorf("LastRecovery = %+v, %v", stored, err)
+	}
+	if again, err := reopened.Recover(false); err != nil || again != nil {
+		t.Errorf("second Recover = %+v, %v; want nil, nil", again, err)
+	}
+}
+
+func TestRecoverRepairsLaggingJournalSequence(t *testing.T) {
+	path := tempDBPath(t)
+	database := openTestDB(t, path, Options{})
+	if _, err := database.AppendChanges(make([]types.ChangeEvent, 3), 100); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := crashAndReopen(t, database, path, func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(bucketJournal)).SetSequence(1)
+	})
+	report, err := reopened.Recover(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := finding(report, bucketJournal+".sequence")
+	if f == nil || f.Expected != 3 || f.Actual != 1 || !report.Severe || !report.Repaired {
+		t.Fatalf("report = %+v, want a repaired severe journal.sequence finding (3, 1)", report)
+	}
+
+	events, err := reopened.AppendChanges(make([]types.ChangeEvent, 1), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events[0].Seq != 4 {
+		t.Errorf("next event numbered %d after repair, want 4", events[0].Seq)
+	}
+}
+
+func TestRecoverAcceptsPrunedJournal(t *testing.T) {
+	path := tempDBPath(t)
+	database := openTestDB(t, path, Options{})
+	if _, err := database.AppendChanges(make([]types.ChangeEvent, 5), 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.TruncateChanges(types.ChangeEvent{}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := crashAndReopen(t, database, path, func(*bbolt.Tx) error { return nil })
+	report, err := reopened.Recover(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Findings) != 0 {
+		t.Errorf("findings on a consistent database: %+v", report.Findings)
+	}
+}
+
+func TestRecoverRebuildsIndexes(t *testing.T) {
+	path := tempDBPath(t)
+	database := openTestDB(t, path, Options{})
+	nodes := seedTree(t, database, 2, 2)
+
+	reopened := crashAndReopen(t, database, path, func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(bucketIndexPath)).Delete([]byte(nodes[1].Path))
+	})
+	report, err := reopened.Recover(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := finding(report, bucketIndexPath+".cardinality"); f == nil || !report.Repaired {
+		t.Fatalf("report = %+v, want a repaired index_path cardinality finding", report)
+	}
+	node, err := reopened.GetNodeByPath(nodes[1].Path, "primary")
+	if err != nil || node == nil || node.ID != nodes[1].ID {
+		t.Errorf("GetNodeByPath(%s) after repair = %+v, %v", nodes[1].Path, node, err)
+	}
+}
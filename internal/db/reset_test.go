@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// resets are the two ways of clearing the tree, which must leave the same state
+var resets = map[string]func(database *DB, ctx context.Context, rootTimestamp time.Time) error{
+	"ResetNodes":         (*DB).ResetNodes,
+	"DeleteAllNodesSlow": (*DB).DeleteAllNodesSlow,
+}
+
+func TestResetLeavesOnlyRoot(t *testing.T) {
+	stamp := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	for name, reset := range resets {
+		t.Run(name, func(t *testing.T) {
+			database := newTestDB(t, Options{NodeCacheSize: 64})
+			seedTree(t, database, 10, 3)
+			rootNode(t, database) // Cached, so a stale copy would show below
+
+			if err := reset(database, context.Background(), stamp); err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			err := database.ScanNodes(context.Background(), "", func(node *types.Node) (bool, error) {
+				ids = append(ids, node.ID)
+				return true, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ids) != 1 || ids[0] != "root" {
+				t.Errorf("stored after reset: %v, want only the root", ids)
+			}
+			if root := rootNode(t, database); !root.LastUpdated.Equal(stamp) {
+				t.Errorf("root stamped %v, want %v", root.LastUpdated, stamp)
+			}
+			if stats := storedStats(t, database); stats.TotalNodes != 0 || stats.TotalFileSize != 0 {
+				t.Errorf("stats after reset: %+v", stats)
+			}
+			report, err := database.CheckIntegrity(context.Background(), false)
+			if err != nil || !report.OK {
+				t.Errorf("integrity after reset: %+v, %v", report, err)
+			}
+
+			// The emptied buckets take a new tree
+			seedTree(t, database, 2, 1)
+			if children, err := database.GetChildrenByParentID("root", "primary"); err != nil || len(children) != 2 {
+				t.Errorf("root lists %d children after reseeding (%v), want 2", len(children), err)
+			}
+		})
+	}
+}
+
+// benchmarkReset measures reset on a 100k-node tree, which is seeded once and restored from a named
+// snapshot outside the timer before each run
+func benchmarkReset(b *testing.B, reset func(database *DB, ctx context.Context, rootTimestamp time.Time) error) {
+	database := newTestDB(b, Options{NoSync: true})
+	seedTree(b, database, 1000, 99)
+	if err := database.SaveSnapshot(&types.SnapshotInfo{Name: "tree"}); err != nil {
+		b.Fatal(err)
+	}
+
+	for b.Loop() {
+		b.StopTimer()
+		if _, err := database.RestoreSnapshot(context.Background(), "tree"); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		if err := reset(database, context.Background(), time.Time{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkResetNodes drops and recreates the buckets of a 100k-node tree
+func BenchmarkResetNodes(b *testing.B) {
+	benchmarkReset(b, (*DB).ResetNodes)
+}
+
+// BenchmarkDeleteAllNodesSlow deletes the same tree key by key
+func BenchmarkDeleteAllNodesSlow(b *testing.B) {
+	benchmarkReset(b, (*DB).DeleteAllNodesSlow)
+}
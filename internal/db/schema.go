@@ -1,6 +1,7 @@
 package db
 
 import (
+	"bytes"
 	"fmt"
 
 	"go.etcd.io/bbolt"
@@ -13,8 +14,14 @@ const (
 	bucketIndexPath       = "index_path"
 	bucketIndexParentPath = "index_parent_path"
 	bucketStats           = "stats"
+	bucketMeta            = "meta"
+	bucketMutations       = "mutations"
+	bucketJournal         = "journal"
 )
 
+// addedBuckets were introduced after the initial schema and are created on open when missing
+var addedBuckets = []string{bucketMeta, bucketMutations, bucketJournal}
+
 // InitializeBuckets creates all required buckets in the BoltDB database
 // This replaces the SQL table creation logic
 func InitializeBuckets(db *bbolt.DB) error {
@@ -42,6 +49,21 @@ func InitializeBuckets(db *bbolt.DB) error {
 			return fmt.Errorf("failed to create stats bucket: %w", err)
 		}
 
+		// Create meta bucket
+		if _, err := tx.CreateBucketIfNotExists([]byte(bucketMeta)); err != nil {
+			return fmt.Errorf("failed to create meta bucket: %w", err)
+		}
+
+		// Create mutation log bucket
+		if _, err := tx.CreateBucketIfNotExists([]byte(bucketMutations)); err != nil {
+			return fmt.Errorf("failed to create mutations bucket: %w", err)
+		}
+
+		// Create change journal bucket
+		if _, err := tx.CreateBucketIfNotExists([]byte(bucketJournal)); err != nil {
+			return fmt.Errorf("failed to create journal bucket: %w", err)
+		}
+
 		return nil
 	})
 }
@@ -68,3 +90,68 @@ func VerifyBucketsExist(db *bbolt.DB) error {
 		return nil
 	})
 }
+
+// MigrateBuckets creates any bucket added after the initial schema that an existing database lacks
+// and rewrites an index_parent_id still in the flat layout
+func MigrateBuckets(db *bbolt.DB) error {
+	return db.Update(func(tx *bbolt.Tx) error {
+		for _, bucketName := range addedBuckets {
+			if _, err := tx.CreateBucketIfNotExists([]byte(bucketName)); err != nil {
+				return fmt.Errorf("failed to create %s bucket: %w", bucketName, err)
+			}
+		}
+		if err := migrateParentIndexTx(tx); err != nil {
+			return fmt.Errorf("failed to migrate %s bucket: %w", bucketIndexParentID, err)
+		}
+		return nil
+	})
+}
+
+// checkMigratedTx returns an error naming the first change MigrateBuckets would make, for a
+// database opened read-only
+func checkMigratedTx(tx *bbolt.Tx) error {
+	for _, bucketName := range addedBuckets {
+		if tx.Bucket([]byte(bucketName)) == nil {
+			return fmt.Errorf("[SpectraFS] %s bucket is missing; open the database writable once to migrate it", bucketName)
+		}
+	}
+	first, _ := tx.Bucket([]byte(bucketIndexParentID)).Cursor().First()
+	if first != nil && tx.Bucket([]byte(bucketIndexParentID)).Bucket(first) == nil {
+		return fmt.Errorf("[SpectraFS] %s uses the flat layout; open the database writable once to migrate it", bucketIndexParentID)
+	}
+	return nil
+}
+
+// migrateParentIndexTx moves an index_parent_id written before it held a bucket per parent, with
+// flat "{parentID}|{nodeID}" keys, into nested buckets. An empty or already nested index is left alone
+func migrateParentIndexTx(tx *bbolt.Tx) error {
+	indexParentID := tx.Bucket([]byte(bucketIndexParentID))
+	first, _ := indexParentID.Cursor().First()
+	if first == nil || indexParentID.Bucket(first) != nil {
+		return nil
+	}
+
+	var links [][2]string // (parentID, childID)
+	err := indexParentID.ForEach(func(key, _ []byte) error {
+		separator := bytes.LastIndexByte(key, '|')
+		if separator < 0 {
+			return nil // Malformed keys name no link and are dropped
+		}
+		links = append(links, [2]string{string(key[:separator]), string(key[separator+1:])})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := clearBucket(tx, bucketIndexParentID); err != nil {
+		return err
+	}
+	indexParentID = tx.Bucket([]byte(bucketIndexParentID))
+	for _, link := range links {
+		if err := linkChild(indexParentID, link[0], link[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
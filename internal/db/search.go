@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// SearchPaths visits the stored nodes at prefix or below it in path order until fn returns false
+// The index_path cursor is seeked to the prefix instead of scanning every node, and only paths that
+// pass match are loaded and decoded. It runs on one read-only snapshot without db.mu and stops with
+// ctx.Err() if ctx is cancelled
+func (db *DB) SearchPaths(ctx context.Context, prefix string, match func(path string) bool, fn func(node *types.Node) (bool, error)) error {
+	visited := 0
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		return db.index.ScanPathPrefix(tx, prefix, "", func(path, id string) (bool, error) {
+			if err := checkCtx(ctx, visited); err != nil {
+				return false, err
+			}
+			visited++
+			if !match(path) {
+				return true, nil
+			}
+
+			node, err := db.loadNodeTx(tx, id)
+			if err != nil {
+				return false, err
+			}
+			if node == nil {
+				return true, nil
+			}
+			return fn(node)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("[SpectraFS] failed to search nodes: %w", err)
+	}
+	return nil
+}
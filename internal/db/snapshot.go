@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/internal/utils"
+	"go.etcd.io/bbolt"
+)
+
+// ErrDatabaseNotEmpty is returned by ImportNodes without merge when the database holds nodes besides the root
+var ErrDatabaseNotEmpty = NewError(ErrConflict, "[SpectraFS] database is not empty")
+
+// ErrInvalidSnapshot is returned by ImportNodes for a node that does not fit the tree imported so far
+var ErrInvalidSnapshot = NewError(ErrInvalidInput, "[SpectraFS] invalid snapshot")
+
+// ImportNodes loads the nodes returned by next (nil ends the snapshot) in a single transaction,
+// indexing each one as it is stored and then rebuilding the stats and coverage counters
+// A node's parent must already be stored or come earlier in the snapshot (parent-before-child order).
+// Without merge the database must hold nothing but the root, which the snapshot's own root replaces;
+// with merge, nodes whose ID is already stored are skipped and a new node at a taken path fails with
+// ErrPathExists. Any error, or cancelling ctx, rolls the whole import back.
+// Returns the number of nodes imported and skipped
+func (db *DB) ImportNodes(ctx context.Context, merge bool, next func() (*types.Node, error)) (int, int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var imported []*types.Node
+	var sizes []int64
+	skipped := 0
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		if !merge {
+			if err := db.clearForImportTx(tx); err != nil {
+				return err
+			}
+		}
+
+		for i := 0; ; i++ {
+			if err := checkCtx(ctx, i); err != nil {
+				return err
+			}
+			node, err := next()
+			if err != nil {
+				return err
+			}
+			if node == nil {
+				break
+			}
+
+			exists, err := db.nodes.Exists(tx, node.ID)
+			if err != nil {
+				return err
+			}
+			if exists {
+				if !merge {
+					return fmt.Errorf("%w: duplicate node %s", ErrInvalidSnapshot, node.ID)
+				}
+				skipped++
+				continue
+			}
+			if err := db.checkImportTx(tx, node); err != nil {
+				return err
+			}
+
+			size, err := db.nodes.Put(tx, node)
+			if err != nil {
+				return err
+			}
+			if err := db.index.Add(tx, node); err != nil {
+				return err
+			}
+			imported = append(imported, node)
+			sizes = append(sizes, size)
+		}
+
+		rootExists, err := db.nodes.Exists(tx, "root")
+		if err != nil {
+			return err
+		}
+		if !rootExists {
+			return fmt.Errorf("%w: snapshot has no root", ErrInvalidSnapshot)
+		}
+		return db.rebuildStatsTx(tx)
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !merge {
+		db.pending = make(map[string]struct{})
+	}
+	if db.cache != nil {
+		if !merge {
+			db.cache.clear()
+		}
+		for i, node := range imported {
+			db.cache.add(node, sizes[i])
+		}
+	}
+	return len(imported), skipped, nil
+}
+
+// clearForImportTx empties a database that holds nothing but the root, or fails with ErrDatabaseNotEmpty
+func (db *DB) clearForImportTx(tx *bbolt.Tx) error {
+	empty := true
+	err := db.nodes.Scan(tx, "", func(node *types.Node) (bool, error) {
+		if node.ID != "root" {
+			empty = false
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	if !empty {
+		return ErrDatabaseNotEmpty
+	}
+
+	if err := db.nodes.Clear(tx); err != nil {
+		return err
+	}
+	if err := db.index.Clear(tx); err != nil {
+		return err
+	}
+	if err := db.clearChildrenGeneratedTx(tx); err != nil {
+		return err
+	}
+	return db.clearPendingTx(tx)
+}
+
+// checkImportTx verifies that a node is the root or hangs below a stored folder at the matching path and depth
+func (db *DB) checkImportTx(tx *bbolt.Tx, node *types.Node) error {
+	if node.ParentID == "" {
+		if node.ID != "root" || node.Path != "/" || node.DepthLevel != 0 {
+			return fmt.Errorf("%w: node %s has no parent", ErrInvalidSnapshot, node.ID)
+		}
+		return nil
+	}
+
+	parent, err := db.nodes.Get(tx, node.ParentID)
+	if err != nil {
+		return err
+	}
+	if parent == nil {
+		return fmt.Errorf("%w: node %s appears before its parent %s", ErrInvalidSnapshot, node.ID, node.ParentID)
+	}
+	if parent.Type != types.NodeTypeFolder {
+		return fmt.Errorf("%w: parent of node %s is not a folder", ErrInvalidSnapshot, node.ID)
+	}
+	if node.ParentPath != parent.Path || node.Path != utils.JoinPath(parent.Path, node.Name) || node.DepthLevel != parent.DepthLevel+1 {
+		return fmt.Errorf("%w: node %s does not match its parent's path or depth", ErrInvalidSnapshot, node.ID)
+	}
+
+	return db.checkPathFreeTx(tx, node.Path)
+}
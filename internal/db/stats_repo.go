@@ -0,0 +1,322 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// Records in the stats bucket
+const (
+	statsKey    = "global"   // Aggregate file/folder/world counters
+	coverageKey = "coverage" // Per-world, per-depth folder coverage counters
+)
+
+// StatsRepo owns the stats bucket: aggregate file/folder/world counters and coverage counters
+// Methods run inside a caller-supplied transaction and never lock
+type StatsRepo interface {
+	// Init writes zero stats if none exist yet
+	Init(tx *bbolt.Tx) error
+	// Get returns the current stats (zero stats if none are stored)
+	Get(tx *bbolt.Tx) (*types.Stats, error)
+	// Apply adds (increment) or removes the contribution of a set of nodes
+	Apply(tx *bbolt.Tx, nodes []*types.Node, increment bool) error
+	// MarkGenerated records the time of the latest generation
+	MarkGenerated(tx *bbolt.Tx, at time.Time) error
+	// Reset overwrites the stats and coverage counters with zero values
+	Reset(tx *bbolt.Tx) error
+	// SetWorld stores a secondary world's node count, adding the world if it is new
+	SetWorld(tx *bbolt.Tx, world string, count int64) error
+	// DropWorld removes a secondary world from the node counts and the coverage counters
+	DropWorld(tx *bbolt.Tx, world string) error
+	// SetWorldCounts replaces the primary and secondary world node counts ("primary" keys the primary count)
+	SetWorldCounts(tx *bbolt.Tx, counts map[string]int64) error
+	// GetCoverage returns the coverage counters, or nil if none are stored yet
+	GetCoverage(tx *bbolt.Tx) (*types.CoverageCounters, error)
+	// PutCoverage stores the coverage counters
+	PutCoverage(tx *bbolt.Tx, counters *types.CoverageCounters) error
+}
+
+// boltStatsRepo is the BoltDB implementation of StatsRepo
+type boltStatsRepo struct {
+	secondaryTables []string // Secondary worlds that always appear in SecondaryNodes
+}
+
+// bucket returns the stats bucket
+func (r boltStatsRepo) bucket(tx *bbolt.Tx) (*bbolt.Bucket, error) {
+	bucket := tx.Bucket([]byte(bucketStats))
+	if bucket == nil {
+		return nil, fmt.Errorf("[SpectraFS] stats bucket does not exist")
+	}
+	return bucket, nil
+}
+
+// zero returns empty stats with every secondary world present
+func (r boltStatsRepo) zero() *types.Stats {
+	stats := &types.Stats{
+		SecondaryNodes: make(map[string]int64),
+	}
+	for _, worldName := range r.secondaryTables {
+		stats.SecondaryNodes[worldName] = 0
+	}
+	return stats
+}
+
+// derive fills the fields computed from the stored counters
+func derive(stats *types.Stats) {
+	stats.TotalNodes = stats.FileCount + stats.FolderCount + stats.SymlinkCount
+
+	// Trailing empty depths are dropped so MaxDepth follows deletes
+	for len(stats.NodesPerDepth) > 0 && stats.NodesPerDepth[len(stats.NodesPerDepth)-1] == 0 {
+		stats.NodesPerDepth = stats.NodesPerDepth[:len(stats.NodesPerDepth)-1]
+	}
+	stats.MaxDepth = 0
+	if len(stats.NodesPerDepth) > 0 {
+		stats.MaxDepth = len(stats.NodesPerDepth) - 1
+	}
+}
+
+// put stores stats
+func (r boltStatsRepo) put(bucket *bbolt.Bucket, stats *types.Stats) error {
+	derive(stats)
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("[SpectraFS] failed to marshal stats: %w", err)
+	}
+	if err := bucket.Put([]byte(statsKey), data); err != nil {
+		return fmt.Errorf("[SpectraFS] failed to store stats: %w", err)
+	}
+	return nil
+}
+
+// Init writes zero stats if none exist yet
+func (r boltStatsRepo) Init(tx *bbolt.Tx) error {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return err
+	}
+	if bucket.Get([]byte(statsKey)) != nil {
+		return nil
+	}
+	return r.put(bucket, r.zero())
+}
+
+// Get returns the current stats (zero stats if none are stored)
+func (r boltStatsRepo) Get(tx *bbolt.Tx) (*types.Stats, error) {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	data := bucket.Get([]byte(statsKey))
+	if data == nil {
+		return r.zero(), nil
+	}
+
+	stats := &types.Stats{}
+	if err := json.Unmarshal(data, stats); err != nil {
+		return nil, fmt.Errorf("[SpectraFS] failed to unmarshal stats: %w", err)
+	}
+	derive(stats)
+
+	// Ensure every secondary world is in the map
+	if stats.SecondaryNodes == nil {
+		stats.SecondaryNodes = make(map[string]int64)
+	}
+	for _, worldName := range r.secondaryTables {
+		if _, exists := stats.SecondaryNodes[worldName]; !exists {
+			stats.SecondaryNodes[worldName] = 0
+		}
+	}
+
+	return stats, nil
+}
+
+// Apply adds (increment) or removes the contribution of a set of nodes
+// The root is never counted, so it is skipped if present
+func (r boltStatsRepo) Apply(tx *bbolt.Tx, nodes []*types.Node, increment bool) error {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return err
+	}
+
+	stats, err := r.Get(tx)
+	if err != nil {
+		return err
+	}
+
+	delta := int64(1)
+	if !increment {
+		delta = -1
+	}
+
+	for _, node := range nodes {
+		if node.ID == "root" {
+			continue
+		}
+
+		switch node.Type {
+		case types.NodeTypeFile:
+			stats.FileCount += delta
+			stats.TotalFileSize += delta * node.Size
+			if stats.TotalFileSize < 0 {
+				stats.TotalFileSize = 0
+			}
+		case types.NodeTypeFolder:
+			stats.FolderCount += delta
+		case types.NodeTypeSymlink:
+			stats.SymlinkCount += delta
+		}
+
+		for len(stats.NodesPerDepth) <= node.DepthLevel {
+			stats.NodesPerDepth = append(stats.NodesPerDepth, 0)
+		}
+		stats.NodesPerDepth[node.DepthLevel] += delta
+		if stats.NodesPerDepth[node.DepthLevel] < 0 {
+			stats.NodesPerDepth[node.DepthLevel] = 0
+		}
+
+		// Update the node count of every world the node exists in
+		if node.ExistenceMap["primary"] {
+			stats.PrimaryNodes += delta
+			if stats.PrimaryNodes < 0 {
+				stats.PrimaryNodes = 0
+			}
+		}
+		for worldName := range stats.SecondaryNodes {
+			if node.ExistenceMap[worldName] {
+				stats.SecondaryNodes[worldName] += delta
+				if stats.SecondaryNodes[worldName] < 0 {
+					stats.SecondaryNodes[worldName] = 0
+				}
+			}
+		}
+	}
+
+	return r.put(bucket, stats)
+}
+
+// MarkGenerated records the time of the latest generation
+func (r boltStatsRepo) MarkGenerated(tx *bbolt.Tx, at time.Time) error {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return err
+	}
+
+	stats, err := r.Get(tx)
+	if err != nil {
+		return err
+	}
+	at = at.UTC()
+	stats.LastGeneratedAt = &at
+	return r.put(bucket, stats)
+}
+
+// Reset overwrites the stats and coverage counters with zero values
+func (r boltStatsRepo) Reset(tx *bbolt.Tx) error {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return err
+	}
+	if err := r.put(bucket, r.zero()); err != nil {
+		return err
+	}
+	return r.PutCoverage(tx, newCoverageCounters())
+}
+
+// SetWorld stores a secondary world's node count, adding the world if it is new
+func (r boltStatsRepo) SetWorld(tx *bbolt.Tx, world string, count int64) error {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return err
+	}
+
+	stats, err := r.Get(tx)
+	if err != nil {
+		return err
+	}
+	stats.SecondaryNodes[world] = count
+	return r.put(bucket, stats)
+}
+
+// SetWorldCounts replaces the primary and secondary world node counts ("primary" keys the primary count)
+func (r boltStatsRepo) SetWorldCounts(tx *bbolt.Tx, counts map[string]int64) error {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return err
+	}
+
+	stats, err := r.Get(tx)
+	if err != nil {
+		return err
+	}
+	stats.PrimaryNodes = counts["primary"]
+	for worldName := range stats.SecondaryNodes {
+		stats.SecondaryNodes[worldName] = counts[worldName]
+	}
+	return r.put(bucket, stats)
+}
+
+// DropWorld removes a secondary world from the node counts and the coverage counters
+func (r boltStatsRepo) DropWorld(tx *bbolt.Tx, world string) error {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return err
+	}
+
+	stats, err := r.Get(tx)
+	if err != nil {
+		return err
+	}
+	delete(stats.SecondaryNodes, world)
+	if err := r.put(bucket, stats); err != nil {
+		return err
+	}
+
+	counters, err := r.GetCoverage(tx)
+	if err != nil || counters == nil {
+		return err
+	}
+	delete(counters.Folders, world)
+	delete(counters.Expanded, world)
+	return r.PutCoverage(tx, counters)
+}
+
+// GetCoverage returns the coverage counters, or nil if none are stored yet
+func (r boltStatsRepo) GetCoverage(tx *bbolt.Tx) (*types.CoverageCounters, error) {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	data := bucket.Get([]byte(coverageKey))
+	if data == nil {
+		return nil, nil
+	}
+
+	counters := newCoverageCounters()
+	if err := json.Unmarshal(data, counters); err != nil {
+		return nil, fmt.Errorf("[SpectraFS] failed to unmarshal coverage: %w", err)
+	}
+	return counters, nil
+}
+
+// PutCoverage stores the coverage counters
+func (r boltStatsRepo) PutCoverage(tx *bbolt.Tx, counters *types.CoverageCounters) error {
+	bucket, err := r.bucket(tx)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(counters)
+	if err != nil {
+		return fmt.Errorf("[SpectraFS] failed to marshal coverage: %w", err)
+	}
+	if err := bucket.Put([]byte(coverageKey), data); err != nil {
+		return fmt.Errorf("[SpectraFS] failed to store coverage: %w", err)
+	}
+	return nil
+}
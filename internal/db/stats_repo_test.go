@@ -0,0 +1,125 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// storedStats reads the stats through the repository
+func storedStats(tb testing.TB, database *DB) *types.Stats {
+	tb.Helper()
+	var stats *types.Stats
+	view(tb, database, func(tx *bbolt.Tx) error {
+		var err error
+		stats, err = database.stats.Get(tx)
+		return err
+	})
+	return stats
+}
+
+func TestStatsRepoApply(t *testing.T) {
+	database := newTestDB(t, Options{})
+	repo := database.stats
+	root := rootNode(t, database)
+	folder := newNode(root, "a", types.NodeTypeFolder)
+	file := newNode(folder, "x.txt", types.NodeTypeFile)
+	file.ExistenceMap["s1"] = false
+	nodes := []*types.Node{root, folder, file}
+
+	update(t, database, func(tx *bbolt.Tx) error { return repo.Apply(tx, nodes, true) })
+	stats := storedStats(t, database)
+	want := types.Stats{
+		TotalNodes:     2,
+		FileCount:      1,
+		FolderCount:    1,
+		TotalFileSize:  file.Size,
+		PrimaryNodes:   2,
+		SecondaryNodes: map[string]int64{"s1": 1},
+		NodesPerDepth:  []int64{0, 1, 1},
+		MaxDepth:       2,
+	}
+	if !reflect.DeepEqual(*stats, want) {
+		t.Errorf("stats after increment = %+v, want %+v", *stats, want)
+	}
+
+	update(t, database, func(tx *bbolt.Tx) error { return repo.Apply(tx, nodes[2:], false) })
+	stats = storedStats(t, database)
+	if stats.FileCount != 0 || stats.TotalFileSize != 0 || stats.MaxDepth != 1 || stats.SecondaryNodes["s1"] != 1 {
+		t.Errorf("stats after removing the file = %+v", *stats)
+	}
+
+	// Removing more than was added clamps at zero
+	update(t, database, func(tx *bbolt.Tx) error { return repo.Apply(tx, nodes, false) })
+	update(t, database, func(tx *bbolt.Tx) error { return repo.Apply(tx, nodes, false) })
+	stats = storedStats(t, database)
+	if stats.PrimaryNodes != 0 || stats.SecondaryNodes["s1"] != 0 || stats.TotalFileSize != 0 || stats.MaxDepth != 0 {
+		t.Errorf("stats went negative: %+v", *stats)
+	}
+}
+
+func TestStatsRepoWorlds(t *testing.T) {
+	database := newTestDB(t, Options{})
+	repo := database.stats
+
+	update(t, database, func(tx *bbolt.Tx) error { return repo.SetWorld(tx, "s2", 7) })
+	update(t, database, func(tx *bbolt.Tx) error {
+		return repo.SetWorldCounts(tx, map[string]int64{"primary": 10, "s1": 4})
+	})
+	stats := storedStats(t, database)
+	if want := map[string]int64{"s1": 4, "s2": 0}; stats.PrimaryNodes != 10 || !reflect.DeepEqual(stats.SecondaryNodes, want) {
+		t.Errorf("world counts = %d, %v; want 10, %v", stats.PrimaryNodes, stats.SecondaryNodes, want)
+	}
+
+	update(t, database, func(tx *bbolt.Tx) error {
+		return repo.PutCoverage(tx, &types.CoverageCounters{
+			Folders:  map[string][]int64{"primary": {1}, "s2": {1, 2}},
+			Expanded: map[string][]int64{"s2": {1}},
+		})
+	})
+	update(t, database, func(tx *bbolt.Tx) error { return repo.DropWorld(tx, "s2") })
+	if stats := storedStats(t, database); len(stats.SecondaryNodes) != 1 {
+		t.Errorf("secondary worlds after DropWorld = %v", stats.SecondaryNodes)
+	}
+	view(t, database, func(tx *bbolt.Tx) error {
+		counters, err := repo.GetCoverage(tx)
+		if err != nil {
+			return err
+		}
+		if _, ok := counters.Folders["s2"]; ok || !reflect.DeepEqual(counters.Folders["primary"], []int64{1}) {
+			t.Errorf("coverage after DropWorld = %+v", counters)
+		}
+		if _, ok := counters.Expanded["s2"]; ok {
+			t.Errorf("expanded coverage kept s2: %+v", counters.Expanded)
+		}
+		return nil
+	})
+}
+
+func TestStatsRepoReset(t *testing.T) {
+	database := newTestDB(t, Options{})
+	repo := database.stats
+	seedTree(t, database, 2, 2)
+	if stats := storedStats(t, database); stats.TotalNodes != 6 {
+		t.Fatalf("TotalNodes = %d, want 6", stats.TotalNodes)
+	}
+
+	update(t, database, func(tx *bbolt.Tx) error { return repo.Reset(tx) })
+	stats := storedStats(t, database)
+	want := types.Stats{SecondaryNodes: map[string]int64{"s1": 0}}
+	if !reflect.DeepEqual(*stats, want) {
+		t.Errorf("stats after Reset = %+v, want %+v", *stats, want)
+	}
+	view(t, database, func(tx *bbolt.Tx) error {
+		counters, err := repo.GetCoverage(tx)
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(counters, newCoverageCounters()) {
+			t.Errorf("coverage after Reset = %+v", counters)
+		}
+		return nil
+	})
+}
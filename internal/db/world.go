@@ -0,0 +1,241 @@
+package db
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// worldOpBatchSize is the number of nodes AddWorld and RemoveWorld rewrite per transaction
+const worldOpBatchSize = 1000
+
+// metaPendingWorld names the world of an AddWorld or RemoveWorld in progress
+// Both settle the same way after a crash: the world's key is stripped from every node, which
+// rolls back a half-finished add and completes a half-finished remove
+const metaPendingWorld = "pending_world"
+
+// worldItem is a node queued for AddWorld with its parent's existence in the new world
+type worldItem struct {
+	id           string
+	parentExists bool
+}
+
+// worlds returns the current secondary world names
+func (db *DB) worlds() []string {
+	db.worldsMu.RLock()
+	defer db.worldsMu.RUnlock()
+	return db.secondaryTables
+}
+
+// setWorlds replaces the secondary world names used by the DB and its stats
+// NOTE: This function assumes the caller already holds db.mu lock
+func (db *DB) setWorlds(worlds []string) {
+	db.worldsMu.Lock()
+	defer db.worldsMu.Unlock()
+	db.secondaryTables = worlds
+	db.stats = boltStatsRepo{secondaryTables: worlds}
+}
+
+// AddWorld registers a secondary world and records every node's existence in it
+// Nodes are visited breadth-first from the root in transactions of worldOpBatchSize, so each node is
+// decided after its parent; exists receives the node and whether its parent exists in the world.
+// The world only becomes visible (in GetSecondaryTables, GetTableInfo and the stats) in the last
+// transaction. Returns the number of nodes in the new world, root included
+func (db *DB) AddWorld(name string, exists func(node *types.Node, parentExists bool) bool) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if name == "primary" || slices.Contains(db.worlds(), name) {
+		return 0, fmt.Errorf("[SpectraFS] world %s already exists", name)
+	}
+
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		return db.meta.Put(tx, metaPendingWorld, []byte(name))
+	})
+	if err != nil {
+		return 0, fmt.Errorf("[SpectraFS] failed to record world operation: %w", err)
+	}
+
+	count := 0
+	queue := []worldItem{{id: "root", parentExists: true}}
+	for len(queue) > 0 {
+		batch := queue[:min(len(queue), worldOpBatchSize)]
+		queue = queue[len(batch):]
+
+		ids := make([]string, len(batch))
+		for i, item := range batch {
+			ids[i] = item.id
+		}
+
+		updated := make([]*types.Node, 0, len(batch))
+		sizes := make([]int64, 0, len(batch))
+		var next []worldItem
+		added := 0
+		err := db.withTx(func(tx *bbolt.Tx) error {
+			return db.coverageTx(tx, ids, func() error {
+				for _, item := range batch {
+					node, err := db.nodes.Get(tx, item.id)
+					if err != nil {
+						return err
+					}
+					if node == nil {
+						continue
+					}
+
+					inWorld := exists(node, item.parentExists)
+					node.ExistenceMap = maps.Clone(node.ExistenceMap)
+					node.ExistenceMap[name] = inWorld
+					size, err := db.nodes.Put(tx, node)
+					if err != nil {
+						return fmt.Errorf("[SpectraFS] failed to update existence map for %s: %w", node.ID, err)
+					}
+					updated = append(updated, node)
+					sizes = append(sizes, size)
+					if inWorld {
+						added++
+					}
+
+					childIDs, err := db.index.ChildIDs(tx, node.ID)
+					if err != nil {
+						return err
+					}
+					for _, childID := range childIDs {
+						next = append(next, worldItem{id: childID, parentExists: inWorld})
+					}
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return 0, fmt.Errorf("[SpectraFS] failed to add world %s: %w", name, err)
+		}
+
+		count += added
+		queue = append(queue, next...)
+		db.cacheUpdates(updated, sizes)
+	}
+
+	// The root is never counted in stats
+	err = db.withTx(func(tx *bbolt.Tx) error {
+		if err := db.stats.SetWorld(tx, name, int64(count-1)); err != nil {
+			return err
+		}
+		return db.meta.Delete(tx, metaPendingWorld)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("[SpectraFS] failed to finish adding world %s: %w", name, err)
+	}
+
+	worlds := append(slices.Clone(db.worlds()), name)
+	db.setWorlds(worlds)
+	return count, nil
+}
+
+// RemoveWorld unregisters a secondary world and strips it from every node's existence map
+// The world disappears from GetSecondaryTables and GetTableInfo before the nodes are rewritten
+func (db *DB) RemoveWorld(name string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	worlds := db.worlds()
+	if !slices.Contains(worlds, name) {
+		return fmt.Errorf("[SpectraFS] world %s does not exist", name)
+	}
+
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		return db.meta.Put(tx, metaPendingWorld, []byte(name))
+	})
+	if err != nil {
+		return fmt.Errorf("[SpectraFS] failed to record world operation: %w", err)
+	}
+
+	db.setWorlds(slices.DeleteFunc(slices.Clone(worlds), func(world string) bool { return world == name }))
+	return db.stripWorld(name)
+}
+
+// settleWorldOp strips the world of an AddWorld or RemoveWorld left unfinished by a crash
+func (db *DB) settleWorldOp() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var name []byte
+	err := db.withViewTx(func(tx *bbolt.Tx) error {
+		var err error
+		name, err = db.meta.Get(tx, metaPendingWorld)
+		return err
+	})
+	if err != nil || name == nil {
+		return err
+	}
+	return db.stripWorld(string(name))
+}
+
+// stripWorld deletes a world's key from every node in transactions of worldOpBatchSize, then drops
+// it from the stats and clears the pending world operation
+// NOTE: This function assumes the caller already holds db.mu lock
+func (db *DB) stripWorld(name string) error {
+	afterID := ""
+	for done := false; !done; {
+		updated := make([]*types.Node, 0, worldOpBatchSize)
+		sizes := make([]int64, 0, worldOpBatchSize)
+		err := db.withTx(func(tx *bbolt.Tx) error {
+			// Collect first: bbolt cursors must not run across writes to their bucket
+			var batch []*types.Node
+			done = true
+			err := db.nodes.Scan(tx, afterID, func(node *types.Node) (bool, error) {
+				afterID = node.ID
+				if _, ok := node.ExistenceMap[name]; ok {
+					batch = append(batch, node)
+				}
+				if len(batch) == worldOpBatchSize {
+					done = false
+					return false, nil
+				}
+				return true, nil
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, node := range batch {
+				node.ExistenceMap = maps.Clone(node.ExistenceMap)
+				delete(node.ExistenceMap, name)
+				size, err := db.nodes.Put(tx, node)
+				if err != nil {
+					return fmt.Errorf("[SpectraFS] failed to update existence map for %s: %w", node.ID, err)
+				}
+				updated = append(updated, node)
+				sizes = append(sizes, size)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("[SpectraFS] failed to remove world %s: %w", name, err)
+		}
+		db.cacheUpdates(updated, sizes)
+	}
+
+	err := db.withTx(func(tx *bbolt.Tx) error {
+		if err := db.stats.DropWorld(tx, name); err != nil {
+			return err
+		}
+		return db.meta.Delete(tx, metaPendingWorld)
+	})
+	if err != nil {
+		return fmt.Errorf("[SpectraFS] failed to finish removing world %s: %w", name, err)
+	}
+	return nil
+}
+
+// cacheUpdates mirrors committed node rewrites into the preload cache
+func (db *DB) cacheUpdates(nodes []*types.Node, sizes []int64) {
+	if db.cache == nil {
+		return
+	}
+	for i, node := range nodes {
+		db.cache.update(node, sizes[i])
+	}
+}
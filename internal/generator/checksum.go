@@ -11,11 +11,9 @@ func ComputeChecksum(data []byte) string {
 	return fmt.Sprintf("%x", hash)
 }
 
-// GenerateFileData generates 1KB of random data and returns both the data and its checksum
-// This matches the requirement for 1KB files with checksum generation
-func GenerateFileData(rng *RNG) ([]byte, string, error) {
-	// Generate 1KB (1024 bytes) of random data
-	data := make([]byte, 1024)
+// GenerateFileData generates size bytes of random data and returns both the data and its checksum
+func GenerateFileData(rng *RNG, size int64) ([]byte, string, error) {
+	data := make([]byte, size)
 	_, err := rng.Read(data)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to generate random data: %w", err)
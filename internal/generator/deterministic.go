@@ -1,8 +1,99 @@
 package generator
 
-// GenerateDeterministicFileData produces deterministic file data and checksum based on
-// a single seed value. Every call with the same seed yields the same byte pattern and checksum.
-func GenerateDeterministicFileData(baseSeed int64) ([]byte, string, error) {
-	rng := NewRNG(baseSeed)
-	return GenerateFileData(rng)
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/google/uuid"
+)
+
+// FileBinarySeed returns the file content seed of seed: file_binary_seed, or when that is unset (0)
+// one derived from the main seed, so configs with different seeds get different file content
+func FileBinarySeed(seed types.SeedConfig) int64 {
+	if seed.FileBinarySeed != 0 {
+		return seed.FileBinarySeed
+	}
+	return DeriveFileBinarySeed(seed.Seed)
+}
+
+// DeriveFileBinarySeed returns the file content seed used for the main seed when file_binary_seed is
+// unset: a hash of it, always positive, so it never reads as unset itself
+func DeriveFileBinarySeed(seed int64) int64 {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(seed))
+	hash := sha256.Sum256(append([]byte("file_binary_seed:"), buf[:]...))
+	derived := int64(binary.BigEndian.Uint64(hash[:8]) >> 1)
+	if derived == 0 {
+		return 1
+	}
+	return derived
+}
+
+// NodeID returns the ID of a generated node: a name-based (version 5) UUID in a namespace derived
+// from the main seed, named by the parent's ID and the node's name and type, so the same config
+// always generates the same IDs. Generated parents have derived IDs too, all the way up from the
+// root; keying on the parent's ID rather than its path keeps a folder generated where a moved one
+// used to be from reusing the moved folder's children's IDs. Random (version 4) UUIDs, used with
+// seed.random_ids and for every node a client creates, can never equal a derived one
+func NodeID(seed types.SeedConfig, parentID, name, nodeType string) string {
+	if seed.RandomIDs {
+		return uuid.New().String()
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(seed.Seed))
+	namespace := uuid.NewSHA1(uuid.NameSpaceOID, append([]byte("spectra:seed:"), buf[:]...))
+	return uuid.NewSHA1(namespace, []byte(parentID+"\x00"+name+"\x00"+nodeType)).String()
+}
+
+// ContentSeed returns the seed of a file's content: the file content seed (see FileBinarySeed)
+// hashed together with the node ID, or the file content seed itself when seed.identical_file_content
+// is set. Content therefore follows the node through renames and moves.
+func ContentSeed(cfg *types.Config, nodeID string) int64 {
+	fileBinarySeed := FileBinarySeed(cfg.Seed)
+	if cfg.Seed.IdenticalContent {
+		return fileBinarySeed
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(fileBinarySeed))
+	hash := sha256.New()
+	hash.Write(buf[:])
+	hash.Write([]byte(nodeID))
+	return int64(binary.BigEndian.Uint64(hash.Sum(nil)[:8]))
+}
+
+// DuplicatePoolSize is the number of shared contents that files generated as duplicates draw from
+// (see seed.duplicate_probability). A small pool makes groups of several files likely even in a small tree
+const DuplicatePoolSize = 64
+
+// DuplicateContentID returns the ContentID of one of the duplicate pool's contents. Files that draw
+// the same slot share its content seed and size (see duplicateSize), so their bytes and checksums
+// are identical wherever they are in the tree. The IDs cannot collide with node IDs, which are UUIDs
+func DuplicateContentID(slot int) string {
+	return fmt.Sprintf("dup-%d", slot)
+}
+
+// duplicateSize returns the size of a duplicate pool content, drawn from the file size range by a
+// stream keyed by the seed and the slot rather than by the folder the duplicate is generated in
+func duplicateSize(cfg *types.Config, slot int) int64 {
+	minSize, maxSize := FileSizeRange(cfg)
+	if maxSize <= minSize {
+		return minSize
+	}
+	return minSize + NodeRNG(cfg, DuplicateContentID(slot), -1).Int63n(maxSize-minSize+1)
+}
+
+// GenerateDeterministicFileData produces size bytes of deterministic file data and its checksum
+// based on a single seed value. Every call with the same seed and size yields the same byte
+// pattern and checksum, and a shorter file's content is a prefix of a longer one's.
+// The bytes are those of NewFileReader(baseSeed, size); use the reader to avoid holding
+// large files in memory.
+func GenerateDeterministicFileData(baseSeed, size int64) ([]byte, string, error) {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(NewFileReader(baseSeed, size), data); err != nil {
+		return nil, "", fmt.Errorf("failed to generate random data: %w", err)
+	}
+	return data, ComputeChecksum(data), nil
 }
@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// plannedNodes plans the whole tree of cfg and returns its nodes by path
+func plannedNodes(t *testing.T, cfg *types.Config) map[string]*types.Node {
+	t.Helper()
+	nodes := make(map[string]*types.Node)
+	planTree(t, cfg, func(_ *types.Node, children []*types.Node) {
+		for _, child := range children {
+			nodes[child.Path] = child
+		}
+	})
+	return nodes
+}
+
+func TestExistenceIndependentOfOtherWorlds(t *testing.T) {
+	one := testConfig()
+	one.Seed.MaxDepth = 4
+	one.SecondaryTables = map[string]float64{"s1": 0.6}
+	two := testConfig()
+	two.Seed.MaxDepth = 4
+	two.SecondaryTables = map[string]float64{"s1": 0.6, "s2": 0.4}
+	three := testConfig()
+	three.Seed.MaxDepth = 4
+	three.SecondaryTables = map[string]float64{"a0": 0.9, "s1": 0.6, "s2": 0.4, "z9": 0.1}
+
+	want := plannedNodes(t, one)
+	present := 0
+	for _, node := range want {
+		if node.ExistenceMap["s1"] {
+			present++
+		}
+	}
+	if present == 0 || present == len(want) {
+		t.Errorf("%d of %d nodes exist in s1, want some but not all", present, len(want))
+	}
+
+	for _, cfg := range []*types.Config{two, three, two} {
+		got := plannedNodes(t, cfg)
+		if len(got) != len(want) {
+			t.Fatalf("%d worlds planned %d nodes, one world %d", len(cfg.SecondaryTables), len(got), len(want))
+		}
+		for path, node := range want {
+			other, ok := got[path]
+			if !ok || other.ID != node.ID || other.Size != node.Size {
+				t.Fatalf("%s differs with worlds %v", path, cfg.SecondaryTables)
+			}
+			if other.ExistenceMap["s1"] != node.ExistenceMap["s1"] {
+				t.Errorf("%s: s1 existence %v with worlds %v, %v with s1 alone",
+					path, other.ExistenceMap["s1"], cfg.SecondaryTables, node.ExistenceMap["s1"])
+			}
+		}
+	}
+}
+
+func TestExistenceImpliesAncestorExistence(t *testing.T) {
+	cfg := testConfig()
+	cfg.Seed.MaxDepth = 6
+	cfg.Seed.MinFolders, cfg.Seed.MaxFolders = 2, 2
+	cfg.SecondaryTables = map[string]float64{"s1": 0.8, "s2": 0.5, "s3": 0.2}
+	cfg.WorldGeneration = map[string]types.WorldGeneration{"s2": {ExtraNodesProbability: 0.3}}
+
+	nodes := plannedNodes(t, cfg)
+	root := testRoot(cfg)
+	checked := 0
+	for path, node := range nodes {
+		for world, exists := range node.ExistenceMap {
+			if !exists {
+				continue
+			}
+			checked++
+			for parent := nodes[node.ParentPath]; parent != nil; parent = nodes[parent.ParentPath] {
+				if !parent.ExistenceMap[world] {
+					t.Errorf("%s exists in %s, its ancestor %s does not", path, world, parent.Path)
+				}
+			}
+			if !root.ExistenceMap[world] {
+				t.Errorf("%s exists in %s, which the root is missing from", path, world)
+			}
+		}
+	}
+	if checked == 0 {
+		t.Fatal("no node exists in any world")
+	}
+}
@@ -1,14 +1,19 @@
 package generator
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
+	"maps"
 	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/Project-Sylos/Spectra/internal/types"
 	"github.com/Project-Sylos/Spectra/internal/utils"
-	"github.com/google/uuid"
 )
 
 // RNG wraps math/rand.Rand for seeded random generation with thread-safety
@@ -24,6 +29,164 @@ func NewRNG(seed int64) *RNG {
 	}
 }
 
+// NodeRNG returns a generator seeded from the config seed and a path-keyed scope
+// Each folder's children draw from their own stream, so the tree does not depend on the order
+// (or concurrency) in which folders are generated. Paths stand in for node IDs, which are random
+// UUIDs and would differ between instances.
+func NodeRNG(cfg *types.Config, path string, depth int) *RNG {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(cfg.Seed.Seed))
+	binary.BigEndian.PutUint64(buf[8:], uint64(depth))
+	hash := sha256.New()
+	hash.Write(buf[:])
+	hash.Write([]byte(path))
+	return NewRNG(int64(binary.BigEndian.Uint64(hash.Sum(nil)[:8])))
+}
+
+// RollExistence builds the ExistenceMap of the child at path: primary always, and each secondary
+// world with its configured probability if the parent exists there. Each world's roll comes from
+// WorldRoll rather than a shared stream, so adding or removing a world leaves the other worlds'
+// decisions (and every other generated property) unchanged. Children of a node missing from
+// primary (an extra world-only node) inherit its ExistenceMap without rolling.
+func RollExistence(parent *types.Node, cfg *types.Config, path string, depth int) map[string]bool {
+	if !parent.ExistenceMap["primary"] {
+		return maps.Clone(parent.ExistenceMap)
+	}
+
+	// Primary is always true
+	existenceMap := map[string]bool{"primary": true}
+
+	for worldName, probability := range cfg.SecondaryTables {
+		// If parent doesn't exist in this world, child cannot exist
+		if !parent.ExistenceMap[worldName] {
+			existenceMap[worldName] = false
+			continue
+		}
+		// Parent exists, so roll dice: roll [0.0, 1.0) must be <= probability
+		existenceMap[worldName] = WorldRoll(cfg, worldName, path, depth) <= probability
+	}
+	return existenceMap
+}
+
+// WorldRoll returns the node at path's existence roll in world, in [0.0, 1.0), hashed from the
+// config seed, the world name, the path and the depth. Paths stand in for node IDs as in NodeRNG.
+func WorldRoll(cfg *types.Config, world, path string, depth int) float64 {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(cfg.Seed.Seed))
+	binary.BigEndian.PutUint64(buf[8:], uint64(depth))
+	hash := sha256.New()
+	hash.Write(buf[:])
+	hash.Write([]byte(world))
+	hash.Write([]byte{0})
+	hash.Write([]byte(path))
+	// The top 53 bits, scaled like math/rand's Float64
+	return float64(binary.BigEndian.Uint64(hash.Sum(nil)[:8])>>11) / (1 << 53)
+}
+
+// BackfillExistence decides whether an existing node exists in a world added at runtime
+// The root is in every world; other nodes need their parent in the world, must exist in primary
+// (extra nodes of other worlds never spread), and pass the same WorldRoll as generation, so a
+// world added at runtime gets the tree it would have had if it had been configured from the start
+func BackfillExistence(cfg *types.Config, world string, probability float64, node *types.Node, parentExists bool) bool {
+	if node.ParentID == "" {
+		return true
+	}
+	if !parentExists || !node.ExistenceMap["primary"] {
+		return false
+	}
+	return WorldRoll(cfg, world, node.Path, node.DepthLevel) <= probability
+}
+
+// WorldCountRanges returns the folder and file count ranges for a world's extra nodes,
+// falling back to the seed's ranges for bounds the world leaves unset
+func WorldCountRanges(cfg *types.Config, world string) (minFolders, maxFolders, minFiles, maxFiles int) {
+	minFolders, maxFolders = cfg.Seed.MinFolders, cfg.Seed.MaxFolders
+	minFiles, maxFiles = cfg.Seed.MinFiles, cfg.Seed.MaxFiles
+
+	override := cfg.WorldGeneration[world]
+	if override.MinFolders != nil {
+		minFolders = *override.MinFolders
+	}
+	if override.MaxFolders != nil {
+		maxFolders = *override.MaxFolders
+	}
+	if override.MinFiles != nil {
+		minFiles = *override.MinFiles
+	}
+	if override.MaxFiles != nil {
+		maxFiles = *override.MaxFiles
+	}
+	return minFolders, maxFolders, minFiles, maxFiles
+}
+
+// DefaultLongTailMultiplier is the factor on long-tail folders' counts when profile.long_tail_multiplier is unset
+const DefaultLongTailMultiplier = 10
+
+// EffectiveProfile returns seed.profile, or the uniform profile the flat seed ranges amount to when it is unset
+func EffectiveProfile(cfg *types.Config) types.GenerationProfile {
+	if cfg.Seed.Profile != nil {
+		return *cfg.Seed.Profile
+	}
+	return types.GenerationProfile{
+		Depths: []types.DepthProfile{{
+			MinDepth:   0,
+			MaxDepth:   cfg.Seed.MaxDepth,
+			MinFolders: &cfg.Seed.MinFolders,
+			MaxFolders: &cfg.Seed.MaxFolders,
+			MinFiles:   &cfg.Seed.MinFiles,
+			MaxFiles:   &cfg.Seed.MaxFiles,
+		}},
+	}
+}
+
+// DepthCountRanges returns the folder and file count ranges for the children of a folder at depth:
+// those of the first profile entry covering the depth, falling back to the seed's ranges
+func DepthCountRanges(cfg *types.Config, depth int) (minFolders, maxFolders, minFiles, maxFiles int) {
+	for _, level := range EffectiveProfile(cfg).Depths {
+		if depth >= level.MinDepth && depth <= level.MaxDepth {
+			return LevelCountRanges(cfg, level)
+		}
+	}
+	return cfg.Seed.MinFolders, cfg.Seed.MaxFolders, cfg.Seed.MinFiles, cfg.Seed.MaxFiles
+}
+
+// LevelCountRanges returns a profile entry's count ranges, falling back to the seed's for bounds it leaves unset
+func LevelCountRanges(cfg *types.Config, level types.DepthProfile) (minFolders, maxFolders, minFiles, maxFiles int) {
+	minFolders, maxFolders = cfg.Seed.MinFolders, cfg.Seed.MaxFolders
+	minFiles, maxFiles = cfg.Seed.MinFiles, cfg.Seed.MaxFiles
+	if level.MinFolders != nil {
+		minFolders = *level.MinFolders
+	}
+	if level.MaxFolders != nil {
+		maxFolders = *level.MaxFolders
+	}
+	if level.MinFiles != nil {
+		minFiles = *level.MinFiles
+	}
+	if level.MaxFiles != nil {
+		maxFiles = *level.MaxFiles
+	}
+	return minFolders, maxFolders, minFiles, maxFiles
+}
+
+// LongTailMultiplier returns the configured factor on long-tail folders' counts
+func LongTailMultiplier(cfg *types.Config) int {
+	if cfg.Seed.Profile != nil && cfg.Seed.Profile.LongTailMultiplier > 0 {
+		return cfg.Seed.Profile.LongTailMultiplier
+	}
+	return DefaultLongTailMultiplier
+}
+
+// MeanFolderCount returns the expected number of child folders of a folder at depth, long tail included
+func MeanFolderCount(cfg *types.Config, depth int) float64 {
+	minFolders, maxFolders, _, _ := DepthCountRanges(cfg, depth)
+	mean := float64(minFolders+maxFolders) / 2
+	if profile := cfg.Seed.Profile; profile != nil && profile.LongTailFraction > 0 {
+		mean *= 1 + profile.LongTailFraction*float64(LongTailMultiplier(cfg)-1)
+	}
+	return mean
+}
+
 // Intn returns a random integer in [0, n) with thread-safety
 func (r *RNG) Intn(n int) int {
 	r.mu.Lock()
@@ -38,6 +201,20 @@ func (r *RNG) Float64() float64 {
 	return r.rand.Float64()
 }
 
+// Seed reseeds the generator in place with thread-safety
+func (r *RNG) Seed(seed int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rand.Seed(seed)
+}
+
+// Int63n returns a random int64 in [0, n) with thread-safety
+func (r *RNG) Int63n(n int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Int63n(n)
+}
+
 // Read fills the slice with random bytes with thread-safety
 func (r *RNG) Read(p []byte) (n int, err error) {
 	r.mu.Lock()
@@ -45,13 +222,190 @@ func (r *RNG) Read(p []byte) (n int, err error) {
 	return r.rand.Read(p)
 }
 
+// DefaultTimestampStep is the spacing between sibling timestamps when seed.timestamp_step_ms is unset
+const DefaultTimestampStep = time.Millisecond
+
+// TimestampStep returns the configured spacing between sibling LastUpdated values
+func TimestampStep(cfg *types.Config) time.Duration {
+	if cfg.Seed.TimestampStepMillis > 0 {
+		return time.Duration(cfg.Seed.TimestampStepMillis) * time.Millisecond
+	}
+	return DefaultTimestampStep
+}
+
+// DefaultBaseTimestamp is where generated timestamps start when seed.base_timestamp is unset
+// It is fixed rather than the current time, so regenerating a tree reproduces its timestamps
+var DefaultBaseTimestamp = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// BaseTimestamp returns the configured seed.base_timestamp: the root's LastUpdated and the
+// earliest one a generated node can get
+func BaseTimestamp(cfg *types.Config) time.Time {
+	if cfg.Seed.BaseTimestamp != "" {
+		if base, err := time.Parse(time.RFC3339, cfg.Seed.BaseTimestamp); err == nil {
+			return base
+		}
+	}
+	return DefaultBaseTimestamp
+}
+
+// TimestampJitter returns the configured seed.timestamp_jitter (0 when unset)
+func TimestampJitter(cfg *types.Config) time.Duration {
+	jitter, err := time.ParseDuration(cfg.Seed.TimestampJitter)
+	if err != nil || jitter < 0 {
+		return 0
+	}
+	return jitter
+}
+
+// timestampRNG returns the stream a folder's child timestamps are drawn from
+// It is keyed like NodeRNG but separate from it, so enabling jitter never changes the tree itself
+func timestampRNG(cfg *types.Config, path string, depth int) *RNG {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(cfg.Seed.Seed))
+	binary.BigEndian.PutUint64(buf[8:], uint64(depth))
+	hash := sha256.New()
+	hash.Write([]byte("timestamps"))
+	hash.Write(buf[:])
+	hash.Write([]byte(path))
+	return NewRNG(int64(binary.BigEndian.Uint64(hash.Sum(nil)[:8])))
+}
+
+// MutationRNG returns the stream the mutation with sequence number seq draws from
+// It is keyed by the mutation seed alone, so mutations never change what generation produces
+func MutationRNG(seed, seq int64) *RNG {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(seed))
+	binary.BigEndian.PutUint64(buf[8:], uint64(seq))
+	hash := sha256.New()
+	hash.Write([]byte("mutations"))
+	hash.Write(buf[:])
+	return NewRNG(int64(binary.BigEndian.Uint64(hash.Sum(nil)[:8])))
+}
+
+// stampChildren sets planned children's LastUpdated: the base timestamp, plus a jitter offset per
+// child, plus index × step. The offsets are sorted before they are assigned, so siblings stay
+// strictly increasing in generation order
+func stampChildren(children []*types.Node, parent *types.Node, depth int, cfg *types.Config) {
+	base := BaseTimestamp(cfg)
+	step := TimestampStep(cfg)
+
+	offsets := make([]time.Duration, len(children))
+	if jitter := TimestampJitter(cfg); jitter > 0 {
+		rng := timestampRNG(cfg, parent.Path, depth)
+		for i := range offsets {
+			offsets[i] = time.Duration(rng.Int63n(int64(jitter) + 1))
+		}
+		slices.Sort(offsets)
+	}
+
+	for i, child := range children {
+		child.LastUpdated = base.Add(offsets[i] + time.Duration(i)*step)
+	}
+}
+
+// DefaultMetadataPool is the metadata generated nodes draw from when seed.metadata_pool is unset
+var DefaultMetadataPool = map[string][]string{
+	"owner":        {"user_{n}", "service_{n}"},
+	"content-type": {"text/plain", "application/json", "application/octet-stream", "image/png"},
+	"tags":         {"draft", "final", "archive", "batch_{n}"},
+}
+
+// MetadataPool returns the configured metadata keys and value patterns
+func MetadataPool(cfg *types.Config) map[string][]string {
+	if len(cfg.Seed.MetadataPool) > 0 {
+		return cfg.Seed.MetadataPool
+	}
+	return DefaultMetadataPool
+}
+
+// metadataRNG returns the stream a folder's child metadata is drawn from
+// It is keyed like NodeRNG but separate from it, so enabling metadata never changes the tree itself
+func metadataRNG(cfg *types.Config, path string, depth int) *RNG {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(cfg.Seed.Seed))
+	binary.BigEndian.PutUint64(buf[8:], uint64(depth))
+	hash := sha256.New()
+	hash.Write([]byte("metadata"))
+	hash.Write(buf[:])
+	hash.Write([]byte(path))
+	return NewRNG(int64(binary.BigEndian.Uint64(hash.Sum(nil)[:8])))
+}
+
+// assignMetadata gives each planned child metadata with seed.metadata_probability: a value for every
+// pool key, drawn in key order from one of its patterns with "{n}" replaced by a number below 1000
+func assignMetadata(children []*types.Node, parent *types.Node, depth int, cfg *types.Config) {
+	if cfg.Seed.MetadataProbability <= 0 || len(children) == 0 {
+		return
+	}
+
+	pool := MetadataPool(cfg)
+	keys := slices.Sorted(maps.Keys(pool))
+	rng := metadataRNG(cfg, parent.Path, depth)
+	for _, child := range children {
+		if rng.Float64() >= cfg.Seed.MetadataProbability {
+			continue
+		}
+		child.Metadata = make(map[string]string, len(keys))
+		for _, key := range keys {
+			pattern := pool[key][rng.Intn(len(pool[key]))]
+			child.Metadata[key] = strings.ReplaceAll(pattern, "{n}", strconv.Itoa(rng.Intn(1000)))
+		}
+	}
+}
+
+// DefaultFileSize is the size of every generated file when seed.min_file_size and seed.max_file_size are unset
+const DefaultFileSize int64 = 1024
+
+// DefaultFileSizeCap is the largest max_file_size accepted when seed.file_size_cap is unset
+const DefaultFileSizeCap int64 = 64 << 20
+
+// FileSizeRange returns the configured inclusive range of generated file sizes in bytes
+func FileSizeRange(cfg *types.Config) (int64, int64) {
+	if cfg.Seed.MinFileSize == 0 && cfg.Seed.MaxFileSize == 0 {
+		return DefaultFileSize, DefaultFileSize
+	}
+	return cfg.Seed.MinFileSize, cfg.Seed.MaxFileSize
+}
+
+// FileSizeCap returns the largest max_file_size the configuration accepts
+func FileSizeCap(cfg *types.Config) int64 {
+	if cfg.Seed.FileSizeCap > 0 {
+		return cfg.Seed.FileSizeCap
+	}
+	return DefaultFileSizeCap
+}
+
 // GenerateChildren generates children nodes for a given parent based on configuration
 // Returns a single list of nodes with ExistenceMap populated for each
-func GenerateChildren(parent *types.Node, depth int, rng *RNG, cfg *types.Config) ([]*types.Node, error) {
+// The draws come from NodeRNG(cfg, parent.Path, depth), so the same parent always gets the same children
+// Child counts are drawn from the seed.profile ranges for the parent's depth (see DepthCountRanges)
+// Siblings get strictly increasing LastUpdated values in generation order (folders, files, then symlinks),
+// computed from seed.base_timestamp and seed.timestamp_jitter rather than the clock (see stampChildren)
+func GenerateChildren(parent *types.Node, depth int, cfg *types.Config) ([]*types.Node, error) {
+	children, err := PlanChildren(parent, depth, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, child := range children {
+		if err := ChecksumFile(child, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	return children, nil
+}
+
+// PlanChildren draws a parent's children from its RNG like GenerateChildren but leaves file checksums unset
+// The checksums need no RNG, so ChecksumFile can fill them later (e.g. in parallel) without changing the tree
+func PlanChildren(parent *types.Node, depth int, cfg *types.Config) ([]*types.Node, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("configuration cannot be nil")
 	}
 
+	rng := NodeRNG(cfg, parent.Path, depth)
+	names := newNamer(cfg, parent, depth)
+
 	var children []*types.Node
 
 	// Don't generate children if we've reached max depth
@@ -59,116 +413,204 @@ func GenerateChildren(parent *types.Node, depth int, rng *RNG, cfg *types.Config
 		return children, nil
 	}
 
+	// Long-tail folders get a multiple of the counts drawn for their depth; nothing is drawn for the
+	// long tail unless it is enabled, and a uniform profile draws like the flat ranges, so existing
+	// seeds keep their trees
+	minFolders, maxFolders, minFiles, maxFiles := DepthCountRanges(cfg, depth)
+	multiplier := 1
+	if profile := cfg.Seed.Profile; profile != nil && profile.LongTailFraction > 0 && rng.Float64() < profile.LongTailFraction {
+		multiplier = LongTailMultiplier(cfg)
+	}
+
 	// Generate folders
-	folderCount := rng.Intn(cfg.Seed.MaxFolders-cfg.Seed.MinFolders+1) + cfg.Seed.MinFolders
+	folderCount := (rng.Intn(maxFolders-minFolders+1) + minFolders) * multiplier
 	for i := 0; i < folderCount; i++ {
-		folder, err := generateFolder(parent, i+1, depth+1, cfg, rng)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate folder %d: %w", i+1, err)
-		}
-		children = append(children, folder)
+		children = append(children, generateFolder(parent, names.folder(i+1, ""), depth+1, cfg, ""))
 	}
 
 	// Generate files
-	fileCount := rng.Intn(cfg.Seed.MaxFiles-cfg.Seed.MinFiles+1) + cfg.Seed.MinFiles
+	fileCount := (rng.Intn(maxFiles-minFiles+1) + minFiles) * multiplier
 	for i := 0; i < fileCount; i++ {
-		file, err := generateFile(parent, i+1, depth+1, cfg, rng)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate file %d: %w", i+1, err)
+		children = append(children, generateFile(parent, names.file(i+1, ""), depth+1, cfg, rng, ""))
+	}
+
+	// Nothing is drawn for symlinks unless they are enabled, so existing seeds keep their trees
+	if cfg.Seed.SymlinkProbability > 0 && rng.Float64() < cfg.Seed.SymlinkProbability {
+		children = append(children, generateSymlink(parent, 1, depth+1, cfg, rng, names, children))
+	}
+
+	// Extra world-only nodes are drawn after the primary ones, so worlds without settings leave the tree unchanged
+	if !parent.ExistenceMap["primary"] {
+		stampChildren(children, parent, depth, cfg)
+		assignMetadata(children, parent, depth, cfg)
+		return children, nil // Everything below a world-only folder is already world-only
+	}
+	for _, world := range slices.Sorted(maps.Keys(cfg.WorldGeneration)) {
+		if !parent.ExistenceMap[world] || cfg.WorldGeneration[world].ExtraNodesProbability <= 0 {
+			continue
+		}
+		if rng.Float64() >= cfg.WorldGeneration[world].ExtraNodesProbability {
+			continue
+		}
+
+		minFolders, maxFolders, minFiles, maxFiles := WorldCountRanges(cfg, world)
+		extraFolders := rng.Intn(maxFolders-minFolders+1) + minFolders
+		for i := 0; i < extraFolders; i++ {
+			children = append(children, generateFolder(parent, names.folder(i+1, world), depth+1, cfg, world))
+		}
+		extraFiles := rng.Intn(maxFiles-minFiles+1) + minFiles
+		for i := 0; i < extraFiles; i++ {
+			children = append(children, generateFile(parent, names.file(i+1, world), depth+1, cfg, rng, world))
 		}
-		children = append(children, file)
 	}
 
+	stampChildren(children, parent, depth, cfg)
+	assignMetadata(children, parent, depth, cfg)
 	return children, nil
 }
 
-// generateFolder creates a new folder node with UUID and ExistenceMap
-func generateFolder(parent *types.Node, index int, depth int, cfg *types.Config, rng *RNG) (*types.Node, error) {
-	name := fmt.Sprintf("folder_%d", index)
-	path := utils.JoinPath(parent.Path, name)
+// worldOnlyExistence returns an ExistenceMap with every world present and only world set
+func worldOnlyExistence(cfg *types.Config, world string) map[string]bool {
+	existenceMap := map[string]bool{"primary": false}
+	for worldName := range cfg.SecondaryTables {
+		existenceMap[worldName] = worldName == world
+	}
+	return existenceMap
+}
 
-	// Generate UUID for the node
-	nodeID := uuid.New().String()
+// ChecksumFile sets a planned file's checksum by streaming its deterministic content, so repeated
+// reads always return content matching the checksum and large files are never materialized
+// Folders and nodes that already have a checksum are left unchanged
+func ChecksumFile(node *types.Node, cfg *types.Config) error {
+	if node.Type != types.NodeTypeFile || node.Checksum != nil {
+		return nil
+	}
 
-	// Create existence map - ensure all worlds have keys
-	existenceMap := make(map[string]bool)
+	contentID := node.ContentID
+	if contentID == "" {
+		contentID = node.ID
+	}
+	checksum, err := DeterministicChecksum(ContentSeed(cfg, contentID), node.Size)
+	if err != nil {
+		return fmt.Errorf("failed to generate file data: %w", err)
+	}
+	node.Checksum = &checksum
+	return nil
+}
 
-	// Primary is always true
-	existenceMap["primary"] = true
+// generateFolder creates a new folder node named name with its derived ID (see NodeID) and ExistenceMap; its LastUpdated is set by stampChildren
+// A non-empty extraWorld makes it an extra node that exists only there (the namer prefixes its name with the world)
+func generateFolder(parent *types.Node, name string, depth int, cfg *types.Config, extraWorld string) *types.Node {
+	path := utils.JoinPath(parent.Path, name)
 
-	// For each secondary world, check parent existence first
-	for worldName, probability := range cfg.SecondaryTables {
-		// If parent doesn't exist in this world, child cannot exist
-		if !parent.ExistenceMap[worldName] {
-			existenceMap[worldName] = false
-		} else {
-			// Parent exists, so roll dice: roll [0.0, 1.0) must be <= probability
-			roll := rng.Float64()
-			existenceMap[worldName] = (roll <= probability)
-		}
+	// Derive the node's ID from its parent and name
+	nodeID := NodeID(cfg.Seed, parent.ID, name, types.NodeTypeFolder)
+
+	// Create existence map - ensure all worlds have keys
+	var existenceMap map[string]bool
+	if extraWorld != "" {
+		existenceMap = worldOnlyExistence(cfg, extraWorld)
+	} else {
+		existenceMap = RollExistence(parent, cfg, path, depth)
 	}
 
 	return &types.Node{
-		ID:           nodeID,
-		ParentID:     parent.ID,
-		Name:         name,
-		Path:         path,
-		ParentPath:   parent.Path,
-		Type:         types.NodeTypeFolder,
-		DepthLevel:   depth,
-		Size:         0, // Folders have size 0
-		LastUpdated:  time.Now(),
-		Checksum:     nil, // Folders don't have checksums
-		ExistenceMap: existenceMap,
-	}, nil
-}
-
-// generateFile creates a new file node with UUID and ExistenceMap
-func generateFile(parent *types.Node, index int, depth int, cfg *types.Config, rng *RNG) (*types.Node, error) {
-	name := fmt.Sprintf("file_%d.txt", index)
+		ID:              nodeID,
+		ParentID:        parent.ID,
+		Name:            name,
+		Path:            path,
+		ParentPath:      parent.Path,
+		Type:            types.NodeTypeFolder,
+		DepthLevel:      depth,
+		Size:            0,   // Folders have size 0
+		Checksum:        nil, // Folders don't have checksums
+		ExistenceMap:    existenceMap,
+		TraversalStatus: types.StatusPending,
+		CopyStatus:      types.CopyStatusPending,
+	}
+}
+
+// generateFile creates a new file node named name with its derived ID (see NodeID) and ExistenceMap; its checksum is set by
+// ChecksumFile and its LastUpdated by stampChildren. With seed.duplicate_probability set it may take one of the
+// duplicate pool's contents instead of its own (see DuplicateContentID)
+// A non-empty extraWorld makes it an extra node that exists only there (the namer prefixes its name with the world)
+func generateFile(parent *types.Node, name string, depth int, cfg *types.Config, rng *RNG, extraWorld string) *types.Node {
 	path := utils.JoinPath(parent.Path, name)
 
-	// Generate UUID for the node
-	nodeID := uuid.New().String()
+	// Derive the node's ID from its parent and name
+	nodeID := NodeID(cfg.Seed, parent.ID, name, types.NodeTypeFile)
 
-	// Generate file data and checksum deterministically so repeated reads always
-	// return identical content, regardless of node identity
-	data, checksum, err := GenerateDeterministicFileData(cfg.Seed.FileBinarySeed)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate file data: %w", err)
+	// Pick the size from the RNG; a fixed range draws nothing so the sequence is unchanged
+	minSize, maxSize := FileSizeRange(cfg)
+	size := minSize
+	if maxSize > minSize {
+		size += rng.Int63n(maxSize - minSize + 1)
+	}
+
+	// Nothing is drawn for duplicates unless they are enabled, so existing seeds keep their trees
+	var contentID string
+	if cfg.Seed.DuplicateProbability > 0 && rng.Float64() < cfg.Seed.DuplicateProbability {
+		slot := rng.Intn(DuplicatePoolSize)
+		contentID = DuplicateContentID(slot)
+		size = duplicateSize(cfg, slot)
 	}
 
 	// Create existence map - ensure all worlds have keys
-	existenceMap := make(map[string]bool)
+	var existenceMap map[string]bool
+	if extraWorld != "" {
+		existenceMap = worldOnlyExistence(cfg, extraWorld)
+	} else {
+		existenceMap = RollExistence(parent, cfg, path, depth)
+	}
 
-	// Primary is always true
-	existenceMap["primary"] = true
+	return &types.Node{
+		ID:              nodeID,
+		ParentID:        parent.ID,
+		Name:            name,
+		Path:            path,
+		ParentPath:      parent.Path,
+		Type:            types.NodeTypeFile,
+		DepthLevel:      depth,
+		Size:            size,
+		Checksum:        nil, // Filled in by ChecksumFile
+		ExistenceMap:    existenceMap,
+		TraversalStatus: types.StatusPending,
+		CopyStatus:      types.CopyStatusPending,
+		ContentID:       contentID,
+	}
+}
 
-	// For each secondary world, check parent existence first
-	for worldName, probability := range cfg.SecondaryTables {
-		// If parent doesn't exist in this world, child cannot exist
-		if !parent.ExistenceMap[worldName] {
-			existenceMap[worldName] = false
-		} else {
-			// Parent exists, so roll dice: roll [0.0, 1.0) must be <= probability
-			roll := rng.Float64()
-			existenceMap[worldName] = (roll <= probability)
-		}
+// generateSymlink creates a symlink node among parent's children, pointing at one of siblings (by
+// relative name), at parent itself (by absolute path, a cycle for tools that follow links) or at a
+// missing sibling (dangling); its LastUpdated is set by stampChildren
+func generateSymlink(parent *types.Node, index int, depth int, cfg *types.Config, rng *RNG, names *namer, siblings []*types.Node) *types.Node {
+	name := names.claim(fmt.Sprintf("link_%d", index))
+	path := utils.JoinPath(parent.Path, name)
+
+	var target string
+	switch kind := rng.Intn(3); {
+	case kind == 0 && len(siblings) > 0:
+		target = siblings[rng.Intn(len(siblings))].Name
+	case kind == 2:
+		target = names.claim(fmt.Sprintf("missing_%d", index)) // Claimed so no sibling can take the name
+	default:
+		target = parent.Path
 	}
 
 	return &types.Node{
-		ID:           nodeID,
-		ParentID:     parent.ID,
-		Name:         name,
-		Path:         path,
-		ParentPath:   parent.Path,
-		Type:         types.NodeTypeFile,
-		DepthLevel:   depth,
-		Size:         int64(len(data)),
-		LastUpdated:  time.Now(),
-		Checksum:     &checksum, // Store the computed checksum
-		ExistenceMap: existenceMap,
-	}, nil
+		ID:              NodeID(cfg.Seed, parent.ID, name, types.NodeTypeSymlink),
+		ParentID:        parent.ID,
+		Name:            name,
+		Path:            path,
+		ParentPath:      parent.Path,
+		Type:            types.NodeTypeSymlink,
+		DepthLevel:      depth,
+		Size:            int64(len(target)), // Like lstat, a symlink's size is its target's length
+		ExistenceMap:    RollExistence(parent, cfg, path, depth),
+		TraversalStatus: types.StatusPending,
+		CopyStatus:      types.CopyStatusPending,
+		Target:          target,
+	}
 }
 
 // ValidateConfig validates the generator configuration
@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// testConfig returns a small configuration; the generator can't import config.DefaultConfig
+func testConfig() *types.Config {
+	return &types.Config{
+		Seed: types.SeedConfig{
+			MaxDepth:       3,
+			MinFolders:     2,
+			MaxFolders:     3,
+			MinFiles:       2,
+			MaxFiles:       3,
+			Seed:           42,
+			FileBinarySeed: DeriveFileBinarySeed(42),
+		},
+		SecondaryTables: map[string]float64{"s1": 0.7},
+	}
+}
+
+// testRoot returns a root node present in every world of cfg
+func testRoot(cfg *types.Config) *types.Node {
+	existence := map[string]bool{"primary": true}
+	for world := range cfg.SecondaryTables {
+		existence[world] = true
+	}
+	return &types.Node{ID: "root", Path: "/", Type: types.NodeTypeFolder, ExistenceMap: existence}
+}
+
+// planTree plans the whole tree below the root down to MaxDepth, calling fn with each folder's children
+func planTree(tb testing.TB, cfg *types.Config, fn func(parent *types.Node, children []*types.Node)) {
+	tb.Helper()
+	queue := []*types.Node{testRoot(cfg)}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		children, err := PlanChildren(parent, parent.DepthLevel, cfg)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		fn(parent, children)
+		for _, child := range children {
+			if child.Type == types.NodeTypeFolder {
+				queue = append(queue, child)
+			}
+		}
+	}
+}
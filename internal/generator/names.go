@@ -0,0 +1,238 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// Name strategies for seed.name_strategy
+const (
+	NameStrategySimple    = "simple"    // folder_N and file_N.txt (the default)
+	NameStrategyRealistic = "realistic" // Built-in word lists and templates, with weighted file extensions
+	NameStrategyCustom    = "custom"    // seed.folder_name_patterns and seed.file_name_patterns templates
+)
+
+// DefaultExtensionWeights is the file extension distribution used when seed.extension_weights is unset
+var DefaultExtensionWeights = map[string]float64{
+	".txt":  0.3,
+	".jpg":  0.25,
+	".pdf":  0.2,
+	".docx": 0.1,
+	".csv":  0.1,
+	".zip":  0.05,
+}
+
+// folderWords and fileWords are the {word} lists of folder and file names
+var (
+	folderWords = []string{
+		"documents", "photos", "projects", "reports", "archive", "backup", "invoices", "music",
+		"assets", "drafts", "shared", "clients", "exports", "downloads", "templates", "research",
+	}
+	fileWords = []string{
+		"report", "invoice", "photo", "notes", "budget", "summary", "draft", "presentation",
+		"contract", "readme", "schedule", "receipt", "scan", "minutes", "plan", "proposal",
+	}
+)
+
+// realisticFolderPatterns and realisticFilePatterns are the templates of the realistic strategy
+var (
+	realisticFolderPatterns = []string{"{word}", "{word}_{num}", "{word}-{word}"}
+	realisticFilePatterns   = []string{"{word}{ext}", "{word}_{num}{ext}", "{word}-{word}{ext}", "IMG_{num}{ext}"}
+)
+
+// ValidateNaming checks seed.name_strategy and the settings it uses
+func ValidateNaming(cfg *types.Config) error {
+	switch cfg.Seed.NameStrategy {
+	case "", NameStrategySimple, NameStrategyRealistic, NameStrategyCustom:
+	default:
+		return fmt.Errorf("name_strategy must be %q, %q or %q, got %q",
+			NameStrategySimple, NameStrategyRealistic, NameStrategyCustom, cfg.Seed.NameStrategy)
+	}
+
+	total := 0.0
+	for ext, weight := range cfg.Seed.ExtensionWeights {
+		if strings.Contains(ext, "/") || (ext != "" && !strings.HasPrefix(ext, ".")) {
+			return fmt.Errorf("extension_weights key %q must be empty or start with \".\" and contain no \"/\"", ext)
+		}
+		if weight < 0 {
+			return fmt.Errorf("extension_weights weight for %q must be non-negative, got %f", ext, weight)
+		}
+		total += weight
+	}
+	if len(cfg.Seed.ExtensionWeights) > 0 && total <= 0 {
+		return fmt.Errorf("extension_weights must have a positive total weight")
+	}
+
+	for _, pattern := range slices.Concat(cfg.Seed.FolderNamePatterns, cfg.Seed.FileNamePatterns) {
+		if pattern == "" || pattern == "." || pattern == ".." || strings.Contains(pattern, "/") {
+			return fmt.Errorf("name pattern %q must be non-empty, not \".\" or \"..\", and contain no \"/\"", pattern)
+		}
+	}
+	return nil
+}
+
+// namer draws the names of one parent's generated children and keeps them unique among the siblings
+// The simple strategy draws nothing, so it leaves every other draw (and existing trees) unchanged
+type namer struct {
+	rng  *RNG // nil for the simple strategy
+	used map[string]bool
+
+	folderPatterns []string
+	filePatterns   []string
+	extensions     []string
+	weights        []float64
+	totalWeight    float64
+}
+
+// newNamer returns the namer of parent's children; its draws come from a stream keyed like NodeRNG
+// but separate from it, so the strategy never changes what parent's own children are, only their names
+// (subtrees below still differ, since each folder's stream is keyed by its path)
+func newNamer(cfg *types.Config, parent *types.Node, depth int) *namer {
+	n := &namer{used: make(map[string]bool)}
+	strategy := cfg.Seed.NameStrategy
+	if strategy == "" || strategy == NameStrategySimple {
+		return n
+	}
+
+	n.rng = nameRNG(cfg, parent.Path, depth)
+	n.folderPatterns, n.filePatterns = realisticFolderPatterns, realisticFilePatterns
+	if strategy == NameStrategyCustom {
+		if len(cfg.Seed.FolderNamePatterns) > 0 {
+			n.folderPatterns = cfg.Seed.FolderNamePatterns
+		}
+		if len(cfg.Seed.FileNamePatterns) > 0 {
+			n.filePatterns = cfg.Seed.FileNamePatterns
+		}
+	}
+
+	weights := cfg.Seed.ExtensionWeights
+	if len(weights) == 0 {
+		weights = DefaultExtensionWeights
+	}
+	n.extensions = slices.Sorted(maps.Keys(weights))
+	for _, ext := range n.extensions {
+		n.weights = append(n.weights, weights[ext])
+		n.totalWeight += weights[ext]
+	}
+	return n
+}
+
+// nameRNG returns the stream a folder's child names are drawn from
+func nameRNG(cfg *types.Config, path string, depth int) *RNG {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(cfg.Seed.Seed))
+	binary.BigEndian.PutUint64(buf[8:], uint64(depth))
+	hash := sha256.New()
+	hash.Write([]byte("names"))
+	hash.Write(buf[:])
+	hash.Write([]byte(path))
+	return NewRNG(int64(binary.BigEndian.Uint64(hash.Sum(nil)[:8])))
+}
+
+// folder returns the name of the index-th generated folder (1-based), prefixed for an extra world's node
+func (n *namer) folder(index int, extraWorld string) string {
+	name := fmt.Sprintf("folder_%d", index)
+	if n.rng != nil {
+		if drawn := n.expand(n.folderPatterns, folderWords); usableName(drawn) {
+			name = drawn
+		}
+	}
+	return n.claim(worldPrefix(extraWorld) + name)
+}
+
+// file returns the name of the index-th generated file (1-based), prefixed for an extra world's node
+func (n *namer) file(index int, extraWorld string) string {
+	name := fmt.Sprintf("file_%d.txt", index)
+	if n.rng != nil {
+		if drawn := n.expand(n.filePatterns, fileWords); usableName(drawn) {
+			name = drawn
+		}
+	}
+	return n.claim(worldPrefix(extraWorld) + name)
+}
+
+// usableName reports whether an expanded pattern can name a node (e.g. "{ext}" may expand to nothing)
+func usableName(name string) bool {
+	return name != "" && name != "." && name != ".."
+}
+
+// worldPrefix returns the name prefix of an extra world's nodes ("" for primary nodes)
+func worldPrefix(extraWorld string) string {
+	if extraWorld == "" {
+		return ""
+	}
+	return extraWorld + "_"
+}
+
+// expand draws one of patterns and fills its placeholders: {word} from words, {num} with a number
+// below 10000 and {ext} with an extension drawn by weight
+func (n *namer) expand(patterns []string, words []string) string {
+	pattern := patterns[n.rng.Intn(len(patterns))]
+
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			break
+		}
+		b.WriteString(pattern[:start])
+		switch placeholder := pattern[start : start+end+1]; placeholder {
+		case "{word}":
+			b.WriteString(words[n.rng.Intn(len(words))])
+		case "{num}":
+			b.WriteString(strconv.Itoa(n.rng.Intn(10000)))
+		case "{ext}":
+			b.WriteString(n.extension())
+		default:
+			b.WriteString(placeholder)
+		}
+		pattern = pattern[start+end+1:]
+	}
+	b.WriteString(pattern)
+	return b.String()
+}
+
+// extension draws a file extension by weight
+func (n *namer) extension() string {
+	roll := n.rng.Float64() * n.totalWeight
+	for i, weight := range n.weights {
+		if roll < weight {
+			return n.extensions[i]
+		}
+		roll -= weight
+	}
+	return n.extensions[len(n.extensions)-1]
+}
+
+// claim reserves name among the siblings; a name already taken gets "_2", "_3", ... before its extension
+func (n *namer) claim(name string) string {
+	if !n.used[name] {
+		n.used[name] = true
+		return name
+	}
+
+	ext := path.Ext(name)
+	if ext == name {
+		ext = "" // A dotfile such as ".env" has no extension to keep
+	}
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; ; i++ {
+		candidate := base + "_" + strconv.Itoa(i) + ext
+		if !n.used[candidate] {
+			n.used[candidate] = true
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"math"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestNamesUniqueAmongSiblings(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		configure func(*types.Config)
+	}{
+		{"simple", func(*types.Config) {}},
+		{"realistic", func(cfg *types.Config) { cfg.Seed.NameStrategy = NameStrategyRealistic }},
+		{"custom single name", func(cfg *types.Config) {
+			cfg.Seed.NameStrategy = NameStrategyCustom
+			cfg.Seed.FolderNamePatterns = []string{"same"}
+			cfg.Seed.FileNamePatterns = []string{"same{ext}"}
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Seed.SymlinkProbability = 1
+			cfg.Seed.MinFolders, cfg.Seed.MaxFolders = 6, 6
+			cfg.Seed.MinFiles, cfg.Seed.MaxFiles = 8, 8
+			tc.configure(cfg)
+			if err := ValidateNaming(cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			planTree(t, cfg, func(parent *types.Node, children []*types.Node) {
+				seen := make(map[string]bool)
+				for _, child := range children {
+					if seen[child.Name] {
+						t.Errorf("%s has two children named %s", parent.Path, child.Name)
+					}
+					seen[child.Name] = true
+					if !usableName(child.Name) || strings.Contains(child.Name, "/") {
+						t.Errorf("%s has a child named %q", parent.Path, child.Name)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestNamesDeterministicPerSeed(t *testing.T) {
+	names := func(seed int64) []string {
+		cfg := testConfig()
+		cfg.Seed.Seed = seed
+		cfg.Seed.NameStrategy = NameStrategyRealistic
+		var paths []string
+		planTree(t, cfg, func(_ *types.Node, children []*types.Node) {
+			for _, child := range children {
+				paths = append(paths, child.Path)
+			}
+		})
+		return paths
+	}
+
+	first, again, other := names(7), names(7), names(8)
+	if strings.Join(first, "\n") != strings.Join(again, "\n") {
+		t.Error("the same seed planned different names")
+	}
+	if strings.Join(first, "\n") == strings.Join(other, "\n") {
+		t.Error("different seeds planned the same names")
+	}
+}
+
+func TestNamesExtensionDistribution(t *testing.T) {
+	weights := map[string]float64{".txt": 0.5, ".jpg": 0.3, ".pdf": 0.2}
+	cfg := testConfig()
+	cfg.Seed.NameStrategy = NameStrategyCustom
+	cfg.Seed.FileNamePatterns = []string{"{word}_{num}{ext}"}
+	cfg.Seed.ExtensionWeights = weights
+	cfg.Seed.MaxDepth = 5
+	cfg.Seed.MinFolders, cfg.Seed.MaxFolders = 3, 3
+	cfg.Seed.MinFiles, cfg.Seed.MaxFiles = 10, 10
+
+	counts := make(map[string]int)
+	total := 0
+	planTree(t, cfg, func(_ *types.Node, children []*types.Node) {
+		for _, child := range children {
+			if child.Type == types.NodeTypeFile {
+				counts[path.Ext(child.Name)]++
+				total++
+			}
+		}
+	})
+	if total < 1000 {
+		t.Fatalf("planned only %d files", total)
+	}
+	for ext, count := range counts {
+		want, ok := weights[ext]
+		if !ok {
+			t.Errorf("%d files have the unconfigured extension %q", count, ext)
+			continue
+		}
+		if share := float64(count) / float64(total); math.Abs(share-want) > 0.05 {
+			t.Errorf("%s: %.3f of %d files, want about %.2f", ext, share, total, want)
+		}
+	}
+}
+
+func TestValidateNamingRejects(t *testing.T) {
+	for _, configure := range []func(*types.Config){
+		func(cfg *types.Config) { cfg.Seed.NameStrategy = "fancy" },
+		func(cfg *types.Config) { cfg.Seed.ExtensionWeights = map[string]float64{"txt": 1} },
+		func(cfg *types.Config) { cfg.Seed.ExtensionWeights = map[string]float64{".txt": -1} },
+		func(cfg *types.Config) { cfg.Seed.ExtensionWeights = map[string]float64{".txt": 0} },
+		func(cfg *types.Config) { cfg.Seed.FileNamePatterns = []string{"a/{word}"} },
+		func(cfg *types.Config) { cfg.Seed.FolderNamePatterns = []string{".."} },
+	} {
+		cfg := testConfig()
+		configure(cfg)
+		if err := ValidateNaming(cfg); err == nil {
+			t.Errorf("ValidateNaming accepted %+v", cfg.Seed)
+		}
+	}
+}
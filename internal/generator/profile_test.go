@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// childCounts returns the numbers of folders and files among children
+func childCounts(children []*types.Node) (folders, files int) {
+	for _, child := range children {
+		if child.Type == types.NodeTypeFolder {
+			folders++
+		} else {
+			files++
+		}
+	}
+	return folders, files
+}
+
+func TestProfileCountsByDepth(t *testing.T) {
+	five, eight, none, two, three, one := 5, 8, 0, 2, 3, 1
+	cfg := testConfig()
+	cfg.Seed.MaxDepth = 4
+	cfg.Seed.Profile = &types.GenerationProfile{Depths: []types.DepthProfile{
+		{MinDepth: 0, MaxDepth: 1, MinFolders: &five, MaxFolders: &eight, MinFiles: &none, MaxFiles: &two},
+		{MinDepth: 2, MaxDepth: 4, MinFolders: &none, MaxFolders: &three, MinFiles: &one, MaxFiles: &one},
+	}}
+
+	parents := make(map[int]int)
+	planTree(t, cfg, func(parent *types.Node, children []*types.Node) {
+		depth := parent.DepthLevel
+		if depth >= cfg.Seed.MaxDepth {
+			if len(children) > 0 {
+				t.Errorf("%s at max depth has %d children", parent.Path, len(children))
+			}
+			return
+		}
+		parents[depth]++
+		minFolders, maxFolders, minFiles, maxFiles := DepthCountRanges(cfg, depth)
+		folders, files := childCounts(children)
+		if folders < minFolders || folders > maxFolders || files < minFiles || files > maxFiles {
+			t.Errorf("%s at depth %d has %d folders and %d files, want %d-%d and %d-%d",
+				parent.Path, depth, folders, files, minFolders, maxFolders, minFiles, maxFiles)
+		}
+	})
+	for depth := range 3 {
+		if parents[depth] == 0 {
+			t.Errorf("no folders planned at depth %d", depth)
+		}
+	}
+}
+
+func TestProfileLongTail(t *testing.T) {
+	cfg := testConfig()
+	cfg.Seed.Profile = &types.GenerationProfile{LongTailFraction: 0.5, LongTailMultiplier: 10}
+
+	long, short := 0, 0
+	planTree(t, cfg, func(parent *types.Node, children []*types.Node) {
+		if parent.DepthLevel >= cfg.Seed.MaxDepth {
+			return
+		}
+		folders, files := childCounts(children)
+		switch {
+		case folders >= 20 && folders <= 30 && files >= 20 && files <= 30 && folders%10 == 0 && files%10 == 0:
+			long++
+		case folders >= 2 && folders <= 3 && files >= 2 && files <= 3:
+			short++
+		default:
+			t.Errorf("%s has %d folders and %d files, neither the normal nor the long-tail counts", parent.Path, folders, files)
+		}
+	})
+	if long == 0 || short == 0 {
+		t.Errorf("%d long-tail and %d normal folders, want both", long, short)
+	}
+}
+
+func TestProfileUniformMatchesFlatRanges(t *testing.T) {
+	paths := func(cfg *types.Config) []string {
+		var paths []string
+		planTree(t, cfg, func(_ *types.Node, children []*types.Node) {
+			for _, child := range children {
+				paths = append(paths, child.ID+child.Path)
+			}
+		})
+		return paths
+	}
+
+	flat := testConfig()
+	uniform := testConfig()
+	profile := EffectiveProfile(flat)
+	uniform.Seed.Profile = &profile
+	if !slices.Equal(paths(flat), paths(uniform)) {
+		t.Error("the synthesized uniform profile planned a different tree than the flat ranges")
+	}
+}
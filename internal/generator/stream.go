@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// FileBlockSize is the size of the independently seeded blocks file content is generated in
+const FileBlockSize = 64 << 10
+
+// blockSeedMix spreads block indexes across the seed space (64-bit golden ratio constant)
+const blockSeedMix uint64 = 0x9E3779B97F4A7C15
+
+// FileReader lazily produces the deterministic content of a file of a given size
+// Content is generated one FileBlockSize block at a time: block 0 is the start of the
+// baseSeed stream and every later block is seeded from baseSeed and its index, so any
+// offset can be reached without generating what comes before it.
+type FileReader struct {
+	baseSeed int64
+	size     int64
+	offset   int64
+
+	block    int64 // Index of the block held in buf (-1 = none)
+	buf      []byte
+	blockLen int
+	rng      *RNG // Reseeded per block
+}
+
+// NewFileReader returns a reader over size bytes of content for baseSeed
+func NewFileReader(baseSeed, size int64) *FileReader {
+	return &FileReader{baseSeed: baseSeed, size: size, block: -1}
+}
+
+// Size returns the total content length
+func (r *FileReader) Size() int64 {
+	return r.size
+}
+
+// Read implements io.Reader
+func (r *FileReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if err := r.load(r.offset / FileBlockSize); err != nil {
+		return 0, err
+	}
+
+	start := int(r.offset % FileBlockSize)
+	n := copy(p, r.buf[start:r.blockLen])
+	r.offset += int64(n)
+	return n, nil
+}
+
+// Seek implements io.Seeker
+func (r *FileReader) Seek(offset int64, whence int) (int64, error) {
+	var next int64
+	switch whence {
+	case io.SeekStart:
+		next = offset
+	case io.SeekCurrent:
+		next = r.offset + offset
+	case io.SeekEnd:
+		next = r.size + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if next < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.offset = next
+	return next, nil
+}
+
+// load generates block index into buf unless it is already there
+func (r *FileReader) load(index int64) error {
+	if r.block == index {
+		return nil
+	}
+	if r.buf == nil {
+		r.buf = make([]byte, FileBlockSize)
+		r.rng = NewRNG(0)
+	}
+
+	length := int64(FileBlockSize)
+	if remaining := r.size - index*FileBlockSize; remaining < length {
+		length = remaining
+	}
+	r.rng.Seed(blockSeed(r.baseSeed, index))
+	if _, err := r.rng.Read(r.buf[:length]); err != nil {
+		return fmt.Errorf("failed to generate random data: %w", err)
+	}
+	r.block = index
+	r.blockLen = int(length)
+	return nil
+}
+
+// blockSeed derives the seed of a content block; block 0 uses baseSeed itself
+func blockSeed(baseSeed, index int64) int64 {
+	return int64(uint64(baseSeed) ^ uint64(index)*blockSeedMix)
+}
+
+// DeterministicChecksum returns the SHA256 checksum of size bytes of content for baseSeed
+// without holding more than one block in memory
+func DeterministicChecksum(baseSeed, size int64) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, NewFileReader(baseSeed, size)); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
@@ -0,0 +1,75 @@
+// Package logging builds the slog loggers Spectra logs through and carries request-scoped ones
+// (tagged with the request ID) from the API's handlers down into spectrafs through contexts
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Log levels accepted by api.log_level
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// Log formats accepted by api.log_format
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Discard drops every record; it is the library's logger until one is set
+var Discard = slog.New(slog.DiscardHandler)
+
+// ParseLevel returns the slog level named by level ("" is info)
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case LevelDebug:
+		return slog.LevelDebug, nil
+	case LevelInfo, "":
+		return slog.LevelInfo, nil
+	case LevelWarn:
+		return slog.LevelWarn, nil
+	case LevelError:
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("log_level must be %q, %q, %q or %q, got %q", LevelDebug, LevelInfo, LevelWarn, LevelError, level)
+}
+
+// New creates a logger writing records of level and above to w as text or JSON ("" is text)
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	minLevel, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	options := &slog.HandlerOptions{Level: minLevel}
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	}
+	return nil, fmt.Errorf("log_format must be %q or %q, got %q", FormatText, FormatJSON, format)
+}
+
+// contextKey is the context key of a request-scoped logger
+type contextKey struct{}
+
+// WithLogger returns a copy of ctx carrying logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger ctx carries, or fallback if it carries none
+func FromContext(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return fallback
+}
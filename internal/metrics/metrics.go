@@ -0,0 +1,56 @@
+// Package metrics records Spectra's operational metrics through a Recorder and renders them in the
+// Prometheus text format (see Registry). Embedders with a metrics system of their own implement
+// Recorder over it instead, using Descs to register the metrics up front.
+package metrics
+
+// Metric names
+const (
+	HTTPRequests               = "spectra_http_requests_total"                  // Counter by method, route and status
+	HTTPRequestDuration        = "spectra_http_request_duration_seconds"        // Histogram by method and route
+	NodesGenerated             = "spectra_nodes_generated_total"                // Counter of nodes created by generation
+	ChildrenGenerationDuration = "spectra_children_generation_duration_seconds" // Histogram of lazy folder expansions
+	BoltTxDuration             = "spectra_bolt_tx_duration_seconds"             // Histogram by op ("view" or "update")
+	Nodes                      = "spectra_nodes"                                // Gauge of stored nodes by world
+)
+
+// Metric types, as written on Prometheus TYPE lines
+const (
+	TypeCounter   = "counter"
+	TypeHistogram = "histogram"
+	TypeGauge     = "gauge"
+)
+
+// DefaultBuckets are the histogram upper bounds in seconds, from half a millisecond to ten seconds
+var DefaultBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Desc describes one metric
+type Desc struct {
+	Name   string
+	Type   string
+	Help   string
+	Labels []string // Label names, in the order they are recorded
+}
+
+// Descs lists every metric Spectra records
+var Descs = []Desc{
+	{HTTPRequests, TypeCounter, "API requests served.", []string{"method", "route", "status"}},
+	{HTTPRequestDuration, TypeHistogram, "API request latency in seconds.", []string{"method", "route"}},
+	{NodesGenerated, TypeCounter, "Nodes created by lazy generation and GenerateAll.", nil},
+	{ChildrenGenerationDuration, TypeHistogram, "Time to generate and store a folder's children on first listing, in seconds.", nil},
+	{BoltTxDuration, TypeHistogram, "BoltDB transaction duration in seconds.", []string{"op"}},
+	{Nodes, TypeGauge, "Nodes stored per world.", []string{"world"}},
+}
+
+// Label is one label of a recorded value
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Recorder receives metric values; implementations must be safe for concurrent use
+type Recorder interface {
+	// Add adds delta to the counter name
+	Add(name string, delta float64, labels ...Label)
+	// Observe records value in the histogram name
+	Observe(name string, value float64, labels ...Label)
+}
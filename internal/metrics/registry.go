@@ -0,0 +1,209 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the Prometheus text exposition format served by Registry
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Sample is one value of a gauge
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+// Registry is an in-memory Recorder that serves what it recorded in the Prometheus text format
+// Values of names missing from Descs are kept too, without HELP; histograms use DefaultBuckets
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family         // Recorded counters and histograms by name
+	gauges   map[string]func() []Sample // Gauges read at scrape time, by name
+}
+
+// family is every series of one metric name
+type family struct {
+	kind   string
+	series map[string]*series // By label key
+}
+
+// series is the value of one label combination
+type series struct {
+	labels []Label
+	value  float64  // Counter total
+	counts []uint64 // Histogram observations per bucket (not cumulative), +Inf last
+	sum    float64  // Histogram sum
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		families: make(map[string]*family),
+		gauges:   make(map[string]func() []Sample),
+	}
+}
+
+// Add implements Recorder
+func (r *Registry) Add(name string, delta float64, labels ...Label) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.series(name, TypeCounter, labels).value += delta
+}
+
+// Observe implements Recorder
+func (r *Registry) Observe(name string, value float64, labels ...Label) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.series(name, TypeHistogram, labels)
+	if s.counts == nil {
+		s.counts = make([]uint64, len(DefaultBuckets)+1)
+	}
+	bucket, _ := slices.BinarySearch(DefaultBuckets, value)
+	s.counts[bucket]++
+	s.sum += value
+}
+
+// GaugeFunc registers a gauge whose samples fn returns on every scrape, replacing any earlier one
+func (r *Registry) GaugeFunc(name string, fn func() []Sample) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[name] = fn
+}
+
+// series returns the series of name with labels, creating it (and its family) if needed
+// NOTE: This function assumes the caller already holds r.mu
+func (r *Registry) series(name, kind string, labels []Label) *series {
+	f, ok := r.families[name]
+	if !ok {
+		f = &family{kind: kind, series: make(map[string]*series)}
+		r.families[name] = f
+	}
+	key := labelKey(labels)
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: slices.Clone(labels)}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Write writes every metric in the Prometheus text format, families sorted by name
+func (r *Registry) Write(w io.Writer) error {
+	// Gauges may query the database, so they are read before taking the lock
+	r.mu.Lock()
+	gauges := maps.Clone(r.gauges)
+	r.mu.Unlock()
+	samples := make(map[string][]Sample, len(gauges))
+	for name, fn := range gauges {
+		samples[name] = fn()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.families)+len(samples))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	for name := range samples {
+		if _, ok := r.families[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	out := bufio.NewWriter(w)
+	for _, name := range names {
+		if f, ok := r.families[name]; ok {
+			writeHeader(out, name, f.kind)
+			for _, key := range slices.Sorted(maps.Keys(f.series)) {
+				writeSeries(out, name, f.series[key])
+			}
+			continue
+		}
+		writeHeader(out, name, TypeGauge)
+		for _, sample := range samples[name] {
+			writeValue(out, name, sample.Labels, sample.Value)
+		}
+	}
+	return out.Flush()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	r.Write(w)
+}
+
+// writeHeader writes the HELP (for names in Descs) and TYPE lines of a family
+func writeHeader(out *bufio.Writer, name, kind string) {
+	for _, desc := range Descs {
+		if desc.Name == name {
+			out.WriteString("# HELP " + name + " " + desc.Help + "\n")
+			break
+		}
+	}
+	out.WriteString("# TYPE " + name + " " + kind + "\n")
+}
+
+// writeSeries writes a counter's value, or a histogram's cumulative buckets, sum and count
+func writeSeries(out *bufio.Writer, name string, s *series) {
+	if s.counts == nil {
+		writeValue(out, name, s.labels, s.value)
+		return
+	}
+	var cumulative uint64
+	for i, count := range s.counts {
+		cumulative += count
+		bound := "+Inf"
+		if i < len(DefaultBuckets) {
+			bound = formatFloat(DefaultBuckets[i])
+		}
+		writeValue(out, name+"_bucket", append(slices.Clip(s.labels), Label{"le", bound}), float64(cumulative))
+	}
+	writeValue(out, name+"_sum", s.labels, s.sum)
+	writeValue(out, name+"_count", s.labels, float64(cumulative))
+}
+
+// writeValue writes one sample line
+func writeValue(out *bufio.Writer, name string, labels []Label, value float64) {
+	out.WriteString(name)
+	if len(labels) > 0 {
+		out.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			out.WriteString(label.Name + `="` + labelEscaper.Replace(label.Value) + `"`)
+		}
+		out.WriteByte('}')
+	}
+	out.WriteString(" " + formatFloat(value) + "\n")
+}
+
+// labelEscaper escapes label values as the text format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatFloat formats a value as the text format expects
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// labelKey identifies a label combination within a family
+func labelKey(labels []Label) string {
+	var b strings.Builder
+	for _, label := range labels {
+		b.WriteString(label.Name)
+		b.WriteByte('=')
+		b.WriteString(label.Value)
+		b.WriteByte(0xff)
+	}
+	return b.String()
+}
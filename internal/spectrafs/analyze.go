@@ -0,0 +1,233 @@
+package spectrafs
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"sort"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// Analyze reports the shape of the subtree below rootID (the root if empty) in world (the ID's world
+// prefix, or primary, if empty): nodes per depth, a histogram of folders by child count and of files
+// by size, the share of its nodes in each world and path lengths. It is one ForEachNode pass, over
+// every node for the whole tree or through the path index for a subtree, so only the subtree's own
+// nodes are read. Nodes are not kept in memory, only counters and a child count per folder.
+// ctx.Err() is returned if ctx is cancelled
+func (s *SpectraFS) Analyze(ctx context.Context, rootID, world string) (*types.AnalysisReport, error) {
+	started := time.Now()
+	if rootID == "" {
+		rootID = s.root
+	}
+	rootID, prefixWorld := s.normalizeNodeID(rootID)
+	if world == "" {
+		world = prefixWorld
+	}
+	if world == "" {
+		world = "primary"
+	}
+	if err := s.checkWorld(world); err != nil {
+		return nil, err
+	}
+
+	start, err := s.db.GetNodeByID(rootID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve analysis start: %w", err)
+	}
+	if !start.ExistenceMap[world] {
+		return nil, fmt.Errorf("%w: %s does not exist in world %s", ErrNodeNotFound, start.Path, world)
+	}
+
+	worlds := []string{"primary"}
+	for name := range s.secondaryWorlds() {
+		worlds = append(worlds, name)
+	}
+	a := newAnalysis(start, world, worlds)
+	prefix := start.Path
+	if start.ID == s.root {
+		prefix = "" // Every node, in ID order, without reading the path index
+	}
+	if err := s.analyzeNodes(ctx, a, prefix); err != nil {
+		return nil, err
+	}
+
+	report := a.finish()
+	report.AnalyzedAt = time.Now().UTC()
+	report.DurationMillis = time.Since(started).Milliseconds()
+	return report, nil
+}
+
+// analyzeNodes adds every node in a's world at or below prefix ("" = the whole tree) to a in one
+// scan, counting each folder's children as they go by
+func (s *SpectraFS) analyzeNodes(ctx context.Context, a *analysis, prefix string) error {
+	type folderCount struct {
+		children int
+		folder   bool // The folder itself is in the world, not just some of its children
+	}
+	folders := make(map[string]*folderCount)
+	count := func(id string) *folderCount {
+		entry, ok := folders[id]
+		if !ok {
+			entry = &folderCount{}
+			folders[id] = entry
+		}
+		return entry
+	}
+
+	opts := types.NodeScanOptions{World: a.world, PathPrefix: prefix}
+	err := s.ForEachNode(ctx, opts, func(node *types.Node) error {
+		a.add(node)
+		if node.Type == types.NodeTypeFolder {
+			count(node.ID).folder = true
+		}
+		if node.ParentID != "" {
+			count(node.ParentID).children++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range folders {
+		if entry.folder {
+			a.addFolder(entry.children)
+		}
+	}
+	return nil
+}
+
+// analysis accumulates an AnalysisReport one node at a time
+type analysis struct {
+	report      *types.AnalysisReport
+	world       string
+	worlds      []string                     // Every world, for coverage
+	depths      map[int]*types.DepthAnalysis // Depth -> counts
+	childCounts map[int]int64                // Histogram bucket -> folders
+	fileSizes   map[int]int64                // Histogram bucket -> files
+	inWorld     map[string]int64             // World -> analyzed nodes that exist there
+	children    int64                        // Children of every analyzed folder
+	pathBytes   int64
+}
+
+// newAnalysis starts an analysis of the subtree below start in world
+func newAnalysis(start *types.Node, world string, worlds []string) *analysis {
+	return &analysis{
+		report: &types.AnalysisReport{
+			RootID:   start.ID,
+			RootPath: start.Path,
+			World:    world,
+		},
+		world:       world,
+		worlds:      worlds,
+		depths:      make(map[int]*types.DepthAnalysis),
+		childCounts: make(map[int]int64),
+		fileSizes:   make(map[int]int64),
+		inWorld:     make(map[string]int64),
+	}
+}
+
+// add counts one node
+func (a *analysis) add(node *types.Node) {
+	report := a.report
+	report.Nodes++
+
+	depth, ok := a.depths[node.DepthLevel]
+	if !ok {
+		depth = &types.DepthAnalysis{Depth: node.DepthLevel}
+		a.depths[node.DepthLevel] = depth
+	}
+	depth.Nodes++
+
+	switch node.Type {
+	case types.NodeTypeFolder:
+		report.Folders++
+		depth.Folders++
+	case types.NodeTypeFile:
+		report.Files++
+		depth.Files++
+		report.TotalBytes += node.Size
+		if report.Files == 1 || node.Size < report.MinFileSize {
+			report.MinFileSize = node.Size
+		}
+		if node.Size > report.MaxFileSize {
+			report.MaxFileSize = node.Size
+		}
+		a.fileSizes[histogramBucket(node.Size)]++
+	case types.NodeTypeSymlink:
+		report.Symlinks++
+		depth.Symlinks++
+	}
+
+	for _, world := range a.worlds {
+		if node.ExistenceMap[world] {
+			a.inWorld[world]++
+		}
+	}
+	a.pathBytes += int64(len(node.Path))
+	if len(node.Path) > report.MaxPathLength {
+		report.MaxPathLength = len(node.Path)
+	}
+}
+
+// addFolder counts the children of one analyzed folder
+func (a *analysis) addFolder(children int) {
+	a.children += int64(children)
+	a.childCounts[histogramBucket(int64(children))]++
+	if children == 0 {
+		a.report.EmptyFolders++
+	}
+}
+
+// finish derives the histograms, shares and averages from the counters
+func (a *analysis) finish() *types.AnalysisReport {
+	report := a.report
+
+	report.Depths = make([]types.DepthAnalysis, 0, len(a.depths))
+	for _, depth := range a.depths {
+		report.Depths = append(report.Depths, *depth)
+	}
+	sort.Slice(report.Depths, func(i, j int) bool { return report.Depths[i].Depth < report.Depths[j].Depth })
+
+	report.ChildCounts = histogram(a.childCounts)
+	report.FileSizes = histogram(a.fileSizes)
+	if report.Folders > 0 {
+		report.MeanChildCount = float64(a.children) / float64(report.Folders)
+	}
+	if report.Files > 0 {
+		report.MeanFileSize = float64(report.TotalBytes) / float64(report.Files)
+	}
+
+	report.WorldCoverage = make(map[string]float64, len(a.worlds))
+	for _, world := range a.worlds {
+		report.WorldCoverage[world] = 100 * float64(a.inWorld[world]) / float64(report.Nodes)
+	}
+	report.MeanPathLength = float64(a.pathBytes) / float64(report.Nodes)
+	return report
+}
+
+// histogramBucket returns the bucket of a non-negative value: 0 for 0, otherwise the bit length,
+// so bucket k holds [2^(k-1), 2^k - 1]
+func histogramBucket(value int64) int {
+	if value <= 0 {
+		return 0
+	}
+	return bits.Len64(uint64(value))
+}
+
+// histogram turns bucket counts into buckets with their bounds, smallest first
+func histogram(counts map[int]int64) []types.HistogramBucket {
+	buckets := make([]types.HistogramBucket, 0, len(counts))
+	for bucket, count := range counts {
+		entry := types.HistogramBucket{Count: count}
+		if bucket > 0 {
+			entry.Min = int64(1) << (bucket - 1)
+			entry.Max = int64(1)<<bucket - 1
+		}
+		buckets = append(buckets, entry)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Min < buckets[j].Min })
+	return buckets
+}
@@ -0,0 +1,123 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/internal/utils"
+	"github.com/google/uuid"
+)
+
+// handNode returns a node below parent that exists in primary and in s1 if inS1
+func handNode(parent *types.Node, name, nodeType string, size int64, inS1 bool) *types.Node {
+	return &types.Node{
+		ID:           uuid.New().String(),
+		ParentID:     parent.ID,
+		Name:         name,
+		Path:         utils.JoinPath(parent.Path, name),
+		ParentPath:   parent.Path,
+		Type:         nodeType,
+		DepthLevel:   parent.DepthLevel + 1,
+		Size:         size,
+		ExistenceMap: map[string]bool{"primary": true, "s1": inS1},
+	}
+}
+
+func TestAnalyzeHandBuiltTree(t *testing.T) {
+	// Nothing is ever listed, so the tree is exactly what is stored here (* = also in s1):
+	//   /a/* { x.txt (4 bytes), y.txt* (1 byte), b/ (empty) }, c.txt (10 bytes)
+	s := newTestFS(t)
+	root, err := s.db.GetNodeByID(s.root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := handNode(root, "a", types.NodeTypeFolder, 0, true)
+	b := handNode(a, "b", types.NodeTypeFolder, 0, false)
+	nodes := []*types.Node{
+		a,
+		handNode(a, "x.txt", types.NodeTypeFile, 4, false),
+		handNode(a, "y.txt", types.NodeTypeFile, 1, true),
+		b,
+		handNode(root, "c.txt", types.NodeTypeFile, 10, false),
+	}
+	if err := s.db.BulkInsertNodes(context.Background(), nodes); err != nil {
+		t.Fatal(err)
+	}
+
+	// The paths are "/", "/a", "/a/x.txt", "/a/y.txt", "/a/b" and "/c.txt" (29 bytes), and s1 holds
+	// the root, a and y.txt
+	report, err := s.Analyze(context.Background(), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAnalysis(t, "whole tree", report, &types.AnalysisReport{
+		RootID: s.root, RootPath: "/", World: "primary",
+		Nodes: 6, Folders: 3, Files: 3, TotalBytes: 15,
+		Depths: []types.DepthAnalysis{
+			{Depth: 0, Nodes: 1, Folders: 1},
+			{Depth: 1, Nodes: 2, Folders: 1, Files: 1},
+			{Depth: 2, Nodes: 3, Folders: 1, Files: 2},
+		},
+		ChildCounts:    []types.HistogramBucket{{Min: 0, Max: 0, Count: 1}, {Min: 2, Max: 3, Count: 2}}, // b; root and a
+		MeanChildCount: 5.0 / 3,
+		EmptyFolders:   1,
+		FileSizes:      []types.HistogramBucket{{Min: 1, Max: 1, Count: 1}, {Min: 4, Max: 7, Count: 1}, {Min: 8, Max: 15, Count: 1}},
+		MinFileSize:    1,
+		MaxFileSize:    10,
+		MeanFileSize:   5,
+		WorldCoverage:  map[string]float64{"primary": 100, "s1": 50},
+		MeanPathLength: 29.0 / 6,
+		MaxPathLength:  8,
+	})
+
+	report, err = s.Analyze(context.Background(), a.ID, "primary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAnalysis(t, "subtree /a", report, &types.AnalysisReport{
+		RootID: a.ID, RootPath: "/a", World: "primary",
+		Nodes: 4, Folders: 2, Files: 2, TotalBytes: 5,
+		Depths: []types.DepthAnalysis{
+			{Depth: 1, Nodes: 1, Folders: 1},
+			{Depth: 2, Nodes: 3, Folders: 1, Files: 2},
+		},
+		ChildCounts:    []types.HistogramBucket{{Min: 0, Max: 0, Count: 1}, {Min: 2, Max: 3, Count: 1}},
+		MeanChildCount: 1.5,
+		EmptyFolders:   1,
+		FileSizes:      []types.HistogramBucket{{Min: 1, Max: 1, Count: 1}, {Min: 4, Max: 7, Count: 1}},
+		MinFileSize:    1,
+		MaxFileSize:    4,
+		MeanFileSize:   2.5,
+		WorldCoverage:  map[string]float64{"primary": 100, "s1": 50},
+		MeanPathLength: 22.0 / 4,
+		MaxPathLength:  8,
+	})
+
+	// In s1 the subtree is just a and y.txt, and b is not there to start from
+	report, err = s.Analyze(context.Background(), a.ID, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Nodes != 2 || report.Files != 1 || report.TotalBytes != 1 || report.EmptyFolders != 0 || report.MeanChildCount != 1 {
+		t.Errorf("subtree /a in s1: %+v, want a with y.txt as its only child", report)
+	}
+	if _, err := s.Analyze(context.Background(), b.ID, "s1"); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("analyzing /a/b in s1, where it does not exist = %v, want ErrNodeNotFound", err)
+	}
+	if _, err := s.Analyze(context.Background(), "", "s9"); !errors.Is(err, ErrUnknownWorld) {
+		t.Errorf("analyzing an unknown world = %v, want ErrUnknownWorld", err)
+	}
+}
+
+// checkAnalysis compares a report with want, ignoring when it ran
+func checkAnalysis(t *testing.T, name string, got, want *types.AnalysisReport) {
+	t.Helper()
+	copied := *got
+	copied.AnalyzedAt, copied.DurationMillis = want.AnalyzedAt, want.DurationMillis
+	if !reflect.DeepEqual(&copied, want) {
+		t.Errorf("%s:\n got %+v\nwant %+v", name, &copied, want)
+	}
+}
@@ -0,0 +1,237 @@
+package spectrafs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/generator"
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/internal/utils"
+	"github.com/google/uuid"
+)
+
+// MaxBatchCreateSize is the maximum number of ops accepted by a single BatchCreate call
+const MaxBatchCreateSize = 1000
+
+// ErrInvalidBatch is returned by BatchCreate for an empty or oversized batch or a repeated key
+var ErrInvalidBatch = newError(ErrInvalidInput, "invalid batch")
+
+// batchCreate holds the nodes created so far by one BatchCreate call
+type batchCreate struct {
+	byKey  map[string]*types.Node
+	byID   map[string]*types.Node
+	byPath map[string]*types.Node
+}
+
+// BatchCreate creates folders and files in request order and reports a per-op result
+// Consecutive ops that succeed are stored with a single bulk insert; an op that fails ends the run,
+// and a failed insert fails every op of its run. An op may name its parent by the Key of a folder
+// created earlier in the batch (ParentKey); ops whose keyed parent failed fail too. Parent IDs and
+// paths also see nodes created earlier in the batch. New paths must be free, both in the database
+// and within the batch (ErrPathExists). File content is deterministic, as with UploadFile.
+// Returns ErrInvalidBatch if the batch is empty, exceeds MaxBatchCreateSize, or repeats a key
+func (s *SpectraFS) BatchCreate(ctx context.Context, ops []models.BatchOp) (*types.BatchCreateResult, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("%w: no ops", ErrInvalidBatch)
+	}
+	if len(ops) > MaxBatchCreateSize {
+		return nil, fmt.Errorf("%w: %d ops exceeds maximum of %d", ErrInvalidBatch, len(ops), MaxBatchCreateSize)
+	}
+	keys := make(map[string]bool)
+	for i := range ops {
+		if key := ops[i].Key; key != "" {
+			if keys[key] {
+				return nil, fmt.Errorf("%w: key %q is used more than once", ErrInvalidBatch, key)
+			}
+			keys[key] = true
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	b := &batchCreate{
+		byKey:  make(map[string]*types.Node),
+		byID:   make(map[string]*types.Node),
+		byPath: make(map[string]*types.Node),
+	}
+	result := &types.BatchCreateResult{
+		Results: make([]types.BatchOpResult, len(ops)),
+	}
+
+	var run []*types.Node
+	var runOps []int
+	var changes []change
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if err := s.db.BulkInsertNodes(ctx, run); err != nil {
+			for j, i := range runOps {
+				res := &result.Results[i]
+				res.Success = false
+				res.Node = nil
+				res.Error = fmt.Sprintf("failed to insert nodes: %v", err)
+				b.forget(&ops[i], run[j])
+			}
+		} else {
+			for _, node := range run {
+				changes = append(changes, nodeChange(types.ChangeCreate, node, 1))
+			}
+		}
+		run, runOps = nil, nil
+	}
+
+	for i := range ops {
+		op := &ops[i]
+		res := &result.Results[i]
+		res.Index = i
+		res.Key = op.Key
+
+		node, err := s.batchNode(ctx, b, op)
+		if err != nil {
+			flush()
+			res.Error = err.Error()
+			continue
+		}
+
+		b.remember(op, node)
+		res.Success = true
+		res.Node = node
+		run = append(run, node)
+		runOps = append(runOps, i)
+	}
+	flush()
+
+	if err := s.journal(changes...); err != nil {
+		return nil, err
+	}
+
+	for _, res := range result.Results {
+		if res.Success {
+			result.Created++
+		} else {
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// batchNode validates one op and builds its node, resolving the parent against the batch first
+func (s *SpectraFS) batchNode(ctx context.Context, b *batchCreate, op *models.BatchOp) (*types.Node, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if op.Op != types.NodeTypeFolder && op.Op != types.NodeTypeFile {
+		return nil, fmt.Errorf("%w: op must be %q or %q, got %q", ErrInvalidInput, types.NodeTypeFolder, types.NodeTypeFile, op.Op)
+	}
+	name := op.GetName()
+	if strings.TrimSpace(name) == "" || strings.Contains(name, "/") || name == "." || name == ".." {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	if op.Op == types.NodeTypeFile && len(op.GetData()) == 0 {
+		return nil, fmt.Errorf("%w: data is required", ErrInvalidInput)
+	}
+
+	parent, err := s.batchParent(b, op)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent node: %w", err)
+	}
+	if parent.Type != types.NodeTypeFolder {
+		return nil, fmt.Errorf("%w: parent %s is not a folder", ErrInvalidInput, parent.ID)
+	}
+
+	path := utils.JoinPath(parent.Path, name)
+	if b.byPath[path] != nil {
+		return nil, fmt.Errorf("%w: %s", ErrPathExists, path)
+	}
+	exists, err := s.db.PathExists(path)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("%w: %s", ErrPathExists, path)
+	}
+
+	node := &types.Node{
+		ID:              uuid.New().String(),
+		ParentID:        parent.ID,
+		Name:            name,
+		Path:            path,
+		ParentPath:      parent.Path,
+		Type:            op.Op,
+		DepthLevel:      parent.DepthLevel + 1,
+		LastUpdated:     time.Now(),
+		ExistenceMap:    generator.RollExistence(parent, s.config(), path, parent.DepthLevel+1),
+		TraversalStatus: types.StatusPending,
+		CopyStatus:      types.CopyStatusPending,
+	}
+
+	if op.Op == types.NodeTypeFile {
+		// Generate deterministic file data metadata (data itself is not persisted)
+		data, checksum, err := generator.GenerateDeterministicFileData(generator.ContentSeed(s.config(), node.ID), generator.DefaultFileSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate file data: %w", err)
+		}
+		node.Size = int64(len(data))
+		node.Checksum = &checksum
+	}
+	return node, nil
+}
+
+// batchParent resolves an op's parent: by ParentKey, or by ParentID / ParentPath among the nodes
+// created earlier in the batch and then in the database
+func (s *SpectraFS) batchParent(b *batchCreate, op *models.BatchOp) (*types.Node, error) {
+	if op.ParentKey != "" {
+		parent := b.byKey[op.ParentKey]
+		if parent == nil {
+			return nil, fmt.Errorf("%w: no node was created earlier in the batch with key %q", ErrParentNotFound, op.ParentKey)
+		}
+		return parent, nil
+	}
+
+	if err := validateRequest(models.ValidateParentIdentifier(op)); err != nil {
+		return nil, err
+	}
+	if op.ParentID != "" {
+		parentID, _ := s.normalizeNodeID(op.ParentID)
+		if parent := b.byID[parentID]; parent != nil {
+			return parent, nil
+		}
+	} else if parent := b.byPath[op.ParentPath]; parent != nil {
+		if !parent.ExistenceMap[op.TableName] {
+			return nil, fmt.Errorf("%w: %w with path %s in world %s", ErrParentNotFound, ErrNodeNotFound, op.ParentPath, op.TableName)
+		}
+		return parent, nil
+	}
+
+	parent, _, err := s.resolveNodeAndWorld(op)
+	return parent, err
+}
+
+// remember records a node built for op so later ops can name it as their parent
+func (b *batchCreate) remember(op *models.BatchOp, node *types.Node) {
+	if op.Key != "" {
+		b.byKey[op.Key] = node
+	}
+	b.byID[node.ID] = node
+	b.byPath[node.Path] = node
+}
+
+// forget drops a node whose insert failed, so later ops cannot use it as their parent
+func (b *batchCreate) forget(op *models.BatchOp, node *types.Node) {
+	if op.Key != "" {
+		delete(b.byKey, op.Key)
+	}
+	delete(b.byID, node.ID)
+	delete(b.byPath, node.Path)
+}
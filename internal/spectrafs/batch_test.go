@@ -0,0 +1,69 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestBatchCreateResolvesKeysAndReportsFailures(t *testing.T) {
+	s := newTestFS(t)
+	data := []byte("content")
+	ops := []models.BatchOp{
+		{Op: types.NodeTypeFolder, Key: "a", ParentID: s.root, Name: "a"},
+		{Op: types.NodeTypeFolder, Key: "b", ParentKey: "a", Name: "b"},
+		{Op: types.NodeTypeFile, ParentPath: "/a/b", TableName: "primary", Name: "f.txt", Data: data},
+		{Op: types.NodeTypeFile, ParentID: s.root, Name: "a", Data: data},         // Path taken earlier in the batch
+		{Op: types.NodeTypeFile, ParentKey: "missing", Name: "g.txt", Data: data}, // Unknown key
+		{Op: types.NodeTypeFile, ParentKey: "a", Name: "h.txt", Data: data},
+	}
+	result, err := s.BatchCreate(context.Background(), ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Created != 4 || result.Failed != 2 {
+		t.Fatalf("BatchCreate created %d and failed %d, want 4 and 2: %+v", result.Created, result.Failed, result.Results)
+	}
+	for i, want := range []bool{true, true, true, false, false, true} {
+		if res := result.Results[i]; res.Index != i || res.Success != want || (res.Error == "") != want {
+			t.Errorf("op %d = %+v, want success %v", i, res, want)
+		}
+	}
+
+	// Created nodes are stored under the parents the batch resolved
+	nodes := storedNodes(t, s)
+	for path, parentPath := range map[string]string{"/a": "/", "/a/b": "/a", "/a/b/f.txt": "/a/b", "/a/h.txt": "/a"} {
+		node, err := s.db.GetNodeByPath(path, "")
+		if err != nil {
+			t.Errorf("%s not stored: %v", path, err)
+			continue
+		}
+		if parent := nodes[node.ParentID]; parent == nil || parent.Path != parentPath {
+			t.Errorf("%s stored under %v, want %s", path, parent, parentPath)
+		}
+	}
+	if result.Results[2].Node.Size == 0 || result.Results[2].Node.Checksum == nil {
+		t.Errorf("batch file = %+v, want deterministic content", result.Results[2].Node)
+	}
+}
+
+func TestBatchCreateRejectsInvalidBatches(t *testing.T) {
+	s := newTestFS(t)
+	ctx := context.Background()
+	if _, err := s.BatchCreate(ctx, nil); !errors.Is(err, ErrInvalidBatch) {
+		t.Errorf("empty batch = %v, want ErrInvalidBatch", err)
+	}
+	repeated := []models.BatchOp{
+		{Op: types.NodeTypeFolder, Key: "k", ParentID: s.root, Name: "x"},
+		{Op: types.NodeTypeFolder, Key: "k", ParentID: s.root, Name: "y"},
+	}
+	if _, err := s.BatchCreate(ctx, repeated); !errors.Is(err, ErrInvalidBatch) {
+		t.Errorf("batch repeating a key = %v, want ErrInvalidBatch", err)
+	}
+	if _, err := s.BatchCreate(ctx, make([]models.BatchOp, MaxBatchCreateSize+1)); !errors.Is(err, ErrInvalidBatch) {
+		t.Errorf("oversized batch = %v, want ErrInvalidBatch", err)
+	}
+}
@@ -0,0 +1,109 @@
+package spectrafs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// storedTotals returns the stored node count and file bytes from the stats counters
+func storedTotals(t *testing.T, s *SpectraFS) (int64, int64) {
+	t.Helper()
+	stats, err := s.db.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stats.TotalNodes, stats.TotalFileSize
+}
+
+// budgets are the tiny budgets the tests run under, each allowing a few folders' children
+var budgets = map[string]func(*types.Config){
+	"max_total_nodes": func(cfg *types.Config) { cfg.Seed.MaxTotalNodes = 20 },
+	"max_total_bytes": func(cfg *types.Config) { cfg.Seed.MaxTotalBytes = 10 * 1024 },
+}
+
+// checkBudget fails the test if the stored totals overshoot the budget by more than one folder's children
+func checkBudget(t *testing.T, s *SpectraFS) {
+	t.Helper()
+	nodes, bytes := storedTotals(t, s)
+	seed := s.config().Seed
+	batch := int64(seed.MaxFolders + seed.MaxFiles)
+	if seed.MaxTotalNodes > 0 && nodes > seed.MaxTotalNodes+batch {
+		t.Errorf("%d nodes stored, budget %d plus one batch of %d", nodes, seed.MaxTotalNodes, batch)
+	}
+	if seed.MaxTotalBytes > 0 && bytes > seed.MaxTotalBytes+int64(seed.MaxFiles)*1024 {
+		t.Errorf("%d bytes stored, budget %d plus one batch", bytes, seed.MaxTotalBytes)
+	}
+}
+
+func TestBudgetStopsGenerateAll(t *testing.T) {
+	for name, configure := range budgets {
+		t.Run(name, func(t *testing.T) {
+			s := newTestFS(t, configure)
+			progress, err := s.GenerateAll(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !progress.BudgetExhausted {
+				t.Error("GenerateAll finished without reporting the budget exhausted")
+			}
+			checkBudget(t, s)
+
+			// Re-running stores nothing more
+			before, _ := storedTotals(t, s)
+			if _, err := s.GenerateAll(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if after, _ := storedTotals(t, s); after != before {
+				t.Errorf("second run stored %d more nodes", after-before)
+			}
+		})
+	}
+}
+
+func TestBudgetStopsLazyListing(t *testing.T) {
+	for name, configure := range budgets {
+		t.Run(name, func(t *testing.T) {
+			s := newTestFS(t, configure)
+
+			exhausted := 0
+			queue := []string{s.root}
+			for len(queue) > 0 {
+				result := list(t, s, &models.ListChildrenRequest{ParentID: queue[0], TableName: "primary"})
+				queue = queue[1:]
+				if result.Message == BudgetExhaustedMessage {
+					exhausted++
+					if len(result.Folders)+len(result.Files) > 0 {
+						t.Errorf("budget-exhausted listing returned %d children", len(result.Folders)+len(result.Files))
+					}
+				}
+				for _, folder := range result.Folders {
+					queue = append(queue, folder.ID)
+				}
+			}
+			if exhausted == 0 {
+				t.Error("no listing reported the budget exhausted")
+			}
+			checkBudget(t, s)
+		})
+	}
+}
+
+func TestBudgetRaisedLetsFolderExpand(t *testing.T) {
+	s := newTestFS(t, func(cfg *types.Config) { cfg.Seed.MaxTotalNodes = 1 })
+	folder := childNodes(list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}))[0]
+	if result := list(t, s, &models.ListChildrenRequest{ParentID: folder.ID, TableName: "primary"}); result.Message != BudgetExhaustedMessage {
+		t.Fatalf("listing %s under a spent budget: %q", folder.Path, result.Message)
+	}
+
+	cfg := *s.config()
+	cfg.Seed.MaxTotalNodes = 0
+	if err := s.UpdateConfig(context.Background(), &cfg, false); err != nil {
+		t.Fatal(err)
+	}
+	if result := list(t, s, &models.ListChildrenRequest{ParentID: folder.ID, TableName: "primary"}); len(result.Folders)+len(result.Files) == 0 {
+		t.Errorf("listing %s after lifting the budget: %q with no children", folder.Path, result.Message)
+	}
+}
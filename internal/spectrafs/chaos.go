@@ -0,0 +1,134 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/config"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// ErrChaosInjected is matched (errors.Is) by every failure injected by chaos rules
+var ErrChaosInjected = errors.New("chaos: injected failure")
+
+// ChaosError is an injected failure, carrying the HTTP status the API reports for it
+type ChaosError struct {
+	Op   string
+	Code int
+}
+
+// Error describes the injected failure
+func (e *ChaosError) Error() string {
+	return fmt.Sprintf("%v: %s (status %d)", ErrChaosInjected, e.Op, e.Code)
+}
+
+// Unwrap lets errors.Is match ErrChaosInjected
+func (e *ChaosError) Unwrap() error {
+	return ErrChaosInjected
+}
+
+// chaos holds the live chaos rules and their RNG, which is independent of generation
+type chaos struct {
+	mu    sync.Mutex
+	rules types.ChaosConfig
+	rng   *rand.Rand
+}
+
+// newChaos creates the chaos state for a validated configuration
+func newChaos(rules types.ChaosConfig) *chaos {
+	c := &chaos{}
+	c.set(rules)
+	return c
+}
+
+// set replaces the rules and reseeds the RNG; callers hold c.mu or own c exclusively
+func (c *chaos) set(rules types.ChaosConfig) {
+	seed := rules.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rules.Operations = maps.Clone(rules.Operations)
+	c.rules = rules
+	c.rng = rand.New(rand.NewSource(seed))
+}
+
+// roll draws the delay and outcome of one call to op; ok is false if no rule applies
+func (c *chaos) roll(op string) (delay time.Duration, code int, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rule, ok := c.rules.Operations[op]
+	if !ok {
+		if rule, ok = c.rules.Operations[types.ChaosOpAny]; !ok {
+			return 0, 0, false
+		}
+	}
+
+	if len(rule.LatencyMS) == 2 {
+		ms := rule.LatencyMS[0]
+		if spread := rule.LatencyMS[1] - rule.LatencyMS[0]; spread > 0 {
+			ms += c.rng.Intn(spread + 1)
+		}
+		delay = time.Duration(ms) * time.Millisecond
+	}
+	if rule.ErrorRate > 0 && c.rng.Float64() < rule.ErrorRate {
+		code = rule.ErrorCode
+		if code == 0 {
+			code = http.StatusServiceUnavailable
+		}
+	}
+	return delay, code, true
+}
+
+// ChaosSettings returns a copy of the chaos rules currently in force
+func (s *SpectraFS) ChaosSettings() types.ChaosConfig {
+	s.chaos.mu.Lock()
+	defer s.chaos.mu.Unlock()
+
+	rules := s.chaos.rules
+	rules.Operations = maps.Clone(rules.Operations)
+	return rules
+}
+
+// SetChaos replaces the chaos rules at runtime and reseeds the chaos RNG
+// An empty configuration turns chaos off. The configuration file is not rewritten
+func (s *SpectraFS) SetChaos(rules types.ChaosConfig) error {
+	if err := config.ValidateChaos(&rules); err != nil {
+		return err
+	}
+
+	s.chaos.mu.Lock()
+	defer s.chaos.mu.Unlock()
+	s.chaos.set(rules)
+	return nil
+}
+
+// InjectChaos applies the rule for op (or the "*" rule): it sleeps for the drawn latency and
+// then returns a *ChaosError if the call was drawn to fail. Returns nil if no rule applies,
+// or ctx.Err() if ctx is cancelled during the delay
+func (s *SpectraFS) InjectChaos(ctx context.Context, op string) error {
+	delay, code, ok := s.chaos.roll(op)
+	if !ok {
+		return nil
+	}
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+	if code != 0 {
+		return &ChaosError{Op: op, Code: code}
+	}
+	return ctx.Err()
+}
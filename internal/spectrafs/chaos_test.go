@@ -0,0 +1,70 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestChaosInjectsPerOperation(t *testing.T) {
+	s := newTestFS(t)
+	ctx := context.Background()
+	err := s.SetChaos(types.ChaosConfig{Seed: 1, Operations: map[string]types.ChaosRule{
+		types.ChaosOpList: {ErrorRate: 1, ErrorCode: http.StatusTooManyRequests},
+		types.ChaosOpGet:  {LatencyMS: []int{20, 20}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var chaosErr *ChaosError
+	if err := s.InjectChaos(ctx, types.ChaosOpList); !errors.As(err, &chaosErr) || !errors.Is(err, ErrChaosInjected) || chaosErr.Code != http.StatusTooManyRequests {
+		t.Errorf("list = %v, want an injected 429", err)
+	}
+
+	start := time.Now()
+	if err := s.InjectChaos(ctx, types.ChaosOpGet); err != nil || time.Since(start) < 20*time.Millisecond {
+		t.Errorf("get = %v after %v, want no error after 20ms", err, time.Since(start))
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := s.InjectChaos(cancelled, types.ChaosOpGet); !errors.Is(err, context.Canceled) {
+		t.Errorf("get with a cancelled context = %v, want context.Canceled", err)
+	}
+
+	if err := s.InjectChaos(ctx, types.ChaosOpRead); err != nil {
+		t.Errorf("read without a rule = %v, want nil", err)
+	}
+	if err := s.SetChaos(types.ChaosConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.InjectChaos(ctx, types.ChaosOpList); err != nil {
+		t.Errorf("list after chaos was turned off = %v, want nil", err)
+	}
+}
+
+func TestChaosLeavesGenerationUnchanged(t *testing.T) {
+	reference := newTestFS(t)
+	want := childNodes(list(t, reference, &models.ListChildrenRequest{ParentID: reference.root, TableName: "primary"}))
+
+	s := newTestFS(t, func(cfg *types.Config) {
+		cfg.Chaos = types.ChaosConfig{Seed: 7, Operations: map[string]types.ChaosRule{types.ChaosOpAny: {ErrorRate: 0.5}}}
+	})
+	for range 10 {
+		s.InjectChaos(context.Background(), types.ChaosOpList)
+	}
+	got := childNodes(list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}))
+	if len(got) != len(want) {
+		t.Fatalf("with chaos the root has %d children, without %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].ID != want[i].ID || got[i].Size != want[i].Size {
+			t.Errorf("child %d with chaos = %s (%d bytes), without %s (%d bytes)", i, got[i].Path, got[i].Size, want[i].Path, want[i].Size)
+		}
+	}
+}
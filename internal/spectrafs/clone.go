@@ -0,0 +1,37 @@
+package spectrafs
+
+import (
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// Identity returns this instance's identity: instance ID, seed, and clone lineage
+func (s *SpectraFS) Identity() (*types.InstanceIdentity, error) {
+	return s.db.Identity()
+}
+
+// Clone copies the live database to targetDBPath without stopping this instance
+// The copy is a consistent snapshot with a new instance ID, a cloned-from reference to this
+// instance, and the same seed. Open it with a config whose seed.db_path is targetDBPath;
+// the two databases are independent from then on.
+func (s *SpectraFS) Clone(targetDBPath string) error {
+	if targetDBPath == "" {
+		return fmt.Errorf("%w: clone target path cannot be empty", ErrInvalidInput)
+	}
+	if db.IsMemoryPath(targetDBPath) {
+		return fmt.Errorf("%w: clone target must be a file path, not %s", ErrInvalidInput, db.MemoryPath)
+	}
+	if targetDBPath == s.config().Seed.DBPath {
+		return fmt.Errorf("%w: clone target must differ from the source database path", ErrInvalidInput)
+	}
+
+	s.exclusive.Lock()
+	defer s.exclusive.Unlock()
+
+	if _, err := s.db.CloneTo(targetDBPath); err != nil {
+		return err
+	}
+	return nil
+}
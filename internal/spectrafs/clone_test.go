@@ -0,0 +1,80 @@
+package spectrafs
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+)
+
+// rootNames returns the names listed under the root in world primary
+func rootNames(t *testing.T, s *SpectraFS) []string {
+	t.Helper()
+	var names []string
+	for _, node := range childNodes(list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"})) {
+		names = append(names, node.Name)
+	}
+	return names
+}
+
+func TestCloneDivergesWithLineage(t *testing.T) {
+	source := newTestFS(t, onDisk(t))
+	before := rootNames(t, source)
+
+	target := filepath.Join(t.TempDir(), "clone.db")
+	if err := source.Clone(target); err != nil {
+		t.Fatal(err)
+	}
+	cfg := *source.config()
+	cfg.Seed.DBPath = target
+	clone := openTestFS(t, &cfg)
+
+	sourceID, err := source.Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cloneID, err := clone.Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cloneID.InstanceID == sourceID.InstanceID || cloneID.ClonedFrom != sourceID.InstanceID || cloneID.ClonedAt == nil {
+		t.Errorf("clone identity %+v, source %+v", cloneID, sourceID)
+	}
+	if cloneID.Seed != sourceID.Seed {
+		t.Errorf("clone seed %d, source %d", cloneID.Seed, sourceID.Seed)
+	}
+	if got := rootNames(t, clone); !slices.Equal(got, before) {
+		t.Fatalf("clone lists %v, source listed %v", got, before)
+	}
+
+	mkdir(t, source, source.root, "only-source")
+	mkdir(t, clone, clone.root, "only-clone")
+
+	sourceNames, cloneNames := rootNames(t, source), rootNames(t, clone)
+	if !slices.Contains(sourceNames, "only-source") || slices.Contains(sourceNames, "only-clone") {
+		t.Errorf("source lists %v", sourceNames)
+	}
+	if !slices.Contains(cloneNames, "only-clone") || slices.Contains(cloneNames, "only-source") {
+		t.Errorf("clone lists %v", cloneNames)
+	}
+}
+
+func TestCloneRejectsTargets(t *testing.T) {
+	s := newTestFS(t, onDisk(t))
+	for _, target := range []string{"", db.MemoryPath, s.config().Seed.DBPath} {
+		if err := s.Clone(target); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Clone(%q) = %v, want ErrInvalidInput", target, err)
+		}
+	}
+
+	existing := filepath.Join(t.TempDir(), "clone.db")
+	if err := s.Clone(existing); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Clone(existing); err == nil {
+		t.Error("Clone over an existing file succeeded")
+	}
+}
@@ -0,0 +1,37 @@
+package spectrafs
+
+import (
+	"context"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// ErrCompactUnsupported is returned by Compact on an in-memory database
+var ErrCompactUnsupported = db.ErrCompactUnsupported
+
+// Compact rewrites the database file with only its live pages, so the space freed by a Reset or by
+// large deletes is returned to the filesystem, and reports the file size before and after. Writes,
+// lazy generation and exclusive operations wait for it; reads carry on. db.compact_on_close_ratio
+// does the same on Close when enough of the file is free
+func (s *SpectraFS) Compact() (*types.CompactResult, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	s.exclusive.Lock()
+	defer s.exclusive.Unlock()
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	return s.db.Compact("")
+}
+
+// MigrateNodeEncoding rewrites the nodes still stored as JSON by a database created before the
+// binary node encoding, so every read decodes the faster format; nodes are otherwise rewritten only
+// when they change. It runs in batches between other writes and can be cancelled and run again
+func (s *SpectraFS) MigrateNodeEncoding(ctx context.Context) (*types.NodeEncodingResult, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	return s.db.MigrateNodeEncoding(ctx)
+}
@@ -0,0 +1,274 @@
+package spectrafs
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/internal/utils"
+	"github.com/google/uuid"
+)
+
+// copyBatchSize is the number of copies inserted per BulkInsertNodes transaction
+const copyBatchSize = 1000
+
+// ErrInvalidCopyStatus is returned when a status is not "pending", "in_progress" or "completed"
+var ErrInvalidCopyStatus = newError(ErrInvalidInput, "invalid copy status")
+
+// copyStatusCursorScope scopes ListNodesByCopyStatus cursors so they cannot be replayed against children listings
+const copyStatusCursorScope = "copy_status:"
+
+// ErrInvalidCopyOptions is returned when CopyOptions try to hide copies from primary
+var ErrInvalidCopyOptions = newError(ErrInvalidInput, "invalid copy options")
+
+// CopySubtree duplicates a node and all of its descendants under dstParentID
+// Copies get new UUIDs but keep names, types, sizes, checksums, content and timestamps, with
+// paths and depths rebuilt under the destination. They are inserted in batches with CopyStatus
+// in_progress and marked completed once every batch is stored. The source is snapshotted first,
+// so copying a folder into its own subtree copies it once. Cancelling ctx stops the copy between
+// batches, leaving the batches already stored in_progress.
+func (s *SpectraFS) CopySubtree(ctx context.Context, srcID, dstParentID string, opts types.CopyOptions) (*types.CopyResult, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := s.validateCopyOptions(opts); err != nil {
+		return nil, err
+	}
+
+	srcID, _ = s.normalizeNodeID(srcID)
+	dstParentID, _ = s.normalizeNodeID(dstParentID)
+	dstParent, err := s.db.GetNodeByID(dstParentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve destination parent: %w", err)
+	}
+	if dstParent.Type != types.NodeTypeFolder {
+		return nil, fmt.Errorf("%w: %s", ErrMoveTargetNotDir, dstParent.Path)
+	}
+
+	source, err := s.db.GetSubtree(srcID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source subtree: %w", err)
+	}
+	if opts.OnlyWorld != "" && !source[0].ExistenceMap[opts.OnlyWorld] {
+		return nil, fmt.Errorf("%w: %s does not exist in world %s", ErrNodeNotFound, source[0].Path, opts.OnlyWorld)
+	}
+
+	target := utils.JoinPath(dstParent.Path, source[0].Name)
+	if exists, err := s.db.PathExists(target); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, fmt.Errorf("%w: %s", ErrPathExists, target)
+	}
+
+	// Build the copies top-down so each derives its path, depth and existence from its copied parent
+	copies := make([]*types.Node, 0, len(source))
+	copied := make(map[string]*types.Node, len(source))
+	for i, src := range source {
+		parent := dstParent
+		if i > 0 {
+			var ok bool
+			if parent, ok = copied[src.ParentID]; !ok {
+				continue // Parent was filtered out
+			}
+		}
+		if opts.OnlyWorld != "" && !src.ExistenceMap[opts.OnlyWorld] {
+			continue
+		}
+
+		node := s.copyNode(src, parent, opts)
+		copied[src.ID] = node
+		copies = append(copies, node)
+	}
+
+	ids := make([]string, len(copies))
+	for i, node := range copies {
+		ids[i] = node.ID
+	}
+
+	for start := 0; start < len(copies); start += copyBatchSize {
+		end := min(start+copyBatchSize, len(copies))
+		if err := s.db.BulkInsertNodes(ctx, copies[start:end]); err != nil {
+			return nil, fmt.Errorf("failed to insert copied nodes: %w", err)
+		}
+	}
+
+	for start := 0; start < len(ids); start += copyBatchSize {
+		end := min(start+copyBatchSize, len(ids))
+		if err := s.db.SetCopyStatus(ids[start:end], types.CopyStatusCompleted); err != nil {
+			return nil, fmt.Errorf("failed to complete copy: %w", err)
+		}
+	}
+
+	root := copies[0]
+	root.CopyStatus = types.CopyStatusCompleted
+	if err := s.journal(nodeChange(types.ChangeCreate, root, len(copies))); err != nil {
+		return nil, err
+	}
+	return &types.CopyResult{
+		Root:        root,
+		NodesCopied: len(copies),
+		CopyStatus:  types.CopyStatusCompleted,
+	}, nil
+}
+
+// copyNode builds the in-progress copy of src under its (already copied) parent
+func (s *SpectraFS) copyNode(src, parent *types.Node, opts types.CopyOptions) *types.Node {
+	node := *src
+	node.ID = uuid.New().String()
+	node.ParentID = parent.ID
+	node.ParentPath = parent.Path
+	node.Path = utils.JoinPath(parent.Path, src.Name)
+	node.DepthLevel = parent.DepthLevel + 1
+	node.TraversalStatus = types.StatusPending
+	node.CopyStatus = types.CopyStatusInProgress
+	node.Metadata = maps.Clone(src.Metadata)
+	if src.Checksum != nil {
+		checksum := *src.Checksum
+		node.Checksum = &checksum
+	}
+	if src.Type == types.NodeTypeFile {
+		node.ContentID = src.ContentID
+		if node.ContentID == "" {
+			node.ContentID = src.ID
+		}
+	}
+
+	// A copy never exists where its parent does not
+	node.ExistenceMap = maps.Clone(src.ExistenceMap)
+	maps.Copy(node.ExistenceMap, opts.WorldOverrides)
+	for world, exists := range node.ExistenceMap {
+		node.ExistenceMap[world] = exists && parent.ExistenceMap[world]
+	}
+	node.ExistenceMap["primary"] = parent.ExistenceMap["primary"]
+	return &node
+}
+
+// validateCopyOptions rejects worlds the instance does not have and primary overrides
+func (s *SpectraFS) validateCopyOptions(opts types.CopyOptions) error {
+	if opts.OnlyWorld != "" {
+		if err := s.checkWorld(opts.OnlyWorld); err != nil {
+			return err
+		}
+	}
+	for world := range opts.WorldOverrides {
+		if world == "primary" {
+			return fmt.Errorf("%w: primary existence cannot be overridden", ErrInvalidCopyOptions)
+		}
+		if err := s.checkWorld(world); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateCopyStatus records a copy engine's progress on a node
+// Accepts any struct that implements NodeIdentifier and StatusRequest; the status must be
+// "pending", "in_progress" or "completed". Returns the updated node.
+func (s *SpectraFS) UpdateCopyStatus(req interface {
+	models.NodeIdentifier
+	models.StatusRequest
+}) (*types.Node, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	node, err := s.resolveCopyStatusTarget(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.UpdateCopyStatus(node.ID, req.GetStatus()); err != nil {
+		return nil, err
+	}
+	node.CopyStatus = req.GetStatus()
+
+	return s.presentRoot(node), nil
+}
+
+// UpdateSubtreeCopyStatus sets the copy status of a node and all of its descendants at once
+// Returns the number of nodes updated
+func (s *SpectraFS) UpdateSubtreeCopyStatus(req interface {
+	models.NodeIdentifier
+	models.StatusRequest
+}) (int, error) {
+	if err := s.checkWritable(); err != nil {
+		return 0, err
+	}
+	node, err := s.resolveCopyStatusTarget(req)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.db.UpdateSubtreeCopyStatus(node.ID, req.GetStatus())
+}
+
+// ListNodesByCopyStatus pages through the nodes of a world with the given copy status, in ID order
+// A simulated copy engine can poll "pending" to pull work. limit 0 returns every match; cursor is
+// the NextCursor of the previous page ("" for the first) and fails with ErrInvalidCursor or
+// ErrCursorExpired like ListChildren cursors
+func (s *SpectraFS) ListNodesByCopyStatus(world, status string, limit int, cursor string) (*types.NodePage, error) {
+	if world == "" {
+		world = "primary"
+	}
+	if err := s.checkWorld(world); err != nil {
+		return nil, err
+	}
+	if !types.IsValidCopyStatus(status) {
+		return nil, fmt.Errorf("%w %q: must be %q, %q or %q", ErrInvalidCopyStatus,
+			status, types.CopyStatusPending, types.CopyStatusInProgress, types.CopyStatusCompleted)
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("%w: limit must be non-negative, got %d", ErrInvalidInput, limit)
+	}
+
+	scope := copyStatusCursorScope + status
+	afterID := ""
+	if cursor != "" {
+		decoded, err := s.decodeCursor(cursor, scope, world)
+		if err != nil {
+			return nil, err
+		}
+		afterID = decoded.ID
+	}
+
+	nodes, more, err := s.db.ListNodesByCopyStatus(world, status, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &types.NodePage{Nodes: nodes}
+	if more && len(nodes) > 0 {
+		page.NextCursor = s.encodeCursor(scope, world, nodes[len(nodes)-1])
+	}
+	for _, node := range nodes {
+		s.presentRoot(node)
+	}
+	return page, nil
+}
+
+// resolveCopyStatusTarget validates a copy status request and resolves its node
+func (s *SpectraFS) resolveCopyStatusTarget(req interface {
+	models.NodeIdentifier
+	models.StatusRequest
+}) (*types.Node, error) {
+	if err := validateRequest(models.ValidateNodeIdentifier(req)); err != nil {
+		return nil, err
+	}
+	if !types.IsValidCopyStatus(req.GetStatus()) {
+		return nil, fmt.Errorf("%w %q: must be %q, %q or %q", ErrInvalidCopyStatus,
+			req.GetStatus(), types.CopyStatusPending, types.CopyStatusInProgress, types.CopyStatusCompleted)
+	}
+
+	node, _, err := s.resolveNodeAndWorld(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve node: %w", err)
+	}
+	return node, nil
+}
@@ -0,0 +1,107 @@
+package spectrafs
+
+import (
+	"math"
+	"sort"
+
+	"github.com/Project-Sylos/Spectra/internal/generator"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// coverageEstimate describes how CoverageReport expectations are derived
+const coverageEstimate = "expected counts assume (min_folders+max_folders)/2 folders per folder (from seed.profile for " +
+	"the folder's depth, long tail included) down to max_depth, scaled per level by each secondary world's probability; " +
+	"they are statistical expectations, not guarantees"
+
+// GetCoverage reports, per world and depth, how much of the expected tree has been materialized
+// A folder is materialized once its children have been generated (or created); frontier folders
+// are stored folders above max depth that have not been expanded yet
+func (s *SpectraFS) GetCoverage() (*types.CoverageReport, error) {
+	counters, err := s.db.GetCoverageCounters()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.CoverageReport{
+		Estimate: coverageEstimate,
+		Worlds:   make([]types.WorldCoverage, 0, len(s.secondaryWorlds())+1),
+	}
+	for _, world := range s.coverageWorlds() {
+		report.Worlds = append(report.Worlds, s.worldCoverage(world, counters))
+	}
+	return report, nil
+}
+
+// coverageWorlds returns primary followed by the configured secondary worlds in name order
+func (s *SpectraFS) coverageWorlds() []string {
+	tables := s.secondaryWorlds()
+	worlds := make([]string, 0, len(tables))
+	for world := range tables {
+		worlds = append(worlds, world)
+	}
+	sort.Strings(worlds)
+	return append([]string{"primary"}, worlds...)
+}
+
+// worldCoverage builds the coverage summary for a single world
+func (s *SpectraFS) worldCoverage(world string, counters *types.CoverageCounters) types.WorldCoverage {
+	maxDepth := s.config().Seed.MaxDepth
+	folders := counters.Folders[world]
+	expanded := counters.Expanded[world]
+
+	// Expected folders per folder in this world: the depth's mean fan-out times the per-level survival probability
+	survival := 1.0
+	if world != "primary" {
+		survival = s.secondaryWorlds()[world]
+	}
+
+	depths := maxDepth + 1
+	if len(folders) > depths {
+		depths = len(folders) // Folders created below max depth
+	}
+
+	coverage := types.WorldCoverage{
+		World:  world,
+		Depths: make([]types.DepthCoverage, 0, depths),
+	}
+	expected := 1.0 // Folders expected at the current depth
+	for depth := 0; depth < depths; depth++ {
+		row := types.DepthCoverage{
+			Depth:   depth,
+			Folders: countAt(folders, depth),
+		}
+		if depth <= maxDepth {
+			row.ExpectedFolders = expected
+			expected *= generator.MeanFolderCount(s.config(), depth) * survival
+		}
+		if depth < maxDepth {
+			row.Materialized = countAt(expanded, depth)
+			row.Frontier = row.Folders - row.Materialized
+			coverage.ExpectedExpandable += row.ExpectedFolders
+		}
+		row.CoveragePercent = percentOf(float64(row.Folders), row.ExpectedFolders)
+
+		coverage.MaterializedFolders += row.Materialized
+		coverage.FrontierFolders += row.Frontier
+		coverage.Depths = append(coverage.Depths, row)
+	}
+	coverage.CoveragePercent = percentOf(float64(coverage.MaterializedFolders), coverage.ExpectedExpandable)
+
+	return coverage
+}
+
+// countAt returns counts[depth], or 0 past the end of the slice
+func countAt(counts []int64, depth int) int64 {
+	if depth < len(counts) {
+		return counts[depth]
+	}
+	return 0
+}
+
+// percentOf returns part/whole as a percentage rounded to two decimals and capped at 100
+func percentOf(part, whole float64) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return math.Round(math.Min(part/whole, 1)*10000) / 100
+}
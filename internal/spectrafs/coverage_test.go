@@ -0,0 +1,102 @@
+package spectrafs
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// newCoverageFS opens an instance that generates exactly two folders per folder, so the expected
+// tree is 1, 2, 4 and 8 folders at depths 0 to 3
+func newCoverageFS(t *testing.T) *SpectraFS {
+	t.Helper()
+	return newTestFS(t, func(cfg *types.Config) {
+		cfg.Seed.MaxFolders = 2
+		cfg.SecondaryTables = map[string]float64{"s1": 0.5}
+	})
+}
+
+// worldCoverageOf returns the coverage of world, failing the test if it is missing
+func worldCoverageOf(t *testing.T, s *SpectraFS, world string) types.WorldCoverage {
+	t.Helper()
+	report, err := s.GetCoverage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, coverage := range report.Worlds {
+		if coverage.World == world {
+			return coverage
+		}
+	}
+	t.Fatalf("no coverage for %s in %+v", world, report.Worlds)
+	return types.WorldCoverage{}
+}
+
+func TestCoverageGrowsWithListing(t *testing.T) {
+	s := newCoverageFS(t)
+
+	primary := worldCoverageOf(t, s, "primary")
+	if primary.MaterializedFolders != 0 || primary.ExpectedExpandable != 7 || len(primary.Depths) != 4 {
+		t.Fatalf("fresh primary coverage = %+v", primary)
+	}
+
+	// Expand the root and the first level: 3 of the 7 folders above max depth
+	rootFolders := list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}).Folders
+	for _, folder := range rootFolders {
+		list(t, s, &models.ListChildrenRequest{ParentID: folder.ID, TableName: "primary"})
+	}
+
+	primary = worldCoverageOf(t, s, "primary")
+	wantDepths := []types.DepthCoverage{
+		{Depth: 0, Folders: 1, Materialized: 1, ExpectedFolders: 1, CoveragePercent: 100},
+		{Depth: 1, Folders: 2, Materialized: 2, ExpectedFolders: 2, CoveragePercent: 100},
+		{Depth: 2, Folders: 4, Frontier: 4, ExpectedFolders: 4, CoveragePercent: 100},
+		{Depth: 3, ExpectedFolders: 8},
+	}
+	if !reflect.DeepEqual(primary.Depths, wantDepths) {
+		t.Errorf("primary depths = %+v, want %+v", primary.Depths, wantDepths)
+	}
+	if primary.MaterializedFolders != 3 || primary.FrontierFolders != 4 || primary.CoveragePercent != 42.86 {
+		t.Errorf("primary totals = %+v, want 3 materialized, 4 frontier, 42.86%%", primary)
+	}
+
+	s1 := worldCoverageOf(t, s, "s1")
+	if s1.ExpectedExpandable != 3 {
+		t.Errorf("s1 expects %v expandable folders, want 1 + 2*0.5 + 4*0.5*0.5 = 3", s1.ExpectedExpandable)
+	}
+}
+
+func TestCoverageCountersMatchRebuild(t *testing.T) {
+	s := newCoverageFS(t)
+	ctx := context.Background()
+
+	rootFolders := list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}).Folders
+	first, second := rootFolders[0].Node, rootFolders[1].Node
+	list(t, s, &models.ListChildrenRequest{ParentID: first.ID, TableName: "primary"})
+	created := mkdir(t, s, second.ID, "created")
+	mkdir(t, s, created.ID, "nested")
+	if _, err := s.SetWorldExistence(first.ID, "s1", false, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DeleteNodes(ctx, []string{created.ID}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	incremental, err := s.db.GetCoverageCounters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.db.RebuildStats(); err != nil {
+		t.Fatal(err)
+	}
+	rebuilt, err := s.db.GetCoverageCounters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(incremental, rebuilt) {
+		t.Errorf("incremental coverage %+v, rebuilt %+v", incremental, rebuilt)
+	}
+}
@@ -0,0 +1,99 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestConcurrentCreateSameName(t *testing.T) {
+	const goroutines = 32
+	s := newTestFS(t)
+
+	for _, kind := range []string{types.NodeTypeFolder, types.NodeTypeFile} {
+		t.Run(kind, func(t *testing.T) {
+			name := "contended-" + kind
+			var (
+				wg      sync.WaitGroup
+				mu      sync.Mutex
+				created []*types.Node
+				others  []error
+			)
+			for range goroutines {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					var node *types.Node
+					var err error
+					if kind == types.NodeTypeFolder {
+						node, err = s.CreateFolder(context.Background(), &models.CreateFolderRequest{ParentID: s.root, Name: name})
+					} else {
+						node, err = s.UploadFile(context.Background(), &models.UploadFileRequest{ParentID: s.root, Name: name, Data: []byte("x")})
+					}
+					mu.Lock()
+					defer mu.Unlock()
+					switch {
+					case err == nil:
+						created = append(created, node)
+					case !errors.Is(err, ErrPathExists):
+						others = append(others, err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if len(others) > 0 {
+				t.Fatalf("creates failed with errors other than ErrPathExists: %v", others)
+			}
+			if len(created) != 1 {
+				t.Fatalf("%d of %d creates succeeded, want exactly 1", len(created), goroutines)
+			}
+			byPath, err := s.GetNode(context.Background(), &models.GetNodeRequest{Path: "/" + name, TableName: "primary"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if byPath.ID != created[0].ID {
+				t.Errorf("path resolves to %s, the created node is %s", byPath.ID, created[0].ID)
+			}
+			children, err := s.db.GetChildrenByParentID(s.root, "primary")
+			if err != nil {
+				t.Fatal(err)
+			}
+			siblings := 0
+			for _, node := range children {
+				if node.Name == name {
+					siblings++
+				}
+			}
+			if siblings != 1 {
+				t.Errorf("%d stored children named %s", siblings, name)
+			}
+		})
+	}
+}
+
+func TestUploadOverwriteReplacesFile(t *testing.T) {
+	s := newTestFS(t)
+	first := upload(t, s, s.root, "x.txt", []byte("x"))
+
+	if _, err := s.UploadFile(context.Background(), &models.UploadFileRequest{ParentID: s.root, Name: "x.txt", Data: []byte("y")}); !errors.Is(err, ErrPathExists) {
+		t.Fatalf("second upload = %v, want ErrPathExists", err)
+	}
+
+	replaced, err := s.UploadFile(context.Background(), &models.UploadFileRequest{ParentID: s.root, Name: "x.txt", Data: []byte("y"), Overwrite: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replaced.ID != first.ID {
+		t.Errorf("overwrite gave the file a new ID: %s, was %s", replaced.ID, first.ID)
+	}
+
+	mkdir(t, s, s.root, "dir")
+	if _, err := s.UploadFile(context.Background(), &models.UploadFileRequest{ParentID: s.root, Name: "dir", Data: []byte("y"), Overwrite: true}); err == nil {
+		t.Error("overwrite replaced a folder with a file")
+	}
+}
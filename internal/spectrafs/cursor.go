@@ -0,0 +1,216 @@
+package spectrafs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// CursorTTL is how long a pagination cursor stays valid after it was issued
+const CursorTTL = 24 * time.Hour
+
+// ErrInvalidCursor is returned when a pagination cursor is malformed, tampered with,
+// or was issued for a different parent/world
+var ErrInvalidCursor = newError(ErrInvalidInput, "invalid pagination cursor")
+
+// ErrCursorExpired is returned when a pagination cursor is older than CursorTTL
+var ErrCursorExpired = newError(ErrInvalidInput, "pagination cursor expired")
+
+// pageCursor is the decoded form of an opaque pagination token
+// It records the sort key (type, name, id; see db.CompareNodeKeys) of the last node on a page so the next
+// page can resume strictly after it, regardless of inserts or deletes in between.
+// A listing sorted by size or mtime also records that value and the sort it was issued under
+type pageCursor struct {
+	ParentID string `json:"p"`
+	World    string `json:"w"`
+	Type     string `json:"t"`
+	Name     string `json:"n"`
+	ID       string `json:"i"`
+	Sort     string `json:"o,omitempty"`
+	Size     int64  `json:"z,omitempty"`
+	MTime    int64  `json:"m,omitempty"`
+	IssuedAt int64  `json:"ts"`
+}
+
+// encodeCursor builds an opaque, signed token pointing at the given node
+func (s *SpectraFS) encodeCursor(parentID, world string, node *types.Node) string {
+	return s.signCursor(pageCursor{
+		ParentID: parentID,
+		World:    world,
+		Type:     node.Type,
+		Name:     node.Name,
+		ID:       node.ID,
+		IssuedAt: time.Now().Unix(),
+	})
+}
+
+// encodeListCursor builds a token pointing at the given node in a listing sorted by opts
+func (s *SpectraFS) encodeListCursor(parentID, world string, node *types.Node, opts *listOptions) string {
+	cursor := pageCursor{
+		ParentID: parentID,
+		World:    world,
+		Type:     node.Type,
+		Name:     node.Name,
+		ID:       node.ID,
+		Sort:     opts.sortKey(),
+		IssuedAt: time.Now().Unix(),
+	}
+	switch {
+	case !opts.sorted():
+	case opts.sortBy == models.SortBySize:
+		cursor.Size = node.Size
+	case opts.sortBy == models.SortByMTime:
+		cursor.MTime = node.LastUpdated.UnixNano()
+	}
+	return s.signCursor(cursor)
+}
+
+// signCursor encodes and signs a cursor
+func (s *SpectraFS) signCursor(cursor pageCursor) string {
+	payload, _ := json.Marshal(cursor)
+
+	mac := hmac.New(sha256.New, s.cursorKey)
+	mac.Write(payload)
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// decodeCursor verifies and decodes a token produced by encodeCursor
+func (s *SpectraFS) decodeCursor(token, parentID, world string) (*pageCursor, error) {
+	encodedPayload, encodedMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidCursor)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidCursor)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidCursor)
+	}
+
+	mac := hmac.New(sha256.New, s.cursorKey)
+	mac.Write(payload)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidCursor)
+	}
+
+	var cursor pageCursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidCursor)
+	}
+
+	if cursor.ParentID != parentID || cursor.World != world {
+		return nil, fmt.Errorf("%w: cursor was issued for a different parent or world", ErrInvalidCursor)
+	}
+
+	if time.Since(time.Unix(cursor.IssuedAt, 0)) > CursorTTL {
+		return nil, ErrCursorExpired
+	}
+
+	return &cursor, nil
+}
+
+// cursorNode returns the sort key a cursor records as a node, for comparing against children
+func (c *pageCursor) cursorNode() *types.Node {
+	return &types.Node{
+		Type:        c.Type,
+		Name:        c.Name,
+		ID:          c.ID,
+		Size:        c.Size,
+		LastUpdated: time.Unix(0, c.MTime),
+	}
+}
+
+// decodeListCursor decodes a listing cursor, checking it was issued under the sort of opts
+func (s *SpectraFS) decodeListCursor(token, parentID, world string, opts *listOptions) (*pageCursor, error) {
+	cursor, err := s.decodeCursor(token, parentID, world)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.Sort != opts.sortKey() {
+		return nil, fmt.Errorf("%w: cursor was issued for a different sort order", ErrInvalidCursor)
+	}
+	return cursor, nil
+}
+
+// paginateChildren applies keyset pagination to children that are already sorted with opts.compare
+// (db.CompareNodes when opts is nil). A cursor issued under a different sort is rejected
+// Returns the page plus the cursors for the next and previous pages (empty when there are none)
+func (s *SpectraFS) paginateChildren(children []*types.Node, parentID, world string, page pageRequest, opts *listOptions) ([]*types.Node, string, string, error) {
+	if page.Limit < 0 {
+		return nil, "", "", fmt.Errorf("%w: limit must be non-negative, got %d", ErrInvalidInput, page.Limit)
+	}
+	if page.StartingAfter != "" && page.EndingBefore != "" {
+		return nil, "", "", fmt.Errorf("%w: starting_after and ending_before are mutually exclusive", ErrInvalidCursor)
+	}
+
+	// Window boundaries into the sorted children slice: [start, end)
+	start, end := 0, len(children)
+
+	if page.StartingAfter != "" {
+		cursor, err := s.decodeListCursor(page.StartingAfter, parentID, world, opts)
+		if err != nil {
+			return nil, "", "", err
+		}
+		// First child strictly after the cursor key
+		start = len(children)
+		key := cursor.cursorNode()
+		for i, child := range children {
+			if opts.compare(child, key) > 0 {
+				start = i
+				break
+			}
+		}
+		if page.Limit > 0 && start+page.Limit < end {
+			end = start + page.Limit
+		}
+	} else if page.EndingBefore != "" {
+		cursor, err := s.decodeListCursor(page.EndingBefore, parentID, world, opts)
+		if err != nil {
+			return nil, "", "", err
+		}
+		// First child at or after the cursor key bounds the window
+		end = len(children)
+		key := cursor.cursorNode()
+		for i, child := range children {
+			if opts.compare(child, key) >= 0 {
+				end = i
+				break
+			}
+		}
+		if page.Limit > 0 && end-page.Limit > start {
+			start = end - page.Limit
+		}
+	} else if page.Limit > 0 && page.Limit < end {
+		end = page.Limit
+	}
+
+	pageNodes := children[start:end]
+
+	var nextCursor, prevCursor string
+	if end < len(children) && len(pageNodes) > 0 {
+		nextCursor = s.encodeListCursor(parentID, world, pageNodes[len(pageNodes)-1], opts)
+	}
+	if start > 0 && len(pageNodes) > 0 {
+		prevCursor = s.encodeListCursor(parentID, world, pageNodes[0], opts)
+	}
+
+	return pageNodes, nextCursor, prevCursor, nil
+}
+
+// pageRequest holds the pagination parameters extracted from a request
+type pageRequest struct {
+	Limit         int
+	StartingAfter string
+	EndingBefore  string
+}
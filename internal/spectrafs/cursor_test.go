@@ -0,0 +1,195 @@
+package spectrafs
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// pagedFolder creates a folder holding n files named f00, f01, ... and returns its ID
+func pagedFolder(t *testing.T, s *SpectraFS, n int) string {
+	t.Helper()
+	folder := mkdir(t, s, "root", "paged")
+	for i := 0; i < n; i++ {
+		upload(t, s, folder.ID, fmt.Sprintf("f%02d", i), []byte("x"))
+	}
+	return folder.ID
+}
+
+func TestPaginationNoSkipNoDuplicate(t *testing.T) {
+	s := newTestFS(t)
+	ctx := context.Background()
+	parentID := pagedFolder(t, s, 20)
+
+	// Children present for the whole walk must be seen exactly once, in order, whatever changes
+	// between pages; deleting f15 before it is reached must not skip its neighbours
+	seen := make(map[string]int)
+	var order []string
+	cursor := ""
+	for page := 0; ; page++ {
+		result := list(t, s, &models.ListChildrenRequest{ParentID: parentID, Limit: 3, StartingAfter: cursor})
+		for _, node := range childNodes(result) {
+			seen[node.Name]++
+			order = append(order, node.Name)
+		}
+
+		switch page {
+		case 1:
+			upload(t, s, parentID, "f00a", []byte("x")) // Before the cursor: not seen
+			upload(t, s, parentID, "f19a", []byte("x")) // After the cursor: seen once
+		case 2:
+			node, err := s.GetNode(ctx, &models.GetNodeRequest{Path: "/paged/f15", TableName: "primary"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.DeleteNode(ctx, &models.DeleteNodeRequest{ID: node.ID}); err != nil {
+				t.Fatal(err)
+			}
+		case 3:
+			// Delete the boundary node itself: the cursor still resumes after its key
+			last := order[len(order)-1]
+			node, err := s.GetNode(ctx, &models.GetNodeRequest{Path: "/paged/" + last, TableName: "primary"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.DeleteNode(ctx, &models.DeleteNodeRequest{ID: node.ID}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+
+	for name, count := range seen {
+		if count != 1 {
+			t.Errorf("%s seen %d times", name, count)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("f%02d", i)
+		if name == "f15" {
+			if seen[name] != 0 {
+				t.Errorf("deleted %s was listed", name)
+			}
+			continue
+		}
+		if seen[name] != 1 {
+			t.Errorf("%s seen %d times, want 1", name, seen[name])
+		}
+	}
+	if seen["f00a"] != 0 || seen["f19a"] != 1 {
+		t.Errorf("inserted siblings seen %d (before cursor) and %d (after), want 0 and 1", seen["f00a"], seen["f19a"])
+	}
+	for i := 1; i < len(order); i++ {
+		if order[i-1] >= order[i] {
+			t.Fatalf("pages out of order: %v", order)
+		}
+	}
+}
+
+func TestPaginationBackwards(t *testing.T) {
+	s := newTestFS(t)
+	parentID := pagedFolder(t, s, 10)
+
+	first := list(t, s, &models.ListChildrenRequest{ParentID: parentID, Limit: 4})
+	second := list(t, s, &models.ListChildrenRequest{ParentID: parentID, Limit: 4, StartingAfter: first.NextCursor})
+	back := list(t, s, &models.ListChildrenRequest{ParentID: parentID, Limit: 4, EndingBefore: second.PrevCursor})
+
+	if got, want := names(childNodes(back)), names(childNodes(first)); got != want {
+		t.Fatalf("previous page = %s, want %s", got, want)
+	}
+	if back.PrevCursor != "" {
+		t.Fatalf("first page has a previous cursor")
+	}
+}
+
+func TestCursorRejectsTampering(t *testing.T) {
+	s := newTestFS(t)
+	ctx := context.Background()
+	parentID := pagedFolder(t, s, 5)
+	result := list(t, s, &models.ListChildrenRequest{ParentID: parentID, Limit: 2})
+
+	payload, signature, _ := strings.Cut(result.NextCursor, ".")
+	raw, _ := base64.RawURLEncoding.DecodeString(payload)
+	tampered := base64.RawURLEncoding.EncodeToString([]byte(strings.Replace(string(raw), "f01", "f03", 1))) + "." + signature
+
+	for name, token := range map[string]string{
+		"tampered":  tampered,
+		"malformed": "not-a-cursor",
+		"forged":    forgeCursor(t, raw, fmt.Sprintf("spectra-cursor-%d", s.config().Seed.Seed)),
+	} {
+		_, err := s.ListChildren(ctx, &models.ListChildrenRequest{ParentID: parentID, Limit: 2, StartingAfter: token})
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s cursor: got %v, want ErrInvalidCursor", name, err)
+		}
+	}
+
+	// A cursor for another parent is refused too
+	other := mkdir(t, s, "root", "other")
+	upload(t, s, other.ID, "a", []byte("x"))
+	if _, err := s.ListChildren(ctx, &models.ListChildrenRequest{ParentID: other.ID, StartingAfter: result.NextCursor}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("cursor of another parent: got %v, want ErrInvalidCursor", err)
+	}
+}
+
+// forgeCursor signs payload with key the way signCursor does
+func forgeCursor(t *testing.T, payload []byte, key string) string {
+	t.Helper()
+	var cursor pageCursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		t.Fatal(err)
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestCursorSecret(t *testing.T) {
+	dir := onDisk(t)
+	s := newTestFS(t, dir)
+	parentID := pagedFolder(t, s, 5)
+	cursor := list(t, s, &models.ListChildrenRequest{ParentID: parentID, Limit: 2}).NextCursor
+	key := s.cursorKey
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The secret is stored, so cursors stay valid across a reopen
+	reopened := newTestFS(t, dir)
+	if string(reopened.cursorKey) != string(key) {
+		t.Fatalf("cursor secret changed across a reopen")
+	}
+	if got := list(t, reopened, &models.ListChildrenRequest{ParentID: parentID, Limit: 2, StartingAfter: cursor}); len(childNodes(got)) != 2 {
+		t.Fatalf("cursor from before the reopen returned %d children", len(childNodes(got)))
+	}
+
+	// Instances with the same seed do not share it
+	other := newTestFS(t)
+	if string(other.cursorKey) == string(key) {
+		t.Fatalf("two instances drew the same cursor secret")
+	}
+	if len(key) != 32 || strings.Contains(string(key), "spectra-cursor") {
+		t.Fatalf("cursor secret %q is not a random key", key)
+	}
+}
+
+// names joins node names for comparisons in failure messages
+func names(nodes []*types.Node) string {
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		parts[i] = node.Name
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,29 @@
+package spectrafs
+
+import (
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// ErrDebugDisabled is returned by the raw bucket accessors unless debug.expose_buckets is set
+var ErrDebugDisabled = newError(ErrNotFound, "debug bucket access is disabled")
+
+// ErrUnknownBucket is returned when a raw bucket scan names a bucket that does not exist
+var ErrUnknownBucket = db.ErrUnknownBucket
+
+// DebugBuckets lists the raw BoltDB buckets and their key counts (requires debug.expose_buckets)
+func (s *SpectraFS) DebugBuckets() ([]types.BucketInfo, error) {
+	if !s.config().Debug.ExposeBuckets {
+		return nil, ErrDebugDisabled
+	}
+	return s.db.ListBuckets()
+}
+
+// DebugScanBucket returns raw key/value pairs from a bucket, filtered by key prefix and paged
+// with after (the previous page's NextAfter) and limit (requires debug.expose_buckets)
+func (s *SpectraFS) DebugScanBucket(name, prefix, after string, limit int) (*types.BucketPage, error) {
+	if !s.config().Debug.ExposeBuckets {
+		return nil, ErrDebugDisabled
+	}
+	return s.db.ScanBucket(name, prefix, after, limit)
+}
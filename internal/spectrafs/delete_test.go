@@ -0,0 +1,176 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// statuses maps each outcome's ID to its status
+func statuses(result *types.BatchDeleteResult) map[string]string {
+	got := make(map[string]string, len(result.Results))
+	for _, outcome := range result.Results {
+		got[outcome.ID] = outcome.Status
+	}
+	return got
+}
+
+func TestDeleteNodesMixedOutcomes(t *testing.T) {
+	s := newTestFS(t)
+	ctx := context.Background()
+
+	full := mkdir(t, s, "root", "full")
+	child := upload(t, s, full.ID, "child", []byte("x"))
+	empty := mkdir(t, s, "root", "empty")
+	file := upload(t, s, "root", "file", []byte("x"))
+
+	ids := []string{file.ID, full.ID, "missing", "root", empty.ID, file.ID}
+	result, err := s.DeleteNodes(ctx, ids, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		file.ID:   types.DeleteStatusDeleted,
+		full.ID:   types.DeleteStatusSkippedNotEmpty,
+		"missing": types.DeleteStatusNotFound,
+		"root":    types.DeleteStatusSkippedRoot,
+		empty.ID:  types.DeleteStatusDeleted,
+	}
+	got := statuses(result)
+	for id, status := range want {
+		if got[id] != status {
+			t.Errorf("%s: status %q, want %q", id, got[id], status)
+		}
+	}
+	if len(result.Results) != len(want) {
+		t.Errorf("%d outcomes for %d distinct IDs", len(result.Results), len(want))
+	}
+	if result.Results[0].ID != file.ID || result.Results[1].ID != full.ID {
+		t.Errorf("outcomes not in request order: %+v", result.Results)
+	}
+	if result.Deleted != 2 {
+		t.Errorf("deleted %d nodes, want 2", result.Deleted)
+	}
+	if _, err := s.GetNode(ctx, &models.GetNodeRequest{ID: child.ID}); err != nil {
+		t.Errorf("child of skipped folder: %v", err)
+	}
+}
+
+func TestDeleteNodesRecursive(t *testing.T) {
+	s := newTestFS(t)
+	ctx := context.Background()
+
+	full := mkdir(t, s, "root", "full")
+	sub := mkdir(t, s, full.ID, "sub")
+	leaf := upload(t, s, sub.ID, "leaf", []byte("x"))
+
+	// A listed descendant of a listed folder is swept up with it and reported deleted
+	result, err := s.DeleteNodes(ctx, []string{leaf.ID, full.ID}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := statuses(result)
+	if got[leaf.ID] != types.DeleteStatusDeleted || got[full.ID] != types.DeleteStatusDeleted {
+		t.Fatalf("statuses %v, want both deleted", got)
+	}
+	if result.Deleted != 3 {
+		t.Fatalf("deleted %d nodes, want 3", result.Deleted)
+	}
+	for _, id := range []string{full.ID, sub.ID, leaf.ID} {
+		if _, err := s.GetNode(ctx, &models.GetNodeRequest{ID: id}); !errors.Is(err, ErrNodeNotFound) {
+			t.Errorf("%s still stored: %v", id, err)
+		}
+	}
+}
+
+// cancelAfter is a context whose Err starts reporting cancellation after n calls
+type cancelAfter struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfter) Err() error {
+	if c.n <= 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+}
+
+func TestDeleteNodesPartialFailure(t *testing.T) {
+	s := newTestFS(t)
+	a := upload(t, s, "root", "a", []byte("x"))
+	b := upload(t, s, "root", "b", []byte("x"))
+	c := upload(t, s, "root", "c", []byte("x"))
+
+	// The run is cancelled after the first delete: each ID reports what happened to it
+	result, err := s.DeleteNodes(&cancelAfter{Context: context.Background(), n: 1}, []string{a.ID, b.ID, c.ID}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := statuses(result)
+	if got[a.ID] != types.DeleteStatusDeleted {
+		t.Errorf("first ID: status %q, want deleted", got[a.ID])
+	}
+	for _, id := range []string{b.ID, c.ID} {
+		if got[id] != types.DeleteStatusFailed {
+			t.Errorf("%s: status %q, want failed", id, got[id])
+		}
+	}
+	for _, outcome := range result.Results[1:] {
+		if outcome.Message == "" {
+			t.Errorf("failed outcome %s has no message", outcome.ID)
+		}
+	}
+	if result.Deleted != 1 {
+		t.Errorf("deleted %d nodes, want 1", result.Deleted)
+	}
+	if _, err := s.GetNode(context.Background(), &models.GetNodeRequest{ID: b.ID}); err != nil {
+		t.Errorf("node after the failure was deleted: %v", err)
+	}
+}
+
+func TestDeleteNodesLegacyIDs(t *testing.T) {
+	s := newTestFS(t)
+	a := upload(t, s, "root", "a", []byte("x"))
+	b := upload(t, s, "root", "b", []byte("x"))
+
+	// Legacy forms resolve as they do elsewhere and outcomes keep the IDs as given; a second form
+	// of an ID already listed is a duplicate
+	ids := []string{"p-root", "p-" + a.ID, "s1-" + b.ID, a.ID}
+	result, err := s.DeleteNodes(context.Background(), ids, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []types.DeleteOutcome{
+		{ID: "p-root", Status: types.DeleteStatusSkippedRoot},
+		{ID: "p-" + a.ID, Status: types.DeleteStatusDeleted},
+		{ID: "s1-" + b.ID, Status: types.DeleteStatusDeleted},
+	}
+	if len(result.Results) != len(want) {
+		t.Fatalf("outcomes %+v, want %+v", result.Results, want)
+	}
+	for i, outcome := range result.Results {
+		if outcome.ID != want[i].ID || outcome.Status != want[i].Status {
+			t.Errorf("outcome %d = %s %s, want %s %s", i, outcome.ID, outcome.Status, want[i].ID, want[i].Status)
+		}
+	}
+	if result.Deleted != 2 {
+		t.Errorf("deleted %d nodes, want 2", result.Deleted)
+	}
+}
+
+func TestDeleteNodesValidation(t *testing.T) {
+	s := newTestFS(t)
+	if _, err := s.DeleteNodes(context.Background(), nil, false); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("empty ids: got %v, want ErrInvalidInput", err)
+	}
+	ids := make([]string, MaxBatchDeleteSize+1)
+	if _, err := s.DeleteNodes(context.Background(), ids, false); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("oversized batch: got %v, want ErrInvalidInput", err)
+	}
+}
@@ -0,0 +1,277 @@
+package spectrafs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/generator"
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// Bounds for the tree materialized by DeterminismCheck in each throwaway instance
+const (
+	determinismCheckMaxDepth = 3
+	determinismCheckMaxNodes = 2000
+	determinismCheckWorkers  = 4 // Goroutines scrambling the generation order in later iterations
+)
+
+// nodeFingerprint describes a generated node for comparison across instances
+// IDs are included only when they are derived from the seed; with seed.random_ids they legitimately differ
+type nodeFingerprint struct {
+	Path   string
+	Fields [][2]string // Ordered (field, value) pairs
+}
+
+// DeterminismCheck builds iterations throwaway instances from the current config, materializes
+// a small bounded tree in each, and compares structure, existence, and content fingerprints
+// Iteration 0 generates breadth-first; later ones first generate concurrently in scrambled orders,
+// so the check also proves the tree does not depend on the order folders are listed in.
+// The first divergence from iteration 0 is reported with its path, field, and both values
+func (s *SpectraFS) DeterminismCheck(iterations int) (*types.DeterminismReport, error) {
+	if iterations < 2 {
+		return nil, fmt.Errorf("%w: determinism check needs at least 2 iterations, got %d", ErrInvalidInput, iterations)
+	}
+
+	report := &types.DeterminismReport{
+		Deterministic: true,
+		Iterations:    iterations,
+	}
+
+	var baseline []nodeFingerprint
+	for i := 0; i < iterations; i++ {
+		prints, err := s.fingerprintThrowawayInstance(i)
+		if err != nil {
+			return nil, fmt.Errorf("determinism check iteration %d: %w", i, err)
+		}
+
+		if i == 0 {
+			baseline = prints
+			report.NodesCompared = len(prints)
+			report.Fingerprint = digestFingerprints(prints)
+			continue
+		}
+
+		if divergence := compareFingerprints(baseline, prints); divergence != nil {
+			divergence.Iteration = i
+			report.Deterministic = false
+			report.Divergence = divergence
+			break
+		}
+	}
+
+	return report, nil
+}
+
+// fingerprintThrowawayInstance generates a bounded tree in an in-memory database and fingerprints it
+// Every iteration after the first scrambles the generation order before fingerprinting
+func (s *SpectraFS) fingerprintThrowawayInstance(iteration int) ([]nodeFingerprint, error) {
+	cfg := *s.config()
+	cfg.Seed.DBPath = db.MemoryPath
+	cfg.DB = types.DBConfig{Preload: types.PreloadNone}
+
+	instance, err := NewSpectraFSFromConfig(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer instance.Close()
+
+	root, err := instance.GetNode(context.Background(), &models.GetNodeRequest{ID: instance.root})
+	if err != nil {
+		return nil, err
+	}
+
+	if iteration > 0 {
+		if err := instance.scrambledMaterialize(root, iteration); err != nil {
+			return nil, err
+		}
+	}
+
+	// Breadth-first in listing order, so fingerprints line up across instances
+	prints := []nodeFingerprint{instance.fingerprintNode(root)}
+	queue := []*types.Node{root}
+	for len(queue) > 0 && len(prints) < determinismCheckMaxNodes {
+		parent := queue[0]
+		queue = queue[1:]
+		if parent.DepthLevel >= determinismCheckMaxDepth {
+			continue
+		}
+
+		result, err := instance.ListChildren(context.Background(), &models.ListChildrenRequest{ParentID: parent.ID, TableName: "primary"})
+		if err != nil {
+			return nil, err
+		}
+		if !result.Success {
+			return nil, fmt.Errorf("failed to list %s: %s", parent.Path, result.Message)
+		}
+
+		for i := range result.Folders {
+			folder := &result.Folders[i].Node
+			prints = append(prints, instance.fingerprintNode(folder))
+			queue = append(queue, folder)
+		}
+		for i := range result.Files {
+			prints = append(prints, instance.fingerprintNode(&result.Files[i].Node))
+		}
+		for i := range result.Symlinks {
+			prints = append(prints, instance.fingerprintNode(&result.Symlinks[i].Node))
+		}
+	}
+
+	if len(prints) > determinismCheckMaxNodes {
+		prints = prints[:determinismCheckMaxNodes]
+	}
+	return prints, nil
+}
+
+// scrambledMaterialize generates the bounded tree from several goroutines at once, each crawling
+// depth-first with its children visited in a different rotation, stopping after determinismCheckMaxNodes listings
+func (s *SpectraFS) scrambledMaterialize(root *types.Node, iteration int) error {
+	var listed atomic.Int64
+	errs := make(chan error, determinismCheckWorkers)
+	var wg sync.WaitGroup
+	for w := 0; w < determinismCheckWorkers; w++ {
+		wg.Add(1)
+		go func(rotation int) {
+			defer wg.Done()
+			stack := []*types.Node{root}
+			for len(stack) > 0 && listed.Add(1) <= determinismCheckMaxNodes {
+				parent := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if parent.DepthLevel >= determinismCheckMaxDepth {
+					continue
+				}
+
+				result, err := s.ListChildren(context.Background(), &models.ListChildrenRequest{ParentID: parent.ID, TableName: "primary"})
+				if err != nil {
+					errs <- err
+					return
+				}
+				if !result.Success {
+					errs <- fmt.Errorf("failed to list %s: %s", parent.Path, result.Message)
+					return
+				}
+
+				for i := range result.Folders {
+					stack = append(stack, &result.Folders[(i+rotation)%len(result.Folders)].Node)
+				}
+			}
+		}(iteration + w)
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// fingerprintNode captures every generated property of a node, its ID included. With seed.random_ids
+// the ID and, unless seed.identical_file_content is set, the ID-derived checksum and content are left out
+func (s *SpectraFS) fingerprintNode(node *types.Node) nodeFingerprint {
+	seed := s.config().Seed
+	checksum := ""
+	if node.Checksum != nil {
+		checksum = *node.Checksum
+	}
+
+	// Per-node content is derived from the ID, so with random IDs only its agreement with the checksum is comparable
+	content := [2]string{}
+	if node.Type == types.NodeTypeFile {
+		contentChecksum, err := generator.DeterministicChecksum(s.contentSeed(node), node.Size)
+		if err != nil {
+			contentChecksum = ""
+		}
+		if seed.IdenticalContent || !seed.RandomIDs {
+			content = [2]string{"content", contentChecksum}
+		} else {
+			content = [2]string{"content_matches_checksum", strconv.FormatBool(contentChecksum == checksum)}
+			checksum = ""
+		}
+	}
+
+	worlds := make([]string, 0, len(node.ExistenceMap))
+	for world, exists := range node.ExistenceMap {
+		worlds = append(worlds, world+"="+strconv.FormatBool(exists))
+	}
+	sort.Strings(worlds)
+
+	metadata := make([]string, 0, len(node.Metadata))
+	for key, value := range node.Metadata {
+		metadata = append(metadata, key+"="+value)
+	}
+	sort.Strings(metadata)
+
+	fields := [][2]string{
+		{"name", node.Name},
+		{"type", node.Type},
+		{"parent_path", node.ParentPath},
+		{"depth_level", strconv.Itoa(node.DepthLevel)},
+		{"size", strconv.FormatInt(node.Size, 10)},
+		{"checksum", checksum},
+		{"target", node.Target},
+		{"existence_map", strings.Join(worlds, ",")},
+		{"metadata", strings.Join(metadata, ",")},
+		{"last_updated", node.LastUpdated.UTC().Format(time.RFC3339Nano)},
+	}
+
+	if content[0] != "" {
+		fields = append(fields, content)
+	}
+	if !seed.RandomIDs {
+		fields = append(fields, [2]string{"id", node.ID}, [2]string{"parent_id", node.ParentID})
+	}
+
+	return nodeFingerprint{Path: node.Path, Fields: fields}
+}
+
+// compareFingerprints returns the first difference between two fingerprint lists, or nil
+func compareFingerprints(expected, actual []nodeFingerprint) *types.DeterminismDivergence {
+	for i, want := range expected {
+		if i >= len(actual) {
+			return &types.DeterminismDivergence{Path: want.Path, Field: "missing", Expected: "present", Actual: "absent"}
+		}
+		got := actual[i]
+		if got.Path != want.Path {
+			return &types.DeterminismDivergence{Path: want.Path, Field: "path", Expected: want.Path, Actual: got.Path}
+		}
+		for j, field := range want.Fields {
+			if j >= len(got.Fields) || got.Fields[j] != field {
+				actualValue := ""
+				if j < len(got.Fields) {
+					actualValue = got.Fields[j][1]
+				}
+				return &types.DeterminismDivergence{Path: want.Path, Field: field[0], Expected: field[1], Actual: actualValue}
+			}
+		}
+	}
+
+	if len(actual) > len(expected) {
+		extra := actual[len(expected)]
+		return &types.DeterminismDivergence{Path: extra.Path, Field: "unexpected", Expected: "absent", Actual: "present"}
+	}
+
+	return nil
+}
+
+// digestFingerprints hashes a fingerprint list into a single comparable value
+func digestFingerprints(prints []nodeFingerprint) string {
+	hash := sha256.New()
+	for _, fp := range prints {
+		hash.Write([]byte(fp.Path))
+		for _, field := range fp.Fields {
+			hash.Write([]byte{0})
+			hash.Write([]byte(field[0]))
+			hash.Write([]byte{'='})
+			hash.Write([]byte(field[1]))
+		}
+		hash.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
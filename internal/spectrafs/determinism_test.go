@@ -0,0 +1,187 @@
+package spectrafs
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/google/uuid"
+)
+
+// goldenFingerprint is DeterminismCheck's digest of the tree generated from goldenSeed with the test
+// configuration. A change to it means generation changed: if that was intended (a new generated
+// property, a deliberate change to the RNG streams), update the constant in the same change
+const (
+	goldenSeed        = 42
+	goldenFingerprint = "777de32e4073d56dfeb5e4ab0eb2417fc2dff3a31bf0942a6dcd42c12c4772ce"
+)
+
+func TestDeterminismFingerprint(t *testing.T) {
+	s := newTestFS(t, func(cfg *types.Config) {
+		cfg.Seed.Seed = goldenSeed
+	})
+
+	report, err := s.DeterminismCheck(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Deterministic {
+		d := report.Divergence
+		t.Fatalf("iteration %d diverged at %s, field %s: expected %q, got %q", d.Iteration, d.Path, d.Field, d.Expected, d.Actual)
+	}
+	if report.NodesCompared < 10 {
+		t.Fatalf("only %d nodes compared", report.NodesCompared)
+	}
+	if report.Fingerprint != goldenFingerprint {
+		t.Errorf("fingerprint of seed %d = %s, want %s (generation changed; see goldenFingerprint)",
+			goldenSeed, report.Fingerprint, goldenFingerprint)
+	}
+}
+
+func TestDeterminismCheckValidation(t *testing.T) {
+	s := newTestFS(t)
+	if _, err := s.DeterminismCheck(1); err == nil {
+		t.Error("DeterminismCheck(1) succeeded")
+	}
+}
+
+// generatedProps walks the primary tree, generating what is left, and returns each node's
+// generated properties by path
+func generatedProps(t *testing.T, s *SpectraFS) map[string]string {
+	t.Helper()
+	props := make(map[string]string)
+	err := s.WalkFunc(context.Background(), &models.WalkRequest{ParentID: s.root, TableName: "primary"}, func(node *types.Node) error {
+		checksum := ""
+		if node.Checksum != nil {
+			checksum = *node.Checksum
+		}
+		props[node.Path] = fmt.Sprintf("%s %s %d %s %v", node.Name, node.Type, node.Size, checksum, node.ExistenceMap)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return props
+}
+
+func TestDeterminismIndependentOfListingOrder(t *testing.T) {
+	configure := func(cfg *types.Config) { cfg.Seed.Seed = goldenSeed }
+	inOrder := newTestFS(t, configure)
+	want := generatedProps(t, inOrder)
+
+	// The second instance expands each level from concurrent goroutines, last folder first
+	concurrent := newTestFS(t, configure)
+	frontier := []string{concurrent.root}
+	for len(frontier) > 0 {
+		var (
+			mu   sync.Mutex
+			wg   sync.WaitGroup
+			next []string
+		)
+		for _, parentID := range slices.Backward(frontier) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := concurrent.ListChildren(context.Background(), &models.ListChildrenRequest{ParentID: parentID, TableName: "primary"})
+				if err != nil || !result.Success {
+					t.Errorf("ListChildren(%s): %v %+v", parentID, err, result)
+					return
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				for _, folder := range result.Folders {
+					next = append(next, folder.ID)
+				}
+			}()
+		}
+		wg.Wait()
+		frontier = next
+	}
+
+	got := generatedProps(t, concurrent)
+	if len(got) != len(want) {
+		t.Fatalf("concurrent listing generated %d nodes, in-order %d", len(got), len(want))
+	}
+	for path, props := range want {
+		if got[path] != props {
+			t.Errorf("%s: in order %q, concurrently %q", path, props, got[path])
+		}
+	}
+}
+
+// nodeIDs walks the primary tree, generating what is left, and returns each node's ID and parent
+// ID by path
+func nodeIDs(t *testing.T, s *SpectraFS) map[string]string {
+	t.Helper()
+	ids := make(map[string]string)
+	err := s.WalkFunc(context.Background(), &models.WalkRequest{ParentID: s.root, TableName: "primary"}, func(node *types.Node) error {
+		ids[node.Path] = node.ID + " " + node.ParentID
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+func TestDeterminismIDsMatchAcrossInstances(t *testing.T) {
+	want := nodeIDs(t, newTestFS(t))
+	other := newTestFS(t)
+	if got := nodeIDs(t, other); !maps.Equal(got, want) {
+		t.Errorf("a second instance of the same config generated %d IDs differing from the first", diffCount(got, want))
+	}
+
+	// A reset regenerates the same IDs
+	if err := other.Reset(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := nodeIDs(t, other); !maps.Equal(got, want) {
+		t.Errorf("regenerating after a reset gave %d different IDs", diffCount(got, want))
+	}
+
+	// Another seed, or seed.random_ids, gives other IDs
+	for name, configure := range map[string]func(*types.Config){
+		"seed":       func(cfg *types.Config) { cfg.Seed.Seed++ },
+		"random_ids": func(cfg *types.Config) { cfg.Seed.RandomIDs = true },
+	} {
+		got := nodeIDs(t, newTestFS(t, configure))
+		for path, ids := range got {
+			if path != "/" && want[path] == ids {
+				t.Errorf("%s: %s has the same ID as with the default config", name, path)
+			}
+		}
+	}
+}
+
+func TestClientCreatedNodesGetRandomIDs(t *testing.T) {
+	a, b := newTestFS(t), newTestFS(t)
+	for _, create := range []func(s *SpectraFS) *types.Node{
+		func(s *SpectraFS) *types.Node { return mkdir(t, s, s.root, "made") },
+		func(s *SpectraFS) *types.Node { return upload(t, s, s.root, "made.txt", []byte("data")) },
+	} {
+		first, second := create(a), create(b)
+		id, err := uuid.Parse(first.ID)
+		if err != nil || id.Version() != 4 {
+			t.Errorf("%s has ID %s, want a random (version 4) UUID", first.Path, first.ID)
+		}
+		if first.ID == second.ID {
+			t.Errorf("%s got the same ID %s in two instances", first.Path, first.ID)
+		}
+	}
+}
+
+// diffCount returns how many paths of want map to something else in got
+func diffCount(got, want map[string]string) int {
+	n := 0
+	for path, value := range want {
+		if got[path] != value {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,123 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// diffCursorScope prefixes the cursor scope of world diff pages, keeping them apart from listing cursors
+const diffCursorScope = "diff:"
+
+// errDiffPageFull stops the node scan once a diff page is full
+var errDiffPageFull = errors.New("diff page full")
+
+// DiffWorlds compares two worlds in one scan of the nodes bucket and returns a page of their differences
+// Nodes are compared as listings see them, so retention-expired nodes count as absent. opts.Root limits
+// the diff to a subtree by path; opts.Limit caps the differences per page (0 = all) and opts.Cursor
+// resumes after a previous page, failing with ErrInvalidCursor or ErrCursorExpired like ListChildren cursors
+func (s *SpectraFS) DiffWorlds(ctx context.Context, worldA, worldB string, opts types.DiffOptions) (*types.WorldDiff, error) {
+	if opts.Limit < 0 {
+		return nil, fmt.Errorf("%w: limit must be non-negative, got %d", ErrInvalidInput, opts.Limit)
+	}
+	root, err := s.validateDiff(worldA, worldB, opts.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, worlds := diffCursorScope+root, worldA+"|"+worldB
+	afterID := ""
+	if opts.Cursor != "" {
+		decoded, err := s.decodeCursor(opts.Cursor, scope, worlds)
+		if err != nil {
+			return nil, err
+		}
+		afterID = decoded.ID
+	}
+
+	diff := &types.WorldDiff{
+		WorldA:  worldA,
+		WorldB:  worldB,
+		Root:    root,
+		OnlyInA: make([]*types.Node, 0),
+		OnlyInB: make([]*types.Node, 0),
+		Changed: make([]*types.Node, 0),
+	}
+	var last *types.Node
+	found := 0
+	err = s.diffWorlds(ctx, worldA, worldB, root, afterID, func(kind string, node *types.Node) error {
+		if opts.Limit > 0 && found == opts.Limit {
+			diff.NextCursor = s.encodeCursor(scope, worlds, last)
+			return errDiffPageFull
+		}
+		switch kind {
+		case types.DiffOnlyInA:
+			diff.OnlyInA = append(diff.OnlyInA, node)
+		case types.DiffOnlyInB:
+			diff.OnlyInB = append(diff.OnlyInB, node)
+		case types.DiffChanged:
+			diff.Changed = append(diff.Changed, node)
+		}
+		last = node
+		found++
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDiffPageFull) {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
+// DiffWorldsFunc streams every difference between two worlds under root (a path, "" = whole tree)
+// to fn in ID order, without paging. An error from fn stops the scan and is returned.
+func (s *SpectraFS) DiffWorldsFunc(ctx context.Context, worldA, worldB, root string, fn func(kind string, node *types.Node) error) error {
+	root, err := s.validateDiff(worldA, worldB, root)
+	if err != nil {
+		return err
+	}
+	return s.diffWorlds(ctx, worldA, worldB, root, "", fn)
+}
+
+// validateDiff checks both worlds and the subtree root, returning the normalized root path
+func (s *SpectraFS) validateDiff(worldA, worldB, root string) (string, error) {
+	for _, world := range []string{worldA, worldB} {
+		if err := s.checkWorld(world); err != nil {
+			return "", err
+		}
+	}
+
+	if root == "" || root == "/" {
+		return "/", nil
+	}
+	if _, err := s.db.GetNodeByPath(root, ""); err != nil {
+		return "", fmt.Errorf("%w: diff root %s", ErrNodeNotFound, root)
+	}
+	return root, nil
+}
+
+// diffWorlds scans the nodes after afterID and reports each node under root whose presence differs between the worlds
+func (s *SpectraFS) diffWorlds(ctx context.Context, worldA, worldB, root, afterID string, fn func(kind string, node *types.Node) error) error {
+	return s.db.ScanNodes(ctx, afterID, func(node *types.Node) (bool, error) {
+		if !pathHasPrefix(node.Path, root) {
+			return true, nil
+		}
+
+		s.applyRetentionView(node)
+		inA, inB := node.ExistenceMap[worldA], node.ExistenceMap[worldB]
+		if inA == inB {
+			return true, nil // Present in both with shared metadata, or in neither
+		}
+
+		kind := types.DiffOnlyInA
+		if inB {
+			kind = types.DiffOnlyInB
+		}
+		if err := fn(kind, s.presentRoot(node)); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+}
@@ -0,0 +1,94 @@
+package spectrafs
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// diffIDs returns the IDs of nodes, sorted
+func diffIDs(nodes []*types.Node) []string {
+	ids := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		ids = append(ids, node.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestDiffWorldsMatchesExistence(t *testing.T) {
+	s := generatedFS(t)
+	ctx := context.Background()
+
+	nodes := storedNodes(t, s)
+	var onlyPrimary, onlyS1 []string
+	for id, node := range nodes {
+		switch {
+		case node.ExistenceMap["primary"] && !node.ExistenceMap["s1"]:
+			onlyPrimary = append(onlyPrimary, id)
+		case node.ExistenceMap["s1"] && !node.ExistenceMap["primary"]:
+			onlyS1 = append(onlyS1, id)
+		}
+	}
+	slices.Sort(onlyPrimary)
+	slices.Sort(onlyS1)
+	if len(onlyPrimary) < 3 {
+		t.Fatalf("only %d nodes differ between primary and s1; the test needs a few", len(onlyPrimary))
+	}
+
+	diff, err := s.DiffWorlds(ctx, "primary", "s1", types.DiffOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := diffIDs(diff.OnlyInA); !slices.Equal(got, onlyPrimary) {
+		t.Errorf("only in primary = %v, want %v", got, onlyPrimary)
+	}
+	if got := diffIDs(diff.OnlyInB); !slices.Equal(got, onlyS1) {
+		t.Errorf("only in s1 = %v, want %v", got, onlyS1)
+	}
+	if diff.NextCursor != "" {
+		t.Errorf("unpaged diff has next cursor %q", diff.NextCursor)
+	}
+
+	// Pages of 2 add up to the same differences
+	var paged []*types.Node
+	opts := types.DiffOptions{Limit: 2}
+	for {
+		page, err := s.DiffWorlds(ctx, "primary", "s1", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paged = append(append(paged, page.OnlyInA...), page.OnlyInB...)
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+	if got, want := diffIDs(paged), diffIDs(append(diff.OnlyInA, diff.OnlyInB...)); !slices.Equal(got, want) {
+		t.Errorf("paged diff = %d nodes, want the %d of the unpaged one", len(got), len(want))
+	}
+
+	// Scoped to a subtree, only differences below it are reported
+	root := nodes[onlyPrimary[0]]
+	for root.DepthLevel > 1 {
+		root = nodes[root.ParentID]
+	}
+	scoped := make(map[string]string)
+	err = s.DiffWorldsFunc(ctx, "primary", "s1", root.Path, func(kind string, node *types.Node) error {
+		scoped[node.ID] = kind
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scoped[onlyPrimary[0]] != types.DiffOnlyInA {
+		t.Errorf("diff scoped to %s misses %s", root.Path, onlyPrimary[0])
+	}
+	for id := range scoped {
+		if node := nodes[id]; !pathHasPrefix(node.Path, root.Path) {
+			t.Errorf("diff scoped to %s reports %s", root.Path, node.Path)
+		}
+	}
+}
@@ -28,8 +28,11 @@ func (de *nodeDirEntry) IsDir() bool {
 
 // Type returns the type bits for the entry
 func (de *nodeDirEntry) Type() fs.FileMode {
-	if de.node.Type == types.NodeTypeFolder {
+	switch de.node.Type {
+	case types.NodeTypeFolder:
 		return fs.ModeDir
+	case types.NodeTypeSymlink:
+		return fs.ModeSymlink
 	}
 	return 0
 }
@@ -0,0 +1,67 @@
+package spectrafs
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// Duplicates groups the stored files in world (primary if empty) whose content is byte-identical:
+// files generated from the same duplicate pool slot (see seed.duplicate_probability), copies and
+// their source, and files whose content was replaced by another's. It is one ForEachNode pass over
+// the world, so only materialized files are grouped. With seed.identical_file_content every file has the
+// same bytes anyway, which the groups do not show. ctx.Err() is returned if ctx is cancelled
+func (s *SpectraFS) Duplicates(ctx context.Context, world string) (*types.DuplicatesReport, error) {
+	if world == "" {
+		world = "primary"
+	}
+	if err := s.checkWorld(world); err != nil {
+		return nil, err
+	}
+
+	type contentKey struct {
+		id   string
+		size int64
+	}
+	groups := make(map[contentKey]*types.DuplicateGroup)
+	report := &types.DuplicatesReport{World: world, Groups: make([]types.DuplicateGroup, 0)}
+
+	err := s.ForEachNode(ctx, types.NodeScanOptions{World: world}, func(node *types.Node) error {
+		if node.Type != types.NodeTypeFile || !s.applyRetentionView(node).ExistenceMap[world] {
+			return nil
+		}
+		report.Files++
+
+		key := contentKey{id: node.ContentID, size: node.Size}
+		if key.id == "" {
+			key.id = node.ID
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &types.DuplicateGroup{ContentID: key.id, Size: node.Size}
+			groups[key] = group
+		}
+		if group.Checksum == "" && node.Checksum != nil {
+			group.Checksum = *node.Checksum
+		}
+		group.Paths = append(group.Paths, node.Path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		if len(group.Paths) < 2 {
+			continue
+		}
+		sort.Strings(group.Paths)
+		report.Grouped += int64(len(group.Paths))
+		report.Groups = append(report.Groups, *group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		return report.Groups[i].Paths[0] < report.Groups[j].Paths[0]
+	})
+	return report, nil
+}
@@ -0,0 +1,78 @@
+package spectrafs
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// duplicates generates the whole test tree with seed.duplicate_probability set and returns its duplicate groups
+func duplicates(t *testing.T, probability float64) (*SpectraFS, *types.DuplicatesReport) {
+	t.Helper()
+	s := newTestFS(t, func(cfg *types.Config) { cfg.Seed.DuplicateProbability = probability })
+	if _, err := s.GenerateAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	report, err := s.Duplicates(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, report
+}
+
+func TestDuplicatesShareContent(t *testing.T) {
+	// Every file draws from the pool, so even the small test tree holds groups
+	s, report := duplicates(t, 1)
+	if len(report.Groups) == 0 {
+		t.Fatalf("no duplicate groups among %d files", report.Files)
+	}
+
+	for _, group := range report.Groups {
+		if len(group.Paths) < 2 {
+			t.Errorf("group %s holds only %v", group.ContentID, group.Paths)
+			continue
+		}
+		var first []byte
+		for i, path := range group.Paths {
+			node, err := s.db.GetNodeByPath(path, "primary")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if node.Checksum == nil || *node.Checksum != group.Checksum {
+				t.Errorf("%s checksum differs from its group's %s", path, group.Checksum)
+			}
+			data, _, err := s.GetFileData(node.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i == 0 {
+				first = data
+			} else if !bytes.Equal(data, first) {
+				t.Errorf("%s content differs from %s", path, group.Paths[0])
+			}
+		}
+	}
+
+	// The same seed yields the same groups
+	if _, again := duplicates(t, 1); !reflect.DeepEqual(again.Groups, report.Groups) {
+		t.Errorf("a second instance grouped %v, the first %v", again.Groups, report.Groups)
+	}
+}
+
+func TestDuplicatesOffByDefault(t *testing.T) {
+	s, report := duplicates(t, 0)
+	if report.Files == 0 || len(report.Groups) != 0 {
+		t.Errorf("probability 0 grouped %d of %d files into %v", report.Grouped, report.Files, report.Groups)
+	}
+
+	// Nothing is drawn for duplicates, so the tree is the one generated without the setting
+	want := storedNodes(t, generatedFS(t))
+	for id, node := range storedNodes(t, s) {
+		if other, ok := want[id]; !ok || other.Size != node.Size || node.ContentID != "" {
+			t.Errorf("%s differs from the tree generated without duplicate_probability", node.Path)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package spectrafs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// emptyBelowRoot gives the root two folders and no files, and those folders no children
+func emptyBelowRoot(cfg *types.Config) {
+	zero := 0
+	cfg.Seed.MinFolders, cfg.Seed.MaxFolders = 2, 2
+	cfg.Seed.MinFiles, cfg.Seed.MaxFiles = 0, 0
+	cfg.Seed.Profile = &types.GenerationProfile{Depths: []types.DepthProfile{
+		{MinDepth: 1, MaxDepth: 1, MinFolders: &zero, MaxFolders: &zero, MinFiles: &zero, MaxFiles: &zero},
+	}}
+}
+
+// rootFolders lists the root and returns its folders
+func rootFolders(t *testing.T, s *SpectraFS) []*types.Node {
+	t.Helper()
+	var folders []*types.Node
+	for _, node := range childNodes(list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"})) {
+		if node.Type == types.NodeTypeFolder {
+			folders = append(folders, node)
+		}
+	}
+	if len(folders) == 0 {
+		t.Fatal("root has no folders")
+	}
+	return folders
+}
+
+func TestMaxDepthFolderIsNeverGenerated(t *testing.T) {
+	s := newTestFS(t, func(cfg *types.Config) { cfg.Seed.MaxDepth = 1 })
+	folder := rootFolders(t, s)[0]
+	stored := len(storedNodes(t, s))
+
+	for range 2 {
+		result := list(t, s, &models.ListChildrenRequest{ParentID: folder.ID, TableName: "primary"})
+		if result.Generated || len(childNodes(result)) != 0 || result.Message != AtMaxDepthMessage {
+			t.Errorf("listing %s at max depth = generated %v, %d children, %q", folder.Path, result.Generated, len(childNodes(result)), result.Message)
+		}
+	}
+	if now := len(storedNodes(t, s)); now != stored {
+		t.Errorf("listing a folder at max depth stored %d nodes", now-stored)
+	}
+
+	progress, err := s.GenerateAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.FoldersGenerated != 0 || progress.NodesCreated != 0 {
+		t.Errorf("GenerateAll at max depth generated %d folders and %d nodes", progress.FoldersGenerated, progress.NodesCreated)
+	}
+}
+
+func TestEmptyFolderIsGeneratedOnce(t *testing.T) {
+	s := newTestFS(t, emptyBelowRoot)
+	folder := rootFolders(t, s)[0]
+
+	first := list(t, s, &models.ListChildrenRequest{ParentID: folder.ID, TableName: "primary"})
+	again := list(t, s, &models.ListChildrenRequest{ParentID: folder.ID, TableName: "primary"})
+	if !first.Generated || again.Generated || len(childNodes(first))+len(childNodes(again)) != 0 {
+		t.Errorf("listing %s twice generated %v then %v", folder.Path, first.Generated, again.Generated)
+	}
+	if marked, err := s.db.ChildrenGenerated(folder.ID); err != nil || !marked {
+		t.Errorf("%s children generated = %v, %v; want the marker", folder.Path, marked, err)
+	}
+}
+
+func TestGenerateAllMarksEmptyFolders(t *testing.T) {
+	s := newTestFS(t, emptyBelowRoot)
+	ctx := context.Background()
+	progress, err := s.GenerateAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.FoldersGenerated != 3 || progress.NodesCreated != 2 {
+		t.Fatalf("GenerateAll generated %d folders and %d nodes, want the root and its 2 empty folders", progress.FoldersGenerated, progress.NodesCreated)
+	}
+
+	// Listings find the marker GenerateAll left
+	for _, folder := range rootFolders(t, s) {
+		if marked, err := s.db.ChildrenGenerated(folder.ID); err != nil || !marked {
+			t.Errorf("%s children generated = %v, %v; want the marker", folder.Path, marked, err)
+		}
+		if result := list(t, s, &models.ListChildrenRequest{ParentID: folder.ID, TableName: "primary"}); result.Generated {
+			t.Errorf("listing %s after GenerateAll generated it again", folder.Path)
+		}
+	}
+
+	// A spent budget does not stop a run that only meets generated folders
+	cfg := *s.config()
+	cfg.Seed.MaxTotalNodes = 2
+	if err := s.UpdateConfig(ctx, &cfg, false); err != nil {
+		t.Fatal(err)
+	}
+	progress, err = s.GenerateAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.BudgetExhausted || progress.FoldersGenerated != 0 || progress.FoldersSkipped != 3 {
+		t.Errorf("second GenerateAll: budget exhausted %v, %d folders generated, %d skipped; want 0 and 3",
+			progress.BudgetExhausted, progress.FoldersGenerated, progress.FoldersSkipped)
+	}
+}
+
+func TestDeletedChildrenAreNotRegenerated(t *testing.T) {
+	s := newTestFS(t)
+	ctx := context.Background()
+	listed := childNodes(list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}))
+	for _, node := range listed {
+		if err := s.DeleteNode(ctx, &models.DeleteNodeRequest{ID: node.ID, Recursive: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"})
+	if result.Generated || len(childNodes(result)) != 0 {
+		t.Errorf("listing the root after deleting its %d children generated %v and returned %d", len(listed), result.Generated, len(childNodes(result)))
+	}
+	progress, err := s.GenerateAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.NodesCreated != 0 {
+		t.Errorf("GenerateAll after deleting the root's children created %d nodes", progress.NodesCreated)
+	}
+}
+
+func TestGenerateAllMarksGeneratedFolders(t *testing.T) {
+	s := generatedFS(t)
+	ctx := context.Background()
+	folder := rootFolders(t, s)[0]
+	for _, node := range childNodes(list(t, s, &models.ListChildrenRequest{ParentID: folder.ID, TableName: "primary"})) {
+		if err := s.DeleteNode(ctx, &models.DeleteNodeRequest{ID: node.ID, Recursive: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if result := list(t, s, &models.ListChildrenRequest{ParentID: folder.ID, TableName: "primary"}); result.Generated {
+		t.Errorf("listing %s after deleting its children generated it again", folder.Path)
+	}
+	progress, err := s.GenerateAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.NodesCreated != 0 {
+		t.Errorf("second GenerateAll created %d nodes under emptied folders", progress.NodesCreated)
+	}
+}
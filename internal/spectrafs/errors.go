@@ -0,0 +1,39 @@
+package spectrafs
+
+import (
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+)
+
+// Error categories matched by the sentinels of this package and db (see db.NewError); ErrRootProtected
+// stands on its own. Errors matching none of them are internal failures
+var (
+	ErrNotFound     = db.ErrNotFound
+	ErrInvalidInput = db.ErrInvalidInput
+	ErrConflict     = db.ErrConflict
+)
+
+// ErrParentNotFound is returned when the parent named by a list, create, upload, batch or walk request
+// does not exist; the lookup's ErrNodeNotFound is wrapped with it
+var ErrParentNotFound = newError(ErrNotFound, "parent not found")
+
+// Errors of the instance manager that hosts several filesystems behind one server (see sdk.MultiInstance)
+var (
+	ErrUnknownInstance = newError(ErrNotFound, "unknown instance")
+	ErrInstanceExists  = newError(ErrConflict, "instance already exists")
+	ErrInvalidInstance = newError(ErrInvalidInput, "invalid instance")
+)
+
+// validateRequest marks a request model validation failure as ErrInvalidInput (nil stays nil)
+func validateRequest(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+}
+
+// newError returns a sentinel error that also matches category (see db.NewError)
+func newError(category error, message string) error {
+	return db.NewError(category, message)
+}
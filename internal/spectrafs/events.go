@@ -0,0 +1,160 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// EventBufferSize is the number of events buffered per subscriber before it is disconnected as too slow
+const EventBufferSize = 256
+
+// ErrEventsClosed is returned by Subscribe once the instance is closed
+var ErrEventsClosed = errors.New("event stream is closed")
+
+// eventHub fans live events out to subscribers without ever blocking the writer
+type eventHub struct {
+	mu     sync.Mutex // Protects subs and closed; journal holds it across append and publish to keep order
+	subs   map[*subscriber]struct{}
+	closed bool
+}
+
+// subscriber is one Subscribe call's buffered channel
+type subscriber struct {
+	ch   chan types.Event
+	gone chan struct{} // Closed when the hub drops the subscriber, releasing its context watcher
+}
+
+// newEventHub creates an empty hub
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[*subscriber]struct{})}
+}
+
+// Subscribe returns a channel of live events: journaled changes in journal order (with Seq set) and
+// nodes created by lazy generation. The channel is closed when ctx is done, when the instance is
+// closed, or when the subscriber falls EventBufferSize events behind; a consumer whose ctx is still
+// live should then resume from GetChanges with the last Seq it saw
+func (s *SpectraFS) Subscribe(ctx context.Context) (<-chan types.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sub := &subscriber{
+		ch:   make(chan types.Event, EventBufferSize),
+		gone: make(chan struct{}),
+	}
+	h := s.events
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil, ErrEventsClosed
+	}
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			h.mu.Lock()
+			h.dropLocked(sub)
+			h.mu.Unlock()
+		case <-sub.gone:
+		}
+	}()
+	return sub.ch, nil
+}
+
+// publish delivers events to every subscriber
+func (h *eventHub) publish(events ...types.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, event := range events {
+		h.publishLocked(event)
+	}
+}
+
+// publishLocked delivers event to every subscriber, dropping those whose buffer is full
+func (h *eventHub) publishLocked(event types.Event) {
+	for sub := range h.subs {
+		select {
+		case sub.ch <- event:
+		default:
+			h.dropLocked(sub)
+		}
+	}
+}
+
+// dropLocked unregisters sub and closes its channel (no-op if already dropped)
+func (h *eventHub) dropLocked(sub *subscriber) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	delete(h.subs, sub)
+	close(sub.ch)
+	close(sub.gone)
+}
+
+// close drops every subscriber and refuses new ones
+func (h *eventHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subs {
+		h.dropLocked(sub)
+	}
+}
+
+// active reports whether anyone is subscribed, so callers can skip building events
+func (h *eventHub) active() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs) > 0
+}
+
+// liveEvent builds the live event for a journaled change, with a copy of the node snapshot
+func liveEvent(event types.ChangeEvent, node *types.Node) types.Event {
+	live := types.Event{
+		Seq:     event.Seq,
+		Op:      event.Op,
+		Worlds:  event.Worlds,
+		OldPath: event.OldPath,
+		Nodes:   event.Nodes,
+		At:      event.At,
+	}
+	if node != nil {
+		live.Node = snapshotNode(node)
+	}
+	return live
+}
+
+// generatedEvents builds the live events for nodes created by lazy generation during a listing of world
+func (s *SpectraFS) generatedEvents(nodes []*types.Node, world string) []types.Event {
+	now := s.now()
+	events := make([]types.Event, len(nodes))
+	for i, node := range nodes {
+		events[i] = types.Event{
+			Op:     types.EventGenerate,
+			World:  world,
+			Worlds: existingWorlds(node.ExistenceMap),
+			Node:   snapshotNode(node),
+			Nodes:  1,
+			At:     now,
+		}
+	}
+	return events
+}
+
+// snapshotNode copies a node so subscribers cannot observe later changes to it
+func snapshotNode(node *types.Node) *types.Node {
+	snapshot := *node
+	snapshot.ExistenceMap = maps.Clone(node.ExistenceMap)
+	snapshot.Metadata = maps.Clone(node.Metadata)
+	if node.Checksum != nil {
+		checksum := *node.Checksum
+		snapshot.Checksum = &checksum
+	}
+	return &snapshot
+}
@@ -0,0 +1,167 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// subscribe subscribes to s for the rest of the test
+func subscribe(t *testing.T, s *SpectraFS) <-chan types.Event {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	events, err := s.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+// drain returns the events already delivered on ch, without waiting for more
+func drain(ch <-chan types.Event) []types.Event {
+	var events []types.Event
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+// closedWithin reports whether ch is closed within a second, discarding events on the way
+func closedWithin(ch <-chan types.Event) bool {
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return true
+			}
+		case <-timeout:
+			return false
+		}
+	}
+}
+
+func TestEventsFollowOperationOrder(t *testing.T) {
+	// Every node exists in s1, so the move below is never refused for existence
+	s := newTestFS(t, func(cfg *types.Config) { cfg.SecondaryTables = map[string]float64{"s1": 1} })
+	events := subscribe(t, s)
+	ctx := context.Background()
+
+	listed := childNodes(list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}))
+	a := mkdir(t, s, s.root, "a")
+	upload(t, s, a.ID, "x.txt", []byte("x"))
+	if _, err := s.RenameNode(ctx, &models.RenameNodeRequest{ID: a.ID, NewName: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	c := mkdir(t, s, s.root, "c")
+	if _, err := s.MoveNode(ctx, &models.MoveNodeRequest{ID: a.ID, NewParentID: c.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteNode(ctx, &models.DeleteNodeRequest{ID: c.ID, Recursive: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := drain(events)
+	if len(got) < len(listed) {
+		t.Fatalf("received %d events, the listing alone generated %d nodes", len(got), len(listed))
+	}
+	for i, node := range listed {
+		if got[i].Op != types.EventGenerate || got[i].World != "primary" || got[i].Node.ID != node.ID {
+			t.Errorf("event %d = %s of %v in %s, want the generation of %s", i, got[i].Op, got[i].Node, got[i].World, node.Path)
+		}
+	}
+
+	type step struct{ op, path string }
+	want := []step{
+		{types.ChangeCreate, "/a"},
+		{types.ChangeCreate, "/a/x.txt"},
+		{types.ChangeRename, "/b"},
+		{types.ChangeCreate, "/c"},
+		{types.ChangeMove, "/c/b"},
+		{types.ChangeDelete, "/c"},
+	}
+	var steps []step
+	var seqs []int64
+	for _, event := range got[len(listed):] {
+		if event.Op == types.EventGenerate {
+			continue
+		}
+		steps = append(steps, step{event.Op, event.Node.Path})
+		seqs = append(seqs, event.Seq)
+	}
+	if !slices.Equal(steps, want) {
+		t.Errorf("journaled events %v, want %v", steps, want)
+	}
+	for i, seq := range seqs {
+		if seq <= 0 || (i > 0 && seq <= seqs[i-1]) {
+			t.Errorf("sequence numbers %v are not increasing", seqs)
+			break
+		}
+	}
+
+	// Live events carry the journal's sequence numbers
+	journaled := changesSince(t, s, 0)
+	if last := journaled[len(journaled)-1]; len(seqs) > 0 && last.Seq != seqs[len(seqs)-1] {
+		t.Errorf("last live event seq %d, journal ends at %d", seqs[len(seqs)-1], last.Seq)
+	}
+}
+
+func TestEventsDropSlowSubscriber(t *testing.T) {
+	s := newTestFS(t)
+	stalled := subscribe(t, s)
+	reading := subscribe(t, s)
+
+	received := 0
+	for i := range EventBufferSize + 1 {
+		mkdir(t, s, s.root, fmt.Sprintf("f%03d", i))
+		received += len(drain(reading))
+	}
+	if received != EventBufferSize+1 {
+		t.Errorf("reading subscriber got %d events, want %d", received, EventBufferSize+1)
+	}
+	if got := len(drain(stalled)); got != EventBufferSize {
+		t.Errorf("stalled subscriber got %d buffered events, want %d", got, EventBufferSize)
+	}
+	if !closedWithin(stalled) {
+		t.Error("stalled subscriber was not disconnected")
+	}
+}
+
+func TestEventsCloseWithContextAndInstance(t *testing.T) {
+	s := newTestFS(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := s.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if !closedWithin(events) {
+		t.Error("cancelling the context did not close the channel")
+	}
+
+	events = subscribe(t, s)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !closedWithin(events) {
+		t.Error("closing the instance did not close the channel")
+	}
+	if _, err := s.Subscribe(context.Background()); !errors.Is(err, ErrEventsClosed) {
+		t.Errorf("Subscribe after Close = %v, want ErrEventsClosed", err)
+	}
+}
@@ -0,0 +1,133 @@
+package spectrafs
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// ErrAncestorMissing is returned when SetWorldExistence would add a node to a world its parent is not in
+var ErrAncestorMissing = newError(ErrConflict, "ancestor does not exist in world")
+
+// SetWorldExistence sets whether a stored node exists in a secondary world, keeping every node's
+// parent present wherever the node is. Removing (exists false) always cascades: the node and all of
+// its stored descendants leave the world. Adding (exists true) fails with ErrAncestorMissing unless
+// every ancestor is already in the world, and adds the node alone, or with all of its stored
+// descendants when recursive is set. Primary and the root cannot be changed. Nodes are rewritten
+// in batches with their per-world counters; folders never listed are generated later with their
+// own existence dice, like any other folder in the world.
+func (s *SpectraFS) SetWorldExistence(rootID, world string, exists, recursive bool) (*types.WorldExistenceResult, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if world == "" {
+		return nil, fmt.Errorf("%w: world is required", ErrInvalidInput)
+	}
+	if err := s.checkWorld(world); err != nil {
+		return nil, err
+	}
+	if world == "primary" {
+		return nil, fmt.Errorf("%w: existence in primary cannot be changed", ErrInvalidWorld)
+	}
+
+	rootID, _ = s.normalizeNodeID(rootID)
+	if err := s.guardMutation(opUpdateExistence, rootID); err != nil {
+		return nil, err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	target, err := s.db.GetNodeByID(rootID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		if err := s.checkAncestorsInWorld(target, world); err != nil {
+			return nil, err
+		}
+	}
+
+	nodes := []*types.Node{target}
+	if !exists || recursive {
+		if nodes, err = s.db.GetSubtree(target.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &types.WorldExistenceResult{
+		ID:        target.ID,
+		Path:      target.Path,
+		World:     world,
+		Exists:    exists,
+		Recursive: recursive,
+	}
+
+	// The subtree is breadth-first; removals run deepest first so an interrupted run never leaves
+	// a node present under an absent parent
+	if !exists {
+		slices.Reverse(nodes)
+	}
+	updates := make([]db.ExistenceUpdate, 0, len(nodes))
+	for _, node := range nodes {
+		if node.ExistenceMap[world] == exists {
+			result.Unchanged++
+			continue
+		}
+		existenceMap := maps.Clone(node.ExistenceMap)
+		if existenceMap == nil {
+			existenceMap = make(map[string]bool)
+		}
+		existenceMap[world] = exists
+		updates = append(updates, db.ExistenceUpdate{ID: node.ID, ExistenceMap: existenceMap})
+	}
+
+	updated, err := s.db.UpdateExistenceMaps(updates)
+	result.Updated = len(updated)
+
+	// Batches already stored are journaled even if a later one fails
+	var changes []change
+	if len(updated) > 0 {
+		changed := *target
+		changed.ExistenceMap = maps.Clone(target.ExistenceMap)
+		if changed.ExistenceMap == nil {
+			changed.ExistenceMap = make(map[string]bool)
+		}
+		changed.ExistenceMap[world] = exists
+		event := nodeChange(types.ChangeExistence, &changed, len(updated))
+		event.Worlds = []string{world}
+		changes = append(changes, event)
+	}
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to set existence of %s in world %s: %w", target.Path, world, err), s.journal(changes...))
+	}
+
+	if err := s.journal(changes...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkAncestorsInWorld returns ErrAncestorMissing, naming the highest missing ancestor, unless
+// every ancestor of node is stored as existing in world
+func (s *SpectraFS) checkAncestorsInWorld(node *types.Node, world string) error {
+	var missing *types.Node
+	for parentID := node.ParentID; parentID != ""; {
+		parent, err := s.db.GetNodeByID(parentID)
+		if err != nil {
+			return fmt.Errorf("failed to resolve ancestor of %s: %w", node.Path, err)
+		}
+		if !parent.ExistenceMap[world] {
+			missing = parent
+		}
+		parentID = parent.ParentID
+	}
+	if missing != nil {
+		return fmt.Errorf("%w: %s is not in world %s", ErrAncestorMissing, missing.Path, world)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// inWorld returns the paths of the stored nodes under prefix (itself included) and whether each is in world
+func inWorld(t *testing.T, s *SpectraFS, prefix, world string) map[string]bool {
+	t.Helper()
+	paths := make(map[string]bool)
+	for _, node := range storedNodes(t, s) {
+		if node.Path == prefix || strings.HasPrefix(node.Path, prefix+"/") {
+			paths[node.Path] = node.ExistenceMap[world]
+		}
+	}
+	return paths
+}
+
+// checkExistenceCounters fails the test unless the stored per-world counters match the stored nodes
+func checkExistenceCounters(t *testing.T, s *SpectraFS, world string) {
+	t.Helper()
+	var want int64
+	for _, node := range storedNodes(t, s) {
+		if node.ID != s.root && node.ExistenceMap[world] {
+			want++
+		}
+	}
+	if got := storedStats(t, s).SecondaryNodes[world]; got != want {
+		t.Errorf("counter for %s is %d, %d stored nodes are in it", world, got, want)
+	}
+}
+
+func TestSetWorldExistenceCascades(t *testing.T) {
+	// Every generated node starts in s1
+	s := newTestFS(t, func(cfg *types.Config) { cfg.SecondaryTables = map[string]float64{"s1": 1} })
+	if _, err := s.GenerateAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var folder, child *types.Node
+	for _, node := range storedNodes(t, s) {
+		if node.Type == types.NodeTypeFolder && node.DepthLevel == 1 {
+			folder = node
+			break
+		}
+	}
+	for _, node := range storedNodes(t, s) {
+		if node.ParentID == folder.ID && node.Type == types.NodeTypeFolder {
+			child = node
+			break
+		}
+	}
+	if child == nil {
+		t.Fatalf("%s has no subfolder", folder.Path)
+	}
+	subtree := inWorld(t, s, folder.Path, "s1")
+
+	// Removing cascades to every descendant and leaves primary and the parent alone
+	result, err := s.SetWorldExistence(folder.ID, "s1", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Updated != len(subtree) || result.Unchanged != 0 {
+		t.Errorf("removal updated %d and left %d, the subtree has %d nodes", result.Updated, result.Unchanged, len(subtree))
+	}
+	for path, exists := range inWorld(t, s, folder.Path, "s1") {
+		if exists {
+			t.Errorf("%s is still in s1 after its ancestor was removed", path)
+		}
+	}
+	for path, exists := range inWorld(t, s, folder.Path, "primary") {
+		if !exists {
+			t.Errorf("%s left primary", path)
+		}
+	}
+	if root := inWorld(t, s, "/", "s1")["/"]; !root {
+		t.Error("the root left s1")
+	}
+	checkExistenceCounters(t, s, "s1")
+
+	// Adding needs every ancestor in the world
+	if _, err := s.SetWorldExistence(child.ID, "s1", true, true); !errors.Is(err, ErrAncestorMissing) {
+		t.Errorf("adding %s under a removed folder = %v, want ErrAncestorMissing", child.Path, err)
+	}
+
+	// Adding without recursive adds the node alone
+	if result, err = s.SetWorldExistence(folder.ID, "s1", true, false); err != nil {
+		t.Fatal(err)
+	}
+	if result.Updated != 1 {
+		t.Errorf("non-recursive add updated %d nodes, want 1", result.Updated)
+	}
+	for path, exists := range inWorld(t, s, folder.Path, "s1") {
+		if exists != (path == folder.Path) {
+			t.Errorf("%s in s1 = %v after adding only %s", path, exists, folder.Path)
+		}
+	}
+
+	// Recursive adds the rest, the folder itself already being there
+	if result, err = s.SetWorldExistence(folder.ID, "s1", true, true); err != nil {
+		t.Fatal(err)
+	}
+	if result.Updated != len(subtree)-1 || result.Unchanged != 1 {
+		t.Errorf("recursive add updated %d and left %d, want %d and 1", result.Updated, result.Unchanged, len(subtree)-1)
+	}
+	for path, exists := range inWorld(t, s, folder.Path, "s1") {
+		if !exists {
+			t.Errorf("%s is not back in s1", path)
+		}
+	}
+	checkExistenceCounters(t, s, "s1")
+
+	if _, err := s.SetWorldExistence(folder.ID, "primary", false, false); !errors.Is(err, ErrInvalidWorld) {
+		t.Errorf("removing from primary = %v, want ErrInvalidWorld", err)
+	}
+	if _, err := s.SetWorldExistence(s.root, "s1", false, false); !errors.Is(err, ErrRootProtected) {
+		t.Errorf("removing the root = %v, want ErrRootProtected", err)
+	}
+}
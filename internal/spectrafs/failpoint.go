@@ -0,0 +1,18 @@
+package spectrafs
+
+import "github.com/Project-Sylos/Spectra/internal/db"
+
+// ErrInjectedFailure is returned when the generation failure hook fires
+var ErrInjectedFailure = db.ErrInjectedFailure
+
+// ArmGenerationFailure makes generation fail once afterNodes more nodes have been inserted
+// The nodes inserted before the failure are kept; the rest of the folder is completed by the
+// next ListChildren of that folder. The hook fires once; afterNodes <= 0 disarms it
+func (s *SpectraFS) ArmGenerationFailure(afterNodes int64) {
+	s.db.ArmInsertFailure(afterNodes)
+}
+
+// GenerationFailureArmed reports whether the hook is armed and how many inserts remain before it fires
+func (s *SpectraFS) GenerationFailureArmed() (bool, int64) {
+	return s.db.InsertFailureArmed()
+}
@@ -0,0 +1,93 @@
+package spectrafs
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+)
+
+// storedChildren returns the IDs of a parent's stored primary children, without generating any
+func storedChildren(t *testing.T, s *SpectraFS, parentID string) []string {
+	t.Helper()
+	children, err := s.db.GetChildrenByParentID(parentID, "primary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, 0, len(children))
+	for _, child := range children {
+		ids = append(ids, child.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestGenerationFailureAtExactCount(t *testing.T) {
+	s := newTestFS(t)
+	reference := newTestFS(t)
+	want := childNodes(list(t, reference, &models.ListChildrenRequest{ParentID: reference.root, TableName: "primary"}))
+	if len(want) < 4 {
+		t.Fatalf("root generates only %d children", len(want))
+	}
+
+	s.ArmGenerationFailure(3)
+	if armed, remaining := s.GenerationFailureArmed(); !armed || remaining != 3 {
+		t.Fatalf("armed = %v, %d remaining; want armed with 3", armed, remaining)
+	}
+	// The failure is reported in the listing, like any other failed generation
+	result, err := s.ListChildren(context.Background(), &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success || !strings.Contains(result.Message, ErrInjectedFailure.Error()+" after 3 nodes") {
+		t.Fatalf("listing %q (success %v), want the injected failure after 3 nodes", result.Message, result.Success)
+	}
+	if got := storedChildren(t, s, s.root); len(got) != 3 {
+		t.Fatalf("%d children stored after the failure, want exactly 3", len(got))
+	}
+	if armed, _ := s.GenerationFailureArmed(); armed {
+		t.Error("hook still armed after firing")
+	}
+
+	report, err := s.CheckIntegrity(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK {
+		t.Errorf("partial tree fails the integrity check: %+v", report)
+	}
+
+	// The next listing completes the folder with the children the failed run would have made
+	got := childNodes(list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}))
+	if len(got) != len(want) {
+		t.Fatalf("listed %d children after recovery, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].ID != want[i].ID || got[i].Name != want[i].Name {
+			t.Errorf("child %d = %s (%s), want %s (%s)", i, got[i].Name, got[i].ID, want[i].Name, want[i].ID)
+		}
+	}
+	if stored := storedChildren(t, s, s.root); len(stored) != len(want) || len(slices.Compact(stored)) != len(want) {
+		t.Errorf("stored children %v, want %d without duplicates", stored, len(want))
+	}
+}
+
+func TestGenerationFailureDisarm(t *testing.T) {
+	s := newTestFS(t)
+	s.ArmGenerationFailure(1)
+	s.ArmGenerationFailure(0)
+	if armed, _ := s.GenerationFailureArmed(); armed {
+		t.Fatal("ArmGenerationFailure(0) left the hook armed")
+	}
+	list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"})
+
+	stats, err := s.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalNodes == 0 {
+		t.Errorf("nothing generated with the hook disarmed: %+v", stats)
+	}
+}
@@ -1,6 +1,7 @@
 package spectrafs
 
 import (
+	"errors"
 	"io"
 	"io/fs"
 
@@ -10,7 +11,9 @@ import (
 // spectraFile implements fs.File for regular files
 type spectraFile struct {
 	node    *types.Node
-	data    []byte
+	info    fs.FileInfo   // Overrides the node-derived FileInfo for virtual files
+	data    []byte        // In-memory content (virtual files)
+	stream  io.ReadSeeker // Lazily generated content (stored files); takes precedence over data
 	offset  int64
 	closeFn func() error
 }
@@ -18,17 +21,39 @@ type spectraFile struct {
 // spectraDir implements fs.ReadDirFile for directories
 type spectraDir struct {
 	node    *types.Node
+	info    fs.FileInfo // Overrides the node-derived FileInfo for virtual directories
 	entries []fs.DirEntry
+	pager   *dirPager // Fetches further pages of a stored directory (nil for virtual ones)
+	err     error     // Deferred error from a page fetch, returned once buffered entries run out
 	closeFn func() error
 }
 
+// fill buffers pages until at least n entries are available (n <= 0: the whole directory)
+func (d *spectraDir) fill(n int) {
+	for d.pager != nil && !d.pager.done && d.err == nil && (n <= 0 || len(d.entries) < n) {
+		page, err := d.pager.next()
+		if err != nil {
+			d.err = err
+			return
+		}
+		d.entries = append(d.entries, page...)
+	}
+}
+
 // Stat returns the FileInfo structure describing file
 func (f *spectraFile) Stat() (fs.FileInfo, error) {
+	if f.info != nil {
+		return f.info, nil
+	}
 	return NewFileInfo(f.node), nil
 }
 
 // Read reads up to len(b) bytes from the file
 func (f *spectraFile) Read(b []byte) (int, error) {
+	if f.stream != nil {
+		return f.stream.Read(b)
+	}
+
 	if f.data == nil {
 		return 0, io.EOF
 	}
@@ -42,6 +67,30 @@ func (f *spectraFile) Read(b []byte) (int, error) {
 	return n, nil
 }
 
+// Seek sets the offset for the next Read, implementing io.Seeker
+func (f *spectraFile) Seek(offset int64, whence int) (int64, error) {
+	if f.stream != nil {
+		return f.stream.Seek(offset, whence)
+	}
+
+	var next int64
+	switch whence {
+	case io.SeekStart:
+		next = offset
+	case io.SeekCurrent:
+		next = f.offset + offset
+	case io.SeekEnd:
+		next = int64(len(f.data)) + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if next < 0 {
+		return 0, errors.New("negative position")
+	}
+	f.offset = next
+	return next, nil
+}
+
 // Close closes the file
 func (f *spectraFile) Close() error {
 	if f.closeFn != nil {
@@ -52,6 +101,9 @@ func (f *spectraFile) Close() error {
 
 // Stat returns the FileInfo structure describing dir
 func (d *spectraDir) Stat() (fs.FileInfo, error) {
+	if d.info != nil {
+		return d.info, nil
+	}
 	return NewFileInfo(d.node), nil
 }
 
@@ -62,13 +114,14 @@ func (d *spectraDir) Read(b []byte) (int, error) {
 }
 
 // ReadDir reads the contents of the directory and returns
-// a slice of up to n DirEntry values in directory order
+// a slice of up to n DirEntry values in directory order: the ListChildren order of folders,
+// then files, then symlinks, with any virtual entries last. SpectraFSWrapper.ReadDir sorts by name
 func (d *spectraDir) ReadDir(n int) ([]fs.DirEntry, error) {
-	if d.entries == nil {
-		return nil, io.EOF
-	}
-
 	if n <= 0 {
+		d.fill(-1)
+		if d.err != nil {
+			return nil, d.err
+		}
 		// Return all entries
 		result := make([]fs.DirEntry, len(d.entries))
 		copy(result, d.entries)
@@ -76,7 +129,11 @@ func (d *spectraDir) ReadDir(n int) ([]fs.DirEntry, error) {
 		return result, nil
 	}
 
+	d.fill(n)
 	if len(d.entries) == 0 {
+		if d.err != nil {
+			return nil, d.err
+		}
 		return nil, io.EOF
 	}
 
@@ -91,7 +148,7 @@ func (d *spectraDir) ReadDir(n int) ([]fs.DirEntry, error) {
 	d.entries = d.entries[count:]
 
 	var err error
-	if len(d.entries) == 0 {
+	if len(d.entries) == 0 && (d.pager == nil || d.pager.done) {
 		err = io.EOF
 	}
 
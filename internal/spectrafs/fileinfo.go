@@ -22,22 +22,26 @@ func (fi *nodeFileInfo) Name() string {
 	return fi.node.Name
 }
 
-// Size returns the length in bytes for regular files; 0 for directories
+// Size returns the length in bytes for regular files, the target's length for symlinks; 0 for directories
 func (fi *nodeFileInfo) Size() int64 {
 	return fi.node.Size
 }
 
 // Mode returns the file mode bits
 func (fi *nodeFileInfo) Mode() fs.FileMode {
-	if fi.node.Type == types.NodeTypeFolder {
+	switch fi.node.Type {
+	case types.NodeTypeFolder:
 		return fs.ModeDir | 0755
+	case types.NodeTypeSymlink:
+		return fs.ModeSymlink | 0777
 	}
 	return 0644
 }
 
 // ModTime returns the modification time
+// The monotonic clock reading is stripped so freshly generated and stored nodes compare equal
 func (fi *nodeFileInfo) ModTime() time.Time {
-	return fi.node.LastUpdated
+	return fi.node.LastUpdated.Round(0)
 }
 
 // IsDir reports whether the file describes a directory
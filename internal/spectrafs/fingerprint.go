@@ -0,0 +1,141 @@
+package spectrafs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// ErrConfigMismatch is returned on open when the config's seed, branching or world settings differ
+// from those the database was generated with, and db.accept_config_change is not set
+var ErrConfigMismatch = newError(ErrConflict, "config does not match the database")
+
+// PersistedConfig returns the generation settings stored in the database, which match the config
+// unless they were changed since open
+func (s *SpectraFS) PersistedConfig() (*types.GenerationFingerprint, error) {
+	return s.db.Fingerprint()
+}
+
+// ConfigChangesOnOpen lists the differences between the stored generation settings and the config
+// that db.accept_config_change let this open through, or nil if they matched
+func (s *SpectraFS) ConfigChangesOnOpen() []string {
+	return s.configChanges
+}
+
+// recordWorlds stores the generation settings after AddWorld or RemoveWorld changed the worlds
+// NOTE: This function assumes the caller already holds worldsMu
+func (s *SpectraFS) recordWorlds() error {
+	if err := s.db.SetFingerprint(generationFingerprint(s.config(), s.now())); err != nil {
+		return fmt.Errorf("failed to record generation settings: %w", err)
+	}
+	return nil
+}
+
+// checkFingerprintOnOpen compares the config with the generation settings stored in the database,
+// storing the config's when there are none yet (unless the database is read-only). A mismatch is
+// ErrConflict unless db.accept_config_change is set, in which case the config's settings are stored
+// and the differences returned
+func checkFingerprintOnOpen(database *db.DB, cfg *types.Config) ([]string, error) {
+	current := generationFingerprint(cfg, time.Now())
+
+	stored, err := database.Fingerprint()
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil && stored.Hash == current.Hash {
+		return nil, nil
+	}
+
+	var changes []string
+	if stored != nil {
+		changes = fingerprintChanges(stored, current)
+		if !cfg.DB.AcceptConfigChange {
+			return nil, fmt.Errorf("%w: %s (set db.accept_config_change to open it anyway)", ErrConfigMismatch, strings.Join(changes, "; "))
+		}
+	}
+	if database.ReadOnly() {
+		return changes, nil // Settings are never recorded in a read-only database
+	}
+	if err := database.SetFingerprint(current); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// generationFingerprint returns the generation settings of cfg, stamped with at
+func generationFingerprint(cfg *types.Config, at time.Time) *types.GenerationFingerprint {
+	worlds := maps.Clone(cfg.SecondaryTables)
+	if worlds == nil {
+		worlds = make(map[string]float64)
+	}
+	fingerprint := &types.GenerationFingerprint{
+		Seed:       cfg.Seed.Seed,
+		MaxDepth:   cfg.Seed.MaxDepth,
+		MinFolders: cfg.Seed.MinFolders,
+		MaxFolders: cfg.Seed.MaxFolders,
+		MinFiles:   cfg.Seed.MinFiles,
+		MaxFiles:   cfg.Seed.MaxFiles,
+		Profile:    cfg.Seed.Profile,
+		Worlds:     worlds,
+	}
+
+	// Hash the settings alone; JSON object keys are sorted, so equal settings hash equally
+	data, _ := json.Marshal(fingerprint)
+	sum := sha256.Sum256(data)
+	fingerprint.Hash = hex.EncodeToString(sum[:])
+	fingerprint.RecordedAt = at.UTC()
+	return fingerprint
+}
+
+// fingerprintChanges describes how current differs from stored, one entry per setting
+func fingerprintChanges(stored, current *types.GenerationFingerprint) []string {
+	var changes []string
+	for _, setting := range []struct {
+		name             string
+		database, config int64
+	}{
+		{"seed", stored.Seed, current.Seed},
+		{"max_depth", int64(stored.MaxDepth), int64(current.MaxDepth)},
+		{"min_folders", int64(stored.MinFolders), int64(current.MinFolders)},
+		{"max_folders", int64(stored.MaxFolders), int64(current.MaxFolders)},
+		{"min_files", int64(stored.MinFiles), int64(current.MinFiles)},
+		{"max_files", int64(stored.MaxFiles), int64(current.MaxFiles)},
+	} {
+		if setting.database != setting.config {
+			changes = append(changes, fmt.Sprintf("seed.%s is %d in the database but %d in the config", setting.name, setting.database, setting.config))
+		}
+	}
+	if !reflect.DeepEqual(stored.Profile, current.Profile) {
+		changes = append(changes, "seed.profile differs from the database's")
+	}
+
+	names := slices.Sorted(maps.Keys(stored.Worlds))
+	for name := range current.Worlds {
+		if _, ok := stored.Worlds[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		database, inDatabase := stored.Worlds[name]
+		config, inConfig := current.Worlds[name]
+		switch {
+		case !inConfig:
+			changes = append(changes, fmt.Sprintf("world %s (probability %g) is in the database but not in secondary_tables", name, database))
+		case !inDatabase:
+			changes = append(changes, fmt.Sprintf("world %s (probability %g) is in secondary_tables but not in the database", name, config))
+		case database != config:
+			changes = append(changes, fmt.Sprintf("world %s has probability %g in the database but %g in the config", name, database, config))
+		}
+	}
+	return changes
+}
@@ -0,0 +1,76 @@
+package spectrafs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestFingerprintRefusesMismatchedConfig(t *testing.T) {
+	dir := onDisk(t)
+	s := newTestFS(t, dir)
+	stored, err := s.PersistedConfig()
+	if err != nil || stored == nil || stored.Seed != s.config().Seed.Seed || stored.MaxDepth != 3 || stored.Worlds["s1"] != 0.7 {
+		t.Fatalf("PersistedConfig() on first open = %+v, %v", stored, err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The same settings reopen without changes
+	same := newTestFS(t, dir)
+	if changes := same.ConfigChangesOnOpen(); changes != nil {
+		t.Errorf("reopening with the same config reports changes %v", changes)
+	}
+	if err := same.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := testConfig(t)
+	dir(changed)
+	changed.Seed.Seed++
+	changed.SecondaryTables = map[string]float64{"s2": 0.5}
+	_, err = NewSpectraFSFromConfig(changed)
+	if !errors.Is(err, ErrConfigMismatch) || !errors.Is(err, ErrConflict) {
+		t.Fatalf("opening with another seed and worlds = %v, want ErrConfigMismatch", err)
+	}
+	for _, want := range []string{"seed.seed is", "world s1", "world s2"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("mismatch error %q does not mention %q", err, want)
+		}
+	}
+
+	// Accepting the change records the new settings, which later opens then match
+	changed.DB.AcceptConfigChange = true
+	accepted := openTestFS(t, changed)
+	if changes := accepted.ConfigChangesOnOpen(); len(changes) != 3 {
+		t.Errorf("accepted open reports changes %v, want the seed and two worlds", changes)
+	}
+	if stored, err := accepted.PersistedConfig(); err != nil || stored.Seed != changed.Seed.Seed || len(stored.Worlds) != 1 {
+		t.Errorf("PersistedConfig() after accepting = %+v, %v", stored, err)
+	}
+	if err := accepted.Close(); err != nil {
+		t.Fatal(err)
+	}
+	changed.DB.AcceptConfigChange = false
+	if changes := openTestFS(t, changed).ConfigChangesOnOpen(); changes != nil {
+		t.Errorf("reopening with the accepted config reports changes %v", changes)
+	}
+}
+
+func TestFingerprintIgnoresOtherSettings(t *testing.T) {
+	dir := onDisk(t)
+	if err := newTestFS(t, dir).Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Settings outside seed branching and worlds do not change the generated tree
+	other := newTestFS(t, dir, func(cfg *types.Config) {
+		cfg.API.Port = 9999
+		cfg.DB.JournalMaxEntries = 5
+	})
+	if changes := other.ConfigChangesOnOpen(); changes != nil {
+		t.Errorf("changing unrelated settings reports %v", changes)
+	}
+}
@@ -0,0 +1,61 @@
+package spectrafs
+
+import (
+	"context"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// generationFlight is one lazy generation of a folder's children, shared by every listing of the
+// folder that arrives while it runs
+type generationFlight struct {
+	done   chan struct{}     // Closed when the generation has finished
+	early  *types.ListResult // The leader's failed or budget-exhausted listing, shared with the waiters
+	stored bool              // The folder's children are stored (by the leader or a writer before it)
+}
+
+// generateOnce generates parent's children through generateMissingChildren unless another listing
+// of parent is already doing so, returning those in world and whether this call stored them. A
+// listing that finds a generation running waits for it and reads the stored children in world
+// instead of queueing for writeMu, or shares its failed or budget-exhausted result. If the
+// generation was cancelled before storing anything, the waiter generates the children itself.
+// epoch is the tree the caller read parent from (see generateMissingChildren)
+func (s *SpectraFS) generateOnce(ctx context.Context, parent *types.Node, world string, epoch uint64) ([]*types.Node, bool, *types.ListResult, error) {
+	s.flightsMu.Lock()
+	flight, running := s.flights[parent.ID]
+	if !running {
+		flight = &generationFlight{done: make(chan struct{})}
+		s.flights[parent.ID] = flight
+	}
+	s.flightsMu.Unlock()
+
+	if !running {
+		defer s.land(parent.ID, flight)
+		children, generated, early, err := s.generateMissingChildren(ctx, parent, world, epoch)
+		flight.early = early
+		flight.stored = err == nil && early == nil
+		return children, generated, early, err
+	}
+
+	select {
+	case <-flight.done:
+	case <-ctx.Done():
+		return nil, false, nil, ctx.Err()
+	}
+	if flight.early != nil {
+		return nil, false, flight.early, nil
+	}
+	if !flight.stored {
+		return s.generateOnce(ctx, parent, world, epoch)
+	}
+	children, early := s.storedChildren(parent.ID, world)
+	return children, false, early, nil
+}
+
+// land ends parentID's flight and releases its waiters, even if the generation panicked
+func (s *SpectraFS) land(parentID string, flight *generationFlight) {
+	s.flightsMu.Lock()
+	delete(s.flights, parentID)
+	s.flightsMu.Unlock()
+	close(flight.done)
+}
@@ -0,0 +1,378 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/generator"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// generateBatchSize is the number of nodes GenerateAll collects before inserting them in one transaction
+// A folder's children are never split across batches, so a batch may run over by one folder's worth
+const generateBatchSize = 10000
+
+// ErrGenerationRunning is returned when GenerateAll is called while another run is in progress
+var ErrGenerationRunning = newError(ErrConflict, "generation is already running")
+
+// BudgetExhaustedMessage is the ListResult message of a folder left unexpanded because the generation
+// budget (seed.max_total_nodes or seed.max_total_bytes) is spent
+const BudgetExhaustedMessage = "budget_exhausted"
+
+// AtMaxDepthMessage is the ListResult message of an empty folder at seed.max_depth, which is never
+// generated (raising max_depth lets it expand)
+const AtMaxDepthMessage = "Children retrieved successfully (folder is at max depth)"
+
+// errBudgetExhausted stops a GenerateAll run cleanly once the budget is spent
+var errBudgetExhausted = errors.New("generation budget exhausted")
+
+// generationBudget tracks stored totals against seed.max_total_nodes and seed.max_total_bytes
+type generationBudget struct {
+	maxNodes, maxBytes int64
+	nodes, bytes       int64
+}
+
+// loadBudget reads the stored node and byte totals from the stats counters (no scan)
+// Returns nil when no budget is configured
+func (s *SpectraFS) loadBudget() (*generationBudget, error) {
+	if s.config().Seed.MaxTotalNodes <= 0 && s.config().Seed.MaxTotalBytes <= 0 {
+		return nil, nil
+	}
+	stats, err := s.db.GetStats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read generation budget: %w", err)
+	}
+	return &generationBudget{
+		maxNodes: s.config().Seed.MaxTotalNodes,
+		maxBytes: s.config().Seed.MaxTotalBytes,
+		nodes:    stats.TotalNodes,
+		bytes:    stats.TotalFileSize,
+	}, nil
+}
+
+// exhausted reports whether either total has reached its budget; a nil budget never is
+// It is checked before a folder is expanded, so the totals overshoot by at most that folder's children
+func (b *generationBudget) exhausted() bool {
+	if b == nil {
+		return false
+	}
+	return (b.maxNodes > 0 && b.nodes >= b.maxNodes) || (b.maxBytes > 0 && b.bytes >= b.maxBytes)
+}
+
+// spend adds planned nodes to the totals
+func (b *generationBudget) spend(nodes []*types.Node) {
+	if b == nil {
+		return
+	}
+	for _, node := range nodes {
+		b.nodes++
+		if node.Type == types.NodeTypeFile {
+			b.bytes += node.Size
+		}
+	}
+}
+
+// generationRun tracks the progress and cancellation of the latest GenerateAll
+type generationRun struct {
+	progress types.GenerationProgress
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// levelNode is a folder queued for generation; fresh folders were created by this run and have no stored children
+type levelNode struct {
+	node  *types.Node
+	fresh bool
+}
+
+// GenerateAll eagerly materializes the whole tree down to seed.max_depth, level by level in listing order
+// Each folder's children come from its own path-seeded RNG, exactly as ListChildren would generate them,
+// checksummed by a worker pool, and inserted with BulkInsertNodes in large batches. Folders that already
+// have children or were generated before are skipped (so re-running is a no-op), and cancelling ctx stops the run after the batch
+// in flight is stored. Once the stored totals reach seed.max_total_nodes or seed.max_total_bytes the run
+// stores what it planned and stops without error, with BudgetExhausted set in its progress. Progress is reported through GetStats while the run is active and after it ends.
+// Writers, including lazy generation by concurrent listings, wait for the run to finish.
+func (s *SpectraFS) GenerateAll(ctx context.Context) (*types.GenerationProgress, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	run, err := s.startGeneration(cancel)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+
+	s.exclusive.Lock()
+	s.writeMu.Lock()
+	err = s.generateLevels(ctx, run)
+	s.writeMu.Unlock()
+	s.exclusive.Unlock()
+
+	if errors.Is(err, errBudgetExhausted) {
+		s.updateGeneration(run, func(p *types.GenerationProgress) { p.BudgetExhausted = true })
+		err = nil
+	}
+
+	progress := s.finishGeneration(run, err)
+	s.log(ctx).Debug("generation finished", "nodes", progress.NodesCreated, "depth", progress.CurrentDepth,
+		"budget_exhausted", progress.BudgetExhausted, "duration", time.Since(start), "error", progress.Error)
+	return progress, err
+}
+
+// GenerationProgress returns a snapshot of the latest GenerateAll run, or nil if none has started since open
+func (s *SpectraFS) GenerationProgress() *types.GenerationProgress {
+	s.genMu.Lock()
+	defer s.genMu.Unlock()
+
+	if s.generation == nil {
+		return nil
+	}
+	progress := s.generation.progress
+	return &progress
+}
+
+// CancelGeneration cancels a running GenerateAll and reports whether one was running
+func (s *SpectraFS) CancelGeneration() bool {
+	s.genMu.Lock()
+	defer s.genMu.Unlock()
+
+	if s.generation == nil || !s.generation.progress.Running {
+		return false
+	}
+	s.generation.cancel()
+	return true
+}
+
+// stopGeneration cancels a running GenerateAll and waits for it to store its last batch
+func (s *SpectraFS) stopGeneration() {
+	s.genMu.Lock()
+	run := s.generation
+	s.genMu.Unlock()
+
+	if run != nil {
+		run.cancel()
+		<-run.done
+	}
+}
+
+// startGeneration registers a new run, failing if one is already in progress
+func (s *SpectraFS) startGeneration(cancel context.CancelFunc) (*generationRun, error) {
+	s.genMu.Lock()
+	defer s.genMu.Unlock()
+
+	if s.generation != nil && s.generation.progress.Running {
+		return nil, ErrGenerationRunning
+	}
+
+	s.generation = &generationRun{
+		progress: types.GenerationProgress{
+			Running:   true,
+			MaxDepth:  s.config().Seed.MaxDepth,
+			StartedAt: s.now(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	return s.generation, nil
+}
+
+// finishGeneration records the end of a run and returns its final progress
+func (s *SpectraFS) finishGeneration(run *generationRun, err error) *types.GenerationProgress {
+	s.genMu.Lock()
+	defer s.genMu.Unlock()
+
+	finished := s.now()
+	run.progress.Running = false
+	run.progress.FinishedAt = &finished
+	if err != nil {
+		run.progress.Error = err.Error()
+	}
+	close(run.done)
+
+	progress := run.progress
+	return &progress
+}
+
+// updateGeneration applies a change to a run's progress under the progress lock
+func (s *SpectraFS) updateGeneration(run *generationRun, update func(*types.GenerationProgress)) {
+	s.genMu.Lock()
+	defer s.genMu.Unlock()
+	update(&run.progress)
+}
+
+// generateLevels walks the tree breadth-first, generating every folder that has no children yet
+func (s *SpectraFS) generateLevels(ctx context.Context, run *generationRun) error {
+	root, err := s.db.GetNodeByID(s.root)
+	if err != nil {
+		return fmt.Errorf("failed to load root: %w", err)
+	}
+	budget, err := s.loadBudget()
+	if err != nil {
+		return err
+	}
+
+	level := []levelNode{{node: root}}
+	for depth := root.DepthLevel; depth < s.config().Seed.MaxDepth && len(level) > 0; depth++ {
+		s.updateGeneration(run, func(p *types.GenerationProgress) { p.CurrentDepth = depth + 1 })
+
+		var next []levelNode
+		var batch generatedBatch
+		for _, parent := range level {
+			if err := ctx.Err(); err != nil {
+				return s.joinFlush(ctx, run, batch, err)
+			}
+
+			children, generated, err := s.levelChildren(parent, budget)
+			if err != nil {
+				return s.joinFlush(ctx, run, batch, err)
+			}
+
+			s.updateGeneration(run, func(p *types.GenerationProgress) {
+				if generated {
+					p.FoldersGenerated++
+				} else {
+					p.FoldersSkipped++
+				}
+			})
+
+			for _, child := range children {
+				if child.Type == types.NodeTypeFolder {
+					next = append(next, levelNode{node: child, fresh: generated})
+				}
+			}
+
+			if generated {
+				batch.nodes = append(batch.nodes, children...)
+				batch.parents = append(batch.parents, parent.node.ID)
+				if len(batch.nodes) >= generateBatchSize {
+					if err := s.flushGenerated(ctx, run, batch); err != nil {
+						return err
+					}
+					batch = generatedBatch{}
+				}
+			}
+		}
+
+		if err := s.flushGenerated(ctx, run, batch); err != nil {
+			return err
+		}
+		level = next
+	}
+
+	return nil
+}
+
+// levelChildren returns a folder's children in listing order, planning them from its RNG if it has none
+// Planned children are returned with generated set and still need checksums and inserting, and are
+// charged to budget; a folder that would need planning once the budget is spent gets errBudgetExhausted.
+// A folder whose children were generated before, even if none came out or all were deleted since,
+// is skipped without planning or touching the budget
+func (s *SpectraFS) levelChildren(parent levelNode, budget *generationBudget) ([]*types.Node, bool, error) {
+	if !parent.fresh {
+		// Finish a folder left half-generated by an injected failure (no-op otherwise)
+		if _, err := s.db.CompletePendingChildren(parent.node.ID); err != nil {
+			return nil, false, fmt.Errorf("failed to complete pending children of %s: %w", parent.node.Path, err)
+		}
+
+		// Children in every world, so world-only folders are generated too
+		children, err := s.db.GetAllChildren(parent.node.ID)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(children) > 0 {
+			return children, false, nil
+		}
+
+		generated, err := s.db.ChildrenGenerated(parent.node.ID)
+		if err != nil {
+			return nil, false, err
+		}
+		if generated {
+			return nil, false, nil
+		}
+	}
+
+	if budget.exhausted() {
+		return nil, false, errBudgetExhausted
+	}
+	children, err := generator.PlanChildren(parent.node, parent.node.DepthLevel, s.config())
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to generate children of %s: %w", parent.node.Path, err)
+	}
+	budget.spend(children)
+	db.SortNodes(children)
+	return children, true, nil
+}
+
+// generatedBatch is the planned children waiting to be stored and the folders they were planned for
+// (some of which may have come out empty)
+type generatedBatch struct {
+	nodes   []*types.Node
+	parents []string
+}
+
+// flushGenerated checksums a batch of planned nodes with a worker pool and inserts it in one transaction,
+// then marks the batch's folders as generated (see db.MarkChildrenGenerated), so listings and later
+// runs leave them alone once their children are stored
+func (s *SpectraFS) flushGenerated(ctx context.Context, run *generationRun, batch generatedBatch) error {
+	if len(batch.parents) == 0 {
+		return nil
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	jobs := make(chan *types.Node)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for node := range jobs {
+				if err := generator.ChecksumFile(node, s.config()); err != nil {
+					errs <- err
+					for range jobs {
+					}
+					return
+				}
+			}
+		}()
+	}
+	for _, node := range batch.nodes {
+		jobs <- node
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		return err
+	}
+
+	if err := s.db.BulkInsertNodes(ctx, batch.nodes); err != nil {
+		return fmt.Errorf("failed to bulk insert nodes: %w", err)
+	}
+	if err := s.db.MarkChildrenGenerated(batch.parents); err != nil {
+		return fmt.Errorf("failed to mark generated folders: %w", err)
+	}
+	s.recordGenerated(len(batch.nodes))
+	s.log(ctx).Debug("stored generated batch", "nodes", len(batch.nodes), "folders", len(batch.parents))
+
+	s.updateGeneration(run, func(p *types.GenerationProgress) { p.NodesCreated += int64(len(batch.nodes)) })
+	return nil
+}
+
+// joinFlush stores the folders already planned before a run stops, so that work is not lost,
+// and returns the reason it stopped; the flush ignores ctx's cancellation since it may be the reason
+func (s *SpectraFS) joinFlush(ctx context.Context, run *generationRun, batch generatedBatch, cause error) error {
+	if err := s.flushGenerated(context.WithoutCancel(ctx), run, batch); err != nil {
+		return errors.Join(cause, err)
+	}
+	return cause
+}
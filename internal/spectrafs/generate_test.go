@@ -0,0 +1,72 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGenerateAllIsIdempotent(t *testing.T) {
+	s := newTestFS(t)
+	ctx := context.Background()
+	first, err := s.GenerateAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := len(storedNodes(t, s))
+	if first.NodesCreated != int64(stored-1) || first.FoldersGenerated == 0 || first.Running {
+		t.Errorf("first run = %+v, want %d nodes created and the run finished", first, stored-1)
+	}
+	if first.CurrentDepth != s.config().Seed.MaxDepth {
+		t.Errorf("first run ended at depth %d, want %d", first.CurrentDepth, s.config().Seed.MaxDepth)
+	}
+
+	// Everything is generated already, so a second run only skips folders
+	second, err := s.GenerateAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.NodesCreated != 0 || second.FoldersGenerated != 0 || second.FoldersSkipped != first.FoldersGenerated {
+		t.Errorf("second run = %+v, want nothing created and the %d generated folders skipped", second, first.FoldersGenerated)
+	}
+	if got := len(storedNodes(t, s)); got != stored {
+		t.Errorf("second run changed the stored nodes from %d to %d", stored, got)
+	}
+
+	stats, err := s.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Generation == nil || stats.Generation.StartedAt != second.StartedAt {
+		t.Errorf("GetStats reports generation %+v, want the latest run", stats.Generation)
+	}
+}
+
+func TestGenerateAllCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := newTestFS(t)
+	progress, err := s.GenerateAll(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("GenerateAll with a cancelled context = %v, want context.Canceled", err)
+	}
+	if progress.Error == "" || progress.Running {
+		t.Errorf("cancelled run = %+v, want it finished with an error", progress)
+	}
+
+	// A later run completes the same tree as an uninterrupted one
+	if _, err := s.GenerateAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := storedNodes(t, generatedFS(t))
+	got := storedNodes(t, s)
+	if len(got) != len(want) {
+		t.Fatalf("tree after a cancelled run has %d nodes, want %d", len(got), len(want))
+	}
+	for id, node := range want {
+		if got[id] == nil || got[id].Path != node.Path {
+			t.Errorf("node %s (%s) missing after a cancelled run", id, node.Path)
+		}
+	}
+}
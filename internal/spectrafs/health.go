@@ -0,0 +1,71 @@
+package spectrafs
+
+import (
+	"context"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// Destructive operations reported by Health while they run
+const (
+	OperationReset   = "reset"
+	OperationImport  = "import"
+	OperationRestore = "restore"
+)
+
+// Health checks that the database is open and holds its nodes bucket and root node, with one cheap
+// read-only transaction, and reports the instance as not ready while the check fails or a Reset,
+// Import or RestoreSnapshot is replacing the tree. A failed check is remembered in LastError after it recovers
+func (s *SpectraFS) Health(ctx context.Context) *types.HealthReport {
+	s.healthMu.Lock()
+	operation := s.operation
+	s.healthMu.Unlock()
+
+	report := &types.HealthReport{
+		Ready:         true,
+		Operation:     operation,
+		DBPath:        s.config().Seed.DBPath,
+		OpenedAt:      s.openedAt,
+		UptimeSeconds: int64(time.Since(s.openedAt).Seconds()),
+	}
+
+	// The check is skipped during a destructive operation: it would only see the tree half replaced
+	switch {
+	case operation != "":
+		report.Ready = false
+		report.Reason = operation + " in progress"
+	case ctx.Err() != nil:
+		report.Ready = false
+		report.Reason = ctx.Err().Error()
+	default:
+		if err := s.db.Ping(); err != nil {
+			report.Ready = false
+			report.Reason = "database check failed: " + err.Error()
+			s.log(ctx).Warn("readiness check failed", "error", err)
+
+			s.healthMu.Lock()
+			now := time.Now()
+			s.lastHealthErr, s.lastHealthErrAt = err.Error(), &now
+			s.healthMu.Unlock()
+		}
+	}
+
+	s.healthMu.Lock()
+	report.LastError, report.LastErrorAt = s.lastHealthErr, s.lastHealthErrAt
+	s.healthMu.Unlock()
+	return report
+}
+
+// beginOperation reports op through Health until the returned function is called
+func (s *SpectraFS) beginOperation(op string) func() {
+	s.healthMu.Lock()
+	s.operation = op
+	s.healthMu.Unlock()
+
+	return func() {
+		s.healthMu.Lock()
+		s.operation = ""
+		s.healthMu.Unlock()
+	}
+}
@@ -0,0 +1,71 @@
+package spectrafs
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestHealthNotReadyDuringImport(t *testing.T) {
+	snapshot := export(t, generatedFS(t), "jsonl")
+	s := newTestFS(t)
+	ctx := context.Background()
+
+	if report := s.Health(ctx); !report.Ready || report.Operation != "" || report.DBPath != s.config().Seed.DBPath {
+		t.Fatalf("fresh instance reports %+v", report)
+	}
+
+	// The import blocks reading the second half of the snapshot while health is checked
+	r, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.Import(ctx, r, false)
+		done <- err
+	}()
+	if _, err := w.Write(snapshot[:len(snapshot)/2]); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		report := s.Health(ctx)
+		if report.Operation == OperationImport {
+			if report.Ready || report.Reason != "import in progress" {
+				t.Errorf("health during import = %+v", report)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("health never reported the import: %+v", report)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	w.Write(snapshot[len(snapshot)/2:])
+	w.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if report := s.Health(ctx); !report.Ready || report.Operation != "" {
+		t.Errorf("health after the import = %+v", report)
+	}
+}
+
+func TestHealthChecksDatabase(t *testing.T) {
+	s := newTestFS(t)
+	ctx := context.Background()
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	report := s.Health(ctx)
+	if report.Ready || report.Reason == "" || report.LastError == "" || report.LastErrorAt == nil {
+		t.Errorf("health of a closed database = %+v, want not ready with the failure recorded", report)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if report := newTestFS(t).Health(cancelled); report.Ready {
+		t.Errorf("health with a cancelled context = %+v, want not ready", report)
+	}
+}
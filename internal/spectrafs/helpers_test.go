@@ -0,0 +1,138 @@
+package spectrafs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/config"
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// testBackendEnv switches the test instances to a file in t.TempDir() when set to "file"
+// (the same switch as sdk.TestFS), so the suite can run against both backends
+const testBackendEnv = "SPECTRA_TEST_BACKEND"
+
+// testConfig returns a small, fast configuration for a throwaway instance
+func testConfig(t testing.TB) *types.Config {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Seed.MaxDepth = 3
+	cfg.Seed.MinFolders = 2
+	cfg.Seed.MaxFolders = 3
+	cfg.Seed.MinFiles = 2
+	cfg.Seed.MaxFiles = 3
+	cfg.Seed.DBPath = db.MemoryPath
+	if os.Getenv(testBackendEnv) == "file" {
+		cfg.Seed.DBPath = filepath.Join(t.TempDir(), "spectra.db")
+	}
+	return &cfg
+}
+
+// newTestFS opens a throwaway instance, letting configure adjust the test configuration first
+// The instance is closed when the test ends
+func newTestFS(t testing.TB, configure ...func(*types.Config)) *SpectraFS {
+	t.Helper()
+	cfg := testConfig(t)
+	for _, fn := range configure {
+		fn(cfg)
+	}
+	return openTestFS(t, cfg)
+}
+
+// openTestFS opens an instance from cfg and closes it when the test ends
+func openTestFS(t testing.TB, cfg *types.Config) *SpectraFS {
+	t.Helper()
+	s, err := NewSpectraFSFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to open instance: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// onDisk puts the instance's database in a file under t.TempDir(), for tests that reopen it
+func onDisk(t testing.TB) func(*types.Config) {
+	path := filepath.Join(t.TempDir(), "spectra.db")
+	return func(cfg *types.Config) {
+		cfg.Seed.DBPath = path
+	}
+}
+
+// list lists a folder in world, failing the test on any error
+func list(t testing.TB, s *SpectraFS, req *models.ListChildrenRequest) *types.ListResult {
+	t.Helper()
+	result, err := s.ListChildren(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ListChildren(%+v): %v", req, err)
+	}
+	if !result.Success {
+		t.Fatalf("ListChildren(%+v): %s", req, result.Message)
+	}
+	return result
+}
+
+// childNodes flattens a listing into its children in listing order
+func childNodes(result *types.ListResult) []*types.Node {
+	var nodes []*types.Node
+	for i := range result.Folders {
+		nodes = append(nodes, &result.Folders[i].Node)
+	}
+	for i := range result.Files {
+		nodes = append(nodes, &result.Files[i].Node)
+	}
+	for i := range result.Symlinks {
+		nodes = append(nodes, &result.Symlinks[i].Node)
+	}
+	return nodes
+}
+
+// mkdir creates a folder under parentID, failing the test on error
+func mkdir(t testing.TB, s *SpectraFS, parentID, name string) *types.Node {
+	t.Helper()
+	node, err := s.CreateFolder(context.Background(), &models.CreateFolderRequest{ParentID: parentID, Name: name})
+	if err != nil {
+		t.Fatalf("CreateFolder(%s, %s): %v", parentID, name, err)
+	}
+	return node
+}
+
+// upload creates a file under parentID, failing the test on error
+func upload(t testing.TB, s *SpectraFS, parentID, name string, data []byte) *types.Node {
+	t.Helper()
+	node, err := s.UploadFile(context.Background(), &models.UploadFileRequest{ParentID: parentID, Name: name, Data: data})
+	if err != nil {
+		t.Fatalf("UploadFile(%s, %s): %v", parentID, name, err)
+	}
+	return node
+}
+
+// changesSince returns every journal event after sequence number since, oldest first
+func changesSince(t testing.TB, s *SpectraFS, since int64) []types.ChangeEvent {
+	t.Helper()
+	var events []types.ChangeEvent
+	for {
+		feed, err := s.GetChanges(since, MaxChangesLimit)
+		if err != nil {
+			t.Fatalf("GetChanges(%d): %v", since, err)
+		}
+		events = append(events, feed.Events...)
+		since = feed.NextCursor
+		if !feed.HasMore {
+			return events
+		}
+	}
+}
+
+// latestChangeSeq returns the sequence number of the newest journal event (0 if there is none)
+func latestChangeSeq(t testing.TB, s *SpectraFS) int64 {
+	t.Helper()
+	events := changesSince(t, s, 0)
+	if len(events) == 0 {
+		return 0
+	}
+	return events[len(events)-1].Seq
+}
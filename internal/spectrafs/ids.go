@@ -0,0 +1,35 @@
+package spectrafs
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// normalizeNodeID maps legacy world-prefixed IDs onto the canonical scheme (plain "root" and bare UUIDs)
+// "p-root" and "{world}-root" become "root", and "p-{uuid}" and "{world}-{uuid}" become the bare UUID,
+// where "p" stands for primary and {world} is a configured world. The second result is the world the
+// prefix named, or "" when id is already canonical (or not a recognizable legacy form)
+func (s *SpectraFS) normalizeNodeID(id string) (string, string) {
+	prefix, rest, ok := strings.Cut(id, "-")
+	if !ok {
+		return id, ""
+	}
+
+	world := prefix
+	if prefix == "p" {
+		world = "primary"
+	}
+	if !s.isKnownWorld(world) {
+		return id, ""
+	}
+
+	if rest == "root" {
+		return s.root, world
+	}
+	// A bare UUID never matches: the rest of it ("xxxx-xxxx-xxxx-xxxxxxxxxxxx") does not parse
+	if _, err := uuid.Parse(rest); err != nil || len(rest) != 36 {
+		return id, ""
+	}
+	return rest, world
+}
@@ -0,0 +1,65 @@
+package spectrafs
+
+import (
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+)
+
+func TestNormalizeNodeID(t *testing.T) {
+	s := newTestFS(t)
+	const id = "0b4f6a8e-5c1d-4e0a-9f3b-2d7c8e9a1b2c"
+
+	for _, tc := range []struct {
+		in        string
+		wantID    string
+		wantWorld string
+	}{
+		{"root", "root", ""},
+		{"p-root", "root", "primary"},
+		{"s1-root", "root", "s1"},
+		{id, id, ""},
+		{"p-" + id, id, "primary"},
+		{"s1-" + id, id, "s1"},
+		{"s9-" + id, "s9-" + id, ""},   // Unknown world: left as is
+		{"s1-folder", "s1-folder", ""}, // Not a UUID
+		{"s1-" + id[:35], "s1-" + id[:35], ""},
+	} {
+		gotID, gotWorld := s.normalizeNodeID(tc.in)
+		if gotID != tc.wantID || gotWorld != tc.wantWorld {
+			t.Errorf("normalizeNodeID(%q) = %q, %q; want %q, %q", tc.in, gotID, gotWorld, tc.wantID, tc.wantWorld)
+		}
+	}
+}
+
+func TestLegacyIDsResolve(t *testing.T) {
+	s := newTestFS(t)
+	child := childNodes(list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}))[0]
+
+	for _, tc := range []struct {
+		id        string
+		wantID    string
+		wantWorld string
+	}{
+		{"root", "root", "primary"},
+		{"p-root", "root", "primary"},
+		{"s1-root", "root", "s1"},
+		{"p-" + child.ID, child.ID, "primary"},
+		{"s1-" + child.ID, child.ID, "s1"},
+	} {
+		node, world, err := s.resolveNodeAndWorld(&models.GetNodeRequest{ID: tc.id})
+		if err != nil {
+			t.Errorf("resolve %s: %v", tc.id, err)
+			continue
+		}
+		if node.ID != tc.wantID || world != tc.wantWorld {
+			t.Errorf("resolve %s = %s in %s, want %s in %s", tc.id, node.ID, world, tc.wantID, tc.wantWorld)
+		}
+	}
+
+	// ListChildren accepts the legacy root ID too
+	byLegacy := childNodes(list(t, s, &models.ListChildrenRequest{ParentID: "p-root"}))
+	if len(byLegacy) == 0 || byLegacy[0].ID != child.ID {
+		t.Errorf("listing p-root returned %d children, starting %v", len(byLegacy), byLegacy)
+	}
+}
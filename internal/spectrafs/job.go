@@ -0,0 +1,29 @@
+package spectrafs
+
+import (
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// ErrJobNotFound is returned by GetJob for an ID with no record
+var ErrJobNotFound = db.ErrJobNotFound
+
+// SaveJob records the state of a job run by the API's job manager, replacing its earlier record;
+// only the latest db.MaxJobRecords finished jobs are kept. Returns ErrReadOnly for a read-only
+// instance, whose jobs are not recorded
+func (s *SpectraFS) SaveJob(job *types.Job) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	return s.db.SaveJob(job)
+}
+
+// GetJob returns the recorded state of a job
+func (s *SpectraFS) GetJob(id string) (*types.Job, error) {
+	return s.db.GetJob(id)
+}
+
+// ListJobs returns the recorded jobs, newest first
+func (s *SpectraFS) ListJobs() ([]*types.Job, error) {
+	return s.db.ListJobs()
+}
@@ -0,0 +1,47 @@
+package spectrafs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestUnfinishedJobsFailOnReopen(t *testing.T) {
+	dir := onDisk(t)
+	s := newTestFS(t, dir)
+	now := time.Now().UTC()
+	jobs := []*types.Job{
+		{ID: "queued", Kind: types.JobGenerate, Status: types.JobQueued, CreatedAt: now},
+		{ID: "running", Kind: types.JobGenerate, Status: types.JobRunning, CreatedAt: now.Add(time.Second), StartedAt: &now},
+		{ID: "done", Kind: types.JobGenerate, Status: types.JobDone, CreatedAt: now.Add(2 * time.Second), FinishedAt: &now},
+	}
+	for _, job := range jobs {
+		if err := s.SaveJob(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := newTestFS(t, dir)
+	for id, want := range map[string]string{"queued": types.JobFailed, "running": types.JobFailed, "done": types.JobDone} {
+		job, err := reopened.GetJob(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status != want || (want == types.JobFailed && (job.Error == "" || job.FinishedAt == nil)) {
+			t.Errorf("job %s after reopen = %+v, want %s", id, job, want)
+		}
+	}
+
+	listed, err := reopened.ListJobs()
+	if err != nil || len(listed) != 3 || listed[0].ID != "done" {
+		t.Errorf("ListJobs() = %d jobs starting with %v, %v, want newest first", len(listed), listed[0], err)
+	}
+	if _, err := reopened.GetJob("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("GetJob(missing) = %v, want ErrJobNotFound", err)
+	}
+}
@@ -0,0 +1,137 @@
+package spectrafs
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// DefaultJournalMaxEntries is the change journal length kept when db.journal_max_entries is unset
+const DefaultJournalMaxEntries = 10000
+
+// DefaultChangesLimit is the page size of GetChanges when limit is 0
+const DefaultChangesLimit = 100
+
+// MaxChangesLimit is the largest page GetChanges returns
+const MaxChangesLimit = 1000
+
+// ErrInvalidChanges is returned by GetChanges for a negative cursor or an out-of-range limit
+var ErrInvalidChanges = newError(ErrInvalidInput, "invalid changes request")
+
+// GetChanges returns up to limit journal events after sequence number sinceSeq, oldest first,
+// with the cursor to pass next time (limit 0 = DefaultChangesLimit, at most MaxChangesLimit)
+// Truncated is set when events after sinceSeq were pruned, or sinceSeq is ahead of the journal;
+// consumers should then rescan. A reset event means the same
+func (s *SpectraFS) GetChanges(sinceSeq int64, limit int) (*types.ChangeFeed, error) {
+	if sinceSeq < 0 {
+		return nil, fmt.Errorf("%w: since must be non-negative", ErrInvalidChanges)
+	}
+	if limit < 0 || limit > MaxChangesLimit {
+		return nil, fmt.Errorf("%w: limit must be between 0 and %d", ErrInvalidChanges, MaxChangesLimit)
+	}
+	if limit == 0 {
+		limit = DefaultChangesLimit
+	}
+
+	events, oldest, latest, err := s.db.ListChanges(sinceSeq, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &types.ChangeFeed{
+		Events:     events,
+		NextCursor: sinceSeq,
+		Truncated:  sinceSeq > latest || (latest > sinceSeq && (oldest == 0 || oldest > sinceSeq+1)),
+	}
+	if len(events) > limit {
+		feed.Events = events[:limit]
+		feed.HasMore = true
+	}
+	if n := len(feed.Events); n > 0 {
+		feed.NextCursor = feed.Events[n-1].Seq
+	}
+	return feed, nil
+}
+
+// change is a journal entry together with the node snapshot published to live subscribers
+type change struct {
+	types.ChangeEvent
+	node *types.Node // nil for world-wide changes and resets
+}
+
+// journal appends changes to the change journal, stamped with the clock, pruning it to the configured
+// length, and publishes them to subscribers. The hub lock is held across both, so subscribers see
+// changes in journal order
+func (s *SpectraFS) journal(changes ...change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	now := s.now()
+	events := make([]types.ChangeEvent, len(changes))
+	for i := range changes {
+		events[i] = changes[i].ChangeEvent
+		events[i].At = now
+	}
+
+	s.events.mu.Lock()
+	defer s.events.mu.Unlock()
+	stored, err := s.db.AppendChanges(events, s.journalMaxEntries())
+	if err != nil {
+		return fmt.Errorf("failed to journal changes: %w", err)
+	}
+	for i := range stored {
+		s.events.publishLocked(liveEvent(stored[i], changes[i].node))
+	}
+	return nil
+}
+
+// resetJournal empties the change journal, leaving a single reset event, and publishes it
+func (s *SpectraFS) resetJournal() error {
+	s.events.mu.Lock()
+	defer s.events.mu.Unlock()
+	event, err := s.db.TruncateChanges(types.ChangeEvent{Op: types.ChangeReset, NodeID: "root", Path: "/", At: s.now()})
+	if err != nil {
+		return fmt.Errorf("failed to journal changes: %w", err)
+	}
+	s.events.publishLocked(liveEvent(event, nil))
+	return nil
+}
+
+// journalMaxEntries returns the configured journal length
+func (s *SpectraFS) journalMaxEntries() int64 {
+	if s.config().DB.JournalMaxEntries > 0 {
+		return s.config().DB.JournalMaxEntries
+	}
+	return DefaultJournalMaxEntries
+}
+
+// nodeChange describes op on node, which (with its descendants) covers nodes nodes
+// The node's worlds are those it exists in; the caller overrides them for existence changes
+func nodeChange(op string, node *types.Node, nodes int) change {
+	return change{
+		ChangeEvent: types.ChangeEvent{
+			Op:           op,
+			NodeID:       node.ID,
+			Path:         node.Path,
+			NodeType:     node.Type,
+			Worlds:       existingWorlds(node.ExistenceMap),
+			ExistenceMap: maps.Clone(node.ExistenceMap),
+			Nodes:        nodes,
+		},
+		node: node,
+	}
+}
+
+// existingWorlds returns the worlds marked present in existenceMap, sorted
+func existingWorlds(existenceMap map[string]bool) []string {
+	worlds := make([]string, 0, len(existenceMap))
+	for world, exists := range existenceMap {
+		if exists {
+			worlds = append(worlds, world)
+		}
+	}
+	sort.Strings(worlds)
+	return worlds
+}
@@ -0,0 +1,109 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestGetChangesPagesByCursor(t *testing.T) {
+	s := newTestFS(t, func(cfg *types.Config) { cfg.SecondaryTables = map[string]float64{"s1": 1} })
+
+	a := mkdir(t, s, s.root, "a")
+	upload(t, s, a.ID, "x.txt", []byte("x"))
+	if _, err := s.SetWorldExistence(a.ID, "s1", false, true); err != nil {
+		t.Fatal(err)
+	}
+
+	var ops []string
+	var since int64
+	for {
+		feed, err := s.GetChanges(since, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if feed.Truncated {
+			t.Fatalf("feed after %d is truncated", since)
+		}
+		if len(feed.Events) == 0 {
+			if feed.HasMore || feed.NextCursor != since {
+				t.Fatalf("empty page after %d: %+v", since, feed)
+			}
+			break
+		}
+		event := feed.Events[0]
+		if event.Seq != since+1 || feed.NextCursor != event.Seq {
+			t.Fatalf("page after %d holds seq %d with cursor %d", since, event.Seq, feed.NextCursor)
+		}
+		ops = append(ops, event.Op+" "+event.Path)
+		since = feed.NextCursor
+	}
+	want := []string{"create /a", "create /a/x.txt", "existence /a"}
+	if !slices.Equal(ops, want) {
+		t.Errorf("journal %v, want %v", ops, want)
+	}
+
+	last := changesSince(t, s, 0)[2]
+	if !slices.Equal(last.Worlds, []string{"s1"}) || last.ExistenceMap["s1"] || last.Nodes != 2 {
+		t.Errorf("existence event = %+v, want s1 removed from 2 nodes", last)
+	}
+
+	for _, bad := range [][2]int{{-1, 0}, {0, -1}, {0, MaxChangesLimit + 1}} {
+		if _, err := s.GetChanges(int64(bad[0]), bad[1]); !errors.Is(err, ErrInvalidChanges) || !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("GetChanges(%d, %d) = %v, want ErrInvalidChanges", bad[0], bad[1], err)
+		}
+	}
+}
+
+func TestJournalPrunesOldestEntries(t *testing.T) {
+	s := newTestFS(t, func(cfg *types.Config) { cfg.DB.JournalMaxEntries = 3 })
+
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		mkdir(t, s, s.root, name)
+	}
+
+	feed, err := s.GetChanges(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, event := range feed.Events {
+		paths = append(paths, event.Path)
+	}
+	if !slices.Equal(paths, []string{"/c", "/d", "/e"}) || !feed.Truncated {
+		t.Errorf("pruned journal holds %v (truncated %t), want the newest 3 and a truncated feed", paths, feed.Truncated)
+	}
+	if feed, err := s.GetChanges(2, 0); err != nil || feed.Truncated || len(feed.Events) != 3 {
+		t.Errorf("reading on from the oldest kept event's predecessor = %+v, %v", feed, err)
+	}
+	if feed, err := s.GetChanges(6, 0); err != nil || !feed.Truncated {
+		t.Errorf("cursor ahead of the journal = %+v, %v, want truncated", feed, err)
+	}
+}
+
+func TestResetTruncatesJournal(t *testing.T) {
+	s := newTestFS(t)
+	mkdir(t, s, s.root, "a")
+	mkdir(t, s, s.root, "b")
+	before := latestChangeSeq(t, s)
+
+	if err := s.Reset(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	events := changesSince(t, s, 0)
+	if len(events) != 1 || events[0].Op != types.ChangeReset || events[0].Seq != before+1 {
+		t.Fatalf("journal after reset = %+v, want a single reset event at seq %d", events, before+1)
+	}
+	// A consumer reading on from before the reset sees the reset event and knows to rescan
+	feed, err := s.GetChanges(before, 0)
+	if err != nil || len(feed.Events) != 1 || feed.Events[0].Op != types.ChangeReset {
+		t.Errorf("GetChanges(%d) after reset = %+v, %v", before, feed, err)
+	}
+	if feed, err := s.GetChanges(0, 0); err != nil || !feed.Truncated {
+		t.Errorf("reading from the start after reset = %+v, %v, want truncated", feed, err)
+	}
+}
@@ -0,0 +1,202 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/metrics"
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// BenchmarkParallelReads runs the read paths of a pre-generated tree from concurrent readers
+// Reads take no instance or database lock, so on a multi-core machine ns/op falls as -cpu grows:
+// go test ./internal/spectrafs -run '^$' -bench ParallelReads -cpu 1,2,4,8
+func BenchmarkParallelReads(b *testing.B) {
+	s := newTestFS(b, onDisk(b), func(cfg *types.Config) {
+		cfg.Seed.MaxDepth = 4
+		cfg.DB.NoSync = true
+	})
+	ctx := context.Background()
+	if _, err := s.GenerateAll(ctx); err != nil {
+		b.Fatal(err)
+	}
+
+	// Collected after generation, so the benchmark measures reads rather than lazy generation
+	folders := []string{s.root}
+	err := s.WalkFunc(ctx, &models.WalkRequest{ParentID: s.root, TableName: "primary"}, func(node *types.Node) error {
+		if node.Type == types.NodeTypeFolder {
+			folders = append(folders, node.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	reads := []struct {
+		name string
+		read func(parentID string) error
+	}{
+		{"ListChildren", func(parentID string) error {
+			result, err := s.ListChildren(ctx, &models.ListChildrenRequest{ParentID: parentID, TableName: "primary"})
+			if err == nil && !result.Success {
+				err = errors.New(result.Message)
+			}
+			return err
+		}},
+		{"GetMeta", func(string) error {
+			_, err := s.db.GetMeta(metaTaskPrefix + "compact")
+			return err
+		}},
+		{"GetStats", func(string) error {
+			_, err := s.db.GetStats()
+			return err
+		}},
+		{"ChildrenGenerated", func(parentID string) error {
+			_, err := s.db.ChildrenGenerated(parentID)
+			return err
+		}},
+		{"CreateFolder", func(parentID string) error {
+			_, err := s.db.CreateFolder(parentID, "new", 1)
+			return err
+		}},
+		{"GetNodesInWorld", func(string) error {
+			_, err := s.db.GetNodesInWorld("primary")
+			return err
+		}},
+	}
+	for _, r := range reads {
+		b.Run(r.name, func(b *testing.B) {
+			var next atomic.Int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					parentID := folders[next.Add(1)%int64(len(folders))]
+					if err := r.read(parentID); err != nil {
+						b.Errorf("%s(%s): %v", r.name, parentID, err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestConcurrentListingGeneratesOnce(t *testing.T) {
+	// Random IDs make a second generation of the folder visible as a second set of children
+	random := func(cfg *types.Config) { cfg.Seed.RandomIDs = true }
+	s := newTestFS(t, random)
+	folder := list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}).Folders[0]
+	reference := newTestFS(t, random)
+	list(t, reference, &models.ListChildrenRequest{ParentID: reference.root, TableName: "primary"})
+	want := len(childNodes(list(t, reference, &models.ListChildrenRequest{ParentPath: folder.Path, TableName: "primary"})))
+
+	const listers = 50
+	var (
+		wg      sync.WaitGroup
+		start   = make(chan struct{})
+		results = make([][]string, listers)
+	)
+	for i := range listers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			result, err := s.ListChildren(context.Background(), &models.ListChildrenRequest{ParentID: folder.ID, TableName: "primary"})
+			if err != nil || !result.Success {
+				t.Errorf("lister %d: %v %+v", i, err, result)
+				return
+			}
+			for _, child := range childNodes(result) {
+				results[i] = append(results[i], child.ID)
+			}
+			slices.Sort(results[i])
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	stored := storedChildren(t, s, folder.ID)
+	if len(stored) != want {
+		t.Fatalf("%d children stored after %d concurrent listings, one generation makes %d", len(stored), listers, want)
+	}
+	for i, ids := range results {
+		if !slices.Equal(ids, stored) {
+			t.Errorf("lister %d saw %d children, not the %d stored", i, len(ids), len(stored))
+		}
+	}
+	stats, err := s.db.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total := len(childNodes(list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}))) + want; stats.TotalNodes != int64(total) {
+		t.Errorf("stats count %d nodes, the root and folder hold %d", stats.TotalNodes, total)
+	}
+}
+
+// txCounter counts BoltDB transactions by op ("view" or "update")
+type txCounter struct {
+	mu  sync.Mutex
+	ops map[string]int
+}
+
+// Add ignores counters
+func (c *txCounter) Add(string, float64, ...metrics.Label) {}
+
+// Observe counts a transaction by its op label
+func (c *txCounter) Observe(name string, _ float64, labels ...metrics.Label) {
+	if name != metrics.BoltTxDuration {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, label := range labels {
+		c.ops[label.Value]++
+	}
+}
+
+// take returns the counts since the last call and starts over
+func (c *txCounter) take() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ops := c.ops
+	c.ops = make(map[string]int)
+	return ops
+}
+
+func TestListingReturnsParentInOneSnapshot(t *testing.T) {
+	s := newTestFS(t)
+	counter := &txCounter{ops: make(map[string]int)}
+	s.SetMetrics(counter)
+	folder := list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}).Folders[0]
+	counter.take()
+
+	first := list(t, s, &models.ListChildrenRequest{ParentID: folder.ID, TableName: "primary"})
+	if ops := counter.take(); ops["view"] != 1 || ops["update"] != 1 {
+		t.Errorf("generating listing ran %v transactions, want one view and one update", ops)
+	}
+	second := list(t, s, &models.ListChildrenRequest{ParentID: folder.ID, TableName: "primary"})
+	if ops := counter.take(); ops["view"] != 1 || ops["update"] != 0 {
+		t.Errorf("stored listing ran %v transactions, want one view", ops)
+	}
+
+	if !first.Generated || second.Generated {
+		t.Errorf("generated flags %v then %v, want true then false", first.Generated, second.Generated)
+	}
+	for _, result := range []*types.ListResult{first, second} {
+		if result.Parent == nil || result.Parent.ID != folder.ID || result.Parent.Path != folder.Path || result.Parent.DepthLevel != 1 {
+			t.Errorf("listing parent %+v, want %s at depth 1", result.Parent, folder.Path)
+		}
+	}
+	if a, b := childNodes(first), childNodes(second); len(a) == 0 || len(a) != len(b) {
+		t.Errorf("generating listing returned %d children, stored listing %d", len(a), len(b))
+	}
+	if list(t, s, &models.ListChildrenRequest{ParentPath: folder.Path, TableName: "primary"}).Parent.ID != folder.ID {
+		t.Error("listing by path returned another parent")
+	}
+}
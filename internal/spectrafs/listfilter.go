@@ -0,0 +1,132 @@
+package spectrafs
+
+import (
+	"cmp"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// listOptions holds the filtering and sorting options of a ListChildren request
+// A nil *listOptions keeps every child in the default db.CompareNodes order
+type listOptions struct {
+	typeFilter   string
+	nameContains string
+	nameGlob     string
+	minSize      int64
+	maxSize      int64
+	sortBy       string // Empty for the default name order
+	desc         bool
+}
+
+// listOptionsOf validates and returns the listing options of req, or nil if it sets none
+func listOptionsOf(req models.ParentIdentifier) (*listOptions, error) {
+	filter, ok := req.(models.ListFilterRequest)
+	if !ok {
+		return nil, nil
+	}
+	if err := validateRequest(models.ValidateListFilter(filter)); err != nil {
+		return nil, err
+	}
+
+	opts := &listOptions{
+		typeFilter:   filter.GetTypeFilter(),
+		nameContains: filter.GetNameContains(),
+		nameGlob:     filter.GetNameGlob(),
+		minSize:      filter.GetMinSize(),
+		maxSize:      filter.GetMaxSize(),
+		sortBy:       filter.GetSortBy(),
+		desc:         filter.GetSortOrder() == models.SortOrderDesc,
+	}
+	if opts.sortBy == models.SortByName && !opts.desc {
+		opts.sortBy = "" // The default order
+	}
+	if !opts.filtering() && !opts.sorted() {
+		return nil, nil
+	}
+	return opts, nil
+}
+
+// filtering reports whether the options drop any children
+func (o *listOptions) filtering() bool {
+	return o != nil && (o.typeFilter != "" || o.nameContains != "" || o.nameGlob != "" || o.minSize > 0 || o.maxSize > 0)
+}
+
+// sorted reports whether the options change the order of the children
+func (o *listOptions) sorted() bool {
+	return o != nil && (o.sortBy != "" || o.desc)
+}
+
+// match reports whether node passes the filters
+func (o *listOptions) match(node *types.Node) bool {
+	if !o.filtering() {
+		return true
+	}
+	if o.typeFilter != "" && node.Type != o.typeFilter {
+		return false
+	}
+	if o.nameContains != "" && !strings.Contains(node.Name, o.nameContains) {
+		return false
+	}
+	if o.nameGlob != "" {
+		if ok, _ := path.Match(o.nameGlob, node.Name); !ok {
+			return false
+		}
+	}
+	return node.Size >= o.minSize && (o.maxSize == 0 || node.Size <= o.maxSize)
+}
+
+// sortKey names the order for pagination cursors; empty for the default order
+func (o *listOptions) sortKey() string {
+	if !o.sorted() {
+		return ""
+	}
+	key := o.sortBy
+	if key == "" {
+		key = models.SortByName
+	}
+	if o.desc {
+		return key + ":" + models.SortOrderDesc
+	}
+	return key + ":" + models.SortOrderAsc
+}
+
+// compare orders two nodes: by type, then by the sort key, then by name and ID, with everything
+// after the type reversed for a descending sort
+func (o *listOptions) compare(a, b *types.Node) int {
+	if !o.sorted() {
+		return db.CompareNodes(a, b)
+	}
+	if c := db.CompareNodeTypes(a.Type, b.Type); c != 0 {
+		return c
+	}
+
+	var c int
+	switch o.sortBy {
+	case models.SortBySize:
+		c = cmp.Compare(a.Size, b.Size)
+	case models.SortByMTime:
+		c = a.LastUpdated.Compare(b.LastUpdated)
+	}
+	if c == 0 {
+		c = db.CompareNodeKeys("", a.Name, a.ID, "", b.Name, b.ID)
+	}
+	if o.desc {
+		return -c
+	}
+	return c
+}
+
+// sort reorders children in place; a no-op for the default order, which the db already returns
+func (o *listOptions) sort(children []*types.Node) {
+	if !o.sorted() {
+		return
+	}
+	sort.Slice(children, func(i, j int) bool {
+		return o.compare(children[i], children[j]) < 0
+	})
+}
@@ -0,0 +1,183 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// listingFixture creates a folder holding two folders and three files
+func listingFixture(t *testing.T, s *SpectraFS) string {
+	t.Helper()
+	folder := mkdir(t, s, "root", "listing")
+	mkdir(t, s, folder.ID, "beta")
+	mkdir(t, s, folder.ID, "alpha")
+	upload(t, s, folder.ID, "c.txt", []byte("x"))
+	upload(t, s, folder.ID, "a.txt", []byte("x"))
+	upload(t, s, folder.ID, "b.log", []byte("x"))
+	return folder.ID
+}
+
+// sizedFS opens an instance whose generated files vary in size
+func sizedFS(t *testing.T) *SpectraFS {
+	return newTestFS(t, func(cfg *types.Config) {
+		cfg.Seed.MinFiles = 6
+		cfg.Seed.MaxFiles = 8
+		cfg.Seed.MinFileSize = 1
+		cfg.Seed.MaxFileSize = 4096
+	})
+}
+
+// filesOf returns the generated root's files in the default order, failing the test if their sizes
+// do not vary enough to tell the size filters and sorts apart
+func filesOf(t *testing.T, s *SpectraFS) []*types.Node {
+	t.Helper()
+	result := list(t, s, &models.ListChildrenRequest{ParentID: s.root, TypeFilter: "file"})
+	var files []*types.Node
+	sizes := make(map[int64]bool)
+	for i := range result.Files {
+		files = append(files, &result.Files[i].Node)
+		sizes[result.Files[i].Size] = true
+	}
+	if len(sizes) < 2 {
+		t.Fatalf("the generated root's %d files all have the same size", len(files))
+	}
+	return files
+}
+
+// listNames lists a folder and returns the children's names in listing order with the filtered count
+func listNames(t *testing.T, s *SpectraFS, req *models.ListChildrenRequest) (string, int) {
+	t.Helper()
+	result := list(t, s, req)
+	return names(childNodes(result)), result.Filtered
+}
+
+func TestListChildrenFilters(t *testing.T) {
+	s := newTestFS(t)
+	parentID := listingFixture(t, s)
+
+	tests := []struct {
+		name     string
+		req      models.ListChildrenRequest
+		want     string
+		filtered int
+	}{
+		{"none", models.ListChildrenRequest{}, "alpha,beta,a.txt,b.log,c.txt", 0},
+		{"folders", models.ListChildrenRequest{TypeFilter: "folder"}, "alpha,beta", 3},
+		{"files", models.ListChildrenRequest{TypeFilter: "file"}, "a.txt,b.log,c.txt", 2},
+		{"contains", models.ListChildrenRequest{NameContains: "a"}, "alpha,beta,a.txt", 2},
+		{"glob", models.ListChildrenRequest{NameGlob: "*.txt"}, "a.txt,c.txt", 3},
+		{"combined", models.ListChildrenRequest{TypeFilter: "file", NameContains: "a", NameGlob: "*.txt"}, "a.txt", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.ParentID = parentID
+			got, filtered := listNames(t, s, &req)
+			if got != tt.want || filtered != tt.filtered {
+				t.Errorf("listed %s (%d filtered out), want %s (%d filtered out)", got, filtered, tt.want, tt.filtered)
+			}
+		})
+	}
+
+	result := list(t, s, &models.ListChildrenRequest{ParentID: parentID, TypeFilter: "folder"})
+	if !strings.Contains(result.Message, "3 filtered out") {
+		t.Errorf("filtered listing message %q does not count the children left out", result.Message)
+	}
+}
+
+func TestListChildrenSizeFilters(t *testing.T) {
+	s := sizedFS(t)
+	files := filesOf(t, s)
+	minSize, maxSize := files[0].Size, files[0].Size
+	for _, file := range files {
+		minSize, maxSize = min(minSize, file.Size), max(maxSize, file.Size)
+	}
+
+	// Bounds at the extremes keep everything; just inside them drop the smallest or the largest files
+	for _, bounds := range [][2]int64{{minSize, maxSize}, {minSize + 1, 0}, {0, maxSize - 1}, {minSize + 1, maxSize - 1}} {
+		var want []*types.Node
+		for _, file := range files {
+			if file.Size >= bounds[0] && (bounds[1] == 0 || file.Size <= bounds[1]) {
+				want = append(want, file)
+			}
+		}
+		result := list(t, s, &models.ListChildrenRequest{ParentID: s.root, TypeFilter: "file", MinSize: bounds[0], MaxSize: bounds[1]})
+		if got := names(childNodes(result)); got != names(want) {
+			t.Errorf("sizes %d..%d listed %s, want %s", bounds[0], bounds[1], got, names(want))
+		}
+	}
+}
+
+func TestListChildrenSorts(t *testing.T) {
+	s := sizedFS(t)
+	files := filesOf(t, s) // Before the fixture, so the root is generated
+	parentID := listingFixture(t, s)
+
+	for _, tt := range []struct {
+		order, want string
+	}{
+		{"", "alpha,beta,a.txt,b.log,c.txt"},
+		{models.SortOrderDesc, "beta,alpha,c.txt,b.log,a.txt"},
+	} {
+		got, _ := listNames(t, s, &models.ListChildrenRequest{ParentID: parentID, SortBy: models.SortByName, SortOrder: tt.order})
+		if got != tt.want {
+			t.Errorf("name order %q listed %s, want %s", tt.order, got, tt.want)
+		}
+	}
+
+	// Files sorted by size or mtime come back ordered by that key, folders still first
+	for _, sortBy := range []string{models.SortBySize, models.SortByMTime} {
+		for _, order := range []string{models.SortOrderAsc, models.SortOrderDesc} {
+			result := list(t, s, &models.ListChildrenRequest{ParentID: s.root, SortBy: sortBy, SortOrder: order})
+			if len(result.Folders) < 2 || len(result.Files) != len(files) {
+				t.Fatalf("%s %s listed %d folders and %d files", sortBy, order, len(result.Folders), len(result.Files))
+			}
+			for i := 1; i < len(result.Files); i++ {
+				prev, next := &result.Files[i-1].Node, &result.Files[i].Node
+				c := next.LastUpdated.Compare(prev.LastUpdated)
+				if sortBy == models.SortBySize {
+					c = int(next.Size - prev.Size)
+				}
+				if (order == models.SortOrderAsc && c < 0) || (order == models.SortOrderDesc && c > 0) {
+					t.Errorf("%s %s: %s listed after %s", sortBy, order, next.Name, prev.Name)
+				}
+			}
+		}
+	}
+
+	// Pages of a sorted listing follow the sort, not the default order
+	whole := list(t, s, &models.ListChildrenRequest{ParentID: s.root, SortBy: models.SortBySize, SortOrder: models.SortOrderDesc})
+	var paged []*types.Node
+	cursor := ""
+	for {
+		result := list(t, s, &models.ListChildrenRequest{ParentID: s.root, SortBy: models.SortBySize, SortOrder: models.SortOrderDesc, Limit: 2, StartingAfter: cursor})
+		paged = append(paged, childNodes(result)...)
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+	if got, want := names(paged), names(childNodes(whole)); got != want {
+		t.Errorf("paged size-descending listing = %s, want %s", got, want)
+	}
+}
+
+func TestListChildrenRejectsInvalidOptions(t *testing.T) {
+	s := newTestFS(t)
+	for _, req := range []*models.ListChildrenRequest{
+		{ParentID: "root", SortBy: "owner"},
+		{ParentID: "root", SortOrder: "sideways"},
+		{ParentID: "root", TypeFilter: "device"},
+		{ParentID: "root", NameGlob: "["},
+		{ParentID: "root", MinSize: 10, MaxSize: 5},
+	} {
+		if _, err := s.ListChildren(context.Background(), req); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("ListChildren(%+v) = %v, want ErrInvalidInput", req, err)
+		}
+	}
+}
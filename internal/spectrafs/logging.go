@@ -0,0 +1,24 @@
+package spectrafs
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Project-Sylos/Spectra/internal/logging"
+)
+
+// SetLogger sets the logger of generation and BoltDB transaction details, logged at debug level
+// (nil stops logging). Requests whose context carries a logger (see logging.WithLogger) log through
+// that one instead. Like SetClock, call it before the instance is shared
+func (s *SpectraFS) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = logging.Discard
+	}
+	s.logger = logger
+	s.db.SetLogger(logger)
+}
+
+// log returns the logger of ctx's request, or the instance's
+func (s *SpectraFS) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, s.logger)
+}
@@ -0,0 +1,98 @@
+package spectrafs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/logging"
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+)
+
+// logRecords decodes JSON log output into one map per record
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestDebugLogsGenerationAndTransactions(t *testing.T) {
+	s := newTestFS(t)
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.LevelDebug, logging.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetLogger(logger)
+
+	result := list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"})
+	var generated, transactions int
+	for _, record := range logRecords(t, &buf) {
+		switch record["msg"] {
+		case "generated children":
+			generated++
+			if record["parent"] != "/" || record["world"] != "primary" || int(record["count"].(float64)) != len(childNodes(result)) {
+				t.Errorf("generation record %v, want root's %d children in primary", record, len(childNodes(result)))
+			}
+			if _, ok := record["duration"]; !ok {
+				t.Errorf("generation record %v has no duration", record)
+			}
+		case "bolt transaction":
+			transactions++
+		}
+		if record["level"] != "DEBUG" {
+			t.Errorf("record %v is not at debug level", record)
+		}
+	}
+	if generated != 1 || transactions == 0 {
+		t.Errorf("listing the root logged %d generations and %d transactions", generated, transactions)
+	}
+}
+
+func TestLoggingQuietByDefault(t *testing.T) {
+	s := newTestFS(t)
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.LevelInfo, logging.FormatText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetLogger(logger)
+	list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"})
+	if buf.Len() != 0 {
+		t.Errorf("an info-level logger received %q", buf.String())
+	}
+
+	// A request's logger takes over from the instance's
+	var request bytes.Buffer
+	requestLogger, _ := logging.New(&request, logging.LevelDebug, logging.FormatJSON)
+	ctx := logging.WithLogger(context.Background(), requestLogger.With("request_id", "r-1"))
+	folder := list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}).Folders[0]
+	if _, err := s.ListChildren(ctx, &models.ListChildrenRequest{ParentID: folder.ID, TableName: "primary"}); err != nil {
+		t.Fatal(err)
+	}
+	records := logRecords(t, &request)
+	if len(records) == 0 || records[0]["request_id"] != "r-1" {
+		t.Errorf("request logger received %v, want generation records tagged with its request ID", records)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("the instance logger received %q during a request with its own logger", buf.String())
+	}
+
+	for _, bad := range [][2]string{{"loud", ""}, {"", "xml"}} {
+		if _, err := logging.New(&buf, bad[0], bad[1]); err == nil {
+			t.Errorf("logging.New(level %q, format %q) succeeded", bad[0], bad[1])
+		}
+	}
+}
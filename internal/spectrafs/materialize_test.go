@@ -0,0 +1,104 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// deepestFile returns a file at seed.max_depth in the fully generated tree of the test configuration
+func deepestFile(t *testing.T, reference *SpectraFS) *types.Node {
+	t.Helper()
+	maxDepth := reference.config().Seed.MaxDepth
+	for _, node := range storedNodes(t, reference) {
+		if node.Type == types.NodeTypeFile && node.DepthLevel == maxDepth && node.ExistenceMap["primary"] {
+			return node
+		}
+	}
+	t.Fatalf("no primary file at depth %d", maxDepth)
+	return nil
+}
+
+func TestMaterializeResolvesDepth3PathOnColdDatabase(t *testing.T) {
+	reference := generatedFS(t)
+	want := deepestFile(t, reference)
+	total := len(storedNodes(t, reference))
+
+	s := newTestFS(t)
+	ctx := context.Background()
+	if _, err := s.GetNode(ctx, &models.GetNodeRequest{Path: want.Path, TableName: "primary"}); !errors.Is(err, ErrNodeNotFound) {
+		t.Fatalf("plain lookup of %s on a cold database = %v, want ErrNodeNotFound", want.Path, err)
+	}
+
+	node, err := s.GetNode(ctx, &models.GetNodeRequest{Path: want.Path, TableName: "primary", Materialize: true})
+	if err != nil {
+		t.Fatalf("materializing %s: %v", want.Path, err)
+	}
+	if node.ID != want.ID || node.Size != want.Size {
+		t.Errorf("materialized %+v, want %+v", node, want)
+	}
+
+	// Only the folders on the way were listed, one listing per level
+	if stored := len(storedNodes(t, s)); stored >= total {
+		t.Errorf("materializing one path stored %d nodes, the whole tree has %d", stored, total)
+	}
+	for depth, parent := 0, s.root; depth < want.DepthLevel; depth++ {
+		children := storedChildren(t, s, parent)
+		if len(children) == 0 {
+			t.Fatalf("ancestor at depth %d of %s was not generated", depth, want.Path)
+		}
+		next, err := s.db.GetNodeByPath(strings.Join(strings.Split(want.Path, "/")[:depth+2], "/"), "primary")
+		if err != nil {
+			t.Fatal(err)
+		}
+		parent = next.ID
+	}
+
+	// ListChildren materializes ParentPath the same way
+	fresh := newTestFS(t)
+	result := list(t, fresh, &models.ListChildrenRequest{ParentPath: want.ParentPath, TableName: "primary", Materialize: true})
+	found := false
+	for _, child := range childNodes(result) {
+		found = found || child.ID == want.ID
+	}
+	if !found {
+		t.Errorf("listing %s with materialize did not return %s", want.ParentPath, want.Path)
+	}
+}
+
+func TestMaterializeRespectsLimits(t *testing.T) {
+	reference := generatedFS(t)
+	deep := deepestFile(t, reference)
+	ctx := context.Background()
+
+	// Nothing is generated below seed.max_depth
+	s := newTestFS(t)
+	beyond := deep.ParentPath + "/folder_1/file_1.txt"
+	if _, err := s.GetNode(ctx, &models.GetNodeRequest{Path: beyond, TableName: "primary", Materialize: true}); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("materializing %s below max_depth = %v, want ErrNodeNotFound", beyond, err)
+	}
+
+	// A budget that only covers the root's children stops the walk
+	budgeted := newTestFS(t, func(cfg *types.Config) { cfg.Seed.MaxTotalNodes = 3 })
+	if _, err := budgeted.GetNode(ctx, &models.GetNodeRequest{Path: deep.Path, TableName: "primary", Materialize: true}); err == nil {
+		t.Errorf("materializing %s under a 3-node budget succeeded", deep.Path)
+	}
+	checkBudget(t, budgeted)
+
+	// A read-only instance generates nothing
+	dir := onDisk(t)
+	if err := newTestFS(t, dir).Close(); err != nil {
+		t.Fatal(err)
+	}
+	readOnly := newTestFS(t, dir, func(cfg *types.Config) { cfg.DB.ReadOnly = true })
+	if _, err := readOnly.GetNode(ctx, &models.GetNodeRequest{Path: deep.Path, TableName: "primary", Materialize: true}); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("materializing on a read-only instance = %v, want ErrNodeNotFound", err)
+	}
+	if stored := len(storedNodes(t, readOnly)); stored != 1 {
+		t.Errorf("read-only instance holds %d nodes after materializing, want only the root", stored)
+	}
+}
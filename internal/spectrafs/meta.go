@@ -0,0 +1,208 @@
+package spectrafs
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// MetaDirName is the top-level directory of the virtual metadata subtree
+// "<MetaDirName>/<path>.json" holds the JSON-serialized Node for "<path>";
+// the root node's document is "<MetaDirName>/.json"
+const MetaDirName = ".spectra-meta"
+
+// metaFileSuffix is appended to a node's path to form its metadata document name
+const metaFileSuffix = ".json"
+
+// MetaOptions configures the virtual metadata subtree of an fs.FS wrapper
+type MetaOptions struct {
+	ListMeta bool // Show MetaDirName in the root directory listing (it is always openable by name)
+}
+
+// NewSpectraFSWrapperWithMeta creates an fs.FS wrapper that also serves per-node metadata documents
+func NewSpectraFSWrapperWithMeta(fs *SpectraFS, world string, opts MetaOptions) *SpectraFSWrapper {
+	wrapper := NewSpectraFSWrapper(fs, world)
+	wrapper.meta = &opts
+	return wrapper
+}
+
+// metaEntry is a resolved file or directory inside the metadata subtree
+type metaEntry struct {
+	node *types.Node // Real node the entry describes (the folder itself for directories)
+	info fs.FileInfo
+	data []byte // Document contents (files only)
+}
+
+// isMetaPath reports whether an internal path falls inside the metadata subtree
+func (w *SpectraFSWrapper) isMetaPath(path string) bool {
+	if w.meta == nil {
+		return false
+	}
+	return path == "/"+MetaDirName || strings.HasPrefix(path, "/"+MetaDirName+"/")
+}
+
+// resolveMeta maps a metadata subtree path onto the real node it describes
+func (w *SpectraFSWrapper) resolveMeta(op, name, path string) (*metaEntry, error) {
+	rest := strings.TrimPrefix(path, "/"+MetaDirName)
+
+	// The subtree root mirrors the real root directory
+	if rest == "" {
+		root, ok := w.lookupNode("/")
+		if !ok {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		return &metaEntry{node: root, info: newMetaDirInfo(MetaDirName, root)}, nil
+	}
+
+	// "<path>.json" is the document for <path> ("/.json" is the root's document)
+	if realPath, ok := strings.CutSuffix(rest, metaFileSuffix); ok {
+		if realPath == "" {
+			realPath = "/"
+		}
+		if node, found := w.lookupNode(realPath); found {
+			data, err := metaDocument(node)
+			if err != nil {
+				return nil, &fs.PathError{Op: op, Path: name, Err: err}
+			}
+			info := newMetaFileInfo(metaDocumentName(node), int64(len(data)), node)
+			return &metaEntry{node: node, info: info, data: data}, nil
+		}
+	}
+
+	// Anything else must mirror a real folder
+	if node, found := w.lookupNode(rest); found && node.Type == types.NodeTypeFolder {
+		return &metaEntry{node: node, info: newMetaDirInfo(node.Name, node)}, nil
+	}
+
+	return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
+
+// openMeta opens a file or directory inside the metadata subtree
+func (w *SpectraFSWrapper) openMeta(name, path string) (fs.File, error) {
+	entry, err := w.resolveMeta("open", name, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !entry.info.IsDir() {
+		return &spectraFile{node: entry.node, info: entry.info, data: entry.data}, nil
+	}
+
+	result, err := w.fs.ListChildren(context.Background(), &models.ListChildrenRequest{ParentID: entry.node.ID, TableName: w.world})
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	children := make([]*types.Node, 0, len(result.Folders)+len(result.Files)+len(result.Symlinks))
+	for i := range result.Folders {
+		children = append(children, &result.Folders[i].Node)
+	}
+	for i := range result.Files {
+		children = append(children, &result.Files[i].Node)
+	}
+	for i := range result.Symlinks {
+		children = append(children, &result.Symlinks[i].Node)
+	}
+
+	entries := make([]fs.DirEntry, 0, 2*len(children)+1)
+	if entry.node.ID == w.fs.root {
+		if rootEntry, err := w.metaFileEntry(entry.node); err == nil {
+			entries = append(entries, rootEntry)
+		}
+	}
+	for _, child := range children {
+		if child.Type == types.NodeTypeFolder {
+			entries = append(entries, fs.FileInfoToDirEntry(newMetaDirInfo(child.Name, child)))
+		}
+		fileEntry, err := w.metaFileEntry(child)
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		entries = append(entries, fileEntry)
+	}
+
+	return &spectraDir{node: entry.node, info: entry.info, entries: entries}, nil
+}
+
+// metaFileEntry builds the directory entry for a node's metadata document
+func (w *SpectraFSWrapper) metaFileEntry(node *types.Node) (fs.DirEntry, error) {
+	data, err := metaDocument(node)
+	if err != nil {
+		return nil, err
+	}
+	return fs.FileInfoToDirEntry(newMetaFileInfo(metaDocumentName(node), int64(len(data)), node)), nil
+}
+
+// metaDocumentName returns the base name of a node's metadata document
+func metaDocumentName(node *types.Node) string {
+	if node.Path == "/" {
+		return metaFileSuffix
+	}
+	return node.Name + metaFileSuffix
+}
+
+// metaDocument serializes a node as its metadata document
+// Output is deterministic: struct fields keep declaration order and map keys are sorted
+func metaDocument(node *types.Node) ([]byte, error) {
+	data, err := json.MarshalIndent(node, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// metaFileInfo implements fs.FileInfo for entries of the metadata subtree
+type metaFileInfo struct {
+	name string
+	size int64
+	dir  bool
+	node *types.Node
+}
+
+// newMetaFileInfo describes a metadata document
+func newMetaFileInfo(name string, size int64, node *types.Node) fs.FileInfo {
+	return &metaFileInfo{name: name, size: size, node: node}
+}
+
+// newMetaDirInfo describes a metadata directory mirroring a real folder
+func newMetaDirInfo(name string, node *types.Node) fs.FileInfo {
+	return &metaFileInfo{name: name, dir: true, node: node}
+}
+
+// Name returns the base name of the entry
+func (fi *metaFileInfo) Name() string {
+	return fi.name
+}
+
+// Size returns the document length in bytes; 0 for directories
+func (fi *metaFileInfo) Size() int64 {
+	return fi.size
+}
+
+// Mode returns read-only file mode bits
+func (fi *metaFileInfo) Mode() fs.FileMode {
+	if fi.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// ModTime returns the described node's modification time
+func (fi *metaFileInfo) ModTime() time.Time {
+	return fi.node.LastUpdated.Round(0)
+}
+
+// IsDir reports whether the entry is a directory
+func (fi *metaFileInfo) IsDir() bool {
+	return fi.dir
+}
+
+// Sys returns the described node
+func (fi *metaFileInfo) Sys() any {
+	return fi.node
+}
@@ -0,0 +1,125 @@
+package spectrafs
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"reflect"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// metaDocPath returns the fs.FS name of the metadata document for a node path
+func metaDocPath(path string) string {
+	if path == "/" {
+		return MetaDirName + "/.json"
+	}
+	return MetaDirName + path + ".json"
+}
+
+func TestMetaDocumentMatchesGetNode(t *testing.T) {
+	s := newTestFS(t)
+	fsys := NewSpectraFSWrapperWithMeta(s, "primary", MetaOptions{})
+
+	var paths []string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			paths = append(paths, "/")
+		} else {
+			paths = append(paths, "/"+name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) < 10 {
+		t.Fatalf("walk found only %v", paths)
+	}
+
+	for _, path := range paths {
+		data, err := fs.ReadFile(fsys, metaDocPath(path))
+		if err != nil {
+			t.Fatalf("read metadata of %s: %v", path, err)
+		}
+		var fromMeta types.Node
+		if err := json.Unmarshal(data, &fromMeta); err != nil {
+			t.Fatalf("decode metadata of %s: %v", path, err)
+		}
+
+		node, err := s.GetNode(context.Background(), &models.GetNodeRequest{Path: path, TableName: "primary"})
+		if err != nil {
+			t.Fatalf("GetNode(%s): %v", path, err)
+		}
+		if !fromMeta.LastUpdated.Equal(node.LastUpdated) {
+			t.Errorf("%s: metadata time %v, node time %v", path, fromMeta.LastUpdated, node.LastUpdated)
+		}
+		fromMeta.LastUpdated = node.LastUpdated
+		if !reflect.DeepEqual(&fromMeta, node) {
+			t.Errorf("%s: metadata %+v, node %+v", path, fromMeta, *node)
+		}
+
+		again, err := fs.ReadFile(fsys, metaDocPath(path))
+		if err != nil || string(again) != string(data) {
+			t.Errorf("%s: metadata document changed between reads", path)
+		}
+	}
+}
+
+func TestMetaSubtreeHiddenUnlessListed(t *testing.T) {
+	s := newTestFS(t)
+
+	for _, listMeta := range []bool{false, true} {
+		fsys := NewSpectraFSWrapperWithMeta(s, "primary", MetaOptions{ListMeta: listMeta})
+		entries, err := fs.ReadDir(fsys, ".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		listed := slices.ContainsFunc(entries, func(e fs.DirEntry) bool { return e.Name() == MetaDirName })
+		if listed != listMeta {
+			t.Errorf("ListMeta %v: meta directory listed = %v", listMeta, listed)
+		}
+
+		if _, err := fs.Stat(fsys, MetaDirName); err != nil {
+			t.Errorf("ListMeta %v: meta directory not openable by name: %v", listMeta, err)
+		}
+	}
+
+	plain := NewSpectraFSWrapper(s, "primary")
+	if _, err := fs.Stat(plain, MetaDirName); err == nil {
+		t.Error("plain wrapper serves the meta directory")
+	}
+}
+
+func TestMetaWrapperPassesFSTest(t *testing.T) {
+	s := newTestFS(t)
+
+	for _, listMeta := range []bool{false, true} {
+		fsys := NewSpectraFSWrapperWithMeta(s, "primary", MetaOptions{ListMeta: listMeta})
+		entries, err := fs.ReadDir(fsys, ".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var expected []string
+		for _, entry := range entries {
+			if entry.Name() == MetaDirName {
+				continue
+			}
+			expected = append(expected, entry.Name())
+			if listMeta {
+				expected = append(expected, metaDocPath("/"+entry.Name()))
+			}
+		}
+
+		if err := fstest.TestFS(fsys, expected...); err != nil {
+			t.Errorf("ListMeta %v: %v", listMeta, err)
+		}
+	}
+}
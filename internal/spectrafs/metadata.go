@@ -0,0 +1,88 @@
+package spectrafs
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// ErrInvalidMetadata is returned for a metadata update without entries or with an empty key
+var ErrInvalidMetadata = newError(ErrInvalidInput, "invalid metadata")
+
+// SetNodeMetadata updates a node's custom metadata and returns the updated node
+// Accepts any struct that implements NodeIdentifier and MetadataRequest. The metadata is merged
+// into the node's (an empty value removes its key) unless Replace is set, in which case it becomes
+// the node's whole metadata; replacing with no entries clears it
+func (s *SpectraFS) SetNodeMetadata(ctx context.Context, req interface {
+	models.NodeIdentifier
+	models.MetadataRequest
+}) (*types.Node, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := validateRequest(models.ValidateNodeIdentifier(req)); err != nil {
+		return nil, err
+	}
+	if len(req.GetMetadata()) == 0 && !req.GetReplace() {
+		return nil, fmt.Errorf("%w: metadata is required", ErrInvalidMetadata)
+	}
+	for key := range req.GetMetadata() {
+		if key == "" {
+			return nil, fmt.Errorf("%w: keys must not be empty", ErrInvalidMetadata)
+		}
+	}
+
+	node, _, err := s.resolveNodeAndWorld(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve node: %w", err)
+	}
+
+	node, err = s.db.UpdateMetadata(node.ID, req.GetMetadata(), req.GetReplace())
+	if err != nil {
+		return nil, err
+	}
+	return s.presentRoot(node), nil
+}
+
+// GetNodeMetadata returns a copy of a node's custom metadata (empty, not nil, when it has none)
+// Accepts any struct that implements NodeIdentifier (ID or Path+TableName)
+func (s *SpectraFS) GetNodeMetadata(ctx context.Context, req models.NodeIdentifier) (map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := validateRequest(models.ValidateNodeIdentifier(req)); err != nil {
+		return nil, err
+	}
+
+	node, _, err := s.resolveNodeAndWorld(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve node: %w", err)
+	}
+
+	metadata := maps.Clone(node.Metadata)
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	return metadata, nil
+}
+
+// matchMetadata reports whether node has every key of patterns with a value matching its path.Match pattern
+func matchMetadata(node *types.Node, patterns map[string]string) bool {
+	for key, pattern := range patterns {
+		value, ok := node.Metadata[key]
+		if !ok {
+			return false
+		}
+		if matched, _ := path.Match(pattern, value); !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// withMetadata gives every generated node metadata from the default pool
+func withMetadata(cfg *types.Config) { cfg.Seed.MetadataProbability = 1 }
+
+// metadataByPath returns the metadata of every stored node by path
+func metadataByPath(t *testing.T, s *SpectraFS) map[string]map[string]string {
+	t.Helper()
+	byPath := make(map[string]map[string]string)
+	for _, node := range storedNodes(t, s) {
+		byPath[node.Path] = node.Metadata
+	}
+	return byPath
+}
+
+func TestGeneratedMetadataIsDeterministic(t *testing.T) {
+	ctx := context.Background()
+	var trees [2]map[string]map[string]string
+	for i := range trees {
+		s := newTestFS(t, withMetadata)
+		if _, err := s.GenerateAll(ctx); err != nil {
+			t.Fatal(err)
+		}
+		trees[i] = metadataByPath(t, s)
+	}
+
+	withAny := 0
+	for path, metadata := range trees[0] {
+		if !maps.Equal(metadata, trees[1][path]) {
+			t.Errorf("%s has metadata %v in one instance and %v in the other", path, metadata, trees[1][path])
+		}
+		if len(metadata) > 0 {
+			withAny++
+		}
+	}
+	if withAny == 0 {
+		t.Fatal("no generated node has metadata with metadata_probability 1")
+	}
+
+	// Metadata draws do not disturb the tree itself
+	plain := generatedFS(t)
+	paths := metadataByPath(t, plain)
+	if len(paths) != len(trees[0]) {
+		t.Fatalf("tree with metadata has %d nodes, without %d", len(trees[0]), len(paths))
+	}
+	for path := range trees[0] {
+		if _, ok := paths[path]; !ok {
+			t.Errorf("%s is only generated with metadata enabled", path)
+		}
+	}
+}
+
+func TestSetNodeMetadata(t *testing.T) {
+	dir := onDisk(t)
+	s := newTestFS(t, dir)
+	ctx := context.Background()
+	file := upload(t, s, s.root, "a.txt", []byte("a"))
+
+	set := func(metadata map[string]string, replace bool) map[string]string {
+		t.Helper()
+		node, err := s.SetNodeMetadata(ctx, &models.SetNodeMetadataRequest{ID: file.ID, Metadata: metadata, Replace: replace})
+		if err != nil {
+			t.Fatalf("SetNodeMetadata(%v, replace %t): %v", metadata, replace, err)
+		}
+		return node.Metadata
+	}
+
+	if got := set(map[string]string{"owner": "alice", "tags": "draft"}, false); !maps.Equal(got, map[string]string{"owner": "alice", "tags": "draft"}) {
+		t.Errorf("first merge = %v", got)
+	}
+	if got := set(map[string]string{"tags": "", "content-type": "text/plain"}, false); !maps.Equal(got, map[string]string{"owner": "alice", "content-type": "text/plain"}) {
+		t.Errorf("merge removing tags = %v", got)
+	}
+	if got := set(map[string]string{"owner": "bob"}, true); !maps.Equal(got, map[string]string{"owner": "bob"}) {
+		t.Errorf("replace = %v", got)
+	}
+
+	for _, metadata := range []map[string]string{nil, {"": "x"}} {
+		_, err := s.SetNodeMetadata(ctx, &models.SetNodeMetadataRequest{ID: file.ID, Metadata: metadata})
+		if !errors.Is(err, ErrInvalidMetadata) || !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("SetNodeMetadata(%v) = %v, want ErrInvalidMetadata", metadata, err)
+		}
+	}
+
+	// The copy returned cannot change the stored metadata, which survives a reopen
+	got, err := s.GetNodeMetadata(ctx, &models.GetNodeRequest{ID: file.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got["owner"] = "mallory"
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reopened := newTestFS(t, dir)
+	if got, err := reopened.GetNodeMetadata(ctx, &models.GetNodeRequest{Path: "/a.txt", TableName: "primary"}); err != nil || !maps.Equal(got, map[string]string{"owner": "bob"}) {
+		t.Errorf("metadata after reopen = %v, %v", got, err)
+	}
+}
+
+func TestSearchByMetadata(t *testing.T) {
+	s := newTestFS(t)
+	ctx := context.Background()
+	for name, contentType := range map[string]string{"a.png": "image/png", "b.jpg": "image/jpeg", "c.txt": "text/plain"} {
+		file := upload(t, s, s.root, name, []byte(name))
+		if _, err := s.SetNodeMetadata(ctx, &models.SetNodeMetadataRequest{ID: file.ID, Metadata: map[string]string{"content-type": contentType}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		patterns map[string]string
+		want     int
+	}{
+		{map[string]string{"content-type": "image/*"}, 2},
+		{map[string]string{"content-type": "text/plain"}, 1},
+		{map[string]string{"content-type": "image/*", "owner": "*"}, 0},
+	} {
+		result, err := s.Search(ctx, &models.SearchRequest{Metadata: tc.patterns})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Nodes) != tc.want {
+			t.Errorf("Search(metadata %v) found %d nodes, want %d", tc.patterns, len(result.Nodes), tc.want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package spectrafs
+
+import (
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/metrics"
+)
+
+// SetMetrics sets the recorder of generation counts and durations, and of the database's transaction
+// durations (nil stops recording). Like SetClock, call it before the instance is shared
+func (s *SpectraFS) SetMetrics(recorder metrics.Recorder) {
+	s.metrics = recorder
+	s.db.SetMetrics(recorder)
+}
+
+// recordGenerated counts nodes stored by generation
+func (s *SpectraFS) recordGenerated(count int) {
+	if s.metrics != nil {
+		s.metrics.Add(metrics.NodesGenerated, float64(count))
+	}
+}
+
+// recordChildrenGeneration records a folder's lazy expansion that started at start
+func (s *SpectraFS) recordChildrenGeneration(count int, start time.Time) {
+	if s.metrics != nil {
+		s.recordGenerated(count)
+		s.metrics.Observe(metrics.ChildrenGenerationDuration, time.Since(start).Seconds())
+	}
+}
@@ -1,6 +1,9 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"path"
+)
 
 // NodeIdentifier interface for identifying a node by ID or Path+TableName
 // This allows any struct to be used as long as it provides these fields
@@ -28,11 +31,86 @@ type DataRequest interface {
 	GetData() []byte
 }
 
+// TargetRequest interface for requests that create a symlink
+type TargetRequest interface {
+	GetTarget() string
+}
+
+// MetadataRequest interface for requests that update a node's metadata
+type MetadataRequest interface {
+	GetMetadata() map[string]string
+	GetReplace() bool
+}
+
 // StatusRequest interface for requests that include a status
 type StatusRequest interface {
 	GetStatus() string
 }
 
+// PaginatedRequest interface for requests that support keyset pagination
+// StartingAfter and EndingBefore are opaque cursors returned in a previous ListResult
+type PaginatedRequest interface {
+	GetLimit() int
+	GetStartingAfter() string
+	GetEndingBefore() string
+}
+
+// ListFilterRequest interface for listing requests that filter and sort the children returned
+// Empty strings and zero sizes leave the listing unfiltered and in the default order
+type ListFilterRequest interface {
+	GetTypeFilter() string
+	GetNameContains() string
+	GetNameGlob() string
+	GetMinSize() int64
+	GetMaxSize() int64
+	GetSortBy() string
+	GetSortOrder() string
+}
+
+// Sort keys and orders accepted by ListFilterRequest
+const (
+	SortByName  = "name"
+	SortBySize  = "size"
+	SortByMTime = "mtime"
+
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// MaterializeRequest interface for path lookups that may generate the unlisted folders on the way
+type MaterializeRequest interface {
+	GetMaterialize() bool
+}
+
+// RecursiveRequest interface for delete requests that may remove a non-empty folder with its descendants
+type RecursiveRequest interface {
+	GetRecursive() bool
+}
+
+// OverwriteRequest interface for upload requests that may replace a file already stored at the new path
+type OverwriteRequest interface {
+	GetOverwrite() bool
+}
+
+// MoveRequest interface for requests that name a destination parent
+// Either NewParentID or NewParentPath (looked up in the request's TableName) must be set
+type MoveRequest interface {
+	GetNewParentID() string
+	GetNewParentPath() string
+}
+
+// RenameRequest interface for requests that carry a node's new name
+type RenameRequest interface {
+	GetNewName() string
+}
+
+// WalkOptions interface for requests that bound a recursive walk
+// MaxDepth is relative to the starting folder (0 = unbounded); MaxNodes caps the nodes returned (0 = default cap)
+type WalkOptions interface {
+	GetMaxDepth() int
+	GetMaxNodes() int
+}
+
 // BaseRequest is the base struct containing common fields for all requests
 // Users can embed this and add their own fields
 type BaseRequest struct {
@@ -46,6 +124,13 @@ type BaseRequest struct {
 	Name   string `json:"name,omitempty"`
 	Data   []byte `json:"data,omitempty"`
 	Status string `json:"status,omitempty"`
+
+	Limit         int    `json:"limit,omitempty"`
+	StartingAfter string `json:"starting_after,omitempty"`
+	EndingBefore  string `json:"ending_before,omitempty"`
+
+	Recursive bool `json:"recursive,omitempty"`
+	Overwrite bool `json:"overwrite,omitempty"`
 }
 
 // GetID implements NodeIdentifier
@@ -88,6 +173,31 @@ func (b *BaseRequest) GetStatus() string {
 	return b.Status
 }
 
+// GetLimit implements PaginatedRequest
+func (b *BaseRequest) GetLimit() int {
+	return b.Limit
+}
+
+// GetStartingAfter implements PaginatedRequest
+func (b *BaseRequest) GetStartingAfter() string {
+	return b.StartingAfter
+}
+
+// GetEndingBefore implements PaginatedRequest
+func (b *BaseRequest) GetEndingBefore() string {
+	return b.EndingBefore
+}
+
+// GetRecursive implements RecursiveRequest
+func (b *BaseRequest) GetRecursive() bool {
+	return b.Recursive
+}
+
+// GetOverwrite implements OverwriteRequest
+func (b *BaseRequest) GetOverwrite() bool {
+	return b.Overwrite
+}
+
 // ValidateNodeIdentifier validates that either ID is provided OR (Path + TableName) are both provided
 func ValidateNodeIdentifier(req NodeIdentifier) error {
 	id := req.GetID()
@@ -125,3 +235,37 @@ func ValidateParentIdentifier(req ParentIdentifier) error {
 
 	return fmt.Errorf("either ParentID or (ParentPath + TableName) must be provided")
 }
+
+// ValidateListFilter validates the filtering and sorting options of a listing request
+func ValidateListFilter(req ListFilterRequest) error {
+	switch req.GetTypeFilter() {
+	case "", "folder", "file", "symlink":
+	default:
+		return fmt.Errorf("unknown type_filter %q (want folder, file or symlink)", req.GetTypeFilter())
+	}
+
+	if glob := req.GetNameGlob(); glob != "" {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("name_glob %q: %v", glob, err)
+		}
+	}
+
+	minSize, maxSize := req.GetMinSize(), req.GetMaxSize()
+	if minSize < 0 || maxSize < 0 || (maxSize > 0 && minSize > maxSize) {
+		return fmt.Errorf("min_size and max_size must be non-negative with min_size <= max_size")
+	}
+
+	switch req.GetSortBy() {
+	case "", SortByName, SortBySize, SortByMTime:
+	default:
+		return fmt.Errorf("unknown sort_by %q (want %s, %s or %s)", req.GetSortBy(), SortByName, SortBySize, SortByMTime)
+	}
+
+	switch req.GetSortOrder() {
+	case "", SortOrderAsc, SortOrderDesc:
+	default:
+		return fmt.Errorf("unknown sort_order %q (want %s or %s)", req.GetSortOrder(), SortOrderAsc, SortOrderDesc)
+	}
+
+	return nil
+}
@@ -2,17 +2,21 @@ package models
 
 // GetNodeRequest represents the request to get a node
 // You can specify either:
-//   - ID: Direct node ID (e.g., "root", "s1-abc123")
+//   - ID: Direct node ID ("root" or a bare UUID; legacy "p-root" and "s1-{uuid}" forms are accepted)
 //   - Path + TableName: Lookup by path in a specific table (TableName should be "primary", "s1", "s2", etc.)
 //
 // If ID is provided, Path and TableName are ignored.
 // If Path is provided, TableName is required.
 //
-// This struct implements NodeIdentifier, allowing it to be used with the interface-based API.
+// Set Materialize to generate the unlisted folders on the way to Path before concluding it does not
+// exist, as listing them one by one would.
+//
+// This struct implements NodeIdentifier and MaterializeRequest, allowing it to be used with the interface-based API.
 type GetNodeRequest struct {
-	ID        string `json:"id,omitempty"`
-	Path      string `json:"path,omitempty"`
-	TableName string `json:"table_name,omitempty"`
+	ID          string `json:"id,omitempty"`
+	Path        string `json:"path,omitempty"`
+	TableName   string `json:"table_name,omitempty"`
+	Materialize bool   `json:"materialize,omitempty"`
 }
 
 // GetID implements NodeIdentifier
@@ -24,19 +28,46 @@ func (r *GetNodeRequest) GetPath() string { return r.Path }
 // GetTableName implements NodeIdentifier
 func (r *GetNodeRequest) GetTableName() string { return r.TableName }
 
+// GetMaterialize implements MaterializeRequest
+func (r *GetNodeRequest) GetMaterialize() bool { return r.Materialize }
+
 // ListChildrenRequest represents the request to list children of a parent node
 // You can specify either:
-//   - ParentID: Direct parent node ID (e.g., "root", "s1-abc123")
+//   - ParentID: Direct parent node ID ("root" or a bare UUID; legacy "p-root" and "s1-{uuid}" forms are accepted)
 //   - ParentPath + TableName: Lookup by path in a specific table
 //
 // If ParentID is provided, ParentPath and TableName are ignored.
 // If ParentPath is provided, TableName is required.
 //
-// This struct implements ParentIdentifier.
+// Pagination is optional: Limit caps the page size (0 returns every child), and
+// StartingAfter / EndingBefore take the NextCursor / PrevCursor of a previous ListResult.
+// Cursor is accepted as an alias for StartingAfter.
+//
+// Filtering is optional: TypeFilter keeps one node type ("folder", "file" or "symlink"), NameContains
+// keeps names containing a substring, NameGlob keeps names matching a path.Match pattern, and
+// MinSize / MaxSize bound the size in bytes (MaxSize 0 = no maximum). SortBy orders the children
+// within each type by "name" (the default), "size" or "mtime", and SortOrder is "asc" (the default)
+// or "desc". Cursors are tied to the sort they were issued under.
+//
+// Set Materialize to generate the unlisted folders on the way to ParentPath first, like GetNodeRequest.
+//
+// This struct implements ParentIdentifier, PaginatedRequest, ListFilterRequest and MaterializeRequest.
 type ListChildrenRequest struct {
-	ParentID   string `json:"parent_id,omitempty"`
-	ParentPath string `json:"parent_path,omitempty"`
-	TableName  string `json:"table_name,omitempty"`
+	ParentID      string `json:"parent_id,omitempty"`
+	ParentPath    string `json:"parent_path,omitempty"`
+	TableName     string `json:"table_name,omitempty"`
+	Limit         int    `json:"limit,omitempty"`
+	StartingAfter string `json:"starting_after,omitempty"`
+	EndingBefore  string `json:"ending_before,omitempty"`
+	Cursor        string `json:"cursor,omitempty"`
+	TypeFilter    string `json:"type_filter,omitempty"`
+	NameContains  string `json:"name_contains,omitempty"`
+	NameGlob      string `json:"name_glob,omitempty"`
+	MinSize       int64  `json:"min_size,omitempty"`
+	MaxSize       int64  `json:"max_size,omitempty"`
+	SortBy        string `json:"sort_by,omitempty"`
+	SortOrder     string `json:"sort_order,omitempty"`
+	Materialize   bool   `json:"materialize,omitempty"`
 }
 
 // GetParentID implements ParentIdentifier
@@ -48,6 +79,75 @@ func (r *ListChildrenRequest) GetParentPath() string { return r.ParentPath }
 // GetTableName implements ParentIdentifier
 func (r *ListChildrenRequest) GetTableName() string { return r.TableName }
 
+// GetLimit implements PaginatedRequest
+func (r *ListChildrenRequest) GetLimit() int { return r.Limit }
+
+// GetStartingAfter implements PaginatedRequest (falling back to Cursor)
+func (r *ListChildrenRequest) GetStartingAfter() string {
+	if r.StartingAfter != "" {
+		return r.StartingAfter
+	}
+	return r.Cursor
+}
+
+// GetEndingBefore implements PaginatedRequest
+func (r *ListChildrenRequest) GetEndingBefore() string { return r.EndingBefore }
+
+// GetTypeFilter implements ListFilterRequest
+func (r *ListChildrenRequest) GetTypeFilter() string { return r.TypeFilter }
+
+// GetNameContains implements ListFilterRequest
+func (r *ListChildrenRequest) GetNameContains() string { return r.NameContains }
+
+// GetNameGlob implements ListFilterRequest
+func (r *ListChildrenRequest) GetNameGlob() string { return r.NameGlob }
+
+// GetMinSize implements ListFilterRequest
+func (r *ListChildrenRequest) GetMinSize() int64 { return r.MinSize }
+
+// GetMaxSize implements ListFilterRequest
+func (r *ListChildrenRequest) GetMaxSize() int64 { return r.MaxSize }
+
+// GetSortBy implements ListFilterRequest
+func (r *ListChildrenRequest) GetSortBy() string { return r.SortBy }
+
+// GetSortOrder implements ListFilterRequest
+func (r *ListChildrenRequest) GetSortOrder() string { return r.SortOrder }
+
+// GetMaterialize implements MaterializeRequest
+func (r *ListChildrenRequest) GetMaterialize() bool { return r.Materialize }
+
+// WalkRequest represents the request to walk a folder's whole subtree
+// The starting folder is identified like ListChildrenRequest (ParentID, or ParentPath + TableName);
+// TableName also selects the world walked (default "primary").
+//
+// MaxDepth limits how far below the starting folder the walk descends (0 = down to seed.max_depth),
+// and MaxNodes caps the number of nodes returned (0 = DefaultWalkMaxNodes).
+//
+// This struct implements ParentIdentifier and WalkOptions.
+type WalkRequest struct {
+	ParentID   string `json:"parent_id,omitempty"`
+	ParentPath string `json:"parent_path,omitempty"`
+	TableName  string `json:"table_name,omitempty"`
+	MaxDepth   int    `json:"max_depth,omitempty"`
+	MaxNodes   int    `json:"max_nodes,omitempty"`
+}
+
+// GetParentID implements ParentIdentifier
+func (r *WalkRequest) GetParentID() string { return r.ParentID }
+
+// GetParentPath implements ParentIdentifier
+func (r *WalkRequest) GetParentPath() string { return r.ParentPath }
+
+// GetTableName implements ParentIdentifier
+func (r *WalkRequest) GetTableName() string { return r.TableName }
+
+// GetMaxDepth implements WalkOptions
+func (r *WalkRequest) GetMaxDepth() int { return r.MaxDepth }
+
+// GetMaxNodes implements WalkOptions
+func (r *WalkRequest) GetMaxNodes() int { return r.MaxNodes }
+
 // CreateFolderRequest represents the request to create a new folder
 // You can specify either:
 //   - ParentID: Direct parent node ID
@@ -75,20 +175,54 @@ func (r *CreateFolderRequest) GetTableName() string { return r.TableName }
 // GetName implements NamedRequest
 func (r *CreateFolderRequest) GetName() string { return r.Name }
 
+// CreateSymlinkRequest represents the request to create a symlink
+// You can specify either:
+//   - ParentID: Direct parent node ID
+//   - ParentPath + TableName: Lookup by path in a specific table
+//
+// Name and Target are required. Target is an absolute path in the same world or one relative to the
+// parent; it is stored as given and need not exist.
+//
+// This struct implements ParentIdentifier, NamedRequest, and TargetRequest.
+type CreateSymlinkRequest struct {
+	ParentID   string `json:"parent_id,omitempty"`
+	ParentPath string `json:"parent_path,omitempty"`
+	TableName  string `json:"table_name,omitempty"`
+	Name       string `json:"name"`
+	Target     string `json:"target"`
+}
+
+// GetParentID implements ParentIdentifier
+func (r *CreateSymlinkRequest) GetParentID() string { return r.ParentID }
+
+// GetParentPath implements ParentIdentifier
+func (r *CreateSymlinkRequest) GetParentPath() string { return r.ParentPath }
+
+// GetTableName implements ParentIdentifier
+func (r *CreateSymlinkRequest) GetTableName() string { return r.TableName }
+
+// GetName implements NamedRequest
+func (r *CreateSymlinkRequest) GetName() string { return r.Name }
+
+// GetTarget implements TargetRequest
+func (r *CreateSymlinkRequest) GetTarget() string { return r.Target }
+
 // UploadFileRequest represents the request to upload a file
 // You can specify either:
 //   - ParentID: Direct parent node ID
 //   - ParentPath + TableName: Lookup by path in a specific table
 //
-// Name and Data are required.
+// Name and Data are required. Set Overwrite to replace the content of a file already stored
+// at the new path instead of failing with ErrPathExists.
 //
-// This struct implements ParentIdentifier, NamedRequest, and DataRequest.
+// This struct implements ParentIdentifier, NamedRequest, DataRequest, and OverwriteRequest.
 type UploadFileRequest struct {
 	ParentID   string `json:"parent_id,omitempty"`
 	ParentPath string `json:"parent_path,omitempty"`
 	TableName  string `json:"table_name,omitempty"`
 	Name       string `json:"name"`
 	Data       []byte `json:"data"`
+	Overwrite  bool   `json:"overwrite,omitempty"`
 }
 
 // GetParentID implements ParentIdentifier
@@ -106,16 +240,22 @@ func (r *UploadFileRequest) GetName() string { return r.Name }
 // GetData implements DataRequest
 func (r *UploadFileRequest) GetData() []byte { return r.Data }
 
+// GetOverwrite implements OverwriteRequest
+func (r *UploadFileRequest) GetOverwrite() bool { return r.Overwrite }
+
 // DeleteNodeRequest represents the request to delete a node
 // You can specify either:
 //   - ID: Direct node ID
 //   - Path + TableName: Lookup by path in a specific table
 //
-// This struct implements NodeIdentifier.
+// Set Recursive to delete a non-empty folder together with all of its descendants.
+//
+// This struct implements NodeIdentifier and RecursiveRequest.
 type DeleteNodeRequest struct {
 	ID        string `json:"id,omitempty"`
 	Path      string `json:"path,omitempty"`
 	TableName string `json:"table_name,omitempty"`
+	Recursive bool   `json:"recursive,omitempty"`
 }
 
 // GetID implements NodeIdentifier
@@ -127,6 +267,120 @@ func (r *DeleteNodeRequest) GetPath() string { return r.Path }
 // GetTableName implements NodeIdentifier
 func (r *DeleteNodeRequest) GetTableName() string { return r.TableName }
 
+// GetRecursive implements RecursiveRequest
+func (r *DeleteNodeRequest) GetRecursive() bool { return r.Recursive }
+
+// MoveNodeRequest represents the request to move a node (and its subtree) to a new parent
+// The node is identified like any NodeIdentifier (ID, or Path + TableName); the destination by
+// NewParentID or NewParentPath, which is looked up in TableName (default "primary").
+//
+// This struct implements NodeIdentifier and MoveRequest.
+type MoveNodeRequest struct {
+	ID            string `json:"id,omitempty"`
+	Path          string `json:"path,omitempty"`
+	TableName     string `json:"table_name,omitempty"`
+	NewParentID   string `json:"new_parent_id,omitempty"`
+	NewParentPath string `json:"new_parent_path,omitempty"`
+}
+
+// GetID implements NodeIdentifier
+func (r *MoveNodeRequest) GetID() string { return r.ID }
+
+// GetPath implements NodeIdentifier
+func (r *MoveNodeRequest) GetPath() string { return r.Path }
+
+// GetTableName implements NodeIdentifier
+func (r *MoveNodeRequest) GetTableName() string { return r.TableName }
+
+// GetNewParentID implements MoveRequest
+func (r *MoveNodeRequest) GetNewParentID() string { return r.NewParentID }
+
+// GetNewParentPath implements MoveRequest
+func (r *MoveNodeRequest) GetNewParentPath() string { return r.NewParentPath }
+
+// RenameNodeRequest represents the request to rename a node in place
+// The node is identified like any NodeIdentifier (ID, or Path + TableName).
+// NewName is required and must not contain "/".
+//
+// This struct implements NodeIdentifier and RenameRequest.
+type RenameNodeRequest struct {
+	ID        string `json:"id,omitempty"`
+	Path      string `json:"path,omitempty"`
+	TableName string `json:"table_name,omitempty"`
+	NewName   string `json:"new_name"`
+}
+
+// GetID implements NodeIdentifier
+func (r *RenameNodeRequest) GetID() string { return r.ID }
+
+// GetPath implements NodeIdentifier
+func (r *RenameNodeRequest) GetPath() string { return r.Path }
+
+// GetTableName implements NodeIdentifier
+func (r *RenameNodeRequest) GetTableName() string { return r.TableName }
+
+// GetNewName implements RenameRequest
+func (r *RenameNodeRequest) GetNewName() string { return r.NewName }
+
+// SetNodeMetadataRequest represents the request to update a node's metadata
+// You can specify either:
+//   - ID: Direct node ID
+//   - Path + TableName: Lookup by path in a specific table
+//
+// Metadata is merged into the node's metadata, and keys with an empty value are removed. Set
+// Replace to make the node's metadata exactly Metadata instead.
+//
+// This struct implements NodeIdentifier and MetadataRequest.
+type SetNodeMetadataRequest struct {
+	ID        string            `json:"id,omitempty"`
+	Path      string            `json:"path,omitempty"`
+	TableName string            `json:"table_name,omitempty"`
+	Metadata  map[string]string `json:"metadata"`
+	Replace   bool              `json:"replace,omitempty"`
+}
+
+// GetID implements NodeIdentifier
+func (r *SetNodeMetadataRequest) GetID() string { return r.ID }
+
+// GetPath implements NodeIdentifier
+func (r *SetNodeMetadataRequest) GetPath() string { return r.Path }
+
+// GetTableName implements NodeIdentifier
+func (r *SetNodeMetadataRequest) GetTableName() string { return r.TableName }
+
+// GetMetadata implements MetadataRequest
+func (r *SetNodeMetadataRequest) GetMetadata() map[string]string { return r.Metadata }
+
+// GetReplace implements MetadataRequest
+func (r *SetNodeMetadataRequest) GetReplace() bool { return r.Replace }
+
+// UpdateCopyStatusRequest represents the request to update a node's copy status
+// You can specify either:
+//   - ID: Direct node ID
+//   - Path + TableName: Lookup by path in a specific table
+//
+// Status is required ("pending", "in_progress", or "completed").
+//
+// This struct implements NodeIdentifier and StatusRequest.
+type UpdateCopyStatusRequest struct {
+	ID        string `json:"id,omitempty"`
+	Path      string `json:"path,omitempty"`
+	TableName string `json:"table_name,omitempty"`
+	Status    string `json:"status"`
+}
+
+// GetID implements NodeIdentifier
+func (r *UpdateCopyStatusRequest) GetID() string { return r.ID }
+
+// GetPath implements NodeIdentifier
+func (r *UpdateCopyStatusRequest) GetPath() string { return r.Path }
+
+// GetTableName implements NodeIdentifier
+func (r *UpdateCopyStatusRequest) GetTableName() string { return r.TableName }
+
+// GetStatus implements StatusRequest
+func (r *UpdateCopyStatusRequest) GetStatus() string { return r.Status }
+
 // UpdateTraversalStatusRequest represents the request to update a node's traversal status
 // You can specify either:
 //   - ID: Direct node ID
@@ -153,3 +407,47 @@ func (r *UpdateTraversalStatusRequest) GetTableName() string { return r.TableNam
 
 // GetStatus implements StatusRequest
 func (r *UpdateTraversalStatusRequest) GetStatus() string { return r.Status }
+
+// SearchRequest represents the request to find stored nodes by path prefix, name, size and metadata
+// Every field is optional; zero values do not filter.
+type SearchRequest struct {
+	World      string `json:"world,omitempty"`       // World the nodes must exist in (default "primary")
+	PathPrefix string `json:"path_prefix,omitempty"` // Subtree to search, matched by whole path components (default "/")
+	NameGlob   string `json:"name_glob,omitempty"`   // path.Match pattern for the node name, e.g. "*.txt"
+	Type       string `json:"type,omitempty"`        // "file", "folder" or "symlink"
+	MinSize    int64  `json:"min_size,omitempty"`    // Minimum size in bytes
+	MaxSize    int64  `json:"max_size,omitempty"`    // Maximum size in bytes (0 = no maximum)
+	Limit      int    `json:"limit,omitempty"`       // Result cap (0 = default cap)
+
+	Metadata map[string]string `json:"metadata,omitempty"` // Metadata key -> path.Match pattern the node's value must match, e.g. {"content-type": "image/*"}
+}
+
+// BatchOp is one create in a BatchCreate call
+// Op is "folder" or "file". The parent is named like CreateFolderRequest (ParentID, or
+// ParentPath + TableName), or by ParentKey: the Key of a folder created earlier in the same batch.
+// Data is required for files and ignored for folders.
+type BatchOp struct {
+	Op         string `json:"op"`
+	Key        string `json:"key,omitempty"` // Client-assigned key later ops can use as ParentKey
+	ParentID   string `json:"parent_id,omitempty"`
+	ParentPath string `json:"parent_path,omitempty"`
+	TableName  string `json:"table_name,omitempty"`
+	ParentKey  string `json:"parent_key,omitempty"`
+	Name       string `json:"name"`
+	Data       []byte `json:"data,omitempty"`
+}
+
+// GetParentID implements ParentIdentifier
+func (r *BatchOp) GetParentID() string { return r.ParentID }
+
+// GetParentPath implements ParentIdentifier
+func (r *BatchOp) GetParentPath() string { return r.ParentPath }
+
+// GetTableName implements ParentIdentifier
+func (r *BatchOp) GetTableName() string { return r.TableName }
+
+// GetName implements NamedRequest
+func (r *BatchOp) GetName() string { return r.Name }
+
+// GetData implements DataRequest
+func (r *BatchOp) GetData() []byte { return r.Data }
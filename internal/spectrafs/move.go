@@ -0,0 +1,75 @@
+package spectrafs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// Errors returned by MoveNode when a move would break the tree
+var (
+	ErrMoveIntoDescendant = db.ErrMoveIntoDescendant
+	ErrMoveWorldMismatch  = db.ErrMoveWorldMismatch
+	ErrMoveTargetNotDir   = db.ErrMoveTargetNotDir
+	ErrPathExists         = db.ErrPathExists
+)
+
+// MoveNode moves a node, with its whole subtree, under a new parent folder
+// Paths, parent paths and depths of every descendant are rewritten in the same transaction.
+// Moving root, moving a node into its own subtree, moving to a path that is taken, and moving a
+// node into a parent that is missing from a world the node exists in are all rejected.
+// Returns the moved node.
+func (s *SpectraFS) MoveNode(ctx context.Context, req interface {
+	models.NodeIdentifier
+	models.MoveRequest
+}) (*types.Node, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := validateRequest(models.ValidateNodeIdentifier(req)); err != nil {
+		return nil, err
+	}
+	if req.GetNewParentID() == "" && req.GetNewParentPath() == "" {
+		return nil, fmt.Errorf("%w: either new_parent_id or new_parent_path must be provided", ErrInvalidInput)
+	}
+
+	node, world, err := s.resolveNodeAndWorld(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve node: %w", err)
+	}
+	if err := s.guardMutation(opMove, node.ID); err != nil {
+		return nil, err
+	}
+
+	var newParent *types.Node
+	if req.GetNewParentID() != "" {
+		newParentID, _ := s.normalizeNodeID(req.GetNewParentID())
+		newParent, err = s.db.GetNodeByID(newParentID)
+	} else {
+		newParent, err = s.db.GetNodeByPath(req.GetNewParentPath(), world)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve new parent: %w", err)
+	}
+
+	moved, err := s.db.MoveSubtree(node.ID, newParent.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := nodeChange(types.ChangeMove, moved[0], len(moved))
+	event.OldPath = node.Path
+	if err := s.journal(event); err != nil {
+		return nil, err
+	}
+	return moved[0], nil
+}
@@ -0,0 +1,460 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/generator"
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Defaults for the mutations config section
+const (
+	DefaultMutationInterval = "10s"
+	DefaultMutationRate     = 1
+)
+
+// MaxMutationsPerRun bounds how many mutations a single RunMutations call applies
+const MaxMutationsPerRun = 1000
+
+// ErrMutationsRunning is returned by StartMutations while the mutation engine is already running
+var ErrMutationsRunning = newError(ErrConflict, "mutation engine is already running")
+
+// ErrNoMutationCandidates is returned when no node in the mutation scope can take any weighted kind of mutation
+var ErrNoMutationCandidates = newError(ErrConflict, "nothing in the mutation scope can be mutated")
+
+// mutationEngine applies rounds of mutations in the background
+type mutationEngine struct {
+	stop chan struct{}
+	wg   sync.WaitGroup
+
+	mu      sync.Mutex
+	nextRun time.Time
+}
+
+// mutationCounters tracks the engine's outcomes since the instance was opened
+type mutationCounters struct {
+	skipped   int64
+	failed    int64
+	lastError string
+	lastAt    *time.Time
+}
+
+// MutationSettings returns the mutations config section with its defaults filled in
+func (s *SpectraFS) MutationSettings() types.MutationsConfig {
+	settings := s.config().Mutations
+	if settings.Interval == "" {
+		settings.Interval = DefaultMutationInterval
+	}
+	if settings.Rate == 0 {
+		settings.Rate = DefaultMutationRate
+	}
+	if settings.Weights == (types.MutationWeights{}) {
+		settings.Weights = types.MutationWeights{Create: 1, Modify: 1, Delete: 1}
+	}
+	if settings.Scope == "" {
+		settings.Scope = "/"
+	}
+	if settings.World == "" {
+		settings.World = "primary"
+	}
+	if settings.Seed == 0 {
+		settings.Seed = s.config().Seed.Seed
+	}
+	return settings
+}
+
+// StartMutations starts the mutation engine, which applies rate mutations every interval
+// (see the mutations config section) until StopMutations or Close
+// Returns ErrMutationsRunning if it is already running
+func (s *SpectraFS) StartMutations() error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	s.mutMu.Lock()
+	defer s.mutMu.Unlock()
+
+	if s.mutator != nil {
+		return ErrMutationsRunning
+	}
+
+	settings := s.MutationSettings()
+	interval, err := time.ParseDuration(settings.Interval)
+	if err != nil {
+		return fmt.Errorf("invalid mutation interval: %w", err)
+	}
+
+	engine := &mutationEngine{stop: make(chan struct{})}
+	engine.wg.Add(1)
+	go s.runMutationEngine(engine, interval, settings.Rate)
+
+	s.mutator = engine
+	return nil
+}
+
+// StopMutations stops the mutation engine and waits for a round in progress to finish
+// It is a no-op when the engine is not running
+func (s *SpectraFS) StopMutations() {
+	s.mutMu.Lock()
+	engine := s.mutator
+	s.mutator = nil
+	s.mutMu.Unlock()
+
+	if engine != nil {
+		close(engine.stop)
+		engine.wg.Wait()
+	}
+}
+
+// runMutationEngine runs one round of mutations every interval until the engine stops
+func (s *SpectraFS) runMutationEngine(engine *mutationEngine, interval time.Duration, rate int) {
+	defer engine.wg.Done()
+
+	for {
+		engine.mu.Lock()
+		engine.nextRun = s.now().Add(interval)
+		engine.mu.Unlock()
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-engine.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.runMutationRound(rate)
+	}
+}
+
+// runMutationRound applies one round of background mutations
+// The round is skipped while an exclusive operation such as Reset or Clone is active; failures and
+// skips are counted in MutationStatus
+func (s *SpectraFS) runMutationRound(rate int) {
+	if !s.exclusive.TryLock() {
+		s.recordMutationOutcome(nil, errors.New("skipped: an exclusive operation is in progress"), true)
+		return
+	}
+	defer s.exclusive.Unlock()
+
+	for i := 0; i < rate; i++ {
+		if _, err := s.applyMutation(context.Background()); err != nil {
+			s.recordMutationOutcome(nil, err, errors.Is(err, ErrNoMutationCandidates))
+			return
+		}
+	}
+}
+
+// RunMutations applies count mutations now, one after another, and returns them as logged
+// It works whether or not the engine is running, waits for exclusive operations to finish, and stops
+// early with ctx.Err() or ErrNoMutationCandidates; the mutations applied until then are returned
+func (s *SpectraFS) RunMutations(ctx context.Context, count int) ([]types.Mutation, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if count < 1 || count > MaxMutationsPerRun {
+		return nil, fmt.Errorf("%w: count must be between 1 and %d, got %d", ErrInvalidInput, MaxMutationsPerRun, count)
+	}
+
+	s.exclusive.Lock()
+	defer s.exclusive.Unlock()
+
+	applied := make([]types.Mutation, 0, count)
+	for i := 0; i < count; i++ {
+		if err := ctx.Err(); err != nil {
+			return applied, err
+		}
+		mutation, err := s.applyMutation(ctx)
+		if err != nil {
+			s.recordMutationOutcome(nil, err, errors.Is(err, ErrNoMutationCandidates))
+			return applied, err
+		}
+		applied = append(applied, *mutation)
+	}
+	return applied, nil
+}
+
+// ListMutations returns up to limit logged mutations after sequence number afterSeq, oldest first
+// The log survives restarts and is emptied by Reset
+func (s *SpectraFS) ListMutations(afterSeq int64, limit int) ([]types.Mutation, error) {
+	if limit < 1 || limit > MaxMutationsPerRun {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d, got %d", ErrInvalidInput, MaxMutationsPerRun, limit)
+	}
+	return s.db.ListMutations(afterSeq, limit)
+}
+
+// MutationStatus reports the engine's settings, whether it is running, and its progress
+func (s *SpectraFS) MutationStatus() (*types.MutationStatus, error) {
+	applied, err := s.db.LastMutationSeq()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutMu.Lock()
+	defer s.mutMu.Unlock()
+
+	status := &types.MutationStatus{
+		Running:        s.mutator != nil,
+		Settings:       s.MutationSettings(),
+		Applied:        applied,
+		Skipped:        s.mutStats.skipped,
+		Failed:         s.mutStats.failed,
+		LastError:      s.mutStats.lastError,
+		LastMutationAt: s.mutStats.lastAt,
+	}
+	if s.mutator != nil {
+		s.mutator.mu.Lock()
+		next := s.mutator.nextRun
+		s.mutator.mu.Unlock()
+		status.NextRunAt = &next
+	}
+	return status, nil
+}
+
+// recordMutationOutcome counts an applied mutation, or a failure (skipped marks it a skip instead)
+func (s *SpectraFS) recordMutationOutcome(mutation *types.Mutation, err error, skipped bool) {
+	s.mutMu.Lock()
+	defer s.mutMu.Unlock()
+
+	switch {
+	case err == nil:
+		at := mutation.At
+		s.mutStats.lastAt = &at
+	case skipped:
+		s.mutStats.skipped++
+		s.mutStats.lastError = err.Error()
+	default:
+		s.mutStats.failed++
+		s.mutStats.lastError = err.Error()
+	}
+}
+
+// applyMutation applies and logs the next mutation under writeMu, like any other write
+// Its draws come from MutationRNG(seed, seq): a kind by weight, then a target among the candidates
+// in path order. A kind without candidates is dropped and the kind drawn again, so the outcome
+// depends only on the seed, the sequence number and the stored tree
+func (s *SpectraFS) applyMutation(ctx context.Context) (*types.Mutation, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	settings := s.MutationSettings()
+	last, err := s.db.LastMutationSeq()
+	if err != nil {
+		return nil, err
+	}
+	seq := last + 1
+	rng := generator.MutationRNG(settings.Seed, seq)
+
+	weights := map[string]float64{
+		types.MutationCreate: settings.Weights.Create,
+		types.MutationModify: settings.Weights.Modify,
+		types.MutationDelete: settings.Weights.Delete,
+	}
+	for {
+		kind := drawMutationKind(rng, weights)
+		if kind == "" {
+			return nil, fmt.Errorf("%w (scope %s, world %s)", ErrNoMutationCandidates, settings.Scope, settings.World)
+		}
+
+		candidates, err := s.mutationCandidates(ctx, kind, settings)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) == 0 {
+			delete(weights, kind)
+			continue
+		}
+
+		mutation, err := s.mutate(kind, candidates[rng.Intn(len(candidates))], seq, settings, rng)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply %s mutation %d: %w", kind, seq, err)
+		}
+		if err := s.db.AppendMutation(mutation); err != nil {
+			return nil, err
+		}
+		s.recordMutationOutcome(mutation, nil, false)
+		return mutation, nil
+	}
+}
+
+// drawMutationKind picks a kind with positive weight in proportion to the weights ("" when none is left)
+func drawMutationKind(rng *generator.RNG, weights map[string]float64) string {
+	total := 0.0
+	for _, kind := range types.MutationKinds {
+		total += weights[kind]
+	}
+	if total <= 0 {
+		return ""
+	}
+
+	draw := rng.Float64() * total
+	last := ""
+	for _, kind := range types.MutationKinds {
+		if weights[kind] <= 0 {
+			continue
+		}
+		if draw < weights[kind] {
+			return kind
+		}
+		draw -= weights[kind]
+		last = kind
+	}
+	return last // Rounding left the draw just past the final weight
+}
+
+// mutationCandidates lists, in path order, the stored nodes in scope that exist in the world (as
+// listings see them) and can take a kind of mutation: files for modify, nodes below the scope for
+// delete, and folders whose children have already been generated for create. Only materialized
+// nodes qualify, so mutations never generate folders or keep them from being generated
+func (s *SpectraFS) mutationCandidates(ctx context.Context, kind string, settings types.MutationsConfig) ([]*types.Node, error) {
+	var candidates []*types.Node
+	var folders []*types.Node
+	expanded := make(map[string]bool)
+
+	err := s.db.SearchPaths(ctx, settings.Scope, func(string) bool { return true }, func(node *types.Node) (bool, error) {
+		if !s.applyRetentionView(node).ExistenceMap[settings.World] {
+			return true, nil
+		}
+
+		switch kind {
+		case types.MutationModify:
+			if node.Type == types.NodeTypeFile {
+				candidates = append(candidates, node)
+			}
+		case types.MutationDelete:
+			if node.Path != settings.Scope && !s.isRoot(node.ID) {
+				candidates = append(candidates, node)
+			}
+		case types.MutationCreate:
+			// Parents sort before their children, so a folder is seen before it is known to be expanded
+			if node.Type == types.NodeTypeFolder {
+				folders = append(folders, node)
+			}
+			expanded[node.ParentID] = true
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, folder := range folders {
+		if expanded[folder.ID] {
+			candidates = append(candidates, folder)
+		}
+	}
+	return candidates, nil
+}
+
+// mutate applies one mutation of kind to target through the regular storage paths, so indexes,
+// stats and coverage stay consistent, journals it like any other change, and returns its log entry
+func (s *SpectraFS) mutate(kind string, target *types.Node, seq int64, settings types.MutationsConfig, rng *generator.RNG) (*types.Mutation, error) {
+	mutation := &types.Mutation{
+		Seq:   seq,
+		Kind:  kind,
+		World: settings.World,
+		At:    s.now(),
+	}
+
+	var journaled change
+
+	switch kind {
+	case types.MutationCreate:
+		node, err := s.newMutationNode(target, seq, settings.World, mutation.At, rng)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.db.InsertNode(node); err != nil {
+			return nil, err
+		}
+		mutation.NodeID, mutation.Path, mutation.NodeType = node.ID, node.Path, node.Type
+		mutation.Size, mutation.Checksum = node.Size, node.Checksum
+		journaled = nodeChange(types.ChangeCreate, node, 1)
+
+	case types.MutationModify:
+		// The file keeps its ID and place; its content moves to a new revision
+		revised := *target
+		revised.ContentID = fmt.Sprintf("%s@%d", target.ID, seq)
+		revised.Size = mutationFileSize(s.config(), rng)
+		revised.LastUpdated = mutation.At
+		checksum, err := generator.DeterministicChecksum(generator.ContentSeed(s.config(), revised.ContentID), revised.Size)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate file data: %w", err)
+		}
+		revised.Checksum = &checksum
+
+		stored, _, err := s.db.ReplaceFileNode(&revised)
+		if err != nil {
+			return nil, err
+		}
+		mutation.NodeID, mutation.Path, mutation.NodeType = stored.ID, stored.Path, stored.Type
+		mutation.Size, mutation.Checksum = stored.Size, stored.Checksum
+		mutation.PrevSize, mutation.PrevChecksum = target.Size, target.Checksum
+		journaled = nodeChange(types.ChangeModify, stored, 1)
+
+	case types.MutationDelete:
+		if err := s.guardMutation(opDelete, target.ID); err != nil {
+			return nil, err
+		}
+		deleted, err := s.db.DeleteSubtree(target.ID)
+		if err != nil {
+			return nil, err
+		}
+		mutation.NodeID, mutation.Path, mutation.NodeType = target.ID, target.Path, target.Type
+		mutation.Removed = len(deleted)
+		journaled = nodeChange(types.ChangeDelete, target, len(deleted))
+
+	default:
+		return nil, fmt.Errorf("unknown mutation kind %s", kind)
+	}
+
+	if err := s.journal(journaled); err != nil {
+		return nil, err
+	}
+	return mutation, nil
+}
+
+// newMutationNode builds the file or folder a create mutation adds to parent, named after the
+// sequence number ("mutation_7" or "mutation_7.txt") and present in the mutated world
+func (s *SpectraFS) newMutationNode(parent *types.Node, seq int64, world string, at time.Time, rng *generator.RNG) (*types.Node, error) {
+	node := &types.Node{
+		ID:              uuid.New().String(),
+		ParentID:        parent.ID,
+		ParentPath:      parent.Path,
+		Type:            types.NodeTypeFolder,
+		Name:            fmt.Sprintf("mutation_%d", seq),
+		DepthLevel:      parent.DepthLevel + 1,
+		LastUpdated:     at,
+		TraversalStatus: types.StatusPending,
+		CopyStatus:      types.CopyStatusPending,
+	}
+	if rng.Intn(2) == 1 {
+		node.Type = types.NodeTypeFile
+		node.Name += ".txt"
+		node.Size = mutationFileSize(s.config(), rng)
+	}
+	node.Path = utils.JoinPath(parent.Path, node.Name)
+
+	// Existence is rolled like any created node's, but the node is always in the world being mutated
+	node.ExistenceMap = generator.RollExistence(parent, s.config(), node.Path, node.DepthLevel)
+	node.ExistenceMap[world] = true
+
+	if err := generator.ChecksumFile(node, s.config()); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// mutationFileSize draws a file size from the configured range
+func mutationFileSize(cfg *types.Config, rng *generator.RNG) int64 {
+	minSize, maxSize := generator.FileSizeRange(cfg)
+	if maxSize > minSize {
+		return minSize + rng.Int63n(maxSize-minSize+1)
+	}
+	return minSize
+}
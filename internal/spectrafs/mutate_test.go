@@ -0,0 +1,155 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// mutationTrace summarizes mutations by what they did, leaving out the random node IDs and times
+func mutationTrace(mutations []types.Mutation) []string {
+	trace := make([]string, 0, len(mutations))
+	for _, m := range mutations {
+		checksum := ""
+		if m.Checksum != nil {
+			checksum = *m.Checksum
+		}
+		trace = append(trace, m.Kind+" "+m.Path+" "+checksum)
+	}
+	return trace
+}
+
+func TestRunMutationsIsDeterministic(t *testing.T) {
+	ctx := context.Background()
+	var traces [2][]string
+	kinds := make(map[string]bool)
+	for i := range traces {
+		s := generatedFS(t)
+		mutations, err := s.RunMutations(ctx, 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		traces[i] = mutationTrace(mutations)
+		for _, m := range mutations {
+			kinds[m.Kind] = true
+		}
+	}
+
+	for i := range traces[0] {
+		if traces[0][i] != traces[1][i] {
+			t.Fatalf("mutation %d differs between runs with the same seed:\n%s\n%s", i+1, traces[0][i], traces[1][i])
+		}
+	}
+	if len(kinds) != len(types.MutationKinds) {
+		t.Errorf("20 mutations with equal weights applied only %v", kinds)
+	}
+}
+
+func TestRunMutationsAppliesAndLogs(t *testing.T) {
+	s := generatedFS(t)
+	ctx := context.Background()
+
+	var applied []types.Mutation
+	for range 20 {
+		mutations, err := s.RunMutations(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := mutations[0]
+		applied = append(applied, m)
+
+		nodes := storedNodes(t, s)
+		node := nodes[m.NodeID]
+		switch m.Kind {
+		case types.MutationCreate:
+			if node == nil || node.Path != m.Path || !node.ExistenceMap["primary"] {
+				t.Fatalf("created %s is not stored in primary: %+v", m.Path, node)
+			}
+		case types.MutationModify:
+			if node == nil || node.Size != m.Size || *node.Checksum != *m.Checksum || *m.Checksum == *m.PrevChecksum {
+				t.Fatalf("modified %s does not carry the new content: %+v", m.Path, node)
+			}
+		case types.MutationDelete:
+			if node != nil || m.Removed < 1 {
+				t.Fatalf("deleted %s (%d removed) is still stored", m.Path, m.Removed)
+			}
+			for _, other := range nodes {
+				if other.ParentID == m.NodeID {
+					t.Fatalf("%s is stored under deleted %s", other.Path, m.Path)
+				}
+			}
+		}
+	}
+
+	logged, err := s.ListMutations(0, MaxMutationsPerRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logged) != len(applied) {
+		t.Fatalf("logged %d mutations, applied %d", len(logged), len(applied))
+	}
+	for i := range logged {
+		if logged[i].Seq != int64(i+1) || logged[i].NodeID != applied[i].NodeID || logged[i].Kind != applied[i].Kind {
+			t.Errorf("logged mutation %d = %+v, applied %+v", i+1, logged[i], applied[i])
+		}
+	}
+	if later, err := s.ListMutations(15, 10); err != nil || len(later) != 5 || later[0].Seq != 16 {
+		t.Errorf("ListMutations(15, 10) = %d mutations, %v", len(later), err)
+	}
+
+	report, err := s.CheckIntegrity(ctx, false)
+	if err != nil || !report.OK {
+		t.Fatalf("integrity after mutations: %+v, %v", report, err)
+	}
+}
+
+func TestMutationEngineLifecycle(t *testing.T) {
+	s := newTestFS(t, func(cfg *types.Config) {
+		cfg.Mutations.Interval = "10ms"
+		cfg.Mutations.Rate = 2
+	})
+	if _, err := s.GenerateAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.StartMutations(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.StartMutations(); !errors.Is(err, ErrMutationsRunning) {
+		t.Fatalf("second StartMutations = %v, want ErrMutationsRunning", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status, err := s.MutationStatus()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !status.Running || status.NextRunAt == nil {
+			t.Fatalf("running engine reports %+v", status)
+		}
+		if status.Applied >= 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("engine applied %d mutations in 5s", status.Applied)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	s.StopMutations()
+	status, err := s.MutationStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Running || status.NextRunAt != nil || status.Failed != 0 {
+		t.Errorf("stopped engine reports %+v", status)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if after, _ := s.MutationStatus(); after.Applied != status.Applied {
+		t.Errorf("mutations kept running after StopMutations: %d then %d", status.Applied, after.Applied)
+	}
+}
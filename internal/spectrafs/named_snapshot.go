@@ -0,0 +1,116 @@
+package spectrafs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// Errors of the named snapshots
+var (
+	ErrSnapshotNotFound    = db.ErrSnapshotNotFound
+	ErrSnapshotExists      = db.ErrSnapshotExists
+	ErrInvalidSnapshotName = newError(ErrInvalidInput, "invalid snapshot name")
+)
+
+// maxSnapshotNameLength bounds snapshot names, which are bucket keys and URL path elements
+const maxSnapshotNameLength = 255
+
+// SaveSnapshot stores a copy of the tree under name in the database, together with its stats,
+// mutation log and change journal, so RestoreSnapshot can return to it without a Reset and
+// regeneration. Writes wait while it copies. Fails with ErrSnapshotExists if the name is taken
+func (s *SpectraFS) SaveSnapshot(ctx context.Context, name string) (*types.SnapshotInfo, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := validateSnapshotName(name); err != nil {
+		return nil, err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	info := &types.SnapshotInfo{
+		Name:        name,
+		CreatedAt:   s.now().UTC(),
+		Fingerprint: generationFingerprint(s.config(), time.Time{}).Hash,
+	}
+	if err := s.db.SaveSnapshot(info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// RestoreSnapshot replaces the tree, its stats and mutation log with those saved under name, in
+// one transaction. Like Reset it waits for running operations (GenerateAll, maintenance) and
+// holds off every write while it runs. The journal keeps numbering where it was and gets a reset
+// event, so feed consumers rescan. A snapshot saved under other generation settings (another seed
+// or set of worlds) returns ErrConfigMismatch, since the restored tree would not match generation
+func (s *SpectraFS) RestoreSnapshot(ctx context.Context, name string) (*types.SnapshotInfo, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := validateSnapshotName(name); err != nil {
+		return nil, err
+	}
+
+	s.exclusive.Lock()
+	defer s.exclusive.Unlock()
+	defer s.beginOperation(OperationRestore)()
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	saved, err := s.db.GetSnapshot(name)
+	if err != nil {
+		return nil, err
+	}
+	if saved.Fingerprint != generationFingerprint(s.config(), time.Time{}).Hash {
+		return nil, fmt.Errorf("%w: snapshot %s was saved with other generation settings", ErrConfigMismatch, name)
+	}
+
+	info, err := s.db.RestoreSnapshot(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	s.epoch.Add(1)
+	if err := s.journal(change{ChangeEvent: types.ChangeEvent{Op: types.ChangeReset, NodeID: "root", Path: "/", Nodes: int(info.Nodes)}}); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// ListSnapshots returns the saved snapshots, ordered by name
+func (s *SpectraFS) ListSnapshots() ([]types.SnapshotInfo, error) {
+	return s.db.ListSnapshots()
+}
+
+// DeleteSnapshot removes the snapshot saved under name (ErrSnapshotNotFound if there is none)
+func (s *SpectraFS) DeleteSnapshot(name string) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	if err := validateSnapshotName(name); err != nil {
+		return err
+	}
+	return s.db.DeleteSnapshot(name)
+}
+
+// validateSnapshotName rejects empty and overlong names and names containing a slash
+func validateSnapshotName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidSnapshotName)
+	case len(name) > maxSnapshotNameLength:
+		return fmt.Errorf("%w: name is longer than %d bytes", ErrInvalidSnapshotName, maxSnapshotNameLength)
+	case strings.Contains(name, "/"):
+		return fmt.Errorf("%w: name must not contain \"/\"", ErrInvalidSnapshotName)
+	}
+	return nil
+}
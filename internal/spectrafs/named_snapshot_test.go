@@ -0,0 +1,168 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// storedStats returns the stored counters, without the fields computed per call
+func storedStats(t *testing.T, s *SpectraFS) types.Stats {
+	t.Helper()
+	stats, err := s.db.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return types.Stats{
+		TotalNodes:     stats.TotalNodes,
+		FileCount:      stats.FileCount,
+		FolderCount:    stats.FolderCount,
+		TotalFileSize:  stats.TotalFileSize,
+		PrimaryNodes:   stats.PrimaryNodes,
+		SecondaryNodes: stats.SecondaryNodes,
+	}
+}
+
+func TestSnapshotRestoreRollsBackMutations(t *testing.T) {
+	s := generatedFS(t)
+	ctx := context.Background()
+	wantProps, wantStats := generatedProps(t, s), storedStats(t, s)
+
+	saved, err := s.SaveSnapshot(ctx, "baseline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.JournalSeq != latestChangeSeq(t, s) {
+		t.Errorf("snapshot records journal seq %d, journal is at %d", saved.JournalSeq, latestChangeSeq(t, s))
+	}
+
+	a := mkdir(t, s, s.root, "added")
+	upload(t, s, a.ID, "x.txt", []byte("data"))
+	first := childNodes(list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}))[0]
+	if err := s.DeleteNode(ctx, &models.DeleteNodeRequest{ID: first.ID, Recursive: true}); err != nil {
+		t.Fatal(err)
+	}
+	beforeRestore := latestChangeSeq(t, s)
+
+	info, err := s.RestoreSnapshot(ctx, "baseline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := generatedProps(t, s); !maps.Equal(got, wantProps) {
+		t.Errorf("restored tree has %d nodes, the saved one %d", len(got), len(wantProps))
+	}
+	if got := storedStats(t, s); fmt.Sprint(got) != fmt.Sprint(wantStats) {
+		t.Errorf("restored stats %+v, want %+v", got, wantStats)
+	}
+	if info.Nodes != wantStats.TotalNodes+1 {
+		t.Errorf("restore reports %d nodes, want %d with the root", info.Nodes, wantStats.TotalNodes+1)
+	}
+
+	// The journal keeps numbering and marks the restore for feed consumers
+	events := changesSince(t, s, beforeRestore)
+	if len(events) != 1 || events[0].Op != types.ChangeReset || events[0].Seq != beforeRestore+1 {
+		t.Errorf("journal after restore = %+v, want one reset event numbered %d", events, beforeRestore+1)
+	}
+	report, err := s.CheckIntegrity(ctx, false)
+	if err != nil || !report.OK {
+		t.Errorf("integrity after restore: %+v, %v", report, err)
+	}
+}
+
+func TestSnapshotListAndDelete(t *testing.T) {
+	s := newTestFS(t)
+	ctx := context.Background()
+
+	for _, name := range []string{"b", "a"} {
+		if _, err := s.SaveSnapshot(ctx, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.SaveSnapshot(ctx, "a"); !errors.Is(err, ErrSnapshotExists) {
+		t.Errorf("saving a taken name = %v, want ErrSnapshotExists", err)
+	}
+	for _, name := range []string{"", "a/b"} {
+		if _, err := s.SaveSnapshot(ctx, name); !errors.Is(err, ErrInvalidSnapshotName) {
+			t.Errorf("SaveSnapshot(%q) = %v, want ErrInvalidSnapshotName", name, err)
+		}
+	}
+
+	snapshots, err := s.ListSnapshots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Name != "a" || snapshots[1].Name != "b" {
+		t.Errorf("ListSnapshots = %+v, want a and b in order", snapshots)
+	}
+
+	if err := s.DeleteSnapshot("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteSnapshot("a"); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Errorf("deleting twice = %v, want ErrSnapshotNotFound", err)
+	}
+	if _, err := s.RestoreSnapshot(ctx, "a"); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Errorf("restoring a deleted snapshot = %v, want ErrSnapshotNotFound", err)
+	}
+}
+
+func TestSnapshotRestoreRefusesOtherSettings(t *testing.T) {
+	s := newTestFS(t)
+	if _, err := s.SaveSnapshot(context.Background(), "seeded"); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := *s.config()
+	changed.Seed.Seed++
+	if err := s.UpdateConfig(context.Background(), &changed, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RestoreSnapshot(context.Background(), "seeded"); !errors.Is(err, ErrConfigMismatch) {
+		t.Errorf("restoring a snapshot of another seed = %v, want ErrConfigMismatch", err)
+	}
+}
+
+func TestSnapshotRestoreWaitsForWrites(t *testing.T) {
+	s := newTestFS(t)
+	ctx := context.Background()
+	if _, err := s.SaveSnapshot(ctx, "empty"); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 20 {
+				_, err := s.CreateFolder(ctx, &models.CreateFolderRequest{ParentID: s.root, Name: fmt.Sprintf("w%d-%d", i, j)})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	for range 5 {
+		if _, err := s.RestoreSnapshot(ctx, "empty"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+
+	// Every write landed wholly before or after a restore, so counters match the stored tree
+	report, err := s.CheckIntegrity(ctx, false)
+	if err != nil || !report.OK {
+		t.Fatalf("integrity after restores under writes: %+v, %v", report, err)
+	}
+	nodes, _ := storedTotals(t, s)
+	if stored := int64(len(storedNodes(t, s)) - 1); nodes != stored {
+		t.Errorf("stats count %d nodes, %d are stored", nodes, stored)
+	}
+}
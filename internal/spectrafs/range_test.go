@@ -0,0 +1,58 @@
+package spectrafs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/generator"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestReadFileRangeMatchesContent(t *testing.T) {
+	// Files span a few content blocks, so ranges start and end inside and across them
+	size := int64(3*generator.FileBlockSize + 100)
+	s := newTestFS(t, func(cfg *types.Config) { cfg.Seed.MinFileSize, cfg.Seed.MaxFileSize = size, size })
+	file := firstFile(t, s)
+	full, _, err := s.GetFileData(file.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(full)) != size {
+		t.Fatalf("file holds %d bytes, want %d", len(full), size)
+	}
+
+	// Two adjacent ranges make up the whole content wherever the split is
+	for _, split := range []int64{0, 1, generator.FileBlockSize - 1, generator.FileBlockSize, size / 2, size - 1, size} {
+		head, err := s.ReadFileRange(file.ID, 0, split)
+		if err != nil {
+			t.Fatalf("range [0, %d): %v", split, err)
+		}
+		tail, err := s.ReadFileRange(file.ID, split, size-split)
+		if err != nil {
+			t.Fatalf("range [%d, %d): %v", split, size, err)
+		}
+		if !bytes.Equal(append(head, tail...), full) {
+			t.Errorf("ranges split at %d differ from the full content", split)
+		}
+	}
+
+	// A window past the end is cut short with io.EOF
+	tail, err := s.ReadFileRange(file.ID, size-10, 100)
+	if !errors.Is(err, io.EOF) || !bytes.Equal(tail, full[size-10:]) {
+		t.Errorf("range past the end = %d bytes, %v; want the last 10 and io.EOF", len(tail), err)
+	}
+	if end, err := s.ReadFileRange(file.ID, size, 5); !errors.Is(err, io.EOF) || len(end) != 0 {
+		t.Errorf("range at the end = %d bytes, %v; want none and io.EOF", len(end), err)
+	}
+
+	for _, bad := range [][2]int64{{size + 1, 5}, {-1, 5}, {0, -1}} {
+		if _, err := s.ReadFileRange(file.ID, bad[0], bad[1]); !errors.Is(err, ErrInvalidRange) {
+			t.Errorf("range at %d of %d = %v, want ErrInvalidRange", bad[0], bad[1], err)
+		}
+	}
+	if _, err := s.ReadFileRange(s.root, 0, 1); !errors.Is(err, ErrNotAFile) {
+		t.Errorf("range of a folder = %v, want ErrNotAFile", err)
+	}
+}
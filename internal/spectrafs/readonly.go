@@ -0,0 +1,22 @@
+package spectrafs
+
+import "github.com/Project-Sylos/Spectra/internal/db"
+
+// ErrReadOnly is returned by every mutating method of an instance opened with db.read_only. Like
+// ErrRootProtected it matches none of the error categories
+var ErrReadOnly = db.ErrReadOnly
+
+// ReadOnly reports whether the instance was opened with db.read_only: it serves the stored tree as
+// it is, and a folder that was never expanded lists as empty instead of being generated
+func (s *SpectraFS) ReadOnly() bool {
+	return s.db.ReadOnly()
+}
+
+// checkWritable returns ErrReadOnly for a read-only instance, so a write fails before it does any work
+// (the database refuses any write that gets past it)
+func (s *SpectraFS) checkWritable() error {
+	if s.db.ReadOnly() {
+		return ErrReadOnly
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package spectrafs
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/config"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// ErrInvalidConfig is returned by UpdateConfig for a config that fails validation or changes a
+// setting that is fixed while the instance is open
+var ErrInvalidConfig = newError(ErrInvalidInput, "invalid config")
+
+// UpdateConfig replaces the configuration of the running instance with a copy of cfg, which is
+// defaulted and validated first (ErrInvalidConfig). seed.db_path, instances and the set of secondary
+// worlds are fixed while the instance is open (use AddWorld and RemoveWorld for worlds). Changes to
+// the generation settings (the seed section and world probabilities and world_generation) would make
+// the stored tree disagree with what generation produces, so they return ErrConfigMismatch unless
+// reset is set, which clears the tree like Reset and records the new settings in the database.
+//
+// Retention, chaos, the generation budgets and root_display_name apply immediately; the api, db,
+// debug, mutations and maintenance_schedule sections and the bandwidth limits are stored and take
+// effect when the instance is reopened. An instance opened from a config file saves the new
+// config to it (see config.SaveToFile).
+func (s *SpectraFS) UpdateConfig(ctx context.Context, cfg *types.Config, reset bool) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	next := config.Clone(cfg)
+	if err := config.ApplyDefaults(next); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if err := config.Validate(next); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	s.exclusive.Lock()
+	defer s.exclusive.Unlock()
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.worldsMu.Lock()
+	defer s.worldsMu.Unlock()
+
+	current := s.config()
+	if err := checkFixedSettings(current, next); err != nil {
+		return err
+	}
+	changes := generationChanges(current, next)
+	if len(changes) > 0 && !reset {
+		return fmt.Errorf("%w: %s (reset the tree to apply them)", ErrConfigMismatch, strings.Join(changes, "; "))
+	}
+
+	s.cfg.Store(next)
+	if reset {
+		err := func() error {
+			defer s.beginOperation(OperationReset)()
+			return s.resetTree(ctx)
+		}()
+		if err != nil {
+			s.cfg.Store(current)
+			return err
+		}
+	}
+	if len(changes) > 0 {
+		if err := s.recordWorlds(); err != nil {
+			return err
+		}
+	}
+
+	if !reflect.DeepEqual(current.Chaos, next.Chaos) {
+		s.chaos.mu.Lock()
+		s.chaos.set(next.Chaos)
+		s.chaos.mu.Unlock()
+	}
+
+	if s.configPath != "" {
+		if err := config.SaveToFile(next, s.configPath); err != nil {
+			return fmt.Errorf("config applied but not saved: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkFixedSettings returns ErrInvalidConfig if next changes a setting that cannot change while open
+func checkFixedSettings(current, next *types.Config) error {
+	if next.Seed.DBPath != current.Seed.DBPath {
+		return fmt.Errorf("%w: seed.db_path cannot change while the instance is open", ErrInvalidConfig)
+	}
+	if !reflect.DeepEqual(next.Instances, current.Instances) {
+		return fmt.Errorf("%w: instances cannot change while the instance is open", ErrInvalidConfig)
+	}
+	currentWorlds := slices.Sorted(maps.Keys(current.SecondaryTables))
+	nextWorlds := slices.Sorted(maps.Keys(next.SecondaryTables))
+	if !slices.Equal(currentWorlds, nextWorlds) {
+		return fmt.Errorf("%w: secondary_tables must list the same worlds (use AddWorld and RemoveWorld)", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// skippedSeedSettings are left out of generationChanges' per-setting comparison, by JSON name: the
+// first line shapes reads or how far generation goes rather than what it produces, and the second
+// is fingerprinted, so fingerprintChanges describes it
+var skippedSeedSettings = map[string]bool{
+	"db_path": true, "per_file_bandwidth": true, "max_total_nodes": true, "max_total_bytes": true,
+	"seed": true, "max_depth": true, "min_folders": true, "max_folders": true, "min_files": true, "max_files": true, "profile": true,
+}
+
+// generationChanges describes how next's generation settings differ from current's, one entry per setting
+func generationChanges(current, next *types.Config) []string {
+	changes := fingerprintChanges(generationFingerprint(current, time.Time{}), generationFingerprint(next, time.Time{}))
+
+	currentSeed, nextSeed := reflect.ValueOf(current.Seed), reflect.ValueOf(next.Seed)
+	for i := 0; i < currentSeed.NumField(); i++ {
+		name, _, _ := strings.Cut(currentSeed.Type().Field(i).Tag.Get("json"), ",")
+		if !skippedSeedSettings[name] && !sameSetting(currentSeed.Field(i), nextSeed.Field(i)) {
+			changes = append(changes, "seed."+name+" differs")
+		}
+	}
+	if !sameSetting(reflect.ValueOf(current.WorldGeneration), reflect.ValueOf(next.WorldGeneration)) {
+		changes = append(changes, "world_generation differs")
+	}
+	return changes
+}
+
+// sameSetting reports whether two values of a setting are equal, an empty map or slice matching nil
+func sameSetting(a, b reflect.Value) bool {
+	if (a.Kind() == reflect.Map || a.Kind() == reflect.Slice) && a.Len() == 0 && b.Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
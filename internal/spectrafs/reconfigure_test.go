@@ -0,0 +1,107 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"path/filepath"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/config"
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestGetConfigIsolated(t *testing.T) {
+	want := generatedProps(t, newTestFS(t))
+
+	cfg := testConfig(t)
+	s := openTestFS(t, cfg)
+
+	// Neither the config passed in nor the one returned reaches the running instance
+	mutate := func(c *types.Config) {
+		c.Seed.Seed++
+		c.Seed.MaxDepth = 1
+		c.Seed.MaxFolders = 9
+		c.SecondaryTables["s1"] = 0
+		c.SecondaryTables["s2"] = 1
+	}
+	mutate(cfg)
+	returned := s.GetConfig()
+	mutate(returned)
+
+	if got := generatedProps(t, s); !maps.Equal(got, want) {
+		t.Errorf("mutated configs changed generation: %d nodes, want %d", len(got), len(want))
+	}
+	if again := s.GetConfig(); again.Seed.Seed != testConfig(t).Seed.Seed || len(again.SecondaryTables) != 1 {
+		t.Errorf("GetConfig after mutating a copy: seed %d, worlds %v", again.Seed.Seed, again.SecondaryTables)
+	}
+}
+
+func TestUpdateConfig(t *testing.T) {
+	s := newTestFS(t)
+	ctx := context.Background()
+	list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"})
+
+	// Settings that don't shape the tree apply without a reset
+	cfg := s.GetConfig()
+	cfg.Seed.MaxTotalNodes = 1000
+	if err := s.UpdateConfig(ctx, cfg, false); err != nil {
+		t.Fatal(err)
+	}
+	if s.GetConfig().Seed.MaxTotalNodes != 1000 {
+		t.Error("max_total_nodes was not applied")
+	}
+
+	// A new seed needs a reset
+	cfg.Seed.Seed++
+	if err := s.UpdateConfig(ctx, cfg, false); !errors.Is(err, ErrConfigMismatch) {
+		t.Fatalf("seed change without reset = %v, want ErrConfigMismatch", err)
+	}
+	if err := s.UpdateConfig(ctx, cfg, true); err != nil {
+		t.Fatal(err)
+	}
+	fresh := newTestFS(t, func(c *types.Config) { c.Seed.Seed = cfg.Seed.Seed; c.Seed.MaxTotalNodes = 1000 })
+	if got, want := generatedProps(t, s), generatedProps(t, fresh); !maps.Equal(got, want) {
+		t.Errorf("tree after reset with seed %d differs from a fresh instance's", cfg.Seed.Seed)
+	}
+
+	// The world set and the database path are fixed while open
+	worlds := s.GetConfig()
+	worlds.SecondaryTables["s2"] = 0.5
+	if err := s.UpdateConfig(ctx, worlds, true); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("adding a world = %v, want ErrInvalidConfig", err)
+	}
+	invalid := s.GetConfig()
+	invalid.Seed.MaxDepth = -1
+	if err := s.UpdateConfig(ctx, invalid, true); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("negative max_depth = %v, want ErrInvalidConfig", err)
+	}
+}
+
+func TestUpdateConfigSavesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := testConfig(t)
+	onDisk(t)(cfg)
+	if err := config.SaveToFile(cfg, path); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSpectraFS(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	next := s.GetConfig()
+	next.RootDisplayName = "mount"
+	if err := s.UpdateConfig(context.Background(), next, false); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := config.LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.RootDisplayName != "mount" {
+		t.Errorf("saved root_display_name %q, want mount", saved.RootDisplayName)
+	}
+}
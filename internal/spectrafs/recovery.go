@@ -0,0 +1,83 @@
+package spectrafs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// RecoveryOnOpen returns the consistency report produced when this instance opened its database,
+// or nil if the previous run shut down cleanly
+func (s *SpectraFS) RecoveryOnOpen() *types.RecoveryReport {
+	return s.recovery
+}
+
+// LastRecovery returns the most recent recovery report stored in the database, or nil if the
+// database has never been opened after an unclean shutdown
+func (s *SpectraFS) LastRecovery() (*types.RecoveryReport, error) {
+	return s.db.LastRecovery()
+}
+
+// IntegrityOnOpen returns the index check run when this instance opened its database,
+// or nil unless db.check_integrity is set
+func (s *SpectraFS) IntegrityOnOpen() *types.IntegrityReport {
+	return s.integrity
+}
+
+// CheckIntegrity verifies the index buckets against the stored nodes and reports every disagreement,
+// along with nodes existing in a world their parent is missing from (which no listing of that world reaches)
+// A quick check only compares entry counts. It reads one snapshot, so it runs alongside other operations
+func (s *SpectraFS) CheckIntegrity(ctx context.Context, quick bool) (*types.IntegrityReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.db.CheckIntegrity(ctx, quick)
+}
+
+// Repair marks orphaned nodes missing from the worlds their parent is missing from, rebuilds every
+// index, the stats and the coverage counters from the stored nodes and returns a full integrity
+// check of the result. Like Reset it waits for running operations
+func (s *SpectraFS) Repair(ctx context.Context) (*types.IntegrityReport, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	s.exclusive.Lock()
+	defer s.exclusive.Unlock()
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := s.db.Repair(ctx); err != nil {
+		return nil, fmt.Errorf("failed to repair database: %w", err)
+	}
+	report, err := s.db.CheckIntegrity(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	report.Repaired = true
+	return report, nil
+}
+
+// checkIntegrityOnOpen runs the quick check db.check_integrity asks for, following a failed one with
+// the full check (and, with db.auto_repair, a repair). Returns nil when the setting is off
+func checkIntegrityOnOpen(database *db.DB, cfg *types.Config) (*types.IntegrityReport, error) {
+	if !cfg.DB.CheckIntegrity {
+		return nil, nil
+	}
+	ctx := context.Background()
+
+	report, err := database.CheckIntegrity(ctx, true)
+	if err != nil || report.OK {
+		return report, err
+	}
+	if report, err = database.CheckIntegrity(ctx, false); err != nil || !cfg.DB.AutoRepair {
+		return report, err
+	}
+	if err := database.Repair(ctx); err != nil {
+		return nil, err
+	}
+	report.Repaired = true
+	return report, nil
+}
@@ -0,0 +1,59 @@
+package spectrafs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// ErrInvalidName is returned when a new node name is empty or contains a path separator
+var ErrInvalidName = newError(ErrInvalidInput, "invalid node name")
+
+// RenameNode renames a node in place, keeping its ID
+// The paths and parent paths of every descendant are rewritten in the same transaction.
+// Renaming root, empty names, names containing "/", and names that collide with an existing
+// sibling are rejected. Returns the renamed node.
+func (s *SpectraFS) RenameNode(ctx context.Context, req interface {
+	models.NodeIdentifier
+	models.RenameRequest
+}) (*types.Node, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := validateRequest(models.ValidateNodeIdentifier(req)); err != nil {
+		return nil, err
+	}
+	name := req.GetNewName()
+	if strings.TrimSpace(name) == "" || strings.Contains(name, "/") || name == "." || name == ".." {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	node, _, err := s.resolveNodeAndWorld(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve node: %w", err)
+	}
+	if err := s.guardMutation(opRename, node.ID); err != nil {
+		return nil, err
+	}
+
+	renamed, err := s.db.RenameSubtree(node.ID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	event := nodeChange(types.ChangeRename, renamed[0], len(renamed))
+	event.OldPath = node.Path
+	if err := s.journal(event); err != nil {
+		return nil, err
+	}
+	return renamed[0], nil
+}
@@ -0,0 +1,76 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+)
+
+func TestResetUnderConcurrentListing(t *testing.T) {
+	s := newTestFS(t)
+	ctx := context.Background()
+
+	var stop atomic.Bool
+	var listings atomic.Int64
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !stop.Load() {
+				// Walk down from the root, one listing per level, until a level has no folders
+				parentID := s.root
+				for parentID != "" && !stop.Load() {
+					result, err := s.ListChildren(ctx, &models.ListChildrenRequest{ParentID: parentID, TableName: "primary"})
+					switch {
+					case err == nil:
+						listings.Add(1)
+					case parentID != s.root && (errors.Is(err, ErrNodeNotFound) || errors.Is(err, ErrParentNotFound)):
+						// A folder listed before the reset may be gone
+					default:
+						t.Errorf("ListChildren(%s) during resets: %v", parentID, err)
+						return
+					}
+					parentID = ""
+					if result != nil && len(result.Folders) > 0 {
+						parentID = result.Folders[0].ID
+					}
+				}
+			}
+		}()
+	}
+	for range 10 {
+		// Let listings run between resets, so generation is in flight when the next one starts
+		for seen := listings.Load(); listings.Load() < seen+2 && !t.Failed(); {
+			runtime.Gosched()
+		}
+		if err := s.Reset(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stop.Store(true)
+	wg.Wait()
+
+	// No generation from before a reset landed under the new root
+	report, err := s.CheckIntegrity(ctx, false)
+	if err != nil || !report.OK {
+		t.Fatalf("integrity after resets under listing: %+v, %v", report, err)
+	}
+	nodes := storedNodes(t, s)
+	for _, node := range nodes {
+		if node.ID != s.root && nodes[node.ParentID] == nil {
+			t.Errorf("%s is stored without its parent %s", node.Path, node.ParentID)
+		}
+	}
+	if total, _ := storedTotals(t, s); total != int64(len(nodes)-1) {
+		t.Errorf("stats count %d nodes, %d are stored", total, len(nodes)-1)
+	}
+	if result := list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}); len(result.Folders)+len(result.Files) == 0 {
+		t.Error("root lists no children after the resets")
+	}
+}
@@ -0,0 +1,212 @@
+package spectrafs
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// ErrUnknownWorld is returned when an operation names a world that is not configured
+// It is invalid input: the error's message lists the worlds that are (see checkWorld)
+var ErrUnknownWorld = newError(ErrInvalidInput, "unknown world")
+
+// SetClock replaces the clock used to evaluate retention TTLs (nil restores time.Now)
+// Intended for tests that need to cross a TTL boundary deterministically
+func (s *SpectraFS) SetClock(now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+	s.now = now
+}
+
+// retentionExpiry returns when a node expires from a world, if any retention rule matches it
+// When several rules match, the earliest expiry wins. The root never expires.
+func (s *SpectraFS) retentionExpiry(node *types.Node, world string) (time.Time, bool) {
+	if node.ID == s.root {
+		return time.Time{}, false
+	}
+
+	var expiry time.Time
+	matched := false
+	for _, rule := range s.retentionRules()[world] {
+		if !pathHasPrefix(node.Path, rule.PathPrefix) {
+			continue
+		}
+		at := node.LastUpdated.Add(time.Duration(rule.TTLSeconds) * time.Second)
+		if !matched || at.Before(expiry) {
+			expiry = at
+			matched = true
+		}
+	}
+	return expiry, matched
+}
+
+// isRetentionExpired reports whether the clock has passed a node's retention expiry in a world
+func (s *SpectraFS) isRetentionExpired(node *types.Node, world string) bool {
+	expiry, ok := s.retentionExpiry(node, world)
+	return ok && !s.now().Before(expiry)
+}
+
+// applyRetentionView lazily hides a node from every world whose retention rule has expired it
+// The node's existence map is replaced (never mutated) so stored and cached copies are untouched
+func (s *SpectraFS) applyRetentionView(node *types.Node) *types.Node {
+	retention := s.retentionRules()
+	if node == nil || len(retention) == 0 {
+		return node
+	}
+
+	var view map[string]bool
+	for world := range retention {
+		if node.ExistenceMap[world] && s.isRetentionExpired(node, world) {
+			if view == nil {
+				view = maps.Clone(node.ExistenceMap)
+			}
+			view[world] = false
+		}
+	}
+	if view != nil {
+		node.ExistenceMap = view
+	}
+	return node
+}
+
+// filterRetained applies the retention view to nodes and drops those no longer present in world
+func (s *SpectraFS) filterRetained(nodes []*types.Node, world string) []*types.Node {
+	if len(s.retentionRules()[world]) == 0 {
+		return nodes
+	}
+
+	kept := nodes[:0]
+	for _, node := range nodes {
+		if s.applyRetentionView(node).ExistenceMap[world] {
+			kept = append(kept, node)
+		}
+	}
+	return kept
+}
+
+// ApplyRetention persists retention for a world: every node whose rule has expired it has its
+// existence in that world flipped to false, with cause "retention". An expired folder takes its
+// descendants with it (as SetWorldExistence does), so no node is left present under an absent
+// parent. The flips are written in batches, children first, under the write lock, so they are
+// computed from the stored existence maps and never overwrite a concurrent change
+func (s *SpectraFS) ApplyRetention(world string) (*types.RetentionResult, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := s.checkWorld(world); err != nil {
+		return nil, err
+	}
+
+	result := &types.RetentionResult{
+		World:       world,
+		Expirations: make([]types.RetentionExpiration, 0),
+	}
+	if len(s.retentionRules()[world]) == 0 {
+		return result, nil
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	nodes, err := s.db.GetNodesInWorld(world)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parents before children, so each node sees whether an ancestor expired first
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].DepthLevel < nodes[j].DepthLevel
+	})
+
+	now := s.now()
+	expiredRoot := make(map[string]*types.Node) // Expiring node ID -> the node whose own rule expired it
+	rootExpiry := make(map[string]time.Time)    // Those nodes' expiry
+	var roots, expiring []*types.Node
+	for _, node := range nodes {
+		expiration := types.RetentionExpiration{ID: node.ID, Path: node.Path, Cause: types.ExistenceCauseRetention}
+		if root, ok := expiredRoot[node.ParentID]; ok {
+			expiredRoot[node.ID] = root
+			expiration.ExpiredAt = rootExpiry[root.ID]
+			expiration.ExpiredWith = root.Path
+		} else {
+			expiry, matched := s.retentionExpiry(node, world)
+			if !matched || now.Before(expiry) {
+				continue
+			}
+			expiredRoot[node.ID] = node
+			rootExpiry[node.ID] = expiry
+			expiration.ExpiredAt = expiry
+			roots = append(roots, node)
+		}
+		expiring = append(expiring, node)
+		result.Expirations = append(result.Expirations, expiration)
+	}
+
+	// Children first, so an interrupted run never leaves a node present under an absent parent
+	slices.Reverse(expiring)
+	updates := make([]db.ExistenceUpdate, 0, len(expiring))
+	for _, node := range expiring {
+		existenceMap := maps.Clone(node.ExistenceMap)
+		existenceMap[world] = false
+		updates = append(updates, db.ExistenceUpdate{ID: node.ID, ExistenceMap: existenceMap})
+	}
+	updated, updateErr := s.db.UpdateExistenceMaps(updates)
+
+	// Batches already stored are journaled even if a later one fails: one event per expired
+	// subtree, counting its nodes that were flipped
+	flipped := make(map[string]int, len(roots))
+	for _, node := range updated {
+		flipped[expiredRoot[node.ID].ID]++
+	}
+	var changes []change
+	for _, root := range roots {
+		if flipped[root.ID] == 0 {
+			continue
+		}
+		changed := *root
+		changed.ExistenceMap = maps.Clone(root.ExistenceMap)
+		changed.ExistenceMap[world] = false
+		event := nodeChange(types.ChangeExistence, &changed, flipped[root.ID])
+		event.Worlds = []string{world}
+		changes = append(changes, event)
+	}
+
+	if updateErr != nil {
+		return nil, errors.Join(fmt.Errorf("failed to expire nodes: %w", updateErr), s.journal(changes...))
+	}
+
+	sort.Slice(result.Expirations, func(i, j int) bool {
+		return result.Expirations[i].Path < result.Expirations[j].Path
+	})
+	result.Expired = len(result.Expirations)
+
+	if err := s.journal(changes...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// isKnownWorld reports whether world is primary or a configured secondary world
+func (s *SpectraFS) isKnownWorld(world string) bool {
+	if world == "primary" {
+		return true
+	}
+	_, ok := s.secondaryWorlds()[world]
+	return ok
+}
+
+// pathHasPrefix reports whether path equals prefix or lies beneath it
+func pathHasPrefix(path, prefix string) bool {
+	if prefix == "/" || path == prefix {
+		return true
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/")
+}
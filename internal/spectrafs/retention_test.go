@@ -0,0 +1,195 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// retentionT0 is the LastUpdated the retention tests age nodes from
+var retentionT0 = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// newRetentionFS opens an instance where s1 expires nodes an hour after LastUpdated, with the first
+// root folder and its children present in s1. The folder is touched at retentionT0 and everything
+// else listed under the root and under the folder an hour later, so only the folder's own rule
+// expires at T0+1h
+func newRetentionFS(t *testing.T) (s *SpectraFS, folder *types.Node, children []*types.Node, clock *time.Time) {
+	t.Helper()
+	s = newTestFS(t, func(cfg *types.Config) {
+		cfg.Retention = map[string][]types.RetentionRule{"s1": {{PathPrefix: "/", TTLSeconds: 3600}}}
+	})
+	clock = new(time.Time)
+	*clock = retentionT0
+	s.SetClock(func() time.Time { return *clock })
+
+	rootChildren := childNodes(list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}))
+	folder = rootChildren[0]
+	if folder.Type != types.NodeTypeFolder {
+		t.Fatalf("first root child %s is not a folder", folder.Path)
+	}
+	children = childNodes(list(t, s, &models.ListChildrenRequest{ParentID: folder.ID, TableName: "primary"}))
+	if len(children) == 0 {
+		t.Fatalf("%s has no children", folder.Path)
+	}
+	if _, err := s.SetWorldExistence(folder.ID, "s1", true, true); err != nil {
+		t.Fatal(err)
+	}
+
+	touch := func(node *types.Node, at time.Time) {
+		if _, err := s.TouchNode(&models.GetNodeRequest{ID: node.ID}, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, node := range append(rootChildren[1:], children...) {
+		touch(node, retentionT0.Add(time.Hour))
+	}
+	touch(folder, retentionT0)
+	return s, folder, children, clock
+}
+
+// storedExistence reads a node's stored existence in world, bypassing the retention view
+func storedExistence(t *testing.T, s *SpectraFS, id, world string) bool {
+	t.Helper()
+	node, err := s.db.GetNodeByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node.ExistenceMap[world]
+}
+
+// listedIn reports whether id is among parentID's children listed in world
+func listedIn(t *testing.T, s *SpectraFS, parentID, world, id string) bool {
+	t.Helper()
+	result := list(t, s, &models.ListChildrenRequest{ParentID: parentID, TableName: world})
+	return slices.ContainsFunc(childNodes(result), func(n *types.Node) bool { return n.ID == id })
+}
+
+func TestRetentionLazyView(t *testing.T) {
+	s, folder, _, clock := newRetentionFS(t)
+
+	*clock = retentionT0.Add(59 * time.Minute)
+	if !listedIn(t, s, s.root, "s1", folder.ID) {
+		t.Fatal("folder hidden from s1 before its TTL")
+	}
+
+	*clock = retentionT0.Add(time.Hour)
+	if listedIn(t, s, s.root, "s1", folder.ID) {
+		t.Error("folder still listed in s1 once its TTL passed")
+	}
+	if !listedIn(t, s, s.root, "primary", folder.ID) {
+		t.Error("folder hidden from primary, which has no rules")
+	}
+
+	node, err := s.GetNode(context.Background(), &models.GetNodeRequest{ID: folder.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.ExistenceMap["s1"] || !node.ExistenceMap["primary"] {
+		t.Errorf("GetNode existence = %v, want absent from s1 only", node.ExistenceMap)
+	}
+	_, err = s.GetNode(context.Background(), &models.GetNodeRequest{Path: folder.Path, TableName: "s1"})
+	if !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("path lookup in s1 = %v, want ErrNodeNotFound", err)
+	}
+	if !storedExistence(t, s, folder.ID, "s1") {
+		t.Error("lazy evaluation changed the stored existence")
+	}
+}
+
+func TestApplyRetentionCascadesToDescendants(t *testing.T) {
+	s, folder, children, clock := newRetentionFS(t)
+
+	*clock = retentionT0.Add(30 * time.Minute)
+	result, err := s.ApplyRetention("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Expired != 0 {
+		t.Fatalf("expired %d nodes before any TTL passed: %+v", result.Expired, result.Expirations)
+	}
+
+	*clock = retentionT0.Add(90 * time.Minute)
+	result, err = s.ApplyRetention("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Expired != 1+len(children) {
+		t.Fatalf("expired %d nodes, want the folder and its %d children: %+v", result.Expired, len(children), result.Expirations)
+	}
+	for _, expiration := range result.Expirations {
+		if expiration.Cause != types.ExistenceCauseRetention || !expiration.ExpiredAt.Equal(retentionT0.Add(time.Hour)) {
+			t.Errorf("expiration %+v, want cause retention at T0+1h", expiration)
+		}
+		wantWith := folder.Path
+		if expiration.ID == folder.ID {
+			wantWith = ""
+		}
+		if expiration.ExpiredWith != wantWith {
+			t.Errorf("%s expired with %q, want %q", expiration.Path, expiration.ExpiredWith, wantWith)
+		}
+	}
+
+	for _, node := range append([]*types.Node{folder}, children...) {
+		if storedExistence(t, s, node.ID, "s1") {
+			t.Errorf("%s still stored present in s1", node.Path)
+		}
+		if !storedExistence(t, s, node.ID, "primary") {
+			t.Errorf("%s lost its primary existence", node.Path)
+		}
+	}
+
+	// Persisted: turning the clock back does not bring the folder back
+	*clock = retentionT0
+	if listedIn(t, s, s.root, "s1", folder.ID) {
+		t.Error("persisted expiration undone by the clock")
+	}
+
+	result, err = s.ApplyRetention("s1")
+	if err != nil || result.Expired != 0 {
+		t.Errorf("second run expired %+v (err %v), want nothing", result, err)
+	}
+}
+
+func TestApplyRetentionJournalsOneEventPerSubtree(t *testing.T) {
+	s, folder, children, clock := newRetentionFS(t)
+	before := latestChangeSeq(t, s)
+
+	*clock = retentionT0.Add(time.Hour)
+	if _, err := s.ApplyRetention("s1"); err != nil {
+		t.Fatal(err)
+	}
+
+	events := changesSince(t, s, before)
+	if len(events) != 1 {
+		t.Fatalf("journaled %d events, want 1: %+v", len(events), events)
+	}
+	event := events[0]
+	if event.Op != types.ChangeExistence || event.NodeID != folder.ID || event.Nodes != 1+len(children) {
+		t.Errorf("event %+v, want an existence change of %s covering %d nodes", event, folder.Path, 1+len(children))
+	}
+	if !slices.Equal(event.Worlds, []string{"s1"}) || event.ExistenceMap["s1"] {
+		t.Errorf("event worlds %v, existence %v", event.Worlds, event.ExistenceMap)
+	}
+}
+
+func TestApplyRetentionPrimaryUnaffected(t *testing.T) {
+	s, folder, _, clock := newRetentionFS(t)
+
+	*clock = retentionT0.Add(24 * time.Hour)
+	result, err := s.ApplyRetention("primary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Expired != 0 || !storedExistence(t, s, folder.ID, "primary") {
+		t.Errorf("primary retention expired %+v", result)
+	}
+
+	if _, err := s.ApplyRetention("nope"); !errors.Is(err, ErrUnknownWorld) {
+		t.Errorf("unknown world error = %v, want ErrUnknownWorld", err)
+	}
+}
@@ -0,0 +1,65 @@
+package spectrafs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/generator"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// ErrRootProtected is returned when a mutating operation targets the root node
+var ErrRootProtected = errors.New("root node is protected")
+
+// Mutating operations checked by guardMutation (used in its error message)
+const (
+	opDelete          = "delete"
+	opMove            = "move"
+	opRename          = "rename"
+	opUpdateExistence = "change existence of"
+)
+
+// isRoot reports whether id is the canonical root ID
+func (s *SpectraFS) isRoot(id string) bool {
+	return id == s.root
+}
+
+// guardMutation is the single root-protection check: every operation that changes, removes or
+// re-parents a stored node calls it with the target's ID before touching the database
+func (s *SpectraFS) guardMutation(op, id string) error {
+	if s.isRoot(id) {
+		return fmt.Errorf("%w: cannot %s root", ErrRootProtected, op)
+	}
+	return nil
+}
+
+// presentRoot applies the cosmetic root_display_name to a node about to be returned to a caller
+// Only the Name field changes, and only for root; IDs and paths stay canonical
+func (s *SpectraFS) presentRoot(node *types.Node) *types.Node {
+	if node != nil && s.isRoot(node.ID) && s.config().RootDisplayName != "" {
+		node.Name = s.config().RootDisplayName
+	}
+	return node
+}
+
+// stampRoot sets the root's LastUpdated to seed.base_timestamp if it differs, so a fresh or reset
+// root is as reproducible as the generated nodes (roots created before base timestamps are updated too)
+func stampRoot(database *db.DB, cfg *types.Config) error {
+	if database.ReadOnly() {
+		return nil // The root keeps the timestamp it was stored with
+	}
+	root, err := database.GetNodeByID("root")
+	if err != nil {
+		return fmt.Errorf("failed to get root node: %w", err)
+	}
+
+	base := generator.BaseTimestamp(cfg)
+	if root.LastUpdated.Equal(base) {
+		return nil
+	}
+	if _, err := database.TouchNode("root", base); err != nil {
+		return fmt.Errorf("failed to stamp root node: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestRootProtected(t *testing.T) {
+	s := newTestFS(t, func(cfg *types.Config) {
+		cfg.SecondaryTables = map[string]float64{"s1": 0.5}
+	})
+	ctx := context.Background()
+	folder := mkdir(t, s, s.root, "target")
+
+	for _, tc := range []struct {
+		name string
+		op   func() error
+	}{
+		{"delete by ID", func() error {
+			return s.DeleteNode(ctx, &models.DeleteNodeRequest{ID: s.root, Recursive: true})
+		}},
+		{"delete by path", func() error {
+			return s.DeleteNode(ctx, &models.DeleteNodeRequest{Path: "/", TableName: "primary", Recursive: true})
+		}},
+		{"move", func() error {
+			_, err := s.MoveNode(ctx, &models.MoveNodeRequest{ID: s.root, NewParentID: folder.ID})
+			return err
+		}},
+		{"move by path", func() error {
+			_, err := s.MoveNode(ctx, &models.MoveNodeRequest{Path: "/", TableName: "primary", NewParentPath: folder.Path})
+			return err
+		}},
+		{"rename", func() error {
+			_, err := s.RenameNode(ctx, &models.RenameNodeRequest{ID: s.root, NewName: "renamed"})
+			return err
+		}},
+		{"remove from world", func() error {
+			_, err := s.SetWorldExistence(s.root, "s1", false, true)
+			return err
+		}},
+		{"add to world", func() error {
+			_, err := s.SetWorldExistence(s.root, "s1", true, false)
+			return err
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.op(); !errors.Is(err, ErrRootProtected) {
+				t.Errorf("error = %v, want ErrRootProtected", err)
+			}
+		})
+	}
+
+	root, err := s.GetNode(ctx, &models.GetNodeRequest{ID: s.root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Path != "/" || root.ParentID != "" || !root.ExistenceMap["s1"] {
+		t.Errorf("root changed: %+v", root)
+	}
+	result, err := s.DeleteNodes(ctx, []string{s.root}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := statuses(result)[s.root]; status != types.DeleteStatusSkippedRoot {
+		t.Errorf("batch delete of root = %s, want %s", status, types.DeleteStatusSkippedRoot)
+	}
+}
+
+func TestRootDisplayNameIsCosmetic(t *testing.T) {
+	s := newTestFS(t, func(cfg *types.Config) {
+		cfg.RootDisplayName = "Drive"
+	})
+	ctx := context.Background()
+
+	for _, req := range []*models.GetNodeRequest{{ID: s.root}, {Path: "/", TableName: "primary"}} {
+		root, err := s.GetNode(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if root.Name != "Drive" || root.ID != s.root || root.Path != "/" {
+			t.Errorf("GetNode(%+v) = name %q, ID %s, path %s", req, root.Name, root.ID, root.Path)
+		}
+	}
+
+	child := mkdir(t, s, s.root, "child")
+	if child.ParentPath != "/" || child.Path != "/child" {
+		t.Errorf("child of a renamed root at %s (parent %s)", child.Path, child.ParentPath)
+	}
+	if _, err := s.GetNode(ctx, &models.GetNodeRequest{Path: "/Drive", TableName: "primary"}); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("display name resolved as a path: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package spectrafs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// ErrInvalidScanOptions is returned for a relative path prefix or a negative batch size
+var ErrInvalidScanOptions = newError(ErrInvalidInput, "invalid scan options")
+
+// ForEachNode calls fn for every stored node that passes opts, without listing folder by folder:
+// opts.World keeps the nodes stored as existing in that world, and opts.PathPrefix those at or below
+// a path (read through the path index, in path order; otherwise nodes come in ID order). Nodes are
+// read in batches of opts.BatchSize, and no transaction is held while fn runs, so fn may be slow or
+// write; nodes changed between batches may or may not be visited. opts.Snapshot reads every node on
+// one snapshot instead, for callers that must not see a torn tree, and then fn must not write.
+// Nodes are visited as stored: retention views are not applied and nothing is generated. The first
+// error fn returns stops the scan and is returned as is; ctx.Err() is returned if ctx is cancelled
+func (s *SpectraFS) ForEachNode(ctx context.Context, opts types.NodeScanOptions, fn func(node *types.Node) error) error {
+	if opts.World != "" {
+		if err := s.checkWorld(opts.World); err != nil {
+			return err
+		}
+	}
+	if opts.PathPrefix != "" {
+		if !strings.HasPrefix(opts.PathPrefix, "/") {
+			return fmt.Errorf("%w: path prefix must start with /", ErrInvalidScanOptions)
+		}
+		if opts.PathPrefix = strings.TrimRight(opts.PathPrefix, "/"); opts.PathPrefix == "" {
+			opts.PathPrefix = "/"
+		}
+	}
+	if opts.BatchSize < 0 {
+		return fmt.Errorf("%w: batch size must be non-negative, got %d", ErrInvalidScanOptions, opts.BatchSize)
+	}
+
+	return s.db.ForEachNode(ctx, opts, fn)
+}
@@ -0,0 +1,159 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// scan runs ForEachNode with opts and returns the visited nodes in visit order
+func scan(t *testing.T, s *SpectraFS, opts types.NodeScanOptions) []*types.Node {
+	t.Helper()
+	var nodes []*types.Node
+	err := s.ForEachNode(context.Background(), opts, func(node *types.Node) error {
+		nodes = append(nodes, node)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachNode(%+v): %v", opts, err)
+	}
+	return nodes
+}
+
+func TestForEachNodeVisitsEveryNodeOnce(t *testing.T) {
+	s := generatedFS(t)
+	want := len(storedNodes(t, s))
+
+	for _, opts := range []types.NodeScanOptions{{}, {BatchSize: 1}, {BatchSize: 7}, {Snapshot: true}} {
+		nodes := scan(t, s, opts)
+		seen := make(map[string]bool)
+		for i, node := range nodes {
+			if seen[node.ID] {
+				t.Fatalf("%+v visited %s twice", opts, node.Path)
+			}
+			seen[node.ID] = true
+			if i > 0 && node.ID < nodes[i-1].ID {
+				t.Errorf("%+v visited %s before %s, out of ID order", opts, nodes[i-1].ID, node.ID)
+			}
+		}
+		if len(nodes) != want {
+			t.Errorf("%+v visited %d nodes, want %d", opts, len(nodes), want)
+		}
+	}
+}
+
+func TestForEachNodeFilters(t *testing.T) {
+	s := generatedFS(t)
+	all := scan(t, s, types.NodeScanOptions{})
+
+	// The world filter keeps exactly the nodes stored as existing in it
+	var want []string
+	for _, node := range all {
+		if node.ExistenceMap["s1"] {
+			want = append(want, node.ID)
+		}
+	}
+	var got []string
+	for _, node := range scan(t, s, types.NodeScanOptions{World: "s1", BatchSize: 2}) {
+		got = append(got, node.ID)
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("world s1 visited %d nodes, want %d", len(got), len(want))
+	}
+	if len(want) == len(all) {
+		t.Fatalf("every node exists in s1, so the world filter is not exercised")
+	}
+
+	// The path prefix keeps the node at the path and those below it, in path order, but not
+	// siblings whose names merely start with the same characters
+	below := mkdir(t, s, mkdir(t, s, "root", "scan").ID, "below")
+	upload(t, s, below.ID, "file.txt", []byte("x"))
+	mkdir(t, s, "root", "scanner")
+	for _, prefix := range []string{"/scan", "/scan/"} {
+		var paths []string
+		for _, node := range scan(t, s, types.NodeScanOptions{PathPrefix: prefix, BatchSize: 1}) {
+			paths = append(paths, node.Path)
+		}
+		if got := strings.Join(paths, ","); got != "/scan,/scan/below,/scan/below/file.txt" {
+			t.Errorf("prefix %q visited %s", prefix, got)
+		}
+	}
+
+	var paths []string
+	for _, node := range scan(t, s, types.NodeScanOptions{PathPrefix: "/"}) {
+		paths = append(paths, node.Path)
+	}
+	if len(paths) != len(all)+4 || !sort.StringsAreSorted(paths) {
+		t.Errorf("prefix / visited %d nodes (sorted %v), want all %d in path order", len(paths), sort.StringsAreSorted(paths), len(all)+4)
+	}
+}
+
+func TestForEachNodeStops(t *testing.T) {
+	s := generatedFS(t)
+
+	// The first error from fn ends the scan and is returned as is
+	errStop := errors.New("stop")
+	visited := 0
+	err := s.ForEachNode(context.Background(), types.NodeScanOptions{BatchSize: 2}, func(*types.Node) error {
+		if visited++; visited == 3 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop || visited != 3 {
+		t.Errorf("ForEachNode after fn failed = %v after %d nodes, want errStop after 3", err, visited)
+	}
+
+	// Cancelling the context ends the scan with ctx.Err()
+	ctx, cancel := context.WithCancel(context.Background())
+	visited = 0
+	err = s.ForEachNode(ctx, types.NodeScanOptions{BatchSize: 2}, func(*types.Node) error {
+		if visited++; visited == 3 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || visited > 4 {
+		t.Errorf("ForEachNode after cancel = %v after %d nodes, want context.Canceled within the batch", err, visited)
+	}
+}
+
+func TestForEachNodeAllowsWritesBetweenBatches(t *testing.T) {
+	s := generatedFS(t)
+	before := len(scan(t, s, types.NodeScanOptions{}))
+
+	// fn may write when the scan is not a snapshot: no transaction is held while it runs
+	written := 0
+	err := s.ForEachNode(context.Background(), types.NodeScanOptions{BatchSize: 1}, func(*types.Node) error {
+		if written < 3 {
+			written++
+			mkdir(t, s, "root", fmt.Sprintf("during%d", written))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after := len(scan(t, s, types.NodeScanOptions{})); after != before+3 {
+		t.Errorf("scan with writes left %d nodes, want %d", after, before+3)
+	}
+}
+
+func TestForEachNodeRejectsInvalidOptions(t *testing.T) {
+	s := newTestFS(t)
+	for _, opts := range []types.NodeScanOptions{{PathPrefix: "scan"}, {BatchSize: -1}} {
+		err := s.ForEachNode(context.Background(), opts, func(*types.Node) error { return nil })
+		if !errors.Is(err, ErrInvalidScanOptions) || !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("ForEachNode(%+v) = %v, want ErrInvalidScanOptions", opts, err)
+		}
+	}
+	err := s.ForEachNode(context.Background(), types.NodeScanOptions{World: "nope"}, func(*types.Node) error { return nil })
+	if !errors.Is(err, ErrUnknownWorld) {
+		t.Errorf("ForEachNode in an unknown world = %v, want ErrUnknownWorld", err)
+	}
+}
@@ -0,0 +1,274 @@
+package spectrafs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// metaTaskPrefix prefixes the meta keys holding each maintenance task's last-run status
+const metaTaskPrefix = "maintenance_task/"
+
+// maintenanceJitter is the largest fraction of an interval added at random to each wait,
+// so tasks sharing an interval do not fire in lockstep
+const maintenanceJitter = 0.1
+
+// maintenanceTasks maps each schedulable task to its implementation
+var maintenanceTasks = map[string]func(*SpectraFS) error{
+	types.MaintenanceTaskApplyRetention:  (*SpectraFS).applyAllRetention,
+	types.MaintenanceTaskRebuildStats:    (*SpectraFS).rebuildStats,
+	types.MaintenanceTaskCompact:         (*SpectraFS).compactTask,
+	types.MaintenanceTaskPruneJournal:    (*SpectraFS).pruneJournal,
+	types.MaintenanceTaskSnapshotCleanup: (*SpectraFS).cleanupSnapshots,
+}
+
+// maintenanceTimer starts a timer that fires once after d, returning its channel and a function
+// that stops it
+type maintenanceTimer func(d time.Duration) (<-chan time.Time, func() bool)
+
+// systemTimer is the maintenanceTimer backed by time.NewTimer
+func systemTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	timer := time.NewTimer(d)
+	return timer.C, timer.Stop
+}
+
+// maintenanceScheduler runs the configured maintenance tasks in the background
+type maintenanceScheduler struct {
+	stop chan struct{}
+	wg   sync.WaitGroup
+
+	mu   sync.Mutex
+	next map[string]time.Time // Next planned run per task
+}
+
+// StartMaintenance starts the background scheduler for the tasks in maintenance_schedule
+// It is a no-op when the schedule is empty; the scheduler stops in Close
+func (s *SpectraFS) StartMaintenance() error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	s.schedMu.Lock()
+	defer s.schedMu.Unlock()
+
+	if s.scheduler != nil {
+		return fmt.Errorf("%w: maintenance scheduler is already running", ErrConflict)
+	}
+	if len(s.config().MaintenanceSchedule) == 0 {
+		return nil
+	}
+
+	sched := &maintenanceScheduler{
+		stop: make(chan struct{}),
+		next: make(map[string]time.Time),
+	}
+	for _, task := range sortedKeys(s.config().MaintenanceSchedule) {
+		interval, err := time.ParseDuration(s.config().MaintenanceSchedule[task])
+		if err != nil {
+			return fmt.Errorf("invalid interval for maintenance task %s: %w", task, err)
+		}
+		sched.wg.Add(1)
+		go s.runSchedule(sched, task, interval)
+	}
+
+	s.scheduler = sched
+	return nil
+}
+
+// stopMaintenance stops the scheduler and waits for any running task to finish
+func (s *SpectraFS) stopMaintenance() {
+	s.schedMu.Lock()
+	sched := s.scheduler
+	s.scheduler = nil
+	s.schedMu.Unlock()
+
+	if sched != nil {
+		close(sched.stop)
+		sched.wg.Wait()
+	}
+}
+
+// runSchedule runs one task every interval (plus jitter) until the scheduler stops
+func (s *SpectraFS) runSchedule(sched *maintenanceScheduler, task string, interval time.Duration) {
+	defer sched.wg.Done()
+
+	for {
+		wait := interval + time.Duration(rand.Int63n(int64(float64(interval)*maintenanceJitter)+1))
+		sched.mu.Lock()
+		sched.next[task] = s.now().Add(wait)
+		sched.mu.Unlock()
+
+		fired, stop := s.newTimer(wait)
+		select {
+		case <-sched.stop:
+			stop()
+			return
+		case <-fired:
+		}
+
+		// Failures are recorded in the task status; the schedule keeps going
+		_, _ = s.RunMaintenanceTask(task)
+	}
+}
+
+// RunMaintenanceTask runs one maintenance task now and records its status in the meta bucket
+// The run is skipped (status "skipped") while an exclusive operation such as Reset or Clone is active
+func (s *SpectraFS) RunMaintenanceTask(task string) (*types.MaintenanceTaskStatus, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	run, ok := maintenanceTasks[task]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown maintenance task %s", ErrInvalidInput, task)
+	}
+
+	status, err := s.loadTaskStatus(task)
+	if err != nil {
+		return nil, err
+	}
+
+	started := s.now()
+	status.LastRunAt = &started
+	status.LastMessage = ""
+	status.DurationMillis = 0
+
+	if !s.exclusive.TryLock() {
+		status.LastStatus = types.MaintenanceStatusSkipped
+		status.LastMessage = "an exclusive operation is in progress"
+		status.Skips++
+	} else {
+		runErr := run(s)
+		s.exclusive.Unlock()
+
+		status.DurationMillis = s.now().Sub(started).Milliseconds()
+		status.Runs++
+		status.LastStatus = types.MaintenanceStatusOK
+		if runErr != nil {
+			status.LastStatus = types.MaintenanceStatusFailed
+			status.LastMessage = runErr.Error()
+		}
+	}
+
+	if err := s.storeTaskStatus(status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// MaintenanceSchedule reports every scheduled task with its last-run status
+func (s *SpectraFS) MaintenanceSchedule() (*types.MaintenanceSchedule, error) {
+	s.schedMu.Lock()
+	sched := s.scheduler
+	s.schedMu.Unlock()
+
+	report := &types.MaintenanceSchedule{
+		Running: sched != nil,
+		Tasks:   make([]types.MaintenanceTaskStatus, 0, len(s.config().MaintenanceSchedule)),
+	}
+	for _, task := range sortedKeys(s.config().MaintenanceSchedule) {
+		status, err := s.loadTaskStatus(task)
+		if err != nil {
+			return nil, err
+		}
+		if sched != nil {
+			sched.mu.Lock()
+			if next, ok := sched.next[task]; ok {
+				status.NextRunAt = &next
+			}
+			sched.mu.Unlock()
+		}
+		report.Tasks = append(report.Tasks, *status)
+	}
+	return report, nil
+}
+
+// loadTaskStatus reads a task's stored status, or a fresh one if it never ran
+func (s *SpectraFS) loadTaskStatus(task string) (*types.MaintenanceTaskStatus, error) {
+	status := &types.MaintenanceTaskStatus{}
+	data, err := s.db.GetMeta(metaTaskPrefix + task)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		if err := json.Unmarshal(data, status); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal status of maintenance task %s: %w", task, err)
+		}
+	}
+
+	status.Task = task
+	status.Interval = s.config().MaintenanceSchedule[task]
+	status.NextRunAt = nil
+	return status, nil
+}
+
+// storeTaskStatus writes a task's status to the meta bucket
+func (s *SpectraFS) storeTaskStatus(status *types.MaintenanceTaskStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to marshal status of maintenance task %s: %w", status.Task, err)
+	}
+	return s.db.PutMeta(metaTaskPrefix+status.Task, data)
+}
+
+// applyAllRetention persists retention for every world that has rules
+func (s *SpectraFS) applyAllRetention() error {
+	for _, world := range sortedKeys(s.retentionRules()) {
+		if _, err := s.ApplyRetention(world); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildStats recomputes the stats and coverage counters from the stored nodes
+func (s *SpectraFS) rebuildStats() error {
+	return s.db.RebuildStats()
+}
+
+// compactTask compacts the database file; the scheduler already holds exclusive, so unlike
+// Compact it only waits for writes
+func (s *SpectraFS) compactTask() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	_, err := s.db.Compact("")
+	return err
+}
+
+// pruneJournal prunes the change journal to the configured length
+func (s *SpectraFS) pruneJournal() error {
+	return s.db.PruneChanges(s.journalMaxEntries())
+}
+
+// cleanupSnapshots deletes all but the db.snapshot_keep newest named snapshots (none when it is 0)
+func (s *SpectraFS) cleanupSnapshots() error {
+	keep := s.config().DB.SnapshotKeep
+	if keep <= 0 {
+		return nil
+	}
+	snapshots, err := s.db.ListSnapshots()
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool { return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt) })
+	for _, snapshot := range snapshots[min(keep, len(snapshots)):] {
+		if err := s.db.DeleteSnapshot(snapshot.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns a map's keys in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
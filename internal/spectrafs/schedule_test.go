@@ -0,0 +1,206 @@
+package spectrafs
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// scheduleT0 is the time the scheduler tests' clock stands at
+var scheduleT0 = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeTimer is one wait the scheduler started; sending on fire ends it
+type fakeTimer struct {
+	wait time.Duration
+	fire chan time.Time
+}
+
+// fakeTimers replaces the scheduler's timers: every wait it starts is sent on armed, and only
+// fires when the test fires it
+type fakeTimers struct {
+	armed chan fakeTimer
+}
+
+func (f *fakeTimers) start(d time.Duration) (<-chan time.Time, func() bool) {
+	timer := fakeTimer{wait: d, fire: make(chan time.Time, 1)}
+	f.armed <- timer
+	return timer.fire, func() bool { return true }
+}
+
+// next waits for the scheduler to start its next wait, which it does after finishing a run
+func (f *fakeTimers) next(t *testing.T) fakeTimer {
+	t.Helper()
+	select {
+	case timer := <-f.armed:
+		return timer
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduler started no wait")
+		return fakeTimer{}
+	}
+}
+
+// newScheduledFS opens an instance scheduling rebuild-stats hourly, on a fixed clock and fake
+// timers, with the scheduler started
+func newScheduledFS(t *testing.T) (*SpectraFS, *fakeTimers) {
+	t.Helper()
+	s := newTestFS(t, func(cfg *types.Config) {
+		cfg.MaintenanceSchedule = map[string]string{types.MaintenanceTaskRebuildStats: "1h"}
+	})
+	s.SetClock(func() time.Time { return scheduleT0 })
+	timers := &fakeTimers{armed: make(chan fakeTimer, 4)}
+	s.newTimer = timers.start
+	if err := s.StartMaintenance(); err != nil {
+		t.Fatal(err)
+	}
+	return s, timers
+}
+
+// taskStatus returns the scheduled task's entry in the schedule report
+func taskStatus(t *testing.T, s *SpectraFS, task string) types.MaintenanceTaskStatus {
+	t.Helper()
+	report, err := s.MaintenanceSchedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := slices.IndexFunc(report.Tasks, func(status types.MaintenanceTaskStatus) bool { return status.Task == task })
+	if i < 0 {
+		t.Fatalf("task %s not in the schedule %+v", task, report.Tasks)
+	}
+	return report.Tasks[i]
+}
+
+func TestMaintenanceSchedulerRunsWhenTimerFires(t *testing.T) {
+	s, timers := newScheduledFS(t)
+
+	timer := timers.next(t)
+	if timer.wait < time.Hour || timer.wait > time.Hour+6*time.Minute {
+		t.Fatalf("wait %s, want 1h plus at most 10%% jitter", timer.wait)
+	}
+	status := taskStatus(t, s, types.MaintenanceTaskRebuildStats)
+	if status.NextRunAt == nil || !status.NextRunAt.Equal(scheduleT0.Add(timer.wait)) {
+		t.Errorf("next run at %v, want %v", status.NextRunAt, scheduleT0.Add(timer.wait))
+	}
+	if status.Runs != 0 || status.LastRunAt != nil {
+		t.Fatalf("task ran before its timer fired: %+v", status)
+	}
+
+	timer.fire <- scheduleT0
+	timers.next(t)
+	status = taskStatus(t, s, types.MaintenanceTaskRebuildStats)
+	if status.Runs != 1 || status.LastStatus != types.MaintenanceStatusOK {
+		t.Errorf("status after one firing %+v, want one ok run", status)
+	}
+	if status.LastRunAt == nil || !status.LastRunAt.Equal(scheduleT0) {
+		t.Errorf("last run at %v, want the clock's %v", status.LastRunAt, scheduleT0)
+	}
+}
+
+func TestMaintenanceSchedulerSkipsDuringExclusiveOperation(t *testing.T) {
+	s, timers := newScheduledFS(t)
+
+	s.exclusive.Lock()
+	timers.next(t).fire <- scheduleT0
+	timers.next(t)
+	s.exclusive.Unlock()
+
+	status := taskStatus(t, s, types.MaintenanceTaskRebuildStats)
+	if status.LastStatus != types.MaintenanceStatusSkipped || status.Skips != 1 || status.Runs != 0 {
+		t.Errorf("status %+v, want one skipped run", status)
+	}
+}
+
+func TestMaintenanceSchedulerStops(t *testing.T) {
+	s, timers := newScheduledFS(t)
+	timers.next(t)
+
+	s.stopMaintenance()
+	report, err := s.MaintenanceSchedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Running || report.Tasks[0].NextRunAt != nil {
+		t.Errorf("schedule after stopping %+v, want stopped with no next run", report)
+	}
+	if err := s.StartMaintenance(); err != nil {
+		t.Errorf("restart after stopping: %v", err)
+	}
+}
+
+func TestMaintenanceTaskCompact(t *testing.T) {
+	s := newTestFS(t, onDisk(t))
+	status, err := s.RunMaintenanceTask(types.MaintenanceTaskCompact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.LastStatus != types.MaintenanceStatusOK {
+		t.Errorf("compact on disk: %+v", status)
+	}
+
+	memory := newTestFS(t, func(cfg *types.Config) { cfg.Seed.DBPath = db.MemoryPath })
+	status, err = memory.RunMaintenanceTask(types.MaintenanceTaskCompact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.LastStatus != types.MaintenanceStatusFailed || !strings.Contains(status.LastMessage, ErrCompactUnsupported.Error()) {
+		t.Errorf("compact in memory: %+v, want failed as unsupported", status)
+	}
+}
+
+func TestMaintenanceTaskPruneJournal(t *testing.T) {
+	dir := onDisk(t)
+	s := newTestFS(t, dir)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		mkdir(t, s, s.root, name)
+	}
+	latest := latestChangeSeq(t, s)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The lowered cap applies on the next append; the task applies it now
+	s = newTestFS(t, dir, func(cfg *types.Config) { cfg.DB.JournalMaxEntries = 2 })
+	if len(changesSince(t, s, 0)) <= 2 {
+		t.Fatal("journal already pruned on open")
+	}
+	if _, err := s.RunMaintenanceTask(types.MaintenanceTaskPruneJournal); err != nil {
+		t.Fatal(err)
+	}
+	events := changesSince(t, s, 0)
+	if len(events) != 2 || events[1].Seq != latest {
+		t.Errorf("journal after pruning %+v, want the 2 newest events ending at %d", events, latest)
+	}
+}
+
+func TestMaintenanceTaskSnapshotCleanup(t *testing.T) {
+	s := newTestFS(t, func(cfg *types.Config) { cfg.DB.SnapshotKeep = 2 })
+	clock := scheduleT0
+	s.SetClock(func() time.Time { return clock })
+
+	// Named against their age, so the cleanup cannot be going by name
+	for _, name := range []string{"c", "b", "a"} {
+		if _, err := s.SaveSnapshot(context.Background(), name); err != nil {
+			t.Fatal(err)
+		}
+		clock = clock.Add(time.Minute)
+	}
+	if _, err := s.RunMaintenanceTask(types.MaintenanceTaskSnapshotCleanup); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, err := s.ListSnapshots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, snapshot := range snapshots {
+		names = append(names, snapshot.Name)
+	}
+	if !slices.Equal(names, []string{"a", "b"}) {
+		t.Errorf("snapshots after cleanup %v, want the 2 newest [a b]", names)
+	}
+}
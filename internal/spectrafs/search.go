@@ -0,0 +1,120 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// DefaultSearchLimit caps a search whose request leaves Limit unset
+const DefaultSearchLimit = 1000
+
+// ErrInvalidSearch is returned for a malformed glob, path prefix, type, size range or metadata pattern
+var ErrInvalidSearch = newError(ErrInvalidInput, "invalid search")
+
+// errSearchFull stops the index scan once the result is full
+var errSearchFull = errors.New("search result full")
+
+// Search finds stored nodes under a path prefix that match a name glob, type, size range and metadata patterns
+// Only materialized nodes are searched (the result says so): nothing is generated, so it has no
+// side effects and never expands the tree. Nodes are matched as listings see them, so
+// retention-expired nodes are absent. Results are in path order; Truncated is set when Limit stopped it
+func (s *SpectraFS) Search(ctx context.Context, req *models.SearchRequest) (*types.SearchResult, error) {
+	world, prefix, limit, err := s.validateSearch(req)
+	if err != nil {
+		return nil, err
+	}
+
+	match := func(p string) bool {
+		if req.NameGlob == "" {
+			return true
+		}
+		ok, _ := path.Match(req.NameGlob, path.Base(p))
+		return ok
+	}
+
+	result := &types.SearchResult{Nodes: make([]*types.Node, 0), MaterializedOnly: true}
+	err = s.db.SearchPaths(ctx, prefix, match, func(node *types.Node) (bool, error) {
+		if req.Type != "" && node.Type != req.Type {
+			return true, nil
+		}
+		if node.Size < req.MinSize || (req.MaxSize > 0 && node.Size > req.MaxSize) {
+			return true, nil
+		}
+		if !matchMetadata(node, req.Metadata) {
+			return true, nil
+		}
+		if !s.applyRetentionView(node).ExistenceMap[world] {
+			return true, nil
+		}
+		if len(result.Nodes) == limit {
+			return false, errSearchFull
+		}
+		result.Nodes = append(result.Nodes, node)
+		return true, nil
+	})
+	if errors.Is(err, errSearchFull) {
+		result.Truncated = true
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// validateSearch checks a search request and returns its world, normalized path prefix and limit
+func (s *SpectraFS) validateSearch(req *models.SearchRequest) (string, string, int, error) {
+	world := req.World
+	if world == "" {
+		world = "primary"
+	}
+	if err := s.checkWorld(world); err != nil {
+		return "", "", 0, err
+	}
+
+	prefix := req.PathPrefix
+	if prefix == "" {
+		prefix = "/"
+	}
+	if !strings.HasPrefix(prefix, "/") {
+		return "", "", 0, fmt.Errorf("%w: path_prefix must start with /", ErrInvalidSearch)
+	}
+	if prefix != "/" {
+		prefix = strings.TrimRight(prefix, "/")
+		if prefix == "" {
+			prefix = "/"
+		}
+	}
+
+	if req.NameGlob != "" {
+		if _, err := path.Match(req.NameGlob, ""); err != nil {
+			return "", "", 0, fmt.Errorf("%w: name_glob %q: %v", ErrInvalidSearch, req.NameGlob, err)
+		}
+	}
+	if req.Type != "" && req.Type != types.NodeTypeFile && req.Type != types.NodeTypeFolder && req.Type != types.NodeTypeSymlink {
+		return "", "", 0, fmt.Errorf("%w: type must be %q, %q or %q", ErrInvalidSearch, types.NodeTypeFile, types.NodeTypeFolder, types.NodeTypeSymlink)
+	}
+	for key, pattern := range req.Metadata {
+		if _, err := path.Match(pattern, ""); key == "" || err != nil {
+			return "", "", 0, fmt.Errorf("%w: metadata %q: key must not be empty and value must be a valid pattern", ErrInvalidSearch, key)
+		}
+	}
+	if req.MinSize < 0 || req.MaxSize < 0 || (req.MaxSize > 0 && req.MinSize > req.MaxSize) {
+		return "", "", 0, fmt.Errorf("%w: min_size and max_size must be non-negative with min_size <= max_size", ErrInvalidSearch)
+	}
+	if req.Limit < 0 {
+		return "", "", 0, fmt.Errorf("%w: limit must be non-negative", ErrInvalidSearch)
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = DefaultSearchLimit
+	}
+	return world, prefix, limit, nil
+}
@@ -0,0 +1,90 @@
+package spectrafs
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// search runs req, failing the test on error, and returns the matched paths
+func search(t *testing.T, s *SpectraFS, req *models.SearchRequest) ([]string, *types.SearchResult) {
+	t.Helper()
+	result, err := s.Search(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Search(%+v): %v", req, err)
+	}
+	var paths []string
+	for _, node := range result.Nodes {
+		paths = append(paths, node.Path)
+	}
+	return paths, result
+}
+
+func TestSearchPrefixMatchesWholeComponents(t *testing.T) {
+	s := newTestFS(t)
+	a := mkdir(t, s, s.root, "a")
+	ab := mkdir(t, s, s.root, "ab")
+	x := upload(t, s, a.ID, "x.txt", []byte("x"))
+	upload(t, s, a.ID, "z.bin", []byte("zz"))
+	upload(t, s, ab.ID, "y.txt", []byte("y"))
+
+	paths, _ := search(t, s, &models.SearchRequest{PathPrefix: "/a"})
+	if slices.ContainsFunc(paths, func(p string) bool { return strings.HasPrefix(p, "/ab") }) {
+		t.Errorf("prefix /a matched under /ab: %v", paths)
+	}
+	if !slices.Contains(paths, "/a/x.txt") || !slices.Contains(paths, "/a/z.bin") {
+		t.Errorf("prefix /a = %v, want both files under /a", paths)
+	}
+
+	paths, _ = search(t, s, &models.SearchRequest{PathPrefix: "/a/", NameGlob: "*.txt"})
+	if !slices.Equal(paths, []string{"/a/x.txt"}) {
+		t.Errorf("*.txt under /a/ = %v, want [/a/x.txt]", paths)
+	}
+
+	paths, _ = search(t, s, &models.SearchRequest{NameGlob: "*.txt", Type: types.NodeTypeFile, MinSize: x.Size, MaxSize: x.Size})
+	if !slices.Equal(paths, []string{"/a/x.txt", "/ab/y.txt"}) {
+		t.Errorf("*.txt files of %d bytes = %v, want both", x.Size, paths)
+	}
+	if paths, _ = search(t, s, &models.SearchRequest{NameGlob: "*.txt", MaxSize: x.Size - 1}); len(paths) != 0 {
+		t.Errorf("*.txt files under %d bytes = %v, want none", x.Size, paths)
+	}
+}
+
+func TestSearchOnlyMaterializedNodes(t *testing.T) {
+	s := newTestFS(t)
+	before, err := s.db.GetNodeCount(context.Background(), "primary")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths, result := search(t, s, &models.SearchRequest{})
+	if !result.MaterializedOnly || len(paths) > before {
+		t.Errorf("search of an unexpanded tree found %v (materialized only %v)", paths, result.MaterializedOnly)
+	}
+	after, err := s.db.GetNodeCount(context.Background(), "primary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after != before {
+		t.Errorf("search generated nodes: %d before, %d after", before, after)
+	}
+}
+
+func TestSearchLimitTruncates(t *testing.T) {
+	s := newTestFS(t)
+	if _, err := s.GenerateAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, result := search(t, s, &models.SearchRequest{Limit: 2})
+	if len(paths) != 2 || !result.Truncated {
+		t.Errorf("limit 2 returned %v, truncated %v", paths, result.Truncated)
+	}
+	if !slices.IsSorted(paths) {
+		t.Errorf("results out of path order: %v", paths)
+	}
+}
@@ -0,0 +1,171 @@
+package spectrafs
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// ErrUnknownFormat is returned by Export for a format other than jsonl or json
+var ErrUnknownFormat = newError(ErrInvalidInput, "unknown snapshot format")
+
+// ErrInvalidSnapshot is returned by Import for malformed input, unknown worlds or out-of-order nodes
+var ErrInvalidSnapshot = db.ErrInvalidSnapshot
+
+// ErrDatabaseNotEmpty is returned by Import without merge when nodes besides the root are stored
+var ErrDatabaseNotEmpty = db.ErrDatabaseNotEmpty
+
+// Export writes every stored node to w, ordered by depth then path so parents precede children
+// format is types.SnapshotFormatJSONL (the default, one node per line) or types.SnapshotFormatJSON.
+// Nodes are written as stored: retention views are not applied and nothing is generated. The nodes
+// are read from one consistent snapshot, so concurrent writes never produce a torn export
+func (s *SpectraFS) Export(ctx context.Context, w io.Writer, format string) error {
+	if format == "" {
+		format = types.SnapshotFormatJSONL
+	}
+	if format != types.SnapshotFormatJSONL && format != types.SnapshotFormatJSON {
+		return fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+
+	var nodes []*types.Node
+	err := s.ForEachNode(ctx, types.NodeScanOptions{Snapshot: true}, func(node *types.Node) error {
+		nodes = append(nodes, node)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(nodes, func(a, b *types.Node) int {
+		return cmp.Or(cmp.Compare(a.DepthLevel, b.DepthLevel), strings.Compare(a.Path, b.Path))
+	})
+
+	bw := bufio.NewWriter(w)
+	if format == types.SnapshotFormatJSON {
+		bw.WriteString("[\n")
+	}
+	for i, node := range nodes {
+		if i%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		data, err := json.Marshal(node)
+		if err != nil {
+			return fmt.Errorf("failed to encode node %s: %w", node.ID, err)
+		}
+		if format == types.SnapshotFormatJSON && i > 0 {
+			bw.WriteString(",\n")
+		}
+		bw.Write(data)
+		if format == types.SnapshotFormatJSONL {
+			bw.WriteByte('\n')
+		}
+	}
+	if format == types.SnapshotFormatJSON {
+		bw.WriteString("\n]\n")
+	}
+	return bw.Flush()
+}
+
+// Import loads a snapshot written by Export (either format, detected from the first byte) from r
+// The whole snapshot is loaded in one transaction, indexed as it goes, and its stats rebuilt; any
+// error leaves the database untouched. Every node's parent must come before it, and every world in
+// its existence map must be configured. Without merge the database must hold nothing but the root
+// (ErrDatabaseNotEmpty); with merge, nodes whose ID is already stored are skipped and a new node at
+// a taken path fails with ErrPathExists
+func (s *SpectraFS) Import(ctx context.Context, r io.Reader, merge bool) (*types.ImportResult, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	s.exclusive.Lock()
+	defer s.exclusive.Unlock()
+	defer s.beginOperation(OperationImport)()
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	br := bufio.NewReader(r)
+	array, err := snapshotIsArray(br)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(br)
+	if array {
+		if _, err := dec.Token(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+	}
+
+	decoded := 0
+	next := func() (*types.Node, error) {
+		if array && !dec.More() {
+			return nil, nil
+		}
+		var node types.Node
+		if err := dec.Decode(&node); err != nil {
+			if err == io.EOF && !array {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("%w: node %d: %v", ErrInvalidSnapshot, decoded+1, err)
+		}
+		decoded++
+		if err := s.checkSnapshotNode(&node); err != nil {
+			return nil, err
+		}
+		return &node, nil
+	}
+
+	imported, skipped, err := s.db.ImportNodes(ctx, merge, next)
+	if err != nil {
+		return nil, err
+	}
+	s.epoch.Add(1)
+
+	// An import rewrites the tree wholesale, so feed consumers are told to rescan
+	if err := s.journal(change{ChangeEvent: types.ChangeEvent{Op: types.ChangeReset, NodeID: "root", Path: "/", Nodes: imported}}); err != nil {
+		return nil, err
+	}
+	return &types.ImportResult{Imported: imported, Skipped: skipped, Merged: merge}, nil
+}
+
+// snapshotIsArray skips leading whitespace and reports whether the snapshot is a JSON array
+func snapshotIsArray(br *bufio.Reader) (bool, error) {
+	for {
+		b, err := br.Peek(1)
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			br.ReadByte()
+		default:
+			return b[0] == '[', nil
+		}
+	}
+}
+
+// checkSnapshotNode verifies the fields of a decoded node that do not depend on the stored tree
+func (s *SpectraFS) checkSnapshotNode(node *types.Node) error {
+	if node.ID == "" {
+		return fmt.Errorf("%w: node without an id", ErrInvalidSnapshot)
+	}
+	if node.Type != types.NodeTypeFolder && node.Type != types.NodeTypeFile && node.Type != types.NodeTypeSymlink {
+		return fmt.Errorf("%w: node %s has invalid type %q", ErrInvalidSnapshot, node.ID, node.Type)
+	}
+	for world := range node.ExistenceMap {
+		if !s.isKnownWorld(world) {
+			return fmt.Errorf("%w: node %s exists in unknown world %s", ErrInvalidSnapshot, node.ID, world)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package spectrafs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// generatedFS opens an instance and generates its whole tree
+func generatedFS(t *testing.T) *SpectraFS {
+	t.Helper()
+	s := newTestFS(t)
+	if _, err := s.GenerateAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// export exports s in format, failing the test on error
+func export(t *testing.T, s *SpectraFS, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := s.Export(context.Background(), &buf, format); err != nil {
+		t.Fatalf("Export(%s): %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+// storedNodes returns every stored node by ID
+func storedNodes(t *testing.T, s *SpectraFS) map[string]*types.Node {
+	t.Helper()
+	nodes := make(map[string]*types.Node)
+	err := s.ForEachNode(context.Background(), types.NodeScanOptions{}, func(node *types.Node) error {
+		nodes[node.ID] = node
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nodes
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	source := generatedFS(t)
+	want := storedNodes(t, source)
+
+	for _, format := range []string{types.SnapshotFormatJSONL, types.SnapshotFormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			snapshot := export(t, source, format)
+
+			target := newTestFS(t)
+			result, err := target.Import(context.Background(), bytes.NewReader(snapshot), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Imported != len(want) || result.Skipped != 0 {
+				t.Errorf("import %+v, want %d nodes imported", result, len(want))
+			}
+
+			got := storedNodes(t, target)
+			if len(got) != len(want) {
+				t.Fatalf("imported %d nodes, want %d", len(got), len(want))
+			}
+			for id, node := range want {
+				imported := got[id]
+				if imported == nil {
+					t.Errorf("%s missing after import", node.Path)
+					continue
+				}
+				if !imported.LastUpdated.Equal(node.LastUpdated) {
+					t.Errorf("%s: LastUpdated %v, want %v", node.Path, imported.LastUpdated, node.LastUpdated)
+				}
+				imported.LastUpdated = node.LastUpdated
+				if !reflect.DeepEqual(imported, node) {
+					t.Errorf("%s: imported %+v, want %+v", node.Path, *imported, *node)
+				}
+			}
+
+			if again := export(t, target, format); !bytes.Equal(again, snapshot) {
+				t.Error("exporting the imported tree gave a different snapshot")
+			}
+		})
+	}
+}
+
+func TestImportIntoNonEmptyDatabase(t *testing.T) {
+	source := generatedFS(t)
+	snapshot := export(t, source, types.SnapshotFormatJSONL)
+
+	if _, err := source.Import(context.Background(), bytes.NewReader(snapshot), false); !errors.Is(err, ErrDatabaseNotEmpty) {
+		t.Fatalf("import into a generated tree = %v, want ErrDatabaseNotEmpty", err)
+	}
+
+	result, err := source.Import(context.Background(), bytes.NewReader(snapshot), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 0 || result.Skipped != len(storedNodes(t, source)) || !result.Merged {
+		t.Errorf("merge of the same tree %+v, want every node skipped", result)
+	}
+}
+
+func TestImportRejectsChildBeforeParent(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(string(export(t, generatedFS(t), types.SnapshotFormatJSONL))), "\n")
+	last := len(lines) - 1
+	lines[1], lines[last] = lines[last], lines[1] // The deepest node, before its parent
+
+	target := newTestFS(t)
+	_, err := target.Import(context.Background(), strings.NewReader(strings.Join(lines, "\n")), false)
+	if !errors.Is(err, ErrInvalidSnapshot) {
+		t.Fatalf("out-of-order import = %v, want ErrInvalidSnapshot", err)
+	}
+	if nodes := storedNodes(t, target); len(nodes) != 1 {
+		t.Errorf("failed import left %d nodes, want only the root", len(nodes))
+	}
+}
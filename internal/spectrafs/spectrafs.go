@@ -1,15 +1,23 @@
 package spectrafs
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"log/slog"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Project-Sylos/Spectra/internal/config"
 	"github.com/Project-Sylos/Spectra/internal/db"
 	"github.com/Project-Sylos/Spectra/internal/generator"
+	"github.com/Project-Sylos/Spectra/internal/logging"
+	"github.com/Project-Sylos/Spectra/internal/metrics"
 	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
 	"github.com/Project-Sylos/Spectra/internal/types"
 	"github.com/Project-Sylos/Spectra/internal/utils"
@@ -20,8 +28,47 @@ import (
 type SpectraFS struct {
 	root string
 	db   *db.DB
-	cfg  *types.Config
-	rng  *generator.RNG
+	cfg  atomic.Pointer[types.Config] // Replaced whole, never mutated, so readers need no lock (see config)
+	now  func() time.Time             // Clock for retention TTLs (see SetClock)
+
+	cursorKey []byte // HMAC key pagination cursors are signed with (see db.CursorSecret)
+
+	recovery  *types.RecoveryReport  // Consistency report produced by this open (nil after a clean shutdown)
+	integrity *types.IntegrityReport // Index check run by this open (nil unless db.check_integrity is set)
+
+	configChanges []string // Generation setting differences db.accept_config_change let this open through
+	configPath    string   // File the config was loaded from, where UpdateConfig saves it ("" = built in code)
+
+	exclusive sync.Mutex            // Held by exclusive operations (Reset, Clone) and scheduled maintenance runs
+	writeMu   sync.Mutex            // Serializes read-then-write sequences such as lazy generation (taken after exclusive)
+	worldsMu  sync.RWMutex          // Serializes replacing cfg (AddWorld, RemoveWorld, UpdateConfig)
+	schedMu   sync.Mutex            // Protects scheduler
+	scheduler *maintenanceScheduler // Background maintenance (nil until StartMaintenance)
+	newTimer  maintenanceTimer      // Starts the scheduler's waits (time.NewTimer outside tests)
+
+	mutMu    sync.Mutex       // Protects mutator and mutStats
+	mutator  *mutationEngine  // Background mutations (nil until StartMutations)
+	mutStats mutationCounters // Mutation outcomes since open (see MutationStatus)
+
+	genMu      sync.Mutex     // Protects generation
+	generation *generationRun // Latest GenerateAll run (nil until one starts)
+
+	flightsMu sync.Mutex                   // Protects flights
+	flights   map[string]*generationFlight // Lazy generations in progress, by parent ID (see generateOnce)
+	epoch     atomic.Uint64                // Bumped under writeMu whenever the tree is replaced (see generateMissingChildren)
+
+	events *eventHub // Live event subscribers (see Subscribe)
+
+	chaos     *chaos             // Live chaos rules (see SetChaos)
+	readLimit *utils.TokenBucket // Shared file content read limit (nil = unlimited; see contentReader)
+	metrics   metrics.Recorder   // Receives generation counts and durations (nil = not recorded; see SetMetrics)
+	logger    *slog.Logger       // Fallback for contexts without a request logger (see SetLogger)
+
+	openedAt        time.Time  // When the instance was opened, for Health's uptime
+	healthMu        sync.Mutex // Protects operation and the last health error
+	operation       string     // Destructive operation in progress (see beginOperation)
+	lastHealthErr   string     // Most recent failed health check
+	lastHealthErrAt *time.Time
 }
 
 // NewSpectraFS creates a new SpectraFS instance with multi-table support
@@ -32,103 +79,271 @@ func NewSpectraFS(configPath string) (*SpectraFS, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
+	s, err := NewSpectraFSFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.configPath = configPath
+	return s, nil
+}
+
+// NewSpectraFSFromConfig creates a new SpectraFS instance from a configuration built in code or
+// loaded with config.LoadFromFile. Unset settings are filled in (see config.ApplyDefaults) before the
+// result is validated, so cfg is modified
+func NewSpectraFSFromConfig(cfg *types.Config) (*SpectraFS, error) {
+	if err := config.ApplyDefaults(cfg); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(cfg); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
 	// Initialize database with secondary tables
 	// Note: InitializeSchema() already creates root nodes automatically
-	database, err := db.New(cfg.Seed.DBPath, cfg.SecondaryTables)
+	open := db.New
+	if cfg.DB.ReadOnly {
+		open = db.NewReadOnly // Must exist already; nothing below writes to it
+	}
+	database, err := open(cfg.Seed.DBPath, cfg.SecondaryTables, db.Options{
+		NoSync:          cfg.DB.NoSync,
+		InitialMmapSize: cfg.DB.InitialMmapSize,
+		FreelistType:    cfg.DB.FreelistType,
+		BulkBatchSize:   cfg.DB.BulkBatchSize,
+		NodeCacheSize:   cfg.DB.NodeCacheSize,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
 
-	// Initialize seeded random number generator
-	rng := generator.NewRNG(cfg.Seed.Seed)
+	// Check (and optionally repair) the database if the previous run did not close it cleanly
+	recovery, err := database.Recover(cfg.DB.AutoRepair)
+	if err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to recover database: %w", err)
+	}
+
+	// Verify the indexes (and optionally repair them) if requested, and again before a clean shutdown is recorded
+	integrity, err := checkIntegrityOnOpen(database, cfg)
+	if err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to check database integrity: %w", err)
+	}
+	database.CheckOnClose(cfg.DB.CheckIntegrity)
+	database.CompactOnClose(cfg.DB.CompactOnCloseRatio)
+
+	// Record the instance identity on first open (clones arrive with their own)
+	if _, err := database.EnsureIdentity(cfg.Seed.Seed); err != nil {
+		database.Close()
+		return nil, err
+	}
+
+	// Sign pagination cursors with the instance's stored secret, so they survive restarts but not forgery
+	cursorKey, err := database.CursorSecret()
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+
+	// Refuse to continue a tree generated with other settings (or record them on first open)
+	configChanges, err := checkFingerprintOnOpen(database, cfg)
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+
+	// Warm the in-memory index structures if requested
+	if err := database.Preload(cfg.DB.Preload, cfg.DB.PreloadMaxBytes); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to preload database: %w", err)
+	}
 
-	return &SpectraFS{
+	// Arm the generation failure hook (testing only; see config.Warnings)
+	database.ArmInsertFailure(cfg.Debug.FailGenerationAfterNNodes)
+
+	// Give the root the configured base timestamp, like every generated node
+	if err := stampRoot(database, cfg); err != nil {
+		database.Close()
+		return nil, err
+	}
+
+	// Jobs the API was running when the database was last closed can never finish
+	if !cfg.DB.ReadOnly {
+		if _, err := database.FailInterruptedJobs(); err != nil {
+			database.Close()
+			return nil, err
+		}
+	}
+
+	s := &SpectraFS{
 		root: "root",
 		db:   database,
-		cfg:  cfg,
-		rng:  rng,
-	}, nil
+		now:  time.Now,
+
+		cursorKey: cursorKey,
+		recovery:  recovery,
+		integrity: integrity,
+		events:    newEventHub(),
+		flights:   make(map[string]*generationFlight),
+		chaos:     newChaos(cfg.Chaos),
+		readLimit: newReadLimit(cfg),
+		logger:    logging.Discard,
+		openedAt:  time.Now(),
+
+		configChanges: configChanges,
+		newTimer:      systemTimer,
+	}
+	s.cfg.Store(config.Clone(cfg))
+	return s, nil
+}
+
+// config returns the configuration in force; it is replaced whole rather than mutated, so the
+// result may be read without locking but must not be modified
+func (s *SpectraFS) config() *types.Config {
+	return s.cfg.Load()
 }
 
 // ListChildren retrieves children for a parent node in a specific world
 // This is the OPTIMIZED single-table version with minimal DB queries
 // Accepts any struct that implements the ParentIdentifier interface
-func (s *SpectraFS) ListChildren(req models.ParentIdentifier) (*types.ListResult, error) {
+// If the request also implements PaginatedRequest, results are paged with keyset cursors;
+// a malformed or expired cursor returns ErrInvalidCursor / ErrCursorExpired, and a missing parent ErrParentNotFound.
+// If it implements ListFilterRequest, children are filtered and sorted before paging, and the result
+// counts the children filtered out; invalid options return ErrInvalidInput. A ParentPath that is not
+// found is materialized like a GetNode path when the request implements MaterializeRequest and asks for it
+// ctx.Err() is returned if ctx is cancelled before the listing or while generated children are inserted
+func (s *SpectraFS) ListChildren(ctx context.Context, req models.ParentIdentifier) (*types.ListResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// Validate request
-	if err := models.ValidateParentIdentifier(req); err != nil {
-		return &types.ListResult{
-			Success: false,
-			Message: err.Error(),
-		}, nil
+	if err := validateRequest(models.ValidateParentIdentifier(req)); err != nil {
+		return nil, err
+	}
+	opts, err := listOptionsOf(req)
+	if err != nil {
+		return nil, err
 	}
 
-	// Resolve the parent node and extract world from request
-	parent, world, err := s.resolveNodeAndWorld(req)
+	// Read the parent and its children in this world in one snapshot, noting which tree it came from
+	epoch := s.epoch.Load()
+	parentID, parentPath, world, err := s.parentRef(req)
 	if err != nil {
-		return &types.ListResult{
-			Success: false,
-			Message: fmt.Sprintf("Parent node not found: %v", err),
-		}, nil
+		return nil, err
+	}
+	// Filter while loading, keeping children hidden by retention so they are not counted as filtered out
+	var keep func(*types.Node) bool
+	if opts.filtering() {
+		keep = func(node *types.Node) bool {
+			return opts.match(node) || !s.applyRetentionView(node).ExistenceMap[world]
+		}
+	}
+	listing, err := s.db.GetListing(parentID, parentPath, world, keep)
+	if errors.Is(err, ErrNodeNotFound) && parentID == "" && materializing(req) {
+		if err = s.materializePath(ctx, parentPath, world); err == nil {
+			listing, err = s.db.GetListing(parentID, parentPath, world, keep)
+		}
 	}
+	if err != nil {
+		if errors.Is(err, ErrNodeNotFound) {
+			err = fmt.Errorf("%w: %w", ErrParentNotFound, err)
+		}
+		return nil, err
+	}
+	parent := s.applyRetentionView(listing.Parent)
 
 	// Check if parent exists in the requested world
 	if !parent.ExistenceMap[world] {
 		return &types.ListResult{
 			Success: true,
 			Message: fmt.Sprintf("Node does not exist in world %s", world),
+			Parent:  parent,
 			Folders: make([]types.Folder, 0),
 			Files:   make([]types.File, 0),
 		}, nil
 	}
 
-	// OPTIMIZATION: Get parent + children in ONE query
-	nodes, err := s.db.GetParentAndChildren(parent.ID, world)
-	if err != nil {
+	// Finish a folder left half-generated by an injected failure (no-op otherwise), then reread it
+	children, filtered, reread := listing.Children, listing.Filtered, false
+	if completed, err := s.db.CompletePendingChildren(parent.ID); err != nil {
 		return &types.ListResult{
 			Success: false,
-			Message: fmt.Sprintf("Failed to get parent and children: %v", err),
+			Message: fmt.Sprintf("Failed to complete pending children: %v", err),
+			Parent:  parent,
 		}, nil
-	}
-
-	// First node is parent (if found), rest are children
-	var children []*types.Node
-	if len(nodes) > 0 {
-		// nodes[0] is the parent, nodes[1:] are children
-		children = nodes[1:]
-	}
-
-	// If no children exist, generate them
-	if len(children) == 0 {
-		generated, err := generator.GenerateChildren(parent, parent.DepthLevel, s.rng, s.cfg)
-		if err != nil {
+	} else if completed > 0 {
+		if children, err = s.db.GetChildrenByParentID(parent.ID, world); err != nil {
 			return &types.ListResult{
 				Success: false,
-				Message: fmt.Sprintf("Failed to generate children: %v", err),
+				Message: fmt.Sprintf("Failed to get children: %v", err),
+				Parent:  parent,
 			}, nil
 		}
+		reread = true
+	}
 
-		// OPTIMIZATION: Bulk insert all nodes in ONE transaction
-		if err := s.db.BulkInsertNodes(generated); err != nil {
-			return &types.ListResult{
-				Success: false,
-				Message: fmt.Sprintf("Failed to bulk insert nodes: %v", err),
-			}, nil
+	// If the folder has never been expanded, generate its children (a read-only instance lists it as empty).
+	// A folder at max depth always generates nothing, and one generated before is not generated
+	// again, even if nothing came out or all of its children were deleted since
+	generated := false
+	atMaxDepth := parent.DepthLevel >= s.config().Seed.MaxDepth
+	if len(children) == 0 && !listing.HasChildren && !listing.ChildrenGenerated && !atMaxDepth && !s.db.ReadOnly() {
+		var early *types.ListResult
+		children, generated, early, err = s.generateOnce(ctx, parent, world, epoch)
+		if err != nil {
+			return nil, err
+		}
+		if early != nil {
+			result := *early // Shared with concurrent listings of the folder (see generateOnce)
+			result.Parent = parent
+			return &result, nil
 		}
+	}
+
+	// Hide children whose retention TTL has passed in this world
+	children = s.filterRetained(children, world)
 
-		// Filter children by requested world
-		for _, node := range generated {
-			if node.ExistenceMap[world] {
-				children = append(children, node)
+	// Apply the request's filters to children that were not filtered while loading, then its sort
+	if opts.filtering() && (generated || reread) {
+		kept := make([]*types.Node, 0, len(children))
+		for _, child := range children {
+			if opts.match(child) {
+				kept = append(kept, child)
 			}
 		}
+		filtered = len(children) - len(kept)
+		children = kept
 	}
+	opts.sort(children)
 
-	// Separate folders and files
+	// Separate folders, files and symlinks
 	result := &types.ListResult{
-		Success: true,
-		Message: "Children retrieved successfully",
-		Folders: make([]types.Folder, 0),
-		Files:   make([]types.File, 0),
+		Success:   true,
+		Message:   "Children retrieved successfully",
+		Parent:    parent,
+		Generated: generated,
+		Folders:   make([]types.Folder, 0),
+		Files:     make([]types.File, 0),
+		Symlinks:  make([]types.Symlink, 0),
+		Filtered:  filtered,
+	}
+	if result.Filtered > 0 {
+		result.Message = fmt.Sprintf("Children retrieved successfully (%d filtered out)", result.Filtered)
+	} else if len(children) == 0 && atMaxDepth && parent.Type == types.NodeTypeFolder {
+		result.Message = AtMaxDepthMessage
+	}
+
+	// Apply keyset pagination if requested
+	if paged, ok := req.(models.PaginatedRequest); ok {
+		page := pageRequest{
+			Limit:         paged.GetLimit(),
+			StartingAfter: paged.GetStartingAfter(),
+			EndingBefore:  paged.GetEndingBefore(),
+		}
+		children, result.NextCursor, result.PrevCursor, err = s.paginateChildren(children, parent.ID, world, page, opts)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	for _, child := range children {
@@ -137,22 +352,133 @@ func (s *SpectraFS) ListChildren(req models.ParentIdentifier) (*types.ListResult
 			result.Folders = append(result.Folders, types.Folder{Node: *child})
 		case types.NodeTypeFile:
 			result.Files = append(result.Files, types.File{Node: *child})
+		case types.NodeTypeSymlink:
+			result.Symlinks = append(result.Symlinks, types.Symlink{Node: *child})
 		}
 	}
 
 	return result, nil
 }
 
+// generateMissingChildren generates and stores the children of a parent the caller found without any,
+// under writeMu, returning those in world and whether this call stored them. db.InsertChildren checks
+// in the insert's transaction that the parent still has no children; if another writer got there
+// first, its stored children are returned instead, so a folder is generated only once (see also generateOnce).
+// A failed generation, or one skipped because the generation budget is spent (an empty listing with
+// BudgetExhaustedMessage), is reported through the returned ListResult; err is only ctx.Err()
+// or, if the tree was replaced since parent was read in epoch and the parent went with it, ErrParentNotFound
+func (s *SpectraFS) generateMissingChildren(ctx context.Context, parent *types.Node, world string, epoch uint64) ([]*types.Node, bool, *types.ListResult, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	// A reset, restore or import ran since the parent was read: generate from the current tree's copy
+	if s.epoch.Load() != epoch {
+		current, err := s.db.GetNodeByID(parent.ID)
+		if err != nil {
+			if errors.Is(err, ErrNodeNotFound) {
+				err = fmt.Errorf("%w: %w", ErrParentNotFound, err)
+			}
+			return nil, false, nil, err
+		}
+		parent = current
+	}
+
+	budget, err := s.loadBudget()
+	if err != nil {
+		return nil, false, &types.ListResult{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+	if budget.exhausted() {
+		// Nothing is stored, so the folder expands normally once the budget is raised
+		return nil, false, &types.ListResult{
+			Success:  true,
+			Message:  BudgetExhaustedMessage,
+			Folders:  make([]types.Folder, 0),
+			Files:    make([]types.File, 0),
+			Symlinks: make([]types.Symlink, 0),
+		}, nil
+	}
+
+	start := time.Now()
+	generated, err := generator.GenerateChildren(parent, parent.DepthLevel, s.config())
+	if err != nil {
+		return nil, false, &types.ListResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to generate children: %v", err),
+		}, nil
+	}
+
+	// OPTIMIZATION: Bulk insert all nodes in ONE transaction, which rechecks that none exist yet
+	if err := s.db.InsertChildren(ctx, parent.ID, generated); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, false, nil, ctxErr
+		}
+		if errors.Is(err, db.ErrChildrenExist) {
+			children, early := s.storedChildren(parent.ID, world)
+			return children, false, early, nil
+		}
+		if errors.Is(err, ErrNodeNotFound) {
+			return nil, false, nil, fmt.Errorf("%w: %w", ErrParentNotFound, err)
+		}
+		return nil, false, &types.ListResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to bulk insert nodes: %v", err),
+		}, nil
+	}
+	s.recordChildrenGeneration(len(generated), start)
+	s.log(ctx).Debug("generated children", "parent", parent.Path, "world", world, "count", len(generated), "duration", time.Since(start))
+
+	if s.events.active() {
+		s.events.publish(s.generatedEvents(generated, world)...)
+	}
+
+	// Filter children by requested world
+	var children []*types.Node
+	for _, node := range generated {
+		if node.ExistenceMap[world] {
+			children = append(children, node)
+		}
+	}
+
+	// Generated children come out in generation order; match the stored listing order
+	db.SortNodes(children)
+	return children, true, nil, nil
+}
+
+// storedChildren returns a parent's stored children in world, with a failed read reported through the ListResult
+func (s *SpectraFS) storedChildren(parentID, world string) ([]*types.Node, *types.ListResult) {
+	children, err := s.db.GetChildrenByParentID(parentID, world)
+	if err != nil {
+		return nil, &types.ListResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to get children: %v", err),
+		}
+	}
+	return children, nil
+}
+
 // GetNode retrieves a node using either ID or Path+World
 // Accepts any struct that implements the NodeIdentifier interface
-func (s *SpectraFS) GetNode(req models.NodeIdentifier) (*types.Node, error) {
-	if err := models.ValidateNodeIdentifier(req); err != nil {
+// If it also implements MaterializeRequest and asks for it, a path that is not found has the folders
+// on the way generated (see materializePath) and is looked up again
+func (s *SpectraFS) GetNode(ctx context.Context, req models.NodeIdentifier) (*types.Node, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := validateRequest(models.ValidateNodeIdentifier(req)); err != nil {
 		return nil, err
 	}
 
-	id := req.GetID()
+	id, _ := s.normalizeNodeID(req.GetID())
 	path := req.GetPath()
 	tableName := req.GetTableName()
+	if tableName != "" {
+		if err := s.checkWorld(tableName); err != nil {
+			return nil, err
+		}
+	}
 
 	var (
 		node *types.Node
@@ -166,8 +492,13 @@ func (s *SpectraFS) GetNode(req models.NodeIdentifier) (*types.Node, error) {
 			tableName = "primary" // Default to primary world
 		}
 		node, err = s.db.GetNodeByPath(path, tableName)
+		if errors.Is(err, ErrNodeNotFound) && materializing(req) {
+			if err = s.materializePath(ctx, path, tableName); err == nil {
+				node, err = s.db.GetNodeByPath(path, tableName)
+			}
+		}
 	} else {
-		return nil, fmt.Errorf("either id or path must be specified")
+		return nil, fmt.Errorf("%w: either id or path must be specified", ErrInvalidInput)
 	}
 
 	if err != nil {
@@ -175,14 +506,68 @@ func (s *SpectraFS) GetNode(req models.NodeIdentifier) (*types.Node, error) {
 	}
 
 	if node == nil {
-		return nil, fmt.Errorf("node not found")
+		return nil, ErrNodeNotFound
 	}
 
-	return node, nil
+	// Path lookups are world-scoped, so a node expired by retention is absent there
+	s.applyRetentionView(node)
+	if id == "" && !node.ExistenceMap[tableName] {
+		return nil, fmt.Errorf("%w with path %s in world %s", ErrNodeNotFound, path, tableName)
+	}
+
+	return s.presentRoot(node), nil
+}
+
+// materializing reports whether req asks for the folders on the way to its path to be generated
+func materializing(req any) bool {
+	m, ok := req.(models.MaterializeRequest)
+	return ok && m.GetMaterialize()
 }
 
+// materializePath generates the unlisted folders on the way to path in world, so a path lookup finds
+// a node whose ancestors were never listed. It walks down from the root, listing each folder whose
+// next name is missing, and stops without error at the first name that is still missing or that is
+// not a folder, leaving the lookup to report it. Listing generates as usual: nothing below
+// seed.max_depth, within the generation budgets, and nothing on a read-only instance
+func (s *SpectraFS) materializePath(ctx context.Context, path, world string) error {
+	exists := func(path string) (*types.Node, bool) {
+		node, err := s.db.GetNodeByPath(path, world)
+		if err != nil || node == nil || !s.applyRetentionView(node).ExistenceMap[world] {
+			return nil, false
+		}
+		return node, true
+	}
+
+	parent := "/"
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		child := utils.JoinPath(parent, name)
+		node, found := exists(child)
+		if !found {
+			if _, err := s.ListChildren(ctx, &models.ListChildrenRequest{ParentPath: parent, TableName: world, Limit: 1}); err != nil {
+				return err
+			}
+			if node, found = exists(child); !found {
+				return nil
+			}
+		}
+		if node.Type != types.NodeTypeFolder {
+			return nil
+		}
+		parent = child
+	}
+	return nil
+}
+
+// ErrNotAFile is returned when file content is requested for a folder
+var ErrNotAFile = newError(ErrInvalidInput, "node is not a file")
+
 // GetFileData generates deterministic file data and checksum for a file (not persisted)
 func (s *SpectraFS) GetFileData(id string) ([]byte, string, error) {
+	id, _ = s.normalizeNodeID(id)
+
 	// Verify the node exists and is a file
 	node, err := s.db.GetNodeByID(id)
 	if err != nil {
@@ -190,11 +575,11 @@ func (s *SpectraFS) GetFileData(id string) ([]byte, string, error) {
 	}
 
 	if node.Type != types.NodeTypeFile {
-		return nil, "", fmt.Errorf("node %s is not a file", id)
+		return nil, "", fmt.Errorf("%w: %s", ErrNotAFile, id)
 	}
 
 	// Generate deterministic data and checksum
-	data, checksum, err := s.getFileDataDeterministic(node.ID)
+	data, checksum, err := s.getFileDataDeterministic(node)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to generate file data: %w", err)
 	}
@@ -202,17 +587,90 @@ func (s *SpectraFS) GetFileData(id string) ([]byte, string, error) {
 	return data, checksum, nil
 }
 
+// OpenFileData returns a streaming reader over a file's deterministic content with the file node
+// Unlike GetFileData the content is generated lazily in blocks, so files of any size can be
+// served without holding them in memory. Reads are throttled by api.max_read_bandwidth and
+// seed.per_file_bandwidth; once ctx is done they stop waiting and return ctx.Err()
+func (s *SpectraFS) OpenFileData(ctx context.Context, id string) (io.ReadSeeker, *types.Node, error) {
+	id, _ = s.normalizeNodeID(id)
+	node, err := s.db.GetNodeByID(id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get file node: %w", err)
+	}
+
+	if node.Type != types.NodeTypeFile {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotAFile, id)
+	}
+
+	return s.contentReader(ctx, node), node, nil
+}
+
+// ErrInvalidRange is returned by ReadFileRange for a negative offset or length, or an offset past the end
+var ErrInvalidRange = newError(ErrInvalidInput, "invalid byte range")
+
+// ReadFileRange generates length bytes of a file's deterministic content starting at offset
+// Only the content blocks covering the window are generated, so the bytes match GetFileData at
+// any offset. A window running past the end is cut short there and returned with io.EOF, as is
+// an empty read at exactly the end; an offset beyond the end is ErrInvalidRange
+func (s *SpectraFS) ReadFileRange(id string, offset, length int64) ([]byte, error) {
+	if offset < 0 || length < 0 {
+		return nil, fmt.Errorf("%w: offset and length must be non-negative, got %d and %d", ErrInvalidRange, offset, length)
+	}
+
+	id, _ = s.normalizeNodeID(id)
+	node, err := s.db.GetNodeByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file node: %w", err)
+	}
+
+	if node.Type != types.NodeTypeFile {
+		return nil, fmt.Errorf("%w: %s", ErrNotAFile, id)
+	}
+	if offset > node.Size {
+		return nil, fmt.Errorf("%w: offset %d is beyond the end of %s (%d bytes)", ErrInvalidRange, offset, id, node.Size)
+	}
+
+	short := length > node.Size-offset
+	if short {
+		length = node.Size - offset
+	}
+
+	reader := s.fileDataReader(node)
+	if _, err := reader.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return nil, fmt.Errorf("failed to generate file data: %w", err)
+	}
+
+	if short {
+		return data, io.EOF
+	}
+	return data, nil
+}
+
 // CreateFolder creates a new folder node
 // Accepts any struct that implements ParentIdentifier and NamedRequest interfaces
-func (s *SpectraFS) CreateFolder(req interface {
+// Returns ErrPathExists if a sibling already has the name
+func (s *SpectraFS) CreateFolder(ctx context.Context, req interface {
 	models.ParentIdentifier
 	models.NamedRequest
 }) (*types.Node, error) {
-	if err := models.ValidateParentIdentifier(req); err != nil {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := validateRequest(models.ValidateParentIdentifier(req)); err != nil {
 		return nil, err
 	}
 	if req.GetName() == "" {
-		return nil, fmt.Errorf("name is required")
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidInput)
 	}
 
 	// Resolve parent node
@@ -222,7 +680,7 @@ func (s *SpectraFS) CreateFolder(req interface {
 	}
 
 	if parent.Type != types.NodeTypeFolder {
-		return nil, fmt.Errorf("parent %s is not a folder", parent.ID)
+		return nil, fmt.Errorf("%w: parent %s is not a folder", ErrInvalidInput, parent.ID)
 	}
 
 	// Create folder node with UUID
@@ -230,35 +688,23 @@ func (s *SpectraFS) CreateFolder(req interface {
 	path := utils.JoinPath(parent.Path, req.GetName())
 
 	// Roll dice for existence in each world - ensure all worlds have keys
-	existenceMap := make(map[string]bool)
-
-	// Primary is always true
-	existenceMap["primary"] = true
-
-	// For each secondary world, check parent existence first
-	for worldName, probability := range s.cfg.SecondaryTables {
-		// If parent doesn't exist in this world, child cannot exist
-		if !parent.ExistenceMap[worldName] {
-			existenceMap[worldName] = false
-		} else {
-			// Parent exists, so roll dice: roll [0.0, 1.0) must be <= probability
-			roll := s.rng.Float64()
-			existenceMap[worldName] = (roll <= probability)
-		}
-	}
+	// The dice are seeded by the new node's path, so the outcome does not depend on earlier operations
+	existenceMap := generator.RollExistence(parent, s.config(), path, parent.DepthLevel+1)
 
 	folderNode := &types.Node{
-		ID:           nodeID,
-		ParentID:     parent.ID,
-		Name:         req.GetName(),
-		Path:         path,
-		ParentPath:   parent.Path,
-		Type:         types.NodeTypeFolder,
-		DepthLevel:   parent.DepthLevel + 1,
-		Size:         0,
-		LastUpdated:  time.Now(),
-		Checksum:     nil,
-		ExistenceMap: existenceMap,
+		ID:              nodeID,
+		ParentID:        parent.ID,
+		Name:            req.GetName(),
+		Path:            path,
+		ParentPath:      parent.Path,
+		Type:            types.NodeTypeFolder,
+		DepthLevel:      parent.DepthLevel + 1,
+		Size:            0,
+		LastUpdated:     time.Now(),
+		Checksum:        nil,
+		ExistenceMap:    existenceMap,
+		TraversalStatus: types.StatusPending,
+		CopyStatus:      types.CopyStatusPending,
 	}
 
 	// Insert node
@@ -266,25 +712,78 @@ func (s *SpectraFS) CreateFolder(req interface {
 		return nil, fmt.Errorf("failed to insert folder node: %w", err)
 	}
 
+	if err := s.journal(nodeChange(types.ChangeCreate, folderNode, 1)); err != nil {
+		return nil, err
+	}
 	return folderNode, nil
 }
 
 // UploadFile handles file uploads with single-table support
 // Accepts any struct that implements ParentIdentifier, NamedRequest, and DataRequest interfaces
-func (s *SpectraFS) UploadFile(req interface {
+// Returns ErrPathExists if a sibling already has the name, unless req implements OverwriteRequest and
+// asks for it: then an existing file gets the new content in place (same ID), while a folder still conflicts
+func (s *SpectraFS) UploadFile(ctx context.Context, req interface {
 	models.ParentIdentifier
 	models.NamedRequest
 	models.DataRequest
 }) (*types.Node, error) {
-	if err := models.ValidateParentIdentifier(req); err != nil {
+	if err := validateUpload(req); err != nil {
+		return nil, err
+	}
+	if len(req.GetData()) == 0 {
+		return nil, fmt.Errorf("%w: data is required", ErrInvalidInput)
+	}
+	return s.storeUpload(ctx, req)
+}
+
+// UploadFileFrom is UploadFile with the content read from body instead of held in memory; body is
+// consumed before the write lock is taken. A read error from body (e.g. *http.MaxBytesError) is
+// returned wrapped and nothing is stored
+func (s *SpectraFS) UploadFileFrom(ctx context.Context, req interface {
+	models.ParentIdentifier
+	models.NamedRequest
+}, body io.Reader) (*types.Node, error) {
+	if err := validateUpload(req); err != nil {
 		return nil, err
 	}
+	// The content is not persisted (file data is generated), so it only has to arrive in full
+	n, err := io.Copy(io.Discard, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("%w: data is required", ErrInvalidInput)
+	}
+	return s.storeUpload(ctx, req)
+}
+
+// validateUpload checks the parent identifier and name of an upload request
+func validateUpload(req interface {
+	models.ParentIdentifier
+	models.NamedRequest
+}) error {
+	if err := validateRequest(models.ValidateParentIdentifier(req)); err != nil {
+		return err
+	}
 	if req.GetName() == "" {
-		return nil, fmt.Errorf("name is required")
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
 	}
-	if len(req.GetData()) == 0 {
-		return nil, fmt.Errorf("data is required")
+	return nil
+}
+
+// storeUpload creates (or, with Overwrite, replaces) the file node of a validated upload
+func (s *SpectraFS) storeUpload(ctx context.Context, req interface {
+	models.ParentIdentifier
+	models.NamedRequest
+}) (*types.Node, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
 
 	// Resolve parent node
 	parent, _, err := s.resolveNodeAndWorld(req)
@@ -293,7 +792,7 @@ func (s *SpectraFS) UploadFile(req interface {
 	}
 
 	if parent.Type != types.NodeTypeFolder {
-		return nil, fmt.Errorf("parent %s is not a folder", parent.ID)
+		return nil, fmt.Errorf("%w: parent %s is not a folder", ErrInvalidInput, parent.ID)
 	}
 
 	// Generate UUID for the new file
@@ -301,41 +800,45 @@ func (s *SpectraFS) UploadFile(req interface {
 	path := utils.JoinPath(parent.Path, req.GetName())
 
 	// Generate deterministic file data metadata (data itself is not persisted)
-	data, checksum, err := generator.GenerateDeterministicFileData(s.cfg.Seed.FileBinarySeed)
+	data, checksum, err := generator.GenerateDeterministicFileData(generator.ContentSeed(s.config(), nodeID), generator.DefaultFileSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate file data: %w", err)
 	}
 
 	// Roll dice for existence in each world - ensure all worlds have keys
-	existenceMap := make(map[string]bool)
+	// The dice are seeded by the new node's path, so the outcome does not depend on earlier operations
+	existenceMap := generator.RollExistence(parent, s.config(), path, parent.DepthLevel+1)
 
-	// Primary is always true
-	existenceMap["primary"] = true
-
-	// For each secondary world, check parent existence first
-	for worldName, probability := range s.cfg.SecondaryTables {
-		// If parent doesn't exist in this world, child cannot exist
-		if !parent.ExistenceMap[worldName] {
-			existenceMap[worldName] = false
-		} else {
-			// Parent exists, so roll dice: roll [0.0, 1.0) must be <= probability
-			roll := s.rng.Float64()
-			existenceMap[worldName] = (roll <= probability)
-		}
+	fileNode := &types.Node{
+		ID:              nodeID,
+		ParentID:        parent.ID,
+		Name:            req.GetName(),
+		Path:            path,
+		ParentPath:      parent.Path,
+		Type:            types.NodeTypeFile,
+		DepthLevel:      parent.DepthLevel + 1,
+		Size:            int64(len(data)),
+		LastUpdated:     time.Now(),
+		Checksum:        &checksum,
+		ExistenceMap:    existenceMap,
+		TraversalStatus: types.StatusPending,
+		CopyStatus:      types.CopyStatusPending,
 	}
 
-	fileNode := &types.Node{
-		ID:           nodeID,
-		ParentID:     parent.ID,
-		Name:         req.GetName(),
-		Path:         path,
-		ParentPath:   parent.Path,
-		Type:         types.NodeTypeFile,
-		DepthLevel:   parent.DepthLevel + 1,
-		Size:         int64(len(data)),
-		LastUpdated:  time.Now(),
-		Checksum:     &checksum,
-		ExistenceMap: existenceMap,
+	// Replace an existing file at the path if the request asks for it
+	if overwrite, ok := req.(models.OverwriteRequest); ok && overwrite.GetOverwrite() {
+		stored, replaced, err := s.db.ReplaceFileNode(fileNode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store uploaded file node: %w", err)
+		}
+		op := types.ChangeCreate
+		if replaced {
+			op = types.ChangeModify
+		}
+		if err := s.journal(nodeChange(op, stored, 1)); err != nil {
+			return nil, err
+		}
+		return stored, nil
 	}
 
 	// Insert node
@@ -343,52 +846,86 @@ func (s *SpectraFS) UploadFile(req interface {
 		return nil, fmt.Errorf("failed to insert uploaded file node: %w", err)
 	}
 
+	if err := s.journal(nodeChange(types.ChangeCreate, fileNode, 1)); err != nil {
+		return nil, err
+	}
 	return fileNode, nil
 }
 
 // Reset clears all nodes and recreates the root
-func (s *SpectraFS) Reset() error {
-	// Delete all nodes
-	if err := s.db.DeleteAllNodes(); err != nil {
-		return fmt.Errorf("failed to delete all nodes: %w", err)
+// The change journal is emptied down to a single reset event, so feed consumers know to rescan.
+// Cancelling ctx before the nodes are cleared leaves the tree untouched
+func (s *SpectraFS) Reset(ctx context.Context) error {
+	if err := s.checkWritable(); err != nil {
+		return err
 	}
+	s.exclusive.Lock()
+	defer s.exclusive.Unlock()
+	defer s.beginOperation(OperationReset)()
 
-	// Recreate single root node with all worlds
-	if err := s.db.CreateRootNode(); err != nil {
-		return fmt.Errorf("failed to recreate root node: %w", err)
-	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
 
-	// Reset random number generator with same seed for reproducibility
-	s.rng = generator.NewRNG(s.cfg.Seed.Seed)
+	return s.resetTree(ctx)
+}
 
-	return nil
+// resetTree clears all nodes and stats and recreates the root in one transaction, then resets the
+// journal; listings that read a parent before the reset regenerate against the new tree
+// NOTE: This function assumes the caller already holds exclusive and writeMu
+func (s *SpectraFS) resetTree(ctx context.Context) error {
+	if err := s.db.ResetNodes(ctx, generator.BaseTimestamp(s.config())); err != nil {
+		return fmt.Errorf("failed to reset nodes: %w", err)
+	}
+	s.epoch.Add(1)
+	return s.resetJournal()
 }
 
 // Close closes the database connection after performing a WAL checkpoint to ensure data persistence.
 // This ensures all changes are fully saved before the process finishes.
+// Background maintenance, mutations and a running GenerateAll are stopped first, waiting for them to finish,
+// and event subscribers are disconnected.
 func (s *SpectraFS) Close() error {
+	s.stopMaintenance()
+	s.StopMutations()
+	s.stopGeneration()
+	s.events.close()
 	return s.db.Close()
 }
 
-// GetConfig returns the current configuration
+// GetConfig returns a deep copy of the current configuration; changing it does not affect the
+// instance (see UpdateConfig)
 func (s *SpectraFS) GetConfig() *types.Config {
-	return s.cfg
+	return config.Clone(s.config())
 }
 
 // GetNodeCount returns the total number of nodes in a specific world
-func (s *SpectraFS) GetNodeCount(world string) (int, error) {
-	return s.db.GetNodeCount(world)
+func (s *SpectraFS) GetNodeCount(ctx context.Context, world string) (int, error) {
+	return s.db.GetNodeCount(ctx, world)
 }
 
 // GetTableInfo returns information about all tables
-func (s *SpectraFS) GetTableInfo() ([]types.TableInfo, error) {
-	return s.db.GetTableInfo()
+func (s *SpectraFS) GetTableInfo(ctx context.Context) ([]types.TableInfo, error) {
+	return s.db.GetTableInfo(ctx)
 }
 
+// ErrFolderNotEmpty is returned when a non-recursive delete targets a folder that has children
+var ErrFolderNotEmpty = newError(ErrConflict, "folder is not empty")
+
 // DeleteNode deletes a node using either ID or Path+World
 // Accepts any struct that implements the NodeIdentifier interface
-func (s *SpectraFS) DeleteNode(req models.NodeIdentifier) error {
-	if err := models.ValidateNodeIdentifier(req); err != nil {
+// A folder with children is only deleted (with all of its descendants) if the request also
+// implements RecursiveRequest and asks for it; otherwise ErrFolderNotEmpty is returned
+func (s *SpectraFS) DeleteNode(ctx context.Context, req models.NodeIdentifier) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := validateRequest(models.ValidateNodeIdentifier(req)); err != nil {
 		return err
 	}
 
@@ -398,11 +935,236 @@ func (s *SpectraFS) DeleteNode(req models.NodeIdentifier) error {
 		return fmt.Errorf("failed to resolve node: %w", err)
 	}
 
-	// Prevent deletion of root node
-	if node.ID == "root" {
-		return fmt.Errorf("cannot delete root node")
+	if err := s.guardMutation(opDelete, node.ID); err != nil {
+		return err
+	}
+
+	if node.Type == types.NodeTypeFolder {
+		hasChildren, err := s.db.HasChildren(node.ID)
+		if err != nil {
+			return err
+		}
+		if hasChildren {
+			recursive, ok := req.(models.RecursiveRequest)
+			if !ok || !recursive.GetRecursive() {
+				return fmt.Errorf("%w: %s (set recursive to delete its descendants)", ErrFolderNotEmpty, node.Path)
+			}
+			deleted, err := s.db.DeleteSubtree(node.ID)
+			if err != nil {
+				return err
+			}
+			return s.journal(nodeChange(types.ChangeDelete, node, len(deleted)))
+		}
+	}
+
+	if err := s.db.DeleteNode(node.ID); err != nil {
+		return err
+	}
+	return s.journal(nodeChange(types.ChangeDelete, node, 1))
+}
+
+// ErrInvalidTraversalStatus is returned when a status is not "pending", "successful" or "failed"
+var ErrInvalidTraversalStatus = newError(ErrInvalidInput, "invalid traversal status")
+
+// ErrNodeNotFound is returned when a node ID does not exist
+var ErrNodeNotFound = db.ErrNodeNotFound
+
+// UpdateTraversalStatus records a crawler's progress on a node
+// Accepts any struct that implements NodeIdentifier and StatusRequest; the status must be
+// "pending", "successful" or "failed". Returns the updated node.
+func (s *SpectraFS) UpdateTraversalStatus(req interface {
+	models.NodeIdentifier
+	models.StatusRequest
+}) (*types.Node, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := validateRequest(models.ValidateNodeIdentifier(req)); err != nil {
+		return nil, err
+	}
+	if !types.IsValidTraversalStatus(req.GetStatus()) {
+		return nil, fmt.Errorf("%w %q: must be %q, %q or %q", ErrInvalidTraversalStatus,
+			req.GetStatus(), types.StatusPending, types.StatusSuccessful, types.StatusFailed)
+	}
+
+	node, _, err := s.resolveNodeAndWorld(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve node: %w", err)
+	}
+
+	if err := s.db.UpdateTraversalStatus(node.ID, req.GetStatus()); err != nil {
+		return nil, err
 	}
-	return s.db.DeleteNode(node.ID)
+	node.TraversalStatus = req.GetStatus()
+
+	return s.presentRoot(node), nil
+}
+
+// TouchNode sets a node's LastUpdated (mtime) to lastUpdated and returns the node, so tests can
+// bump a timestamp on purpose and check that downstream change detection notices
+// Accepts any struct that implements NodeIdentifier (ID or Path+TableName)
+func (s *SpectraFS) TouchNode(req models.NodeIdentifier, lastUpdated time.Time) (*types.Node, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := validateRequest(models.ValidateNodeIdentifier(req)); err != nil {
+		return nil, err
+	}
+
+	node, _, err := s.resolveNodeAndWorld(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve node: %w", err)
+	}
+
+	node, err = s.db.TouchNode(node.ID, lastUpdated)
+	if err != nil {
+		return nil, err
+	}
+	return s.presentRoot(node), nil
+}
+
+// MaxBatchDeleteSize is the maximum number of IDs accepted by a single DeleteNodes call
+const MaxBatchDeleteSize = 1000
+
+// DeleteNodes deletes a list of nodes by ID and reports a per-ID outcome
+// Without recursive, non-empty folders are skipped; with recursive, their whole subtree is removed
+// Each ID is deleted in its own bounded transaction(s), so a failure partway through only affects that ID;
+// IDs not yet reached when ctx is cancelled are reported failed with ctx.Err(). Legacy IDs are accepted
+// as everywhere else (see normalizeNodeID), and outcomes carry the IDs as given. If journaling the
+// deletes fails after they are stored, the result is returned with the error
+func (s *SpectraFS) DeleteNodes(ctx context.Context, ids []string, recursive bool) (*types.BatchDeleteResult, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: ids is required", ErrInvalidInput)
+	}
+	if len(ids) > MaxBatchDeleteSize {
+		return nil, fmt.Errorf("%w: too many ids: %d exceeds maximum of %d", ErrInvalidInput, len(ids), MaxBatchDeleteSize)
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	result := &types.BatchDeleteResult{
+		Results: make([]types.DeleteOutcome, 0, len(ids)),
+	}
+
+	// Resolve every ID up front, keeping the first occurrence of duplicates (by canonical ID)
+	outcomes := make(map[string]*types.DeleteOutcome, len(ids))
+	var pending []*types.Node
+	for _, requested := range ids {
+		id, _ := s.normalizeNodeID(requested)
+		if _, seen := outcomes[id]; seen {
+			continue
+		}
+		outcome := &types.DeleteOutcome{ID: requested}
+		outcomes[id] = outcome
+
+		if err := s.guardMutation(opDelete, id); err != nil {
+			outcome.Status = types.DeleteStatusSkippedRoot
+			outcome.Message = err.Error()
+			continue
+		}
+
+		node, err := s.db.GetNodeByID(id)
+		if err != nil {
+			if errors.Is(err, db.ErrNodeNotFound) {
+				outcome.Status = types.DeleteStatusNotFound
+			} else {
+				outcome.Status = types.DeleteStatusFailed
+				outcome.Message = err.Error()
+			}
+			continue
+		}
+		pending = append(pending, node)
+	}
+
+	// Recursive deletes go shallowest-first so ancestors sweep up listed descendants;
+	// non-recursive deletes go deepest-first so listed children empty their parents first
+	sort.SliceStable(pending, func(i, j int) bool {
+		if recursive {
+			return pending[i].DepthLevel < pending[j].DepthLevel
+		}
+		return pending[i].DepthLevel > pending[j].DepthLevel
+	})
+
+	removed := make(map[string]bool)
+	var changes []change
+	for _, node := range pending {
+		outcome := outcomes[node.ID]
+
+		// Already removed as part of an ancestor's subtree
+		if removed[node.ID] {
+			outcome.Status = types.DeleteStatusDeleted
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			outcome.Status = types.DeleteStatusFailed
+			outcome.Message = err.Error()
+			continue
+		}
+
+		if node.Type == types.NodeTypeFolder {
+			hasChildren, err := s.db.HasChildren(node.ID)
+			if err != nil {
+				outcome.Status = types.DeleteStatusFailed
+				outcome.Message = err.Error()
+				continue
+			}
+
+			if hasChildren {
+				if !recursive {
+					outcome.Status = types.DeleteStatusSkippedNotEmpty
+					continue
+				}
+
+				deleted, err := s.db.DeleteSubtree(node.ID)
+				for _, d := range deleted {
+					removed[d.ID] = true
+				}
+				result.Deleted += len(deleted)
+				if err != nil {
+					outcome.Status = types.DeleteStatusFailed
+					outcome.Message = err.Error()
+					continue
+				}
+				changes = append(changes, nodeChange(types.ChangeDelete, node, len(deleted)))
+				outcome.Status = types.DeleteStatusDeleted
+				continue
+			}
+		}
+
+		if err := s.db.DeleteNode(node.ID); err != nil {
+			if errors.Is(err, db.ErrNodeNotFound) {
+				outcome.Status = types.DeleteStatusNotFound
+			} else {
+				outcome.Status = types.DeleteStatusFailed
+				outcome.Message = err.Error()
+			}
+			continue
+		}
+		removed[node.ID] = true
+		changes = append(changes, nodeChange(types.ChangeDelete, node, 1))
+		result.Deleted++
+		outcome.Status = types.DeleteStatusDeleted
+	}
+
+	// Report outcomes in request order
+	for _, requested := range ids {
+		id, _ := s.normalizeNodeID(requested)
+		if outcome, ok := outcomes[id]; ok {
+			result.Results = append(result.Results, *outcome)
+			delete(outcomes, id)
+		}
+	}
+
+	// The deletes are stored whether or not journaling them succeeds
+	if err := s.journal(changes...); err != nil {
+		return result, err
+	}
+	return result, nil
 }
 
 // GetSecondaryTables returns the list of secondary table names
@@ -410,14 +1172,41 @@ func (s *SpectraFS) GetSecondaryTables() []string {
 	return s.db.GetSecondaryTables()
 }
 
+// RebuildCounters recomputes the per-world node counters behind GetNodeCount and GetTableInfo
+// Use it on databases whose counters have drifted; they are otherwise kept in step with every write
+func (s *SpectraFS) RebuildCounters() error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	return s.db.RebuildCounters()
+}
+
 // GetStats retrieves the current filesystem statistics
 func (s *SpectraFS) GetStats() (*types.Stats, error) {
-	return s.db.GetStats()
+	stats, err := s.db.GetStats()
+	if err != nil {
+		return nil, err
+	}
+
+	coverage, err := s.GetCoverage()
+	if err != nil {
+		return nil, err
+	}
+	stats.Coverage = make(map[string]float64, len(coverage.Worlds))
+	for _, world := range coverage.Worlds {
+		stats.Coverage[world.World] = world.CoveragePercent
+	}
+	stats.Generation = s.GenerationProgress()
+
+	return stats, nil
 }
 
 // resolveNodeAndWorld resolves a node and world from a request using interfaces
 // Supports both NodeIdentifier (for ID or Path+World) and ParentIdentifier (for ParentID or ParentPath+World)
-// Returns the node and the world name (defaults to "primary" if not specified)
+// Legacy "p-root" and "{world}-{uuid}" IDs are accepted (see normalizeNodeID); their prefix supplies
+// the world when TableName is empty
+// Returns the node and the world name (defaults to "primary" if not specified); a world that is not
+// configured is ErrUnknownWorld
 func (s *SpectraFS) resolveNodeAndWorld(req any) (*types.Node, string, error) {
 	var node *types.Node
 	var world string
@@ -425,57 +1214,102 @@ func (s *SpectraFS) resolveNodeAndWorld(req any) (*types.Node, string, error) {
 
 	// Try NodeIdentifier first (for GetNode, DeleteNode)
 	if nodeID, ok := req.(models.NodeIdentifier); ok {
-		id := nodeID.GetID()
+		id, idWorld := s.normalizeNodeID(nodeID.GetID())
 		path := nodeID.GetPath()
 		world = nodeID.GetTableName() // TableName is used for world name
 
+		if world == "" {
+			world = idWorld // A legacy "{world}-{uuid}" ID names its world
+		}
 		if world == "" {
 			world = "primary" // Default to primary world
 		}
+		if err := s.checkWorld(world); err != nil {
+			return nil, "", err
+		}
 
 		if id != "" {
 			node, err = s.db.GetNodeByID(id)
 		} else if path != "" {
 			node, err = s.db.GetNodeByPath(path, world)
 		} else {
-			return nil, "", fmt.Errorf("either id or path must be specified")
+			return nil, "", fmt.Errorf("%w: either id or path must be specified", ErrInvalidInput)
 		}
-		return node, world, err
+		return s.applyRetentionView(node), world, err
 	}
 
 	// Try ParentIdentifier (for ListChildren, CreateFolder, UploadFile)
-	if parentID, ok := req.(models.ParentIdentifier); ok {
-		parentIDStr := parentID.GetParentID()
-		parentPath := parentID.GetParentPath()
-		world = parentID.GetTableName() // TableName is used for world name
-
-		if world == "" {
-			world = "primary" // Default to primary world
+	if req, ok := req.(models.ParentIdentifier); ok {
+		parentID, parentPath, world, err := s.parentRef(req)
+		if err != nil {
+			return nil, "", err
 		}
 
-		if parentIDStr != "" {
-			node, err = s.db.GetNodeByID(parentIDStr)
-		} else if parentPath != "" {
-			node, err = s.db.GetNodeByPath(parentPath, world)
+		if parentID != "" {
+			node, err = s.db.GetNodeByID(parentID)
 		} else {
-			return nil, "", fmt.Errorf("either parent_id or parent_path must be specified")
+			node, err = s.db.GetNodeByPath(parentPath, world)
 		}
-		return node, world, err
+		if errors.Is(err, ErrNodeNotFound) {
+			err = fmt.Errorf("%w: %w", ErrParentNotFound, err)
+		}
+		return s.applyRetentionView(node), world, err
 	}
 
 	return nil, "", fmt.Errorf("unsupported request type - must implement NodeIdentifier or ParentIdentifier")
 }
 
-// getFileDataDeterministic generates deterministic file data using the configured binary seed
+// parentRef extracts the parent a request names, by normalized ID or else by path, and the world
+// it is in (the table name, the world of a legacy "{world}-{uuid}" ID, or primary). A world that is
+// not configured is ErrUnknownWorld
+func (s *SpectraFS) parentRef(req models.ParentIdentifier) (id, path, world string, err error) {
+	id, idWorld := s.normalizeNodeID(req.GetParentID())
+	path = req.GetParentPath()
+	world = req.GetTableName() // TableName is used for world name
+
+	if world == "" {
+		world = idWorld // A legacy "{world}-{uuid}" ID names its world
+	}
+	if world == "" {
+		world = "primary" // Default to primary world
+	}
+	if err := s.checkWorld(world); err != nil {
+		return "", "", "", err
+	}
+
+	if id == "" && path == "" {
+		return "", "", "", fmt.Errorf("%w: either parent_id or parent_path must be specified", ErrInvalidInput)
+	}
+	return id, path, world, nil
+}
+
+// contentSeed returns the seed of a file node's content; copies share their source's content via ContentID
+func (s *SpectraFS) contentSeed(node *types.Node) int64 {
+	if node.ContentID != "" {
+		return generator.ContentSeed(s.config(), node.ContentID)
+	}
+	return generator.ContentSeed(s.config(), node.ID)
+}
+
+// fileDataReader returns a lazy reader over a file node's deterministic content (see generator.ContentSeed)
+func (s *SpectraFS) fileDataReader(node *types.Node) *generator.FileReader {
+	return generator.NewFileReader(s.contentSeed(node), node.Size)
+}
+
+// getFileDataDeterministic generates exactly node.Size bytes of the node's deterministic file data
 // This ensures every retrieval returns the same data, satisfying tools that rely on stable content
-func (s *SpectraFS) getFileDataDeterministic(nodeID string) ([]byte, string, error) {
-	return generator.GenerateDeterministicFileData(s.cfg.Seed.FileBinarySeed)
+func (s *SpectraFS) getFileDataDeterministic(node *types.Node) ([]byte, string, error) {
+	return generator.GenerateDeterministicFileData(s.contentSeed(node), node.Size)
 }
 
 // SpectraFSWrapper wraps SpectraFS to implement fs.FS interface for a specific world
 type SpectraFSWrapper struct {
 	fs    *SpectraFS
 	world string
+	root  string       // Node path that "." maps to ("/" unless the wrapper came from Sub)
+	meta  *MetaOptions // Metadata subtree options (nil when the subtree is disabled)
+
+	followLinks bool // Resolve symlinks instead of surfacing them (see FollowSymlinks)
 }
 
 // NewSpectraFSWrapper creates a new fs.FS wrapper for a specific world
@@ -486,81 +1320,165 @@ func NewSpectraFSWrapper(fs *SpectraFS, world string) *SpectraFSWrapper {
 	return &SpectraFSWrapper{
 		fs:    fs,
 		world: world,
+		root:  "/",
 	}
 }
 
-// Open opens the named file or directory
-func (w *SpectraFSWrapper) Open(name string) (fs.File, error) {
-	// Normalize path - handle root specially
-	path := name
-	if path == "." || path == "" {
-		path = "/"
-	} else if !strings.HasPrefix(path, "/") {
-		path = "/" + path
+// resolvePath validates an fs.FS name and converts it to the absolute node path used internally
+func (w *SpectraFSWrapper) resolvePath(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
 	}
+	if name == "." {
+		return w.root, nil
+	}
+	return utils.JoinPath(w.root, name), nil
+}
 
-	// Validate path (skip validation for root "/")
-	if path != "/" && !fs.ValidPath(strings.TrimPrefix(path, "/")) {
-		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+// lookupNode resolves a real path in the wrapper's world
+// Folders on the way that were never listed are generated first (GetNode with Materialize), so Open
+// and Stat see the same tree as ReadDir and fs.WalkDir on a fresh instance
+func (w *SpectraFSWrapper) lookupNode(path string) (*types.Node, bool) {
+	node, err := w.fs.GetNode(context.Background(), &models.GetNodeRequest{Path: path, TableName: w.world, Materialize: true})
+	if err != nil || !node.ExistenceMap[w.world] {
+		return nil, false
 	}
+	return node, true
+}
 
-	// Get node by path in the bound world
-	req := &models.GetNodeRequest{
-		Path:      path,
-		TableName: w.world,
+// Sub returns an fs.FS rooted at dir that shares the instance, world and metadata options
+// Like fs.Sub, dir is not checked up front; names opened through the result are resolved (and
+// generated) lazily under it
+func (w *SpectraFSWrapper) Sub(dir string) (fs.FS, error) {
+	path, err := w.resolvePath("sub", dir)
+	if err != nil {
+		return nil, err
 	}
+	sub := *w
+	sub.root = path
+	return &sub, nil
+}
 
-	node, err := w.fs.GetNode(req)
+// Open opens the named file or directory
+func (w *SpectraFSWrapper) Open(name string) (fs.File, error) {
+	path, err := w.resolvePath("open", name)
 	if err != nil {
-		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		return nil, err
 	}
 
-	// Check if node exists in the requested world
-	if !node.ExistenceMap[w.world] {
-		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	// Virtual metadata subtree
+	if w.isMetaPath(path) {
+		return w.openMeta(name, path)
+	}
+
+	// Get node by path in the bound world
+	node, chain, err := w.resolveNode(path, true)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
 	}
+	info := resolvedInfo(node, path)
 
 	// Return appropriate file handle
-	if node.Type == types.NodeTypeFolder {
-		// For directories, we need to list children
-		listReq := &models.ListChildrenRequest{
-			ParentPath: path,
-			TableName:  w.world,
+	switch node.Type {
+	case types.NodeTypeSymlink:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: ErrIsSymlink}
+	case types.NodeTypeFolder:
+		// For directories, the listing is read now so generation happens (and fails) at open;
+		// entries are built a page at a time as ReadDir(n) consumes them
+		pager := &dirPager{wrapper: w, path: node.Path, chain: chain}
+		if path == "/" && w.meta != nil && w.meta.ListMeta {
+			pager.tail = append(pager.tail, fs.FileInfoToDirEntry(newMetaDirInfo(MetaDirName, node)))
 		}
-
-		result, err := w.fs.ListChildren(listReq)
+		entries, err := pager.next()
 		if err != nil {
 			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
 		}
 
-		// Convert to DirEntry slice
-		entries := make([]fs.DirEntry, 0, len(result.Folders)+len(result.Files))
-		for _, folder := range result.Folders {
-			entries = append(entries, NewDirEntry(&folder.Node))
-		}
-		for _, file := range result.Files {
-			entries = append(entries, NewDirEntry(&file.Node))
-		}
-
 		return &spectraDir{
 			node:    node,
+			info:    info,
 			entries: entries,
+			pager:   pager,
 		}, nil
 	}
 
-	// For files, generate deterministic data
-	data, _, err := w.fs.getFileDataDeterministic(node.ID)
-	if err != nil {
-		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
-	}
-
+	// For files, stream the deterministic content lazily
 	return &spectraFile{
 		node:   node,
-		data:   data,
-		offset: 0,
+		info:   info,
+		stream: w.fs.contentReader(context.Background(), node),
 	}, nil
 }
 
+// dirPageSize is the number of children a directory handle converts to entries per page
+const dirPageSize = 1000
+
+// dirPager serves a directory's children one page at a time. The sorted listing is read from
+// ListChildren once, on the first page, and kept on the handle, so reading a large directory with
+// ReadDir(n) is one load and sort rather than one per page
+type dirPager struct {
+	wrapper  *SpectraFSWrapper
+	path     string
+	chain    []string // Folders passed through to reach path, for symlink loop checks
+	children []*types.Node
+	loaded   bool
+	done     bool
+	tail     []fs.DirEntry // Virtual entries returned after the last page
+}
+
+// next returns the next page of entries; once the listing is exhausted it returns the tail and sets done
+func (p *dirPager) next() ([]fs.DirEntry, error) {
+	if !p.loaded {
+		if err := p.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	count := min(dirPageSize, len(p.children))
+	entries := make([]fs.DirEntry, 0, count)
+	for _, child := range p.children[:count] {
+		if child.Type == types.NodeTypeSymlink {
+			entries = append(entries, p.wrapper.linkEntry(child, p.chain))
+		} else {
+			entries = append(entries, NewDirEntry(child))
+		}
+	}
+	p.children = p.children[count:]
+
+	if len(p.children) == 0 {
+		p.done = true
+		entries = append(entries, p.tail...)
+	}
+	return entries, nil
+}
+
+// load reads the whole listing in ListChildren order: folders, then files, then symlinks
+func (p *dirPager) load() error {
+	result, err := p.wrapper.fs.ListChildren(context.Background(), &models.ListChildrenRequest{
+		ParentPath: p.path,
+		TableName:  p.wrapper.world,
+	})
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return errors.New(result.Message)
+	}
+
+	p.children = make([]*types.Node, 0, len(result.Folders)+len(result.Files)+len(result.Symlinks))
+	for i := range result.Folders {
+		p.children = append(p.children, &result.Folders[i].Node)
+	}
+	for i := range result.Files {
+		p.children = append(p.children, &result.Files[i].Node)
+	}
+	for i := range result.Symlinks {
+		p.children = append(p.children, &result.Symlinks[i].Node)
+	}
+	p.loaded = true
+	return nil
+}
+
 // ReadFile reads the named file and returns its contents
 func (w *SpectraFSWrapper) ReadFile(name string) ([]byte, error) {
 	file, err := w.Open(name)
@@ -608,44 +1526,46 @@ func (w *SpectraFSWrapper) ReadDir(name string) ([]fs.DirEntry, error) {
 		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
 	}
 
+	// fs.ReadDirFS requires entries sorted by filename
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
 	return entries, nil
 }
 
 // Stat returns a FileInfo describing the named file
 func (w *SpectraFSWrapper) Stat(name string) (fs.FileInfo, error) {
-	// Normalize path
-	path := name
-	if path == "." || path == "" {
-		path = "/"
-	} else if !strings.HasPrefix(path, "/") {
-		path = "/" + path
+	path, err := w.resolvePath("stat", name)
+	if err != nil {
+		return nil, err
 	}
 
-	// Validate path (skip validation for root "/")
-	if path != "/" && !fs.ValidPath(strings.TrimPrefix(path, "/")) {
-		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	// Virtual metadata subtree
+	if w.isMetaPath(path) {
+		entry, err := w.resolveMeta("stat", name, path)
+		if err != nil {
+			return nil, err
+		}
+		return entry.info, nil
 	}
 
 	// Get node by path in the bound world
-	req := &models.GetNodeRequest{
-		Path:      path,
-		TableName: w.world,
-	}
-
-	node, err := w.fs.GetNode(req)
+	node, _, err := w.resolveNode(path, true)
 	if err != nil {
-		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
-	}
-
-	// Check if node exists in the requested world
-	if !node.ExistenceMap[w.world] {
-		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
 	}
 
-	return NewFileInfo(node), nil
+	return resolvedInfo(node, path), nil
 }
 
 // Glob returns the names of all files matching pattern
 func (w *SpectraFSWrapper) Glob(pattern string) ([]string, error) {
-	return fs.Glob(w, pattern)
+	// Hide the Glob method from fs.Glob, which would otherwise call straight back into it
+	return fs.Glob(readDirOnlyFS{w}, pattern)
+}
+
+// readDirOnlyFS exposes only Open and ReadDir of the wrapped filesystem
+type readDirOnlyFS struct {
+	fs.ReadDirFS
 }
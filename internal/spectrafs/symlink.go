@@ -0,0 +1,262 @@
+package spectrafs
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	pathpkg "path"
+	"strings"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/generator"
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/internal/utils"
+	"github.com/google/uuid"
+)
+
+// maxSymlinkHops bounds the symlinks followed while resolving one name, like the kernel's ELOOP limit
+const maxSymlinkHops = 40
+
+// ErrSymlinkLoop is returned by an fs.FS wrapper following symlinks for a chain of more than
+// maxSymlinkHops links, or a link leading back to a folder on its own path (which would make the tree infinite)
+var ErrSymlinkLoop = newError(ErrInvalidInput, "too many levels of symbolic links")
+
+// ErrNotSymlink is returned by ReadLink for a node that is not a symlink
+var ErrNotSymlink = newError(ErrInvalidInput, "not a symbolic link")
+
+// ErrIsSymlink is returned by Open on a symlink when the fs.FS wrapper surfaces links instead of following them
+var ErrIsSymlink = newError(ErrInvalidInput, "is a symbolic link")
+
+// CreateSymlink creates a symlink node pointing at req's target, which is stored as given: an
+// absolute path in the same world or one relative to the parent, existing or not (dangling)
+// Returns ErrPathExists if a sibling already has the name
+func (s *SpectraFS) CreateSymlink(ctx context.Context, req interface {
+	models.ParentIdentifier
+	models.NamedRequest
+	models.TargetRequest
+}) (*types.Node, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := validateRequest(models.ValidateParentIdentifier(req)); err != nil {
+		return nil, err
+	}
+	if req.GetName() == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if req.GetTarget() == "" {
+		return nil, fmt.Errorf("%w: target is required", ErrInvalidInput)
+	}
+
+	parent, _, err := s.resolveNodeAndWorld(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent node: %w", err)
+	}
+	if parent.Type != types.NodeTypeFolder {
+		return nil, fmt.Errorf("%w: parent %s is not a folder", ErrInvalidInput, parent.ID)
+	}
+
+	path := utils.JoinPath(parent.Path, req.GetName())
+	linkNode := &types.Node{
+		ID:              uuid.New().String(),
+		ParentID:        parent.ID,
+		Name:            req.GetName(),
+		Path:            path,
+		ParentPath:      parent.Path,
+		Type:            types.NodeTypeSymlink,
+		DepthLevel:      parent.DepthLevel + 1,
+		Size:            int64(len(req.GetTarget())),
+		LastUpdated:     time.Now(),
+		ExistenceMap:    generator.RollExistence(parent, s.config(), path, parent.DepthLevel+1),
+		TraversalStatus: types.StatusPending,
+		CopyStatus:      types.CopyStatusPending,
+		Target:          req.GetTarget(),
+	}
+
+	if err := s.db.InsertNode(linkNode); err != nil {
+		return nil, fmt.Errorf("failed to insert symlink node: %w", err)
+	}
+
+	if err := s.journal(nodeChange(types.ChangeCreate, linkNode, 1)); err != nil {
+		return nil, err
+	}
+	return linkNode, nil
+}
+
+// FollowSymlinks returns a copy of the wrapper that resolves symlinks transparently: names passing
+// through a link continue at its target, Open and Stat describe the target, and directory entries of
+// links take their target's type. Dangling links are fs.ErrNotExist and loops ErrSymlinkLoop; such
+// links are listed as symlinks so walkers do not descend into them. ReadLink and Lstat still see links
+func (w *SpectraFSWrapper) FollowSymlinks() *SpectraFSWrapper {
+	follow := *w
+	follow.followLinks = true
+	return &follow
+}
+
+// resolveNode looks up an internal path in the wrapper's world; a wrapper following symlinks
+// resolves the links on the way (and the last name's too if followLast), returning the folders
+// passed through for loop checks
+func (w *SpectraFSWrapper) resolveNode(path string, followLast bool) (*types.Node, []string, error) {
+	if !w.followLinks {
+		node, found := w.lookupNode(path)
+		if !found {
+			return nil, nil, fs.ErrNotExist
+		}
+		return node, nil, nil
+	}
+	hops := 0
+	return w.walkLinks(path, followLast, &hops)
+}
+
+// walkLinks resolves path name by name from the root, following the symlinks met on the way
+// chain holds the stored path of each folder passed through, starting with "/"
+func (w *SpectraFSWrapper) walkLinks(path string, followLast bool, hops *int) (*types.Node, []string, error) {
+	node, found := w.lookupNode("/")
+	if !found {
+		return nil, nil, fs.ErrNotExist
+	}
+	chain := []string{"/"}
+	if path == "/" {
+		return node, chain, nil
+	}
+
+	names := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, name := range names {
+		if node.Type != types.NodeTypeFolder {
+			return nil, nil, fs.ErrNotExist
+		}
+		child, found := w.lookupNode(utils.JoinPath(node.Path, name))
+		if !found {
+			return nil, nil, fs.ErrNotExist
+		}
+		if child.Type == types.NodeTypeSymlink && (followLast || i < len(names)-1) {
+			var err error
+			if child, err = w.followLink(child, chain, hops); err != nil {
+				return nil, nil, err
+			}
+		}
+		node = child
+		if node.Type == types.NodeTypeFolder {
+			chain = append(chain, node.Path)
+		}
+	}
+	return node, chain, nil
+}
+
+// followLink resolves link, reached through the folders in chain, to the node its target names
+// A target folder that is on chain, or an ancestor of one, is ErrSymlinkLoop
+func (w *SpectraFSWrapper) followLink(link *types.Node, chain []string, hops *int) (*types.Node, error) {
+	if *hops++; *hops > maxSymlinkHops {
+		return nil, ErrSymlinkLoop
+	}
+
+	target := link.Target
+	if !strings.HasPrefix(target, "/") {
+		target = utils.JoinPath(link.ParentPath, target)
+	}
+	node, _, err := w.walkLinks(pathpkg.Clean(target), true, hops)
+	if err != nil {
+		return nil, err
+	}
+
+	if node.Type == types.NodeTypeFolder {
+		for _, dir := range chain {
+			if node.Path == "/" || dir == node.Path || strings.HasPrefix(dir, node.Path+"/") {
+				return nil, ErrSymlinkLoop
+			}
+		}
+	}
+	return node, nil
+}
+
+// linkEntry returns the directory entry of a symlink listed in a folder reached through chain: its
+// target under the link's name when the wrapper follows links and the target resolves, the link otherwise
+func (w *SpectraFSWrapper) linkEntry(link *types.Node, chain []string) fs.DirEntry {
+	if w.followLinks {
+		hops := 0
+		if target, err := w.followLink(link, chain, &hops); err == nil {
+			return fs.FileInfoToDirEntry(renamedInfo(NewFileInfo(target), link.Name))
+		}
+	}
+	return NewDirEntry(link)
+}
+
+// ReadLink returns the target of the named symlink as stored (absolute, or relative to its folder)
+// Symlinks on the way are followed only by a wrapper that follows them
+func (w *SpectraFSWrapper) ReadLink(name string) (string, error) {
+	path, err := w.resolvePath("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	if w.isMetaPath(path) {
+		if _, err := w.resolveMeta("readlink", name, path); err != nil {
+			return "", err
+		}
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: ErrNotSymlink}
+	}
+
+	node, _, err := w.resolveNode(path, false)
+	if err != nil {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: err}
+	}
+	if node.Type != types.NodeTypeSymlink {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: ErrNotSymlink}
+	}
+	return node.Target, nil
+}
+
+// Lstat is Stat without following a symlink in the last name, so links describe themselves
+func (w *SpectraFSWrapper) Lstat(name string) (fs.FileInfo, error) {
+	path, err := w.resolvePath("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	if w.isMetaPath(path) {
+		entry, err := w.resolveMeta("lstat", name, path)
+		if err != nil {
+			return nil, err
+		}
+		return entry.info, nil
+	}
+
+	node, _, err := w.resolveNode(path, false)
+	if err != nil {
+		return nil, &fs.PathError{Op: "lstat", Path: name, Err: err}
+	}
+	return NewFileInfo(node), nil
+}
+
+// namedFileInfo is a FileInfo shown under another name (a symlink's target under the link's name)
+type namedFileInfo struct {
+	fs.FileInfo
+	name string
+}
+
+// Name returns the name the FileInfo is shown under
+func (fi *namedFileInfo) Name() string {
+	return fi.name
+}
+
+// resolvedInfo returns the FileInfo of node reached at path, under the path's last name when
+// symlinks led there from elsewhere
+func resolvedInfo(node *types.Node, path string) fs.FileInfo {
+	if node.Path == path {
+		return NewFileInfo(node)
+	}
+	return renamedInfo(NewFileInfo(node), pathpkg.Base(path))
+}
+
+// renamedInfo returns info under name, or info itself when the name already matches
+func renamedInfo(info fs.FileInfo, name string) fs.FileInfo {
+	if info.Name() == name {
+		return info
+	}
+	return &namedFileInfo{FileInfo: info, name: name}
+}
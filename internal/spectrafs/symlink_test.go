@@ -0,0 +1,130 @@
+package spectrafs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// symlink creates a symlink under parentID, failing the test on error
+func symlink(t *testing.T, s *SpectraFS, parentID, name, target string) *types.Node {
+	t.Helper()
+	node, err := s.CreateSymlink(context.Background(), &models.CreateSymlinkRequest{ParentID: parentID, Name: name, Target: target})
+	if err != nil {
+		t.Fatalf("CreateSymlink(%s -> %s): %v", name, target, err)
+	}
+	return node
+}
+
+// linkFixture builds /d/f.txt and links next to it: a relative file link, an absolute folder
+// link, a dangling link, a link back to d's parent, and two links pointing at each other
+func linkFixture(t *testing.T) *SpectraFS {
+	t.Helper()
+	s := newTestFS(t)
+	d := mkdir(t, s, s.root, "d")
+	upload(t, s, d.ID, "f.txt", []byte("f"))
+	symlink(t, s, s.root, "file-link", "d/f.txt")
+	symlink(t, s, s.root, "dir-link", "/d")
+	symlink(t, s, s.root, "dangling", "/missing")
+	symlink(t, s, d.ID, "up", "..")
+	symlink(t, s, s.root, "ping", "pong")
+	symlink(t, s, s.root, "pong", "ping")
+	return s
+}
+
+func TestSymlinksSurfaced(t *testing.T) {
+	s := linkFixture(t)
+	w := NewSpectraFSWrapper(s, "primary")
+
+	info, err := w.Stat("file-link")
+	if err != nil || info.Mode()&fs.ModeSymlink == 0 {
+		t.Fatalf("Stat(file-link) = %v, %v, want a symlink", info, err)
+	}
+	if target, err := w.ReadLink("file-link"); err != nil || target != "d/f.txt" {
+		t.Errorf("ReadLink(file-link) = %q, %v", target, err)
+	}
+	if target, err := w.ReadLink("dangling"); err != nil || target != "/missing" {
+		t.Errorf("ReadLink(dangling) = %q, %v, want the target stored as given", target, err)
+	}
+	if _, err := w.ReadLink("d"); !errors.Is(err, ErrNotSymlink) {
+		t.Errorf("ReadLink of a folder = %v, want ErrNotSymlink", err)
+	}
+	if _, err := w.Open("file-link"); !errors.Is(err, ErrIsSymlink) {
+		t.Errorf("Open of a surfaced link = %v, want ErrIsSymlink", err)
+	}
+
+	result := list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"})
+	links := make(map[string]string)
+	for _, link := range result.Symlinks {
+		links[link.Name] = link.Target
+	}
+	if len(links) != 5 || links["dir-link"] != "/d" {
+		t.Errorf("root lists symlinks %v", links)
+	}
+}
+
+func TestSymlinksFollowed(t *testing.T) {
+	s := linkFixture(t)
+	w := NewSpectraFSWrapper(s, "primary")
+	follow := w.FollowSymlinks()
+
+	direct, err := fs.ReadFile(w, "d/f.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := fs.ReadFile(follow, "file-link"); err != nil || !bytes.Equal(data, direct) {
+		t.Errorf("reading through file-link = %d bytes, %v, want d/f.txt's %d", len(data), err, len(direct))
+	}
+	if info, err := follow.Stat("dir-link"); err != nil || !info.IsDir() || info.Name() != "dir-link" {
+		t.Errorf("Stat(dir-link) = %v, %v, want a folder named dir-link", info, err)
+	}
+	if data, err := fs.ReadFile(follow, "dir-link/f.txt"); err != nil || !bytes.Equal(data, direct) {
+		t.Errorf("reading through dir-link = %d bytes, %v", len(data), err)
+	}
+	if info, err := follow.Lstat("dir-link"); err != nil || info.Mode()&fs.ModeSymlink == 0 {
+		t.Errorf("Lstat(dir-link) = %v, %v, want the link itself", info, err)
+	}
+
+	if _, err := follow.Stat("dangling"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(dangling) = %v, want fs.ErrNotExist", err)
+	}
+	for _, loop := range []string{"ping", "d/up"} {
+		if _, err := follow.Stat(loop); !errors.Is(err, ErrSymlinkLoop) {
+			t.Errorf("Stat(%s) = %v, want ErrSymlinkLoop", loop, err)
+		}
+	}
+
+	// Links that do not resolve stay symlinks in listings, so a walk does not descend into them
+	walked := 0
+	err = fs.WalkDir(follow, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		walked++
+		if path == "d/up" && entry.Type()&fs.ModeSymlink == 0 {
+			t.Errorf("the looping link d/up is listed as %v", entry.Type())
+		}
+		return nil
+	})
+	if err != nil || walked == 0 {
+		t.Errorf("WalkDir following links = %d entries, %v", walked, err)
+	}
+}
+
+func TestSymlinkGeneration(t *testing.T) {
+	s := newTestFS(t, func(cfg *types.Config) { cfg.Seed.SymlinkProbability = 1 })
+	result := list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"})
+	if len(result.Symlinks) != 1 || result.Symlinks[0].Name != "link_1" || result.Symlinks[0].Target == "" {
+		t.Errorf("root generated with symlink_probability 1 lists symlinks %+v", result.Symlinks)
+	}
+
+	none := newTestFS(t)
+	if result := list(t, none, &models.ListChildrenRequest{ParentID: none.root, TableName: "primary"}); len(result.Symlinks) != 0 {
+		t.Errorf("root generated without symlink_probability lists symlinks %+v", result.Symlinks)
+	}
+}
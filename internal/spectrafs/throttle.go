@@ -0,0 +1,73 @@
+package spectrafs
+
+import (
+	"context"
+	"io"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+	"github.com/Project-Sylos/Spectra/internal/utils"
+)
+
+// newReadLimit creates the instance-wide read limiter from api.max_read_bandwidth (nil = unlimited)
+func newReadLimit(cfg *types.Config) *utils.TokenBucket {
+	if cfg.API.MaxReadBandwidth <= 0 {
+		return nil
+	}
+	return utils.NewTokenBucket(cfg.API.MaxReadBandwidth)
+}
+
+// contentReader returns a reader over a file node's content, throttled by the instance-wide
+// api.max_read_bandwidth and its own seed.per_file_bandwidth limit when they are set
+func (s *SpectraFS) contentReader(ctx context.Context, node *types.Node) io.ReadSeeker {
+	var buckets []*utils.TokenBucket
+	if s.readLimit != nil {
+		buckets = append(buckets, s.readLimit)
+	}
+	if s.config().Seed.PerFileBandwidth > 0 {
+		buckets = append(buckets, utils.NewTokenBucket(s.config().Seed.PerFileBandwidth))
+	}
+
+	reader := s.fileDataReader(node)
+	if len(buckets) == 0 {
+		return reader
+	}
+
+	chunk := buckets[0].Burst()
+	for _, bucket := range buckets[1:] {
+		chunk = min(chunk, bucket.Burst())
+	}
+	return &throttledReader{ctx: ctx, r: reader, buckets: buckets, chunk: chunk}
+}
+
+// throttledReader charges every read against its token buckets, in chunks of at most one burst
+// Seeking is free; only bytes actually read are charged
+type throttledReader struct {
+	ctx     context.Context
+	r       io.ReadSeeker
+	buckets []*utils.TokenBucket
+	chunk   int
+}
+
+// Read reads up to one chunk and waits until every bucket has paid for it
+// Returns ctx.Err() (and no data) once the context is done
+func (t *throttledReader) Read(b []byte) (int, error) {
+	if err := t.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if len(b) > t.chunk {
+		b = b[:t.chunk]
+	}
+
+	n, err := t.r.Read(b)
+	for _, bucket := range t.buckets {
+		if waitErr := bucket.WaitN(t.ctx, n); waitErr != nil {
+			return 0, waitErr
+		}
+	}
+	return n, err
+}
+
+// Seek sets the offset for the next Read, implementing io.Seeker
+func (t *throttledReader) Seek(offset int64, whence int) (int64, error) {
+	return t.r.Seek(offset, whence)
+}
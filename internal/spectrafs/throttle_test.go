@@ -0,0 +1,79 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// firstFile returns the first file listed under the root
+func firstFile(t *testing.T, s *SpectraFS) *types.Node {
+	t.Helper()
+	result := list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"})
+	if len(result.Files) == 0 {
+		t.Fatal("root has no files")
+	}
+	return &result.Files[0].Node
+}
+
+func TestReadThrottleTakesSizeOverRate(t *testing.T) {
+	const rate = 4096 // Bytes per second
+	for name, configure := range map[string]func(*types.Config){
+		"max_read_bandwidth": func(cfg *types.Config) { cfg.API.MaxReadBandwidth = rate },
+		"per_file_bandwidth": func(cfg *types.Config) { cfg.Seed.PerFileBandwidth = rate },
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := newTestFS(t, configure)
+			file := firstFile(t, s)
+			want := time.Duration(float64(file.Size) / rate * float64(time.Second))
+
+			start := time.Now()
+			reader, _, err := s.OpenFileData(context.Background(), file.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if elapsed := time.Since(start); elapsed < want*9/10 {
+				t.Errorf("read %d bytes at %d B/s in %s, want at least %s", len(data), rate, elapsed, want)
+			}
+
+			// The fs.FS file reader is throttled too
+			start = time.Now()
+			if _, err := fs.ReadFile(NewSpectraFSWrapper(s, "primary"), file.Path[1:]); err != nil {
+				t.Fatal(err)
+			}
+			if elapsed := time.Since(start); elapsed < want*9/10 {
+				t.Errorf("fs.ReadFile took %s, want at least %s", elapsed, want)
+			}
+		})
+	}
+}
+
+func TestReadThrottleStopsOnCancel(t *testing.T) {
+	s := newTestFS(t, func(cfg *types.Config) { cfg.API.MaxReadBandwidth = 64 }) // A 1KiB file takes 16s
+	file := firstFile(t, s)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	reader, _, err := s.OpenFileData(ctx, file.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	_, err = io.ReadAll(reader)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("read error = %v, want the context's", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancelled read took %s to stop", elapsed)
+	}
+}
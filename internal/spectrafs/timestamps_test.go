@@ -0,0 +1,163 @@
+package spectrafs
+
+import (
+	"context"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/generator"
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// mtimeOrder lists a folder by modification time and returns the children's names and times
+func mtimeOrder(t *testing.T, s *SpectraFS, parentID string) ([]string, []time.Time) {
+	t.Helper()
+	result := list(t, s, &models.ListChildrenRequest{ParentID: parentID, TableName: "primary", SortBy: models.SortByMTime})
+	var names []string
+	var times []time.Time
+	for _, node := range childNodes(result) {
+		names = append(names, node.Name)
+		times = append(times, node.LastUpdated)
+	}
+	return names, times
+}
+
+func TestSiblingTimestampsUniqueAndStable(t *testing.T) {
+	a, b := newTestFS(t), newTestFS(t)
+
+	// The root and one folder below it, in both instances
+	parents := [][2]string{{a.root, b.root}}
+	folder := list(t, a, &models.ListChildrenRequest{ParentID: a.root, TableName: "primary"}).Folders[0]
+	parents = append(parents, [2]string{folder.ID, folder.ID})
+
+	for _, pair := range parents {
+		namesA, timesA := mtimeOrder(t, a, pair[0])
+		namesB, timesB := mtimeOrder(t, b, pair[1])
+		if len(namesA) < 2 {
+			t.Fatalf("%s has only %d children", pair[0], len(namesA))
+		}
+
+		for i := 1; i < len(timesA); i++ {
+			if !timesA[i].After(timesA[i-1]) {
+				t.Errorf("%s: %s at %v is not after %s at %v", pair[0], namesA[i], timesA[i], namesA[i-1], timesA[i-1])
+			}
+		}
+		if strings.Join(namesA, "/") != strings.Join(namesB, "/") {
+			t.Errorf("%s: mtime order %v in one instance, %v in the other", pair[0], namesA, namesB)
+		}
+		for i := range timesA {
+			if !timesA[i].Equal(timesB[i]) {
+				t.Errorf("%s: %s modified at %v and %v", pair[0], namesA[i], timesA[i], timesB[i])
+			}
+		}
+	}
+}
+
+func TestSiblingTimestampsInFS(t *testing.T) {
+	s := newTestFS(t, func(cfg *types.Config) {
+		cfg.Seed.TimestampStepMillis = 1000
+	})
+	fsys := NewSpectraFSWrapper(s, "primary")
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[time.Time]string)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		node, err := s.GetNode(t.Context(), &models.GetNodeRequest{Path: "/" + entry.Name(), TableName: "primary"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.ModTime().Equal(node.LastUpdated) {
+			t.Errorf("%s: ModTime %v, LastUpdated %v", entry.Name(), info.ModTime(), node.LastUpdated)
+		}
+		if other, dup := seen[info.ModTime()]; dup {
+			t.Errorf("%s and %s share ModTime %v", entry.Name(), other, info.ModTime())
+		}
+		seen[info.ModTime()] = entry.Name()
+		if offset := node.LastUpdated.Sub(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)); offset%time.Second != 0 {
+			t.Errorf("%s: LastUpdated %v is not on the 1s step", entry.Name(), node.LastUpdated)
+		}
+	}
+}
+
+func TestTimestampsFollowBaseAndJitter(t *testing.T) {
+	s := newTestFS(t, func(cfg *types.Config) {
+		cfg.Seed.BaseTimestamp = "2020-05-01T00:00:00Z"
+		cfg.Seed.TimestampJitter = "1h"
+	})
+	ctx := context.Background()
+	base := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	latest := base.Add(time.Hour + time.Duration(s.config().Seed.MaxFiles+s.config().Seed.MaxFolders)*generator.TimestampStep(s.config()))
+
+	generate := func() map[string]time.Time {
+		t.Helper()
+		if _, err := s.GenerateAll(ctx); err != nil {
+			t.Fatal(err)
+		}
+		times := make(map[string]time.Time)
+		for id, node := range storedNodes(t, s) {
+			if id != s.root {
+				times[id] = node.LastUpdated
+			}
+		}
+		return times
+	}
+
+	first := generate()
+	jittered := false
+	for id, mtime := range first {
+		if mtime.Before(base) || mtime.After(latest) {
+			t.Errorf("%s modified at %v, want between %v and %v", id, mtime, base, latest)
+		}
+		if mtime.Sub(base) >= time.Minute {
+			jittered = true
+		}
+	}
+	if !jittered {
+		t.Error("no timestamp is more than a minute past the base; jitter was not applied")
+	}
+
+	// Regenerating after a reset reproduces every timestamp
+	if err := s.Reset(ctx); err != nil {
+		t.Fatal(err)
+	}
+	second := generate()
+	if len(second) != len(first) {
+		t.Fatalf("regenerated %d nodes, want %d", len(second), len(first))
+	}
+	for id, mtime := range first {
+		if !second[id].Equal(mtime) {
+			t.Errorf("%s modified at %v before the reset, %v after", id, mtime, second[id])
+		}
+	}
+}
+
+func TestTouchNode(t *testing.T) {
+	s := newTestFS(t)
+	file := list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}).Files[0]
+	touched := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	node, err := s.TouchNode(&models.GetNodeRequest{ID: file.ID}, touched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !node.LastUpdated.Equal(touched) {
+		t.Errorf("TouchNode returned LastUpdated %v, want %v", node.LastUpdated, touched)
+	}
+	info, err := fs.Stat(NewSpectraFSWrapper(s, "primary"), file.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(touched) {
+		t.Errorf("ModTime after TouchNode = %v, want %v", info.ModTime(), touched)
+	}
+}
@@ -0,0 +1,155 @@
+package spectrafs
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Project-Sylos/Spectra/internal/generator"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// MaxChecksumMismatches caps the mismatches listed in a VerifyReport; Mismatched counts every one
+const MaxChecksumMismatches = 1000
+
+// ErrInvalidChecksum is returned by VerifyChecksum for a claim that is not a hex SHA256 checksum
+var ErrInvalidChecksum = newError(ErrInvalidInput, "invalid checksum")
+
+// VerifyChecksum regenerates a file's deterministic content and reports whether claimed, a hex
+// SHA256 checksum in either case, matches it. The stored checksum is checked alongside, so a
+// validator can tell a bad copy from a bad source
+func (s *SpectraFS) VerifyChecksum(ctx context.Context, id, claimed string) (*types.ChecksumVerification, error) {
+	claimed = strings.ToLower(strings.TrimSpace(claimed))
+	if decoded, err := hex.DecodeString(claimed); err != nil || len(decoded) != 32 {
+		return nil, fmt.Errorf("%w: expected 64 hex characters", ErrInvalidChecksum)
+	}
+
+	id, _ = s.normalizeNodeID(id)
+	node, err := s.db.GetNodeByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file node: %w", err)
+	}
+	if node.Type != types.NodeTypeFile {
+		return nil, fmt.Errorf("%w: %s", ErrNotAFile, id)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	expected, err := generator.DeterministicChecksum(s.contentSeed(node), node.Size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum file content: %w", err)
+	}
+	stored := storedChecksum(node)
+	return &types.ChecksumVerification{
+		NodeID:   node.ID,
+		Path:     node.Path,
+		Size:     node.Size,
+		Claimed:  claimed,
+		Expected: expected,
+		Stored:   stored,
+		Match:    claimed == expected,
+		StoredOK: stored == expected,
+	}, nil
+}
+
+// VerifyTree recomputes the checksum of every stored file below rootID (the root if empty) that
+// exists in world (the ID's world prefix, or primary, if empty) and reports each file whose stored
+// checksum differs. Folders that were never listed are not generated, so only what a client could
+// already have copied is checked. ctx.Err() is returned if ctx is cancelled
+func (s *SpectraFS) VerifyTree(ctx context.Context, rootID, world string) (*types.VerifyReport, error) {
+	started := time.Now()
+	if rootID == "" {
+		rootID = s.root
+	}
+	rootID, prefixWorld := s.normalizeNodeID(rootID)
+	if world == "" {
+		world = prefixWorld
+	}
+	if world == "" {
+		world = "primary"
+	}
+	if err := s.checkWorld(world); err != nil {
+		return nil, err
+	}
+
+	start, err := s.db.GetNodeByID(rootID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve verify start: %w", err)
+	}
+	if !start.ExistenceMap[world] {
+		return nil, fmt.Errorf("%w: %s does not exist in world %s", ErrNodeNotFound, start.Path, world)
+	}
+
+	report := &types.VerifyReport{
+		RootID:     start.ID,
+		RootPath:   start.Path,
+		World:      world,
+		Mismatches: make([]types.ChecksumMismatch, 0),
+	}
+	queue := []*types.Node{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		switch node.Type {
+		case types.NodeTypeFolder:
+			report.Folders++
+			children, err := s.db.GetChildrenByParentID(node.ID, world)
+			if err != nil {
+				return nil, err
+			}
+			queue = append(queue, children...)
+		case types.NodeTypeFile:
+			if err := s.verifyStoredChecksum(node, report); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	report.OK = report.Mismatched == 0
+	report.CheckedAt = time.Now().UTC()
+	report.DurationMillis = time.Since(started).Milliseconds()
+	return report, nil
+}
+
+// verifyStoredChecksum regenerates one file's content and records it in report if its stored
+// checksum does not match
+func (s *SpectraFS) verifyStoredChecksum(node *types.Node, report *types.VerifyReport) error {
+	expected, err := generator.DeterministicChecksum(s.contentSeed(node), node.Size)
+	if err != nil {
+		return fmt.Errorf("failed to checksum %s: %w", node.Path, err)
+	}
+	report.Files++
+	report.Bytes += node.Size
+
+	stored := storedChecksum(node)
+	if stored == expected {
+		return nil
+	}
+	report.Mismatched++
+	if len(report.Mismatches) >= MaxChecksumMismatches {
+		report.Truncated = true
+		return nil
+	}
+	report.Mismatches = append(report.Mismatches, types.ChecksumMismatch{
+		NodeID:   node.ID,
+		Path:     node.Path,
+		Stored:   stored,
+		Expected: expected,
+	})
+	return nil
+}
+
+// storedChecksum returns a node's stored checksum, or "" if it has none
+func storedChecksum(node *types.Node) string {
+	if node.Checksum == nil {
+		return ""
+	}
+	return *node.Checksum
+}
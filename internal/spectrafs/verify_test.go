@@ -0,0 +1,74 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestVerifyTreeDetectsCorruptedChecksum(t *testing.T) {
+	s := generatedFS(t)
+	ctx := context.Background()
+
+	report, err := s.VerifyTree(ctx, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK || report.Files == 0 {
+		t.Fatalf("generated tree: %+v, want every file checked and matching", report)
+	}
+
+	// Store a wrong checksum for one file, leaving its content seed alone
+	file := firstFile(t, s)
+	corrupted := *file
+	bad := strings.Repeat("0", 64)
+	corrupted.Checksum = &bad
+	if _, replaced, err := s.db.ReplaceFileNode(&corrupted); err != nil || !replaced {
+		t.Fatalf("corrupt %s: replaced %v, %v", file.Path, replaced, err)
+	}
+
+	report, err = s.VerifyTree(ctx, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK || report.Mismatched != 1 || len(report.Mismatches) != 1 {
+		t.Fatalf("after corruption: %+v, want one mismatch", report)
+	}
+	mismatch := report.Mismatches[0]
+	if mismatch.NodeID != file.ID || mismatch.Stored != bad || mismatch.Expected != *file.Checksum {
+		t.Errorf("mismatch %+v, want %s stored as %s, expected %s", mismatch, file.Path, bad, *file.Checksum)
+	}
+
+	// A single verification tells the good copy from the bad source
+	check, err := s.VerifyChecksum(ctx, file.ID, strings.ToUpper(*file.Checksum))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !check.Match || check.StoredOK {
+		t.Errorf("VerifyChecksum of the true content = %+v, want a match and a bad stored checksum", check)
+	}
+}
+
+func TestVerifyChecksumRejectsInput(t *testing.T) {
+	s := newTestFS(t)
+	ctx := context.Background()
+	file := firstFile(t, s)
+
+	if check, err := s.VerifyChecksum(ctx, file.ID, strings.Repeat("a", 64)); err != nil || check.Match || !check.StoredOK {
+		t.Errorf("VerifyChecksum of a wrong claim = %+v, %v", check, err)
+	}
+	if _, err := s.VerifyChecksum(ctx, file.ID, "abc"); !errors.Is(err, ErrInvalidChecksum) {
+		t.Errorf("short claim = %v, want ErrInvalidChecksum", err)
+	}
+	folder := childNodes(list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}))[0]
+	if folder.Type != types.NodeTypeFolder {
+		t.Fatalf("first child %s is not a folder", folder.Path)
+	}
+	if _, err := s.VerifyChecksum(ctx, folder.ID, *file.Checksum); !errors.Is(err, ErrNotAFile) {
+		t.Errorf("verifying a folder = %v, want ErrNotAFile", err)
+	}
+}
@@ -0,0 +1,145 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// DefaultWalkMaxNodes caps a walk whose request leaves MaxNodes unset
+const DefaultWalkMaxNodes = 100000
+
+// ErrWalkLimitReached is returned by WalkFunc when the node cap stops a walk before the subtree is exhausted
+var ErrWalkLimitReached = errors.New("walk node limit reached")
+
+// ErrWalkTargetNotDir is returned when a walk starts from a file
+var ErrWalkTargetNotDir = newError(ErrInvalidInput, "walk start is not a folder")
+
+// ErrInvalidWalkOptions is returned for a negative MaxDepth or MaxNodes
+var ErrInvalidWalkOptions = newError(ErrInvalidInput, "invalid walk options")
+
+// walkFrame is a node waiting on the walk stack with its depth below the starting folder
+type walkFrame struct {
+	node  *types.Node
+	depth int
+}
+
+// Walk returns the subtree below the requested folder flattened in depth-first order
+// Folders are generated lazily as the walk descends; Truncated is set when MaxNodes stopped it
+func (s *SpectraFS) Walk(ctx context.Context, req interface {
+	models.ParentIdentifier
+	models.WalkOptions
+}) (*types.WalkResult, error) {
+	result := &types.WalkResult{Nodes: make([]types.WalkEntry, 0)}
+	err := s.walk(ctx, req, func(node *types.Node, depth int) error {
+		result.Nodes = append(result.Nodes, types.WalkEntry{Depth: depth, Node: node})
+		return nil
+	})
+	if errors.Is(err, ErrWalkLimitReached) {
+		result.Truncated = true
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// WalkFunc calls fn for every node below the requested folder in depth-first order
+// Folders are generated lazily as the walk descends. An error from fn stops the walk and is
+// returned; ErrWalkLimitReached is returned if MaxNodes nodes were visited and more remain, and
+// ctx.Err() if ctx is cancelled.
+func (s *SpectraFS) WalkFunc(ctx context.Context, req interface {
+	models.ParentIdentifier
+	models.WalkOptions
+}, fn func(*types.Node) error) error {
+	return s.walk(ctx, req, func(node *types.Node, _ int) error {
+		return fn(node)
+	})
+}
+
+// walk resolves the starting folder and visits its subtree pre-order through ListChildren,
+// so generation, existence and retention behave exactly as in a level-by-level listing
+func (s *SpectraFS) walk(ctx context.Context, req interface {
+	models.ParentIdentifier
+	models.WalkOptions
+}, visit func(node *types.Node, depth int) error) error {
+	if err := validateRequest(models.ValidateParentIdentifier(req)); err != nil {
+		return err
+	}
+
+	maxDepth, maxNodes := req.GetMaxDepth(), req.GetMaxNodes()
+	if maxDepth < 0 || maxNodes < 0 {
+		return fmt.Errorf("%w: max_depth and max_nodes must be non-negative", ErrInvalidWalkOptions)
+	}
+	if maxNodes == 0 {
+		maxNodes = DefaultWalkMaxNodes
+	}
+
+	start, world, err := s.resolveNodeAndWorld(req)
+	if err != nil {
+		return fmt.Errorf("failed to resolve walk start: %w", err)
+	}
+	if err := s.checkWorld(world); err != nil {
+		return err
+	}
+	if !start.ExistenceMap[world] {
+		return fmt.Errorf("%w: %s does not exist in world %s", ErrNodeNotFound, start.Path, world)
+	}
+	if start.Type != types.NodeTypeFolder {
+		return fmt.Errorf("%w: %s", ErrWalkTargetNotDir, start.Path)
+	}
+
+	stack, err := s.walkChildren(ctx, start, world, 1, nil)
+	if err != nil {
+		return err
+	}
+
+	visited := 0
+	for len(stack) > 0 {
+		frame := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if visited == maxNodes {
+			return ErrWalkLimitReached
+		}
+		if err := visit(frame.node, frame.depth); err != nil {
+			return err
+		}
+		visited++
+
+		if frame.node.Type == types.NodeTypeFolder && (maxDepth == 0 || frame.depth < maxDepth) {
+			if stack, err = s.walkChildren(ctx, frame.node, world, frame.depth+1, stack); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// walkChildren lists a folder's children and pushes them onto the stack in reverse listing order,
+// so they are popped (and visited) in listing order
+func (s *SpectraFS) walkChildren(ctx context.Context, parent *types.Node, world string, depth int, stack []walkFrame) ([]walkFrame, error) {
+	result, err := s.ListChildren(ctx, &models.ListChildrenRequest{ParentID: parent.ID, TableName: world})
+	if err != nil {
+		return nil, err
+	}
+	if !result.Success {
+		return nil, fmt.Errorf("failed to list %s: %s", parent.Path, result.Message)
+	}
+
+	for i := len(result.Symlinks) - 1; i >= 0; i-- {
+		stack = append(stack, walkFrame{node: &result.Symlinks[i].Node, depth: depth})
+	}
+	for i := len(result.Folders) - 1; i >= 0; i-- {
+		stack = append(stack, walkFrame{node: &result.Folders[i].Node, depth: depth})
+	}
+	for i := len(result.Files) - 1; i >= 0; i-- {
+		stack = append(stack, walkFrame{node: &result.Files[i].Node, depth: depth})
+	}
+	return stack, nil
+}
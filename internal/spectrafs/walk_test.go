@@ -0,0 +1,95 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestWalkGeneratesWholeTree(t *testing.T) {
+	reference := generatedFS(t)
+	want := make(map[string]*types.Node)
+	for id, node := range storedNodes(t, reference) {
+		if id != reference.root && node.ExistenceMap["primary"] {
+			want[id] = node
+		}
+	}
+
+	s := newTestFS(t)
+	result, err := s.Walk(context.Background(), &models.WalkRequest{ParentID: s.root, TableName: "primary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Truncated || len(result.Nodes) != len(want) {
+		t.Fatalf("Walk on a fresh instance = %d nodes (truncated %v), want the %d of the generated tree", len(result.Nodes), result.Truncated, len(want))
+	}
+
+	// Depth-first: every node comes after its parent, at its depth below the root
+	seen := map[string]bool{s.root: true}
+	for _, entry := range result.Nodes {
+		node := entry.Node
+		if want[node.ID] == nil || want[node.ID].Path != node.Path {
+			t.Errorf("walked %s (%s), not in the generated tree", node.Path, node.ID)
+		}
+		if !seen[node.ParentID] {
+			t.Errorf("walked %s before its parent", node.Path)
+		}
+		if entry.Depth != node.DepthLevel {
+			t.Errorf("%s has depth %d, want %d", node.Path, entry.Depth, node.DepthLevel)
+		}
+		seen[node.ID] = true
+	}
+}
+
+func TestWalkLimits(t *testing.T) {
+	s := newTestFS(t)
+	ctx := context.Background()
+	children := childNodes(list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"}))
+
+	shallow, err := s.Walk(ctx, &models.WalkRequest{ParentID: s.root, TableName: "primary", MaxDepth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shallow.Nodes) != len(children) || shallow.Truncated {
+		t.Errorf("Walk with max_depth 1 = %d nodes (truncated %v), want the root's %d children", len(shallow.Nodes), shallow.Truncated, len(children))
+	}
+
+	capped, err := s.Walk(ctx, &models.WalkRequest{ParentID: s.root, TableName: "primary", MaxNodes: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(capped.Nodes) != 3 || !capped.Truncated {
+		t.Errorf("Walk with max_nodes 3 = %d nodes (truncated %v), want 3, truncated", len(capped.Nodes), capped.Truncated)
+	}
+
+	visited := 0
+	err = s.WalkFunc(ctx, &models.WalkRequest{ParentID: s.root, TableName: "primary", MaxNodes: 3}, func(*types.Node) error {
+		visited++
+		return nil
+	})
+	if !errors.Is(err, ErrWalkLimitReached) || visited != 3 {
+		t.Errorf("WalkFunc with max_nodes 3 visited %d nodes and returned %v, want 3 and ErrWalkLimitReached", visited, err)
+	}
+
+	stop := errors.New("stop")
+	if err := s.WalkFunc(ctx, &models.WalkRequest{ParentID: s.root, TableName: "primary"}, func(*types.Node) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("WalkFunc with a failing callback = %v, want its error", err)
+	}
+
+	var file *types.Node
+	for _, child := range children {
+		if child.Type == types.NodeTypeFile {
+			file = child
+			break
+		}
+	}
+	if _, err := s.Walk(ctx, &models.WalkRequest{ParentID: file.ID}); !errors.Is(err, ErrWalkTargetNotDir) {
+		t.Errorf("Walk from a file = %v, want ErrWalkTargetNotDir", err)
+	}
+	if _, err := s.Walk(ctx, &models.WalkRequest{ParentID: s.root, MaxDepth: -1}); !errors.Is(err, ErrInvalidWalkOptions) {
+		t.Errorf("Walk with a negative max_depth = %v, want ErrInvalidWalkOptions", err)
+	}
+}
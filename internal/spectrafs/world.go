@@ -0,0 +1,168 @@
+package spectrafs
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Project-Sylos/Spectra/internal/generator"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// ErrWorldExists is returned when AddWorld is given primary or a world that is already registered
+var ErrWorldExists = newError(ErrConflict, "world already exists")
+
+// ErrInvalidWorld is returned for an empty world name, an out-of-range probability, or removing primary
+var ErrInvalidWorld = newError(ErrInvalidInput, "invalid world")
+
+// secondaryWorlds returns the current secondary worlds and their probabilities
+// The map is replaced with the config, never mutated, so callers may range over it without holding worldsMu
+func (s *SpectraFS) secondaryWorlds() map[string]float64 {
+	s.worldsMu.RLock()
+	defer s.worldsMu.RUnlock()
+	return s.config().SecondaryTables
+}
+
+// WorldNames returns the valid world names: primary, then the secondary worlds in name order
+func (s *SpectraFS) WorldNames() []string {
+	return append([]string{"primary"}, slices.Sorted(maps.Keys(s.secondaryWorlds()))...)
+}
+
+// Worlds returns the world registry: primary, then each secondary world in name order with the
+// probability that a generated node exists in it and its extra-node probability, if any
+func (s *SpectraFS) Worlds() []types.WorldInfo {
+	s.worldsMu.RLock()
+	cfg := s.config() // One snapshot of the worlds and their settings
+	s.worldsMu.RUnlock()
+
+	worlds := []types.WorldInfo{{Name: "primary", Type: "primary", Probability: 1.0, RetentionRules: len(cfg.Retention["primary"])}}
+	for _, name := range slices.Sorted(maps.Keys(cfg.SecondaryTables)) {
+		worlds = append(worlds, types.WorldInfo{
+			Name:                  name,
+			Type:                  "secondary",
+			Probability:           cfg.SecondaryTables[name],
+			ExtraNodesProbability: cfg.WorldGeneration[name].ExtraNodesProbability,
+			RetentionRules:        len(cfg.Retention[name]),
+		})
+	}
+	return worlds
+}
+
+// checkWorld returns ErrUnknownWorld, naming the valid worlds, unless world is primary or a
+// configured secondary world. Callers default an empty world to primary first
+func (s *SpectraFS) checkWorld(world string) error {
+	if s.isKnownWorld(world) {
+		return nil
+	}
+	return fmt.Errorf("%w: %q (valid worlds: %s)", ErrUnknownWorld, world, strings.Join(s.WorldNames(), ", "))
+}
+
+// retentionRules returns the current retention rules by world
+func (s *SpectraFS) retentionRules() map[string][]types.RetentionRule {
+	s.worldsMu.RLock()
+	defer s.worldsMu.RUnlock()
+	return s.config().Retention
+}
+
+// AddWorld registers a secondary world at runtime and backfills every node's existence in it
+// Each node's dice are seeded by the world and its path (see generator.BackfillExistence), so the
+// same tree always gets the same replica. Nodes are annotated in batches behind a pending marker,
+// and a crash mid-way rolls the world back on the next open. Returns the new world's table info.
+// The stored generation settings gain the world, so add it to secondary_tables before the next open
+func (s *SpectraFS) AddWorld(name string, probability float64) (*types.TableInfo, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidWorld)
+	}
+	if probability < 0.0 || probability > 1.0 {
+		return nil, fmt.Errorf("%w: probability must be between 0.0 and 1.0, got %f", ErrInvalidWorld, probability)
+	}
+
+	s.exclusive.Lock()
+	defer s.exclusive.Unlock()
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if s.isKnownWorld(name) {
+		return nil, fmt.Errorf("%w: %s", ErrWorldExists, name)
+	}
+
+	count, err := s.db.AddWorld(name, func(node *types.Node, parentExists bool) bool {
+		return generator.BackfillExistence(s.config(), name, probability, node, parentExists)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace rather than mutate the config, so lock-free readers see either the old or the new worlds
+	s.worldsMu.Lock()
+	defer s.worldsMu.Unlock()
+	cfg := *s.config()
+	cfg.SecondaryTables = maps.Clone(cfg.SecondaryTables)
+	if cfg.SecondaryTables == nil {
+		cfg.SecondaryTables = make(map[string]float64)
+	}
+	cfg.SecondaryTables[name] = probability
+	s.cfg.Store(&cfg)
+	if err := s.recordWorlds(); err != nil {
+		return nil, err
+	}
+
+	if err := s.journal(worldChange(name, count)); err != nil {
+		return nil, err
+	}
+	return &types.TableInfo{Name: name, RowCount: count, TableType: "secondary"}, nil
+}
+
+// RemoveWorld unregisters a secondary world and strips it from every node's existence map
+// Its retention and world_generation settings are dropped with it. A crash mid-way is completed
+// on the next open. The stored generation settings drop the world, as must secondary_tables
+func (s *SpectraFS) RemoveWorld(name string) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	if name == "primary" {
+		return fmt.Errorf("%w: primary cannot be removed", ErrInvalidWorld)
+	}
+
+	s.exclusive.Lock()
+	defer s.exclusive.Unlock()
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := s.checkWorld(name); err != nil {
+		return err
+	}
+
+	if err := s.db.RemoveWorld(name); err != nil {
+		return err
+	}
+
+	// Replace rather than mutate the config, as AddWorld does
+	s.worldsMu.Lock()
+	defer s.worldsMu.Unlock()
+	cfg := *s.config()
+	cfg.SecondaryTables = maps.Clone(cfg.SecondaryTables)
+	delete(cfg.SecondaryTables, name)
+	if _, ok := cfg.Retention[name]; ok {
+		cfg.Retention = maps.Clone(cfg.Retention)
+		delete(cfg.Retention, name)
+	}
+	if _, ok := cfg.WorldGeneration[name]; ok {
+		cfg.WorldGeneration = maps.Clone(cfg.WorldGeneration)
+		delete(cfg.WorldGeneration, name)
+	}
+	s.cfg.Store(&cfg)
+	if err := s.recordWorlds(); err != nil {
+		return err
+	}
+	return s.journal(worldChange(name, 0))
+}
+
+// worldChange describes a world being added to or removed from every node's existence map
+func worldChange(name string, count int) change {
+	return change{ChangeEvent: types.ChangeEvent{Op: types.ChangeExistence, NodeID: "root", Path: "/", Worlds: []string{name}, Nodes: count}}
+}
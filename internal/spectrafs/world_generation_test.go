@@ -0,0 +1,62 @@
+package spectrafs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/config"
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestWorldOnlyNodes(t *testing.T) {
+	s := newTestFS(t, func(cfg *types.Config) {
+		cfg.SecondaryTables = map[string]float64{"s1": 1}
+		cfg.WorldGeneration = map[string]types.WorldGeneration{"s1": {ExtraNodesProbability: 1}}
+	})
+	ctx := context.Background()
+	if _, err := s.GenerateAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	inS1, worldOnly := 0, 0
+	for _, node := range storedNodes(t, s) {
+		if node.ExistenceMap["s1"] {
+			inS1++
+		}
+		if node.ExistenceMap["s1"] && !node.ExistenceMap["primary"] {
+			worldOnly++
+		}
+	}
+	if worldOnly == 0 {
+		t.Fatal("extra_nodes_probability 1 generated no s1-only nodes")
+	}
+	if count, err := s.GetNodeCount(ctx, "s1"); err != nil || count != inS1 {
+		t.Errorf("GetNodeCount(s1) = %d, %v, want %d", count, err, inS1)
+	}
+
+	// The root's extra children are listed in s1 only
+	onlyInS1 := func(node *types.Node) bool { return !node.ExistenceMap["primary"] }
+	for _, world := range []string{"primary", "s1"} {
+		extra := 0
+		for _, child := range childNodes(list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: world})) {
+			if onlyInS1(child) {
+				extra++
+			}
+		}
+		if world == "primary" && extra != 0 {
+			t.Errorf("primary listing of the root has %d s1-only children", extra)
+		}
+		if world == "s1" && extra == 0 {
+			t.Error("s1 listing of the root has no s1-only children")
+		}
+	}
+}
+
+func TestWorldGenerationRequiresDeclaredWorld(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.WorldGeneration = map[string]types.WorldGeneration{"s2": {ExtraNodesProbability: 0.5}}
+	if err := config.Validate(cfg); err == nil {
+		t.Error("Validate accepted world_generation for an undeclared world")
+	}
+}
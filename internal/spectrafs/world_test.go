@@ -0,0 +1,75 @@
+package spectrafs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+func TestAddWorldMatchesConfiguredWorld(t *testing.T) {
+	s := generatedFS(t)
+	info, err := s.AddWorld("s2", 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A world added at runtime gets the tree it would have had if it had been configured
+	reference := newTestFS(t, func(cfg *types.Config) {
+		cfg.SecondaryTables = map[string]float64{"s1": 0.7, "s2": 0.5}
+	})
+	if _, err := reference.GenerateAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := storedNodes(t, reference)
+	inS2 := 0
+	for id, node := range storedNodes(t, s) {
+		if node.ExistenceMap["s2"] != want[id].ExistenceMap["s2"] {
+			t.Errorf("%s in s2 = %v after AddWorld, %v when configured", node.Path, node.ExistenceMap["s2"], want[id].ExistenceMap["s2"])
+		}
+		if node.ExistenceMap["s2"] {
+			inS2++
+		}
+	}
+	if info.RowCount != inS2 {
+		t.Errorf("AddWorld reported %d nodes, %d are in s2", info.RowCount, inS2)
+	}
+	if count, err := s.GetNodeCount(context.Background(), "s2"); err != nil || count != inS2 {
+		t.Errorf("GetNodeCount(s2) = %d, %v, want %d", count, err, inS2)
+	}
+	if !slices.Contains(s.WorldNames(), "s2") {
+		t.Errorf("WorldNames() = %v, want s2 registered", s.WorldNames())
+	}
+
+	if err := s.RemoveWorld("s2"); err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range storedNodes(t, s) {
+		if _, ok := node.ExistenceMap["s2"]; ok {
+			t.Fatalf("%s still has s2 in its existence map after RemoveWorld", node.Path)
+		}
+	}
+	if err := s.checkWorld("s2"); err == nil {
+		t.Error("s2 is still a valid world after RemoveWorld")
+	}
+}
+
+func TestAddAndRemoveWorldRejects(t *testing.T) {
+	s := newTestFS(t)
+	for _, name := range []string{"primary", "s1"} {
+		if _, err := s.AddWorld(name, 0.5); !errors.Is(err, ErrWorldExists) {
+			t.Errorf("AddWorld(%s) = %v, want ErrWorldExists", name, err)
+		}
+	}
+	if _, err := s.AddWorld("s2", 1.5); !errors.Is(err, ErrInvalidWorld) {
+		t.Errorf("AddWorld with probability 1.5 = %v, want ErrInvalidWorld", err)
+	}
+	if err := s.RemoveWorld("primary"); !errors.Is(err, ErrInvalidWorld) {
+		t.Errorf("RemoveWorld(primary) = %v, want ErrInvalidWorld", err)
+	}
+	if err := s.RemoveWorld("s2"); err == nil {
+		t.Error("RemoveWorld of an unknown world succeeded")
+	}
+}
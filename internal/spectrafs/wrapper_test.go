@@ -0,0 +1,195 @@
+package spectrafs
+
+import (
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// primaryNames returns the fs.FS names of every stored node present in primary, root excluded,
+// sorted, and the depth of the deepest
+func primaryNames(t *testing.T, s *SpectraFS) ([]string, int) {
+	t.Helper()
+	var names []string
+	deepest := 0
+	for _, node := range storedNodes(t, s) {
+		if node.ID == s.root || !node.ExistenceMap["primary"] {
+			continue
+		}
+		names = append(names, strings.TrimPrefix(node.Path, "/"))
+		deepest = max(deepest, node.DepthLevel)
+	}
+	slices.Sort(names)
+	return names, deepest
+}
+
+func TestWrapperWalkDirGeneratesFullTree(t *testing.T) {
+	want, deepest := primaryNames(t, generatedFS(t))
+	if deepest != testConfig(t).Seed.MaxDepth {
+		t.Fatalf("reference tree is %d deep, want MaxDepth %d", deepest, testConfig(t).Seed.MaxDepth)
+	}
+
+	var got []string
+	err := fs.WalkDir(NewSpectraFSWrapper(newTestFS(t), "primary"), ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." {
+			got = append(got, name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Errorf("WalkDir of a fresh instance visited %d names, GenerateAll stored %d", len(got), len(want))
+	}
+}
+
+func TestWrapperStatGeneratesPath(t *testing.T) {
+	names, _ := primaryNames(t, generatedFS(t))
+	deepest := slices.MaxFunc(names, func(a, b string) int { return strings.Count(a, "/") - strings.Count(b, "/") })
+
+	// Nothing below the root has been listed on the fresh instance
+	info, err := fs.Stat(NewSpectraFSWrapper(newTestFS(t), "primary"), deepest)
+	if err != nil {
+		t.Fatalf("Stat(%s) on a fresh instance: %v", deepest, err)
+	}
+	if info.Name() != path.Base(deepest) {
+		t.Errorf("Stat(%s) named %s", deepest, info.Name())
+	}
+}
+
+func TestWrapperPassesFSTest(t *testing.T) {
+	want, _ := primaryNames(t, generatedFS(t))
+	wrapper := NewSpectraFSWrapper(newTestFS(t), "primary")
+	if err := fstest.TestFS(wrapper, want...); err != nil {
+		t.Fatal(err)
+	}
+
+	// Names are sorted, so a folder is directly followed by its first child
+	var dir string
+	for i := range len(want) - 1 {
+		if strings.HasPrefix(want[i+1], want[i]+"/") {
+			dir = want[i]
+			break
+		}
+	}
+	sub, err := fs.Sub(wrapper, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var below []string
+	for _, name := range want {
+		if rest, ok := strings.CutPrefix(name, dir+"/"); ok {
+			below = append(below, rest)
+		}
+	}
+	if err := fstest.TestFS(sub, below...); err != nil {
+		t.Errorf("Sub(%s): %v", dir, err)
+	}
+}
+
+func TestWrapperGlobReachesUngeneratedDepths(t *testing.T) {
+	want, _ := primaryNames(t, generatedFS(t))
+	var deep []string
+	for _, name := range want {
+		if strings.Count(name, "/") == 2 {
+			deep = append(deep, name)
+		}
+	}
+
+	got, err := fs.Glob(NewSpectraFSWrapper(newTestFS(t), "primary"), "*/*/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, deep) {
+		t.Errorf("Glob(*/*/*) on a fresh instance = %d names, want %d", len(got), len(deep))
+	}
+}
+
+func TestWrapperReadDirIsLexical(t *testing.T) {
+	s := newTestFS(t)
+	dir := mkdir(t, s, s.root, "dir")
+	mkdir(t, s, dir.ID, "b")
+	upload(t, s, dir.ID, "a.txt", []byte("a"))
+	mkdir(t, s, dir.ID, "c")
+
+	// fs.ReadDirFS requires entries sorted by name, whatever their type
+	entries, err := fs.ReadDir(NewSpectraFSWrapper(s, "primary"), "dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if want := []string{"a.txt", "b", "c"}; !slices.Equal(names, want) {
+		t.Errorf("ReadDir = %v, want %v", names, want)
+	}
+
+	// ListChildren keeps folders first
+	names = nil
+	for _, node := range childNodes(list(t, s, &models.ListChildrenRequest{ParentID: dir.ID, TableName: "primary"})) {
+		names = append(names, node.Name)
+	}
+	if want := []string{"b", "c", "a.txt"}; !slices.Equal(names, want) {
+		t.Errorf("ListChildren = %v, want %v", names, want)
+	}
+}
+
+func TestDirHandleKeepsListingAcrossPages(t *testing.T) {
+	total := 2*dirPageSize + 500
+	s := newTestFS(t, func(cfg *types.Config) {
+		cfg.Seed.MaxDepth = 1
+		cfg.Seed.MinFolders, cfg.Seed.MaxFolders = 0, 0
+		cfg.Seed.MinFiles, cfg.Seed.MaxFiles = total, total
+	})
+
+	file, err := NewSpectraFSWrapper(s, "primary").Open(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	dir := file.(fs.ReadDirFile)
+
+	var names []string
+	for {
+		entries, err := dir.ReadDir(300)
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		// A file added while the handle is open is not picked up by a later page
+		if len(names) == 300 {
+			upload(t, s, s.root, "late.txt", []byte("late"))
+		}
+	}
+
+	var want []string
+	for _, node := range childNodes(list(t, s, &models.ListChildrenRequest{ParentID: s.root, TableName: "primary"})) {
+		if node.Name != "late.txt" {
+			want = append(want, node.Name)
+		}
+	}
+	if len(want) != total {
+		t.Fatalf("listing has %d children besides late.txt, want %d", len(want), total)
+	}
+	if !slices.Equal(names, want) {
+		t.Errorf("ReadDir(300) over %d pages returned %d names, want the %d of the listing in order", (total+299)/300, len(names), len(want))
+	}
+}
@@ -1,48 +1,356 @@
 package types
 
 import (
+	"encoding/json"
 	"time"
 )
 
 // Config represents the complete configuration for Spectra
 type Config struct {
-	Seed            SeedConfig         `json:"seed"`
-	API             APIConfig          `json:"api"`
-	SecondaryTables map[string]float64 `json:"secondary_tables"`
+	Seed            SeedConfig                 `json:"seed"`
+	API             APIConfig                  `json:"api"`
+	DB              DBConfig                   `json:"db"`
+	SecondaryTables map[string]float64         `json:"secondary_tables"`
+	Retention       map[string][]RetentionRule `json:"retention,omitempty"`         // Per-world retention rules, keyed by world name
+	WorldGeneration map[string]WorldGeneration `json:"world_generation,omitempty"`  // Per-world extra-node settings, keyed by secondary world name
+	Debug           DebugConfig                `json:"debug,omitempty"`             // Testing hooks; never set in production configs
+	Chaos           ChaosConfig                `json:"chaos,omitempty"`             // Simulated latency and errors for exercising client retries
+	Mutations       MutationsConfig            `json:"mutations,omitempty"`         // Scripted changes to the stored tree over time
+	RootDisplayName string                     `json:"root_display_name,omitempty"` // Name reported for the root node (cosmetic; the ID stays "root")
+	Instances       []InstanceConfig           `json:"instances,omitempty"`         // Extra named filesystems hosted alongside this one, each with its own database
+
+	MaintenanceSchedule map[string]string `json:"maintenance_schedule,omitempty"` // Task name -> interval (Go duration, e.g. "30m")
+}
+
+// InstanceConfig is a named filesystem hosted alongside the main one (see Config.Instances)
+// Every other config section is shared with the main filesystem
+type InstanceConfig struct {
+	Name string     `json:"name"`
+	Seed SeedConfig `json:"seed"` // The main seed section with the instance's own settings laid over it
 }
 
 // SeedConfig represents the filesystem generation configuration
 type SeedConfig struct {
-	MaxDepth       int    `json:"max_depth"`
-	MinFolders     int    `json:"min_folders"`
-	MaxFolders     int    `json:"max_folders"`
-	MinFiles       int    `json:"min_files"`
-	MaxFiles       int    `json:"max_files"`
-	Seed           int64  `json:"seed"`
-	DBPath         string `json:"db_path"`
-	FileBinarySeed int64  `json:"file_binary_seed,omitempty"`
+	MaxDepth             int     `json:"max_depth"`
+	MinFolders           int     `json:"min_folders"`
+	MaxFolders           int     `json:"max_folders"`
+	MinFiles             int     `json:"min_files"`
+	MaxFiles             int     `json:"max_files"`
+	Seed                 int64   `json:"seed"`
+	DBPath               string  `json:"db_path"`
+	FileBinarySeed       int64   `json:"file_binary_seed,omitempty"`       // Seed of file content (0 = derived from seed, see generator.FileBinarySeed)
+	IdenticalContent     bool    `json:"identical_file_content,omitempty"` // Every file shares the file_binary_seed content instead of per-node content
+	TimestampStepMillis  int64   `json:"timestamp_step_ms,omitempty"`      // Spacing between generated siblings' LastUpdated (0 = 1ms)
+	BaseTimestamp        string  `json:"base_timestamp,omitempty"`         // RFC 3339 time generated LastUpdated values start from (default 2024-01-01T00:00:00Z)
+	TimestampJitter      string  `json:"timestamp_jitter,omitempty"`       // Go duration; each generated node's LastUpdated is offset by up to this much (default none)
+	MinFileSize          int64   `json:"min_file_size,omitempty"`          // Smallest generated file in bytes (both sizes unset = 1024)
+	MaxFileSize          int64   `json:"max_file_size,omitempty"`          // Largest generated file in bytes
+	FileSizeCap          int64   `json:"file_size_cap,omitempty"`          // Upper bound accepted for max_file_size (0 = 64MiB)
+	PerFileBandwidth     int64   `json:"per_file_bandwidth,omitempty"`     // Bytes per second for each opened file reader (0 = unlimited)
+	SymlinkProbability   float64 `json:"symlink_probability,omitempty"`    // Chance that a generated folder also gets a symlink among its children (0 = never)
+	DuplicateProbability float64 `json:"duplicate_probability,omitempty"`  // Chance that a generated file shares its content with other files (0 = never, see generator.DuplicatePoolSize)
+	RandomIDs            bool    `json:"random_ids,omitempty"`             // Give generated nodes random UUIDs instead of ones derived from the seed (see generator.NodeID)
+
+	MetadataProbability float64             `json:"metadata_probability,omitempty"` // Chance that a generated node gets metadata drawn from metadata_pool (0 = never)
+	MetadataPool        map[string][]string `json:"metadata_pool,omitempty"`        // Metadata key -> value patterns, "{n}" standing for a random number (empty = DefaultMetadataPool)
+
+	NameStrategy       string             `json:"name_strategy,omitempty"`        // "simple" (folder_N, file_N.txt; the default), "realistic" or "custom"
+	ExtensionWeights   map[string]float64 `json:"extension_weights,omitempty"`    // File extension -> relative weight for realistic and custom names (empty = DefaultExtensionWeights)
+	FolderNamePatterns []string           `json:"folder_name_patterns,omitempty"` // Custom folder name templates with {word}, {num} and {ext} placeholders (empty = the realistic ones)
+	FileNamePatterns   []string           `json:"file_name_patterns,omitempty"`   // Custom file name templates with {word}, {num} and {ext} placeholders (empty = the realistic ones)
+
+	Profile *GenerationProfile `json:"profile,omitempty"` // Per-depth count ranges and long-tail folders (nil = the flat ranges above at every depth)
+
+	MaxTotalNodes int64 `json:"max_total_nodes,omitempty"` // Generation stops expanding folders once this many nodes are stored (0 = unlimited)
+	MaxTotalBytes int64 `json:"max_total_bytes,omitempty"` // Generation stops expanding folders once stored files total this many bytes (0 = unlimited)
+}
+
+// GenerationProfile shapes a generated tree like a real filesystem: count ranges that vary with
+// depth, and a long tail of folders with many times the usual number of children
+type GenerationProfile struct {
+	Depths             []DepthProfile `json:"depths,omitempty"`               // Count ranges by depth; the first entry covering a depth wins, others use the seed's ranges
+	LongTailFraction   float64        `json:"long_tail_fraction,omitempty"`   // Chance that a generated folder is a long-tail folder (0 = none)
+	LongTailMultiplier int            `json:"long_tail_multiplier,omitempty"` // Factor on a long-tail folder's drawn counts (0 = 10)
+}
+
+// DepthProfile sets the child count ranges of folders at depths MinDepth through MaxDepth (the root is
+// depth 0, so its children are drawn from the entry covering 0); unset bounds fall back to the seed's
+type DepthProfile struct {
+	MinDepth   int  `json:"min_depth"`
+	MaxDepth   int  `json:"max_depth"`
+	MinFolders *int `json:"min_folders,omitempty"`
+	MaxFolders *int `json:"max_folders,omitempty"`
+	MinFiles   *int `json:"min_files,omitempty"`
+	MaxFiles   *int `json:"max_files,omitempty"`
 }
 
 // APIConfig represents the HTTP API configuration
 type APIConfig struct {
-	Host string `json:"host"`
-	Port int    `json:"port"`
+	Host           string `json:"host"`
+	Port           int    `json:"port"`
+	ResponseCase   string `json:"response_case,omitempty"`   // "snake" (default) or "camel" for legacy clients
+	WebDAVEnabled  bool   `json:"webdav_enabled,omitempty"`  // Mounts the read/write WebDAV view at /dav/{world}
+	MetricsEnabled bool   `json:"metrics_enabled,omitempty"` // Records request, generation and transaction metrics and serves them at /metrics
+
+	LogLevel  string `json:"log_level,omitempty"`  // "debug", "info" (default), "warn" or "error"; debug adds generation and transaction details
+	LogFormat string `json:"log_format,omitempty"` // "text" (default) or "json", written to stderr
+
+	MaxReadBandwidth int64 `json:"max_read_bandwidth,omitempty"` // Bytes per second shared by all file content reads of the instance (0 = unlimited)
+	MaxUploadBytes   int64 `json:"max_upload_bytes,omitempty"`   // Largest accepted upload body; larger ones get a 413 (0 = 32MiB)
+	MaxJobs          int   `json:"max_jobs,omitempty"`           // Background jobs run at once per instance; later ones queue (0 = 2)
+
+	ReadTimeout     string `json:"read_timeout,omitempty"`     // Go duration for reading a whole request (default "15s")
+	WriteTimeout    string `json:"write_timeout,omitempty"`    // Go duration for writing a response (default "15s"; event streams are exempt)
+	IdleTimeout     string `json:"idle_timeout,omitempty"`     // Go duration a keep-alive connection may sit idle (default "60s")
+	ShutdownTimeout string `json:"shutdown_timeout,omitempty"` // Go duration in-flight requests get to drain on shutdown (default "30s")
+
+	Auth AuthConfig `json:"auth,omitempty"` // Static API tokens; the API is open when none are listed
+}
+
+// AuthConfig lists the tokens accepted by the API, sent as "Authorization: Bearer <token>" or "X-API-Key: <token>"
+type AuthConfig struct {
+	Tokens []AuthToken `json:"tokens,omitempty"`
+}
+
+// AuthToken is one static API token and the role it grants
+type AuthToken struct {
+	Token string `json:"token"`
+	Role  string `json:"role,omitempty"` // "read", "write" or "admin" (default "admin")
+	Name  string `json:"name,omitempty"` // Label for the token's holder (informational)
+}
+
+// API token roles; each includes the ones before it
+const (
+	RoleRead  = "read"  // Listing, reading and searching
+	RoleWrite = "write" // Creating, uploading, moving, renaming and deleting nodes, and driving generation and mutations
+	RoleAdmin = "admin" // Reset, import, chaos settings and debug hooks
+)
+
+// AuthRoles lists the token roles from least to most privileged
+var AuthRoles = []string{RoleRead, RoleWrite, RoleAdmin}
+
+// RetentionRule expires nodes under a path prefix once their synthetic LastUpdated is older than the TTL
+type RetentionRule struct {
+	PathPrefix string `json:"path_prefix"` // Absolute path prefix the rule applies to (e.g. "/folder_1")
+	TTLSeconds int64  `json:"ttl_seconds"` // Seconds after LastUpdated at which matching nodes expire
+}
+
+// WorldGeneration makes a secondary world diverge from primary with nodes that exist only in that world
+// Each generated folder present in the world gets, with ExtraNodesProbability, an extra batch of
+// folders and files whose counts are drawn from the ranges below (unset bounds fall back to seed's)
+type WorldGeneration struct {
+	MinFolders            *int    `json:"min_folders,omitempty"`
+	MaxFolders            *int    `json:"max_folders,omitempty"`
+	MinFiles              *int    `json:"min_files,omitempty"`
+	MaxFiles              *int    `json:"max_files,omitempty"`
+	ExtraNodesProbability float64 `json:"extra_nodes_probability,omitempty"` // Chance per folder of an extra batch (0 = never)
+}
+
+// Preload modes for DBConfig.Preload
+const (
+	PreloadNone  = "none"  // No warm start; every read goes to BoltDB
+	PreloadIndex = "index" // Keep the parentID -> childIDs index in memory
+	PreloadFull  = "full"  // Additionally keep every decoded node in memory
+)
+
+// Freelist types for DBConfig.FreelistType
+const (
+	FreelistArray = "array" // bbolt's default
+	FreelistMap   = "map"   // Hashmap freelist, faster to allocate from on large, fragmented files
+)
+
+// DBConfig represents the storage layer configuration
+type DBConfig struct {
+	Preload           string `json:"preload,omitempty"`             // "none" (default), "index" or "full"
+	PreloadMaxBytes   int64  `json:"preload_max_bytes,omitempty"`   // Memory cap for "full" preload (0 = unlimited)
+	AutoRepair        bool   `json:"auto_repair,omitempty"`         // Rebuild indexes and stats on open when a recovery pass finds severe issues
+	CheckIntegrity    bool   `json:"check_integrity,omitempty"`     // Quick index check on open and before a clean shutdown is recorded
+	JournalMaxEntries int64  `json:"journal_max_entries,omitempty"` // Change journal length before the oldest events are pruned (0 = 10000)
+	ReadOnly          bool   `json:"read_only,omitempty"`           // Open an existing database without ever writing to it (writes return ErrReadOnly)
+	SnapshotKeep      int    `json:"snapshot_keep,omitempty"`       // Newest named snapshots the snapshot-cleanup maintenance task keeps (0 = all)
+
+	CompactOnCloseRatio float64 `json:"compact_on_close_ratio,omitempty"` // Compact on Close when free pages make up at least this share of the file (0 = never)
+
+	NoSync          bool   `json:"no_sync,omitempty"`           // Skip fsync on every commit; Close syncs once (a crash can lose recent commits)
+	InitialMmapSize int    `json:"initial_mmap_size,omitempty"` // Bytes to mmap on open, avoiding remaps while the file grows (0 = bbolt's default)
+	FreelistType    string `json:"freelist_type,omitempty"`     // "array" (default) or "map", which is faster on large, fragmented files
+	BulkBatchSize   int    `json:"bulk_batch_size,omitempty"`   // Nodes per transaction when BulkInsertNodes stores a large slice (0 = one transaction)
+	NodeCacheSize   int    `json:"node_cache_size,omitempty"`   // Decoded nodes kept in an LRU cache for repeated lookups (0 = no cache)
+
+	AcceptConfigChange bool `json:"accept_config_change,omitempty"` // Open a database generated with other seed, branching or world settings, storing the config's
+}
+
+// DebugConfig holds testing hooks that deliberately break the simulator
+type DebugConfig struct {
+	FailGenerationAfterNNodes int64 `json:"fail_generation_after_n_nodes,omitempty"` // Fail generation once this many nodes have been inserted (0 = off)
+	ExposeBuckets             bool  `json:"expose_buckets,omitempty"`                // Serve raw bucket contents under /api/v1/debug/buckets
+}
+
+// ChaosConfig injects latency and failures into operations so clients can exercise retry and backoff
+// Its RNG is seeded separately from generation, so enabling chaos never changes the generated tree
+type ChaosConfig struct {
+	Seed       int64                `json:"seed,omitempty"`       // Seeds the chaos RNG (0 = seeded from the clock)
+	Operations map[string]ChaosRule `json:"operations,omitempty"` // Operation name (see ChaosOperations) or "*" for any other -> rule
+}
+
+// ChaosRule is the latency and failure rate applied to one operation
+type ChaosRule struct {
+	LatencyMS []int   `json:"latency_ms,omitempty"` // [min, max] delay in milliseconds, drawn uniformly per call
+	ErrorRate float64 `json:"error_rate,omitempty"` // Probability (0.0-1.0) that a call fails
+	ErrorCode int     `json:"error_code,omitempty"` // HTTP status of an injected failure, 400-599 (default 503)
+}
+
+// Operations that chaos rules can target
+const (
+	ChaosOpList     = "list"     // Listing children (including /fs folders and WebDAV PROPFIND)
+	ChaosOpGet      = "get"      // Reading node metadata
+	ChaosOpRead     = "read"     // Reading file content
+	ChaosOpCreate   = "create"   // Creating folders
+	ChaosOpUpload   = "upload"   // Uploading files
+	ChaosOpBatch    = "batch"    // Batch creation
+	ChaosOpDelete   = "delete"   // Deleting nodes
+	ChaosOpMove     = "move"     // Moving nodes
+	ChaosOpRename   = "rename"   // Renaming nodes
+	ChaosOpCopy     = "copy"     // Copying subtrees
+	ChaosOpSearch   = "search"   // Searching nodes
+	ChaosOpWalk     = "walk"     // Walking subtrees
+	ChaosOpStatus   = "status"   // Updating traversal and copy statuses
+	ChaosOpMetadata = "metadata" // Updating node metadata
+
+	ChaosOpAny = "*" // Rule for every operation without its own
+)
+
+// ChaosOperations lists every operation name a chaos rule can target
+var ChaosOperations = []string{
+	ChaosOpList, ChaosOpGet, ChaosOpRead, ChaosOpCreate, ChaosOpUpload, ChaosOpBatch, ChaosOpDelete,
+	ChaosOpMove, ChaosOpRename, ChaosOpCopy, ChaosOpSearch, ChaosOpWalk, ChaosOpStatus, ChaosOpMetadata,
+}
+
+// MutationsConfig drives the mutation engine, which changes stored nodes over time to simulate an
+// actively changing filesystem. Its RNG is keyed by its own seed and each mutation's sequence
+// number, so the same seed applied to the same tree makes the same changes
+type MutationsConfig struct {
+	Enabled  bool            `json:"enabled,omitempty"`  // Start the engine together with the API server
+	Interval string          `json:"interval,omitempty"` // Go duration between rounds (default "10s")
+	Rate     int             `json:"rate,omitempty"`     // Mutations per round (default 1)
+	Weights  MutationWeights `json:"weights,omitempty"`  // Relative odds of each kind (all zero = equal odds)
+	Scope    string          `json:"scope,omitempty"`    // Path whose subtree is mutated (default "/")
+	World    string          `json:"world,omitempty"`    // World whose nodes are mutated (default "primary")
+	Seed     int64           `json:"seed,omitempty"`     // Seeds the mutation RNG (0 = seed.seed)
+}
+
+// MutationWeights are the relative odds of each kind of mutation
+type MutationWeights struct {
+	Create float64 `json:"create,omitempty"`
+	Modify float64 `json:"modify,omitempty"`
+	Delete float64 `json:"delete,omitempty"`
+}
+
+// Kinds of mutation
+const (
+	MutationCreate = "create" // A new file or folder in a folder whose children have been generated
+	MutationModify = "modify" // New content, size, checksum and LastUpdated for a file
+	MutationDelete = "delete" // A node removed together with its descendants
+)
+
+// MutationKinds lists every kind of mutation in the order their weights are drawn
+var MutationKinds = []string{MutationCreate, MutationModify, MutationDelete}
+
+// Mutation is one change made by the mutation engine, as recorded in the mutation log
+type Mutation struct {
+	Seq          int64     `json:"seq"` // Position in the log, starting at 1
+	Kind         string    `json:"kind"`
+	NodeID       string    `json:"node_id"`
+	Path         string    `json:"path"`
+	NodeType     string    `json:"node_type"`
+	World        string    `json:"world"`
+	At           time.Time `json:"at"`                      // New LastUpdated of a created or modified node
+	Size         int64     `json:"size,omitempty"`          // Size after a create or modify
+	Checksum     *string   `json:"checksum,omitempty"`      // Checksum after a create or modify
+	PrevSize     int64     `json:"prev_size,omitempty"`     // Size before a modify
+	PrevChecksum *string   `json:"prev_checksum,omitempty"` // Checksum before a modify
+	Removed      int       `json:"removed,omitempty"`       // Nodes removed by a delete, the node itself included
+}
+
+// MutationStatus reports the mutation engine's settings, state and progress
+type MutationStatus struct {
+	Running        bool            `json:"running"`
+	Settings       MutationsConfig `json:"settings"`                   // With defaults filled in
+	Applied        int64           `json:"applied"`                    // Mutations in the log (the latest sequence number)
+	Skipped        int64           `json:"skipped"`                    // Rounds skipped for an exclusive operation or a scope with nothing to mutate
+	Failed         int64           `json:"failed"`                     // Mutations that failed
+	LastError      string          `json:"last_error,omitempty"`       // Latest failure or skip reason
+	LastMutationAt *time.Time      `json:"last_mutation_at,omitempty"` // When the latest mutation since open was applied
+	NextRunAt      *time.Time      `json:"next_run_at,omitempty"`      // Set while the engine is running
+}
+
+// Change journal operations
+const (
+	ChangeCreate    = "create"    // A node (with any copied descendants) was added
+	ChangeModify    = "modify"    // A file's content was replaced in place
+	ChangeDelete    = "delete"    // A node was removed together with its descendants
+	ChangeMove      = "move"      // A node and its subtree moved under a new parent
+	ChangeRename    = "rename"    // A node and its subtree were renamed in place
+	ChangeExistence = "existence" // A node's existence changed in some worlds
+	ChangeReset     = "reset"     // The tree was replaced wholesale; consumers should rescan
+)
+
+// ChangeEvent is one entry of the change journal
+type ChangeEvent struct {
+	Seq          int64           `json:"seq"` // Position in the journal, starting at 1; never reused, even after a reset
+	Op           string          `json:"op"`
+	NodeID       string          `json:"node_id,omitempty"`
+	Path         string          `json:"path,omitempty"`
+	OldPath      string          `json:"old_path,omitempty"` // Path before a move or rename
+	NodeType     string          `json:"node_type,omitempty"`
+	Worlds       []string        `json:"worlds,omitempty"`        // Worlds affected: those the node is (or, for a delete, was) in, or whose existence changed
+	ExistenceMap map[string]bool `json:"existence_map,omitempty"` // The node's existence after the change (before it, for a delete)
+	Nodes        int             `json:"nodes,omitempty"`         // Nodes affected, descendants included
+	At           time.Time       `json:"at"`
+}
+
+// EventGenerate is the live event operation for a node created by lazy generation (never journaled)
+const EventGenerate = "generate"
+
+// Event is a live filesystem event delivered to subscribers
+type Event struct {
+	Seq     int64     `json:"seq,omitempty"`      // Change journal sequence number (0 for generate events)
+	Op      string    `json:"op"`                 // A Change* operation or EventGenerate
+	World   string    `json:"world,omitempty"`    // World whose listing generated the node (generate events only)
+	Worlds  []string  `json:"worlds,omitempty"`   // Worlds affected, as in ChangeEvent
+	Node    *Node     `json:"node,omitempty"`     // Snapshot after the change (before it, for a delete); nil for world-wide changes and resets
+	OldPath string    `json:"old_path,omitempty"` // Path before a move or rename
+	Nodes   int       `json:"nodes,omitempty"`    // Nodes affected, descendants included
+	At      time.Time `json:"at"`
+}
+
+// ChangeFeed is one page of the change journal
+type ChangeFeed struct {
+	Events     []ChangeEvent `json:"events"`
+	NextCursor int64         `json:"next_cursor"` // Pass as since to read on; equals since when there is nothing new
+	HasMore    bool          `json:"has_more"`    // More events follow NextCursor
+	Truncated  bool          `json:"truncated"`   // Events after since were pruned; consumers should rescan
 }
 
 // Node represents a filesystem node (file or folder) in the BoltDB database
 // Unified single-bucket design with existence tracking across worlds
 type Node struct {
-	ID           string          `json:"id" db:"id"`                       // UUID identifier
-	ParentID     string          `json:"parent_id" db:"parent_id"`         // UUID parent reference
-	Name         string          `json:"name" db:"name"`                   // Display name
-	Path         string          `json:"path" db:"path"`                   // Relative path
-	ParentPath   string          `json:"parent_path" db:"parent_path"`     // Parent path
-	Type         string          `json:"type" db:"type"`                   // "folder" or "file"
-	DepthLevel   int             `json:"depth_level" db:"depth_level"`     // BFS-style depth index
-	Size         int64           `json:"size" db:"size"`                   // File size (0 for folders)
-	LastUpdated  time.Time       `json:"last_updated" db:"last_updated"`   // Synthetic timestamp
-	Checksum     *string         `json:"checksum" db:"checksum"`           // SHA256 checksum (NULL for folders)
-	ExistenceMap map[string]bool `json:"existence_map" db:"existence_map"` // JSON: {"primary": true, "s1": true, "s2": false}
+	ID              string            `json:"id" db:"id"`                                       // UUID identifier
+	ParentID        string            `json:"parent_id" db:"parent_id"`                         // UUID parent reference
+	Name            string            `json:"name" db:"name"`                                   // Display name
+	Path            string            `json:"path" db:"path"`                                   // Relative path
+	ParentPath      string            `json:"parent_path" db:"parent_path"`                     // Parent path
+	Type            string            `json:"type" db:"type"`                                   // "folder", "file" or "symlink"
+	DepthLevel      int               `json:"depth_level" db:"depth_level"`                     // BFS-style depth index
+	Size            int64             `json:"size" db:"size"`                                   // File size (0 for folders, the target's length for symlinks)
+	LastUpdated     time.Time         `json:"last_updated" db:"last_updated"`                   // Synthetic timestamp
+	Checksum        *string           `json:"checksum" db:"checksum"`                           // SHA256 checksum (NULL for folders)
+	ExistenceMap    map[string]bool   `json:"existence_map" db:"existence_map"`                 // JSON: {"primary": true, "s1": true, "s2": false}
+	TraversalStatus string            `json:"traversal_status,omitempty" db:"traversal_status"` // "pending", "successful" or "failed" (empty on nodes stored before it existed)
+	CopyStatus      string            `json:"copy_status,omitempty" db:"copy_status"`           // "pending", "in_progress" or "completed" (copies made by CopySubtree end completed)
+	ContentID       string            `json:"content_id,omitempty" db:"content_id"`             // ID whose content a copied, rewritten or duplicate file has (empty = own ID; see generator.DuplicateContentID)
+	Target          string            `json:"target,omitempty" db:"target"`                     // Path a symlink points at, absolute or relative to its folder (it may not exist)
+	Metadata        map[string]string `json:"metadata,omitempty" db:"metadata"`                 // Custom key/value metadata, like object store user metadata (owner, content-type, tags)
 }
 
 // Folder represents a folder node
@@ -55,18 +363,170 @@ type File struct {
 	Node
 }
 
+// Symlink represents a symlink node
+type Symlink struct {
+	Node
+}
+
 // ListResult represents the result of ListChildren operation
 // Enhanced with success/failure response
 type ListResult struct {
-	Success bool     `json:"success"`
-	Message string   `json:"message,omitempty"`
-	Folders []Folder `json:"folders"`
-	Files   []File   `json:"files"`
+	Success    bool      `json:"success"`
+	Message    string    `json:"message,omitempty"`
+	Parent     *Node     `json:"parent,omitempty"` // The listed folder, read in the same snapshot as its children
+	Generated  bool      `json:"generated"`        // This call generated and stored the folder's children
+	Folders    []Folder  `json:"folders"`
+	Files      []File    `json:"files"`
+	Symlinks   []Symlink `json:"symlinks"`
+	Filtered   int       `json:"filtered,omitempty"`    // Children left out by the request's filters
+	NextCursor string    `json:"next_cursor,omitempty"` // Pass as starting_after to fetch the next page
+	PrevCursor string    `json:"prev_cursor,omitempty"` // Pass as ending_before to fetch the previous page
+}
+
+// NodePage is one page of a node query ordered by ID
+type NodePage struct {
+	Nodes      []*Node `json:"nodes"`
+	NextCursor string  `json:"next_cursor,omitempty"` // Pass back to fetch the next page
+}
+
+// Kinds of difference reported by a world diff
+const (
+	DiffOnlyInA = "only_in_a" // The node exists in world A but not in world B
+	DiffOnlyInB = "only_in_b" // The node exists in world B but not in world A
+	DiffChanged = "changed"   // The node exists in both worlds with differing metadata
+)
+
+// NodeScanOptions filters and batches a ForEachNode scan
+type NodeScanOptions struct {
+	World      string // Only nodes stored as existing in this world ("" = every node)
+	PathPrefix string // Only nodes at or below this path, read in path order ("" = every node, in ID order)
+	BatchSize  int    // Nodes read per read-only transaction (0 = 1000)
+	Snapshot   bool   // Read every node on one snapshot, calling fn inside its transaction
+}
+
+// DiffOptions scopes and pages a world diff
+type DiffOptions struct {
+	Root   string `json:"root,omitempty"`   // Path of the subtree to compare ("" or "/" = whole tree)
+	Limit  int    `json:"limit,omitempty"`  // Maximum differences per page (0 = all)
+	Cursor string `json:"cursor,omitempty"` // NextCursor of the previous page ("" for the first)
+}
+
+// WorldDiff lists the nodes that differ between two worlds, each list in ID order
+// Changed stays empty while a node's metadata is shared by every world
+type WorldDiff struct {
+	WorldA     string  `json:"world_a"`
+	WorldB     string  `json:"world_b"`
+	Root       string  `json:"root"`
+	OnlyInA    []*Node `json:"only_in_a"`
+	OnlyInB    []*Node `json:"only_in_b"`
+	Changed    []*Node `json:"changed"`
+	NextCursor string  `json:"next_cursor,omitempty"` // Pass back as cursor to fetch the next page
+}
+
+// Snapshot formats for Export
+const (
+	SnapshotFormatJSONL = "jsonl" // One node per line
+	SnapshotFormatJSON  = "json"  // A single JSON array of nodes
+)
+
+// ImportResult reports what an Import loaded
+type ImportResult struct {
+	Imported int  `json:"imported"` // Nodes stored
+	Skipped  int  `json:"skipped"`  // Nodes already present (merge only)
+	Merged   bool `json:"merged"`
+}
+
+// CompactResult reports a database compaction: the file is rewritten with only its live pages
+type CompactResult struct {
+	CompactedAt     time.Time `json:"compacted_at"`
+	DurationMillis  int64     `json:"duration_ms"`
+	SizeBefore      int64     `json:"size_before"`       // Database file size in bytes before compacting
+	SizeAfter       int64     `json:"size_after"`        // and after
+	FreeBytesBefore int64     `json:"free_bytes_before"` // Bytes in free pages before compacting
+}
+
+// NodeEncodingResult reports a migration of the stored nodes from JSON to the binary encoding
+type NodeEncodingResult struct {
+	MigratedAt     time.Time `json:"migrated_at"`
+	DurationMillis int64     `json:"duration_ms"`
+	Scanned        int64     `json:"scanned"`   // Node records read
+	Rewritten      int64     `json:"rewritten"` // Records that were still JSON and now use the binary encoding
+}
+
+// SnapshotInfo describes a named snapshot of the tree, saved in the database by SaveSnapshot
+type SnapshotInfo struct {
+	Name        string    `json:"name"`
+	CreatedAt   time.Time `json:"created_at"`
+	Nodes       int64     `json:"nodes"`       // Stored nodes, root included
+	JournalSeq  int64     `json:"journal_seq"` // Latest change journal sequence number when saved
+	Fingerprint string    `json:"fingerprint"` // Hash of the generation settings the tree was generated with
+}
+
+// Job kinds: the operations the API runs in the background as jobs
+const (
+	JobGenerate   = "generate"    // GenerateAll
+	JobReset      = "reset"       // Reset, optionally followed by GenerateAll
+	JobVerifyTree = "verify_tree" // VerifyTree
+)
+
+// Job statuses; a job is queued until one of the api.max_jobs slots is free
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobDone      = "done"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// IsFinishedJobStatus reports whether a job with status has ended (done, failed or cancelled)
+func IsFinishedJobStatus(status string) bool {
+	switch status {
+	case JobDone, JobFailed, JobCancelled:
+		return true
+	}
+	return false
+}
+
+// Job is a long-running operation the API runs in the background, polled at /api/v1/jobs/{id}
+// Records are kept in the database, so a job's outcome can be read after a restart
+type Job struct {
+	ID         string          `json:"id"`
+	Kind       string          `json:"kind"`   // One of the Job* kinds
+	Status     string          `json:"status"` // One of the Job* statuses
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	Progress   json.RawMessage `json:"progress,omitempty"`   // Kind-specific counters, e.g. GenerationProgress for generate
+	Result     json.RawMessage `json:"result,omitempty"`     // The operation's result once done, e.g. VerifyReport for verify_tree
+	Error      string          `json:"error,omitempty"`      // Why the job failed
+	ErrorCode  string          `json:"error_code,omitempty"` // The API error code of that failure, as in an error response
+}
+
+// RawShape returns the type of the job's progress or result document for its kind, nil if unknown
+func (j Job) RawShape(field string) any {
+	switch {
+	case field == "progress" && j.Kind == JobGenerate:
+		return GenerationProgress{}
+	case field == "progress" && j.Kind == JobReset:
+		return ResetProgress{}
+	case field == "result" && (j.Kind == JobGenerate || j.Kind == JobReset):
+		return GenerationProgress{}
+	case field == "result" && j.Kind == JobVerifyTree:
+		return VerifyReport{}
+	}
+	return nil
+}
+
+// ResetProgress is the progress of a reset job: the step it is on, and the generation's once it regenerates
+type ResetProgress struct {
+	Stage      string              `json:"stage"` // "reset" or "generate"
+	Generation *GenerationProgress `json:"generation,omitempty"`
 }
 
 // APIResponse represents a generic API response
 type APIResponse struct {
 	Success bool   `json:"success"`
+	Code    string `json:"code,omitempty"` // Machine-readable error code of a failed request (e.g. "parent_not_found")
 	Message string `json:"message,omitempty"`
 	Data    any    `json:"data,omitempty"`
 }
@@ -80,16 +540,355 @@ type TableInfo struct {
 
 // Stats represents filesystem statistics
 type Stats struct {
-	FileCount      int64            `json:"file_count"`      // Total number of files
-	FolderCount    int64            `json:"folder_count"`    // Total number of folders
-	TotalFileSize  int64            `json:"total_file_size"` // Total size of all files combined
-	SecondaryNodes map[string]int64 `json:"secondary_nodes"` // Node counts broken down by world (excluding primary)
+	TotalNodes      int64               `json:"total_nodes"`                 // Files, folders and symlinks (the root is not counted)
+	FileCount       int64               `json:"file_count"`                  // Total number of files
+	FolderCount     int64               `json:"folder_count"`                // Total number of folders
+	SymlinkCount    int64               `json:"symlink_count,omitempty"`     // Total number of symlinks
+	TotalFileSize   int64               `json:"total_file_size"`             // Total size of all files combined
+	PrimaryNodes    int64               `json:"primary_nodes"`               // Nodes that exist in primary (the root is not counted)
+	SecondaryNodes  map[string]int64    `json:"secondary_nodes"`             // Node counts broken down by world (excluding primary)
+	NodesPerDepth   []int64             `json:"nodes_per_depth,omitempty"`   // Node count at each depth level (index = depth)
+	MaxDepth        int                 `json:"max_depth"`                   // Deepest level holding a node
+	LastGeneratedAt *time.Time          `json:"last_generated_at,omitempty"` // When children were last generated
+	Preload         *PreloadStats       `json:"preload,omitempty"`           // Warm-start cache details (nil when preload is off)
+	NodeCache       *NodeCacheStats     `json:"node_cache,omitempty"`        // Hot node cache size and hit rate (nil when db.node_cache_size is 0)
+	Coverage        map[string]float64  `json:"coverage_percent,omitempty"`  // Per-world materialized share of the expected tree (an estimate, see CoverageReport)
+	Generation      *GenerationProgress `json:"generation,omitempty"`        // Latest GenerateAll run since open (nil if none)
+}
+
+// GenerationProgress reports a running or finished GenerateAll
+type GenerationProgress struct {
+	Running          bool       `json:"running"`
+	CurrentDepth     int        `json:"current_depth"`              // Depth of the nodes being created
+	MaxDepth         int        `json:"max_depth"`                  // seed.max_depth
+	NodesCreated     int64      `json:"nodes_created"`              // Nodes inserted by this run
+	FoldersGenerated int64      `json:"folders_generated"`          // Folders whose children this run generated
+	FoldersSkipped   int64      `json:"folders_skipped"`            // Folders that already had children
+	BudgetExhausted  bool       `json:"budget_exhausted,omitempty"` // The run stopped early because seed.max_total_nodes or seed.max_total_bytes was reached
+	StartedAt        time.Time  `json:"started_at"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+	Error            string     `json:"error,omitempty"` // Why the run stopped early (including cancellation)
+}
+
+// PreloadStats reports the cost and current size of the warm-start cache
+type PreloadStats struct {
+	Mode           string     `json:"mode"`                    // Active preload mode (may drop from "full" to "index" if the cap is hit)
+	RequestedMode  string     `json:"requested_mode"`          // Configured preload mode (db.preload)
+	DowngradedAt   *time.Time `json:"downgraded_at,omitempty"` // When growth past preload_max_bytes dropped "full" to "index"
+	StartupMillis  int64      `json:"startup_ms"`              // Time spent preloading at startup
+	Bytes          int64      `json:"bytes"`                   // Approximate memory held by the cache
+	IndexedParents int        `json:"indexed_parents"`         // Parents with an in-memory child list
+	CachedNodes    int        `json:"cached_nodes"`            // Nodes held decoded in memory ("full" mode only)
+}
+
+// NodeCacheStats reports the hot node cache since open
+type NodeCacheStats struct {
+	Size    int     `json:"size"`    // Most nodes held (db.node_cache_size)
+	Entries int     `json:"entries"` // Nodes held now
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"` // Hits / (hits + misses), 0 before the first lookup
+}
+
+// Recovery finding severities
+const (
+	RecoverySeverityWarning = "warning" // Stored counters disagree with the nodes; listings are still correct
+	RecoverySeveritySevere  = "severe"  // Indexes disagree with the nodes; listings and path lookups may be wrong
+)
+
+// RecoveryFinding is one inconsistency found by the consistency pass after an unclean shutdown
+// Expected is derived from the nodes bucket; Actual is what was stored
+type RecoveryFinding struct {
+	Check    string `json:"check"`    // e.g. "stats.file_count", "index_path.cardinality"
+	Severity string `json:"severity"` // One of the RecoverySeverity* constants
+	Expected int64  `json:"expected"`
+	Actual   int64  `json:"actual"`
+	Message  string `json:"message"`
+}
+
+// RecoveryReport describes the consistency pass run when a database is opened after an unclean shutdown
+type RecoveryReport struct {
+	DetectedAt     time.Time         `json:"detected_at"`
+	DurationMillis int64             `json:"duration_ms"`
+	NodeCount      int64             `json:"node_count"` // Records in the nodes bucket, including root
+	Findings       []RecoveryFinding `json:"findings"`
+	Severe         bool              `json:"severe"`   // True if any finding is severe
+	Repaired       bool              `json:"repaired"` // True if indexes and stats were rebuilt (db.auto_repair)
+}
+
+// Integrity issue kinds
+const (
+	IntegrityMissing  = "missing"  // A node has no entry in an index
+	IntegrityDangling = "dangling" // An index entry points at a node that is not stored
+	IntegrityStale    = "stale"    // An index entry points at a stored node that no longer matches it (e.g. its old path)
+	IntegrityOrphaned = "orphaned" // A node exists in a world its parent is missing from, so no listing reaches it
+)
+
+// IntegrityIssue is one disagreement between an index bucket and the nodes bucket, or one node
+// existing in a world its parent is missing from
+type IntegrityIssue struct {
+	Kind   string `json:"kind"`   // One of the Integrity* constants
+	Bucket string `json:"bucket"` // e.g. "index_path"; "nodes" for orphaned existence
+	Key    string `json:"key"`    // The index key that is missing, dangling or stale, or the orphaned node's path
+	NodeID string `json:"node_id"`
+	World  string `json:"world,omitempty"` // The world an orphaned node exists in
+}
+
+// IntegrityReport is the result of an index integrity check
+// A quick check only compares entry counts, so it reports counts but no issues
+type IntegrityReport struct {
+	CheckedAt      time.Time        `json:"checked_at"`
+	DurationMillis int64            `json:"duration_ms"`
+	Quick          bool             `json:"quick"`
+	NodeCount      int64            `json:"node_count"`    // Records in the nodes bucket, including root
+	IndexEntries   map[string]int64 `json:"index_entries"` // Index bucket -> entries
+	Missing        int64            `json:"missing"`
+	Dangling       int64            `json:"dangling"`
+	Stale          int64            `json:"stale"`
+	Orphaned       int64            `json:"orphaned"`  // (node, world) pairs existing without their parent
+	Issues         []IntegrityIssue `json:"issues"`    // The first issues found (see Truncated)
+	Truncated      bool             `json:"truncated"` // More issues were found than Issues holds
+	OK             bool             `json:"ok"`
+	Repaired       bool             `json:"repaired,omitempty"` // Set when the open-time check repaired the indexes (db.auto_repair)
+}
+
+// ChecksumVerification is the result of checking a claimed checksum against a file's content
+type ChecksumVerification struct {
+	NodeID   string `json:"node_id"`
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	Claimed  string `json:"claimed"`
+	Expected string `json:"expected"`  // SHA256 of the file's regenerated deterministic content
+	Stored   string `json:"stored"`    // The node's stored checksum ("" if it has none)
+	Match    bool   `json:"match"`     // Claimed equals Expected
+	StoredOK bool   `json:"stored_ok"` // Stored equals Expected
+}
+
+// ChecksumMismatch is a stored file whose checksum differs from the one of its regenerated content
+type ChecksumMismatch struct {
+	NodeID   string `json:"node_id"`
+	Path     string `json:"path"`
+	Stored   string `json:"stored"` // "" if the file has no checksum
+	Expected string `json:"expected"`
+}
+
+// VerifyReport is the result of recomputing the checksums of the stored files below a node in one world
+// Only materialized nodes are checked: the walk never generates children
+type VerifyReport struct {
+	CheckedAt      time.Time          `json:"checked_at"`
+	DurationMillis int64              `json:"duration_ms"`
+	RootID         string             `json:"root_id"`
+	RootPath       string             `json:"root_path"`
+	World          string             `json:"world"`
+	Folders        int64              `json:"folders"`    // Stored folders walked, including the start
+	Files          int64              `json:"files"`      // Files whose content was regenerated
+	Bytes          int64              `json:"bytes"`      // Content regenerated across those files
+	Mismatched     int64              `json:"mismatched"` // Files whose stored checksum is wrong or missing
+	Mismatches     []ChecksumMismatch `json:"mismatches"` // The first mismatches found (see Truncated)
+	Truncated      bool               `json:"truncated"`  // More mismatches were found than Mismatches holds
+	OK             bool               `json:"ok"`
+}
+
+// AnalysisReport describes the shape of a subtree, for checking that generation settings produce
+// the intended tree. Only stored nodes are counted: folders that were never listed have no children yet
+type AnalysisReport struct {
+	AnalyzedAt     time.Time `json:"analyzed_at"`
+	DurationMillis int64     `json:"duration_ms"`
+	RootID         string    `json:"root_id"`
+	RootPath       string    `json:"root_path"`
+	World          string    `json:"world"`
+
+	Nodes      int64 `json:"nodes"` // Nodes analyzed in World, the start included
+	Folders    int64 `json:"folders"`
+	Files      int64 `json:"files"`
+	Symlinks   int64 `json:"symlinks"`
+	TotalBytes int64 `json:"total_bytes"` // Sum of file sizes
+
+	Depths []DepthAnalysis `json:"depths"` // Node counts by depth, shallowest first
+
+	ChildCounts    []HistogramBucket  `json:"child_counts"`     // Folders by number of children in World
+	MeanChildCount float64            `json:"mean_child_count"` // Children per folder
+	EmptyFolders   int64              `json:"empty_folders"`    // Folders with no children in World (never listed, at max depth or emptied)
+	FileSizes      []HistogramBucket  `json:"file_sizes"`       // Files by size in bytes
+	MinFileSize    int64              `json:"min_file_size"`    // Smallest file (0 if there are none)
+	MaxFileSize    int64              `json:"max_file_size"`    // Largest file
+	MeanFileSize   float64            `json:"mean_file_size"`   // Average file size
+	WorldCoverage  map[string]float64 `json:"world_coverage"`   // World -> percentage of the analyzed nodes that also exist there
+	MeanPathLength float64            `json:"mean_path_length"` // Average path length in bytes
+	MaxPathLength  int                `json:"max_path_length"`  // Longest path in bytes
+}
+
+// DepthAnalysis counts the analyzed nodes at one depth
+type DepthAnalysis struct {
+	Depth    int   `json:"depth"`
+	Nodes    int64 `json:"nodes"`
+	Folders  int64 `json:"folders"`
+	Files    int64 `json:"files"`
+	Symlinks int64 `json:"symlinks"`
+}
+
+// HistogramBucket counts the values in [Min, Max]; buckets are powers of two wide (0, 1, 2-3, 4-7, ...)
+// and empty ones are left out
+type HistogramBucket struct {
+	Min   int64 `json:"min"`
+	Max   int64 `json:"max"`
+	Count int64 `json:"count"`
+}
+
+// WorldInfo describes one world of the registry: primary, or a secondary world with the probability
+// that a generated node exists in it
+type WorldInfo struct {
+	Name                  string  `json:"name"`
+	Type                  string  `json:"type"`                              // "primary" or "secondary"
+	Probability           float64 `json:"probability"`                       // 1 for primary
+	ExtraNodesProbability float64 `json:"extra_nodes_probability,omitempty"` // Chance a folder gets extra nodes only in this world (see WorldGeneration)
+	RetentionRules        int     `json:"retention_rules,omitempty"`         // Retention rules configured for the world
+}
+
+// DuplicatesReport lists the groups of stored files in a world whose content is byte-identical
+type DuplicatesReport struct {
+	World   string           `json:"world"`
+	Files   int64            `json:"files"`   // Files scanned in the world
+	Grouped int64            `json:"grouped"` // Files that belong to a group
+	Groups  []DuplicateGroup `json:"groups"`  // Ordered by their first path
+}
+
+// DuplicateGroup is a set of files that share content: the same ContentID (or, for a file with
+// none, its own ID as the source of copies) and the same size, and so the same bytes and checksum
+type DuplicateGroup struct {
+	ContentID string   `json:"content_id"`
+	Size      int64    `json:"size"`
+	Checksum  string   `json:"checksum,omitempty"`
+	Paths     []string `json:"paths"` // Sorted
+}
+
+// CoverageCounters are the stored per-world, per-depth folder counts behind coverage reporting
+// Slices are indexed by depth; a folder is "expanded" once it has at least one child
+type CoverageCounters struct {
+	Folders  map[string][]int64 `json:"folders"`  // World -> folders stored at each depth
+	Expanded map[string][]int64 `json:"expanded"` // World -> folders at each depth whose children have been generated
+}
+
+// DepthCoverage is the coverage of a single depth level in one world
+type DepthCoverage struct {
+	Depth           int     `json:"depth"`
+	Folders         int64   `json:"folders"`          // Folders stored at this depth
+	Materialized    int64   `json:"materialized"`     // Folders whose children have been generated
+	Frontier        int64   `json:"frontier"`         // Stored folders that can still be expanded
+	ExpectedFolders float64 `json:"expected_folders"` // Expected folders at this depth if the whole tree were generated
+	CoveragePercent float64 `json:"coverage_percent"` // Folders / ExpectedFolders: share of this level generated so far, capped at 100
+}
+
+// WorldCoverage summarizes how much of one world's expected tree has been materialized
+type WorldCoverage struct {
+	World               string          `json:"world"`
+	MaterializedFolders int64           `json:"materialized_folders"`
+	FrontierFolders     int64           `json:"frontier_folders"`
+	ExpectedExpandable  float64         `json:"expected_expandable_folders"` // Expected folders above max depth in a fully generated tree
+	CoveragePercent     float64         `json:"coverage_percent"`            // MaterializedFolders / ExpectedExpandable, capped at 100
+	Depths              []DepthCoverage `json:"depths"`
+}
+
+// CoverageReport is the per-world generation coverage
+// Expected totals are statistical expectations derived from the seed config, not guarantees
+type CoverageReport struct {
+	Estimate string          `json:"estimate"` // Describes how the expected totals were derived
+	Worlds   []WorldCoverage `json:"worlds"`
+}
+
+// InstanceIdentity identifies a database instance and its lineage
+type InstanceIdentity struct {
+	InstanceID string     `json:"instance_id"`
+	Seed       int64      `json:"seed"` // Generation seed the instance was created with (preserved by clones)
+	CreatedAt  time.Time  `json:"created_at"`
+	ClonedFrom string     `json:"cloned_from,omitempty"` // Instance ID of the source, for clones
+	ClonedAt   *time.Time `json:"cloned_at,omitempty"`
+}
+
+// GenerationFingerprint holds the settings that shape a generated tree: the seed, the branching
+// parameters and the secondary worlds. It is stored in the database on first open, and reopening the
+// database with different settings is refused unless db.accept_config_change is set
+type GenerationFingerprint struct {
+	Seed       int64              `json:"seed"`
+	MaxDepth   int                `json:"max_depth"`
+	MinFolders int                `json:"min_folders"`
+	MaxFolders int                `json:"max_folders"`
+	MinFiles   int                `json:"min_files"`
+	MaxFiles   int                `json:"max_files"`
+	Profile    *GenerationProfile `json:"profile,omitempty"`
+	Worlds     map[string]float64 `json:"worlds"`      // Secondary world -> probability
+	Hash       string             `json:"hash"`        // SHA-256 of the settings above, for comparing fingerprints at a glance
+	RecordedAt time.Time          `json:"recorded_at"` // When these settings were stored
+}
+
+// CopyOptions controls how CopySubtree builds the copies' existence maps
+type CopyOptions struct {
+	OnlyWorld      string          `json:"only_world,omitempty"`      // Copy only the nodes that exist in this world
+	WorldOverrides map[string]bool `json:"world_overrides,omitempty"` // Existence forced on every copy per secondary world (still limited by the copy's parent)
+}
+
+// CopyResult reports a completed CopySubtree
+type CopyResult struct {
+	Root        *Node  `json:"root"`         // Copy of the source node
+	NodesCopied int    `json:"nodes_copied"` // Including the root copy
+	CopyStatus  string `json:"copy_status"`  // CopyStatusCompleted once every batch is inserted
+}
+
+// WalkEntry is one node reached by Walk, with its depth below the walk's starting folder
+type WalkEntry struct {
+	Depth int   `json:"depth"` // 1 for the starting folder's children
+	Node  *Node `json:"node"`
+}
+
+// WalkResult is the flattened output of a Walk in depth-first order
+type WalkResult struct {
+	Nodes     []WalkEntry `json:"nodes"`
+	Truncated bool        `json:"truncated"` // The node cap was reached before the walk finished
+}
+
+// SearchResult lists the nodes matching a search, in path order
+// Only nodes already stored are searched: nothing is generated, so folders that were never listed
+// contribute no descendants
+type SearchResult struct {
+	Nodes            []*Node `json:"nodes"`
+	Truncated        bool    `json:"truncated"`         // The limit was reached before the search finished
+	MaterializedOnly bool    `json:"materialized_only"` // Always true: unexpanded folders are not generated
+}
+
+// DeleteOutcome reports what happened to a single ID in a batch delete
+type DeleteOutcome struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`            // One of the DeleteStatus* constants
+	Message string `json:"message,omitempty"` // Error details for failed deletions
+}
+
+// BatchDeleteResult represents the result of a batch delete, with one outcome per requested ID
+type BatchDeleteResult struct {
+	Deleted int             `json:"deleted"` // Total nodes removed, including descendants of recursive deletes
+	Results []DeleteOutcome `json:"results"`
+}
+
+// BatchOpResult reports what happened to one op of a batch create
+type BatchOpResult struct {
+	Index   int    `json:"index"`         // Position of the op in the request
+	Key     string `json:"key,omitempty"` // The op's client-assigned key, if any
+	Success bool   `json:"success"`
+	Node    *Node  `json:"node,omitempty"`  // The created node on success
+	Error   string `json:"error,omitempty"` // Why the op failed
+}
+
+// BatchCreateResult represents the result of a batch create, with one result per op in request order
+type BatchCreateResult struct {
+	Created int             `json:"created"`
+	Failed  int             `json:"failed"`
+	Results []BatchOpResult `json:"results"`
 }
 
 // NodeType constants
 const (
-	NodeTypeFolder = "folder"
-	NodeTypeFile   = "file"
+	NodeTypeFolder  = "folder"
+	NodeTypeFile    = "file"
+	NodeTypeSymlink = "symlink"
 )
 
 // TraversalStatus constants
@@ -99,6 +898,15 @@ const (
 	StatusFailed     = "failed"
 )
 
+// IsValidTraversalStatus reports whether status is one of the TraversalStatus constants
+func IsValidTraversalStatus(status string) bool {
+	switch status {
+	case StatusPending, StatusSuccessful, StatusFailed:
+		return true
+	}
+	return false
+}
+
 // CopyStatus constants
 const (
 	CopyStatusPending    = "pending"
@@ -106,7 +914,153 @@ const (
 	CopyStatusCompleted  = "completed"
 )
 
+// IsValidCopyStatus reports whether status is one of the CopyStatus constants
+func IsValidCopyStatus(status string) bool {
+	switch status {
+	case CopyStatusPending, CopyStatusInProgress, CopyStatusCompleted:
+		return true
+	}
+	return false
+}
+
+// DeleteStatus constants for batch delete outcomes
+const (
+	DeleteStatusDeleted         = "deleted"
+	DeleteStatusNotFound        = "not_found"
+	DeleteStatusSkippedNotEmpty = "skipped_not_empty"
+	DeleteStatusSkippedRoot     = "skipped_root"
+	DeleteStatusFailed          = "failed"
+)
+
 // GetTableName returns the full table name (always "nodes" now)
 func GetTableName(world string) string {
 	return "nodes"
 }
+
+// DeterminismReport is the result of a determinism self-check
+type DeterminismReport struct {
+	Deterministic bool                   `json:"deterministic"`        // True if every iteration produced identical fingerprints
+	Iterations    int                    `json:"iterations"`           // Number of throwaway instances built
+	NodesCompared int                    `json:"nodes_compared"`       // Nodes fingerprinted per instance
+	Fingerprint   string                 `json:"fingerprint"`          // Digest of the first instance's tree
+	Divergence    *DeterminismDivergence `json:"divergence,omitempty"` // First mismatch found (nil when deterministic)
+}
+
+// DeterminismDivergence describes the first field that differed between two instances
+type DeterminismDivergence struct {
+	Iteration int    `json:"iteration"` // Iteration that diverged from iteration 0
+	Path      string `json:"path"`      // Path of the diverging node
+	Field     string `json:"field"`     // Field that differs (e.g. "size", "existence_map", "missing")
+	Expected  string `json:"expected"`  // Value in iteration 0
+	Actual    string `json:"actual"`    // Value in the diverging iteration
+}
+
+// ExistenceCauseRetention marks existence flips made by a retention rule
+const ExistenceCauseRetention = "retention"
+
+// RetentionExpiration describes a node removed from a world by retention
+type RetentionExpiration struct {
+	ID          string    `json:"id"`
+	Path        string    `json:"path"`
+	Cause       string    `json:"cause"`                  // Always ExistenceCauseRetention
+	ExpiredAt   time.Time `json:"expired_at"`             // LastUpdated + TTL of the matching rule
+	ExpiredWith string    `json:"expired_with,omitempty"` // Path of the expired folder the node went with (empty when its own rule expired it)
+}
+
+// RetentionResult reports the outcome of persisting retention for a world
+type RetentionResult struct {
+	World       string                `json:"world"`
+	Expired     int                   `json:"expired"`
+	Expirations []RetentionExpiration `json:"expirations"`
+}
+
+// WorldExistenceResult reports the outcome of setting a subtree's existence in one world
+type WorldExistenceResult struct {
+	ID        string `json:"id"`
+	Path      string `json:"path"`
+	World     string `json:"world"`
+	Exists    bool   `json:"exists"`
+	Recursive bool   `json:"recursive"`
+	Updated   int    `json:"updated"`   // Nodes whose existence in the world changed
+	Unchanged int    `json:"unchanged"` // Nodes visited that already had the requested existence
+}
+
+// BucketInfo describes one raw BoltDB bucket for the debug endpoints
+type BucketInfo struct {
+	Name     string `json:"name"`
+	KeyCount int64  `json:"key_count"`
+}
+
+// BucketEntry is one raw key/value pair from a bucket
+// Values within the size cap are decoded into Value (JSON) or ValueText (other UTF-8); anything else
+// goes to ValueHex hex-encoded, cut to the cap when Truncated
+type BucketEntry struct {
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value,omitempty"`
+	ValueText string          `json:"value_text,omitempty"`
+	ValueHex  string          `json:"value_hex,omitempty"`
+	Size      int             `json:"size"` // Stored value size in bytes
+	Truncated bool            `json:"truncated,omitempty"`
+}
+
+// BucketPage is one page of a raw bucket scan, in key order
+type BucketPage struct {
+	Bucket    string        `json:"bucket"`
+	Prefix    string        `json:"prefix,omitempty"`
+	Entries   []BucketEntry `json:"entries"`
+	NextAfter string        `json:"next_after,omitempty"` // Pass as after to fetch the next page
+}
+
+// Maintenance tasks that can be scheduled in maintenance_schedule
+const (
+	MaintenanceTaskApplyRetention  = "apply-retention"  // ApplyRetention for every world with rules
+	MaintenanceTaskRebuildStats    = "rebuild-stats"    // Recompute stats and coverage counters from the nodes
+	MaintenanceTaskCompact         = "compact"          // Compact the database file (fails on an in-memory database)
+	MaintenanceTaskPruneJournal    = "prune-journal"    // Prune the change journal to db.journal_max_entries
+	MaintenanceTaskSnapshotCleanup = "snapshot-cleanup" // Delete all but the db.snapshot_keep newest named snapshots
+)
+
+// MaintenanceTasks lists every schedulable task name
+var MaintenanceTasks = []string{
+	MaintenanceTaskApplyRetention, MaintenanceTaskRebuildStats, MaintenanceTaskCompact,
+	MaintenanceTaskPruneJournal, MaintenanceTaskSnapshotCleanup,
+}
+
+// Outcomes of a maintenance task run
+const (
+	MaintenanceStatusOK      = "ok"
+	MaintenanceStatusFailed  = "failed"
+	MaintenanceStatusSkipped = "skipped" // Another exclusive operation was running
+)
+
+// MaintenanceTaskStatus is the last-run record of one scheduled maintenance task
+type MaintenanceTaskStatus struct {
+	Task           string     `json:"task"`
+	Interval       string     `json:"interval"`
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	LastStatus     string     `json:"last_status,omitempty"`
+	LastMessage    string     `json:"last_message,omitempty"` // Error or skip reason
+	DurationMillis int64      `json:"duration_ms"`
+	Runs           int64      `json:"runs"`
+	Skips          int64      `json:"skips"`
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"` // Set while the scheduler is running
+}
+
+// MaintenanceSchedule reports every scheduled task and whether the scheduler is running
+type MaintenanceSchedule struct {
+	Running bool                    `json:"running"`
+	Tasks   []MaintenanceTaskStatus `json:"tasks"`
+}
+
+// HealthReport is an instance's readiness, as served by /health/ready
+type HealthReport struct {
+	Ready         bool       `json:"ready"`
+	Reason        string     `json:"reason,omitempty"`    // Why the instance is not ready
+	Operation     string     `json:"operation,omitempty"` // Destructive operation in progress ("reset", "import" or "restore")
+	DBPath        string     `json:"db_path"`
+	LastError     string     `json:"last_error,omitempty"` // Most recent failed database check, even if it has since recovered
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+	OpenedAt      time.Time  `json:"opened_at"`
+	UptimeSeconds int64      `json:"uptime_seconds"`    // Since the instance was opened
+	Version       string     `json:"version,omitempty"` // Build version of the server (set by the API)
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxBucketBurst caps how many tokens a bucket accumulates while idle
+const maxBucketBurst = 256 << 10
+
+// TokenBucket is a token-bucket rate limiter safe for concurrent use
+// Tokens refill continuously at the rate; a bucket starts empty and holds at most Burst tokens,
+// so over any stretch of time at most rate*seconds + Burst tokens are handed out
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens per second
+	burst  float64
+	tokens float64 // Negative while callers are waiting for reserved tokens
+	last   time.Time
+}
+
+// NewTokenBucket creates a bucket refilling at rate tokens per second (rate must be positive)
+// The burst is a tenth of a second's worth of tokens, between 1 and 256KiB
+func NewTokenBucket(rate int64) *TokenBucket {
+	burst := min(max(rate/10, 1), maxBucketBurst)
+	return &TokenBucket{
+		rate:  float64(rate),
+		burst: float64(burst),
+		last:  time.Now(),
+	}
+}
+
+// Burst returns the most tokens the bucket holds; callers should wait for at most this many at once
+func (b *TokenBucket) Burst() int {
+	return int(b.burst)
+}
+
+// WaitN blocks until n tokens are available and takes them
+// Tokens are reserved up front, so concurrent callers are served in order; if ctx is done
+// before the wait ends, the reservation is returned to the bucket and ctx.Err() is returned
+func (b *TokenBucket) WaitN(ctx context.Context, n int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= float64(n)
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens += float64(n)
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
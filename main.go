@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -10,7 +11,7 @@ import (
 
 func main() {
 	var (
-		config = flag.String("config", "configs/default.json", "Configuration file path")
+		config = flag.String("config", "", "Configuration file path (default: a config built in code, kept in memory)")
 		help   = flag.Bool("help", false, "Show help")
 	)
 	flag.Parse()
@@ -38,7 +39,8 @@ func showHelp() {
 	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  -config string")
-	fmt.Println("        Configuration file path (default: configs/default.json)")
+	fmt.Println("        Configuration file path (default: the SDK's default config built in code,")
+	fmt.Println("        with the database kept in memory)")
 	fmt.Println("  -help")
 	fmt.Println("        Show this help message")
 	fmt.Println()
@@ -52,13 +54,24 @@ func showHelp() {
 }
 
 func runDemo(configPath string) {
-	fmt.Printf("Loading configuration from: %s\n", configPath)
-
-	// Initialize SpectraFS with configuration
-	fs, err := sdk.New(configPath)
+	// Initialize SpectraFS from the config file, or from a config built in code without one
+	var fs *sdk.SpectraFS
+	var err error
+	if configPath != "" {
+		fmt.Printf("Loading configuration from: %s\n", configPath)
+		fs, err = sdk.New(configPath)
+	} else {
+		fmt.Println("Building configuration in code (no config file, database in memory)")
+		cfg := sdk.DefaultConfig()
+		cfg.Seed.DBPath = sdk.MemoryDBPath
+		fs, err = sdk.NewFromConfig(&cfg)
+	}
 	if err != nil {
 		log.Fatalf("Failed to initialize SpectraFS: %v", err)
 	}
+	defer fs.Close()
+
+	ctx := context.Background()
 
 	// Get configuration
 	cfg := fs.GetConfig()
@@ -66,7 +79,7 @@ func runDemo(configPath string) {
 
 	// Get table information
 	fmt.Println("\nTable Information:")
-	tableInfo, err := fs.GetTableInfo()
+	tableInfo, err := fs.GetTableInfoContext(ctx)
 	if err != nil {
 		log.Printf("Failed to get table info: %v", err)
 	} else {
@@ -77,7 +90,7 @@ func runDemo(configPath string) {
 
 	// List root children (this will trigger generation)
 	fmt.Println("\nListing root children (triggering generation)...")
-	result, err := fs.ListChildren(&sdk.ListChildrenRequest{ParentID: "root"})
+	result, err := fs.ListChildrenContext(ctx, &sdk.ListChildrenRequest{ParentID: "root"})
 	if err != nil {
 		log.Printf("Failed to list root children: %v", err)
 	} else {
@@ -95,7 +108,7 @@ func runDemo(configPath string) {
 
 	// Get updated table information
 	fmt.Println("\nUpdated Table Information:")
-	tableInfo, err = fs.GetTableInfo()
+	tableInfo, err = fs.GetTableInfoContext(ctx)
 	if err != nil {
 		log.Printf("Failed to get updated table info: %v", err)
 	} else {
@@ -108,7 +121,7 @@ func runDemo(configPath string) {
 	fmt.Println("\nSecondary Tables:")
 	secondaryTables := fs.GetSecondaryTables()
 	for _, table := range secondaryTables {
-		count, err := fs.GetNodeCount(table)
+		count, err := fs.GetNodeCountContext(ctx, table)
 		if err != nil {
 			log.Printf("Failed to get count for %s: %v", table, err)
 		} else {
@@ -118,7 +131,7 @@ func runDemo(configPath string) {
 
 	// Reset filesystem
 	fmt.Println("\nResetting filesystem...")
-	if err := fs.Reset(); err != nil {
+	if err := fs.ResetContext(ctx); err != nil {
 		log.Printf("Failed to reset filesystem: %v", err)
 	} else {
 		fmt.Println("Filesystem reset completed!")
@@ -0,0 +1,282 @@
+package sdk
+
+import (
+	"context"
+	"io"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+	"github.com/Project-Sylos/Spectra/internal/types"
+)
+
+// ChaosFS wraps a SpectraFS so its operations are delayed and fail according to the chaos rules
+// (the config's chaos section, or SetChaos at runtime), for testing client retry logic in-process.
+// Each wrapped call runs InjectChaos for its operation first and returns the injected error
+// without touching the filesystem. Methods that are not wrapped, such as AsFS, pass straight through
+type ChaosFS struct {
+	*SpectraFS
+}
+
+// NewChaosFS wraps s with chaos injection; s itself stays unaffected
+func NewChaosFS(s *SpectraFS) *ChaosFS {
+	return &ChaosFS{SpectraFS: s}
+}
+
+// ListChildrenContext injects chaos for ChaosOpList, then lists the children
+func (c *ChaosFS) ListChildrenContext(ctx context.Context, req *models.ListChildrenRequest) (*types.ListResult, error) {
+	if err := c.InjectChaos(ctx, ChaosOpList); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.ListChildrenContext(ctx, req)
+}
+
+// ListChildren calls ListChildrenContext with context.Background()
+//
+// Deprecated: Use ListChildrenContext.
+func (c *ChaosFS) ListChildren(req *models.ListChildrenRequest) (*types.ListResult, error) {
+	return c.ListChildrenContext(context.Background(), req)
+}
+
+// GetNodeContext injects chaos for ChaosOpGet, then retrieves the node
+func (c *ChaosFS) GetNodeContext(ctx context.Context, req *models.GetNodeRequest) (*types.Node, error) {
+	if err := c.InjectChaos(ctx, ChaosOpGet); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.GetNodeContext(ctx, req)
+}
+
+// GetNode calls GetNodeContext with context.Background()
+//
+// Deprecated: Use GetNodeContext.
+func (c *ChaosFS) GetNode(req *models.GetNodeRequest) (*types.Node, error) {
+	return c.GetNodeContext(context.Background(), req)
+}
+
+// GetFileData injects chaos for ChaosOpRead, then generates the file data
+func (c *ChaosFS) GetFileData(id string) ([]byte, string, error) {
+	if err := c.InjectChaos(context.Background(), ChaosOpRead); err != nil {
+		return nil, "", err
+	}
+	return c.SpectraFS.GetFileData(id)
+}
+
+// OpenFileDataContext injects chaos for ChaosOpRead, then opens the file content
+func (c *ChaosFS) OpenFileDataContext(ctx context.Context, id string) (io.ReadSeeker, *types.Node, error) {
+	if err := c.InjectChaos(ctx, ChaosOpRead); err != nil {
+		return nil, nil, err
+	}
+	return c.SpectraFS.OpenFileDataContext(ctx, id)
+}
+
+// OpenFileData calls OpenFileDataContext with context.Background()
+//
+// Deprecated: Use OpenFileDataContext.
+func (c *ChaosFS) OpenFileData(id string) (io.ReadSeeker, *types.Node, error) {
+	return c.OpenFileDataContext(context.Background(), id)
+}
+
+// CreateFolderContext injects chaos for ChaosOpCreate, then creates the folder
+func (c *ChaosFS) CreateFolderContext(ctx context.Context, req *models.CreateFolderRequest) (*types.Node, error) {
+	if err := c.InjectChaos(ctx, ChaosOpCreate); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.CreateFolderContext(ctx, req)
+}
+
+// CreateFolder calls CreateFolderContext with context.Background()
+//
+// Deprecated: Use CreateFolderContext.
+func (c *ChaosFS) CreateFolder(req *models.CreateFolderRequest) (*types.Node, error) {
+	return c.CreateFolderContext(context.Background(), req)
+}
+
+// CreateSymlink injects chaos for ChaosOpCreate, then creates the symlink
+func (c *ChaosFS) CreateSymlink(ctx context.Context, req *models.CreateSymlinkRequest) (*types.Node, error) {
+	if err := c.InjectChaos(ctx, ChaosOpCreate); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.CreateSymlink(ctx, req)
+}
+
+// UploadFileFrom injects chaos for ChaosOpUpload, then uploads the file streamed from body
+func (c *ChaosFS) UploadFileFrom(ctx context.Context, req *models.UploadFileRequest, body io.Reader) (*types.Node, error) {
+	if err := c.InjectChaos(ctx, ChaosOpUpload); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.UploadFileFrom(ctx, req, body)
+}
+
+// UploadFileContext injects chaos for ChaosOpUpload, then uploads the file
+func (c *ChaosFS) UploadFileContext(ctx context.Context, req *models.UploadFileRequest) (*types.Node, error) {
+	if err := c.InjectChaos(ctx, ChaosOpUpload); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.UploadFileContext(ctx, req)
+}
+
+// UploadFile calls UploadFileContext with context.Background()
+//
+// Deprecated: Use UploadFileContext.
+func (c *ChaosFS) UploadFile(req *models.UploadFileRequest) (*types.Node, error) {
+	return c.UploadFileContext(context.Background(), req)
+}
+
+// BatchCreate injects chaos for ChaosOpBatch once for the whole batch, then runs it
+func (c *ChaosFS) BatchCreate(ctx context.Context, ops []BatchOp) (*BatchCreateResult, error) {
+	if err := c.InjectChaos(ctx, ChaosOpBatch); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.BatchCreate(ctx, ops)
+}
+
+// DeleteNodeContext injects chaos for ChaosOpDelete, then deletes the node
+func (c *ChaosFS) DeleteNodeContext(ctx context.Context, req *models.DeleteNodeRequest) error {
+	if err := c.InjectChaos(ctx, ChaosOpDelete); err != nil {
+		return err
+	}
+	return c.SpectraFS.DeleteNodeContext(ctx, req)
+}
+
+// DeleteNode calls DeleteNodeContext with context.Background()
+//
+// Deprecated: Use DeleteNodeContext.
+func (c *ChaosFS) DeleteNode(req *models.DeleteNodeRequest) error {
+	return c.DeleteNodeContext(context.Background(), req)
+}
+
+// DeleteNodesContext injects chaos for ChaosOpDelete once for the whole batch, then deletes the nodes
+func (c *ChaosFS) DeleteNodesContext(ctx context.Context, ids []string, recursive bool) (*BatchDeleteResult, error) {
+	if err := c.InjectChaos(ctx, ChaosOpDelete); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.DeleteNodesContext(ctx, ids, recursive)
+}
+
+// DeleteNodes calls DeleteNodesContext with context.Background()
+//
+// Deprecated: Use DeleteNodesContext.
+func (c *ChaosFS) DeleteNodes(ids []string, recursive bool) (*BatchDeleteResult, error) {
+	return c.DeleteNodesContext(context.Background(), ids, recursive)
+}
+
+// MoveNodeContext injects chaos for ChaosOpMove, then moves the node
+func (c *ChaosFS) MoveNodeContext(ctx context.Context, req *models.MoveNodeRequest) (*types.Node, error) {
+	if err := c.InjectChaos(ctx, ChaosOpMove); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.MoveNodeContext(ctx, req)
+}
+
+// MoveNode calls MoveNodeContext with context.Background()
+//
+// Deprecated: Use MoveNodeContext.
+func (c *ChaosFS) MoveNode(req *models.MoveNodeRequest) (*types.Node, error) {
+	return c.MoveNodeContext(context.Background(), req)
+}
+
+// RenameNodeContext injects chaos for ChaosOpRename, then renames the node
+func (c *ChaosFS) RenameNodeContext(ctx context.Context, req *models.RenameNodeRequest) (*types.Node, error) {
+	if err := c.InjectChaos(ctx, ChaosOpRename); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.RenameNodeContext(ctx, req)
+}
+
+// RenameNode calls RenameNodeContext with context.Background()
+//
+// Deprecated: Use RenameNodeContext.
+func (c *ChaosFS) RenameNode(req *models.RenameNodeRequest) (*types.Node, error) {
+	return c.RenameNodeContext(context.Background(), req)
+}
+
+// CopySubtreeContext injects chaos for ChaosOpCopy, then copies the subtree
+func (c *ChaosFS) CopySubtreeContext(ctx context.Context, srcID, dstParentID string, opts CopyOptions) (*CopyResult, error) {
+	if err := c.InjectChaos(ctx, ChaosOpCopy); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.CopySubtreeContext(ctx, srcID, dstParentID, opts)
+}
+
+// CopySubtree calls CopySubtreeContext with context.Background()
+//
+// Deprecated: Use CopySubtreeContext.
+func (c *ChaosFS) CopySubtree(srcID, dstParentID string, opts CopyOptions) (*CopyResult, error) {
+	return c.CopySubtreeContext(context.Background(), srcID, dstParentID, opts)
+}
+
+// Search injects chaos for ChaosOpSearch, then runs the search
+func (c *ChaosFS) Search(ctx context.Context, req *models.SearchRequest) (*SearchResult, error) {
+	if err := c.InjectChaos(ctx, ChaosOpSearch); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.Search(ctx, req)
+}
+
+// WalkContext injects chaos for ChaosOpWalk, then walks the subtree
+func (c *ChaosFS) WalkContext(ctx context.Context, req *models.WalkRequest) (*WalkResult, error) {
+	if err := c.InjectChaos(ctx, ChaosOpWalk); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.WalkContext(ctx, req)
+}
+
+// Walk calls WalkContext with context.Background()
+//
+// Deprecated: Use WalkContext.
+func (c *ChaosFS) Walk(req *models.WalkRequest) (*WalkResult, error) {
+	return c.WalkContext(context.Background(), req)
+}
+
+// WalkFuncContext injects chaos for ChaosOpWalk, then walks the subtree
+func (c *ChaosFS) WalkFuncContext(ctx context.Context, req *models.WalkRequest, fn func(*Node) error) error {
+	if err := c.InjectChaos(ctx, ChaosOpWalk); err != nil {
+		return err
+	}
+	return c.SpectraFS.WalkFuncContext(ctx, req, fn)
+}
+
+// WalkFunc calls WalkFuncContext with context.Background()
+//
+// Deprecated: Use WalkFuncContext.
+func (c *ChaosFS) WalkFunc(req *models.WalkRequest, fn func(*Node) error) error {
+	return c.WalkFuncContext(context.Background(), req, fn)
+}
+
+// UpdateTraversalStatus injects chaos for ChaosOpStatus, then updates the status
+func (c *ChaosFS) UpdateTraversalStatus(req *models.UpdateTraversalStatusRequest) (*types.Node, error) {
+	if err := c.InjectChaos(context.Background(), ChaosOpStatus); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.UpdateTraversalStatus(req)
+}
+
+// UpdateCopyStatus injects chaos for ChaosOpStatus, then updates the status
+func (c *ChaosFS) UpdateCopyStatus(req *models.UpdateCopyStatusRequest) (*types.Node, error) {
+	if err := c.InjectChaos(context.Background(), ChaosOpStatus); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.UpdateCopyStatus(req)
+}
+
+// UpdateSubtreeCopyStatus injects chaos for ChaosOpStatus, then updates the subtree's statuses
+func (c *ChaosFS) UpdateSubtreeCopyStatus(req *models.UpdateCopyStatusRequest) (int, error) {
+	if err := c.InjectChaos(context.Background(), ChaosOpStatus); err != nil {
+		return 0, err
+	}
+	return c.SpectraFS.UpdateSubtreeCopyStatus(req)
+}
+
+// SetNodeMetadata injects chaos for ChaosOpMetadata, then updates the node's metadata
+func (c *ChaosFS) SetNodeMetadata(ctx context.Context, req *models.SetNodeMetadataRequest) (*types.Node, error) {
+	if err := c.InjectChaos(ctx, ChaosOpMetadata); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.SetNodeMetadata(ctx, req)
+}
+
+// GetNodeMetadata injects chaos for ChaosOpGet, then returns the node's metadata
+func (c *ChaosFS) GetNodeMetadata(ctx context.Context, req *models.GetNodeRequest) (map[string]string, error) {
+	if err := c.InjectChaos(ctx, ChaosOpGet); err != nil {
+		return nil, err
+	}
+	return c.SpectraFS.GetNodeMetadata(ctx, req)
+}
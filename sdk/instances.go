@@ -0,0 +1,228 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/Project-Sylos/Spectra/internal/config"
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/spectrafs"
+)
+
+// MultiInstance hosts a config's main filesystem together with its named instances (config "instances"),
+// each an independent SpectraFS with its own database, and creates and deletes instances at runtime
+type MultiInstance struct {
+	main *SpectraFS
+
+	mu        sync.RWMutex // Protects instances
+	instances map[string]*SpectraFS
+}
+
+// NewMultiInstance opens the main filesystem of the config file and every instance it lists
+// Without an "instances" section this is the main filesystem alone
+func NewMultiInstance(configPath string) (*MultiInstance, error) {
+	main, err := New(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MultiInstance{
+		main:      main,
+		instances: make(map[string]*SpectraFS),
+	}
+	for _, instance := range main.GetConfig().Instances {
+		if _, err := m.CreateInstance(instance); err != nil {
+			m.Close()
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Main returns the main filesystem, configured by the top-level seed section
+func (m *MultiInstance) Main() *SpectraFS {
+	return m.main
+}
+
+// Instance returns the named instance, or ErrUnknownInstance
+func (m *MultiInstance) Instance(name string) (*SpectraFS, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	instance, ok := m.instances[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownInstance, name)
+	}
+	return instance, nil
+}
+
+// Instances returns a snapshot of the named instances (the main filesystem is not included)
+func (m *MultiInstance) Instances() map[string]*SpectraFS {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	instances := make(map[string]*SpectraFS, len(m.instances))
+	for name, instance := range m.instances {
+		instances[name] = instance
+	}
+	return instances
+}
+
+// Names returns the names of the instances in sorted order
+func (m *MultiInstance) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.instances))
+	for name := range m.instances {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// InstanceSeed returns the main seed section with overrides (a JSON seed section, possibly partial) laid
+// over it and db_path cleared unless overrides sets it, ready for CreateInstance. Returns ErrInvalidInstance
+// for malformed JSON
+func (m *MultiInstance) InstanceSeed(overrides json.RawMessage) (SeedConfig, error) {
+	seed, err := config.InstanceSeed(m.main.GetConfig(), overrides)
+	if err != nil {
+		return seed, fmt.Errorf("%w: %w", ErrInvalidInstance, err)
+	}
+	return seed, nil
+}
+
+// CreateInstance opens a new named instance with the main config's other sections and instance.Seed
+// Start from Main().GetConfig().Seed to change only some settings; an unset db_path (or the main one)
+// becomes the main path with the name before the extension. Returns ErrInvalidInstance for a bad name
+// or seed and ErrInstanceExists for a name already in use. The instance lasts until DeleteInstance or
+// Close; the config file is not rewritten
+func (m *MultiInstance) CreateInstance(instance InstanceConfig) (*SpectraFS, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg, err := m.instanceConfig(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	impl, err := spectrafs.NewSpectraFSFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize instance %s: %w", instance.Name, err)
+	}
+	created := &SpectraFS{impl: impl}
+	m.instances[instance.Name] = created
+	return created, nil
+}
+
+// CloneInstance copies the database of the named source instance and opens the copy as a new instance
+// called name, with the source's seed section. An empty dbPath becomes the main path with the name
+// before the extension, as in CreateInstance. The source keeps serving meanwhile; the copy gets its own
+// instance ID and records the source's as cloned_from (see Identity). Returns ErrUnknownInstance for a
+// missing source, ErrInstanceExists for a name in use, and ErrInvalidInstance for a bad name or a
+// database path that is in memory or already exists
+func (m *MultiInstance) CloneInstance(source, name, dbPath string) (*SpectraFS, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	from, ok := m.instances[source]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownInstance, source)
+	}
+	seed := from.GetConfig().Seed
+	seed.DBPath = dbPath
+
+	cfg, err := m.instanceConfig(InstanceConfig{Name: name, Seed: seed})
+	if err != nil {
+		return nil, err
+	}
+	if db.IsMemoryPath(cfg.Seed.DBPath) {
+		return nil, fmt.Errorf("%w: instance %s: a clone needs a database file, set db_path", ErrInvalidInstance, name)
+	}
+	if _, err := os.Stat(cfg.Seed.DBPath); err == nil {
+		return nil, fmt.Errorf("%w: instance %s: %s already exists", ErrInvalidInstance, name, cfg.Seed.DBPath)
+	}
+
+	if err := from.Clone(cfg.Seed.DBPath); err != nil {
+		return nil, fmt.Errorf("failed to clone instance %s: %w", source, err)
+	}
+	impl, err := spectrafs.NewSpectraFSFromConfig(cfg)
+	if err != nil {
+		os.Remove(cfg.Seed.DBPath)
+		return nil, fmt.Errorf("failed to initialize instance %s: %w", name, err)
+	}
+	created := &SpectraFS{impl: impl}
+	m.instances[name] = created
+	return created, nil
+}
+
+// instanceConfig resolves and validates the config of a new instance, refusing a name in use and a
+// database file shared with the main filesystem or another instance
+// NOTE: This function assumes the caller already holds m.mu
+func (m *MultiInstance) instanceConfig(instance InstanceConfig) (*Config, error) {
+	if _, exists := m.instances[instance.Name]; exists {
+		return nil, fmt.Errorf("%w: %s", ErrInstanceExists, instance.Name)
+	}
+
+	cfg, err := config.ForInstance(m.main.GetConfig(), instance)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInstance, err)
+	}
+	if err := config.Validate(cfg); err != nil {
+		return nil, fmt.Errorf("%w: instance %s: %w", ErrInvalidInstance, instance.Name, err)
+	}
+	if cfg.Seed.DBPath == m.main.GetConfig().Seed.DBPath && !db.IsMemoryPath(cfg.Seed.DBPath) {
+		return nil, fmt.Errorf("%w: instance %s: db_path is the main database", ErrInvalidInstance, instance.Name)
+	}
+	for name, other := range m.instances {
+		if cfg.Seed.DBPath == other.GetConfig().Seed.DBPath && !db.IsMemoryPath(cfg.Seed.DBPath) {
+			return nil, fmt.Errorf("%w: instance %s: db_path is used by instance %s", ErrInvalidInstance, instance.Name, name)
+		}
+	}
+	return cfg, nil
+}
+
+// DeleteInstance closes the named instance and removes its database file
+// Returns ErrUnknownInstance if there is no such instance
+func (m *MultiInstance) DeleteInstance(name string) error {
+	m.mu.Lock()
+	instance, ok := m.instances[name]
+	delete(m.instances, name)
+	m.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownInstance, name)
+	}
+	if err := instance.Close(); err != nil {
+		return fmt.Errorf("failed to close instance %s: %w", name, err)
+	}
+	if path := instance.GetConfig().Seed.DBPath; !db.IsMemoryPath(path) {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove database of instance %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Close closes every instance and then the main filesystem, returning their errors joined
+func (m *MultiInstance) Close() error {
+	m.mu.Lock()
+	instances := m.instances
+	m.instances = make(map[string]*SpectraFS)
+	m.mu.Unlock()
+
+	var errs []error
+	for name, instance := range instances {
+		if err := instance.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close instance %s: %w", name, err))
+		}
+	}
+	if err := m.main.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
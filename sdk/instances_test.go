@@ -0,0 +1,109 @@
+package sdk_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// writeConfig writes a config file into dir whose main database is dir/spectra.db and returns its path
+func writeConfig(t *testing.T, dir, instances string) string {
+	t.Helper()
+	path := filepath.Join(dir, "config.json")
+	data := `{"seed": {"seed": 1, "max_depth": 2, "min_folders": 2, "max_folders": 3, "min_files": 2, "max_files": 3, "db_path": "` + filepath.Join(dir, "spectra.db") + `"}, "api": {"port": 8086}, "instances": ` + instances + `}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// rootNames lists the names of the root's children in the primary world
+func rootNames(t *testing.T, fs *sdk.SpectraFS) []string {
+	t.Helper()
+	result, err := fs.ListChildrenContext(context.Background(), &sdk.ListChildrenRequest{ParentPath: "/", TableName: "primary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, folder := range result.Folders {
+		names = append(names, folder.Name)
+	}
+	for _, file := range result.Files {
+		names = append(names, file.Name)
+	}
+	return names
+}
+
+func TestMultiInstance(t *testing.T) {
+	dir := t.TempDir()
+	m, err := sdk.NewMultiInstance(writeConfig(t, dir, `[{"name": "a", "seed": {"seed": 7}}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.Close() })
+
+	if names := m.Names(); !slices.Equal(names, []string{"a"}) {
+		t.Fatalf("Names() = %v, want [a]", names)
+	}
+	a, err := m.Instance("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seed := a.GetConfig().Seed; seed.Seed != 7 || seed.MaxDepth != 2 || seed.DBPath != filepath.Join(dir, "spectra.a.db") {
+		t.Errorf("instance a seed = %+v, want seed 7 over the main section, in spectra.a.db", seed)
+	}
+
+	// Instances are isolated: a folder made in one is not seen by the main filesystem
+	if _, err := a.CreateFolderContext(context.Background(), &sdk.CreateFolderRequest{ParentPath: "/", TableName: "primary", Name: "only-a"}); err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(rootNames(t, m.Main()), "only-a") {
+		t.Error("the main filesystem sees a folder created in instance a")
+	}
+
+	seed := m.Main().GetConfig().Seed
+	seed.DBPath = ""
+	b, err := m.CreateInstance(sdk.InstanceConfig{Name: "b", Seed: seed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(rootNames(t, b), rootNames(t, m.Main())) {
+		t.Error("instance b with the main seed generates a different root")
+	}
+	if _, err := m.CreateInstance(sdk.InstanceConfig{Name: "b", Seed: seed}); !errors.Is(err, sdk.ErrInstanceExists) {
+		t.Errorf("creating b twice = %v, want ErrInstanceExists", err)
+	}
+	if _, err := m.CreateInstance(sdk.InstanceConfig{Name: "../x", Seed: seed}); !errors.Is(err, sdk.ErrInvalidInstance) {
+		t.Errorf("creating ../x = %v, want ErrInvalidInstance", err)
+	}
+
+	dbPath := b.GetConfig().Seed.DBPath
+	if err := m.DeleteInstance("b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dbPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("database of deleted instance b: %v, want it removed", err)
+	}
+	if _, err := m.Instance("b"); !errors.Is(err, sdk.ErrUnknownInstance) {
+		t.Errorf("Instance(b) after delete = %v, want ErrUnknownInstance", err)
+	}
+	if err := m.DeleteInstance("b"); !errors.Is(err, sdk.ErrUnknownInstance) {
+		t.Errorf("deleting b twice = %v, want ErrUnknownInstance", err)
+	}
+}
+
+func TestMultiInstanceWithoutInstances(t *testing.T) {
+	m, err := sdk.NewMultiInstance(writeConfig(t, t.TempDir(), `[]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.Close() })
+	if len(m.Names()) != 0 || len(rootNames(t, m.Main())) == 0 {
+		t.Errorf("config without instances gives instances %v and a root of %v", m.Names(), rootNames(t, m.Main()))
+	}
+}
@@ -1,9 +1,17 @@
 package sdk
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"io/fs"
+	"log/slog"
+	"time"
 
+	"github.com/Project-Sylos/Spectra/internal/config"
+	"github.com/Project-Sylos/Spectra/internal/db"
+	"github.com/Project-Sylos/Spectra/internal/logging"
+	"github.com/Project-Sylos/Spectra/internal/metrics"
 	"github.com/Project-Sylos/Spectra/internal/spectrafs"
 	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
 	"github.com/Project-Sylos/Spectra/internal/types"
@@ -27,19 +35,73 @@ func New(configPath string) (*SpectraFS, error) {
 	}, nil
 }
 
-// NewWithDefaults creates a new SpectraFS instance using default configuration
+// NewFromConfig creates a new SpectraFS instance from a configuration built in code, without a config file
+// Settings cfg leaves unset get the defaults a config file would (cfg is updated with them), and the
+// result is validated like a loaded file. Together with seed.db_path MemoryDBPath this runs Spectra
+// without touching disk
+func NewFromConfig(cfg *Config) (*SpectraFS, error) {
+	impl, err := spectrafs.NewSpectraFSFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize SpectraFS: %w", err)
+	}
+
+	return &SpectraFS{
+		impl: impl,
+	}, nil
+}
+
+// DefaultConfig returns the configuration used when a config file leaves settings out
+func DefaultConfig() Config {
+	return config.DefaultConfig()
+}
+
+// NewWithDefaults creates a new SpectraFS instance from DefaultConfig, without a config file
 func NewWithDefaults() (*SpectraFS, error) {
-	return New("configs/default.json")
+	cfg := DefaultConfig()
+	return NewFromConfig(&cfg)
 }
 
-// ListChildren returns the children of a given parent node
+// NewReadOnly opens the database named by the config file without ever writing to it, as if the file
+// set db.read_only: mutating methods return ErrReadOnly and a folder that was never expanded lists as
+// empty. The database must exist; any number of processes may open it read-only at once, but not
+// while a writer holds it (the open fails after a second)
+func NewReadOnly(configPath string) (*SpectraFS, error) {
+	cfg, err := config.LoadFromFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize SpectraFS: %w", err)
+	}
+	cfg.DB.ReadOnly = true
+	return NewFromConfig(cfg)
+}
+
+// ReadOnly reports whether the instance was opened with db.read_only (see NewReadOnly)
+func (s *SpectraFS) ReadOnly() bool {
+	return s.impl.ReadOnly()
+}
+
+// ListChildrenContext returns the children of a given parent node
+// Set Limit and StartingAfter/EndingBefore on the request to page through wide folders
+func (s *SpectraFS) ListChildrenContext(ctx context.Context, req *models.ListChildrenRequest) (*types.ListResult, error) {
+	return s.impl.ListChildren(ctx, req)
+}
+
+// ListChildren calls ListChildrenContext with context.Background()
+//
+// Deprecated: Use ListChildrenContext.
 func (s *SpectraFS) ListChildren(req *models.ListChildrenRequest) (*types.ListResult, error) {
-	return s.impl.ListChildren(req)
+	return s.ListChildrenContext(context.Background(), req)
+}
+
+// GetNodeContext retrieves a node using either ID or Path+TableName
+func (s *SpectraFS) GetNodeContext(ctx context.Context, req *models.GetNodeRequest) (*types.Node, error) {
+	return s.impl.GetNode(ctx, req)
 }
 
-// GetNode retrieves a node using either ID or Path+TableName
+// GetNode calls GetNodeContext with context.Background()
+//
+// Deprecated: Use GetNodeContext.
 func (s *SpectraFS) GetNode(req *models.GetNodeRequest) (*types.Node, error) {
-	return s.impl.GetNode(req)
+	return s.GetNodeContext(context.Background(), req)
 }
 
 // GetFileData generates and returns file data with checksum for a given file ID
@@ -48,42 +110,127 @@ func (s *SpectraFS) GetFileData(id string) ([]byte, string, error) {
 	return s.impl.GetFileData(id)
 }
 
-// CreateFolder creates a new folder node
+// ReadFileRange returns length bytes of a file's content starting at offset, generating only the
+// blocks that cover them. A range running past the end is returned short with io.EOF, like
+// io.ReaderAt; an offset beyond the end or a negative offset or length is ErrInvalidRange
+func (s *SpectraFS) ReadFileRange(id string, offset, length int64) ([]byte, error) {
+	return s.impl.ReadFileRange(id, offset, length)
+}
+
+// OpenFileDataContext returns a streaming reader over a file's content together with the file node
+// Content is generated lazily in 64KB blocks, so large files are never held in memory;
+// the bytes match GetFileData and the node's Checksum. Reads are throttled by
+// api.max_read_bandwidth and seed.per_file_bandwidth and stop with ctx.Err() once ctx is done
+func (s *SpectraFS) OpenFileDataContext(ctx context.Context, id string) (io.ReadSeeker, *types.Node, error) {
+	return s.impl.OpenFileData(ctx, id)
+}
+
+// OpenFileData calls OpenFileDataContext with context.Background()
+//
+// Deprecated: Use OpenFileDataContext.
+func (s *SpectraFS) OpenFileData(id string) (io.ReadSeeker, *types.Node, error) {
+	return s.OpenFileDataContext(context.Background(), id)
+}
+
+// CreateFolderContext creates a new folder node
+// Returns ErrPathExists if a sibling already has the name
+func (s *SpectraFS) CreateFolderContext(ctx context.Context, req *models.CreateFolderRequest) (*types.Node, error) {
+	return s.impl.CreateFolder(ctx, req)
+}
+
+// CreateFolder calls CreateFolderContext with context.Background()
+//
+// Deprecated: Use CreateFolderContext.
 func (s *SpectraFS) CreateFolder(req *models.CreateFolderRequest) (*types.Node, error) {
-	return s.impl.CreateFolder(req)
+	return s.CreateFolderContext(context.Background(), req)
 }
 
-// UploadFile handles file uploads - processes the data and creates a file node
+// CreateSymlink creates a symlink node whose Target is req.Target, stored as given (absolute, or
+// relative to the parent) and allowed to dangle
+// Returns ErrPathExists if a sibling already has the name
+func (s *SpectraFS) CreateSymlink(ctx context.Context, req *models.CreateSymlinkRequest) (*types.Node, error) {
+	return s.impl.CreateSymlink(ctx, req)
+}
+
+// UploadFileContext handles file uploads - processes the data and creates a file node
 // The actual file data is not persisted, only metadata
+// Returns ErrPathExists if a sibling already has the name, unless req.Overwrite replaces an existing file in place
+func (s *SpectraFS) UploadFileContext(ctx context.Context, req *models.UploadFileRequest) (*types.Node, error) {
+	return s.impl.UploadFile(ctx, req)
+}
+
+// UploadFile calls UploadFileContext with context.Background()
+//
+// Deprecated: Use UploadFileContext.
 func (s *SpectraFS) UploadFile(req *models.UploadFileRequest) (*types.Node, error) {
-	return s.impl.UploadFile(req)
+	return s.UploadFileContext(context.Background(), req)
+}
+
+// UploadFileFrom is UploadFileContext with the content streamed from body rather than req.Data, which
+// is ignored; body is read to the end before anything is stored, and a read error is returned wrapped
+func (s *SpectraFS) UploadFileFrom(ctx context.Context, req *models.UploadFileRequest, body io.Reader) (*types.Node, error) {
+	return s.impl.UploadFileFrom(ctx, req, body)
 }
 
-// Reset clears all nodes and recreates the root
+// ResetContext clears all nodes and recreates the root
+func (s *SpectraFS) ResetContext(ctx context.Context) error {
+	return s.impl.Reset(ctx)
+}
+
+// Reset calls ResetContext with context.Background()
+//
+// Deprecated: Use ResetContext.
 func (s *SpectraFS) Reset() error {
-	return s.impl.Reset()
+	return s.ResetContext(context.Background())
 }
 
 // Close closes the database connection after performing a WAL checkpoint to ensure data persistence.
-// This ensures all changes are fully saved before the process finishes.
+// This ensures all changes are fully saved before the process finishes, including with db.no_sync,
+// whose skipped fsyncs are made up by one sync before the file is closed.
 // Always call this method during graceful shutdown to guarantee data integrity.
 func (s *SpectraFS) Close() error {
 	return s.impl.Close()
 }
 
-// GetConfig returns the current configuration
+// GetConfig returns a deep copy of the current configuration; changing it does not affect the
+// instance, so pass it to UpdateConfig to apply changes
 func (s *SpectraFS) GetConfig() *types.Config {
 	return s.impl.GetConfig()
 }
 
-// GetNodeCount returns the total number of nodes in a specific table
+// UpdateConfig validates cfg and makes a copy of it the instance's configuration, saving it to the
+// config file when the instance was opened from one. seed.db_path, instances and the set of worlds
+// cannot change (ErrInvalidConfig, as for a config that fails validation). Generation settings (the
+// seed section, world probabilities, world_generation) return ErrConfigMismatch unless reset is set,
+// which clears the tree like Reset first. Retention, chaos, the generation budgets and
+// root_display_name apply immediately; the api, db, debug, mutations and maintenance_schedule
+// sections and the bandwidth limits take effect when the instance is reopened
+func (s *SpectraFS) UpdateConfig(ctx context.Context, cfg *Config, reset bool) error {
+	return s.impl.UpdateConfig(ctx, cfg, reset)
+}
+
+// GetNodeCountContext returns the total number of nodes in a specific table
+func (s *SpectraFS) GetNodeCountContext(ctx context.Context, tableName string) (int, error) {
+	return s.impl.GetNodeCount(ctx, tableName)
+}
+
+// GetNodeCount calls GetNodeCountContext with context.Background()
+//
+// Deprecated: Use GetNodeCountContext.
 func (s *SpectraFS) GetNodeCount(tableName string) (int, error) {
-	return s.impl.GetNodeCount(tableName)
+	return s.GetNodeCountContext(context.Background(), tableName)
 }
 
-// GetTableInfo returns information about all tables
+// GetTableInfoContext returns information about all tables
+func (s *SpectraFS) GetTableInfoContext(ctx context.Context) ([]types.TableInfo, error) {
+	return s.impl.GetTableInfo(ctx)
+}
+
+// GetTableInfo calls GetTableInfoContext with context.Background()
+//
+// Deprecated: Use GetTableInfoContext.
 func (s *SpectraFS) GetTableInfo() ([]types.TableInfo, error) {
-	return s.impl.GetTableInfo()
+	return s.GetTableInfoContext(context.Background())
 }
 
 // GetSecondaryTables returns the list of secondary table names
@@ -91,45 +238,850 @@ func (s *SpectraFS) GetSecondaryTables() []string {
 	return s.impl.GetSecondaryTables()
 }
 
+// RebuildCounters recomputes the per-world node counters behind GetNodeCount and GetTableInfo from the nodes
+func (s *SpectraFS) RebuildCounters() error {
+	return s.impl.RebuildCounters()
+}
+
 // GetStats retrieves the current filesystem statistics
 func (s *SpectraFS) GetStats() (*Stats, error) {
 	return s.impl.GetStats()
 }
 
-// DeleteNode deletes a node using either ID or Path+World
+// DeleteNodeContext deletes a node using either ID or Path+World
+// Non-empty folders return ErrFolderNotEmpty unless req.Recursive is set
+func (s *SpectraFS) DeleteNodeContext(ctx context.Context, req *models.DeleteNodeRequest) error {
+	return s.impl.DeleteNode(ctx, req)
+}
+
+// DeleteNode calls DeleteNodeContext with context.Background()
+//
+// Deprecated: Use DeleteNodeContext.
 func (s *SpectraFS) DeleteNode(req *models.DeleteNodeRequest) error {
-	return s.impl.DeleteNode(req)
+	return s.DeleteNodeContext(context.Background(), req)
+}
+
+// MoveNodeContext moves a node and its whole subtree under a new parent folder, returning the moved node
+// Rejects moving root (ErrRootProtected), moving into the node's own subtree, onto an existing path,
+// or into a parent missing from a world the node exists in
+func (s *SpectraFS) MoveNodeContext(ctx context.Context, req *models.MoveNodeRequest) (*types.Node, error) {
+	return s.impl.MoveNode(ctx, req)
+}
+
+// MoveNode calls MoveNodeContext with context.Background()
+//
+// Deprecated: Use MoveNodeContext.
+func (s *SpectraFS) MoveNode(req *models.MoveNodeRequest) (*types.Node, error) {
+	return s.MoveNodeContext(context.Background(), req)
+}
+
+// CopySubtreeContext duplicates a node and its descendants under dstParentID with new IDs
+// Names, sizes, checksums and content are preserved; opts can restrict the copy to one world
+// and override the copies' secondary-world existence
+func (s *SpectraFS) CopySubtreeContext(ctx context.Context, srcID, dstParentID string, opts CopyOptions) (*CopyResult, error) {
+	return s.impl.CopySubtree(ctx, srcID, dstParentID, opts)
+}
+
+// CopySubtree calls CopySubtreeContext with context.Background()
+//
+// Deprecated: Use CopySubtreeContext.
+func (s *SpectraFS) CopySubtree(srcID, dstParentID string, opts CopyOptions) (*CopyResult, error) {
+	return s.CopySubtreeContext(context.Background(), srcID, dstParentID, opts)
+}
+
+// UpdateCopyStatus sets a node's copy status ("pending", "in_progress" or "completed")
+// Returns ErrInvalidCopyStatus for other values
+func (s *SpectraFS) UpdateCopyStatus(req *models.UpdateCopyStatusRequest) (*types.Node, error) {
+	return s.impl.UpdateCopyStatus(req)
+}
+
+// UpdateSubtreeCopyStatus sets the copy status of a node and all of its descendants,
+// returning the number of nodes updated
+func (s *SpectraFS) UpdateSubtreeCopyStatus(req *models.UpdateCopyStatusRequest) (int, error) {
+	return s.impl.UpdateSubtreeCopyStatus(req)
+}
+
+// ListNodesByCopyStatus pages through a world's nodes with the given copy status in ID order
+// limit 0 returns every match; pass the previous page's NextCursor to continue
+func (s *SpectraFS) ListNodesByCopyStatus(world, status string, limit int, cursor string) (*NodePage, error) {
+	return s.impl.ListNodesByCopyStatus(world, status, limit, cursor)
+}
+
+// DiffWorlds returns a page of the nodes whose presence differs between two worlds, in ID order
+// opts.Root scopes it to a subtree by path; opts.Limit (0 = all) and opts.Cursor page through the result
+func (s *SpectraFS) DiffWorlds(ctx context.Context, worldA, worldB string, opts DiffOptions) (*WorldDiff, error) {
+	return s.impl.DiffWorlds(ctx, worldA, worldB, opts)
+}
+
+// DiffWorldsFunc streams every difference between two worlds under root to fn without paging
+// kind is DiffOnlyInA, DiffOnlyInB or DiffChanged; an error from fn stops the scan and is returned
+func (s *SpectraFS) DiffWorldsFunc(ctx context.Context, worldA, worldB, root string, fn func(kind string, node *Node) error) error {
+	return s.impl.DiffWorldsFunc(ctx, worldA, worldB, root, fn)
+}
+
+// ForEachNode calls fn for every stored node, optionally only those in opts.World or at or below
+// opts.PathPrefix, without listing folder by folder. Nodes are read in batches and no transaction is
+// held while fn runs; opts.Snapshot reads them all on one consistent snapshot instead. Nodes are seen
+// as stored (no retention view, nothing generated). An error from fn stops the scan and is returned;
+// returns ErrInvalidScanOptions or ErrUnknownWorld for bad options and ctx.Err() once ctx is done
+func (s *SpectraFS) ForEachNode(ctx context.Context, opts NodeScanOptions, fn func(node *Node) error) error {
+	return s.impl.ForEachNode(ctx, opts, fn)
+}
+
+// Export writes every stored node to w as a snapshot, ordered by depth then path
+// format is SnapshotFormatJSONL (default) or SnapshotFormatJSON; returns ErrUnknownFormat otherwise
+func (s *SpectraFS) Export(ctx context.Context, w io.Writer, format string) error {
+	return s.impl.Export(ctx, w, format)
+}
+
+// Import loads a snapshot written by Export in a single transaction, rebuilding indexes and stats
+// Without merge the database must hold only the root (ErrDatabaseNotEmpty); with merge, nodes whose ID is
+// already stored are skipped. Malformed or out-of-order snapshots return ErrInvalidSnapshot
+func (s *SpectraFS) Import(ctx context.Context, r io.Reader, merge bool) (*ImportResult, error) {
+	return s.impl.Import(ctx, r, merge)
+}
+
+// SaveSnapshot stores a copy of the tree, its stats, mutation log and change journal under name in
+// the database. Returns ErrSnapshotExists for a taken name and ErrInvalidSnapshotName for an empty
+// name or one containing "/"
+func (s *SpectraFS) SaveSnapshot(ctx context.Context, name string) (*SnapshotInfo, error) {
+	return s.impl.SaveSnapshot(ctx, name)
+}
+
+// RestoreSnapshot atomically replaces the tree with the snapshot saved under name, waiting for
+// running operations like Reset does. Returns ErrSnapshotNotFound for an unknown name and
+// ErrConfigMismatch for a snapshot saved under other generation settings
+func (s *SpectraFS) RestoreSnapshot(ctx context.Context, name string) (*SnapshotInfo, error) {
+	return s.impl.RestoreSnapshot(ctx, name)
+}
+
+// ListSnapshots returns the saved snapshots, ordered by name
+func (s *SpectraFS) ListSnapshots() ([]SnapshotInfo, error) {
+	return s.impl.ListSnapshots()
+}
+
+// DeleteSnapshot removes the snapshot saved under name (ErrSnapshotNotFound if there is none)
+func (s *SpectraFS) DeleteSnapshot(name string) error {
+	return s.impl.DeleteSnapshot(name)
+}
+
+// Compact rewrites the database file with only its live pages, returning the space freed by a Reset
+// or large deletes to the filesystem, and reports the size before and after. Writes wait for it;
+// reads carry on. Returns ErrCompactUnsupported for an in-memory database
+func (s *SpectraFS) Compact() (*CompactResult, error) {
+	return s.impl.Compact()
+}
+
+// MigrateNodeEncoding rewrites the nodes a database created before the binary node encoding still
+// stores as JSON; it can be cancelled and run again. See cmd/migrate
+func (s *SpectraFS) MigrateNodeEncoding(ctx context.Context) (*NodeEncodingResult, error) {
+	return s.impl.MigrateNodeEncoding(ctx)
+}
+
+// Worlds returns the world registry: primary, then each secondary world in name order with its
+// existence probability. These are the names accepted as TableName; any other is ErrUnknownWorld
+func (s *SpectraFS) Worlds() []WorldInfo {
+	return s.impl.Worlds()
+}
+
+// AddWorld registers a secondary world at runtime, backfilling each node's existence in it with
+// deterministic per-node dice. Returns ErrWorldExists for primary or a registered world
+func (s *SpectraFS) AddWorld(name string, probability float64) (*types.TableInfo, error) {
+	return s.impl.AddWorld(name, probability)
+}
+
+// RemoveWorld unregisters a secondary world and strips it from every node's existence map
+// Returns ErrUnknownWorld for a world that is not registered and ErrInvalidWorld for primary
+func (s *SpectraFS) RemoveWorld(name string) error {
+	return s.impl.RemoveWorld(name)
+}
+
+// GenerateAll eagerly materializes the whole tree down to seed.max_depth, skipping folders that already
+// have children; cancel ctx to stop it. Progress is also reported under Generation in GetStats
+func (s *SpectraFS) GenerateAll(ctx context.Context) (*GenerationProgress, error) {
+	return s.impl.GenerateAll(ctx)
+}
+
+// GenerationProgress returns the progress of the latest GenerateAll run, or nil if none has started
+func (s *SpectraFS) GenerationProgress() *GenerationProgress {
+	return s.impl.GenerationProgress()
+}
+
+// CancelGeneration cancels a running GenerateAll and reports whether one was running
+func (s *SpectraFS) CancelGeneration() bool {
+	return s.impl.CancelGeneration()
+}
+
+// SaveJob records the state of a job run by the API's job manager (ErrReadOnly on a read-only
+// instance). Queued and running jobs are marked failed when the database is next opened
+func (s *SpectraFS) SaveJob(job *Job) error {
+	return s.impl.SaveJob(job)
+}
+
+// GetJob returns the recorded state of a job, or ErrJobNotFound
+func (s *SpectraFS) GetJob(id string) (*Job, error) {
+	return s.impl.GetJob(id)
+}
+
+// ListJobs returns the recorded jobs, newest first
+func (s *SpectraFS) ListJobs() ([]*Job, error) {
+	return s.impl.ListJobs()
+}
+
+// WalkContext returns a folder's whole subtree flattened depth-first, each node with its depth below the folder
+// Folders are generated lazily on the way down; req.MaxNodes (default DefaultWalkMaxNodes) caps the
+// result and sets Truncated when it stops the walk
+func (s *SpectraFS) WalkContext(ctx context.Context, req *models.WalkRequest) (*WalkResult, error) {
+	return s.impl.Walk(ctx, req)
+}
+
+// Walk calls WalkContext with context.Background()
+//
+// Deprecated: Use WalkContext.
+func (s *SpectraFS) Walk(req *models.WalkRequest) (*WalkResult, error) {
+	return s.WalkContext(context.Background(), req)
+}
+
+// WalkFuncContext streams a folder's subtree depth-first to fn without building the whole list
+// An error from fn stops the walk and is returned; ErrWalkLimitReached reports that the node cap stopped it
+func (s *SpectraFS) WalkFuncContext(ctx context.Context, req *models.WalkRequest, fn func(*Node) error) error {
+	return s.impl.WalkFunc(ctx, req, fn)
+}
+
+// WalkFunc calls WalkFuncContext with context.Background()
+//
+// Deprecated: Use WalkFuncContext.
+func (s *SpectraFS) WalkFunc(req *models.WalkRequest, fn func(*Node) error) error {
+	return s.WalkFuncContext(context.Background(), req, fn)
+}
+
+// Search finds stored nodes under req.PathPrefix by name glob, type and size range, in path order
+// Only materialized nodes are searched; nothing is generated. req.Limit (default DefaultSearchLimit)
+// caps the result and sets Truncated. Returns ErrInvalidSearch or ErrUnknownWorld for bad requests
+func (s *SpectraFS) Search(ctx context.Context, req *models.SearchRequest) (*SearchResult, error) {
+	return s.impl.Search(ctx, req)
+}
+
+// RenameNodeContext renames a node in place (same ID), rewriting the paths of its whole subtree
+// Rejects root (ErrRootProtected), invalid names (ErrInvalidName) and sibling collisions (ErrPathExists)
+func (s *SpectraFS) RenameNodeContext(ctx context.Context, req *models.RenameNodeRequest) (*types.Node, error) {
+	return s.impl.RenameNode(ctx, req)
+}
+
+// RenameNode calls RenameNodeContext with context.Background()
+//
+// Deprecated: Use RenameNodeContext.
+func (s *SpectraFS) RenameNode(req *models.RenameNodeRequest) (*types.Node, error) {
+	return s.RenameNodeContext(context.Background(), req)
+}
+
+// UpdateTraversalStatus sets a node's traversal status ("pending", "successful" or "failed")
+// for tracking an external crawler's progress, and returns the updated node
+func (s *SpectraFS) UpdateTraversalStatus(req *models.UpdateTraversalStatusRequest) (*types.Node, error) {
+	return s.impl.UpdateTraversalStatus(req)
+}
+
+// TouchNode sets a node's LastUpdated (its fs.FS ModTime) and returns the node (supports ID or
+// Path+TableName lookup), so tests can bump an mtime and check downstream change detection
+func (s *SpectraFS) TouchNode(req *models.GetNodeRequest, lastUpdated time.Time) (*types.Node, error) {
+	return s.impl.TouchNode(req, lastUpdated)
+}
+
+// SetNodeMetadata merges req.Metadata into a node's custom metadata (an empty value removes its key),
+// or replaces it with req.Replace, and returns the updated node (supports ID or Path+TableName lookup)
+func (s *SpectraFS) SetNodeMetadata(ctx context.Context, req *models.SetNodeMetadataRequest) (*types.Node, error) {
+	return s.impl.SetNodeMetadata(ctx, req)
+}
+
+// GetNodeMetadata returns a copy of a node's custom metadata, empty when it has none (supports ID or
+// Path+TableName lookup)
+func (s *SpectraFS) GetNodeMetadata(ctx context.Context, req *models.GetNodeRequest) (map[string]string, error) {
+	return s.impl.GetNodeMetadata(ctx, req)
+}
+
+// DeleteNodesContext deletes a list of nodes by ID and reports a per-ID outcome
+// Set recursive to remove non-empty folders together with their descendants
+func (s *SpectraFS) DeleteNodesContext(ctx context.Context, ids []string, recursive bool) (*BatchDeleteResult, error) {
+	return s.impl.DeleteNodes(ctx, ids, recursive)
+}
+
+// DeleteNodes calls DeleteNodesContext with context.Background()
+//
+// Deprecated: Use DeleteNodesContext.
+func (s *SpectraFS) DeleteNodes(ids []string, recursive bool) (*BatchDeleteResult, error) {
+	return s.DeleteNodesContext(context.Background(), ids, recursive)
+}
+
+// BatchCreate creates folders and files in order and reports a per-op result (created node or error)
+// An op may name a folder created earlier in the batch as its parent via ParentKey. Consecutive
+// successful ops are stored with a single bulk insert. Returns ErrInvalidBatch for an empty batch,
+// one larger than MaxBatchCreateSize, or a repeated key
+func (s *SpectraFS) BatchCreate(ctx context.Context, ops []BatchOp) (*BatchCreateResult, error) {
+	return s.impl.BatchCreate(ctx, ops)
+}
+
+// DeterminismCheck builds throwaway instances from the current config and verifies they generate
+// identical trees, reporting the first divergence (path, field, values) if they do not
+func (s *SpectraFS) DeterminismCheck(iterations int) (*DeterminismReport, error) {
+	return s.impl.DeterminismCheck(iterations)
+}
+
+// VerifyChecksum regenerates a file's content and reports whether claimed, a hex SHA256 checksum,
+// matches it, checking the stored checksum alongside. Returns ErrInvalidChecksum for a malformed claim
+func (s *SpectraFS) VerifyChecksum(ctx context.Context, id, claimed string) (*ChecksumVerification, error) {
+	return s.impl.VerifyChecksum(ctx, id, claimed)
+}
+
+// VerifyTree recomputes the checksums of the stored files below rootID (the root if empty) in
+// world (primary if empty) without generating anything, and reports the files whose stored checksum
+// differs, listing at most MaxChecksumMismatches of them
+func (s *SpectraFS) VerifyTree(ctx context.Context, rootID, world string) (*VerifyReport, error) {
+	return s.impl.VerifyTree(ctx, rootID, world)
+}
+
+// Analyze reports the shape of the stored subtree below rootID (the root if empty) in world
+// (primary if empty): nodes per depth, child count and file size histograms, world coverage and
+// path lengths, for checking that the generation settings produce the intended tree
+func (s *SpectraFS) Analyze(ctx context.Context, rootID, world string) (*AnalysisReport, error) {
+	return s.impl.Analyze(ctx, rootID, world)
+}
+
+// Duplicates groups the stored files in world (primary if empty) that have byte-identical content:
+// duplicates drawn with seed.duplicate_probability, and copies with their source
+func (s *SpectraFS) Duplicates(ctx context.Context, world string) (*DuplicatesReport, error) {
+	return s.impl.Duplicates(ctx, world)
+}
+
+// ApplyRetention persists retention for a world, flipping existence to false for every
+// node whose configured TTL has passed, and reports the expirations
+func (s *SpectraFS) ApplyRetention(world string) (*RetentionResult, error) {
+	return s.impl.ApplyRetention(world)
+}
+
+// SetWorldExistence adds a stored node to a secondary world or removes it, keeping parents present
+// wherever their children are: removal cascades to every descendant, and adding fails with
+// ErrAncestorMissing unless the node's ancestors are in the world (recursive adds the subtree too)
+func (s *SpectraFS) SetWorldExistence(rootID, world string, exists, recursive bool) (*WorldExistenceResult, error) {
+	return s.impl.SetWorldExistence(rootID, world, exists, recursive)
+}
+
+// Clone copies the live database to targetDBPath without stopping this instance
+// The clone gets a new instance ID, records this instance as its source, and keeps the seed;
+// open it with a config pointing seed.db_path at targetDBPath. Mutations to either never affect the other.
+func (s *SpectraFS) Clone(targetDBPath string) error {
+	return s.impl.Clone(targetDBPath)
+}
+
+// Identity returns this instance's identity: instance ID, seed, and clone lineage
+func (s *SpectraFS) Identity() (*InstanceIdentity, error) {
+	return s.impl.Identity()
+}
+
+// PersistedConfig returns the generation settings stored in the database: seed, branching and worlds
+// Opening the database with a config that differs from them fails with ErrConfigMismatch
+func (s *SpectraFS) PersistedConfig() (*GenerationFingerprint, error) {
+	return s.impl.PersistedConfig()
+}
+
+// ConfigChangesOnOpen lists how the config differed from the stored generation settings when
+// db.accept_config_change let this instance open anyway, or nil if they matched
+func (s *SpectraFS) ConfigChangesOnOpen() []string {
+	return s.impl.ConfigChangesOnOpen()
+}
+
+// GetCoverage reports, per world and depth, how much of the expected tree has been materialized
+// Expected totals are estimates derived from the seed config, not guarantees
+func (s *SpectraFS) GetCoverage() (*CoverageReport, error) {
+	return s.impl.GetCoverage()
+}
+
+// LastRecovery returns the most recent crash-recovery report stored in the database, or nil if none
+func (s *SpectraFS) LastRecovery() (*RecoveryReport, error) {
+	return s.impl.LastRecovery()
+}
+
+// RecoveryOnOpen returns the crash-recovery report produced when this instance opened its database,
+// or nil if the previous run shut down cleanly
+func (s *SpectraFS) RecoveryOnOpen() *RecoveryReport {
+	return s.impl.RecoveryOnOpen()
+}
+
+// CheckIntegrity verifies the index buckets against the stored nodes; quick only compares entry counts
+func (s *SpectraFS) CheckIntegrity(ctx context.Context, quick bool) (*IntegrityReport, error) {
+	return s.impl.CheckIntegrity(ctx, quick)
+}
+
+// Repair rebuilds every index and the stats from the stored nodes and returns a full check of the result
+func (s *SpectraFS) Repair(ctx context.Context) (*IntegrityReport, error) {
+	return s.impl.Repair(ctx)
+}
+
+// IntegrityOnOpen returns the index check run when this instance opened its database,
+// or nil unless db.check_integrity is set
+func (s *SpectraFS) IntegrityOnOpen() *IntegrityReport {
+	return s.impl.IntegrityOnOpen()
+}
+
+// Health reports whether the instance is ready to serve: it checks that the database is open and holds
+// the root node in one cheap read-only transaction, and is not ready while Reset or Import runs
+func (s *SpectraFS) Health(ctx context.Context) *HealthReport {
+	return s.impl.Health(ctx)
+}
+
+// ArmGenerationFailure makes generation fail once afterNodes more nodes have been inserted (testing hook)
+// The failing folder keeps the nodes inserted so far and is completed by its next ListChildren;
+// the hook fires once, and afterNodes <= 0 disarms it
+func (s *SpectraFS) ArmGenerationFailure(afterNodes int64) {
+	s.impl.ArmGenerationFailure(afterNodes)
+}
+
+// GenerationFailureArmed reports whether the generation failure hook is armed and how many inserts remain
+func (s *SpectraFS) GenerationFailureArmed() (bool, int64) {
+	return s.impl.GenerationFailureArmed()
+}
+
+// DebugBuckets lists the raw database buckets and their key counts
+// Returns ErrDebugDisabled unless the config sets debug.expose_buckets
+func (s *SpectraFS) DebugBuckets() ([]BucketInfo, error) {
+	return s.impl.DebugBuckets()
+}
+
+// DebugScanBucket returns raw key/value pairs from a bucket whose keys start with prefix, after the
+// key after (a previous page's NextAfter); returns ErrDebugDisabled unless debug.expose_buckets is set
+func (s *SpectraFS) DebugScanBucket(name, prefix, after string, limit int) (*BucketPage, error) {
+	return s.impl.DebugScanBucket(name, prefix, after, limit)
+}
+
+// StartMaintenance starts the background scheduler for the tasks in the config's maintenance_schedule
+// Close stops it, waiting for a running task to finish
+func (s *SpectraFS) StartMaintenance() error {
+	return s.impl.StartMaintenance()
+}
+
+// RunMaintenanceTask runs one maintenance task immediately and records its status
+func (s *SpectraFS) RunMaintenanceTask(task string) (*MaintenanceTaskStatus, error) {
+	return s.impl.RunMaintenanceTask(task)
+}
+
+// MaintenanceSchedule reports each scheduled maintenance task with its last-run status
+func (s *SpectraFS) MaintenanceSchedule() (*MaintenanceSchedule, error) {
+	return s.impl.MaintenanceSchedule()
+}
+
+// SetClock replaces the clock used to evaluate retention TTLs (nil restores time.Now)
+func (s *SpectraFS) SetClock(now func() time.Time) {
+	s.impl.SetClock(now)
+}
+
+// SetMetrics has the instance record generation counts and durations and BoltDB transaction
+// durations into recorder (nil stops recording); call it before the instance is shared.
+// Pass a MetricsRegistry to serve them in the Prometheus format, or adapt an existing metrics
+// system to MetricsRecorder, registering MetricDescs up front
+func (s *SpectraFS) SetMetrics(recorder MetricsRecorder) {
+	s.impl.SetMetrics(recorder)
+}
+
+// NewMetricsRegistry creates an empty in-memory MetricsRecorder that serves its values in the
+// Prometheus text format (it is an http.Handler)
+func NewMetricsRegistry() *MetricsRegistry {
+	return metrics.NewRegistry()
+}
+
+// SetLogger has the instance log generation and BoltDB transaction details through logger at debug
+// level (nil stops logging); call it before the instance is shared. Nothing is logged until then, and
+// a logger at info level or above stays quiet; see NewLogger
+func (s *SpectraFS) SetLogger(logger *slog.Logger) {
+	s.impl.SetLogger(logger)
+}
+
+// NewLogger creates a logger writing records of level ("debug", "info", "warn" or "error") and
+// above to w, as "text" or "json", like the API server's api.log_level and api.log_format
+func NewLogger(w io.Writer, level, format string) (*slog.Logger, error) {
+	return logging.New(w, level, format)
+}
+
+// WithLogger returns a copy of ctx carrying logger, which calls made with it log through instead of
+// the instance's (e.g. one tagged with a caller's request ID)
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return logging.WithLogger(ctx, logger)
+}
+
+// ChaosSettings returns the chaos rules currently in force
+func (s *SpectraFS) ChaosSettings() ChaosConfig {
+	return s.impl.ChaosSettings()
+}
+
+// SetChaos replaces the chaos rules at runtime (an empty ChaosConfig turns chaos off)
+// The chaos RNG is reseeded from rules.Seed; generation is unaffected
+func (s *SpectraFS) SetChaos(rules ChaosConfig) error {
+	return s.impl.SetChaos(rules)
+}
+
+// InjectChaos applies the chaos rule for op: it waits out the drawn latency and returns a
+// *ChaosError (matching ErrChaosInjected) if the call was drawn to fail; nil if no rule applies
+// SpectraFS methods never call it themselves; see WithChaos and the API's chaos middleware
+func (s *SpectraFS) InjectChaos(ctx context.Context, op string) error {
+	return s.impl.InjectChaos(ctx, op)
+}
+
+// StartMutations starts the mutation engine configured by the mutations section: every interval it
+// creates, modifies and deletes rate nodes in the scope, drawing from the mutation seed
+// Returns ErrMutationsRunning if it is already running; Close stops it
+func (s *SpectraFS) StartMutations() error {
+	return s.impl.StartMutations()
+}
+
+// StopMutations stops the mutation engine, waiting for a round in progress (no-op if it is not running)
+func (s *SpectraFS) StopMutations() {
+	s.impl.StopMutations()
+}
+
+// MutationStatus reports the mutation engine's settings, whether it is running, and its progress
+func (s *SpectraFS) MutationStatus() (*MutationStatus, error) {
+	return s.impl.MutationStatus()
+}
+
+// RunMutations applies count mutations immediately (1 to MaxMutationsPerRun) and returns them as logged
+// Stops early with ctx.Err(), or with ErrNoMutationCandidates when the scope has nothing left to mutate
+func (s *SpectraFS) RunMutations(ctx context.Context, count int) ([]Mutation, error) {
+	return s.impl.RunMutations(ctx, count)
+}
+
+// ListMutations returns up to limit entries of the mutation log after sequence number afterSeq, oldest first
+func (s *SpectraFS) ListMutations(afterSeq int64, limit int) ([]Mutation, error) {
+	return s.impl.ListMutations(afterSeq, limit)
+}
+
+// GetChanges returns up to limit change journal events after sequence number sinceSeq, oldest first,
+// with the cursor to pass next (limit 0 = DefaultChangesLimit, at most MaxChangesLimit)
+// A Truncated feed or a reset event means history was lost and the consumer should rescan
+func (s *SpectraFS) GetChanges(sinceSeq int64, limit int) (*ChangeFeed, error) {
+	return s.impl.GetChanges(sinceSeq, limit)
+}
+
+// Subscribe returns a channel of live events (journaled changes in journal order, and nodes created by
+// lazy generation) until ctx is done or Close. The channel is also closed if the consumer falls
+// EventBufferSize events behind; resume from GetChanges with the last Seq seen
+func (s *SpectraFS) Subscribe(ctx context.Context) (<-chan Event, error) {
+	return s.impl.Subscribe(ctx)
 }
 
 // Re-export types for convenience
 type (
-	Config      = types.Config
-	Node        = types.Node
-	Folder      = types.Folder
-	File        = types.File
-	ListResult  = types.ListResult
-	TableInfo   = types.TableInfo
-	Stats       = types.Stats
-	APIResponse = types.APIResponse
+	Config         = types.Config
+	InstanceConfig = types.InstanceConfig
+	SeedConfig     = types.SeedConfig
+	Node           = types.Node
+	Folder         = types.Folder
+	File           = types.File
+	Symlink        = types.Symlink
+	ListResult     = types.ListResult
+	TableInfo      = types.TableInfo
+	WorldInfo      = types.WorldInfo
+	Stats          = types.Stats
+	APIResponse    = types.APIResponse
+
+	DeleteOutcome     = types.DeleteOutcome
+	BatchDeleteResult = types.BatchDeleteResult
+	BatchOpResult     = types.BatchOpResult
+	BatchCreateResult = types.BatchCreateResult
+
+	DeterminismReport     = types.DeterminismReport
+	DeterminismDivergence = types.DeterminismDivergence
+
+	ChecksumVerification = types.ChecksumVerification
+	ChecksumMismatch     = types.ChecksumMismatch
+	VerifyReport         = types.VerifyReport
+
+	AnalysisReport  = types.AnalysisReport
+	DepthAnalysis   = types.DepthAnalysis
+	HistogramBucket = types.HistogramBucket
+
+	DuplicatesReport = types.DuplicatesReport
+	DuplicateGroup   = types.DuplicateGroup
+
+	MetaOptions = spectrafs.MetaOptions
+
+	RetentionRule       = types.RetentionRule
+	RetentionResult     = types.RetentionResult
+	RetentionExpiration = types.RetentionExpiration
+
+	WorldExistenceResult = types.WorldExistenceResult
+
+	RecoveryReport  = types.RecoveryReport
+	RecoveryFinding = types.RecoveryFinding
+
+	IntegrityReport = types.IntegrityReport
+	IntegrityIssue  = types.IntegrityIssue
+
+	HealthReport = types.HealthReport
+
+	Job           = types.Job
+	ResetProgress = types.ResetProgress
+
+	InstanceIdentity      = types.InstanceIdentity
+	GenerationFingerprint = types.GenerationFingerprint
+
+	CoverageReport = types.CoverageReport
+	WorldCoverage  = types.WorldCoverage
+	DepthCoverage  = types.DepthCoverage
+
+	BucketInfo  = types.BucketInfo
+	BucketEntry = types.BucketEntry
+	BucketPage  = types.BucketPage
+
+	MaintenanceSchedule   = types.MaintenanceSchedule
+	MaintenanceTaskStatus = types.MaintenanceTaskStatus
+
+	CopyOptions = types.CopyOptions
+	CopyResult  = types.CopyResult
+	NodePage    = types.NodePage
+
+	WalkEntry  = types.WalkEntry
+	WalkResult = types.WalkResult
+
+	SearchResult = types.SearchResult
+
+	GenerationProgress = types.GenerationProgress
+
+	NodeScanOptions = types.NodeScanOptions
+
+	DiffOptions = types.DiffOptions
+	WorldDiff   = types.WorldDiff
+
+	ImportResult       = types.ImportResult
+	SnapshotInfo       = types.SnapshotInfo
+	CompactResult      = types.CompactResult
+	NodeEncodingResult = types.NodeEncodingResult
+
+	ChaosConfig = types.ChaosConfig
+	ChaosRule   = types.ChaosRule
+	ChaosError  = spectrafs.ChaosError
+
+	MutationsConfig = types.MutationsConfig
+	MutationWeights = types.MutationWeights
+	Mutation        = types.Mutation
+	MutationStatus  = types.MutationStatus
+
+	ChangeEvent = types.ChangeEvent
+	ChangeFeed  = types.ChangeFeed
+	Event       = types.Event
+
+	AuthConfig = types.AuthConfig
+	AuthToken  = types.AuthToken
+
+	MetricsRecorder = metrics.Recorder
+	MetricsRegistry = metrics.Registry
+	MetricLabel     = metrics.Label
+	MetricDesc      = metrics.Desc
 )
 
+// MetricDescs lists every metric SetMetrics records and the API server adds, for registering them up front
+var MetricDescs = metrics.Descs
+
 // Re-export request models
 type (
-	GetNodeRequest      = models.GetNodeRequest
-	ListChildrenRequest = models.ListChildrenRequest
-	CreateFolderRequest = models.CreateFolderRequest
-	UploadFileRequest   = models.UploadFileRequest
-	DeleteNodeRequest   = models.DeleteNodeRequest
+	GetNodeRequest       = models.GetNodeRequest
+	ListChildrenRequest  = models.ListChildrenRequest
+	CreateFolderRequest  = models.CreateFolderRequest
+	CreateSymlinkRequest = models.CreateSymlinkRequest
+	UploadFileRequest    = models.UploadFileRequest
+	DeleteNodeRequest    = models.DeleteNodeRequest
+
+	UpdateTraversalStatusRequest = models.UpdateTraversalStatusRequest
+	MoveNodeRequest              = models.MoveNodeRequest
+	RenameNodeRequest            = models.RenameNodeRequest
+	UpdateCopyStatusRequest      = models.UpdateCopyStatusRequest
+	SetNodeMetadataRequest       = models.SetNodeMetadataRequest
+	WalkRequest                  = models.WalkRequest
+	SearchRequest                = models.SearchRequest
+	BatchOp                      = models.BatchOp
+)
+
+// Re-export sentinel errors
+var (
+	ErrNotFound     = spectrafs.ErrNotFound
+	ErrInvalidInput = spectrafs.ErrInvalidInput
+	ErrConflict     = spectrafs.ErrConflict
+
+	ErrInvalidCursor = spectrafs.ErrInvalidCursor
+	ErrCursorExpired = spectrafs.ErrCursorExpired
+
+	ErrUnknownWorld = spectrafs.ErrUnknownWorld
+	ErrWorldExists  = spectrafs.ErrWorldExists
+	ErrInvalidWorld = spectrafs.ErrInvalidWorld
+
+	ErrAncestorMissing = spectrafs.ErrAncestorMissing
+
+	ErrUnknownInstance = spectrafs.ErrUnknownInstance
+	ErrInstanceExists  = spectrafs.ErrInstanceExists
+	ErrInvalidInstance = spectrafs.ErrInvalidInstance
+
+	ErrRootProtected = spectrafs.ErrRootProtected
+	ErrReadOnly      = spectrafs.ErrReadOnly
+
+	ErrConfigMismatch = spectrafs.ErrConfigMismatch
+	ErrInvalidConfig  = spectrafs.ErrInvalidConfig
+
+	ErrNodeNotFound   = spectrafs.ErrNodeNotFound
+	ErrParentNotFound = spectrafs.ErrParentNotFound
+	ErrNotAFile       = spectrafs.ErrNotAFile
+	ErrInvalidRange   = spectrafs.ErrInvalidRange
+	ErrSymlinkLoop    = spectrafs.ErrSymlinkLoop
+	ErrNotSymlink     = spectrafs.ErrNotSymlink
+	ErrIsSymlink      = spectrafs.ErrIsSymlink
+	ErrFolderNotEmpty = spectrafs.ErrFolderNotEmpty
+
+	ErrMoveIntoDescendant     = spectrafs.ErrMoveIntoDescendant
+	ErrMoveWorldMismatch      = spectrafs.ErrMoveWorldMismatch
+	ErrMoveTargetNotDir       = spectrafs.ErrMoveTargetNotDir
+	ErrPathExists             = spectrafs.ErrPathExists
+	ErrInvalidName            = spectrafs.ErrInvalidName
+	ErrInvalidCopyOptions     = spectrafs.ErrInvalidCopyOptions
+	ErrInvalidTraversalStatus = spectrafs.ErrInvalidTraversalStatus
+	ErrInvalidCopyStatus      = spectrafs.ErrInvalidCopyStatus
+	ErrInvalidMetadata        = spectrafs.ErrInvalidMetadata
+
+	ErrWalkLimitReached   = spectrafs.ErrWalkLimitReached
+	ErrWalkTargetNotDir   = spectrafs.ErrWalkTargetNotDir
+	ErrInvalidWalkOptions = spectrafs.ErrInvalidWalkOptions
+
+	ErrInvalidSearch = spectrafs.ErrInvalidSearch
+	ErrInvalidBatch  = spectrafs.ErrInvalidBatch
+
+	ErrInvalidScanOptions = spectrafs.ErrInvalidScanOptions
+
+	ErrInjectedFailure   = spectrafs.ErrInjectedFailure
+	ErrGenerationRunning = spectrafs.ErrGenerationRunning
+
+	ErrUnknownFormat    = spectrafs.ErrUnknownFormat
+	ErrInvalidSnapshot  = spectrafs.ErrInvalidSnapshot
+	ErrDatabaseNotEmpty = spectrafs.ErrDatabaseNotEmpty
+
+	ErrSnapshotNotFound    = spectrafs.ErrSnapshotNotFound
+	ErrSnapshotExists      = spectrafs.ErrSnapshotExists
+	ErrInvalidSnapshotName = spectrafs.ErrInvalidSnapshotName
+
+	ErrInvalidChecksum = spectrafs.ErrInvalidChecksum
+
+	ErrCompactUnsupported = spectrafs.ErrCompactUnsupported
+
+	ErrJobNotFound = spectrafs.ErrJobNotFound
+
+	ErrDebugDisabled = spectrafs.ErrDebugDisabled
+	ErrUnknownBucket = spectrafs.ErrUnknownBucket
+
+	ErrChaosInjected = spectrafs.ErrChaosInjected
+
+	ErrMutationsRunning     = spectrafs.ErrMutationsRunning
+	ErrNoMutationCandidates = spectrafs.ErrNoMutationCandidates
+
+	ErrInvalidChanges = spectrafs.ErrInvalidChanges
+	ErrEventsClosed   = spectrafs.ErrEventsClosed
 )
 
 // Re-export constants
 const (
-	NodeTypeFolder = types.NodeTypeFolder
-	NodeTypeFile   = types.NodeTypeFile
+	MemoryDBPath = db.MemoryPath
+
+	NodeTypeFolder  = types.NodeTypeFolder
+	NodeTypeFile    = types.NodeTypeFile
+	NodeTypeSymlink = types.NodeTypeSymlink
 
 	StatusPending    = types.StatusPending
 	StatusSuccessful = types.StatusSuccessful
 	StatusFailed     = types.StatusFailed
+
+	DeleteStatusDeleted         = types.DeleteStatusDeleted
+	DeleteStatusNotFound        = types.DeleteStatusNotFound
+	DeleteStatusSkippedNotEmpty = types.DeleteStatusSkippedNotEmpty
+	DeleteStatusSkippedRoot     = types.DeleteStatusSkippedRoot
+	DeleteStatusFailed          = types.DeleteStatusFailed
+
+	MaxBatchDeleteSize = spectrafs.MaxBatchDeleteSize
+	MaxBatchCreateSize = spectrafs.MaxBatchCreateSize
+
+	MetaDirName = spectrafs.MetaDirName
+
+	DefaultWalkMaxNodes = spectrafs.DefaultWalkMaxNodes
+
+	DefaultSearchLimit = spectrafs.DefaultSearchLimit
+
+	DiffOnlyInA = types.DiffOnlyInA
+	DiffOnlyInB = types.DiffOnlyInB
+	DiffChanged = types.DiffChanged
+
+	SnapshotFormatJSONL = types.SnapshotFormatJSONL
+	SnapshotFormatJSON  = types.SnapshotFormatJSON
+
+	RecoverySeverityWarning = types.RecoverySeverityWarning
+	RecoverySeveritySevere  = types.RecoverySeveritySevere
+
+	IntegrityMissing  = types.IntegrityMissing
+	IntegrityDangling = types.IntegrityDangling
+	IntegrityStale    = types.IntegrityStale
+	IntegrityOrphaned = types.IntegrityOrphaned
+
+	MaintenanceTaskApplyRetention  = types.MaintenanceTaskApplyRetention
+	MaintenanceTaskRebuildStats    = types.MaintenanceTaskRebuildStats
+	MaintenanceTaskCompact         = types.MaintenanceTaskCompact
+	MaintenanceTaskPruneJournal    = types.MaintenanceTaskPruneJournal
+	MaintenanceTaskSnapshotCleanup = types.MaintenanceTaskSnapshotCleanup
+
+	MaintenanceStatusOK      = types.MaintenanceStatusOK
+	MaintenanceStatusFailed  = types.MaintenanceStatusFailed
+	MaintenanceStatusSkipped = types.MaintenanceStatusSkipped
+
+	ChaosOpList     = types.ChaosOpList
+	ChaosOpGet      = types.ChaosOpGet
+	ChaosOpRead     = types.ChaosOpRead
+	ChaosOpCreate   = types.ChaosOpCreate
+	ChaosOpUpload   = types.ChaosOpUpload
+	ChaosOpBatch    = types.ChaosOpBatch
+	ChaosOpDelete   = types.ChaosOpDelete
+	ChaosOpMove     = types.ChaosOpMove
+	ChaosOpRename   = types.ChaosOpRename
+	ChaosOpCopy     = types.ChaosOpCopy
+	ChaosOpSearch   = types.ChaosOpSearch
+	ChaosOpWalk     = types.ChaosOpWalk
+	ChaosOpStatus   = types.ChaosOpStatus
+	ChaosOpMetadata = types.ChaosOpMetadata
+	ChaosOpAny      = types.ChaosOpAny
+
+	MutationCreate = types.MutationCreate
+	MutationModify = types.MutationModify
+	MutationDelete = types.MutationDelete
+
+	MaxMutationsPerRun = spectrafs.MaxMutationsPerRun
+
+	ChangeCreate    = types.ChangeCreate
+	ChangeModify    = types.ChangeModify
+	ChangeDelete    = types.ChangeDelete
+	ChangeMove      = types.ChangeMove
+	ChangeRename    = types.ChangeRename
+	ChangeExistence = types.ChangeExistence
+	ChangeReset     = types.ChangeReset
+	EventGenerate   = types.EventGenerate
+
+	DefaultChangesLimit      = spectrafs.DefaultChangesLimit
+	MaxChangesLimit          = spectrafs.MaxChangesLimit
+	DefaultJournalMaxEntries = spectrafs.DefaultJournalMaxEntries
+	EventBufferSize          = spectrafs.EventBufferSize
+	MaxChecksumMismatches    = spectrafs.MaxChecksumMismatches
+
+	RoleRead  = types.RoleRead
+	RoleWrite = types.RoleWrite
+	RoleAdmin = types.RoleAdmin
+
+	JobGenerate   = types.JobGenerate
+	JobReset      = types.JobReset
+	JobVerifyTree = types.JobVerifyTree
+
+	JobQueued    = types.JobQueued
+	JobRunning   = types.JobRunning
+	JobDone      = types.JobDone
+	JobFailed    = types.JobFailed
+	JobCancelled = types.JobCancelled
 )
 
 // AsFS returns an fs.FS instance bound to a specific world
@@ -139,6 +1091,19 @@ func (s *SpectraFS) AsFS(world string) fs.FS {
 	return spectrafs.NewSpectraFSWrapper(s.impl, world)
 }
 
+// AsFSWithMeta returns an fs.FS instance bound to a specific world that also serves
+// each node's JSON metadata at ".spectra-meta/<path>.json"
+func (s *SpectraFS) AsFSWithMeta(world string, opts MetaOptions) fs.FS {
+	return spectrafs.NewSpectraFSWrapperWithMeta(s.impl, world, opts)
+}
+
+// AsFSFollowingSymlinks returns an fs.FS instance bound to a specific world that resolves symlinks
+// transparently, with dangling links reported as fs.ErrNotExist and loops as ErrSymlinkLoop; AsFS
+// surfaces them instead (fs.ModeSymlink entries, ReadLink and Lstat, Open failing with ErrIsSymlink)
+func (s *SpectraFS) AsFSFollowingSymlinks(world string) fs.FS {
+	return spectrafs.NewSpectraFSWrapper(s.impl, world).FollowSymlinks()
+}
+
 // AsFSWithDefaults returns an fs.FS instance using the "primary" world
 // This is a convenience method for the most common use case
 func (s *SpectraFS) AsFSWithDefaults() fs.FS {
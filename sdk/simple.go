@@ -0,0 +1,43 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/Project-Sylos/Spectra/internal/spectrafs/models"
+)
+
+// The methods below are string-based shorthands for the most common calls; each builds the request
+// struct and calls the struct-based method, which remains the way to page, overwrite or recurse
+
+// ListChildrenByID lists the children of the folder with the given ID in the primary world
+func (s *SpectraFS) ListChildrenByID(ctx context.Context, id string) (*ListResult, error) {
+	return s.ListChildrenContext(ctx, &models.ListChildrenRequest{ParentID: id})
+}
+
+// ListChildrenByPath lists the children of the folder at path (e.g. "/folder_1") in world
+func (s *SpectraFS) ListChildrenByPath(ctx context.Context, path, world string) (*ListResult, error) {
+	return s.ListChildrenContext(ctx, &models.ListChildrenRequest{ParentPath: path, TableName: world})
+}
+
+// CreateFolderIn creates a folder named name in the folder with the given ID
+// Returns ErrPathExists if a sibling already has the name
+func (s *SpectraFS) CreateFolderIn(ctx context.Context, parentID, name string) (*Node, error) {
+	return s.CreateFolderContext(ctx, &models.CreateFolderRequest{ParentID: parentID, Name: name})
+}
+
+// UploadFileTo uploads data as a file named name in the folder with the given ID
+// Returns ErrPathExists if a sibling already has the name; use UploadFileContext with Overwrite to replace it
+func (s *SpectraFS) UploadFileTo(ctx context.Context, parentID, name string, data []byte) (*Node, error) {
+	return s.UploadFileContext(ctx, &models.UploadFileRequest{ParentID: parentID, Name: name, Data: data})
+}
+
+// GetNodeByID returns the node with the given ID
+func (s *SpectraFS) GetNodeByID(ctx context.Context, id string) (*Node, error) {
+	return s.GetNodeContext(ctx, &models.GetNodeRequest{ID: id})
+}
+
+// DeleteNodeByID deletes the node with the given ID
+// Non-empty folders return ErrFolderNotEmpty; use DeleteNodeContext with Recursive to remove them
+func (s *SpectraFS) DeleteNodeByID(ctx context.Context, id string) error {
+	return s.DeleteNodeContext(ctx, &models.DeleteNodeRequest{ID: id})
+}
@@ -0,0 +1,84 @@
+package sdk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// newFS opens a small in-memory instance, closed when the test ends
+func newFS(t *testing.T) *sdk.SpectraFS {
+	t.Helper()
+	cfg := sdk.DefaultConfig()
+	cfg.Seed.DBPath = sdk.MemoryDBPath
+	cfg.Seed.MaxDepth = 2
+	fs, err := sdk.NewFromConfig(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fs.Close() })
+	return fs
+}
+
+func TestShorthandMethods(t *testing.T) {
+	fs := newFS(t)
+	ctx := context.Background()
+
+	byID, err := fs.ListChildrenByID(ctx, "p-root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPath, err := fs.ListChildrenByPath(ctx, "/", "primary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byID.Folders) == 0 || len(byID.Folders) != len(byPath.Folders) || len(byID.Files) != len(byPath.Files) {
+		t.Fatalf("root by ID has %d folders and %d files, by path %d and %d",
+			len(byID.Folders), len(byID.Files), len(byPath.Folders), len(byPath.Files))
+	}
+
+	folder, err := fs.CreateFolderIn(ctx, "p-root", "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.CreateFolderIn(ctx, "p-root", "x"); !errors.Is(err, sdk.ErrPathExists) {
+		t.Errorf("second CreateFolderIn = %v, want ErrPathExists", err)
+	}
+	file, err := fs.UploadFileTo(ctx, folder.ID, "a.txt", []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Path != "/x/a.txt" {
+		t.Errorf("uploaded file path %s, want /x/a.txt", file.Path)
+	}
+
+	got, err := fs.GetNodeByID(ctx, file.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != file.ID || got.Name != "a.txt" {
+		t.Errorf("GetNodeByID returned %s %s", got.ID, got.Name)
+	}
+	listed, err := fs.ListChildrenByPath(ctx, "/x", "primary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed.Files) != 1 || listed.Files[0].ID != file.ID {
+		t.Errorf("listing /x returned %d files, want the upload", len(listed.Files))
+	}
+
+	if err := fs.DeleteNodeByID(ctx, folder.ID); !errors.Is(err, sdk.ErrFolderNotEmpty) {
+		t.Errorf("DeleteNodeByID on a non-empty folder = %v, want ErrFolderNotEmpty", err)
+	}
+	if err := fs.DeleteNodeByID(ctx, file.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.DeleteNodeByID(ctx, folder.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.GetNodeByID(ctx, folder.ID); !errors.Is(err, sdk.ErrNodeNotFound) {
+		t.Errorf("GetNodeByID after delete = %v, want ErrNodeNotFound", err)
+	}
+}
@@ -0,0 +1,140 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Defaults for TestFS: a small tree that materializes in a few milliseconds
+const (
+	defaultTestSeed   = 42
+	defaultTestDepth  = 2
+	defaultTestFanout = 2
+)
+
+// Option configures the instance built by TestFS and TestSpectraFS
+type Option func(*testOptions)
+
+// testOptions holds the settings collected from Option values
+type testOptions struct {
+	seed   int64
+	depth  int
+	fanout int
+	worlds map[string]float64
+}
+
+// WithSeed sets the generation seed (default 42)
+func WithSeed(seed int64) Option {
+	return func(o *testOptions) {
+		o.seed = seed
+	}
+}
+
+// WithDepth sets how many folder levels are generated below the root (default 2)
+func WithDepth(depth int) Option {
+	return func(o *testOptions) {
+		o.depth = depth
+	}
+}
+
+// WithFanout sets the exact number of folders and files generated in each folder (default 2)
+func WithFanout(fanout int) Option {
+	return func(o *testOptions) {
+		o.fanout = fanout
+	}
+}
+
+// WithWorlds configures secondary worlds and the probability that a node exists in each (default none)
+func WithWorlds(worlds map[string]float64) Option {
+	return func(o *testOptions) {
+		o.worlds = worlds
+	}
+}
+
+// TestBackendEnv names the environment variable that picks the storage TestFS and TestSpectraFS use:
+// unset (or anything else) keeps the database in memory, TestBackendFile puts it in t.TempDir()
+const (
+	TestBackendEnv  = "SPECTRA_TEST_BACKEND"
+	TestBackendFile = "file"
+)
+
+// TestFS returns the primary-world fs.FS of a throwaway, fully materialized instance
+// The database lives in memory (see TestBackendEnv) and is closed via t.Cleanup, so parallel tests never share state
+func TestFS(t testing.TB, opts ...Option) fs.FS {
+	t.Helper()
+	return TestSpectraFS(t, opts...).AsFS("primary")
+}
+
+// TestSpectraFS is like TestFS but returns the full SDK handle
+// Every folder down to the configured depth is generated up front, in breadth-first order,
+// so the tree does not depend on the order in which the test walks it
+func TestSpectraFS(t testing.TB, opts ...Option) *SpectraFS {
+	t.Helper()
+
+	options := testOptions{
+		seed:   defaultTestSeed,
+		depth:  defaultTestDepth,
+		fanout: defaultTestFanout,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Seed.Seed = options.seed
+	cfg.Seed.MaxDepth = options.depth
+	cfg.Seed.MinFolders = options.fanout
+	cfg.Seed.MaxFolders = options.fanout
+	cfg.Seed.MinFiles = options.fanout
+	cfg.Seed.MaxFiles = options.fanout
+	cfg.Seed.DBPath = MemoryDBPath
+	if os.Getenv(TestBackendEnv) == TestBackendFile {
+		cfg.Seed.DBPath = filepath.Join(t.TempDir(), "spectra.db")
+	}
+	cfg.SecondaryTables = options.worlds
+	if cfg.SecondaryTables == nil {
+		cfg.SecondaryTables = make(map[string]float64)
+	}
+
+	s, err := NewFromConfig(&cfg)
+	if err != nil {
+		t.Fatalf("sdk.TestFS: %v", err)
+	}
+	t.Cleanup(func() {
+		s.Close()
+	})
+
+	if err := materialize(s); err != nil {
+		t.Fatalf("sdk.TestFS: failed to generate tree: %v", err)
+	}
+	return s
+}
+
+// materialize lists every primary-world folder breadth-first so the whole tree is generated
+func materialize(s *SpectraFS) error {
+	maxDepth := s.GetConfig().Seed.MaxDepth
+	queue := []string{"root"}
+	for len(queue) > 0 {
+		parentID := queue[0]
+		queue = queue[1:]
+
+		result, err := s.ListChildrenContext(context.Background(), &ListChildrenRequest{ParentID: parentID, TableName: "primary"})
+		if err != nil {
+			return err
+		}
+		if !result.Success {
+			return fmt.Errorf("failed to list %s: %s", parentID, result.Message)
+		}
+
+		for _, folder := range result.Folders {
+			if folder.DepthLevel < maxDepth {
+				queue = append(queue, folder.ID)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package sdk_test
+
+import (
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+// walk returns every name below the root of fsys, in fs.WalkDir order
+func walk(t *testing.T, fsys fs.FS) []string {
+	t.Helper()
+	var names []string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+func TestFSPassesFSTest(t *testing.T) {
+	t.Parallel()
+	fsys := sdk.TestFS(t, sdk.WithDepth(3))
+
+	if err := fstest.TestFS(fsys, "file_1.txt", "folder_1/file_1.txt", "folder_2/folder_1/file_2.txt"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFSWalkDir(t *testing.T) {
+	t.Parallel()
+	names := walk(t, sdk.TestFS(t))
+
+	// Two files and two folders under the root and under each first-level folder; the second
+	// level is the configured depth, so its folders are empty
+	want := []string{
+		"file_1.txt", "file_2.txt",
+		"folder_1", "folder_1/file_1.txt", "folder_1/file_2.txt", "folder_1/folder_1", "folder_1/folder_2",
+		"folder_2", "folder_2/file_1.txt", "folder_2/file_2.txt", "folder_2/folder_1", "folder_2/folder_2",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("WalkDir visited %v, want %v", names, want)
+	}
+}
+
+func TestFSFanout(t *testing.T) {
+	t.Parallel()
+	entries, err := fs.ReadDir(sdk.TestFS(t, sdk.WithFanout(3), sdk.WithDepth(1)), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 6 {
+		t.Errorf("root holds %d entries, want 3 folders and 3 files", len(entries))
+	}
+}
+
+func TestFSSameSeedSameContent(t *testing.T) {
+	t.Parallel()
+	read := func(seed int64) []byte {
+		data, err := fs.ReadFile(sdk.TestFS(t, sdk.WithSeed(seed)), "folder_1/file_1.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	if a, b := read(7), read(7); string(a) != string(b) {
+		t.Error("two instances with seed 7 generated different content")
+	}
+	if a, b := read(7), read(8); string(a) == string(b) {
+		t.Error("seeds 7 and 8 generated the same content")
+	}
+}
+
+func TestSpectraFSWorlds(t *testing.T) {
+	t.Parallel()
+	s := sdk.TestSpectraFS(t, sdk.WithWorlds(map[string]float64{"s1": 0.5}))
+
+	primary := walk(t, s.AsFS("primary"))
+	inPrimary := make(map[string]bool, len(primary))
+	for _, name := range primary {
+		inPrimary[name] = true
+	}
+	for _, name := range walk(t, s.AsFS("s1")) {
+		if !inPrimary[name] {
+			t.Errorf("%s is in s1 but not in primary", name)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package sdk_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Project-Sylos/Spectra/sdk"
+)
+
+func TestUnknownWorldIsInvalidInput(t *testing.T) {
+	fs := newFS(t)
+	ctx := context.Background()
+
+	var names []string
+	for _, world := range fs.Worlds() {
+		names = append(names, world.Name)
+	}
+	if want := []string{"primary", "s1"}; !slices.Equal(names, want) {
+		t.Fatalf("Worlds() = %v, want %v", names, want)
+	}
+
+	for name, call := range map[string]func() error{
+		"ListChildren": func() error {
+			_, err := fs.ListChildrenContext(ctx, &sdk.ListChildrenRequest{ParentID: "p-root", TableName: "s9"})
+			return err
+		},
+		"GetNode by path": func() error {
+			_, err := fs.GetNodeContext(ctx, &sdk.GetNodeRequest{Path: "/", TableName: "s9"})
+			return err
+		},
+		"CreateFolder": func() error {
+			_, err := fs.CreateFolderContext(ctx, &sdk.CreateFolderRequest{ParentPath: "/", TableName: "s9", Name: "x"})
+			return err
+		},
+		"Search": func() error {
+			_, err := fs.Search(ctx, &sdk.SearchRequest{World: "s9"})
+			return err
+		},
+	} {
+		err := call()
+		if !errors.Is(err, sdk.ErrUnknownWorld) || !errors.Is(err, sdk.ErrInvalidInput) {
+			t.Errorf("%s in s9 = %v, want ErrUnknownWorld", name, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), "primary, s1") {
+			t.Errorf("%s error %q does not name the valid worlds", name, err)
+		}
+	}
+
+	// An empty name is primary
+	if _, err := fs.ListChildrenContext(ctx, &sdk.ListChildrenRequest{ParentID: "p-root"}); err != nil {
+		t.Errorf("ListChildren without a world: %v", err)
+	}
+}